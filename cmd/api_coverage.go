@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+const kernelSDKModule = "github.com/kernel/kernel-go-sdk"
+
+// apiServiceCommands maps top-level SDK services whose CLI command isn't the
+// kebab-cased service name.
+var apiServiceCommands = map[string]string{
+	"Apps":        "app",
+	"Deployments": "deploy",
+	"Invocations": "invoke",
+}
+
+// apiEndpointCommands maps SDK endpoints to the CLI command that covers them
+// when the command isn't named after the service and method. Add an entry
+// here when a new command's name doesn't follow that rule.
+var apiEndpointCommands = map[string]string{
+	"AuditLogs.ExportChunk":                 "audit-logs download",
+	"AuditLogs.List":                        "audit-logs search",
+	"Auth.Connections.Timeline":             "auth connections logs",
+	"Browsers.Computer.CaptureScreenshot":   "browsers computer screenshot",
+	"Browsers.Computer.SetCursorVisibility": "browsers computer set-cursor",
+	"Browsers.Computer.TypeText":            "browsers computer type",
+	"Browsers.Fs.SetFilePermissions":        "browsers fs set-permissions",
+	"Browsers.LoadExtensions":               "browsers extensions upload",
+	"Deployments.Follow":                    "deploy logs",
+	"Deployments.List":                      "deploy history",
+	"Deployments.New":                       "deploy",
+	"Extensions.DownloadFromChromeStore":    "extensions download-web-store",
+	"Invocations.Follow":                    "logs",
+	"Invocations.List":                      "invoke history",
+	"Invocations.ListBrowsers":              "invoke browsers",
+	"Invocations.New":                       "invoke",
+	"Organization.Limits.Get":               "quota",
+	"Projects.Limits.Update":                "projects limits set",
+}
+
+// apiMethodVerbs maps SDK method names to the CLI's verbs for them.
+var apiMethodVerbs = map[string]string{
+	"New":        "create",
+	"DeleteByID": "delete",
+}
+
+// apiEndpoint is one SDK method and the CLI command that covers it, if any.
+type apiEndpoint struct {
+	Endpoint string `json:"endpoint"`
+	Command  string `json:"command,omitempty"`
+	// Suggested is the command name the CLI would use for an uncovered
+	// endpoint.
+	Suggested string `json:"suggested,omitempty"`
+}
+
+type apiCoverageReport struct {
+	SDKVersion string        `json:"sdk_version,omitempty"`
+	Total      int           `json:"total"`
+	Covered    int           `json:"covered"`
+	Endpoints  []apiEndpoint `json:"endpoints"`
+}
+
+// sdkEndpoints lists the API methods of kernel.Client's services as dotted
+// paths such as "Browsers.Fs.ReadFile". Pagination and streaming variants are
+// folded into the method they wrap, and helpers that don't take a context
+// (e.g. Browsers.HTTPClient) are skipped.
+func sdkEndpoints() []string {
+	seen := map[string]bool{}
+	var walk func(prefix string, t reflect.Type)
+	walk = func(prefix string, t reflect.Type) {
+		pt := reflect.PointerTo(t)
+		for i := 0; i < pt.NumMethod(); i++ {
+			m := pt.Method(i)
+			if strings.HasSuffix(m.Name, "AutoPaging") {
+				continue
+			}
+			if m.Type.NumIn() < 2 || m.Type.In(1) != reflect.TypeFor[context.Context]() {
+				continue
+			}
+			seen[prefix+"."+strings.TrimSuffix(m.Name, "Streaming")] = true
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); isSDKService(f) {
+				walk(prefix+"."+f.Name, f.Type)
+			}
+		}
+	}
+	client := reflect.TypeFor[kernel.Client]()
+	for i := 0; i < client.NumField(); i++ {
+		if f := client.Field(i); isSDKService(f) {
+			walk(f.Name, f.Type)
+		}
+	}
+
+	endpoints := make([]string, 0, len(seen))
+	for e := range seen {
+		endpoints = append(endpoints, e)
+	}
+	sort.Strings(endpoints)
+	return endpoints
+}
+
+func isSDKService(f reflect.StructField) bool {
+	return f.IsExported() && f.Type.Kind() == reflect.Struct && strings.HasSuffix(f.Type.Name(), "Service")
+}
+
+// suggestedCommand derives the conventional CLI command for an endpoint:
+// kebab-cased services and methods, with New as create.
+func suggestedCommand(endpoint string) string {
+	parts := strings.Split(endpoint, ".")
+	words := make([]string, len(parts))
+	for i, p := range parts {
+		switch {
+		case i == 0 && apiServiceCommands[p] != "":
+			words[i] = apiServiceCommands[p]
+		case i == len(parts)-1 && apiMethodVerbs[p] != "":
+			words[i] = apiMethodVerbs[p]
+		default:
+			words[i] = kebabCase(p)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// kebabCase turns "DownloadDirZip" into "download-dir-zip" and "APIKeys" into
+// "api-keys".
+func kebabCase(s string) string {
+	r := []rune(s)
+	var b strings.Builder
+	for i, c := range r {
+		if i > 0 && unicode.IsUpper(c) && (unicode.IsLower(r[i-1]) || (i+1 < len(r) && unicode.IsLower(r[i+1]))) {
+			b.WriteByte('-')
+		}
+		b.WriteRune(unicode.ToLower(c))
+	}
+	return b.String()
+}
+
+// findCommandPath returns the command at path (e.g. "browsers fs move") under
+// root, or nil if there isn't one.
+func findCommandPath(root *cobra.Command, path string) *cobra.Command {
+	c, rest, err := root.Find(strings.Fields(path))
+	if err != nil || len(rest) > 0 || c == root {
+		return nil
+	}
+	return c
+}
+
+// apiCoverage matches every SDK endpoint against the command tree under root.
+func apiCoverage(root *cobra.Command, endpoints []string) apiCoverageReport {
+	report := apiCoverageReport{SDKVersion: sdkVersion(), Total: len(endpoints), Endpoints: []apiEndpoint{}}
+	for _, e := range endpoints {
+		ep := apiEndpoint{Endpoint: e}
+		path, ok := apiEndpointCommands[e]
+		if !ok {
+			path = suggestedCommand(e)
+		}
+		if c := findCommandPath(root, path); c != nil {
+			ep.Command = c.CommandPath()
+			report.Covered++
+		} else {
+			ep.Suggested = root.Name() + " " + suggestedCommand(e)
+		}
+		report.Endpoints = append(report.Endpoints, ep)
+	}
+	return report
+}
+
+func sdkVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == kernelSDKModule {
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return ""
+}
+
+var apiCmd = &cobra.Command{
+	Use:   "api",
+	Short: "Inspect the Kernel API as seen by this CLI",
+}
+
+var apiCoverageCmd = &cobra.Command{
+	Use:   "coverage",
+	Short: "List API endpoints that have no CLI command yet",
+	Long: `Compare the endpoints exposed by the SDK this CLI was built with against the
+CLI's commands, and list the ones with no command yet along with the name the
+command would conventionally get. Use --all to also show covered endpoints and
+the command for each.
+
+This runs offline: it only inspects the CLI binary.`,
+	Example: `  kernel api coverage
+  kernel api coverage --all -o json`,
+	Args: cobra.NoArgs,
+	RunE: runAPICoverage,
+}
+
+func init() {
+	apiCmd.AddCommand(apiCoverageCmd)
+	apiCoverageCmd.Flags().Bool("all", false, "Include endpoints that already have a command")
+	addJSONOutputFlag(apiCoverageCmd)
+}
+
+func runAPICoverage(cmd *cobra.Command, args []string) error {
+	all, _ := cmd.Flags().GetBool("all")
+	output, _ := cmd.Flags().GetString("output")
+	if err := validateJSONOutput(output); err != nil {
+		return err
+	}
+
+	report := apiCoverage(cmd.Root(), sdkEndpoints())
+	if !all {
+		missing := []apiEndpoint{}
+		for _, ep := range report.Endpoints {
+			if ep.Command == "" {
+				missing = append(missing, ep)
+			}
+		}
+		report.Endpoints = missing
+	}
+	if output == "json" {
+		return util.PrintJSON(report)
+	}
+
+	sdk := "the SDK"
+	if report.SDKVersion != "" {
+		sdk = "SDK " + report.SDKVersion
+	}
+	pterm.Info.Printf("%d of %d endpoints in %s have a CLI command\n", report.Covered, report.Total, sdk)
+	if len(report.Endpoints) == 0 {
+		return nil
+	}
+	rows := pterm.TableData{{"Endpoint", "Command"}}
+	for _, ep := range report.Endpoints {
+		command := ep.Command
+		if command == "" {
+			command = pterm.Yellow(fmt.Sprintf("%s (missing)", ep.Suggested))
+		}
+		rows = append(rows, []string{ep.Endpoint, command})
+	}
+	PrintTableNoPad(rows, true)
+	return nil
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSDKEndpoints(t *testing.T) {
+	endpoints := sdkEndpoints()
+	assert.Contains(t, endpoints, "Browsers.New")
+	assert.Contains(t, endpoints, "Browsers.Fs.Watch.Events")
+	assert.Contains(t, endpoints, "Auth.Connections.Follow")
+	assert.NotContains(t, endpoints, "Browsers.ListAutoPaging")
+	assert.NotContains(t, endpoints, "Browsers.HTTPClient")
+}
+
+func TestSuggestedCommand(t *testing.T) {
+	assert.Equal(t, "api-keys rotate", suggestedCommand("APIKeys.Rotate"))
+	assert.Equal(t, "browsers fs download-dir-zip", suggestedCommand("Browsers.Fs.DownloadDirZip"))
+	assert.Equal(t, "browser-pools create", suggestedCommand("BrowserPools.New"))
+	assert.Equal(t, "invoke get", suggestedCommand("Invocations.Get"))
+}
+
+func TestAPICoverage(t *testing.T) {
+	root := &cobra.Command{Use: "kernel"}
+	browsers := &cobra.Command{Use: "browsers"}
+	browsers.AddCommand(&cobra.Command{Use: "create", Run: func(*cobra.Command, []string) {}})
+	root.AddCommand(browsers)
+
+	report := apiCoverage(root, []string{"Browsers.New", "Browsers.Get"})
+	assert.Equal(t, 2, report.Total)
+	assert.Equal(t, 1, report.Covered)
+	require.Len(t, report.Endpoints, 2)
+	assert.Equal(t, apiEndpoint{Endpoint: "Browsers.New", Command: "kernel browsers create"}, report.Endpoints[0])
+	assert.Equal(t, apiEndpoint{Endpoint: "Browsers.Get", Suggested: "kernel browsers get"}, report.Endpoints[1])
+}
+
+// Every mapped command must exist, so a rename doesn't silently turn a covered
+// endpoint into a missing one.
+func TestAPIEndpointCommandsExist(t *testing.T) {
+	endpoints := sdkEndpoints()
+	for endpoint, path := range apiEndpointCommands {
+		assert.Contains(t, endpoints, endpoint)
+		assert.NotNil(t, findCommandPath(rootCmd, path), "no command %q for %s", path, endpoint)
+	}
+}
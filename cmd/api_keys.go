@@ -80,6 +80,7 @@ func (c APIKeysCmd) Create(ctx context.Context, in APIKeysCreateInput) error {
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(key)
 	}
+	util.WarnDecodeDrift(key)
 
 	pterm.Success.Printf("Created API key: %s\n", key.ID)
 	renderCreatedAPIKey(key)
@@ -118,6 +119,7 @@ func (c APIKeysCmd) List(ctx context.Context, in APIKeysListInput) error {
 	if in.Output == "json" {
 		return util.PrintPrettyJSONSlice(keys)
 	}
+	util.WarnDecodeDrift(keys)
 
 	if len(keys) == 0 {
 		pterm.Info.Println("No API keys found")
@@ -153,6 +155,7 @@ func (c APIKeysCmd) Get(ctx context.Context, in APIKeysGetInput) error {
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(key)
 	}
+	util.WarnDecodeDrift(key)
 
 	renderAPIKeyDetails(key)
 	return nil
@@ -174,6 +177,7 @@ func (c APIKeysCmd) Update(ctx context.Context, in APIKeysUpdateInput) error {
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(key)
 	}
+	util.WarnDecodeDrift(key)
 
 	pterm.Success.Printf("Updated API key: %s\n", key.ID)
 	return nil
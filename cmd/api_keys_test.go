@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
+	"os"
 	"testing"
 
+	"github.com/kernel/cli/pkg/util"
 	"github.com/kernel/kernel-go-sdk"
 	"github.com/kernel/kernel-go-sdk/option"
 	"github.com/kernel/kernel-go-sdk/packages/pagination"
@@ -196,6 +199,31 @@ func TestAPIKeysListPassesPaginationAndRendersRows(t *testing.T) {
 	assert.Contains(t, out, "Never")
 }
 
+func TestAPIKeysGetWarnsAboutDriftInTableOutput(t *testing.T) {
+	capturePtermOutput(t)
+	util.SetStrictDecode(true)
+	t.Cleanup(func() { util.SetStrictDecode(false) })
+	fake := &FakeAPIKeysService{
+		GetFunc: func(ctx context.Context, id string, query kernel.APIKeyGetParams, opts ...option.RequestOption) (*kernel.APIKey, error) {
+			return apiKeyFromJSON(`{"id":"key_123","name":"ci","masked_key":"sk_...123","created_at":"2026-05-27T12:00:00Z","created_by":{"id":"user_123","email":"dev@example.com","name":"Dev"},"expires_at":null,"project_id":null,"project_name":null,"scopes":["read"]}`), nil
+		},
+	}
+	c := APIKeysCmd{apiKeys: fake}
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	oldStderr := os.Stderr
+	os.Stderr = w
+	err = c.Get(context.Background(), APIKeysGetInput{ID: "key_123"})
+	w.Close()
+	os.Stderr = oldStderr
+	require.NoError(t, err)
+
+	stderr, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Contains(t, string(stderr), `strict-decode: unknown field $.scopes = ["read"]`)
+}
+
 func TestAPIKeysUpdateRequiresName(t *testing.T) {
 	c := APIKeysCmd{apiKeys: &FakeAPIKeysService{}}
 	err := c.Update(context.Background(), APIKeysUpdateInput{ID: "key_123"})
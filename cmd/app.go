@@ -121,11 +121,11 @@ func runAppList(cmd *cobra.Command, args []string) error {
 
 	if output == "json" {
 		if apps == nil || len(apps.Items) == 0 {
-			fmt.Println("[]")
-			return nil
+			return util.PrintJSON([]any{})
 		}
 		return util.PrintPrettyJSONSlice(apps.Items)
 	}
+	util.WarnDecodeDrift(apps)
 
 	if apps == nil || len(apps.Items) == 0 {
 		pterm.Info.Println("No applications found")
@@ -324,11 +324,11 @@ func runAppHistory(cmd *cobra.Command, args []string) error {
 
 	if output == "json" {
 		if deployments == nil || len(deployments.Items) == 0 {
-			fmt.Println("[]")
-			return nil
+			return util.PrintJSON([]any{})
 		}
 		return util.PrintPrettyJSONSlice(deployments.Items)
 	}
+	util.WarnDecodeDrift(deployments)
 
 	if deployments == nil || len(deployments.Items) == 0 {
 		pterm.Info.Println("No deployments found for this application")
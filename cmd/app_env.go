@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/kernel/cli/pkg/appenv"
+	"github.com/kernel/cli/pkg/util"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// AppEnvCmd manages environment variables kept locally per app and applied
+// by "kernel deploy".
+type AppEnvCmd struct {
+	store *appenv.Store
+	// prompt asks for the value of a key given without one.
+	prompt func(key string) (string, error)
+}
+
+type AppEnvSetInput struct {
+	App string
+	// Pairs are KEY=VALUE, or a bare KEY whose value is prompted for.
+	Pairs    []string
+	EnvFiles []string
+	Secret   bool
+	// AllowPlaintext keeps secrets in the app env file when the keychain
+	// can't store them, instead of failing.
+	AllowPlaintext bool
+}
+
+// Set stores the variables for the app. Values from env files are applied
+// first, so pairs given on the command line win.
+func (a AppEnvCmd) Set(in AppEnvSetInput) error {
+	vars := map[string]string{}
+	for _, envFile := range in.EnvFiles {
+		fileVars, err := godotenv.Read(envFile)
+		if err != nil {
+			return fmt.Errorf("failed to read env file %s: %w", envFile, err)
+		}
+		for k, v := range fileVars {
+			vars[k] = v
+		}
+	}
+	for _, pair := range in.Pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			if err := appenv.ValidateKey(key); err != nil {
+				return err
+			}
+			v, err := a.prompt(key)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			value = v
+		}
+		vars[key] = value
+	}
+	if len(vars) == 0 {
+		return fmt.Errorf("nothing to set: pass KEY=VALUE pairs or --env-file")
+	}
+	store := *a.store
+	store.AllowPlaintextSecrets = in.AllowPlaintext
+	plaintext, err := store.Set(in.App, vars, in.Secret)
+	if err != nil {
+		if in.Secret && !in.AllowPlaintext {
+			return fmt.Errorf("%w (pass --allow-plaintext to keep secrets in %s instead)", err, store.Path)
+		}
+		return err
+	}
+	if len(plaintext) > 0 {
+		pterm.Warning.Printf("The OS keychain is unavailable; %s stored in plaintext in %s\n", strings.Join(plaintext, ", "), store.Path)
+	}
+
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kind := "variable(s)"
+	if in.Secret {
+		kind = "secret(s)"
+	}
+	pterm.Success.Printf("Set %d %s for app '%s': %s\n", len(keys), kind, in.App, strings.Join(keys, ", "))
+	pterm.Info.Println("They apply from the next deploy of the app")
+	return nil
+}
+
+type AppEnvListInput struct {
+	App    string
+	Output string
+}
+
+// List prints the app's variables. Secret values are never shown.
+func (a AppEnvCmd) List(in AppEnvListInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	vars, err := a.store.List(in.App)
+	if err != nil {
+		return err
+	}
+	if in.Output == "json" {
+		if vars == nil {
+			vars = []appenv.Var{}
+		}
+		return util.PrintJSON(vars)
+	}
+	if len(vars) == 0 {
+		pterm.Info.Printf("No environment variables set for app '%s'\n", in.App)
+		return nil
+	}
+	rows := pterm.TableData{{"Key", "Value", "Updated At"}}
+	for _, v := range vars {
+		value := v.Value
+		if v.Secret {
+			value = "(secret)"
+		}
+		rows = append(rows, []string{v.Key, value, util.FormatLocal(v.UpdatedAt)})
+	}
+	PrintTableNoPad(rows, true)
+	return nil
+}
+
+type AppEnvUnsetInput struct {
+	App  string
+	Keys []string
+}
+
+func (a AppEnvCmd) Unset(in AppEnvUnsetInput) error {
+	removed, err := a.store.Unset(in.App, in.Keys)
+	if err != nil {
+		return err
+	}
+	for _, key := range in.Keys {
+		if !slices.Contains(removed, key) {
+			pterm.Warning.Printf("%s is not set for app '%s'\n", key, in.App)
+		}
+	}
+	if len(removed) > 0 {
+		pterm.Success.Printf("Unset %s for app '%s'\n", strings.Join(removed, ", "), in.App)
+	}
+	return nil
+}
+
+// appNamePattern finds the name an entrypoint registers its app under, as in
+// kernel.App("name") in Python or kernel.app('name') in TypeScript.
+var appNamePattern = regexp.MustCompile(`\b[Aa]pp\(\s*["']([^"']+)["']`)
+
+// detectAppName returns the app name registered in the entrypoint file, or ""
+// if it can't be found.
+func detectAppName(entrypoint string) string {
+	b, err := os.ReadFile(entrypoint)
+	if err != nil {
+		return ""
+	}
+	m := appNamePattern.FindSubmatch(b)
+	if m == nil {
+		return ""
+	}
+	return string(m[1])
+}
+
+// appEnvScope is the scope app env is stored and applied under for cmd: the
+// primary API endpoint and the selected project, which is how a context or
+// --project picks where an app is deployed. Apps with the same name elsewhere
+// don't get each other's variables.
+func appEnvScope(cmd *cobra.Command) string {
+	project, _ := cmd.Flags().GetString("project")
+	scope := util.GetBaseURL()
+	if project = resolveProjectSelection(project); project != "" {
+		scope += " project=" + project
+	}
+	return scope
+}
+
+// applyDefaultAppEnv applies the app's variables stored under scope in the
+// default store to envVars (see applyStoredAppEnv). Every deploy path calls
+// it, so an app gets the same environment however it is deployed.
+func applyDefaultAppEnv(scope, app string, envVars map[string]string, output string) error {
+	if app == "" {
+		return nil
+	}
+	store, err := appenv.DefaultStore(scope)
+	if err != nil {
+		return err
+	}
+	return applyStoredAppEnv(store, app, envVars, output)
+}
+
+// applyStoredAppEnv adds the variables stored with "kernel app env" for app
+// to envVars, without overriding ones given with --env or --env-file.
+func applyStoredAppEnv(store *appenv.Store, app string, envVars map[string]string, output string) error {
+	if app == "" {
+		return nil
+	}
+	stored, err := store.Values(app)
+	if err != nil {
+		return err
+	}
+	var applied []string
+	for k, v := range stored {
+		if _, ok := envVars[k]; ok {
+			continue
+		}
+		envVars[k] = v
+		applied = append(applied, k)
+	}
+	if len(applied) > 0 && output != "json" {
+		sort.Strings(applied)
+		pterm.Info.Printf("Applying stored environment for app '%s': %s\n", app, strings.Join(applied, ", "))
+	}
+	return nil
+}
+
+var appEnvCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Manage environment variables applied when an app is deployed",
+	Long: "Stores environment variables for an app on this machine. \"kernel deploy\", \"kernel deploy github --app\" and \"kernel invoke --app-dir\" apply them to every deploy of the app, " +
+		"under any given with --env or --env-file. Values set with --secret are kept in the OS keychain and never shown again. " +
+		"Variables are kept separately for each API endpoint and project (from --context, --project or KERNEL_PROJECT), and only apply to deploys there.",
+}
+
+var appEnvSetCmd = &cobra.Command{
+	Use:   "set <app_name> [KEY=VALUE | KEY]...",
+	Short: "Set environment variables for an app",
+	Long:  "Sets environment variables for an app. A KEY given without a value is prompted for, which keeps it out of shell history.",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runAppEnvSet,
+}
+
+var appEnvListCmd = &cobra.Command{
+	Use:   "list <app_name>",
+	Short: "List an app's environment variables",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runAppEnvList,
+}
+
+var appEnvUnsetCmd = &cobra.Command{
+	Use:   "unset <app_name> <KEY>...",
+	Short: "Remove environment variables from an app",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runAppEnvUnset,
+}
+
+func init() {
+	appEnvSetCmd.Flags().Bool("secret", false, "Keep the values in the OS keychain; they are applied on deploy but never shown")
+	appEnvSetCmd.Flags().Bool("allow-plaintext", false, "With --secret, keep the values in the app env file if the OS keychain is unavailable")
+	appEnvSetCmd.Flags().StringArray("env-file", []string{}, "Read variables from a file (.env format). May be specified multiple times")
+	addJSONOutputFlag(appEnvListCmd)
+
+	appEnvCmd.AddCommand(appEnvSetCmd)
+	appEnvCmd.AddCommand(appEnvListCmd)
+	appEnvCmd.AddCommand(appEnvUnsetCmd)
+	appCmd.AddCommand(appEnvCmd)
+}
+
+func newAppEnvCmd(cmd *cobra.Command) (AppEnvCmd, error) {
+	store, err := appenv.DefaultStore(appEnvScope(cmd))
+	if err != nil {
+		return AppEnvCmd{}, err
+	}
+	return AppEnvCmd{store: store, prompt: promptAppEnvValue}, nil
+}
+
+func promptAppEnvValue(key string) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("stdin is not a terminal; pass %s=VALUE instead", key)
+	}
+	return pterm.DefaultInteractiveTextInput.WithMask("*").Show(key)
+}
+
+func runAppEnvSet(cmd *cobra.Command, args []string) error {
+	secret, _ := cmd.Flags().GetBool("secret")
+	envFiles, _ := cmd.Flags().GetStringArray("env-file")
+	allowPlaintext, _ := cmd.Flags().GetBool("allow-plaintext")
+	a, err := newAppEnvCmd(cmd)
+	if err != nil {
+		return err
+	}
+	return a.Set(AppEnvSetInput{App: args[0], Pairs: args[1:], EnvFiles: envFiles, Secret: secret, AllowPlaintext: allowPlaintext})
+}
+
+func runAppEnvList(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	a, err := newAppEnvCmd(cmd)
+	if err != nil {
+		return err
+	}
+	return a.List(AppEnvListInput{App: args[0], Output: output})
+}
+
+func runAppEnvUnset(cmd *cobra.Command, args []string) error {
+	a, err := newAppEnvCmd(cmd)
+	if err != nil {
+		return err
+	}
+	return a.Unset(AppEnvUnsetInput{App: args[0], Keys: args[1:]})
+}
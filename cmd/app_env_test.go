@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kernel/cli/pkg/appenv"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+)
+
+func newTestAppEnvCmd(t *testing.T) AppEnvCmd {
+	keyring.MockInit()
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	store := &appenv.Store{Path: filepath.Join(t.TempDir(), "app-env.json"), Now: func() time.Time { return now }}
+	return AppEnvCmd{store: store, prompt: func(key string) (string, error) {
+		return "", errors.New("unexpected prompt")
+	}}
+}
+
+func TestAppEnvSet_ListHidesSecrets(t *testing.T) {
+	a := newTestAppEnvCmd(t)
+	envFile := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(envFile, []byte("REGION=eu\nDEBUG=1\n"), 0o600))
+	a.prompt = func(key string) (string, error) { return "tok-" + key, nil }
+
+	capturePtermOutput(t)
+	require.NoError(t, a.Set(AppEnvSetInput{App: "my-app", Pairs: []string{"REGION=us"}, EnvFiles: []string{envFile}}))
+	require.NoError(t, a.Set(AppEnvSetInput{App: "my-app", Pairs: []string{"API_TOKEN"}, Secret: true}))
+
+	out := captureStdout(t, func() {
+		require.NoError(t, a.List(AppEnvListInput{App: "my-app", Output: "json"}))
+	})
+	var vars []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(out), &vars))
+	require.Len(t, vars, 3)
+	assert.Equal(t, "API_TOKEN", vars[0]["key"])
+	assert.Equal(t, true, vars[0]["secret"])
+	assert.NotContains(t, vars[0], "value")
+	assert.Equal(t, "us", vars[2]["value"])
+
+	values, err := a.store.Values("my-app")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"API_TOKEN": "tok-API_TOKEN", "DEBUG": "1", "REGION": "us"}, values)
+}
+
+func TestAppEnvSet_RequiresSomething(t *testing.T) {
+	a := newTestAppEnvCmd(t)
+	assert.EqualError(t, a.Set(AppEnvSetInput{App: "my-app"}), "nothing to set: pass KEY=VALUE pairs or --env-file")
+}
+
+func TestAppEnvSet_SecretNeedsKeychainOrAllowPlaintext(t *testing.T) {
+	a := newTestAppEnvCmd(t)
+	keyring.MockInitWithError(errors.New("no keychain"))
+	buf := capturePtermOutput(t)
+
+	err := a.Set(AppEnvSetInput{App: "my-app", Pairs: []string{"TOKEN=s3cret"}, Secret: true})
+	assert.ErrorContains(t, err, "pass --allow-plaintext")
+
+	require.NoError(t, a.Set(AppEnvSetInput{App: "my-app", Pairs: []string{"TOKEN=s3cret"}, Secret: true, AllowPlaintext: true}))
+	assert.Contains(t, buf.String(), "TOKEN stored in plaintext")
+}
+
+func TestAppEnvUnset_WarnsAboutMissingKeys(t *testing.T) {
+	a := newTestAppEnvCmd(t)
+	buf := capturePtermOutput(t)
+	require.NoError(t, a.Set(AppEnvSetInput{App: "my-app", Pairs: []string{"A=1", "B=2"}}))
+
+	require.NoError(t, a.Unset(AppEnvUnsetInput{App: "my-app", Keys: []string{"A", "C"}}))
+	assert.Contains(t, buf.String(), "C is not set for app 'my-app'")
+	assert.Contains(t, buf.String(), "Unset A for app 'my-app'")
+
+	values, err := a.store.Values("my-app")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"B": "2"}, values)
+}
+
+func TestApplyStoredAppEnv_ExplicitValuesWin(t *testing.T) {
+	a := newTestAppEnvCmd(t)
+	capturePtermOutput(t)
+	_, err := a.store.Set("my-app", map[string]string{"REGION": "us", "TOKEN": "stored"}, true)
+	require.NoError(t, err)
+
+	envVars := map[string]string{"TOKEN": "from-flag"}
+	require.NoError(t, applyStoredAppEnv(a.store, "my-app", envVars, ""))
+	assert.Equal(t, map[string]string{"REGION": "us", "TOKEN": "from-flag"}, envVars)
+
+	envVars = map[string]string{}
+	require.NoError(t, applyStoredAppEnv(a.store, "", envVars, ""))
+	assert.Empty(t, envVars)
+}
+
+func TestDetectAppName(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) string {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(p, []byte(src), 0o600))
+		return p
+	}
+	assert.Equal(t, "python-basic", detectAppName(write("main.py", "import kernel\napp = kernel.App(\"python-basic\")\n")))
+	assert.Equal(t, "ts-basic", detectAppName(write("index.ts", "const app = kernel.app('ts-basic');\n")))
+	assert.Equal(t, "", detectAppName(write("other.ts", "console.log('hi')\n")))
+	assert.Equal(t, "", detectAppName(filepath.Join(dir, "missing.ts")))
+}
+
+func TestApplyDefaultAppEnv(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	keyring.MockInit()
+	capturePtermOutput(t)
+	store, err := appenv.DefaultStore("https://api.example.com")
+	require.NoError(t, err)
+	_, err = store.Set("my-app", map[string]string{"REGION": "us"}, false)
+	require.NoError(t, err)
+
+	envVars := map[string]string{}
+	require.NoError(t, applyDefaultAppEnv("https://api.example.com", "my-app", envVars, ""))
+	assert.Equal(t, map[string]string{"REGION": "us"}, envVars)
+
+	envVars = map[string]string{}
+	require.NoError(t, applyDefaultAppEnv("https://api.example.com project=staging", "my-app", envVars, ""))
+	assert.Empty(t, envVars, "variables stored for another project aren't applied")
+}
+
+func TestAppEnvScope(t *testing.T) {
+	t.Setenv("KERNEL_BASE_URL", "https://api.example.com/,https://eu.example.com")
+	t.Setenv("KERNEL_PROJECT", "")
+	cmd := &cobra.Command{}
+	cmd.Flags().String("project", "", "")
+	assert.Equal(t, "https://api.example.com", appEnvScope(cmd))
+
+	require.NoError(t, cmd.Flags().Set("project", "proj_1"))
+	assert.Equal(t, "https://api.example.com project=proj_1", appEnvScope(cmd))
+}
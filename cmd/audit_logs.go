@@ -106,6 +106,7 @@ func (c AuditLogsCmd) Search(ctx context.Context, in AuditLogsSearchInput) error
 	if in.Output == "json" {
 		return util.PrintPrettyJSONSlice(entries)
 	}
+	util.WarnDecodeDrift(entries)
 
 	if len(entries) == 0 {
 		pterm.Info.Println("No audit log entries found")
@@ -0,0 +1,359 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	discoverRequestTimeout = 10 * time.Second
+	discoverMaxBody        = 512 << 10
+	discoverConcurrency    = 6
+	// discoverMaxLinks bounds how many sign-in links found on the home page
+	// are probed in addition to the common paths.
+	discoverMaxLinks = 5
+	// discoverAutoMinScore is the lowest score `create --login-url auto`
+	// accepts; below it the candidates are too weak to pick one blindly.
+	discoverAutoMinScore = 0.5
+)
+
+// discoverLoginPaths are the paths sites most often serve their login page on.
+var discoverLoginPaths = []string{
+	"/login", "/signin", "/sign-in", "/log-in", "/sign_in",
+	"/account/login", "/accounts/login", "/auth/login", "/user/login",
+	"/users/sign_in", "/session/new", "/sso",
+}
+
+// discoverWellKnowns advertise an SSO authorization endpoint.
+var discoverWellKnowns = []string{
+	"/.well-known/openid-configuration",
+	"/.well-known/oauth-authorization-server",
+}
+
+var (
+	discoverPasswordInput = regexp.MustCompile(`(?i)<input[^>]+type\s*=\s*["']?password`)
+	discoverUserInput     = regexp.MustCompile(`(?i)<input[^>]+(?:type\s*=\s*["']?email|(?:name|id|autocomplete)\s*=\s*["']?(?:user(?:name)?|email|login|identifier))`)
+	discoverLoginPath     = regexp.MustCompile(`(?i)/(?:log-?in|sign-?in|sign_in|signon|auth|session|sso|oauth2?/authorize)\b`)
+	discoverSignInText    = regexp.MustCompile(`(?i)\b(?:sign|log)[\s-]?in\b`)
+	discoverHref          = regexp.MustCompile(`(?i)href\s*=\s*["']([^"'#]+)["']`)
+)
+
+// loginURLCandidate is one URL that may be a domain's login page.
+type loginURLCandidate struct {
+	URL string `json:"url"`
+	// Score is a 0-1 confidence that URL is the login page.
+	Score    float64  `json:"score"`
+	Status   int      `json:"status"`
+	Evidence []string `json:"evidence"`
+}
+
+type AuthConnectionDiscoverInput struct {
+	Domain string
+	Limit  int
+	Output string
+}
+
+// Discover probes a domain for its login page and prints the candidates,
+// best first.
+func (c AuthConnectionCmd) Discover(ctx context.Context, in AuthConnectionDiscoverInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if in.Domain == "" {
+		return fmt.Errorf("--domain is required")
+	}
+	if in.Output != "json" {
+		pterm.Info.Printf("Probing %s for login pages...\n", in.Domain)
+	}
+	candidates, err := discoverLoginURLs(ctx, c.httpClient(), in.Domain)
+	if err != nil {
+		return err
+	}
+	if in.Limit > 0 && len(candidates) > in.Limit {
+		candidates = candidates[:in.Limit]
+	}
+
+	if in.Output == "json" {
+		return util.PrintJSON(candidates)
+	}
+	if len(candidates) == 0 {
+		pterm.Warning.Println("No login page found; pass --login-url explicitly or let discovery run server-side")
+		return nil
+	}
+	rows := pterm.TableData{{"Score", "URL", "Evidence"}}
+	for _, cand := range candidates {
+		rows = append(rows, []string{fmt.Sprintf("%.2f", cand.Score), cand.URL, strings.Join(cand.Evidence, ", ")})
+	}
+	PrintTableNoPad(rows, true)
+	if candidates[0].Score >= discoverAutoMinScore {
+		pterm.Info.Printf("Use it with: kernel auth connections create --domain %s --login-url %s ...\n", in.Domain, candidates[0].URL)
+	}
+	return nil
+}
+
+func (c AuthConnectionCmd) httpClient() *http.Client {
+	if c.http != nil {
+		return c.http
+	}
+	return &http.Client{Timeout: discoverRequestTimeout}
+}
+
+// resolveAutoLoginURL picks the best discovered login URL for
+// `create --login-url auto`.
+func (c AuthConnectionCmd) resolveAutoLoginURL(ctx context.Context, domain string, quiet bool) (string, error) {
+	candidates, err := discoverLoginURLs(ctx, c.httpClient(), domain)
+	if err != nil {
+		return "", err
+	}
+	if len(candidates) == 0 || candidates[0].Score < discoverAutoMinScore {
+		return "", fmt.Errorf("could not find a login page on %s with confidence; run 'kernel auth connections discover --domain %s' and pass --login-url explicitly", domain, domain)
+	}
+	if !quiet {
+		pterm.Info.Printf("Discovered login URL %s (score %.2f)\n", candidates[0].URL, candidates[0].Score)
+	}
+	return candidates[0].URL, nil
+}
+
+// discoverLoginURLs probes domain's home page, common login paths, sign-in
+// links on the home page and SSO well-knowns, and returns the pages that
+// look like a login page, best first. domain may include a scheme; it
+// defaults to https.
+func discoverLoginURLs(ctx context.Context, client *http.Client, domain string) ([]loginURLCandidate, error) {
+	base, err := discoverBaseURL(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mu    sync.Mutex
+		found = map[string]loginURLCandidate{}
+	)
+	record := func(cand loginURLCandidate) {
+		if cand.Score <= 0 {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if prev, ok := found[cand.URL]; !ok || cand.Score > prev.Score {
+			found[cand.URL] = cand
+		}
+	}
+
+	// The home page goes first: its sign-in links are probed alongside the
+	// common paths.
+	home, homeBody, err := discoverFetch(ctx, client, base.String())
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	targets := make([]string, 0, len(discoverLoginPaths)+discoverMaxLinks)
+	if err == nil {
+		record(home)
+		targets = append(targets, discoverLinks(home.URL, homeBody)...)
+	}
+	for _, p := range discoverLoginPaths {
+		targets = append(targets, base.ResolveReference(&url.URL{Path: p}).String())
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(discoverConcurrency)
+	for _, target := range targets {
+		g.Go(func() error {
+			if cand, _, err := discoverFetch(gctx, client, target); err == nil {
+				record(cand)
+			}
+			return nil
+		})
+	}
+	for _, p := range discoverWellKnowns {
+		target := base.ResolveReference(&url.URL{Path: p}).String()
+		g.Go(func() error {
+			if cand, ok := discoverSSOEndpoint(gctx, client, target); ok {
+				record(cand)
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	candidates := make([]loginURLCandidate, 0, len(found))
+	for _, cand := range found {
+		candidates = append(candidates, cand)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return len(candidates[i].URL) < len(candidates[j].URL)
+	})
+	return candidates, nil
+}
+
+func discoverBaseURL(domain string) (*url.URL, error) {
+	raw := strings.TrimSpace(domain)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid domain %q", domain)
+	}
+	return &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/"}, nil
+}
+
+// discoverFetch GETs target, following redirects, and scores the page it
+// lands on.
+func discoverFetch(ctx context.Context, client *http.Client, target string) (loginURLCandidate, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return loginURLCandidate{}, "", err
+	}
+	req.Header.Set("User-Agent", "kernel-cli/"+util.FirstOrDash(metadata.Version, "dev"))
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	resp, err := client.Do(req)
+	if err != nil {
+		return loginURLCandidate{}, "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, discoverMaxBody))
+
+	final := resp.Request.URL
+	final.Fragment = ""
+	score, evidence := scoreLoginPage(final, resp.StatusCode, resp.Header, string(body))
+	if final.String() != target {
+		evidence = append(evidence, "redirected from "+target)
+	}
+	return loginURLCandidate{URL: final.String(), Score: score, Status: resp.StatusCode, Evidence: evidence}, string(body), nil
+}
+
+// scoreLoginPage rates how likely a fetched page is to be a login page.
+func scoreLoginPage(u *url.URL, status int, header http.Header, body string) (float64, []string) {
+	if status == http.StatusUnauthorized && header.Get("WWW-Authenticate") != "" {
+		return 0.3, []string{"HTTP auth challenge"}
+	}
+	if status >= 400 {
+		return 0, nil
+	}
+	var score float64
+	var evidence []string
+	if discoverPasswordInput.MatchString(body) {
+		score += 0.55
+		evidence = append(evidence, "password field")
+	}
+	if discoverUserInput.MatchString(body) {
+		score += 0.15
+		evidence = append(evidence, "username field")
+	}
+	if discoverLoginPath.MatchString(u.Path) {
+		score += 0.2
+		evidence = append(evidence, "login path")
+	}
+	if score > 0 && discoverSignInText.MatchString(body) {
+		score += 0.1
+		evidence = append(evidence, "sign-in text")
+	}
+	if score > 1 {
+		score = 1
+	}
+	return score, evidence
+}
+
+// discoverLinks returns up to discoverMaxLinks sign-in links on a page.
+func discoverLinks(pageURL, body string) []string {
+	page, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	var links []string
+	seen := map[string]bool{}
+	for _, m := range discoverHref.FindAllStringSubmatch(body, -1) {
+		ref, err := url.Parse(strings.TrimSpace(m[1]))
+		if err != nil {
+			continue
+		}
+		abs := page.ResolveReference(ref)
+		if (abs.Scheme != "http" && abs.Scheme != "https") || !discoverLoginPath.MatchString(abs.Path) {
+			continue
+		}
+		abs.Fragment = ""
+		if s := abs.String(); !seen[s] {
+			seen[s] = true
+			links = append(links, s)
+		}
+		if len(links) == discoverMaxLinks {
+			break
+		}
+	}
+	return links
+}
+
+// discoverSSOEndpoint reads an OpenID/OAuth well-known document and reports
+// its authorization endpoint. It ranks below a login form: it is where SSO
+// starts, not a page the login flow can fill in directly.
+func discoverSSOEndpoint(ctx context.Context, client *http.Client, target string) (loginURLCandidate, bool) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return loginURLCandidate{}, false
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return loginURLCandidate{}, false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return loginURLCandidate{}, false
+	}
+	var doc struct {
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, discoverMaxBody)).Decode(&doc); err != nil || doc.AuthorizationEndpoint == "" {
+		return loginURLCandidate{}, false
+	}
+	return loginURLCandidate{
+		URL:      doc.AuthorizationEndpoint,
+		Score:    0.35,
+		Status:   resp.StatusCode,
+		Evidence: []string{"SSO authorization endpoint from " + target},
+	}, true
+}
+
+var authConnectionsDiscoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "Find a domain's login page",
+	Long: `Probe a domain's home page, common login paths and SSO well-known documents
+from this machine, and list the pages that look like a login page with a 0-1
+confidence score. Pass the best match to 'create --login-url', or use
+'create --login-url auto' to discover and pick it in one step.`,
+	Args: cobra.NoArgs,
+	RunE: runAuthConnectionsDiscover,
+}
+
+func init() {
+	addJSONOutputFlag(authConnectionsDiscoverCmd)
+	authConnectionsDiscoverCmd.Flags().String("domain", "", "Domain to probe, e.g. example.com (required)")
+	authConnectionsDiscoverCmd.Flags().Int("limit", 5, "Maximum number of candidates to show (0 for all)")
+	authConnectionsCmd.AddCommand(authConnectionsDiscoverCmd)
+}
+
+func runAuthConnectionsDiscover(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	domain, _ := cmd.Flags().GetString("domain")
+	limit, _ := cmd.Flags().GetInt("limit")
+	return AuthConnectionCmd{}.Discover(cmd.Context(), AuthConnectionDiscoverInput{Domain: domain, Limit: limit, Output: output})
+}
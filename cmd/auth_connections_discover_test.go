@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLoginSite(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`<html><a href="/account/sign-in?next=/">Sign in</a><a href="/pricing">Pricing</a></html>`))
+	})
+	mux.HandleFunc("/account/sign-in", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<form><input type="email" name="email"><input type="password" name="pw"><button>Sign in</button></form>`))
+	})
+	// /login redirects to the real page.
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/account/sign-in?next=/", http.StatusFound)
+	})
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"authorization_endpoint":"https://idp.example/authorize"}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDiscoverLoginURLs_RanksLoginFormFirst(t *testing.T) {
+	srv := newLoginSite(t)
+
+	candidates, err := discoverLoginURLs(context.Background(), srv.Client(), srv.URL)
+	require.NoError(t, err)
+	require.NotEmpty(t, candidates)
+
+	best := candidates[0]
+	assert.Equal(t, srv.URL+"/account/sign-in?next=/", best.URL)
+	assert.InDelta(t, 1.0, best.Score, 0.001)
+	assert.Contains(t, best.Evidence, "password field")
+
+	var sso *loginURLCandidate
+	for i := range candidates {
+		if candidates[i].URL == "https://idp.example/authorize" {
+			sso = &candidates[i]
+		}
+		assert.NotEqual(t, srv.URL+"/", candidates[i].URL, "the home page has no login form")
+	}
+	require.NotNil(t, sso, "the OpenID authorization endpoint is a candidate")
+	assert.Less(t, sso.Score, best.Score)
+}
+
+func TestAuthConnectionsCreate_LoginURLAuto(t *testing.T) {
+	srv := newLoginSite(t)
+	setupStdoutCapture(t)
+
+	var got kernel.AuthConnectionNewParams
+	fake := &FakeAuthConnectionService{
+		NewFunc: func(ctx context.Context, body kernel.AuthConnectionNewParams, opts ...option.RequestOption) (*kernel.ManagedAuth, error) {
+			got = body
+			return &kernel.ManagedAuth{ID: "conn-1"}, nil
+		},
+	}
+	c := AuthConnectionCmd{svc: fake, http: srv.Client()}
+	err := c.Create(context.Background(), AuthConnectionCreateInput{Domain: srv.URL, ProfileName: "p", LoginURL: "auto"})
+	require.NoError(t, err)
+	assert.Equal(t, srv.URL+"/account/sign-in?next=/", got.ManagedAuthCreateRequest.LoginURL.Value)
+}
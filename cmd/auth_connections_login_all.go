@@ -0,0 +1,384 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kernel/cli/pkg/lock"
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	defaultLoginAllConcurrency = 3
+	defaultLoginAllTimeout     = 5 * time.Minute
+	// loginAllHandoffGrace is how long a flow may wait on input before it is
+	// handed to a human. Linked credentials are filled in server-side, so a
+	// brief AWAITING_INPUT is normal on the way through.
+	loginAllHandoffGrace = 15 * time.Second
+)
+
+// Login-all outcomes, in the order the summary lists them.
+const (
+	loginAllNeedsHuman = "needs human"
+	loginAllFailed     = "failed"
+	loginAllTimedOut   = "timed out"
+	loginAllLoggedIn   = "logged in"
+)
+
+type AuthConnectionLoginAllInput struct {
+	// Filters are key=value pairs on status, domain and profile.
+	Filters      []string
+	Concurrency  int
+	Timeout      time.Duration
+	PollInterval time.Duration
+	// HandoffGrace overrides loginAllHandoffGrace.
+	HandoffGrace time.Duration
+	Output       string
+}
+
+// loginAllFilter selects connections for login-all. Status defaults to
+// NEEDS_AUTH so authenticated connections aren't logged in again.
+type loginAllFilter struct {
+	Status  string
+	Domain  string
+	Profile string
+}
+
+func parseLoginAllFilters(specs []string) (loginAllFilter, error) {
+	f := loginAllFilter{Status: string(kernel.ManagedAuthStatusNeedsAuth)}
+	for _, spec := range specs {
+		key, value, ok := strings.Cut(spec, "=")
+		if !ok || value == "" {
+			return f, fmt.Errorf("invalid --filter %q (expected key=value)", spec)
+		}
+		switch strings.ToLower(key) {
+		case "status":
+			f.Status = strings.ToUpper(value)
+			switch kernel.ManagedAuthStatus(f.Status) {
+			case kernel.ManagedAuthStatusNeedsAuth, kernel.ManagedAuthStatusAuthenticated, "ANY":
+			default:
+				return f, fmt.Errorf("invalid status filter %q (must be NEEDS_AUTH, AUTHENTICATED or any)", value)
+			}
+		case "domain":
+			f.Domain = value
+		case "profile", "profile_name":
+			f.Profile = value
+		default:
+			return f, fmt.Errorf("unknown --filter key %q (must be status, domain or profile)", key)
+		}
+	}
+	return f, nil
+}
+
+// loginAllResult is how one connection's login ended.
+type loginAllResult struct {
+	ID          string `json:"id"`
+	Domain      string `json:"domain"`
+	ProfileName string `json:"profile_name"`
+	Outcome     string `json:"outcome"`
+	// Credential is the linked credential Kernel fills in, if any.
+	Credential string                       `json:"credential,omitempty"`
+	FlowStatus kernel.ManagedAuthFlowStatus `json:"flow_status,omitempty"`
+	FlowStep   kernel.ManagedAuthFlowStep   `json:"flow_step,omitempty"`
+	Action     string                       `json:"action,omitempty"`
+	HostedURL  string                       `json:"hosted_url,omitempty"`
+	Error      string                       `json:"error,omitempty"`
+}
+
+// LoginAll starts login flows for every matching connection and follows them
+// concurrently. Linked credentials are submitted by Kernel; flows that stop on
+// input no credential covers (usually MFA) are left running and listed with
+// their hosted URL for a human to finish.
+func (c AuthConnectionCmd) LoginAll(ctx context.Context, in AuthConnectionLoginAllInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	filter, err := parseLoginAllFilters(in.Filters)
+	if err != nil {
+		return err
+	}
+	if in.Concurrency <= 0 {
+		in.Concurrency = defaultLoginAllConcurrency
+	}
+	if in.Timeout <= 0 {
+		in.Timeout = defaultLoginAllTimeout
+	}
+	if in.PollInterval <= 0 {
+		in.PollInterval = 2 * time.Second
+	}
+	if in.HandoffGrace <= 0 {
+		in.HandoffGrace = loginAllHandoffGrace
+	}
+	jsonOutput := in.Output == "json"
+
+	conns, err := c.listLoginAllConnections(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if len(conns) == 0 {
+		if jsonOutput {
+			return util.PrintJSON([]loginAllResult{})
+		}
+		pterm.Info.Println("No matching auth connections")
+		return nil
+	}
+	if !jsonOutput {
+		pterm.Info.Printf("Logging in %d connection(s), %d at a time (Ctrl+C to stop following)...\n", len(conns), in.Concurrency)
+	}
+
+	results := make([]loginAllResult, len(conns))
+	var printMu sync.Mutex
+	var g errgroup.Group
+	g.SetLimit(in.Concurrency)
+	for i, conn := range conns {
+		g.Go(func() error {
+			results[i] = c.loginOne(ctx, conn, in, func(s authWatchState) {
+				if jsonOutput {
+					return
+				}
+				printMu.Lock()
+				defer printMu.Unlock()
+				printAuthWatchChange(s)
+			})
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return loginAllRank(results[i].Outcome) < loginAllRank(results[j].Outcome)
+	})
+	if jsonOutput {
+		if err := util.PrintJSON(results); err != nil {
+			return err
+		}
+	} else {
+		printLoginAllSummary(results)
+	}
+
+	var unfinished int
+	for _, r := range results {
+		if r.Outcome != loginAllLoggedIn {
+			unfinished++
+		}
+	}
+	if unfinished > 0 {
+		return fmt.Errorf("%d of %d connections are not logged in", unfinished, len(results))
+	}
+	return nil
+}
+
+// listLoginAllConnections pages through every connection matching filter.
+func (c AuthConnectionCmd) listLoginAllConnections(ctx context.Context, filter loginAllFilter) ([]kernel.ManagedAuth, error) {
+	const pageSize = 100
+	var conns []kernel.ManagedAuth
+	for offset := 0; ; offset += pageSize {
+		params := kernel.AuthConnectionListParams{
+			Limit:  kernel.Opt(int64(pageSize)),
+			Offset: kernel.Opt(int64(offset)),
+		}
+		if filter.Domain != "" {
+			params.Domain = kernel.Opt(filter.Domain)
+		}
+		if filter.Profile != "" {
+			params.ProfileName = kernel.Opt(filter.Profile)
+		}
+		page, err := c.svc.List(ctx, params)
+		if err != nil {
+			return nil, util.CleanedUpSdkError{Err: err}
+		}
+		if page == nil {
+			return conns, nil
+		}
+		for _, auth := range page.Items {
+			if filter.Status == "ANY" || string(auth.Status) == filter.Status {
+				conns = append(conns, auth)
+			}
+		}
+		if len(page.Items) < pageSize {
+			return conns, nil
+		}
+	}
+}
+
+// loginOne starts (or joins) one connection's login flow and polls it until
+// it finishes, needs a human or runs out of time. onChange sees every new
+// state.
+func (c AuthConnectionCmd) loginOne(ctx context.Context, conn kernel.ManagedAuth, in AuthConnectionLoginAllInput, onChange func(authWatchState)) loginAllResult {
+	res := loginAllResult{ID: conn.ID, Domain: conn.Domain, ProfileName: conn.ProfileName}
+	if conn.Credential.Name != "" || conn.Credential.Provider != "" {
+		res.Credential = util.FirstOrDash(conn.Credential.Name, conn.Credential.Provider)
+	}
+
+	// A flow that is already running is followed rather than restarted.
+	if conn.FlowStatus != kernel.ManagedAuthFlowStatusInProgress {
+		var profileLock *lock.Lock
+		if c.profileLocks != nil {
+			var err error
+			profileLock, err = c.profileLocks.Acquire(ctx, conn.ProfileName, lock.Options{TTL: defaultProfileLockTTL, Note: "kernel auth connections login-all " + conn.ID})
+			if err != nil {
+				res.Outcome, res.Error = loginAllFailed, err.Error()
+				return res
+			}
+		}
+		resp, err := c.svc.Login(ctx, conn.ID, kernel.AuthConnectionLoginParams{})
+		if err != nil {
+			if profileLock != nil {
+				c.profileLocks.Release(profileLock)
+			}
+			res.Outcome, res.Error = loginAllFailed, util.CleanedUpSdkError{Err: err}.Error()
+			return res
+		}
+		c.holdProfileLockForFlow(profileLock, resp)
+		res.HostedURL = resp.HostedURL
+	}
+
+	deadline := time.Now().Add(in.Timeout)
+	var last authWatchState
+	var waitingSince time.Time
+	for first := true; ; first = false {
+		auth, err := c.svc.Get(ctx, conn.ID)
+		s := newAuthWatchState(conn.ID, auth, err)
+		if first || !s.sameAs(last) {
+			onChange(s)
+		}
+		last = s
+		res.FlowStatus, res.FlowStep, res.Action, res.Error = s.FlowStatus, s.FlowStep, s.Action, s.Error
+		if auth != nil && auth.HostedURL != "" {
+			res.HostedURL = auth.HostedURL
+		}
+
+		switch {
+		case s.done && s.FlowStatus == kernel.ManagedAuthFlowStatusSuccess:
+			res.Outcome = loginAllLoggedIn
+			return res
+		case s.done:
+			res.Outcome = loginAllFailed
+			if res.Error == "" && s.FlowStatus != "" {
+				res.Error = "flow " + strings.ToLower(string(s.FlowStatus))
+			}
+			return res
+		case s.Action != "":
+			if waitingSince.IsZero() {
+				waitingSince = time.Now()
+			}
+			if time.Since(waitingSince) >= in.HandoffGrace {
+				res.Outcome = loginAllNeedsHuman
+				return res
+			}
+		default:
+			waitingSince = time.Time{}
+		}
+
+		if time.Now().After(deadline) {
+			res.Outcome = loginAllTimedOut
+			return res
+		}
+		select {
+		case <-ctx.Done():
+			res.Outcome, res.Error = loginAllTimedOut, "stopped following"
+			return res
+		case <-time.After(in.PollInterval):
+		}
+	}
+}
+
+func loginAllRank(outcome string) int {
+	switch outcome {
+	case loginAllNeedsHuman:
+		return 0
+	case loginAllFailed:
+		return 1
+	case loginAllTimedOut:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func printLoginAllSummary(results []loginAllResult) {
+	counts := map[string]int{}
+	data := pterm.TableData{{"ID", "Domain", "Profile", "Credential", "Result", "Next Step"}}
+	for _, r := range results {
+		counts[r.Outcome]++
+		next := "-"
+		switch r.Outcome {
+		case loginAllNeedsHuman:
+			next = r.Action
+			if r.HostedURL != "" && !strings.Contains(next, r.HostedURL) {
+				next = strings.TrimSpace(next + " " + r.HostedURL)
+			}
+		case loginAllFailed, loginAllTimedOut:
+			next = util.FirstOrDash(r.Error, string(r.FlowStep))
+		}
+		data = append(data, []string{r.ID, r.Domain, r.ProfileName, util.OrDash(r.Credential), r.Outcome, next})
+	}
+	PrintTableNoPad(data, true)
+
+	var parts []string
+	for _, outcome := range []string{loginAllLoggedIn, loginAllNeedsHuman, loginAllFailed, loginAllTimedOut} {
+		if n := counts[outcome]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, outcome))
+		}
+	}
+	summary := strings.Join(parts, ", ")
+	if counts[loginAllLoggedIn] == len(results) {
+		pterm.Success.Println(summary)
+		return
+	}
+	pterm.Warning.Println(summary)
+	if counts[loginAllNeedsHuman] > 0 {
+		pterm.Info.Println("Flows that need a human are still running; finish them from the hosted URL.")
+	}
+}
+
+var authConnectionsLoginAllCmd = &cobra.Command{
+	Use:   "login-all",
+	Short: "Start and follow login flows for many connections at once",
+	Long: `Start login flows for every connection matching --filter, follow them
+concurrently and summarize which ones still need a human.
+
+Kernel fills in linked credentials on its own. A flow that keeps waiting on
+input (usually MFA) or an external action is left running and reported with
+its hosted URL. Connections whose flow is already in progress are followed
+rather than restarted. The command exits non-zero unless every connection
+ends up logged in.`,
+	Example: `  kernel auth connections login-all
+  kernel auth connections login-all --filter status=NEEDS_AUTH --filter domain=github.com --concurrency 3`,
+	Args: cobra.NoArgs,
+	RunE: runAuthConnectionsLoginAll,
+}
+
+func init() {
+	authConnectionsLoginAllCmd.Flags().StringArray("filter", nil, "Select connections by status=NEEDS_AUTH|AUTHENTICATED|any, domain=<domain> or profile=<name> (repeatable; default status=NEEDS_AUTH)")
+	authConnectionsLoginAllCmd.Flags().Int("concurrency", defaultLoginAllConcurrency, "Maximum login flows to run at once")
+	authConnectionsLoginAllCmd.Flags().Duration("timeout", defaultLoginAllTimeout, "Stop following a connection's flow after this long")
+	addJSONOutputFlag(authConnectionsLoginAllCmd)
+	authConnectionsCmd.AddCommand(authConnectionsLoginAllCmd)
+}
+
+func runAuthConnectionsLoginAll(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	filters, _ := cmd.Flags().GetStringArray("filter")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	output, _ := cmd.Flags().GetString("output")
+
+	svc := client.Auth.Connections
+	profiles := client.Profiles
+	c := AuthConnectionCmd{svc: &svc, profileLocks: newProfileLocker(&profiles)}
+	return c.LoginAll(cmd.Context(), AuthConnectionLoginAllInput{
+		Filters:     filters,
+		Concurrency: concurrency,
+		Timeout:     timeout,
+		Output:      output,
+	})
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sync/atomic"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+)
+
+const magicLinkPollInterval = 5 * time.Second
+
+// magicLinkInbox is the subset of *mailbox.Mailbox the watcher uses.
+type magicLinkInbox interface {
+	Snapshot(ctx context.Context) (uint32, error)
+	FindLink(ctx context.Context, since uint32, pattern *regexp.Regexp) (string, uint32, error)
+}
+
+// magicLinkWatcher polls a mailbox while a flow waits on an external action
+// and opens the first sign-in link that arrives in the login browser.
+type magicLinkWatcher struct {
+	inbox      magicLinkInbox
+	pattern    *regexp.Regexp
+	svc        AuthConnectionService
+	playwright BrowserPlaywrightService
+	id         string
+	interval   time.Duration
+	// quiet suppresses progress messages (used with --output json).
+	quiet bool
+
+	// since is the first mailbox UID not yet scanned; only the polling
+	// goroutine touches it while one is running.
+	since  uint32
+	opened atomic.Bool
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// start records where the mailbox ends so mail that was already there, such
+// as the link from an earlier login, is never opened.
+func (w *magicLinkWatcher) start(ctx context.Context) error {
+	since, err := w.inbox.Snapshot(ctx)
+	if err != nil {
+		return fmt.Errorf("magic link mailbox: %w", err)
+	}
+	w.since = since
+	return nil
+}
+
+func (w *magicLinkWatcher) reset() {
+	w.stop()
+	w.opened.Store(false)
+}
+
+func (w *magicLinkWatcher) stop() {
+	if w.cancel != nil {
+		w.cancel()
+		<-w.done
+		w.cancel = nil
+	}
+}
+
+// Observe starts polling when the flow begins waiting on an external action
+// and stops once it moves on. Polling failures are reported as warnings and
+// never abort the stream.
+func (w *magicLinkWatcher) Observe(ctx context.Context, event kernel.AuthConnectionFollowResponseUnion) {
+	if event.Event != "managed_auth_state" {
+		return
+	}
+	if event.AsManagedAuthState().FlowStep != "AWAITING_EXTERNAL_ACTION" {
+		w.stop()
+		return
+	}
+	if w.cancel != nil || w.opened.Load() {
+		return
+	}
+	pollCtx, cancel := context.WithCancel(ctx)
+	w.cancel = cancel
+	w.done = make(chan struct{})
+	go w.poll(pollCtx)
+}
+
+func (w *magicLinkWatcher) poll(ctx context.Context) {
+	defer close(w.done)
+	if !w.quiet {
+		pterm.Info.Println("Waiting for a sign-in link by email...")
+	}
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		link, next, err := w.inbox.FindLink(ctx, w.since, w.pattern)
+		w.since = next
+		if err != nil && ctx.Err() == nil {
+			w.warn("Checking mailbox for a sign-in link failed: %v", err)
+		}
+		if link != "" {
+			if err := w.open(ctx, link); err != nil {
+				w.warn("Could not open the sign-in link: %v", err)
+			} else {
+				w.opened.Store(true)
+				return
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// open navigates the connection's login browser to link.
+func (w *magicLinkWatcher) open(ctx context.Context, link string) error {
+	auth, err := w.svc.Get(ctx, w.id)
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	if auth.BrowserSessionID == "" {
+		return fmt.Errorf("connection has no browser session")
+	}
+	quoted, err := json.Marshal(link)
+	if err != nil {
+		return err
+	}
+	res, err := executePlaywright(ctx, w.playwright, auth.BrowserSessionID, kernel.BrowserPlaywrightExecuteParams{
+		Code: fmt.Sprintf("await page.goto(%s);", quoted),
+	}, readOnlyPlaywrightRetries)
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	if !res.Success {
+		return fmt.Errorf("%s", util.FirstOrDash(res.Error, res.Stderr))
+	}
+	if !w.quiet {
+		pterm.Info.Println("Opened sign-in link from email in the login browser")
+	}
+	return nil
+}
+
+func (w *magicLinkWatcher) warn(format string, args ...any) {
+	p := pterm.Warning
+	if w.quiet {
+		p = *p.WithWriter(os.Stderr)
+	}
+	p.Printf(format+"\n", args...)
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"gopkg.in/yaml.v3"
+)
+
+// authRunMFATypes are the MFA option types a policy may auto-select. The API
+// also reports "password" and "switch" pseudo-options, which are never chosen
+// automatically.
+var authRunMFATypes = []string{"sms", "call", "email", "totp", "push"}
+
+// authRunPolicy bounds what `auth connections follow --policy` may do on the
+// user's behalf. The zero value automates nothing.
+type authRunPolicy struct {
+	// AutoMFATypes lists MFA methods that may be selected without a human.
+	AutoMFATypes []string `yaml:"auto_mfa_types"`
+	// MaxRetries is how many times a failed login is restarted.
+	MaxRetries int `yaml:"max_retries"`
+	// AutoClickSSO allows clicking the SSO button when it's the only one offered.
+	AutoClickSSO bool `yaml:"auto_click_sso"`
+	// FieldRetryBudget is how many times the same automated input may be
+	// resubmitted when the site bounces back to the step that asked for it.
+	FieldRetryBudget int `yaml:"field_retry_budget"`
+	// MaxRejectedSubmissions trips the circuit breaker once this many
+	// submissions are rejected across the run, counting website errors such
+	// as "Incorrect password". Zero means defaultMaxRejectedSubmissions.
+	MaxRejectedSubmissions int `yaml:"max_rejected_submissions"`
+	// RequireApprovalDomains need a human to confirm every automated action.
+	// Entries match the connection's domain exactly, or "*.example.com" matches
+	// example.com and all of its subdomains.
+	RequireApprovalDomains []string `yaml:"require_approval_domains"`
+}
+
+// defaultMaxRejectedSubmissions stays below the lockout threshold of most sites.
+const defaultMaxRejectedSubmissions = 3
+
+func (p *authRunPolicy) maxRejectedSubmissions() int {
+	if p.MaxRejectedSubmissions > 0 {
+		return p.MaxRejectedSubmissions
+	}
+	return defaultMaxRejectedSubmissions
+}
+
+func loadAuthRunPolicy(path string) (*authRunPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read policy: %w", err)
+	}
+	var p authRunPolicy
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse policy %s: %w", path, err)
+	}
+	for i, t := range p.AutoMFATypes {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if !slices.Contains(authRunMFATypes, t) {
+			return nil, fmt.Errorf("policy %s: invalid auto_mfa_types entry %q: must be one of %s", path, t, strings.Join(authRunMFATypes, ", "))
+		}
+		p.AutoMFATypes[i] = t
+	}
+	if p.MaxRetries < 0 || p.FieldRetryBudget < 0 || p.MaxRejectedSubmissions < 0 {
+		return nil, fmt.Errorf("policy %s: max_retries, field_retry_budget and max_rejected_submissions must be >= 0", path)
+	}
+	return &p, nil
+}
+
+func (p *authRunPolicy) requiresApproval(domain string) bool {
+	domain = strings.ToLower(domain)
+	for _, pattern := range p.RequireApprovalDomains {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if base, ok := strings.CutPrefix(pattern, "*."); ok {
+			if domain == base || strings.HasSuffix(domain, "."+base) {
+				return true
+			}
+		} else if domain == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// authRunAction is a submission the policy allows for the current flow step.
+type authRunAction struct {
+	key         string
+	description string
+	params      kernel.AuthConnectionSubmitParams
+}
+
+// nextAction picks what to submit for state, if anything. Steps that need
+// credentials or an ambiguous choice are always left to a human.
+func (p *authRunPolicy) nextAction(state kernel.AuthConnectionFollowResponseManagedAuthState) (authRunAction, bool) {
+	if state.FlowStep != "AWAITING_INPUT" || len(state.DiscoveredFields) > 0 {
+		return authRunAction{}, false
+	}
+	for _, want := range p.AutoMFATypes {
+		for _, opt := range state.MfaOptions {
+			if opt.Type == want {
+				return authRunAction{
+					key:         "mfa:" + opt.Type,
+					description: fmt.Sprintf("select MFA method %q", util.FirstOrDash(opt.Label, opt.Type)),
+					params: kernel.AuthConnectionSubmitParams{SubmitFieldsRequest: kernel.SubmitFieldsRequestParam{
+						MfaOptionID: kernel.Opt(opt.Type),
+					}},
+				}, true
+			}
+		}
+	}
+	if p.AutoClickSSO && len(state.MfaOptions) == 0 && len(state.PendingSSOButtons) == 1 {
+		btn := state.PendingSSOButtons[0]
+		return authRunAction{
+			key:         "sso:" + btn.Selector,
+			description: fmt.Sprintf("click SSO button %q", util.FirstOrDash(btn.Label, btn.Provider)),
+			params: kernel.AuthConnectionSubmitParams{SubmitFieldsRequest: kernel.SubmitFieldsRequestParam{
+				SSOButtonSelector: kernel.Opt(btn.Selector),
+			}},
+		}, true
+	}
+	return authRunAction{}, false
+}
+
+// errAuthCircuitOpen aborts a run whose submissions keep being rejected.
+var errAuthCircuitOpen = errors.New("possible wrong credential / lockout risk")
+
+// authPolicyRunner applies a policy to a connection's state stream across
+// every login attempt of one follow run.
+type authPolicyRunner struct {
+	policy  *authRunPolicy
+	svc     AuthConnectionService
+	id      string
+	domain  string
+	approve func(prompt string) bool
+	quiet   bool
+
+	// submissions counts automated submissions per action in this attempt;
+	// rejections count across attempts so retries can't reset the breaker.
+	submissions map[string]int
+	rejections  int
+	// lastKey is the action taken for the current step, so the server
+	// repeating a state isn't mistaken for the step bouncing back.
+	lastKey          string
+	lastWebsiteError string
+}
+
+// reset clears per-attempt state before a login attempt is followed.
+func (r *authPolicyRunner) reset() {
+	r.submissions = map[string]int{}
+	r.lastKey = ""
+	r.lastWebsiteError = ""
+}
+
+func (r *authPolicyRunner) Observe(ctx context.Context, event kernel.AuthConnectionFollowResponseUnion) error {
+	if event.Event != "managed_auth_state" {
+		return nil
+	}
+	state := event.AsManagedAuthState()
+
+	if state.WebsiteError != "" && state.WebsiteError != r.lastWebsiteError {
+		if err := r.reject(state.WebsiteError); err != nil {
+			return err
+		}
+	}
+	r.lastWebsiteError = state.WebsiteError
+
+	action, ok := r.policy.nextAction(state)
+	if !ok {
+		r.lastKey = ""
+		return nil
+	}
+	if action.key == r.lastKey {
+		return nil
+	}
+	r.lastKey = action.key
+
+	if n := r.submissions[action.key]; n > r.policy.FieldRetryBudget {
+		return fmt.Errorf("%w: gave up after the site asked to %s %d times", errAuthCircuitOpen, action.description, n+1)
+	}
+
+	if r.policy.requiresApproval(r.domain) {
+		prompt := fmt.Sprintf("Policy requires approval for %s: %s?", r.domain, action.description)
+		if r.approve == nil || !r.approve(prompt) {
+			r.info("Not approved; waiting for a human to %s\n", action.description)
+			return nil
+		}
+	}
+	r.info("Policy: %s\n", action.description)
+	r.submissions[action.key]++
+	resp, err := r.svc.Submit(ctx, r.id, action.params)
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	if resp != nil && !resp.Accepted {
+		return r.reject("submission to " + action.description + " was not accepted")
+	}
+	return nil
+}
+
+// reject records a rejected submission and trips the breaker once the
+// policy's limit is reached.
+func (r *authPolicyRunner) reject(reason string) error {
+	r.rejections++
+	if r.rejections >= r.policy.maxRejectedSubmissions() {
+		return fmt.Errorf("%w: %d rejected submissions (last: %s); stopping before the account is locked", errAuthCircuitOpen, r.rejections, reason)
+	}
+	r.info("Submission rejected (%d/%d before stopping): %s\n", r.rejections, r.policy.maxRejectedSubmissions(), reason)
+	return nil
+}
+
+func (r *authPolicyRunner) info(format string, args ...any) {
+	if !r.quiet {
+		pterm.Info.Printf(format, args...)
+	}
+}
+
+func confirmAuthPolicyAction(prompt string) bool {
+	ok, _ := pterm.DefaultInteractiveConfirm.WithDefaultText(prompt).Show()
+	return ok
+}
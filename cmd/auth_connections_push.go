@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+)
+
+const defaultPushTimeout = 2 * time.Minute
+
+var errPushTimeout = errors.New("push approval timed out")
+
+// pushWaiter selects the push MFA option when a flow offers it and bounds how
+// long the flow may then wait for the user to approve it on their device.
+type pushWaiter struct {
+	svc     AuthConnectionService
+	id      string
+	timeout time.Duration
+	quiet   bool
+	// cancel aborts the follow stream with errPushTimeout.
+	cancel context.CancelCauseFunc
+
+	timer       *time.Timer
+	pending     bool
+	sawExternal bool
+}
+
+func (p *pushWaiter) reset() {
+	p.stop()
+	p.pending = false
+	p.sawExternal = false
+}
+
+func (p *pushWaiter) stop() {
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+}
+
+func (p *pushWaiter) Observe(ctx context.Context, event kernel.AuthConnectionFollowResponseUnion) error {
+	if event.Event != "managed_auth_state" {
+		return nil
+	}
+	state := event.AsManagedAuthState()
+
+	if p.pending {
+		// The push was answered once the flow moves past waiting on the device.
+		switch {
+		case state.FlowStep == "AWAITING_EXTERNAL_ACTION":
+			p.sawExternal = true
+		case state.FlowStatus != "IN_PROGRESS", state.FlowStep == "AWAITING_INPUT", state.FlowStep == "COMPLETED", p.sawExternal:
+			p.stop()
+			p.pending = false
+		}
+		return nil
+	}
+
+	if state.FlowStep != "AWAITING_INPUT" || !slices.ContainsFunc(state.MfaOptions, func(o kernel.AuthConnectionFollowResponseManagedAuthStateMfaOption) bool {
+		return o.Type == "push"
+	}) {
+		return nil
+	}
+
+	params := kernel.AuthConnectionSubmitParams{SubmitFieldsRequest: kernel.SubmitFieldsRequestParam{MfaOptionID: kernel.Opt("push")}}
+	if _, err := p.svc.Submit(ctx, p.id, params); err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	p.pending = true
+	p.timer = time.AfterFunc(p.timeout, func() { p.cancel(errPushTimeout) })
+
+	if p.quiet {
+		return util.PrintJSON(map[string]string{
+			"event":         "push_pending",
+			"connection_id": p.id,
+			"timeout":       p.timeout.String(),
+		})
+	}
+	pterm.Info.Printf("Push approval pending: approve the sign-in on your device (waiting up to %s)\n", p.timeout)
+	return nil
+}
@@ -0,0 +1,542 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+const (
+	authReportTimelinePageSize = 100
+	// authReportScreenshotSlack widens a failed attempt's window when matching
+	// screenshots, which are named by the local clock at capture time.
+	authReportScreenshotSlack = time.Minute
+	// authReportMaxScreenshots caps the screenshots embedded per failure; the
+	// last ones show where the attempt ended up.
+	authReportMaxScreenshots = 3
+)
+
+type AuthConnectionReportInput struct {
+	Since         string
+	Domain        string
+	ScreenshotDir string
+	Output        string
+}
+
+type authReportCounts struct {
+	Attempts     int
+	Succeeded    int
+	Failed       int
+	HealthChecks int
+	SessionsLost int
+}
+
+// SuccessRate is the share of finished login/re-auth attempts that
+// succeeded, or "-" when none finished.
+func (c authReportCounts) SuccessRate() string {
+	finished := c.Succeeded + c.Failed
+	if finished == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d%%", c.Succeeded*100/finished)
+}
+
+func (c *authReportCounts) add(ev kernel.ManagedAuthTimelineEvent) {
+	switch ev.Type {
+	case kernel.ManagedAuthTimelineEventTypeHealthCheck:
+		c.HealthChecks++
+		if ev.Status == kernel.ManagedAuthTimelineEventStatusNeedsAuth && ev.PreviousStatus == kernel.ManagedAuthTimelineEventPreviousStatusAuthenticated {
+			c.SessionsLost++
+		}
+	default:
+		c.Attempts++
+		switch {
+		case ev.Status == kernel.ManagedAuthTimelineEventStatusSuccess:
+			c.Succeeded++
+		case authReportIsFailure(ev):
+			c.Failed++
+		}
+	}
+}
+
+type authReportDomain struct {
+	Domain        string
+	Connections   int
+	Authenticated int
+	Counts        authReportCounts
+	LastSuccess   time.Time
+	LastFailure   time.Time
+}
+
+// Healthy reports whether every connection for the domain is logged in.
+func (d authReportDomain) Healthy() bool {
+	return d.Authenticated == d.Connections
+}
+
+type authReportScreenshot struct {
+	Name string
+	Data template.URL
+}
+
+type authReportFailure struct {
+	At           time.Time
+	Domain       string
+	ConnectionID string
+	Profile      string
+	Type         string
+	Status       string
+	Step         string
+	ErrorCode    string
+	Error        string
+	WebsiteError string
+	ReplayID     string
+	Screenshots  []authReportScreenshot
+}
+
+type authReportDay struct {
+	Date      string
+	Succeeded int
+	Failed    int
+	// Bar widths in percent of the busiest day
+	SucceededWidth int
+	FailedWidth    int
+}
+
+type authReport struct {
+	Generated     time.Time
+	Since         time.Time
+	Connections   int
+	Authenticated int
+	Totals        authReportCounts
+	Domains       []authReportDomain
+	Days          []authReportDay
+	Failures      []authReportFailure
+}
+
+// authReportConnection is a connection with its timeline events in the
+// report window.
+type authReportConnection struct {
+	auth   kernel.ManagedAuth
+	events []kernel.ManagedAuthTimelineEvent
+}
+
+// authReportScreenshotFile is a screenshot saved by follow --screenshot-dir.
+type authReportScreenshotFile struct {
+	path string
+	at   time.Time
+}
+
+// Report writes a standalone HTML report of managed auth connections over
+// a time window, for sharing with people who don't use the CLI.
+func (c AuthConnectionCmd) Report(ctx context.Context, in AuthConnectionReportInput) error {
+	now := time.Now()
+	since, err := parseAuthReportSince(in.Since, now)
+	if err != nil {
+		return err
+	}
+	var shots []authReportScreenshotFile
+	if in.ScreenshotDir != "" {
+		if shots, err = listAuthReportScreenshots(in.ScreenshotDir); err != nil {
+			return err
+		}
+	}
+	if in.Output == "" {
+		in.Output = fmt.Sprintf("auth-report-%s.html", now.Format("20060102"))
+	}
+
+	filter := loginAllFilter{Status: "ANY", Domain: in.Domain}
+	conns, err := c.listLoginAllConnections(ctx, filter)
+	if err != nil {
+		return err
+	}
+	withEvents := make([]authReportConnection, 0, len(conns))
+	for _, conn := range conns {
+		events, err := c.reportTimeline(ctx, conn.ID, since)
+		if err != nil {
+			return fmt.Errorf("timeline for %s (%s): %w", conn.ID, conn.Domain, err)
+		}
+		withEvents = append(withEvents, authReportConnection{auth: conn, events: events})
+	}
+
+	report := buildAuthReport(withEvents, since, now)
+	for i := range report.Failures {
+		report.Failures[i].Screenshots, err = authReportFailureScreenshots(report.Failures[i], withEvents, shots)
+		if err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := authReportTemplate.Execute(&buf, report); err != nil {
+		return fmt.Errorf("render report: %w", err)
+	}
+	if err := os.WriteFile(in.Output, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+	pterm.Success.Printf("Wrote a report on %d connections across %d domains (%d failures) to %s\n",
+		report.Connections, len(report.Domains), len(report.Failures), in.Output)
+	return nil
+}
+
+// reportTimeline pages through a connection's timeline, which the API
+// returns newest-first, until it reaches events older than since.
+func (c AuthConnectionCmd) reportTimeline(ctx context.Context, id string, since time.Time) ([]kernel.ManagedAuthTimelineEvent, error) {
+	var events []kernel.ManagedAuthTimelineEvent
+	for offset := 0; ; offset += authReportTimelinePageSize {
+		page, err := c.svc.Timeline(ctx, id, kernel.AuthConnectionTimelineParams{
+			Limit:  kernel.Opt(int64(authReportTimelinePageSize)),
+			Offset: kernel.Opt(int64(offset)),
+		})
+		if err != nil {
+			return nil, util.CleanedUpSdkError{Err: err}
+		}
+		if page == nil {
+			return events, nil
+		}
+		for _, ev := range page.Items {
+			if ev.Timestamp.Before(since) {
+				return events, nil
+			}
+			events = append(events, ev)
+		}
+		if len(page.Items) < authReportTimelinePageSize {
+			return events, nil
+		}
+	}
+}
+
+// buildAuthReport aggregates connections and their events per domain and
+// per day, and lists failed attempts newest first.
+func buildAuthReport(conns []authReportConnection, since, now time.Time) authReport {
+	report := authReport{Generated: now, Since: since, Connections: len(conns)}
+	domains := map[string]*authReportDomain{}
+	days := map[string]*authReportDay{}
+
+	for _, conn := range conns {
+		d := domains[conn.auth.Domain]
+		if d == nil {
+			d = &authReportDomain{Domain: conn.auth.Domain}
+			domains[conn.auth.Domain] = d
+		}
+		d.Connections++
+		if conn.auth.Status == kernel.ManagedAuthStatusAuthenticated {
+			d.Authenticated++
+			report.Authenticated++
+		}
+
+		for _, ev := range conn.events {
+			d.Counts.add(ev)
+			report.Totals.add(ev)
+			if ev.Type == kernel.ManagedAuthTimelineEventTypeHealthCheck {
+				continue
+			}
+			day := ev.Timestamp.Local().Format("2006-01-02")
+			if days[day] == nil {
+				days[day] = &authReportDay{Date: day}
+			}
+			switch {
+			case ev.Status == kernel.ManagedAuthTimelineEventStatusSuccess:
+				days[day].Succeeded++
+				if ev.Timestamp.After(d.LastSuccess) {
+					d.LastSuccess = ev.Timestamp
+				}
+			case authReportIsFailure(ev):
+				days[day].Failed++
+				if ev.Timestamp.After(d.LastFailure) {
+					d.LastFailure = ev.Timestamp
+				}
+				report.Failures = append(report.Failures, authReportFailure{
+					At:           ev.Timestamp,
+					Domain:       conn.auth.Domain,
+					ConnectionID: conn.auth.ID,
+					Profile:      conn.auth.ProfileName,
+					Type:         string(ev.Type),
+					Status:       string(ev.Status),
+					Step:         string(ev.Step),
+					ErrorCode:    ev.ErrorCode,
+					Error:        ev.ErrorMessage,
+					WebsiteError: ev.WebsiteError,
+					ReplayID:     ev.ReplayID,
+				})
+			}
+		}
+	}
+
+	for _, d := range domains {
+		report.Domains = append(report.Domains, *d)
+	}
+	// Domains that need attention first, then alphabetically
+	sort.Slice(report.Domains, func(i, j int) bool {
+		a, b := report.Domains[i], report.Domains[j]
+		if a.Healthy() != b.Healthy() {
+			return !a.Healthy()
+		}
+		return a.Domain < b.Domain
+	})
+	sort.Slice(report.Failures, func(i, j int) bool {
+		return report.Failures[i].At.After(report.Failures[j].At)
+	})
+
+	// One row per day of the window, including quiet days, so gaps show
+	busiest := 0
+	for _, d := range days {
+		busiest = max(busiest, d.Succeeded+d.Failed)
+	}
+	start := since.Local()
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	for t := start; !t.After(now); t = t.AddDate(0, 0, 1) {
+		day := authReportDay{Date: t.Format("2006-01-02")}
+		if d := days[day.Date]; d != nil {
+			day = *d
+		}
+		if busiest > 0 {
+			day.SucceededWidth = day.Succeeded * 100 / busiest
+			day.FailedWidth = day.Failed * 100 / busiest
+		}
+		report.Days = append(report.Days, day)
+	}
+	return report
+}
+
+// authReportIsFailure reports whether a login or re-auth attempt ended
+// without logging in. Canceled attempts are left out.
+func authReportIsFailure(ev kernel.ManagedAuthTimelineEvent) bool {
+	if ev.Type == kernel.ManagedAuthTimelineEventTypeHealthCheck {
+		return false
+	}
+	return ev.Status == kernel.ManagedAuthTimelineEventStatusFailed || ev.Status == kernel.ManagedAuthTimelineEventStatusExpired
+}
+
+// parseAuthReportSince accepts a duration (7d, 12h, 90m) or a date or
+// RFC-3339 timestamp.
+func parseAuthReportSince(s string, now time.Time) (time.Time, error) {
+	if t, ok := util.ParseTimeOrAgo(s, now); ok {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use a duration like 7d or 24h, a date (2006-01-02) or an RFC-3339 timestamp", s)
+}
+
+// listAuthReportScreenshots finds the screenshots follow --screenshot-dir
+// saved in dir, named by flowScreenshotName.
+func listAuthReportScreenshots(dir string) ([]authReportScreenshotFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read screenshot directory: %w", err)
+	}
+	var shots []authReportScreenshotFile
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || filepath.Ext(name) != ".png" {
+			continue
+		}
+		stamp, _, _ := strings.Cut(name, "-")
+		at, err := time.Parse("20060102T150405.000Z", stamp)
+		if err != nil {
+			continue
+		}
+		shots = append(shots, authReportScreenshotFile{path: filepath.Join(dir, name), at: at})
+	}
+	sort.Slice(shots, func(i, j int) bool { return shots[i].at.Before(shots[j].at) })
+	return shots, nil
+}
+
+// authReportFailureScreenshots embeds the last screenshots taken while the
+// failed attempt ran. Screenshot names don't record the connection, so only
+// the attempt's time window ties them to it.
+func authReportFailureScreenshots(f authReportFailure, conns []authReportConnection, shots []authReportScreenshotFile) ([]authReportScreenshot, error) {
+	if len(shots) == 0 {
+		return nil, nil
+	}
+	end := f.At
+	for _, conn := range conns {
+		if conn.auth.ID != f.ConnectionID {
+			continue
+		}
+		for _, ev := range conn.events {
+			if ev.Timestamp.Equal(f.At) && ev.UpdatedAt.After(end) {
+				end = ev.UpdatedAt
+			}
+		}
+	}
+	from, to := f.At.Add(-authReportScreenshotSlack), end.Add(authReportScreenshotSlack)
+
+	var matched []authReportScreenshotFile
+	for _, s := range shots {
+		if !s.at.Before(from) && !s.at.After(to) {
+			matched = append(matched, s)
+		}
+	}
+	if len(matched) > authReportMaxScreenshots {
+		matched = matched[len(matched)-authReportMaxScreenshots:]
+	}
+	var out []authReportScreenshot
+	for _, s := range matched {
+		data, err := os.ReadFile(s.path)
+		if err != nil {
+			return nil, fmt.Errorf("read screenshot: %w", err)
+		}
+		out = append(out, authReportScreenshot{
+			Name: filepath.Base(s.path),
+			// Data URIs keep the report a single file
+			Data: template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(data)),
+		})
+	}
+	return out, nil
+}
+
+func authReportTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+var authReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"time": authReportTime,
+	"dash": util.OrDash,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Kernel auth connections report</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem auto; max-width: 1100px; color: #1f2328; padding: 0 1rem; }
+h1 { margin-bottom: 0.25rem; }
+h2 { margin-top: 2.5rem; border-bottom: 1px solid #d0d7de; padding-bottom: 0.25rem; }
+.muted { color: #656d76; }
+.cards { display: flex; gap: 1rem; flex-wrap: wrap; margin-top: 1.5rem; }
+.card { border: 1px solid #d0d7de; border-radius: 6px; padding: 0.75rem 1rem; min-width: 140px; }
+.card .value { font-size: 1.6rem; font-weight: 600; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 0.4rem 0.6rem; border-bottom: 1px solid #eaeef2; vertical-align: top; }
+th { background: #f6f8fa; }
+.ok { color: #1a7f37; font-weight: 600; }
+.bad { color: #cf222e; font-weight: 600; }
+.bar { display: flex; height: 14px; min-width: 1px; }
+.bar .s { background: #2da44e; }
+.bar .f { background: #cf222e; }
+.failure { border: 1px solid #d0d7de; border-radius: 6px; padding: 0.75rem 1rem; margin-bottom: 1rem; }
+.failure img { max-width: 32%; border: 1px solid #d0d7de; margin: 0.5rem 0.5rem 0 0; }
+code { background: #f6f8fa; padding: 0.1rem 0.3rem; border-radius: 4px; }
+</style>
+</head>
+<body>
+<h1>Auth connections report</h1>
+<div class="muted">{{time .Since}} to {{time .Generated}}</div>
+
+<div class="cards">
+  <div class="card"><div class="muted">Connections</div><div class="value">{{.Connections}}</div></div>
+  <div class="card"><div class="muted">Logged in now</div><div class="value">{{.Authenticated}}</div></div>
+  <div class="card"><div class="muted">Login attempts</div><div class="value">{{.Totals.Attempts}}</div></div>
+  <div class="card"><div class="muted">Success rate</div><div class="value">{{.Totals.SuccessRate}}</div></div>
+  <div class="card"><div class="muted">Sessions lost</div><div class="value">{{.Totals.SessionsLost}}</div></div>
+</div>
+
+<h2>Status by domain</h2>
+{{if .Domains}}
+<table>
+<tr><th>Domain</th><th>Status</th><th>Attempts</th><th>Succeeded</th><th>Failed</th><th>Success rate</th><th>Sessions lost</th><th>Last success</th><th>Last failure</th></tr>
+{{range .Domains}}
+<tr>
+  <td>{{.Domain}}</td>
+  <td>{{if .Healthy}}<span class="ok">Logged in</span>{{else}}<span class="bad">{{.Authenticated}} of {{.Connections}} logged in</span>{{end}}</td>
+  <td>{{.Counts.Attempts}}</td>
+  <td>{{.Counts.Succeeded}}</td>
+  <td>{{.Counts.Failed}}</td>
+  <td>{{.Counts.SuccessRate}}</td>
+  <td>{{.Counts.SessionsLost}}</td>
+  <td>{{time .LastSuccess}}</td>
+  <td>{{time .LastFailure}}</td>
+</tr>
+{{end}}
+</table>
+{{else}}
+<p class="muted">No auth connections.</p>
+{{end}}
+
+<h2>Trend</h2>
+<p class="muted">Finished login and re-auth attempts per day: <span class="ok">succeeded</span>, <span class="bad">failed</span>.</p>
+<table>
+<tr><th style="width: 8rem">Day</th><th style="width: 6rem">Succeeded</th><th style="width: 6rem">Failed</th><th></th></tr>
+{{range .Days}}
+<tr>
+  <td>{{.Date}}</td>
+  <td>{{.Succeeded}}</td>
+  <td>{{.Failed}}</td>
+  <td><div class="bar"><div class="s" style="width: {{.SucceededWidth}}%"></div><div class="f" style="width: {{.FailedWidth}}%"></div></div></td>
+</tr>
+{{end}}
+</table>
+
+<h2>Failures</h2>
+{{if .Failures}}
+{{range .Failures}}
+<div class="failure">
+  <div><span class="bad">{{.Status}}</span> {{.Type}} on <strong>{{.Domain}}</strong> at {{time .At}}</div>
+  <div class="muted">Connection <code>{{.ConnectionID}}</code>, profile {{dash .Profile}}{{if .Step}}, reached step {{.Step}}{{end}}</div>
+  {{if .Error}}<div>Error{{if .ErrorCode}} ({{.ErrorCode}}){{end}}: {{.Error}}</div>{{end}}
+  {{if .WebsiteError}}<div>Website error: {{.WebsiteError}}</div>{{end}}
+  {{if .ReplayID}}<div>Replay: <code>{{.ReplayID}}</code></div>{{end}}
+  {{range .Screenshots}}<img src="{{.Data}}" alt="{{.Name}}" title="{{.Name}}">{{end}}
+</div>
+{{end}}
+{{else}}
+<p class="muted">No failed attempts in this window.</p>
+{{end}}
+</body>
+</html>
+`))
+
+var authConnectionsReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Write a standalone HTML report on auth connections",
+	Long: `Write a standalone HTML report on managed auth connections over a time
+window, for sharing with people who don't use the CLI: current status per
+domain, login and re-auth success rates, a daily trend, and every failed
+attempt with its error and replay ID.
+
+Screenshots saved by "auth connections follow --screenshot-dir" are embedded
+under the failures they were taken during when --screenshot-dir points at the
+same directory.`,
+	Example: `  kernel auth connections report --since 7d --output report.html
+  kernel auth connections report --since 2026-06-01 --domain github.com --screenshot-dir ./shots`,
+	Args: cobra.NoArgs,
+	RunE: runAuthConnectionsReport,
+}
+
+func init() {
+	authConnectionsReportCmd.Flags().String("since", "7d", "Report window start: a duration like 7d or 24h, a date or an RFC-3339 timestamp")
+	authConnectionsReportCmd.Flags().String("domain", "", "Only report on connections for this domain")
+	authConnectionsReportCmd.Flags().String("screenshot-dir", "", "Directory of screenshots saved by follow --screenshot-dir to embed under failures")
+	authConnectionsReportCmd.Flags().StringP("output", "o", "", "Output HTML file path (default auth-report-<date>.html)")
+	authConnectionsCmd.AddCommand(authConnectionsReportCmd)
+}
+
+func runAuthConnectionsReport(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	since, _ := cmd.Flags().GetString("since")
+	domain, _ := cmd.Flags().GetString("domain")
+	screenshotDir, _ := cmd.Flags().GetString("screenshot-dir")
+	output, _ := cmd.Flags().GetString("output")
+
+	svc := client.Auth.Connections
+	c := AuthConnectionCmd{svc: &svc}
+	return c.Report(cmd.Context(), AuthConnectionReportInput{
+		Since:         since,
+		Domain:        domain,
+		ScreenshotDir: screenshotDir,
+		Output:        output,
+	})
+}
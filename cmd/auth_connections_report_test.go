@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/kernel/kernel-go-sdk/packages/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAuthReportSince(t *testing.T) {
+	now := time.Date(2026, 6, 10, 12, 0, 0, 0, time.UTC)
+	got, err := parseAuthReportSince("7d", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -7), got)
+
+	got, err = parseAuthReportSince("36h", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-36*time.Hour), got)
+
+	got, err = parseAuthReportSince("2026-06-01T00:00:00Z", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), got)
+
+	for _, bad := range []string{"", "0d", "-3d", "last week"} {
+		_, err := parseAuthReportSince(bad, now)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestBuildAuthReport(t *testing.T) {
+	now := time.Now()
+	since := now.Add(-72 * time.Hour)
+	conns := []authReportConnection{
+		{
+			auth: kernel.ManagedAuth{ID: "ma_1", Domain: "github.com", Status: kernel.ManagedAuthStatusAuthenticated},
+			events: []kernel.ManagedAuthTimelineEvent{
+				{Type: "login", Status: "SUCCESS", Timestamp: now.Add(-1 * time.Hour)},
+				{Type: "health_check", Status: "AUTHENTICATED", Timestamp: now.Add(-2 * time.Hour)},
+			},
+		},
+		{
+			auth: kernel.ManagedAuth{ID: "ma_2", Domain: "example.com", Status: kernel.ManagedAuthStatusNeedsAuth},
+			events: []kernel.ManagedAuthTimelineEvent{
+				{Type: "reauth", Status: "FAILED", Step: "AWAITING_INPUT", ErrorMessage: "bad password", Timestamp: now.Add(-3 * time.Hour)},
+				{Type: "health_check", Status: "NEEDS_AUTH", PreviousStatus: "AUTHENTICATED", Timestamp: now.Add(-4 * time.Hour)},
+				{Type: "login", Status: "CANCELED", Timestamp: now.Add(-50 * time.Hour)},
+				{Type: "login", Status: "EXPIRED", Timestamp: now.Add(-51 * time.Hour)},
+			},
+		},
+	}
+
+	report := buildAuthReport(conns, since, now)
+	assert.Equal(t, 2, report.Connections)
+	assert.Equal(t, 1, report.Authenticated)
+	assert.Equal(t, 4, report.Totals.Attempts)
+	assert.Equal(t, 1, report.Totals.Succeeded)
+	assert.Equal(t, 2, report.Totals.Failed)
+	assert.Equal(t, 1, report.Totals.SessionsLost)
+	assert.Equal(t, "33%", report.Totals.SuccessRate())
+
+	require.Len(t, report.Domains, 2)
+	assert.Equal(t, "example.com", report.Domains[0].Domain, "domains that need attention come first")
+	assert.False(t, report.Domains[0].Healthy())
+	assert.Equal(t, "0%", report.Domains[0].Counts.SuccessRate())
+	assert.Equal(t, "-", authReportCounts{}.SuccessRate())
+
+	require.Len(t, report.Failures, 2)
+	assert.Equal(t, "bad password", report.Failures[0].Error, "failures are newest first")
+	assert.Equal(t, "EXPIRED", report.Failures[1].Status)
+
+	assert.GreaterOrEqual(t, len(report.Days), 4)
+	assert.Equal(t, since.Local().Format("2006-01-02"), report.Days[0].Date)
+	assert.Equal(t, now.Local().Format("2006-01-02"), report.Days[len(report.Days)-1].Date)
+}
+
+func TestAuthConnectionsReport_WritesHTMLWithScreenshots(t *testing.T) {
+	failedAt := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	fake := &FakeAuthConnectionService{
+		ListFunc: func(ctx context.Context, query kernel.AuthConnectionListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.ManagedAuth], error) {
+			return &pagination.OffsetPagination[kernel.ManagedAuth]{Items: []kernel.ManagedAuth{
+				{ID: "ma_1", Domain: "<script>.example.com", ProfileName: "p", Status: kernel.ManagedAuthStatusNeedsAuth},
+			}}, nil
+		},
+		TimelineFunc: func(ctx context.Context, id string, query kernel.AuthConnectionTimelineParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.ManagedAuthTimelineEvent], error) {
+			return &pagination.OffsetPagination[kernel.ManagedAuthTimelineEvent]{Items: []kernel.ManagedAuthTimelineEvent{
+				{Type: "login", Status: "FAILED", Timestamp: failedAt, UpdatedAt: failedAt.Add(2 * time.Minute), ReplayID: "rep_1", WebsiteError: "Incorrect password"},
+				{Type: "login", Status: "SUCCESS", Timestamp: time.Now().Add(-30 * 24 * time.Hour)},
+			}}, nil
+		},
+	}
+
+	shots := t.TempDir()
+	during := filepath.Join(shots, flowScreenshotName("awaiting_input-failed", failedAt.Add(90*time.Second)))
+	require.NoError(t, os.WriteFile(during, []byte("png-during"), 0o644))
+	before := filepath.Join(shots, flowScreenshotName("discovering", failedAt.Add(-time.Hour)))
+	require.NoError(t, os.WriteFile(before, []byte("png-before"), 0o644))
+
+	out := filepath.Join(t.TempDir(), "report.html")
+	c := AuthConnectionCmd{svc: fake}
+	require.NoError(t, c.Report(context.Background(), AuthConnectionReportInput{Since: "7d", ScreenshotDir: shots, Output: out}))
+
+	html, err := os.ReadFile(out)
+	require.NoError(t, err)
+	body := string(html)
+	assert.Contains(t, body, "Incorrect password")
+	assert.Contains(t, body, "rep_1")
+	assert.Contains(t, body, "&lt;script&gt;.example.com")
+	assert.NotContains(t, body, "<script>")
+	assert.Contains(t, body, "data:image/png;base64,cG5nLWR1cmluZw==")
+	assert.NotContains(t, body, "cG5nLWJlZm9yZQ==", "screenshots outside the attempt are left out")
+	assert.Equal(t, 1, strings.Count(body, `class="failure"`), "events before --since are left out")
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+)
+
+// flowScreenshotter captures the managed auth browser session via the
+// Computer API whenever a followed flow reaches a new step or fails, so there
+// is visual evidence of where a login went wrong.
+type flowScreenshotter struct {
+	svc      AuthConnectionService
+	computer BrowserComputerService
+	id       string
+	dir      string
+	// quiet suppresses progress messages (used with --output json).
+	quiet bool
+
+	sessionID string
+	lastStep  string
+}
+
+// Observe inspects a follow event and captures a screenshot if warranted.
+// Capture failures are reported as warnings and never abort the stream.
+func (s *flowScreenshotter) Observe(ctx context.Context, event kernel.AuthConnectionFollowResponseUnion) {
+	switch event.Event {
+	case "managed_auth_state":
+		state := event.AsManagedAuthState()
+		failed := state.FlowStatus == string(kernel.ManagedAuthFlowStatusFailed) || state.ErrorMessage != ""
+		label := ""
+		if state.FlowStep != s.lastStep {
+			label = state.FlowStep
+			s.lastStep = state.FlowStep
+		}
+		if failed {
+			label = strings.Trim(label+"-failed", "-")
+		}
+		if label != "" {
+			s.capture(ctx, label, state.Timestamp)
+		}
+	case "error":
+		s.capture(ctx, "error", time.Now())
+	}
+}
+
+func (s *flowScreenshotter) capture(ctx context.Context, label string, at time.Time) {
+	if s.sessionID == "" {
+		auth, err := s.svc.Get(ctx, s.id)
+		if err != nil {
+			s.warn("Could not resolve browser session for screenshot: %v", util.CleanedUpSdkError{Err: err})
+			return
+		}
+		if auth.BrowserSessionID == "" {
+			pterm.Debug.Printf("No browser session yet; skipping %s screenshot\n", label)
+			return
+		}
+		s.sessionID = auth.BrowserSessionID
+	}
+
+	res, err := s.computer.CaptureScreenshot(ctx, s.sessionID, kernel.BrowserComputerCaptureScreenshotParams{})
+	if err != nil {
+		s.warn("Screenshot for %s failed: %v", label, util.CleanedUpSdkError{Err: err})
+		return
+	}
+	defer res.Body.Close()
+
+	if at.IsZero() {
+		at = time.Now()
+	}
+	path := filepath.Join(s.dir, flowScreenshotName(label, at))
+	f, err := os.Create(path)
+	if err != nil {
+		s.warn("Failed to create %s: %v", path, err)
+		return
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, res.Body); err != nil {
+		s.warn("Failed to write %s: %v", path, err)
+		return
+	}
+	if !s.quiet {
+		pterm.Info.Printf("  Screenshot: %s\n", path)
+	}
+}
+
+func (s *flowScreenshotter) warn(format string, args ...any) {
+	w := pterm.Warning
+	if s.quiet {
+		w = *w.WithWriter(os.Stderr)
+	}
+	w.Printf(format+"\n", args...)
+}
+
+// flowScreenshotName builds a file name that sorts chronologically, e.g.
+// "20240102T150405.000Z-awaiting_input.png".
+func flowScreenshotName(label string, at time.Time) string {
+	return fmt.Sprintf("%s-%s.png", at.UTC().Format("20060102T150405.000Z"), strings.ToLower(label))
+}
@@ -6,13 +6,21 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"net/http"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/kernel/kernel-go-sdk"
 	"github.com/kernel/kernel-go-sdk/option"
 	"github.com/kernel/kernel-go-sdk/packages/pagination"
 	"github.com/kernel/kernel-go-sdk/packages/ssestream"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -26,6 +34,7 @@ type FakeAuthConnectionService struct {
 	LoginFunc           func(ctx context.Context, id string, body kernel.AuthConnectionLoginParams, opts ...option.RequestOption) (*kernel.LoginResponse, error)
 	SubmitFunc          func(ctx context.Context, id string, body kernel.AuthConnectionSubmitParams, opts ...option.RequestOption) (*kernel.SubmitFieldsResponse, error)
 	FollowStreamingFunc func(ctx context.Context, id string, opts ...option.RequestOption) *ssestream.Stream[kernel.AuthConnectionFollowResponseUnion]
+	TimelineFunc        func(ctx context.Context, id string, query kernel.AuthConnectionTimelineParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.ManagedAuthTimelineEvent], error)
 }
 
 func (f *FakeAuthConnectionService) New(ctx context.Context, body kernel.AuthConnectionNewParams, opts ...option.RequestOption) (*kernel.ManagedAuth, error) {
@@ -84,6 +93,13 @@ func (f *FakeAuthConnectionService) FollowStreaming(ctx context.Context, id stri
 	return nil
 }
 
+func (f *FakeAuthConnectionService) Timeline(ctx context.Context, id string, query kernel.AuthConnectionTimelineParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.ManagedAuthTimelineEvent], error) {
+	if f.TimelineFunc != nil {
+		return f.TimelineFunc(ctx, id, query, opts...)
+	}
+	return &pagination.OffsetPagination[kernel.ManagedAuthTimelineEvent]{}, nil
+}
+
 func TestAuthConnectionsGet_PrintsSubmissionHints(t *testing.T) {
 	setupStdoutCapture(t)
 
@@ -543,3 +559,631 @@ func TestSubmit_MfaOptionRejectsUnknown(t *testing.T) {
 	assert.Contains(t, err.Error(), "carrier pigeon")
 	assert.Contains(t, err.Error(), "Get a text (sms)")
 }
+
+func TestAuthConnectionsLogs_PrintsTimelineOldestFirst(t *testing.T) {
+	setupStdoutCapture(t)
+
+	var gotType kernel.AuthConnectionTimelineParamsType
+	fake := &FakeAuthConnectionService{
+		TimelineFunc: func(ctx context.Context, id string, query kernel.AuthConnectionTimelineParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.ManagedAuthTimelineEvent], error) {
+			gotType = query.Type
+			return &pagination.OffsetPagination[kernel.ManagedAuthTimelineEvent]{Items: []kernel.ManagedAuthTimelineEvent{
+				{ID: "second", Type: "login", Status: "FAILED", Step: "SUBMITTING", ErrorMessage: "bad password", WebsiteError: "Incorrect password", Timestamp: time.Unix(200, 0)},
+				{ID: "first", Type: "login", Status: "SUCCESS", Step: "COMPLETED", Timestamp: time.Unix(100, 0)},
+			}}, nil
+		},
+	}
+	c := AuthConnectionCmd{svc: fake}
+
+	err := c.Logs(context.Background(), AuthConnectionLogsInput{ID: "conn", Type: "login"})
+	require.NoError(t, err)
+	assert.Equal(t, kernel.AuthConnectionTimelineParamsTypeLogin, gotType)
+
+	out := outBuf.String()
+	assert.Less(t, strings.Index(out, "SUCCESS"), strings.Index(out, "FAILED"))
+	assert.Contains(t, out, "step: SUBMITTING")
+	assert.Contains(t, out, "bad password")
+	assert.Contains(t, out, "Incorrect password")
+}
+
+func TestAuthConnectionsLogs_RejectsUnknownType(t *testing.T) {
+	c := AuthConnectionCmd{svc: &FakeAuthConnectionService{}}
+	err := c.Logs(context.Background(), AuthConnectionLogsInput{ID: "conn", Type: "bogus"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --type")
+}
+
+func TestAuthConnectionsLogs_FollowStreamsEvents(t *testing.T) {
+	setupStdoutCapture(t)
+
+	fake := &FakeAuthConnectionService{
+		FollowStreamingFunc: func(ctx context.Context, id string, opts ...option.RequestOption) *ssestream.Stream[kernel.AuthConnectionFollowResponseUnion] {
+			events := [][]byte{
+				[]byte(`{"event":"managed_auth_state","flow_status":"IN_PROGRESS","flow_step":"AWAITING_INPUT","timestamp":"2024-01-01T00:00:00Z","discovered_fields":[{"name":"email","label":"Email","selector":"#email","type":"email"}]}`),
+			}
+			return ssestream.NewStream[kernel.AuthConnectionFollowResponseUnion](&testDecoder{data: events}, nil)
+		},
+	}
+	c := AuthConnectionCmd{svc: fake}
+
+	err := c.Logs(context.Background(), AuthConnectionLogsInput{ID: "conn", Follow: true})
+	require.NoError(t, err)
+	out := outBuf.String()
+	assert.Contains(t, out, "No timeline events found")
+	assert.Contains(t, out, "AWAITING_INPUT")
+	assert.Contains(t, out, "Discovered fields: email")
+}
+
+func TestAuthConnectionsFollow_ScreenshotDirCapturesStepsAndFailure(t *testing.T) {
+	setupStdoutCapture(t)
+	dir := t.TempDir()
+
+	var sessions []string
+	computer := &FakeComputerService{
+		CaptureScreenshotFunc: func(ctx context.Context, id string, body kernel.BrowserComputerCaptureScreenshotParams, opts ...option.RequestOption) (*http.Response, error) {
+			sessions = append(sessions, id)
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("png"))}, nil
+		},
+	}
+	fake := &FakeAuthConnectionService{
+		GetFunc: func(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.ManagedAuth, error) {
+			return &kernel.ManagedAuth{ID: id, BrowserSessionID: "sess-1"}, nil
+		},
+		FollowStreamingFunc: func(ctx context.Context, id string, opts ...option.RequestOption) *ssestream.Stream[kernel.AuthConnectionFollowResponseUnion] {
+			events := [][]byte{
+				[]byte(`{"event":"managed_auth_state","flow_status":"IN_PROGRESS","flow_step":"DISCOVERING","timestamp":"2024-01-01T00:00:00Z"}`),
+				[]byte(`{"event":"managed_auth_state","flow_status":"IN_PROGRESS","flow_step":"DISCOVERING","timestamp":"2024-01-01T00:00:01Z"}`),
+				[]byte(`{"event":"managed_auth_state","flow_status":"IN_PROGRESS","flow_step":"SUBMITTING","timestamp":"2024-01-01T00:00:02Z"}`),
+				[]byte(`{"event":"managed_auth_state","flow_status":"FAILED","flow_step":"SUBMITTING","timestamp":"2024-01-01T00:00:03Z","error_message":"bad password"}`),
+			}
+			return ssestream.NewStream[kernel.AuthConnectionFollowResponseUnion](&testDecoder{data: events}, nil)
+		},
+	}
+	c := AuthConnectionCmd{svc: fake, computer: computer}
+
+	err := c.Follow(context.Background(), AuthConnectionFollowInput{ID: "conn", ScreenshotDir: dir})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"sess-1", "sess-1", "sess-1"}, sessions)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{
+		"20240101T000000.000Z-discovering.png",
+		"20240101T000002.000Z-submitting.png",
+		"20240101T000003.000Z-failed.png",
+	}, names)
+}
+
+func TestAuthConnectionsWatch_PollsUntilAllFlowsFinish(t *testing.T) {
+	setupStdoutCapture(t)
+
+	var mu sync.Mutex
+	polls := map[string]int{}
+	fake := &FakeAuthConnectionService{
+		GetFunc: func(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.ManagedAuth, error) {
+			mu.Lock()
+			polls[id]++
+			n := polls[id]
+			mu.Unlock()
+			auth := &kernel.ManagedAuth{ID: id, Domain: id + ".com", FlowStatus: kernel.ManagedAuthFlowStatusInProgress}
+			switch {
+			case id == "a" && n >= 2:
+				auth.FlowStatus = kernel.ManagedAuthFlowStatusSuccess
+				auth.FlowStep = kernel.ManagedAuthFlowStepCompleted
+			case id == "b" && n == 1:
+				auth.FlowStep = kernel.ManagedAuthFlowStepAwaitingInput
+				auth.HostedURL = "https://auth.example/b"
+			case id == "b":
+				auth.FlowStatus = kernel.ManagedAuthFlowStatusFailed
+				auth.ErrorMessage = "bad password"
+			}
+			return auth, nil
+		},
+	}
+	c := AuthConnectionCmd{svc: fake}
+
+	err := c.Watch(context.Background(), AuthConnectionWatchInput{IDs: []string{"a", "b"}, Interval: time.Millisecond})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 of 2")
+
+	out := outBuf.String()
+	assert.Contains(t, out, "input needed: https://auth.example/b")
+	assert.Contains(t, out, "[a] a.com SUCCESS / COMPLETED")
+	assert.Contains(t, out, "bad password")
+	assert.Equal(t, 2, polls["a"])
+}
+
+func TestAuthConnectionsWatch_AllActiveSelectsInProgressFlows(t *testing.T) {
+	setupStdoutCapture(t)
+
+	var got []string
+	fake := &FakeAuthConnectionService{
+		ListFunc: func(ctx context.Context, query kernel.AuthConnectionListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.ManagedAuth], error) {
+			return &pagination.OffsetPagination[kernel.ManagedAuth]{Items: []kernel.ManagedAuth{
+				{ID: "idle"},
+				{ID: "active", FlowStatus: kernel.ManagedAuthFlowStatusInProgress},
+				{ID: "done", FlowStatus: kernel.ManagedAuthFlowStatusSuccess},
+			}}, nil
+		},
+		GetFunc: func(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.ManagedAuth, error) {
+			got = append(got, id)
+			return &kernel.ManagedAuth{ID: id, FlowStatus: kernel.ManagedAuthFlowStatusSuccess}, nil
+		},
+	}
+	c := AuthConnectionCmd{svc: fake}
+
+	require.NoError(t, c.Watch(context.Background(), AuthConnectionWatchInput{AllActive: true, Interval: time.Millisecond}))
+	assert.Equal(t, []string{"active"}, got)
+
+	err := c.Watch(context.Background(), AuthConnectionWatchInput{IDs: []string{"x"}, AllActive: true})
+	assert.ErrorContains(t, err, "--all-active")
+}
+
+func TestLoadAuthRunPolicy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("auto_mfa_types: [TOTP, push]\nmax_retries: 2\nrequire_approval_domains: ['*.bank.example']\n"), 0o600))
+
+	p, err := loadAuthRunPolicy(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"totp", "push"}, p.AutoMFATypes)
+	assert.Equal(t, 2, p.MaxRetries)
+	assert.True(t, p.requiresApproval("bank.example"))
+	assert.True(t, p.requiresApproval("login.Bank.example"))
+	assert.False(t, p.requiresApproval("notbank.example"))
+
+	require.NoError(t, os.WriteFile(path, []byte("auto_mfa_types: [password]\n"), 0o600))
+	_, err = loadAuthRunPolicy(path)
+	assert.ErrorContains(t, err, `invalid auto_mfa_types entry "password"`)
+
+	require.NoError(t, os.WriteFile(path, []byte("auto_click_ssso: true\n"), 0o600))
+	_, err = loadAuthRunPolicy(path)
+	assert.ErrorContains(t, err, "auto_click_ssso")
+}
+
+func TestAuthConnectionsFollow_PolicyAutomatesAllowedStepsAndRetries(t *testing.T) {
+	setupStdoutCapture(t)
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("auto_mfa_types: [totp]\nauto_click_sso: true\nmax_retries: 1\nrequire_approval_domains: [shop.example]\n"), 0o600))
+
+	attempts := [][][]byte{
+		{
+			[]byte(`{"event":"managed_auth_state","flow_status":"IN_PROGRESS","flow_step":"AWAITING_INPUT","timestamp":"2024-01-01T00:00:00Z","mfa_options":[{"label":"Text me","type":"sms"},{"label":"Authenticator","type":"totp"}]}`),
+			[]byte(`{"event":"managed_auth_state","flow_status":"IN_PROGRESS","flow_step":"AWAITING_INPUT","timestamp":"2024-01-01T00:00:01Z","mfa_options":[{"label":"Authenticator","type":"totp"}]}`),
+			[]byte(`{"event":"managed_auth_state","flow_status":"FAILED","flow_step":"SUBMITTING","timestamp":"2024-01-01T00:00:02Z","error_message":"timeout"}`),
+		},
+		{
+			[]byte(`{"event":"managed_auth_state","flow_status":"IN_PROGRESS","flow_step":"AWAITING_INPUT","timestamp":"2024-01-01T00:01:00Z","pending_sso_buttons":[{"label":"Google","provider":"google","selector":"//button[@id='g']"}]}`),
+			[]byte(`{"event":"managed_auth_state","flow_status":"SUCCESS","flow_step":"COMPLETED","timestamp":"2024-01-01T00:01:01Z"}`),
+			[]byte(`{"event":"managed_auth_state","flow_status":"SUCCESS","flow_step":"COMPLETED","timestamp":"2024-01-01T00:01:02Z"}`),
+		},
+	}
+	var streams, logins int
+	var submitted []kernel.AuthConnectionSubmitParams
+	var prompts []string
+	fake := &FakeAuthConnectionService{
+		GetFunc: func(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.ManagedAuth, error) {
+			return &kernel.ManagedAuth{ID: id, Domain: "shop.example"}, nil
+		},
+		FollowStreamingFunc: func(ctx context.Context, id string, opts ...option.RequestOption) *ssestream.Stream[kernel.AuthConnectionFollowResponseUnion] {
+			events := attempts[streams]
+			streams++
+			return ssestream.NewStream[kernel.AuthConnectionFollowResponseUnion](&testDecoder{data: events}, nil)
+		},
+		LoginFunc: func(ctx context.Context, id string, body kernel.AuthConnectionLoginParams, opts ...option.RequestOption) (*kernel.LoginResponse, error) {
+			logins++
+			return &kernel.LoginResponse{ID: id}, nil
+		},
+		SubmitFunc: func(ctx context.Context, id string, body kernel.AuthConnectionSubmitParams, opts ...option.RequestOption) (*kernel.SubmitFieldsResponse, error) {
+			submitted = append(submitted, body)
+			return &kernel.SubmitFieldsResponse{Accepted: true}, nil
+		},
+	}
+	c := AuthConnectionCmd{svc: fake, approve: func(prompt string) bool {
+		prompts = append(prompts, prompt)
+		return true
+	}}
+
+	err := c.Follow(context.Background(), AuthConnectionFollowInput{ID: "conn", PolicyFile: path})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, streams)
+	assert.Equal(t, 1, logins)
+	require.Len(t, submitted, 2)
+	assert.Equal(t, "totp", submitted[0].SubmitFieldsRequest.MfaOptionID.Value)
+	assert.Equal(t, "//button[@id='g']", submitted[1].SubmitFieldsRequest.SSOButtonSelector.Value)
+	require.Len(t, prompts, 2)
+	assert.Contains(t, prompts[0], "shop.example")
+	assert.Contains(t, outBuf.String(), "retrying (1 of 1)")
+}
+
+func TestAuthConnectionsFollow_PolicyWaitsForHumanWhenNotApproved(t *testing.T) {
+	setupStdoutCapture(t)
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("auto_mfa_types: [push]\nrequire_approval_domains: ['*.example']\n"), 0o600))
+
+	fake := &FakeAuthConnectionService{
+		GetFunc: func(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.ManagedAuth, error) {
+			return &kernel.ManagedAuth{ID: id, Domain: "app.example"}, nil
+		},
+		FollowStreamingFunc: func(ctx context.Context, id string, opts ...option.RequestOption) *ssestream.Stream[kernel.AuthConnectionFollowResponseUnion] {
+			events := [][]byte{
+				[]byte(`{"event":"managed_auth_state","flow_status":"IN_PROGRESS","flow_step":"AWAITING_INPUT","timestamp":"2024-01-01T00:00:00Z","mfa_options":[{"label":"Push","type":"push"}]}`),
+				[]byte(`{"event":"managed_auth_state","flow_status":"FAILED","flow_step":"AWAITING_INPUT","timestamp":"2024-01-01T00:00:01Z"}`),
+			}
+			return ssestream.NewStream[kernel.AuthConnectionFollowResponseUnion](&testDecoder{data: events}, nil)
+		},
+		SubmitFunc: func(ctx context.Context, id string, body kernel.AuthConnectionSubmitParams, opts ...option.RequestOption) (*kernel.SubmitFieldsResponse, error) {
+			t.Fatal("submit must not be called without approval")
+			return nil, nil
+		},
+	}
+	c := AuthConnectionCmd{svc: fake, approve: func(string) bool { return false }}
+
+	require.NoError(t, c.Follow(context.Background(), AuthConnectionFollowInput{ID: "conn", PolicyFile: path}))
+	assert.Contains(t, outBuf.String(), "waiting for a human")
+}
+
+func TestAuthConnectionsFollow_PolicyRetryBudgetStopsBouncingStep(t *testing.T) {
+	setupStdoutCapture(t)
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("auto_mfa_types: [totp]\nfield_retry_budget: 1\n"), 0o600))
+
+	mfa := []byte(`{"event":"managed_auth_state","flow_status":"IN_PROGRESS","flow_step":"AWAITING_INPUT","timestamp":"2024-01-01T00:00:00Z","mfa_options":[{"label":"Authenticator","type":"totp"}]}`)
+	submitting := []byte(`{"event":"managed_auth_state","flow_status":"IN_PROGRESS","flow_step":"SUBMITTING","timestamp":"2024-01-01T00:00:01Z"}`)
+	submits := 0
+	fake := &FakeAuthConnectionService{
+		GetFunc: func(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.ManagedAuth, error) {
+			return &kernel.ManagedAuth{ID: id, Domain: "shop.example"}, nil
+		},
+		FollowStreamingFunc: func(ctx context.Context, id string, opts ...option.RequestOption) *ssestream.Stream[kernel.AuthConnectionFollowResponseUnion] {
+			events := [][]byte{mfa, mfa, submitting, mfa, submitting, mfa}
+			return ssestream.NewStream[kernel.AuthConnectionFollowResponseUnion](&testDecoder{data: events}, nil)
+		},
+		SubmitFunc: func(ctx context.Context, id string, body kernel.AuthConnectionSubmitParams, opts ...option.RequestOption) (*kernel.SubmitFieldsResponse, error) {
+			submits++
+			return &kernel.SubmitFieldsResponse{Accepted: true}, nil
+		},
+	}
+	c := AuthConnectionCmd{svc: fake}
+
+	err := c.Follow(context.Background(), AuthConnectionFollowInput{ID: "conn", PolicyFile: path})
+	require.ErrorIs(t, err, errAuthCircuitOpen)
+	assert.Contains(t, err.Error(), "lockout risk")
+	assert.Equal(t, 2, submits)
+}
+
+func TestAuthConnectionsFollow_PolicyBreakerStopsRetriesAfterRejections(t *testing.T) {
+	setupStdoutCapture(t)
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("max_retries: 5\nmax_rejected_submissions: 2\n"), 0o600))
+
+	logins := 0
+	fake := &FakeAuthConnectionService{
+		GetFunc: func(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.ManagedAuth, error) {
+			return &kernel.ManagedAuth{ID: id, Domain: "shop.example"}, nil
+		},
+		FollowStreamingFunc: func(ctx context.Context, id string, opts ...option.RequestOption) *ssestream.Stream[kernel.AuthConnectionFollowResponseUnion] {
+			events := [][]byte{
+				[]byte(`{"event":"managed_auth_state","flow_status":"IN_PROGRESS","flow_step":"SUBMITTING","timestamp":"2024-01-01T00:00:00Z","website_error":"Incorrect password"}`),
+				[]byte(`{"event":"managed_auth_state","flow_status":"FAILED","flow_step":"SUBMITTING","timestamp":"2024-01-01T00:00:01Z","website_error":"Incorrect password"}`),
+			}
+			return ssestream.NewStream[kernel.AuthConnectionFollowResponseUnion](&testDecoder{data: events}, nil)
+		},
+		LoginFunc: func(ctx context.Context, id string, body kernel.AuthConnectionLoginParams, opts ...option.RequestOption) (*kernel.LoginResponse, error) {
+			logins++
+			return &kernel.LoginResponse{ID: id}, nil
+		},
+	}
+	c := AuthConnectionCmd{svc: fake}
+
+	err := c.Follow(context.Background(), AuthConnectionFollowInput{ID: "conn", PolicyFile: path})
+	require.ErrorIs(t, err, errAuthCircuitOpen)
+	assert.Contains(t, err.Error(), "2 rejected submissions")
+	assert.Equal(t, 1, logins)
+}
+
+// blockingDecoder replays data and then blocks until ctx is done, like a live
+// SSE stream with no further events.
+type blockingDecoder struct {
+	ctx  context.Context
+	data [][]byte
+	idx  int
+}
+
+func (d *blockingDecoder) Event() ssestream.Event { return ssestream.Event{Data: d.data[d.idx-1]} }
+func (d *blockingDecoder) Next() bool {
+	if d.idx >= len(d.data) {
+		<-d.ctx.Done()
+		return false
+	}
+	d.idx++
+	return true
+}
+func (d *blockingDecoder) Close() error { return nil }
+func (d *blockingDecoder) Err() error   { return d.ctx.Err() }
+
+func TestAuthConnectionsFollow_WaitForPushSelectsPushOnce(t *testing.T) {
+	setupStdoutCapture(t)
+
+	var submitted []kernel.AuthConnectionSubmitParams
+	fake := &FakeAuthConnectionService{
+		GetFunc: func(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.ManagedAuth, error) {
+			return &kernel.ManagedAuth{ID: id}, nil
+		},
+		FollowStreamingFunc: func(ctx context.Context, id string, opts ...option.RequestOption) *ssestream.Stream[kernel.AuthConnectionFollowResponseUnion] {
+			mfa := []byte(`{"event":"managed_auth_state","flow_status":"IN_PROGRESS","flow_step":"AWAITING_INPUT","timestamp":"2024-01-01T00:00:00Z","mfa_options":[{"label":"Text me","type":"sms"},{"label":"Send push","type":"push"}]}`)
+			events := [][]byte{
+				mfa,
+				[]byte(`{"event":"managed_auth_state","flow_status":"IN_PROGRESS","flow_step":"AWAITING_EXTERNAL_ACTION","timestamp":"2024-01-01T00:00:01Z","external_action_message":"Approve on your phone"}`),
+				[]byte(`{"event":"managed_auth_state","flow_status":"SUCCESS","flow_step":"COMPLETED","timestamp":"2024-01-01T00:00:05Z"}`),
+			}
+			return ssestream.NewStream[kernel.AuthConnectionFollowResponseUnion](&testDecoder{data: events}, nil)
+		},
+		SubmitFunc: func(ctx context.Context, id string, body kernel.AuthConnectionSubmitParams, opts ...option.RequestOption) (*kernel.SubmitFieldsResponse, error) {
+			submitted = append(submitted, body)
+			return &kernel.SubmitFieldsResponse{Accepted: true}, nil
+		},
+	}
+	c := AuthConnectionCmd{svc: fake}
+
+	err := c.Follow(context.Background(), AuthConnectionFollowInput{ID: "conn", WaitForPush: true, PushTimeout: time.Minute})
+	require.NoError(t, err)
+	require.Len(t, submitted, 1)
+	assert.Equal(t, "push", submitted[0].SubmitFieldsRequest.MfaOptionID.Value)
+	assert.Contains(t, outBuf.String(), "Push approval pending")
+}
+
+func TestAuthConnectionsFollow_WaitForPushTimesOut(t *testing.T) {
+	setupStdoutCapture(t)
+
+	fake := &FakeAuthConnectionService{
+		GetFunc: func(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.ManagedAuth, error) {
+			return &kernel.ManagedAuth{ID: id}, nil
+		},
+		FollowStreamingFunc: func(ctx context.Context, id string, opts ...option.RequestOption) *ssestream.Stream[kernel.AuthConnectionFollowResponseUnion] {
+			events := [][]byte{
+				[]byte(`{"event":"managed_auth_state","flow_status":"IN_PROGRESS","flow_step":"AWAITING_INPUT","timestamp":"2024-01-01T00:00:00Z","mfa_options":[{"label":"Send push","type":"push"}]}`),
+				[]byte(`{"event":"managed_auth_state","flow_status":"IN_PROGRESS","flow_step":"AWAITING_EXTERNAL_ACTION","timestamp":"2024-01-01T00:00:01Z"}`),
+			}
+			return ssestream.NewStream[kernel.AuthConnectionFollowResponseUnion](&blockingDecoder{ctx: ctx, data: events}, nil)
+		},
+	}
+	c := AuthConnectionCmd{svc: fake}
+
+	err := c.Follow(context.Background(), AuthConnectionFollowInput{ID: "conn", WaitForPush: true, PushTimeout: 20 * time.Millisecond})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "push approval not received within 20ms")
+}
+
+func TestAuthConnectionsFollow_TimeoutsExitWithDistinctCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		lastStep string
+		in       AuthConnectionFollowInput
+		code     int
+		msg      string
+	}{
+		{"overall", "AWAITING_INPUT", AuthConnectionFollowInput{Timeout: 20 * time.Millisecond, StepTimeout: time.Minute}, exitFlowTimeout, "login did not finish within 20ms"},
+		{"step", "AWAITING_INPUT", AuthConnectionFollowInput{StepTimeout: 20 * time.Millisecond, HumanTimeout: time.Minute}, exitStepTimeout, "login stayed on step AWAITING_INPUT for more than 20ms"},
+		{"human", "AWAITING_EXTERNAL_ACTION", AuthConnectionFollowInput{StepTimeout: time.Minute, HumanTimeout: 20 * time.Millisecond}, exitHumanTimeout, "nobody completed the live view handoff within 20ms"},
+		// A handoff only counts against --human-timeout.
+		{"handoff ignores step timeout", "AWAITING_EXTERNAL_ACTION", AuthConnectionFollowInput{Timeout: 50 * time.Millisecond, StepTimeout: 10 * time.Millisecond}, exitFlowTimeout, "login did not finish within 50ms"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupStdoutCapture(t)
+
+			fake := &FakeAuthConnectionService{
+				GetFunc: func(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.ManagedAuth, error) {
+					return &kernel.ManagedAuth{ID: id}, nil
+				},
+				FollowStreamingFunc: func(ctx context.Context, id string, opts ...option.RequestOption) *ssestream.Stream[kernel.AuthConnectionFollowResponseUnion] {
+					events := [][]byte{
+						[]byte(`{"event":"managed_auth_state","flow_status":"IN_PROGRESS","flow_step":"DISCOVERING","timestamp":"2024-01-01T00:00:00Z"}`),
+						[]byte(`{"event":"managed_auth_state","flow_status":"IN_PROGRESS","flow_step":"` + tt.lastStep + `","timestamp":"2024-01-01T00:00:01Z"}`),
+					}
+					return ssestream.NewStream[kernel.AuthConnectionFollowResponseUnion](&blockingDecoder{ctx: ctx, data: events}, nil)
+				},
+			}
+			c := AuthConnectionCmd{svc: fake}
+
+			tt.in.ID = "conn"
+			err := c.Follow(context.Background(), tt.in)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.msg)
+			var coded interface{ ExitCode() int }
+			require.ErrorAs(t, err, &coded)
+			assert.Equal(t, tt.code, coded.ExitCode())
+		})
+	}
+}
+
+type fakeMagicLinkInbox struct {
+	mu    sync.Mutex
+	polls int
+	links []string
+}
+
+func (f *fakeMagicLinkInbox) Snapshot(ctx context.Context) (uint32, error) { return 10, nil }
+
+func (f *fakeMagicLinkInbox) FindLink(ctx context.Context, since uint32, pattern *regexp.Regexp) (string, uint32, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.polls++
+	if len(f.links) == 0 {
+		return "", since, nil
+	}
+	link := f.links[0]
+	f.links = f.links[1:]
+	return link, since + 1, nil
+}
+
+type fakePlaywright struct {
+	mu    sync.Mutex
+	calls []string
+	// executeFunc, when set, produces the response; otherwise Execute succeeds.
+	executeFunc func(id string, body kernel.BrowserPlaywrightExecuteParams) (*kernel.BrowserPlaywrightExecuteResponse, error)
+}
+
+func (f *fakePlaywright) Execute(ctx context.Context, id string, body kernel.BrowserPlaywrightExecuteParams, opts ...option.RequestOption) (*kernel.BrowserPlaywrightExecuteResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, id+": "+body.Code)
+	if f.executeFunc != nil {
+		return f.executeFunc(id, body)
+	}
+	return &kernel.BrowserPlaywrightExecuteResponse{Success: true}, nil
+}
+
+func (f *fakePlaywright) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.calls)
+}
+
+func followStateEvent(t *testing.T, data string) kernel.AuthConnectionFollowResponseUnion {
+	t.Helper()
+	var event kernel.AuthConnectionFollowResponseUnion
+	require.NoError(t, json.Unmarshal([]byte(data), &event))
+	return event
+}
+
+func TestMagicLinkWatcher_OpensNewLinkInLoginBrowser(t *testing.T) {
+	setupStdoutCapture(t)
+
+	inbox := &fakeMagicLinkInbox{links: []string{"", `https://app.example.com/verify?t="abc"`}}
+	pw := &fakePlaywright{}
+	fake := &FakeAuthConnectionService{
+		GetFunc: func(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.ManagedAuth, error) {
+			return &kernel.ManagedAuth{ID: id, BrowserSessionID: "sess-1"}, nil
+		},
+	}
+	w := &magicLinkWatcher{inbox: inbox, pattern: regexp.MustCompile(`.`), svc: fake, playwright: pw, id: "conn", interval: time.Millisecond}
+	require.NoError(t, w.start(context.Background()))
+	assert.Equal(t, uint32(10), w.since)
+
+	ctx := context.Background()
+	w.Observe(ctx, followStateEvent(t, `{"event":"managed_auth_state","flow_status":"IN_PROGRESS","flow_step":"AWAITING_EXTERNAL_ACTION","external_action_message":"Check your email"}`))
+	require.Eventually(t, func() bool { return len(pw.Calls()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{`sess-1: await page.goto("https://app.example.com/verify?t=\"abc\"");`}, pw.Calls())
+
+	// Repeated states don't restart polling once the link has been opened.
+	w.Observe(ctx, followStateEvent(t, `{"event":"managed_auth_state","flow_status":"IN_PROGRESS","flow_step":"AWAITING_EXTERNAL_ACTION"}`))
+	w.Observe(ctx, followStateEvent(t, `{"event":"managed_auth_state","flow_status":"SUCCESS","flow_step":"COMPLETED"}`))
+	assert.Len(t, pw.Calls(), 1)
+	assert.Contains(t, outBuf.String(), "Opened sign-in link")
+}
+
+func TestAuthConnectionsFollow_MagicLinkRequiresPattern(t *testing.T) {
+	fake := &FakeAuthConnectionService{
+		GetFunc: func(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.ManagedAuth, error) {
+			return &kernel.ManagedAuth{ID: id}, nil
+		},
+	}
+	c := AuthConnectionCmd{svc: fake, playwright: &fakePlaywright{}}
+
+	err := c.Follow(context.Background(), AuthConnectionFollowInput{ID: "conn", MagicLinkIMAP: "imaps://me@imap.example.com/INBOX"})
+	assert.ErrorContains(t, err, "--magic-link-pattern is required")
+}
+
+func TestAuthConnectionsLoginAll_SummarizesOutcomes(t *testing.T) {
+	setupStdoutCapture(t)
+
+	var mu sync.Mutex
+	var listed kernel.AuthConnectionListParams
+	var loggedIn []string
+	polls := map[string]int{}
+	fake := &FakeAuthConnectionService{
+		ListFunc: func(ctx context.Context, query kernel.AuthConnectionListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.ManagedAuth], error) {
+			listed = query
+			return &pagination.OffsetPagination[kernel.ManagedAuth]{Items: []kernel.ManagedAuth{
+				{ID: "ok", Domain: "ok.com", Status: kernel.ManagedAuthStatusNeedsAuth, Credential: kernel.ManagedAuthCredential{Name: "ok-creds"}},
+				{ID: "mfa", Domain: "mfa.com", Status: kernel.ManagedAuthStatusNeedsAuth},
+				{ID: "running", Domain: "running.com", Status: kernel.ManagedAuthStatusNeedsAuth, FlowStatus: kernel.ManagedAuthFlowStatusInProgress},
+				{ID: "fine", Domain: "fine.com", Status: kernel.ManagedAuthStatusAuthenticated},
+			}}, nil
+		},
+		LoginFunc: func(ctx context.Context, id string, body kernel.AuthConnectionLoginParams, opts ...option.RequestOption) (*kernel.LoginResponse, error) {
+			mu.Lock()
+			loggedIn = append(loggedIn, id)
+			mu.Unlock()
+			return &kernel.LoginResponse{ID: id}, nil
+		},
+		GetFunc: func(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.ManagedAuth, error) {
+			mu.Lock()
+			polls[id]++
+			n := polls[id]
+			mu.Unlock()
+			auth := &kernel.ManagedAuth{ID: id, Domain: id + ".com", FlowStatus: kernel.ManagedAuthFlowStatusInProgress}
+			switch id {
+			case "ok":
+				// A linked credential is submitted after a brief wait on input.
+				if n == 1 {
+					auth.FlowStep = kernel.ManagedAuthFlowStepAwaitingInput
+				} else {
+					auth.FlowStatus = kernel.ManagedAuthFlowStatusSuccess
+					auth.FlowStep = kernel.ManagedAuthFlowStepCompleted
+				}
+			case "mfa":
+				auth.FlowStep = kernel.ManagedAuthFlowStepAwaitingInput
+				auth.HostedURL = "https://auth.example/mfa"
+			case "running":
+				auth.FlowStatus = kernel.ManagedAuthFlowStatusFailed
+				auth.ErrorMessage = "bad password"
+			}
+			return auth, nil
+		},
+	}
+	c := AuthConnectionCmd{svc: fake}
+
+	err := c.LoginAll(context.Background(), AuthConnectionLoginAllInput{
+		Filters:      []string{"domain=example.com"},
+		PollInterval: time.Millisecond,
+		HandoffGrace: 20 * time.Millisecond,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 of 3")
+	assert.Equal(t, "example.com", listed.Domain.Value)
+	assert.ElementsMatch(t, []string{"ok", "mfa"}, loggedIn, "in-progress flows are followed, not restarted")
+
+	out := outBuf.String()
+	assert.Contains(t, out, "ok-creds")
+	assert.Contains(t, out, "needs human")
+	assert.Contains(t, out, "https://auth.example/mfa")
+	assert.Contains(t, out, "bad password")
+	assert.Contains(t, out, "1 logged in, 1 needs human, 1 failed")
+	assert.Less(t, strings.LastIndex(out, "mfa.com"), strings.LastIndex(out, "running.com"), "needs-human rows come first")
+}
+
+func TestParseLoginAllFilters(t *testing.T) {
+	f, err := parseLoginAllFilters(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "NEEDS_AUTH", f.Status)
+
+	f, err = parseLoginAllFilters([]string{"status=any", "profile=work"})
+	require.NoError(t, err)
+	assert.Equal(t, loginAllFilter{Status: "ANY", Profile: "work"}, f)
+
+	_, err = parseLoginAllFilters([]string{"status=bogus"})
+	assert.ErrorContains(t, err, "invalid status filter")
+	_, err = parseLoginAllFilters([]string{"owner=me"})
+	assert.ErrorContains(t, err, "unknown --filter key")
+	_, err = parseLoginAllFilters([]string{"domain"})
+	assert.ErrorContains(t, err, "expected key=value")
+}
+
+func TestAllowedDomainsFlag_AcceptsDeprecatedAlias(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().StringSlice("allowed-domain", []string{}, "")
+	addDeprecatedAllowedDomainsFlag(cmd)
+	cmd.Flags().SetOutput(io.Discard)
+	require.NoError(t, cmd.Flags().Parse([]string{"--allowed-domain=a.example.com", "--allowed-domains=b.example.com,c.example.com"}))
+
+	assert.Equal(t, []string{"a.example.com", "b.example.com", "c.example.com"}, allowedDomainsFlag(cmd))
+	assert.True(t, cmd.Flags().Lookup("allowed-domains").Hidden)
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kernel/kernel-go-sdk"
+)
+
+// Exit codes for follow timeouts, so pipelines can tell a slow site from a
+// handoff nobody picked up.
+const (
+	exitFlowTimeout  = 3
+	exitStepTimeout  = 4
+	exitHumanTimeout = 5
+)
+
+var (
+	errFlowTimeout  = errors.New("login flow timed out")
+	errStepTimeout  = errors.New("login step timed out")
+	errHumanTimeout = errors.New("live view handoff timed out")
+)
+
+// exitCodeError makes the process exit with code instead of 1.
+type exitCodeError struct {
+	err  error
+	code int
+}
+
+func (e exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e exitCodeError) Unwrap() error {
+	return e.err
+}
+
+func (e exitCodeError) ExitCode() int {
+	return e.code
+}
+
+// stepTimer bounds how long a flow may stay on one step. Waiting on a human
+// (AWAITING_EXTERNAL_ACTION) has its own limit; either is off when zero.
+type stepTimer struct {
+	step  time.Duration
+	human time.Duration
+	// cancel aborts the follow stream with errStepTimeout or errHumanTimeout.
+	cancel context.CancelCauseFunc
+
+	timer   *time.Timer
+	current string
+}
+
+func (t *stepTimer) reset() {
+	t.stop()
+	t.current = ""
+}
+
+func (t *stepTimer) stop() {
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+}
+
+func (t *stepTimer) Observe(event kernel.AuthConnectionFollowResponseUnion) {
+	if event.Event != "managed_auth_state" {
+		return
+	}
+	state := event.AsManagedAuthState()
+	if state.FlowStatus != "IN_PROGRESS" {
+		t.reset()
+		return
+	}
+	if state.FlowStep == t.current {
+		return
+	}
+	t.stop()
+	t.current = state.FlowStep
+
+	limit, cause := t.step, errStepTimeout
+	if state.FlowStep == "AWAITING_EXTERNAL_ACTION" {
+		limit, cause = t.human, errHumanTimeout
+	}
+	if limit > 0 {
+		t.timer = time.AfterFunc(limit, func() { t.cancel(cause) })
+	}
+}
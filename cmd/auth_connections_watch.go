@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kernel/cli/pkg/table"
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"golang.org/x/sync/errgroup"
+)
+
+// authWatchConcurrency bounds parallel Get calls per polling round.
+const authWatchConcurrency = 8
+
+type AuthConnectionWatchInput struct {
+	IDs       []string
+	AllActive bool
+	Interval  time.Duration
+	Output    string
+}
+
+// authWatchState is the latest observed state of one watched connection.
+type authWatchState struct {
+	ID           string                       `json:"id"`
+	Domain       string                       `json:"domain,omitempty"`
+	ProfileName  string                       `json:"profile_name,omitempty"`
+	FlowStatus   kernel.ManagedAuthFlowStatus `json:"flow_status,omitempty"`
+	FlowStep     kernel.ManagedAuthFlowStep   `json:"flow_step,omitempty"`
+	Action       string                       `json:"action,omitempty"`
+	Error        string                       `json:"error,omitempty"`
+	done         bool
+	changedSince time.Time
+}
+
+func newAuthWatchState(id string, auth *kernel.ManagedAuth, err error) authWatchState {
+	s := authWatchState{ID: id}
+	if err != nil {
+		s.Error = util.CleanedUpSdkError{Err: err}.Error()
+		// A connection that no longer exists will never finish its flow.
+		s.done = util.IsNotFound(err)
+		return s
+	}
+	s.Domain = auth.Domain
+	s.ProfileName = auth.ProfileName
+	s.FlowStatus = auth.FlowStatus
+	s.FlowStep = auth.FlowStep
+	s.done = auth.FlowStatus != kernel.ManagedAuthFlowStatusInProgress
+	switch {
+	case auth.ErrorMessage != "":
+		s.Error = auth.ErrorMessage
+	case auth.WebsiteError != "":
+		s.Error = auth.WebsiteError
+	}
+	if s.FlowStatus == kernel.ManagedAuthFlowStatusInProgress {
+		switch s.FlowStep {
+		case kernel.ManagedAuthFlowStepAwaitingInput:
+			s.Action = "input needed"
+			if auth.HostedURL != "" {
+				s.Action += ": " + auth.HostedURL
+			}
+		case kernel.ManagedAuthFlowStepAwaitingExternalAction:
+			s.Action = auth.ExternalActionMessage
+			if s.Action == "" {
+				s.Action = "external action needed"
+			}
+		}
+	}
+	return s
+}
+
+func (s authWatchState) sameAs(o authWatchState) bool {
+	return s.FlowStatus == o.FlowStatus && s.FlowStep == o.FlowStep && s.Action == o.Action && s.Error == o.Error
+}
+
+// Watch polls several connections' login flows and renders them as one live
+// table until every flow has finished.
+func (c AuthConnectionCmd) Watch(ctx context.Context, in AuthConnectionWatchInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if in.AllActive == (len(in.IDs) > 0) {
+		return fmt.Errorf("specify connection IDs or --all-active")
+	}
+	if in.Interval <= 0 {
+		in.Interval = 2 * time.Second
+	}
+	jsonOutput := in.Output == "json"
+
+	ids := in.IDs
+	if in.AllActive {
+		var err error
+		if ids, err = c.activeFlowIDs(ctx); err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			if !jsonOutput {
+				pterm.Info.Println("No login flows in progress")
+			}
+			return nil
+		}
+	}
+
+	var area *pterm.AreaPrinter
+	if !jsonOutput {
+		pterm.Info.Printf("Watching %d login flow(s) (Ctrl+C to stop)...\n", len(ids))
+		if table.IsStdoutTTY() {
+			area, _ = pterm.DefaultArea.Start()
+			defer func() { _ = area.Stop() }()
+		}
+	}
+
+	states := make(map[string]authWatchState, len(ids))
+	for {
+		next := c.pollAuthWatch(ctx, ids)
+		if ctx.Err() != nil {
+			return nil
+		}
+		now := time.Now()
+		allDone := true
+		for _, id := range ids {
+			s := next[id]
+			prev, seen := states[id]
+			if seen && prev.sameAs(s) {
+				s.changedSince = prev.changedSince
+			} else {
+				s.changedSince = now
+				if jsonOutput {
+					_ = util.PrintJSONLine(s)
+				} else if area == nil {
+					printAuthWatchChange(s)
+				}
+			}
+			states[id] = s
+			allDone = allDone && s.done
+		}
+		if area != nil {
+			area.Update(renderAuthWatchTable(ids, states, now))
+		}
+		if allDone {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(in.Interval):
+		}
+	}
+
+	var unsuccessful int
+	for _, s := range states {
+		if (s.FlowStatus == "" && s.Error != "") || (s.FlowStatus != "" && s.FlowStatus != kernel.ManagedAuthFlowStatusSuccess) {
+			unsuccessful++
+		}
+	}
+	if !jsonOutput {
+		if unsuccessful == 0 {
+			pterm.Success.Printf("All %d login flow(s) finished\n", len(ids))
+		} else {
+			pterm.Warning.Printf("%d of %d login flow(s) did not succeed\n", unsuccessful, len(ids))
+		}
+	}
+	if unsuccessful > 0 {
+		return fmt.Errorf("%d of %d login flows did not succeed", unsuccessful, len(ids))
+	}
+	return nil
+}
+
+// activeFlowIDs returns the IDs of every connection with a login flow in progress.
+func (c AuthConnectionCmd) activeFlowIDs(ctx context.Context) ([]string, error) {
+	const pageSize = 100
+	var ids []string
+	for offset := 0; ; offset += pageSize {
+		page, err := c.svc.List(ctx, kernel.AuthConnectionListParams{
+			Limit:  kernel.Opt(int64(pageSize)),
+			Offset: kernel.Opt(int64(offset)),
+		})
+		if err != nil {
+			return nil, util.CleanedUpSdkError{Err: err}
+		}
+		if page == nil {
+			return ids, nil
+		}
+		for _, auth := range page.Items {
+			if auth.FlowStatus == kernel.ManagedAuthFlowStatusInProgress {
+				ids = append(ids, auth.ID)
+			}
+		}
+		if len(page.Items) < pageSize {
+			return ids, nil
+		}
+	}
+}
+
+func (c AuthConnectionCmd) pollAuthWatch(ctx context.Context, ids []string) map[string]authWatchState {
+	var mu sync.Mutex
+	out := make(map[string]authWatchState, len(ids))
+	var g errgroup.Group
+	g.SetLimit(authWatchConcurrency)
+	for _, id := range ids {
+		g.Go(func() error {
+			auth, err := c.svc.Get(ctx, id)
+			s := newAuthWatchState(id, auth, err)
+			mu.Lock()
+			out[id] = s
+			mu.Unlock()
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return out
+}
+
+func printAuthWatchChange(s authWatchState) {
+	line := fmt.Sprintf("[%s] %s", s.ID, s.Domain)
+	if s.FlowStatus != "" {
+		line += fmt.Sprintf(" %s", s.FlowStatus)
+	}
+	if s.FlowStep != "" {
+		line += fmt.Sprintf(" / %s", s.FlowStep)
+	}
+	if s.Action != "" {
+		line += " - " + s.Action
+	}
+	if s.Error != "" {
+		pterm.Warning.Println(line + " - " + s.Error)
+		return
+	}
+	pterm.Info.Println(line)
+}
+
+func renderAuthWatchTable(ids []string, states map[string]authWatchState, now time.Time) string {
+	rows := make([]authWatchState, 0, len(ids))
+	for _, id := range ids {
+		rows = append(rows, states[id])
+	}
+	// Flows waiting on a human float to the top, finished flows sink.
+	sort.SliceStable(rows, func(i, j int) bool {
+		return authWatchRank(rows[i]) < authWatchRank(rows[j])
+	})
+
+	data := pterm.TableData{{"ID", "Domain", "Profile", "Status", "Step", "Since", "Action / Error"}}
+	for _, s := range rows {
+		status := string(s.FlowStatus)
+		switch s.FlowStatus {
+		case kernel.ManagedAuthFlowStatusSuccess:
+			status = pterm.Green(status)
+		case kernel.ManagedAuthFlowStatusFailed, kernel.ManagedAuthFlowStatusExpired, kernel.ManagedAuthFlowStatusCanceled:
+			status = pterm.Red(status)
+		}
+		note := s.Action
+		if s.Error != "" {
+			note = strings.TrimSpace(strings.Join([]string{note, pterm.Red(s.Error)}, " "))
+		}
+		data = append(data, []string{
+			s.ID,
+			s.Domain,
+			s.ProfileName,
+			status,
+			string(s.FlowStep),
+			now.Sub(s.changedSince).Round(time.Second).String(),
+			note,
+		})
+	}
+	out, _ := pterm.DefaultTable.WithHasHeader().WithData(data).Srender()
+	return out
+}
+
+func authWatchRank(s authWatchState) int {
+	switch {
+	case s.Action != "":
+		return 0
+	case !s.done:
+		return 1
+	default:
+		return 2
+	}
+}
@@ -60,11 +60,11 @@ func (c BrowserPoolsCmd) List(ctx context.Context, in BrowserPoolsListInput) err
 
 	if in.Output == "json" {
 		if len(pools) == 0 {
-			fmt.Println("[]")
-			return nil
+			return util.PrintJSON([]any{})
 		}
 		return util.PrintPrettyJSONSlice(pools)
 	}
+	util.WarnDecodeDrift(pools)
 
 	if len(pools) == 0 {
 		pterm.Info.Println("No browser pools found")
@@ -227,6 +227,7 @@ func (c BrowserPoolsCmd) Create(ctx context.Context, in BrowserPoolsCreateInput)
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(pool)
 	}
+	util.WarnDecodeDrift(pool)
 
 	if pool.Name != "" {
 		pterm.Success.Printf("Created browser pool %s (%s)\n", pool.Name, pool.ID)
@@ -257,6 +258,7 @@ func (c BrowserPoolsCmd) Get(ctx context.Context, in BrowserPoolsGetInput) error
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(pool)
 	}
+	util.WarnDecodeDrift(pool)
 
 	cfg := pool.BrowserPoolConfig
 
@@ -413,6 +415,7 @@ func (c BrowserPoolsCmd) Update(ctx context.Context, in BrowserPoolsUpdateInput)
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(pool)
 	}
+	util.WarnDecodeDrift(pool)
 
 	if pool.Name != "" {
 		pterm.Success.Printf("Updated browser pool %s (%s)\n", pool.Name, pool.ID)
@@ -492,8 +495,7 @@ func (c BrowserPoolsCmd) Acquire(ctx context.Context, in BrowserPoolsAcquireInpu
 	}
 	if resp == nil {
 		if in.Output == "json" {
-			fmt.Println("null")
-			return nil
+			return util.PrintJSON(nil)
 		}
 		pterm.Warning.Println("Acquire request timed out (no browser available). Retry to continue waiting.")
 		return nil
@@ -502,6 +504,7 @@ func (c BrowserPoolsCmd) Acquire(ctx context.Context, in BrowserPoolsAcquireInpu
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(resp)
 	}
+	util.WarnDecodeDrift(resp)
 
 	tableData := pterm.TableData{
 		{"Property", "Value"},
@@ -17,6 +17,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/kernel/cli/pkg/lock"
 	"github.com/kernel/cli/pkg/table"
 	"github.com/kernel/cli/pkg/util"
 	"github.com/kernel/kernel-go-sdk"
@@ -24,6 +25,7 @@ import (
 	"github.com/kernel/kernel-go-sdk/packages/pagination"
 	"github.com/kernel/kernel-go-sdk/packages/ssestream"
 	"github.com/kernel/kernel-go-sdk/shared"
+	"github.com/pkg/browser"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -235,6 +237,15 @@ func tagsFromFlag(cmd *cobra.Command, flagName string) (map[string]string, bool)
 	return tags, provided
 }
 
+// labelFlagAsTag lets --label stand in for --tag, the name other tools use
+// for session key/value pairs.
+func labelFlagAsTag(f *pflag.FlagSet, name string) pflag.NormalizedName {
+	if name == "label" {
+		name = "tag"
+	}
+	return pflag.NormalizedName(name)
+}
+
 // formatTags renders tags as a deterministic "k=v, k2=v2" string with keys
 // sorted, for display in detail tables.
 func formatTags(tags kernel.Tags) string {
@@ -274,6 +285,8 @@ type BrowsersCreateInput struct {
 	Name               string
 	Tags               map[string]string
 	Output             string
+	// Copy puts the live view URL on the clipboard.
+	Copy bool
 }
 
 type BrowsersDeleteInput struct {
@@ -283,6 +296,12 @@ type BrowsersDeleteInput struct {
 type BrowsersViewInput struct {
 	Identifier string
 	Output     string
+	// Open launches the live view in the system browser.
+	Open bool
+	// LocalPort, when set, serves the live view through a local proxy.
+	LocalPort int
+	// Copy puts the live view URL on the clipboard.
+	Copy bool
 }
 
 type BrowsersGetInput struct {
@@ -310,6 +329,14 @@ type BrowsersUpdateInput struct {
 	Output             string
 }
 
+type BrowsersAnnotateInput struct {
+	Identifier  string
+	Set         map[string]string
+	SetProvided bool
+	Unset       []string
+	Output      string
+}
+
 // BrowsersCmd is a cobra-independent command handler for browsers operations.
 type BrowsersCmd struct {
 	browsers   BrowsersService
@@ -321,6 +348,11 @@ type BrowsersCmd struct {
 	computer   BrowserComputerService
 	playwright BrowserPlaywrightService
 	telemetry  BrowserTelemetryService
+	// profileLocks is optional; when set, create locks the requested profile
+	// and delete releases locks held for the session.
+	profileLocks *profileLocker
+	// openURL is optional; view --open uses it to launch the system browser.
+	openURL func(url string) error
 }
 
 type BrowsersListInput struct {
@@ -380,6 +412,7 @@ func (b BrowsersCmd) List(ctx context.Context, in BrowsersListInput) error {
 	if in.Output == "json" {
 		return util.PrintPrettyJSONSlice(browsers)
 	}
+	util.WarnDecodeDrift(browsers)
 
 	if len(browsers) == 0 {
 		pterm.Info.Println("No running browsers found")
@@ -387,7 +420,7 @@ func (b BrowsersCmd) List(ctx context.Context, in BrowsersListInput) error {
 	}
 
 	// Prepare table data
-	headers := []string{"Browser ID", "Name", "Created At", "Profile", "Pool", "CDP WS URL", "Live View URL"}
+	headers := []string{"Browser ID", "Name", "Tags", "Created At", "Profile", "Pool", "CDP WS URL", "Live View URL"}
 	showDeletedAt := in.IncludeDeleted || in.Status == "deleted" || in.Status == "all"
 	if showDeletedAt {
 		headers = append(headers, "Deleted At")
@@ -412,6 +445,7 @@ func (b BrowsersCmd) List(ctx context.Context, in BrowsersListInput) error {
 		row := []string{
 			browser.SessionID,
 			util.OrDash(browser.Name),
+			util.OrDash(formatTags(browser.Tags)),
 			util.FormatLocal(browser.CreatedAt),
 			profile,
 			pool,
@@ -438,11 +472,55 @@ func (b BrowsersCmd) Create(ctx context.Context, in BrowsersCreateInput) error {
 	if err := validateJSONOutput(in.Output); err != nil {
 		return err
 	}
-	if err := validateStartURLFlag(in.StartURL); err != nil {
+
+	// Validate profile selection: at most one of profile-id or profile-name must be provided
+	if in.ProfileID != "" && in.ProfileName != "" {
+		pterm.Error.Println("must specify at most one of --profile-id or --profile-name")
+		return nil
+	}
+	if in.Viewport != "" {
+		if _, _, _, err := parseViewport(in.Viewport); err != nil {
+			pterm.Error.Printf("Invalid viewport format: %v\n", err)
+			return nil
+		}
+	}
+
+	params, err := buildBrowserNewParams(in)
+	if err != nil {
+		return err
+	}
+
+	if in.Output != "json" {
+		pterm.Info.Println("Creating browser session...")
+	}
+	browser, err := b.newSession(ctx, in, params)
+	if err != nil {
 		return err
 	}
 
+	if in.Output == "json" {
+		if err := util.PrintPrettyJSON(browser); err != nil {
+			return err
+		}
+	} else {
+		util.WarnDecodeDrift(browser)
+		printBrowserSessionResult(browser.SessionID, browser.CdpWsURL, browser.BrowserLiveViewURL, browser.Profile, browser.StartURL, browser.Name, browser.Tags)
+		if in.Telemetry != "" {
+			printTelemetrySummary(browser.Telemetry)
+		}
+	}
+	if in.Copy {
+		copyOutput("live view URL", browser.BrowserLiveViewURL)
+	}
+	return nil
+}
+
+// buildBrowserNewParams maps create input onto SDK request params.
+func buildBrowserNewParams(in BrowsersCreateInput) (kernel.BrowserNewParams, error) {
 	params := kernel.BrowserNewParams{}
+	if err := validateStartURLFlag(in.StartURL); err != nil {
+		return params, err
+	}
 	if in.TimeoutSeconds > 0 {
 		params.TimeoutSeconds = kernel.Opt(int64(in.TimeoutSeconds))
 	}
@@ -462,10 +540,8 @@ func (b BrowsersCmd) Create(ctx context.Context, in BrowsersCreateInput) error {
 		params.KioskMode = kernel.Opt(in.Kiosk.Value)
 	}
 
-	// Validate profile selection: at most one of profile-id or profile-name must be provided
 	if in.ProfileID != "" && in.ProfileName != "" {
-		pterm.Error.Println("must specify at most one of --profile-id or --profile-name")
-		return nil
+		return params, fmt.Errorf("must specify at most one of profile id or profile name")
 	} else if in.ProfileID != "" || in.ProfileName != "" {
 		params.Profile = kernel.BrowserProfileParam{
 			SaveChanges: kernel.Opt(in.ProfileSaveChanges.Value),
@@ -486,28 +562,25 @@ func (b BrowsersCmd) Create(ctx context.Context, in BrowsersCreateInput) error {
 	}
 
 	// Map extensions (IDs or names) into params.Extensions
-	if len(in.Extensions) > 0 {
-		for _, ext := range in.Extensions {
-			val := strings.TrimSpace(ext)
-			if val == "" {
-				continue
-			}
-			item := kernel.BrowserExtensionParam{}
-			if cuidRegex.MatchString(val) {
-				item.ID = kernel.Opt(val)
-			} else {
-				item.Name = kernel.Opt(val)
-			}
-			params.Extensions = append(params.Extensions, item)
+	for _, ext := range in.Extensions {
+		val := strings.TrimSpace(ext)
+		if val == "" {
+			continue
 		}
+		item := kernel.BrowserExtensionParam{}
+		if cuidRegex.MatchString(val) {
+			item.ID = kernel.Opt(val)
+		} else {
+			item.Name = kernel.Opt(val)
+		}
+		params.Extensions = append(params.Extensions, item)
 	}
 
 	// Add viewport if specified
 	if in.Viewport != "" {
 		width, height, refreshRate, err := parseViewport(in.Viewport)
 		if err != nil {
-			pterm.Error.Printf("Invalid viewport format: %v\n", err)
-			return nil
+			return params, fmt.Errorf("invalid viewport format: %w", err)
 		}
 		params.Viewport = kernel.BrowserViewportParam{
 			Width:  width,
@@ -521,14 +594,14 @@ func (b BrowsersCmd) Create(ctx context.Context, in BrowsersCreateInput) error {
 	if in.Telemetry != "" {
 		t, err := buildNewTelemetryParam(in.Telemetry)
 		if err != nil {
-			return err
+			return params, err
 		}
 		params.Telemetry = t
 	}
 
 	chromePolicy, err := parseChromePolicy(in.ChromePolicy, in.ChromePolicyFile)
 	if err != nil {
-		return err
+		return params, err
 	}
 	if len(chromePolicy) > 0 {
 		params.ChromePolicy = chromePolicy
@@ -540,24 +613,44 @@ func (b BrowsersCmd) Create(ctx context.Context, in BrowsersCreateInput) error {
 	if len(in.Tags) > 0 {
 		params.Tags = kernel.Tags(in.Tags)
 	}
+	return params, nil
+}
 
-	if in.Output != "json" {
-		pterm.Info.Println("Creating browser session...")
+// newSession creates a browser session, holding a profile lock for its
+// lifetime when profile locking is enabled.
+func (b BrowsersCmd) newSession(ctx context.Context, in BrowsersCreateInput, params kernel.BrowserNewParams) (*kernel.BrowserNewResponse, error) {
+	var profileLock *lock.Lock
+	if b.profileLocks != nil && (in.ProfileID != "" || in.ProfileName != "") {
+		ttl := defaultProfileLockTTL
+		if in.TimeoutSeconds > 0 {
+			ttl = time.Duration(in.TimeoutSeconds) * time.Second
+		}
+		profileRef := in.ProfileID
+		if profileRef == "" {
+			profileRef = in.ProfileName
+		}
+		var err error
+		profileLock, err = b.profileLocks.Acquire(ctx, profileRef, lock.Options{TTL: ttl, Note: "kernel browsers create"})
+		if err != nil {
+			return nil, err
+		}
 	}
+
 	browser, err := b.browsers.New(ctx, params)
 	if err != nil {
-		return util.CleanedUpSdkError{Err: err}
-	}
-
-	if in.Output == "json" {
-		return util.PrintPrettyJSON(browser)
+		if b.profileLocks != nil {
+			b.profileLocks.Release(profileLock)
+		}
+		return nil, util.CleanedUpSdkError{Err: err}
 	}
-
-	printBrowserSessionResult(browser.SessionID, browser.CdpWsURL, browser.BrowserLiveViewURL, browser.Profile, browser.StartURL, browser.Name, browser.Tags)
-	if in.Telemetry != "" {
-		printTelemetrySummary(browser.Telemetry)
+	if profileLock != nil {
+		// Tie the lock to the session so "browsers delete" releases it.
+		ttl := profileLock.ExpiresAt.Sub(profileLock.AcquiredAt)
+		if _, err := b.profileLocks.store.Acquire(profileLock.Resource, lock.Options{TTL: ttl, Owner: profileLock.Owner, Note: profileLock.Note, SessionID: browser.SessionID}); err != nil {
+			pterm.Debug.Printf("Failed to tag profile lock with session: %v\n", err)
+		}
 	}
-	return nil
+	return browser, nil
 }
 
 func printBrowserSessionResult(sessionID, cdpURL, liveViewURL string, profile kernel.Profile, startURL, name string, tags kernel.Tags) {
@@ -594,33 +687,60 @@ func buildBrowserTableData(sessionID, cdpURL, liveViewURL string, profile kernel
 	return tableData
 }
 
+// getBrowser fetches a browser by session ID, name, or unambiguous ID prefix.
+func (b BrowsersCmd) getBrowser(ctx context.Context, identifier string, params kernel.BrowserGetParams) (*kernel.BrowserGetResponse, error) {
+	return withIDPrefix(ctx, "browser", identifier, browserIDLister(b.browsers), func(id string) (*kernel.BrowserGetResponse, error) {
+		return b.browsers.Get(ctx, id, params)
+	})
+}
+
 func (b BrowsersCmd) Delete(ctx context.Context, in BrowsersDeleteInput) error {
+	sessionID := in.Identifier
+	_, err := withIDPrefix(ctx, "browser", in.Identifier, browserIDLister(b.browsers), func(id string) (struct{}, error) {
+		sessionID = id
+		return struct{}{}, b.browsers.DeleteByID(ctx, id)
+	})
 	// Treat not found as a success (idempotent delete)
-	if err := b.browsers.DeleteByID(ctx, in.Identifier); err != nil && !util.IsNotFound(err) {
+	if err != nil && !util.IsNotFound(err) {
 		return util.CleanedUpSdkError{Err: err}
 	}
-	pterm.Success.Printf("Successfully deleted (or already absent) browser: %s\n", in.Identifier)
+	b.releaseProfileLocks(sessionID)
+	pterm.Success.Printf("Successfully deleted (or already absent) browser: %s\n", sessionID)
 	return nil
 }
 
+// releaseProfileLocks drops the local profile locks a deleted session held.
+func (b BrowsersCmd) releaseProfileLocks(sessionID string) {
+	if b.profileLocks == nil {
+		return
+	}
+	released, err := b.profileLocks.store.ReleaseSession(sessionID)
+	if err != nil {
+		pterm.Debug.Printf("Failed to release profile locks: %v\n", err)
+	}
+	for _, l := range released {
+		pterm.Debug.Printf("Released %s\n", l.Resource)
+	}
+}
+
 func (b BrowsersCmd) View(ctx context.Context, in BrowsersViewInput) error {
 	if err := validateJSONOutput(in.Output); err != nil {
 		return err
 	}
 
-	browser, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	browser, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
 
-	if in.Output == "json" {
+	if in.Output == "json" && in.LocalPort == 0 {
 		// View command returns a custom response, not the full browser object
-		// Use json.Marshal to ensure proper JSON escaping of the URL
-		urlBytes, err := json.Marshal(browser.BrowserLiveViewURL)
-		if err != nil {
+		if err := util.PrintJSON(map[string]string{"liveViewUrl": browser.BrowserLiveViewURL}); err != nil {
 			return err
 		}
-		fmt.Printf("{\n  \"liveViewUrl\": %s\n}\n", urlBytes)
+		if in.Copy {
+			copyOutput("live view URL", browser.BrowserLiveViewURL)
+		}
 		return nil
 	}
 
@@ -633,7 +753,16 @@ func (b BrowsersCmd) View(ctx context.Context, in BrowsersViewInput) error {
 		return nil
 	}
 
+	if in.LocalPort > 0 {
+		return b.serveLiveView(ctx, browser.BrowserLiveViewURL, in.LocalPort, in.Open, in.Copy)
+	}
 	fmt.Println(browser.BrowserLiveViewURL)
+	if in.Copy {
+		copyOutput("live view URL", browser.BrowserLiveViewURL)
+	}
+	if in.Open {
+		b.openLiveView(browser.BrowserLiveViewURL)
+	}
 	return nil
 }
 
@@ -647,13 +776,14 @@ func (b BrowsersCmd) Get(ctx context.Context, in BrowsersGetInput) error {
 		query.IncludeDeleted = kernel.Opt(true)
 	}
 
-	browser, err := b.browsers.Get(ctx, in.Identifier, query)
+	browser, err := b.getBrowser(ctx, in.Identifier, query)
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(browser)
 	}
+	util.WarnDecodeDrift(browser)
 
 	// Build table starting with common browser fields
 	tableData := buildBrowserTableData(
@@ -823,7 +953,9 @@ func (b BrowsersCmd) Update(ctx context.Context, in BrowsersUpdateInput) error {
 		pterm.Info.Printf("Updating browser %s...\n", in.Identifier)
 	}
 
-	browser, err := b.browsers.Update(ctx, in.Identifier, params)
+	browser, err := withIDPrefix(ctx, "browser", in.Identifier, browserIDLister(b.browsers), func(id string) (*kernel.BrowserUpdateResponse, error) {
+		return b.browsers.Update(ctx, id, params)
+	})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -831,6 +963,7 @@ func (b BrowsersCmd) Update(ctx context.Context, in BrowsersUpdateInput) error {
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(browser)
 	}
+	util.WarnDecodeDrift(browser)
 
 	pterm.Success.Printf("Updated browser %s\n", browser.SessionID)
 	if hasNameChange {
@@ -845,6 +978,63 @@ func (b BrowsersCmd) Update(ctx context.Context, in BrowsersUpdateInput) error {
 	return nil
 }
 
+// maxBrowserTags is the API's limit on tags per session.
+const maxBrowserTags = 50
+
+// Annotate merges tag changes into a session's existing tags, unlike
+// `update --tag`, which replaces the whole set. The API only supports full
+// replacement, so this reads the current tags first; a concurrent change
+// between the read and the write is overwritten.
+func (b BrowsersCmd) Annotate(ctx context.Context, in BrowsersAnnotateInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if in.SetProvided && len(in.Set) == 0 && len(in.Unset) == 0 {
+		return fmt.Errorf("no valid --set KEY=VALUE pairs provided")
+	}
+	if len(in.Set) == 0 && len(in.Unset) == 0 {
+		return fmt.Errorf("must specify at least one --set KEY=VALUE or --unset KEY")
+	}
+	for _, key := range in.Unset {
+		if _, ok := in.Set[key]; ok {
+			return fmt.Errorf("cannot both --set and --unset %q", key)
+		}
+	}
+
+	current, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+
+	tags := kernel.Tags{}
+	for k, v := range current.Tags {
+		tags[k] = v
+	}
+	for _, key := range in.Unset {
+		delete(tags, key)
+	}
+	for k, v := range in.Set {
+		tags[k] = v
+	}
+	if len(tags) > maxBrowserTags {
+		return fmt.Errorf("a browser session can have at most %d tags; this would set %d", maxBrowserTags, len(tags))
+	}
+
+	// An empty map clears the tags; the SDK only omits a nil one.
+	browser, err := b.browsers.Update(ctx, current.SessionID, kernel.BrowserUpdateParams{Tags: tags})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+
+	if in.Output == "json" {
+		return util.PrintPrettyJSON(browser)
+	}
+	util.WarnDecodeDrift(browser)
+	pterm.Success.Printf("Annotated browser %s\n", browser.SessionID)
+	pterm.Info.Printf("Tags: %s\n", util.OrDash(formatTags(browser.Tags)))
+	return nil
+}
+
 // Logs
 type BrowsersLogsStreamInput struct {
 	Identifier        string
@@ -859,7 +1049,7 @@ func (b BrowsersCmd) LogsStream(ctx context.Context, in BrowsersLogsStreamInput)
 		pterm.Error.Println("logs service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -985,7 +1175,7 @@ func (b BrowsersCmd) ComputerClickMouse(ctx context.Context, in BrowsersComputer
 		pterm.Error.Println("computer service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1014,7 +1204,7 @@ func (b BrowsersCmd) ComputerMoveMouse(ctx context.Context, in BrowsersComputerM
 		pterm.Error.Println("computer service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1044,7 +1234,7 @@ func (b BrowsersCmd) ComputerScreenshot(ctx context.Context, in BrowsersComputer
 		pterm.Error.Println("computer service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1080,7 +1270,7 @@ func (b BrowsersCmd) ComputerTypeText(ctx context.Context, in BrowsersComputerTy
 		pterm.Error.Println("computer service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1100,7 +1290,7 @@ func (b BrowsersCmd) ComputerPressKey(ctx context.Context, in BrowsersComputerPr
 		pterm.Error.Println("computer service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1127,7 +1317,7 @@ func (b BrowsersCmd) ComputerScroll(ctx context.Context, in BrowsersComputerScro
 		pterm.Error.Println("computer service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1153,7 +1343,7 @@ func (b BrowsersCmd) ComputerDragMouse(ctx context.Context, in BrowsersComputerD
 		pterm.Error.Println("computer service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1199,7 +1389,7 @@ func (b BrowsersCmd) ComputerSetCursor(ctx context.Context, in BrowsersComputerS
 		pterm.Error.Println("computer service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1221,7 +1411,7 @@ func (b BrowsersCmd) ComputerGetMousePosition(ctx context.Context, in BrowsersCo
 		pterm.Error.Println("computer service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1230,9 +1420,7 @@ func (b BrowsersCmd) ComputerGetMousePosition(ctx context.Context, in BrowsersCo
 		return util.CleanedUpSdkError{Err: err}
 	}
 	if in.Output == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(res)
+		return util.PrintJSON(res)
 	}
 	fmt.Printf("x: %d\ny: %d\n", res.X, res.Y)
 	return nil
@@ -1243,7 +1431,7 @@ func (b BrowsersCmd) ComputerBatch(ctx context.Context, in BrowsersComputerBatch
 		pterm.Error.Println("computer service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1267,7 +1455,7 @@ func (b BrowsersCmd) ComputerReadClipboard(ctx context.Context, in BrowsersCompu
 		pterm.Error.Println("computer service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1276,9 +1464,7 @@ func (b BrowsersCmd) ComputerReadClipboard(ctx context.Context, in BrowsersCompu
 		return util.CleanedUpSdkError{Err: err}
 	}
 	if in.Output == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(res)
+		return util.PrintJSON(res)
 	}
 	fmt.Println(res.Text)
 	return nil
@@ -1289,7 +1475,7 @@ func (b BrowsersCmd) ComputerWriteClipboard(ctx context.Context, in BrowsersComp
 		pterm.Error.Println("computer service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1310,6 +1496,7 @@ type BrowsersReplaysStartInput struct {
 	Identifier         string
 	Framerate          int
 	MaxDurationSeconds int
+	RecordAudio        bool
 	Output             string
 }
 
@@ -1329,18 +1516,20 @@ func (b BrowsersCmd) ReplaysList(ctx context.Context, in BrowsersReplaysListInpu
 		return err
 	}
 
-	items, err := b.replays.List(ctx, in.Identifier)
+	items, err := withIDPrefix(ctx, "browser", in.Identifier, browserIDLister(b.browsers), func(id string) (*[]kernel.BrowserReplayListResponse, error) {
+		return b.replays.List(ctx, id)
+	})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
 
 	if in.Output == "json" {
 		if items == nil || len(*items) == 0 {
-			fmt.Println("[]")
-			return nil
+			return util.PrintJSON([]any{})
 		}
 		return util.PrintPrettyJSONSlice(*items)
 	}
+	util.WarnDecodeDrift(items)
 
 	if items == nil || len(*items) == 0 {
 		pterm.Info.Println("No replays found")
@@ -1359,7 +1548,7 @@ func (b BrowsersCmd) ReplaysStart(ctx context.Context, in BrowsersReplaysStartIn
 		return err
 	}
 
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1370,6 +1559,9 @@ func (b BrowsersCmd) ReplaysStart(ctx context.Context, in BrowsersReplaysStartIn
 	if in.MaxDurationSeconds > 0 {
 		body.MaxDurationInSeconds = kernel.Opt(int64(in.MaxDurationSeconds))
 	}
+	if in.RecordAudio {
+		body.RecordAudio = kernel.Opt(true)
+	}
 	res, err := b.replays.Start(ctx, br.SessionID, body)
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
@@ -1378,6 +1570,7 @@ func (b BrowsersCmd) ReplaysStart(ctx context.Context, in BrowsersReplaysStartIn
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(res)
 	}
+	util.WarnDecodeDrift(res)
 
 	rows := pterm.TableData{{"Property", "Value"}, {"Replay ID", res.ReplayID}, {"View URL", res.ReplayViewURL}, {"Started At", util.FormatLocal(res.StartedAt)}}
 	PrintTableNoPad(rows, true)
@@ -1385,7 +1578,7 @@ func (b BrowsersCmd) ReplaysStart(ctx context.Context, in BrowsersReplaysStartIn
 }
 
 func (b BrowsersCmd) ReplaysStop(ctx context.Context, in BrowsersReplaysStopInput) error {
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1397,8 +1590,38 @@ func (b BrowsersCmd) ReplaysStop(ctx context.Context, in BrowsersReplaysStopInpu
 	return nil
 }
 
+// latestReplay returns the most recently started replay of a browser, or nil
+// if it has none.
+func (b BrowsersCmd) latestReplay(ctx context.Context, identifier string) (*kernel.BrowserReplayListResponse, error) {
+	items, err := withIDPrefix(ctx, "browser", identifier, browserIDLister(b.browsers), func(id string) (*[]kernel.BrowserReplayListResponse, error) {
+		return b.replays.List(ctx, id)
+	})
+	if err != nil || items == nil {
+		return nil, err
+	}
+	var latest *kernel.BrowserReplayListResponse
+	for i, r := range *items {
+		if latest == nil || r.StartedAt.After(latest.StartedAt) {
+			latest = &(*items)[i]
+		}
+	}
+	return latest, nil
+}
+
 func (b BrowsersCmd) ReplaysDownload(ctx context.Context, in BrowsersReplaysDownloadInput) error {
-	res, err := b.replays.Download(ctx, in.ReplayID, kernel.BrowserReplayDownloadParams{ID: in.Identifier})
+	if in.ReplayID == "" {
+		latest, err := b.latestReplay(ctx, in.Identifier)
+		if err != nil {
+			return util.CleanedUpSdkError{Err: err}
+		}
+		if latest == nil {
+			return fmt.Errorf("browser %s has no replays", in.Identifier)
+		}
+		in.ReplayID = latest.ReplayID
+	}
+	res, err := withIDPrefix(ctx, "browser", in.Identifier, browserIDLister(b.browsers), func(id string) (*http.Response, error) {
+		return b.replays.Download(ctx, in.ReplayID, kernel.BrowserReplayDownloadParams{ID: id})
+	})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1497,7 +1720,9 @@ type BrowsersPlaywrightExecuteInput struct {
 	Identifier string
 	Code       string
 	Timeout    int64
-	Output     string
+	// Retries re-runs the script when it fails because the page navigated.
+	Retries int
+	Output  string
 }
 
 func (b BrowsersCmd) PlaywrightExecute(ctx context.Context, in BrowsersPlaywrightExecuteInput) error {
@@ -1509,7 +1734,7 @@ func (b BrowsersCmd) PlaywrightExecute(ctx context.Context, in BrowsersPlaywrigh
 		pterm.Error.Println("playwright service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1517,7 +1742,7 @@ func (b BrowsersCmd) PlaywrightExecute(ctx context.Context, in BrowsersPlaywrigh
 	if in.Timeout > 0 {
 		params.TimeoutSec = kernel.Opt(in.Timeout)
 	}
-	res, err := b.playwright.Execute(ctx, br.SessionID, params)
+	res, err := executePlaywright(ctx, b.playwright, br.SessionID, params, in.Retries)
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1525,6 +1750,7 @@ func (b BrowsersCmd) PlaywrightExecute(ctx context.Context, in BrowsersPlaywrigh
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(res)
 	}
+	util.WarnDecodeDrift(res)
 
 	rows := pterm.TableData{{"Property", "Value"}, {"Success", fmt.Sprintf("%t", res.Success)}}
 	PrintTableNoPad(rows, true)
@@ -1559,7 +1785,7 @@ func (b BrowsersCmd) ProcessExec(ctx context.Context, in BrowsersProcessExecInpu
 		pterm.Error.Println("process service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1587,6 +1813,7 @@ func (b BrowsersCmd) ProcessExec(ctx context.Context, in BrowsersProcessExecInpu
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(res)
 	}
+	util.WarnDecodeDrift(res)
 
 	rows := pterm.TableData{{"Property", "Value"}, {"Exit Code", fmt.Sprintf("%d", res.ExitCode)}, {"Duration (ms)", fmt.Sprintf("%d", res.DurationMs)}}
 	PrintTableNoPad(rows, true)
@@ -1626,7 +1853,7 @@ func (b BrowsersCmd) ProcessSpawn(ctx context.Context, in BrowsersProcessSpawnIn
 		pterm.Error.Println("process service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1654,6 +1881,7 @@ func (b BrowsersCmd) ProcessSpawn(ctx context.Context, in BrowsersProcessSpawnIn
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(res)
 	}
+	util.WarnDecodeDrift(res)
 
 	rows := pterm.TableData{{"Property", "Value"}, {"Process ID", res.ProcessID}, {"PID", fmt.Sprintf("%d", res.Pid)}, {"Started At", util.FormatLocal(res.StartedAt)}}
 	PrintTableNoPad(rows, true)
@@ -1665,7 +1893,7 @@ func (b BrowsersCmd) ProcessKill(ctx context.Context, in BrowsersProcessKillInpu
 		pterm.Error.Println("process service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1683,7 +1911,7 @@ func (b BrowsersCmd) ProcessStatus(ctx context.Context, in BrowsersProcessStatus
 		pterm.Error.Println("process service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1701,7 +1929,7 @@ func (b BrowsersCmd) ProcessStdin(ctx context.Context, in BrowsersProcessStdinIn
 		pterm.Error.Println("process service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1718,7 +1946,7 @@ func (b BrowsersCmd) ProcessStdoutStream(ctx context.Context, in BrowsersProcess
 		pterm.Error.Println("process service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1752,7 +1980,7 @@ func (b BrowsersCmd) ProcessResize(ctx context.Context, in BrowsersProcessResize
 		pterm.Error.Println("process service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1775,7 +2003,7 @@ func (b BrowsersCmd) FSWatchStart(ctx context.Context, in BrowsersFSWatchStartIn
 		pterm.Error.Println("fs watch service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1791,6 +2019,7 @@ func (b BrowsersCmd) FSWatchStart(ctx context.Context, in BrowsersFSWatchStartIn
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(res)
 	}
+	util.WarnDecodeDrift(res)
 
 	pterm.Success.Printf("Started watch on %s with ID: %s\n", in.Path, res.WatchID)
 	return nil
@@ -1801,7 +2030,7 @@ func (b BrowsersCmd) FSWatchStop(ctx context.Context, in BrowsersFSWatchStopInpu
 		pterm.Error.Println("fs watch service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1818,7 +2047,7 @@ func (b BrowsersCmd) FSWatchEvents(ctx context.Context, in BrowsersFSWatchEvents
 		pterm.Error.Println("fs watch service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1927,7 +2156,7 @@ func (b BrowsersCmd) FSNewDirectory(ctx context.Context, in BrowsersFSNewDirInpu
 		pterm.Error.Println("fs service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1947,7 +2176,7 @@ func (b BrowsersCmd) FSDeleteDirectory(ctx context.Context, in BrowsersFSDeleteD
 		pterm.Error.Println("fs service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1963,7 +2192,7 @@ func (b BrowsersCmd) FSDeleteFile(ctx context.Context, in BrowsersFSDeleteFileIn
 		pterm.Error.Println("fs service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -1979,7 +2208,7 @@ func (b BrowsersCmd) FSDownloadDirZip(ctx context.Context, in BrowsersFSDownload
 		pterm.Error.Println("fs service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -2016,7 +2245,7 @@ func (b BrowsersCmd) FSFileInfo(ctx context.Context, in BrowsersFSFileInfoInput)
 		pterm.Error.Println("fs service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -2028,6 +2257,7 @@ func (b BrowsersCmd) FSFileInfo(ctx context.Context, in BrowsersFSFileInfoInput)
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(res)
 	}
+	util.WarnDecodeDrift(res)
 
 	rows := pterm.TableData{{"Property", "Value"}, {"Path", res.Path}, {"Name", res.Name}, {"Mode", res.Mode}, {"IsDir", fmt.Sprintf("%t", res.IsDir)}, {"SizeBytes", fmt.Sprintf("%d", res.SizeBytes)}, {"ModTime", util.FormatLocal(res.ModTime)}}
 	PrintTableNoPad(rows, true)
@@ -2043,7 +2273,7 @@ func (b BrowsersCmd) FSListFiles(ctx context.Context, in BrowsersFSListFilesInpu
 		pterm.Error.Println("fs service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -2054,11 +2284,11 @@ func (b BrowsersCmd) FSListFiles(ctx context.Context, in BrowsersFSListFilesInpu
 
 	if in.Output == "json" {
 		if res == nil || len(*res) == 0 {
-			fmt.Println("[]")
-			return nil
+			return util.PrintJSON([]any{})
 		}
 		return util.PrintPrettyJSONSlice(*res)
 	}
+	util.WarnDecodeDrift(res)
 
 	if res == nil || len(*res) == 0 {
 		pterm.Info.Println("No files found")
@@ -2077,7 +2307,7 @@ func (b BrowsersCmd) FSMove(ctx context.Context, in BrowsersFSMoveInput) error {
 		pterm.Error.Println("fs service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -2093,7 +2323,7 @@ func (b BrowsersCmd) FSReadFile(ctx context.Context, in BrowsersFSReadFileInput)
 		pterm.Error.Println("fs service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -2125,7 +2355,7 @@ func (b BrowsersCmd) FSSetPermissions(ctx context.Context, in BrowsersFSSetPerms
 		pterm.Error.Println("fs service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -2148,7 +2378,7 @@ func (b BrowsersCmd) FSUpload(ctx context.Context, in BrowsersFSUploadInput) err
 		pterm.Error.Println("fs service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -2206,7 +2436,7 @@ func (b BrowsersCmd) FSUploadZip(ctx context.Context, in BrowsersFSUploadZipInpu
 		pterm.Error.Println("fs service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -2228,7 +2458,7 @@ func (b BrowsersCmd) FSWriteFile(ctx context.Context, in BrowsersFSWriteFileInpu
 		pterm.Error.Println("fs service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -2261,7 +2491,7 @@ func (b BrowsersCmd) ExtensionsUpload(ctx context.Context, in BrowsersExtensions
 		pterm.Error.Println("browsers service not available")
 		return nil
 	}
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -2361,21 +2591,76 @@ var browsersListCmd = &cobra.Command{
 var browsersCreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a new browser session",
-	RunE:  runBrowsersCreate,
+	Long: `Create a new browser session.
+
+Use --count (optionally with --name-prefix) to create several identical
+sessions, or --manifest to create the sessions described in a YAML file.
+Batches are created with at most --concurrency requests in flight; use
+-o json for a machine-readable list of session IDs and URLs.
+
+Manifest format (each entry accepts the same settings as the create flags;
+other create flags are ignored when --manifest is set):
+
+  browsers:
+    - name_prefix: load-test-
+      count: 10
+      headless: true
+      tags: {purpose: load}
+    - name: checkout-flow
+      profile_name: shopper
+      viewport: 1920x1080@25
+      tags: {run: '{{env "CI_RUN_ID"}}'}
+
+Manifests may use {{env "VAR"}}, {{now}}, {{uuid}} and {{file "path"}}; file
+paths are relative to the manifest.`,
+	RunE: runBrowsersCreate,
 }
 
 var browsersDeleteCmd = &cobra.Command{
 	Use:   "delete <id-or-name> [ids-or-names...]",
 	Short: "Delete a browser by ID or name",
-	Args:  cobra.MinimumNArgs(1),
-	RunE:  runBrowsersDelete,
+	Long: `Delete browsers by ID or name.
+
+With --all, delete every active browser instead, optionally only those older
+than --older-than or matching --query and --tag. The matching browsers are
+listed and confirmed first. --tag (or --label) without IDs implies --all.`,
+	Example: `  kernel browsers delete abc123
+  kernel browsers delete --all --older-than 1h --dry-run
+  kernel browsers delete --label team=qa
+  kernel browsers delete --label batch=run42 --yes --concurrency 20`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if deleteSelectsByFlags(cmd, args) {
+			if len(args) > 0 {
+				return fmt.Errorf("--all does not take browser IDs")
+			}
+			return nil
+		}
+		if cmd.Flags().Changed("tag") {
+			return fmt.Errorf("--tag does not take browser IDs")
+		}
+		for _, f := range []string{"older-than", "query", "dry-run", "concurrency"} {
+			if cmd.Flags().Changed(f) {
+				return fmt.Errorf("--%s requires --all", f)
+			}
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	RunE: runBrowsersDelete,
 }
 
 var browsersViewCmd = &cobra.Command{
-	Use:   "view <id-or-name>",
-	Short: "Get the live view URL for a browser by ID or name",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runBrowsersView,
+	Use:     "view <id-or-name>",
+	Aliases: []string{"live-view"},
+	Short:   "Get the live view URL for a browser by ID or name",
+	Long: `Print the live view URL for a browser, and with --open launch it in your
+browser.
+
+Where the hosted URL is blocked by a firewall, --local-port serves it through
+a proxy on 127.0.0.1 instead (HTTP and WebSocket traffic) until interrupted.`,
+	Example: `  kernel browsers view abc123 --open
+  kernel browsers live-view abc123 --local-port 8080 --open`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBrowsersView,
 }
 
 var browsersGetCmd = &cobra.Command{
@@ -2401,7 +2686,8 @@ Supported operations:
 
 Notes:
   - Profiles can only be loaded into sessions that don't already have a profile.
-  - --tag replaces the entire tag set (it is not merged with existing tags).`,
+  - --tag replaces the entire tag set (it is not merged with existing tags);
+    use 'kernel browsers annotate' to add or remove individual tags.`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
 			return fmt.Errorf("missing required argument: browser ID or name\n\nUsage: kernel browsers update <id-or-name> [flags]")
@@ -2414,6 +2700,20 @@ Notes:
 	RunE: runBrowsersUpdate,
 }
 
+var browsersAnnotateCmd = &cobra.Command{
+	Use:   "annotate <id-or-name>",
+	Short: "Add or remove tags on a browser session",
+	Long: `Add or remove individual tags on a browser session, keeping the rest.
+
+Tags show up in 'browsers list' and 'browsers get', so shared accounts can
+record whose session is whose (and why) before anyone reaps it.`,
+	Example: `  kernel browsers annotate ab12 --set purpose="auth run github" --set owner=alice
+  kernel browsers annotate ab12 --unset purpose
+  kernel browsers list --tag owner=alice`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBrowsersAnnotate,
+}
+
 func init() {
 	// list flags
 	addJSONOutputFlag(browsersListCmd)
@@ -2422,7 +2722,7 @@ func init() {
 	browsersListCmd.Flags().Int("limit", 0, "Maximum number of results to return (default 20, max 100)")
 	browsersListCmd.Flags().Int("offset", 0, "Number of results to skip (for pagination)")
 	browsersListCmd.Flags().String("query", "", "Search browsers by name, session ID, profile ID, proxy ID, or pool name")
-	browsersListCmd.Flags().StringArray("tag", nil, "Filter by tag KEY=VALUE (repeatable; a session must match every pair)")
+	browsersListCmd.Flags().StringArray("tag", nil, "Filter by tag KEY=VALUE (repeatable; a session must match every pair; alias --label)")
 
 	// get flags
 	addJSONOutputFlag(browsersGetCmd)
@@ -2430,6 +2730,10 @@ func init() {
 
 	// view flags
 	addJSONOutputFlag(browsersViewCmd)
+	browsersViewCmd.Flags().Bool("open", false, "Open the live view in your browser")
+	browsersViewCmd.Flags().Int("local-port", 0, "Serve the live view through a proxy on this local port")
+	browsersViewCmd.Flags().Bool("copy", false, "Copy the live view URL to the clipboard")
+	browsersViewCmd.MarkFlagsMutuallyExclusive("output", "local-port")
 
 	// update flags
 	addJSONOutputFlag(browsersUpdateCmd)
@@ -2446,18 +2750,24 @@ func init() {
 	browsersUpdateCmd.Flags().StringArray("tag", nil, "Set a tag KEY=VALUE (repeatable; up to 50 pairs). Replaces the entire tag set; mutually exclusive with --clear-tags")
 	browsersUpdateCmd.Flags().Bool("clear-tags", false, "Remove all tags from the browser session")
 
+	// annotate flags
+	addJSONOutputFlag(browsersAnnotateCmd)
+	browsersAnnotateCmd.Flags().StringArray("set", nil, "Set a tag KEY=VALUE, keeping other tags (repeatable)")
+	browsersAnnotateCmd.Flags().StringArray("unset", nil, "Remove the tag KEY (repeatable)")
+
 	browsersCmd.AddCommand(browsersListCmd)
 	browsersCmd.AddCommand(browsersCreateCmd)
 	browsersCmd.AddCommand(browsersDeleteCmd)
 	browsersCmd.AddCommand(browsersViewCmd)
 	browsersCmd.AddCommand(browsersGetCmd)
 	browsersCmd.AddCommand(browsersUpdateCmd)
+	browsersCmd.AddCommand(browsersAnnotateCmd)
 
 	// ssh
 	browsersCmd.AddCommand(sshCmd)
 
 	// logs
-	logsRoot := &cobra.Command{Use: "logs", Short: "Browser logs operations"}
+	logsRoot := browsersLogsCmd
 	logsStream := &cobra.Command{Use: "stream <id>", Short: "Stream browser logs", Args: cobra.ExactArgs(1), RunE: runBrowsersLogsStream}
 	logsStream.Flags().String("source", "", "Log source: path or supervisor")
 	logsStream.Flags().Bool("follow", true, "Follow the log stream")
@@ -2474,9 +2784,10 @@ func init() {
 	replaysStart := &cobra.Command{Use: "start <id>", Short: "Start a replay recording", Args: cobra.ExactArgs(1), RunE: runBrowsersReplaysStart}
 	replaysStart.Flags().Int("framerate", 0, "Recording framerate (fps)")
 	replaysStart.Flags().Int("max-duration", 0, "Maximum duration in seconds")
+	replaysStart.Flags().Bool("audio", false, "Record audio as well as video")
 	addJSONOutputFlag(replaysStart)
 	replaysStop := &cobra.Command{Use: "stop <id> <replay-id>", Short: "Stop a replay recording", Args: cobra.ExactArgs(2), RunE: runBrowsersReplaysStop}
-	replaysDownload := &cobra.Command{Use: "download <id> <replay-id>", Short: "Download a replay video", Args: cobra.ExactArgs(2), RunE: runBrowsersReplaysDownload}
+	replaysDownload := &cobra.Command{Use: "download <id> [replay-id]", Short: "Download a replay video (the latest if no replay ID is given)", Args: cobra.RangeArgs(1, 2), RunE: runBrowsersReplaysDownload}
 	replaysDownload.Flags().StringP("output-file", "f", "", "Output file path for the replay video")
 	replaysRoot.AddCommand(replaysList, replaysStart, replaysStop, replaysDownload)
 	browsersCmd.AddCommand(replaysRoot)
@@ -2688,8 +2999,10 @@ func init() {
 	playwrightRoot := &cobra.Command{Use: "playwright", Short: "Playwright operations"}
 	playwrightExecute := &cobra.Command{Use: "execute <id> [code]", Short: "Execute Playwright/TypeScript code against the browser", Args: cobra.MinimumNArgs(1), RunE: runBrowsersPlaywrightExecute}
 	playwrightExecute.Flags().Int64("timeout", 0, "Maximum execution time in seconds (default per server)")
+	playwrightExecute.Flags().Int("retries", 0, playwrightRetriesFlagUsage)
 	addJSONOutputFlag(playwrightExecute)
 	playwrightRoot.AddCommand(playwrightExecute)
+	playwrightRoot.AddCommand(browsersPlaywrightRunCmd)
 	browsersCmd.AddCommand(playwrightRoot)
 
 	// Add flags for create command
@@ -2699,6 +3012,7 @@ func init() {
 	browsersCreateCmd.Flags().Bool("gpu", false, "Launch browser with hardware-accelerated GPU rendering")
 	browsersCreateCmd.Flags().String("invocation-id", "", "Associate the browser session with an invocation")
 	browsersCreateCmd.Flags().Bool("kiosk", false, "Launch browser in kiosk mode")
+	browsersCreateCmd.Flags().Bool("copy", false, "Copy the live view URL to the clipboard")
 	browsersCreateCmd.Flags().IntP("timeout", "t", 60, "Timeout in seconds for the browser session")
 	browsersCreateCmd.Flags().String("profile-id", "", "Profile ID to load into the browser session (mutually exclusive with --profile-name)")
 	browsersCreateCmd.Flags().String("profile-name", "", "Profile name to load into the browser session (mutually exclusive with --profile-id)")
@@ -2712,10 +3026,21 @@ func init() {
 	browsersCreateCmd.Flags().String("pool-name", "", "Browser pool name to acquire from (mutually exclusive with --pool-id)")
 	browsersCreateCmd.Flags().String("telemetry", "", "Configure telemetry (opt-in): --telemetry=all (default set), --telemetry=off (disable), or --telemetry=console,network (capture exactly those categories)")
 	browsersCreateCmd.Flags().String("name", "", "Optional unique name for the browser session (used to find it later; can be changed with 'browsers update --name')")
-	browsersCreateCmd.Flags().StringArray("tag", nil, "Set a tag KEY=VALUE on the session (repeatable; up to 50 pairs)")
+	browsersCreateCmd.Flags().StringArray("tag", nil, "Set a tag KEY=VALUE on the session (repeatable; up to 50 pairs; alias --label)")
+	for _, c := range []*cobra.Command{browsersCreateCmd, browsersListCmd, browsersDeleteCmd} {
+		c.Flags().SetNormalizeFunc(labelFlagAsTag)
+	}
 	browsersCreateCmd.Flags().String("chrome-policy", "", "Custom Chrome enterprise policy as a JSON object")
 	browsersCreateCmd.Flags().String("chrome-policy-file", "", "Read Chrome enterprise policy (JSON object) from a file (use '-' for stdin)")
 	browsersCreateCmd.MarkFlagsMutuallyExclusive("chrome-policy", "chrome-policy-file")
+	browsersCreateCmd.Flags().Int("count", 1, "Number of browser sessions to create with the given flags")
+	browsersCreateCmd.Flags().String("name-prefix", "", "Name batch-created sessions <prefix>1..<prefix>N (use with --count)")
+	browsersCreateCmd.Flags().String("manifest", "", "Create the browser sessions described in a YAML manifest")
+	addNoTemplateFlag(browsersCreateCmd, "the --manifest file")
+	browsersCreateCmd.Flags().Int("concurrency", defaultBatchConcurrency, "Maximum number of sessions to create in parallel (with --count or --manifest)")
+	browsersCreateCmd.MarkFlagsMutuallyExclusive("name", "name-prefix")
+	browsersCreateCmd.MarkFlagsMutuallyExclusive("manifest", "count")
+	browsersCreateCmd.MarkFlagsMutuallyExclusive("manifest", "name-prefix")
 
 	// curl
 	curlCmd := &cobra.Command{
@@ -2764,7 +3089,30 @@ followed automatically by Chromium.`,
 	telemetryRoot.AddCommand(telemetryEvents)
 	browsersCmd.AddCommand(telemetryRoot)
 
+	bugreportCmd := &cobra.Command{
+		Use:   "bugreport <id-or-name>",
+		Short: "Bundle diagnostics for a browser session into a zip",
+		Long: `Gather a screenshot, the current page URL, recent console and network activity,
+loaded extensions and VM system info into one zip for attaching to a bug report.
+Secrets in URLs, headers and logs are redacted; the screenshot is not.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runBrowsersBugreport,
+	}
+	bugreportCmd.Flags().StringP("output", "o", "", "Output zip file path (default bugreport-<session-id>-<time>.zip)")
+	bugreportCmd.Flags().String("since", "15m", "Include console and network events since: RFC-3339 timestamp or a duration like 15m")
+	browsersCmd.AddCommand(bugreportCmd)
+
 	// no flags for view; it takes a single positional argument
+
+	browsersDeleteCmd.ValidArgsFunction = completeResourceArgs("browser", completeBrowser)
+	browsersDeleteCmd.Flags().Bool("all", false, "Delete all active browsers (combine with --older-than, --query or --tag)")
+	browsersDeleteCmd.Flags().Duration("older-than", 0, "With --all, only delete browsers created at least this long ago (e.g. 1h)")
+	browsersDeleteCmd.Flags().String("query", "", "With --all, only delete browsers matching this search")
+	browsersDeleteCmd.Flags().StringArray("tag", nil, "Only delete browsers with tag KEY=VALUE (repeatable; implies --all when no IDs are given; alias --label)")
+	browsersDeleteCmd.Flags().Bool("dry-run", false, "With --all, list the browsers that would be deleted without deleting them")
+	browsersDeleteCmd.Flags().BoolP("yes", "y", false, "With --all, skip the confirmation prompt")
+	browsersDeleteCmd.Flags().Int("concurrency", defaultBatchConcurrency, "With --all, maximum number of browsers to delete in parallel")
+	setBrowserIDCompletion(browsersCmd)
 }
 
 func runBrowsersList(cmd *cobra.Command, args []string) error {
@@ -2826,6 +3174,7 @@ func runBrowsersCreate(cmd *cobra.Command, args []string) error {
 	startURL, _ := cmd.Flags().GetString("start-url")
 	extensions, _ := cmd.Flags().GetStringSlice("extension")
 	viewport, _ := cmd.Flags().GetString("viewport")
+	copyURL, _ := cmd.Flags().GetBool("copy")
 	viewportInteractive, _ := cmd.Flags().GetBool("viewport-interactive")
 	poolID, _ := cmd.Flags().GetString("pool-id")
 	poolName, _ := cmd.Flags().GetString("pool-name")
@@ -2835,12 +3184,32 @@ func runBrowsersCreate(cmd *cobra.Command, args []string) error {
 	chromePolicy, _ := cmd.Flags().GetString("chrome-policy")
 	chromePolicyFile, _ := cmd.Flags().GetString("chrome-policy-file")
 	output, _ := cmd.Flags().GetString("output")
+	count, _ := cmd.Flags().GetInt("count")
+	namePrefix, _ := cmd.Flags().GetString("name-prefix")
+	manifest, _ := cmd.Flags().GetString("manifest")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
 
 	if poolID != "" && poolName != "" {
 		pterm.Error.Println("must specify at most one of --pool-id or --pool-name")
 		return nil
 	}
 
+	batch := manifest != "" || count != 1 || namePrefix != ""
+	if batch {
+		if count < 1 {
+			return fmt.Errorf("--count must be at least 1")
+		}
+		if poolID != "" || poolName != "" {
+			return fmt.Errorf("--count, --name-prefix, and --manifest cannot be used with --pool-id or --pool-name")
+		}
+		if viewportInteractive {
+			return fmt.Errorf("--viewport-interactive cannot be used when creating multiple sessions")
+		}
+		if name != "" && count > 1 {
+			return fmt.Errorf("--name must be unique per session; use --name-prefix with --count")
+		}
+	}
+
 	if poolID != "" || poolName != "" {
 		// When using a pool, configuration comes from the pool itself, but
 		// name, tags, and telemetry apply per-lease to the acquired session.
@@ -2896,16 +3265,22 @@ func runBrowsersCreate(cmd *cobra.Command, args []string) error {
 		}
 		if resp == nil {
 			if output == "json" {
-				fmt.Println("null")
-				return nil
+				return util.PrintJSON(nil)
 			}
 			pterm.Error.Println("Acquire request timed out (no browser available). Retry to continue waiting.")
 			return nil
 		}
 		if output == "json" {
-			return util.PrintPrettyJSON(resp)
+			if err := util.PrintPrettyJSON(resp); err != nil {
+				return err
+			}
+		} else {
+			util.WarnDecodeDrift(resp)
+			printBrowserSessionResult(resp.SessionID, resp.CdpWsURL, resp.BrowserLiveViewURL, resp.Profile, resp.StartURL, resp.Name, resp.Tags)
+		}
+		if copyURL {
+			copyOutput("live view URL", resp.BrowserLiveViewURL)
 		}
-		printBrowserSessionResult(resp.SessionID, resp.CdpWsURL, resp.BrowserLiveViewURL, resp.Profile, resp.StartURL, resp.Name, resp.Tags)
 		return nil
 	}
 
@@ -2946,18 +3321,65 @@ func runBrowsersCreate(cmd *cobra.Command, args []string) error {
 		Name:               name,
 		Tags:               tags,
 		Output:             output,
+		Copy:               copyURL,
 	}
 
 	svc := client.Browsers
-	b := BrowsersCmd{browsers: &svc}
+	profiles := client.Profiles
+	b := BrowsersCmd{browsers: &svc, profileLocks: newProfileLocker(&profiles)}
+	if batch {
+		var sessions []BrowsersCreateInput
+		if manifest != "" {
+			var err error
+			noTemplate, _ := cmd.Flags().GetBool("no-template")
+			if sessions, err = loadBrowserManifest(manifest, !noTemplate); err != nil {
+				return err
+			}
+		} else {
+			// Read the policy file once rather than per session (it may be stdin).
+			if in.ChromePolicyFile != "" {
+				policy, err := parseChromePolicy("", in.ChromePolicyFile)
+				if err != nil {
+					return err
+				}
+				raw, _ := json.Marshal(policy)
+				in.ChromePolicy, in.ChromePolicyFile = string(raw), ""
+			}
+			sessions = expandBrowserCreateInput(in, count, namePrefix)
+		}
+		return b.CreateBatch(cmd.Context(), BrowsersBatchCreateInput{Sessions: sessions, Concurrency: concurrency, Output: output})
+	}
 	return b.Create(cmd.Context(), in)
 }
 
+// deleteSelectsByFlags reports whether `browsers delete` picks its browsers
+// with --all's filters rather than by ID. A tag filter on its own is enough.
+func deleteSelectsByFlags(cmd *cobra.Command, args []string) bool {
+	all, _ := cmd.Flags().GetBool("all")
+	return all || (len(args) == 0 && cmd.Flags().Changed("tag"))
+}
+
 func runBrowsersDelete(cmd *cobra.Command, args []string) error {
 	client := getKernelClient(cmd)
 
 	svc := client.Browsers
-	b := BrowsersCmd{browsers: &svc}
+	b := BrowsersCmd{browsers: &svc, profileLocks: newProfileLocker(nil)}
+	if deleteSelectsByFlags(cmd, args) {
+		olderThan, _ := cmd.Flags().GetDuration("older-than")
+		query, _ := cmd.Flags().GetString("query")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+		tags, _ := tagsFromFlag(cmd, "tag")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		return b.DeleteAll(cmd.Context(), BrowsersDeleteAllInput{
+			OlderThan:   olderThan,
+			Query:       query,
+			Tags:        tags,
+			DryRun:      dryRun,
+			SkipConfirm: yes,
+			Concurrency: concurrency,
+		})
+	}
 	// Iterate all provided identifiers
 	for _, identifier := range args {
 		if err := b.Delete(cmd.Context(), BrowsersDeleteInput{Identifier: identifier}); err != nil {
@@ -2970,12 +3392,18 @@ func runBrowsersDelete(cmd *cobra.Command, args []string) error {
 func runBrowsersView(cmd *cobra.Command, args []string) error {
 	client := getKernelClient(cmd)
 	output, _ := cmd.Flags().GetString("output")
+	open, _ := cmd.Flags().GetBool("open")
+	localPort, _ := cmd.Flags().GetInt("local-port")
+	copyURL, _ := cmd.Flags().GetBool("copy")
+	if localPort < 0 || localPort > 65535 {
+		return fmt.Errorf("invalid --local-port %d", localPort)
+	}
 
 	identifier := args[0]
 
-	in := BrowsersViewInput{Identifier: identifier, Output: output}
+	in := BrowsersViewInput{Identifier: identifier, Output: output, Open: open, LocalPort: localPort, Copy: copyURL}
 	svc := client.Browsers
-	b := BrowsersCmd{browsers: &svc}
+	b := BrowsersCmd{browsers: &svc, openURL: browser.OpenURL}
 	return b.View(cmd.Context(), in)
 }
 
@@ -3031,6 +3459,23 @@ func runBrowsersUpdate(cmd *cobra.Command, args []string) error {
 	})
 }
 
+func runBrowsersAnnotate(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	out, _ := cmd.Flags().GetString("output")
+	set, setProvided := tagsFromFlag(cmd, "set")
+	unset, _ := cmd.Flags().GetStringArray("unset")
+
+	svc := client.Browsers
+	b := BrowsersCmd{browsers: &svc}
+	return b.Annotate(cmd.Context(), BrowsersAnnotateInput{
+		Identifier:  args[0],
+		Set:         set,
+		SetProvided: setProvided,
+		Unset:       unset,
+		Output:      out,
+	})
+}
+
 func runBrowsersLogsStream(cmd *cobra.Command, args []string) error {
 	client := getKernelClient(cmd)
 	svc := client.Browsers
@@ -3061,9 +3506,10 @@ func runBrowsersReplaysStart(cmd *cobra.Command, args []string) error {
 	svc := client.Browsers
 	fr, _ := cmd.Flags().GetInt("framerate")
 	md, _ := cmd.Flags().GetInt("max-duration")
+	audio, _ := cmd.Flags().GetBool("audio")
 	output, _ := cmd.Flags().GetString("output")
 	b := BrowsersCmd{browsers: &svc, replays: &svc.Replays}
-	return b.ReplaysStart(cmd.Context(), BrowsersReplaysStartInput{Identifier: args[0], Framerate: fr, MaxDurationSeconds: md, Output: output})
+	return b.ReplaysStart(cmd.Context(), BrowsersReplaysStartInput{Identifier: args[0], Framerate: fr, MaxDurationSeconds: md, RecordAudio: audio, Output: output})
 }
 
 func runBrowsersReplaysStop(cmd *cobra.Command, args []string) error {
@@ -3077,8 +3523,12 @@ func runBrowsersReplaysDownload(cmd *cobra.Command, args []string) error {
 	client := getKernelClient(cmd)
 	svc := client.Browsers
 	out, _ := cmd.Flags().GetString("output-file")
+	var replayID string
+	if len(args) > 1 {
+		replayID = args[1]
+	}
 	b := BrowsersCmd{browsers: &svc, replays: &svc.Replays}
-	return b.ReplaysDownload(cmd.Context(), BrowsersReplaysDownloadInput{Identifier: args[0], ReplayID: args[1], Output: out})
+	return b.ReplaysDownload(cmd.Context(), BrowsersReplaysDownloadInput{Identifier: args[0], ReplayID: replayID, Output: out})
 }
 
 func runBrowsersProcessExec(cmd *cobra.Command, args []string) error {
@@ -3210,9 +3660,10 @@ func runBrowsersPlaywrightExecute(cmd *cobra.Command, args []string) error {
 		code = string(data)
 	}
 	timeout, _ := cmd.Flags().GetInt64("timeout")
+	retries, _ := cmd.Flags().GetInt("retries")
 	output, _ := cmd.Flags().GetString("output")
 	b := BrowsersCmd{browsers: &svc, playwright: &svc.Playwright}
-	return b.PlaywrightExecute(cmd.Context(), BrowsersPlaywrightExecuteInput{Identifier: args[0], Code: strings.TrimSpace(code), Timeout: timeout, Output: output})
+	return b.PlaywrightExecute(cmd.Context(), BrowsersPlaywrightExecuteInput{Identifier: args[0], Code: strings.TrimSpace(code), Timeout: timeout, Retries: retries, Output: output})
 }
 
 func runBrowsersFSNewDirectory(cmd *cobra.Command, args []string) error {
@@ -3680,11 +4131,12 @@ func (b BrowsersCmd) Curl(ctx context.Context, in BrowsersCurlInput) error {
 	}
 
 	// Seed the SDK's browser route cache before constructing the raw curl client.
-	if _, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{}); err != nil {
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
+	if err != nil {
 		return curlError(in, util.CleanedUpSdkError{Err: err})
 	}
 
-	httpClient, err := b.browsers.HTTPClient(in.Identifier)
+	httpClient, err := b.browsers.HTTPClient(br.SessionID)
 	if err != nil {
 		return curlError(in, util.CleanedUpSdkError{Err: err})
 	}
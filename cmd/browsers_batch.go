@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"golang.org/x/sync/errgroup"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultBatchConcurrency = 5
+
+// BrowsersBatchCreateInput creates several browser sessions at once.
+type BrowsersBatchCreateInput struct {
+	Sessions    []BrowsersCreateInput
+	Concurrency int
+	Output      string
+}
+
+// BrowserBatchResult is the outcome of creating one session in a batch.
+type BrowserBatchResult struct {
+	Name               string `json:"name,omitempty"`
+	SessionID          string `json:"session_id,omitempty"`
+	CdpWsURL           string `json:"cdp_ws_url,omitempty"`
+	BrowserLiveViewURL string `json:"browser_live_view_url,omitempty"`
+	Error              string `json:"error,omitempty"`
+}
+
+// browserManifest is the on-disk format accepted by `browsers create --manifest`.
+type browserManifest struct {
+	Browsers []browserManifestEntry `yaml:"browsers"`
+}
+
+type browserManifestEntry struct {
+	Name         string            `yaml:"name"`
+	NamePrefix   string            `yaml:"name_prefix"`
+	Count        int               `yaml:"count"`
+	Stealth      *bool             `yaml:"stealth"`
+	Headless     *bool             `yaml:"headless"`
+	GPU          *bool             `yaml:"gpu"`
+	Kiosk        *bool             `yaml:"kiosk"`
+	Timeout      int               `yaml:"timeout"`
+	InvocationID string            `yaml:"invocation_id"`
+	ProfileID    string            `yaml:"profile_id"`
+	ProfileName  string            `yaml:"profile_name"`
+	SaveChanges  *bool             `yaml:"save_changes"`
+	ProxyID      string            `yaml:"proxy_id"`
+	StartURL     string            `yaml:"start_url"`
+	Extensions   []string          `yaml:"extensions"`
+	Viewport     string            `yaml:"viewport"`
+	Telemetry    string            `yaml:"telemetry"`
+	ChromePolicy map[string]any    `yaml:"chrome_policy"`
+	Tags         map[string]string `yaml:"tags"`
+}
+
+func optionalBoolFlag(v *bool) BoolFlag {
+	if v == nil {
+		return BoolFlag{}
+	}
+	return BoolFlag{Set: true, Value: *v}
+}
+
+// loadBrowserManifest reads a YAML (or JSON) manifest and expands it into one
+// create input per session.
+func loadBrowserManifest(path string, expand bool) ([]BrowsersCreateInput, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	expanded := string(data)
+	if expand {
+		if expanded, err = util.ExpandTemplate(expanded, filepath.Dir(path)); err != nil {
+			return nil, fmt.Errorf("manifest %s: %w", path, err)
+		}
+	}
+	var m browserManifest
+	if err := yaml.Unmarshal([]byte(expanded), &m); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	if len(m.Browsers) == 0 {
+		return nil, fmt.Errorf("manifest %s defines no browsers", path)
+	}
+
+	var sessions []BrowsersCreateInput
+	for i, e := range m.Browsers {
+		if e.Count < 0 {
+			return nil, fmt.Errorf("manifest entry %d: count must be positive", i+1)
+		}
+		if e.Name != "" && e.NamePrefix != "" {
+			return nil, fmt.Errorf("manifest entry %d: specify at most one of name or name_prefix", i+1)
+		}
+		if e.Name != "" && e.Count > 1 {
+			return nil, fmt.Errorf("manifest entry %d: name must be unique; use name_prefix with count", i+1)
+		}
+		var chromePolicy string
+		if len(e.ChromePolicy) > 0 {
+			b, err := json.Marshal(e.ChromePolicy)
+			if err != nil {
+				return nil, fmt.Errorf("manifest entry %d: chrome_policy: %w", i+1, err)
+			}
+			chromePolicy = string(b)
+		}
+		in := BrowsersCreateInput{
+			TimeoutSeconds:     e.Timeout,
+			Stealth:            optionalBoolFlag(e.Stealth),
+			Headless:           optionalBoolFlag(e.Headless),
+			GPU:                optionalBoolFlag(e.GPU),
+			InvocationID:       e.InvocationID,
+			Kiosk:              optionalBoolFlag(e.Kiosk),
+			ProfileID:          e.ProfileID,
+			ProfileName:        e.ProfileName,
+			ProfileSaveChanges: optionalBoolFlag(e.SaveChanges),
+			ProxyID:            e.ProxyID,
+			StartURL:           e.StartURL,
+			Extensions:         e.Extensions,
+			Viewport:           e.Viewport,
+			Telemetry:          e.Telemetry,
+			ChromePolicy:       chromePolicy,
+			Name:               e.Name,
+			Tags:               e.Tags,
+		}
+		sessions = append(sessions, expandBrowserCreateInput(in, max(e.Count, 1), e.NamePrefix)...)
+	}
+	return sessions, nil
+}
+
+// expandBrowserCreateInput replicates in count times, naming each copy
+// prefix1..prefixN when a prefix is given.
+func expandBrowserCreateInput(in BrowsersCreateInput, count int, namePrefix string) []BrowsersCreateInput {
+	out := make([]BrowsersCreateInput, 0, count)
+	for i := 1; i <= count; i++ {
+		s := in
+		if namePrefix != "" {
+			s.Name = namePrefix + strconv.Itoa(i)
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// CreateBatch creates every requested session with at most Concurrency
+// requests in flight. Failures are reported per session rather than aborting
+// the batch; the command errors at the end if any session failed.
+func (b BrowsersCmd) CreateBatch(ctx context.Context, in BrowsersBatchCreateInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if len(in.Sessions) == 0 {
+		return fmt.Errorf("no browser sessions to create")
+	}
+	concurrency := in.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	// Validate everything up front so a typo doesn't leave a half-created batch.
+	params := make([]kernel.BrowserNewParams, len(in.Sessions))
+	for i, s := range in.Sessions {
+		p, err := buildBrowserNewParams(s)
+		if err != nil {
+			return fmt.Errorf("session %d: %w", i+1, err)
+		}
+		params[i] = p
+	}
+
+	var spinner *pterm.SpinnerPrinter
+	if in.Output != "json" {
+		spinner, _ = pterm.DefaultSpinner.Start(fmt.Sprintf("Creating %d browser sessions (concurrency %d)...", len(params), concurrency))
+	}
+
+	results := make([]BrowserBatchResult, len(params))
+	var (
+		mu     sync.Mutex
+		done   int
+		failed int
+	)
+	var g errgroup.Group
+	g.SetLimit(concurrency)
+	for i, s := range in.Sessions {
+		g.Go(func() error {
+			res := BrowserBatchResult{Name: s.Name}
+			browser, err := b.newSession(ctx, s, params[i])
+			if err != nil {
+				res.Error = err.Error()
+			} else {
+				res.Name = browser.Name
+				res.SessionID = browser.SessionID
+				res.CdpWsURL = browser.CdpWsURL
+				res.BrowserLiveViewURL = browser.BrowserLiveViewURL
+			}
+			results[i] = res
+
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			if err != nil {
+				failed++
+			}
+			if spinner != nil {
+				spinner.UpdateText(fmt.Sprintf("Created %d/%d browser sessions (%d failed)...", done-failed, len(params), failed))
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	if in.Output == "json" {
+		if err := util.PrintJSON(results); err != nil {
+			return err
+		}
+	} else {
+		if failed == 0 {
+			spinner.Success(fmt.Sprintf("Created %d browser sessions", len(results)))
+		} else {
+			spinner.Warning(fmt.Sprintf("Created %d of %d browser sessions", len(results)-failed, len(results)))
+		}
+		rows := pterm.TableData{{"Name", "Session ID", "CDP WebSocket URL", "Live View URL", "Error"}}
+		for _, r := range results {
+			rows = append(rows, []string{r.Name, r.SessionID, r.CdpWsURL, r.BrowserLiveViewURL, r.Error})
+		}
+		PrintTableNoPad(rows, true)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d browser sessions failed to create", failed, len(results))
+	}
+	return nil
+}
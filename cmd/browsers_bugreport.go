@@ -0,0 +1,338 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// bugreportMaxEvents caps the console and network events copied into a
+	// bundle so a chatty session doesn't produce an unattachable zip.
+	bugreportMaxEvents      = 5000
+	bugreportExecTimeoutSec = 20
+)
+
+// bugreportPageScript reports the active page and every open tab.
+const bugreportPageScript = `return {
+  url: page.url(),
+  title: await page.title().catch(() => ""),
+  pages: context.pages().map(p => p.url()),
+};`
+
+// bugreportExtensionsScript lists unpacked extensions uploaded to the VM with
+// their manifest name and version.
+const bugreportExtensionsScript = `for m in /home/kernel/extensions/*/manifest.json; do
+  [ -f "$m" ] || continue
+  printf '%s\t' "$(basename "$(dirname "$m")")"
+  grep -oE '"(name|version)"[[:space:]]*:[[:space:]]*"[^"]*"' "$m" | head -n 2 | tr '\n' ' '
+  echo
+done`
+
+const bugreportSystemScript = `echo "## uname"; uname -a
+echo; echo "## uptime"; uptime
+echo; echo "## memory"; free -m
+echo; echo "## disk"; df -h
+echo; echo "## processes"; ps aux --sort=-%cpu | head -n 40`
+
+type BrowsersBugreportInput struct {
+	Identifier string
+	Output     string
+	Since      string
+}
+
+// bugreportManifest is written to manifest.json in the bundle. Errors lists
+// the artifacts that could not be collected and why; collection is
+// best-effort so one unavailable source doesn't lose the rest.
+type bugreportManifest struct {
+	SessionID   string            `json:"session_id"`
+	CreatedAt   time.Time         `json:"created_at"`
+	CLIVersion  string            `json:"cli_version,omitempty"`
+	EventsSince string            `json:"events_since"`
+	Files       []string          `json:"files"`
+	Errors      map[string]string `json:"errors,omitempty"`
+	Redacted    bool              `json:"redacted"`
+}
+
+type bugreportFile struct {
+	name string
+	data []byte
+	// raw files (the screenshot) skip the redaction pass.
+	raw bool
+}
+
+// Bugreport gathers a session's screenshot, current URL, console and network
+// activity, extensions and VM system info into one zip for attaching to a
+// bug report. Text artifacts are redacted before they are written.
+func (b BrowsersCmd) Bugreport(ctx context.Context, in BrowsersBugreportInput) error {
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	if in.Since == "" {
+		in.Since = "15m"
+	}
+	if in.Output == "" {
+		in.Output = fmt.Sprintf("bugreport-%s-%s.zip", br.SessionID, time.Now().UTC().Format("20060102T150405Z"))
+	}
+
+	manifest := bugreportManifest{
+		SessionID:   br.SessionID,
+		CreatedAt:   time.Now().UTC(),
+		CLIVersion:  metadata.Version,
+		EventsSince: in.Since,
+		Errors:      map[string]string{},
+		Redacted:    true,
+	}
+	var files []bugreportFile
+	add := func(name string, data []byte, raw bool) {
+		files = append(files, bugreportFile{name: name, data: data, raw: raw})
+	}
+	fail := func(name string, err error) {
+		manifest.Errors[name] = err.Error()
+	}
+
+	if data, err := marshalBugreportJSON(br); err == nil {
+		add("browser.json", data, false)
+	} else {
+		fail("browser.json", err)
+	}
+
+	pterm.Info.Println("Capturing screenshot...")
+	if data, err := b.bugreportScreenshot(ctx, br.SessionID); err == nil {
+		add("screenshot.png", data, true)
+	} else {
+		fail("screenshot.png", err)
+	}
+
+	pterm.Info.Println("Reading current page...")
+	if data, err := b.bugreportPage(ctx, br.SessionID); err == nil {
+		add("page.json", data, false)
+	} else {
+		fail("page.json", err)
+	}
+
+	pterm.Info.Println("Reading console and network events...")
+	if byCategory, err := b.bugreportEvents(ctx, br.SessionID, in.Since); err == nil {
+		add("console.ndjson", byCategory["console"], false)
+		add("network.ndjson", byCategory["network"], false)
+	} else {
+		fail("console.ndjson", err)
+		fail("network.ndjson", err)
+	}
+
+	pterm.Info.Println("Collecting extensions and system info...")
+	if data, err := b.bugreportExec(ctx, br.SessionID, bugreportExtensionsScript); err == nil {
+		add("extensions.txt", data, false)
+	} else {
+		fail("extensions.txt", err)
+	}
+	if data, err := b.bugreportExec(ctx, br.SessionID, bugreportSystemScript); err == nil {
+		add("system.txt", data, false)
+	} else {
+		fail("system.txt", err)
+	}
+
+	for _, f := range files {
+		manifest.Files = append(manifest.Files, f.name)
+	}
+	errNames := make([]string, 0, len(manifest.Errors))
+	for name := range manifest.Errors {
+		errNames = append(errNames, name)
+	}
+	sort.Strings(errNames)
+	manifestData, err := marshalBugreportJSON(manifest)
+	if err != nil {
+		return err
+	}
+	files = append([]bugreportFile{{name: "manifest.json", data: manifestData}}, files...)
+
+	if err := writeBugreportZip(in.Output, files); err != nil {
+		return err
+	}
+	for _, name := range errNames {
+		pterm.Warning.Printf("Skipped %s: %s\n", name, manifest.Errors[name])
+	}
+	pterm.Success.Printf("Saved bug report to %s (%d files)\n", in.Output, len(files))
+	pterm.Info.Println("Secrets in URLs, headers and logs were redacted; review the screenshot before sharing.")
+	return nil
+}
+
+func (b BrowsersCmd) bugreportScreenshot(ctx context.Context, sessionID string) ([]byte, error) {
+	if b.computer == nil {
+		return nil, fmt.Errorf("computer service not available")
+	}
+	res, err := b.computer.CaptureScreenshot(ctx, sessionID, kernel.BrowserComputerCaptureScreenshotParams{})
+	if err != nil {
+		return nil, util.CleanedUpSdkError{Err: err}
+	}
+	defer res.Body.Close()
+	return io.ReadAll(res.Body)
+}
+
+func (b BrowsersCmd) bugreportPage(ctx context.Context, sessionID string) ([]byte, error) {
+	if b.playwright == nil {
+		return nil, fmt.Errorf("playwright service not available")
+	}
+	res, err := executePlaywright(ctx, b.playwright, sessionID, kernel.BrowserPlaywrightExecuteParams{Code: bugreportPageScript}, readOnlyPlaywrightRetries)
+	if err != nil {
+		return nil, util.CleanedUpSdkError{Err: err}
+	}
+	if !res.Success {
+		return nil, fmt.Errorf("%s", util.FirstOrDash(res.Error, res.Stderr))
+	}
+	return marshalBugreportJSON(res.Result)
+}
+
+// bugreportEvents returns the session's console and network telemetry since
+// the given window start as newline-delimited JSON, keyed by category.
+func (b BrowsersCmd) bugreportEvents(ctx context.Context, sessionID, since string) (map[string][]byte, error) {
+	if b.telemetry == nil {
+		return nil, fmt.Errorf("telemetry service not available")
+	}
+	params := kernel.BrowserTelemetryEventsParams{Since: kernel.Opt(since), Limit: kernel.Opt(int64(100))}
+	// Categories go as repeated query params; see TelemetryEvents.
+	opts := []option.RequestOption{option.WithQueryAdd("category", "console"), option.WithQueryAdd("category", "network")}
+
+	out := map[string][]byte{"console": nil, "network": nil}
+	pager := b.telemetry.EventsAutoPaging(ctx, sessionID, params, opts...)
+	for n := 0; n < bugreportMaxEvents && pager.Next(); n++ {
+		it := pager.Current()
+		raw := it.RawJSON()
+		if raw == "" {
+			data, err := json.Marshal(it)
+			if err != nil {
+				continue
+			}
+			raw = string(data)
+		}
+		if _, ok := out[it.Event.Category]; ok {
+			out[it.Event.Category] = append(append(out[it.Event.Category], raw...), '\n')
+		}
+	}
+	if err := pager.Err(); err != nil {
+		return nil, util.CleanedUpSdkError{Err: err}
+	}
+	return out, nil
+}
+
+func (b BrowsersCmd) bugreportExec(ctx context.Context, sessionID, script string) ([]byte, error) {
+	if b.process == nil {
+		return nil, fmt.Errorf("process service not available")
+	}
+	res, err := b.process.Exec(ctx, sessionID, kernel.BrowserProcessExecParams{
+		Command:    "bash",
+		Args:       []string{"-c", script},
+		TimeoutSec: kernel.Opt(int64(bugreportExecTimeoutSec)),
+	})
+	if err != nil {
+		return nil, util.CleanedUpSdkError{Err: err}
+	}
+	stdout, err := base64.StdEncoding.DecodeString(res.StdoutB64)
+	if err != nil {
+		return nil, fmt.Errorf("decode stdout: %w", err)
+	}
+	if res.ExitCode != 0 {
+		stderr, _ := base64.StdEncoding.DecodeString(res.StderrB64)
+		return nil, fmt.Errorf("exit code %d: %s", res.ExitCode, util.FirstOrDash(strings.TrimSpace(string(stderr))))
+	}
+	return stdout, nil
+}
+
+// marshalBugreportJSON indents v without HTML-escaping, so URLs keep their
+// literal "&" for both readers and the redaction pass.
+func marshalBugreportJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeBugreportZip(path string, files []bugreportFile) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+	zw := zip.NewWriter(f)
+	modified := time.Now()
+	for _, file := range files {
+		data := file.data
+		if !file.raw {
+			data = []byte(redactDiagnostics(string(data)))
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			f.Close()
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+var (
+	// redactQueryParam matches the value of URL query parameters whose name
+	// suggests a credential.
+	redactQueryParam = regexp.MustCompile(`(?i)((?:[?&]|\\u0026)(?:[a-z0-9_\-]*(?:token|key|secret|password|passwd|auth|session|sig|signature|code|jwt)[a-z0-9_\-]*)=)[^&#"'\s\\]+`)
+	// redactHeader matches credential-bearing header values, both as
+	// "Name: value" text and as JSON "name":"value" pairs.
+	redactHeader     = regexp.MustCompile(`(?i)("?(?:authorization|proxy-authorization|cookie|set-cookie|x-api-key|x-auth-token)"?\s*[:=]\s*"?)([^"\r\n]+)`)
+	redactBearer     = regexp.MustCompile(`(?i)(bearer\s+)[a-z0-9._~+/=\-]+`)
+	redactJWT        = regexp.MustCompile(`eyJ[a-zA-Z0-9_\-]+\.eyJ[a-zA-Z0-9_\-]+\.[a-zA-Z0-9_\-]+`)
+	redactSecretKeys = regexp.MustCompile(`\b(?:sk|pk|rk)_(?:live|test)?_?[A-Za-z0-9]{16,}\b`)
+)
+
+const redacted = "[REDACTED]"
+
+// redactDiagnostics strips common credential shapes from diagnostic text:
+// sensitive query parameters, auth and cookie headers, bearer tokens, JWTs
+// and API-style secret keys.
+func redactDiagnostics(s string) string {
+	s = redactHeader.ReplaceAllString(s, "${1}"+redacted)
+	s = redactQueryParam.ReplaceAllString(s, "${1}"+redacted)
+	s = redactBearer.ReplaceAllString(s, "${1}"+redacted)
+	s = redactJWT.ReplaceAllString(s, redacted)
+	s = redactSecretKeys.ReplaceAllString(s, redacted)
+	return s
+}
+
+func runBrowsersBugreport(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	svc := client.Browsers
+	output, _ := cmd.Flags().GetString("output")
+	since, _ := cmd.Flags().GetString("since")
+	b := BrowsersCmd{
+		browsers:   &svc,
+		computer:   &svc.Computer,
+		playwright: &svc.Playwright,
+		telemetry:  &svc.Telemetry,
+		process:    &svc.Process,
+	}
+	return b.Bugreport(cmd.Context(), BrowsersBugreportInput{Identifier: args[0], Output: output, Since: since})
+}
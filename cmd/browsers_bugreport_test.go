@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	kernel "github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/kernel/kernel-go-sdk/packages/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readZip(t *testing.T, path string) map[string]string {
+	t.Helper()
+	zr, err := zip.OpenReader(path)
+	require.NoError(t, err)
+	defer zr.Close()
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		files[f.Name] = string(data)
+	}
+	return files
+}
+
+func TestBrowsersBugreport_BundlesRedactedDiagnostics(t *testing.T) {
+	setupStdoutCapture(t)
+
+	browsers := &FakeBrowsersService{GetFunc: func(ctx context.Context, id string, query kernel.BrowserGetParams, opts ...option.RequestOption) (*kernel.BrowserGetResponse, error) {
+		return &kernel.BrowserGetResponse{SessionID: "sess-1", CdpWsURL: "wss://cdp.example/sess-1?jwt=abc.def.ghi"}, nil
+	}}
+	playwright := &fakePlaywright{executeFunc: func(id string, body kernel.BrowserPlaywrightExecuteParams) (*kernel.BrowserPlaywrightExecuteResponse, error) {
+		return &kernel.BrowserPlaywrightExecuteResponse{Success: true, Result: map[string]any{
+			"url":   "https://app.example/callback?state=x&access_token=s3cr3t",
+			"pages": []string{"https://app.example/"},
+		}}, nil
+	}}
+	process := &FakeProcessService{ExecFunc: func(ctx context.Context, id string, body kernel.BrowserProcessExecParams, opts ...option.RequestOption) (*kernel.BrowserProcessExecResponse, error) {
+		out := "Linux kernel-vm\n"
+		if strings.Contains(body.Args[1], "/home/kernel/extensions") {
+			out = "ublock\t\"name\": \"uBlock\" \"version\": \"1.0\"\n"
+		}
+		return &kernel.BrowserProcessExecResponse{StdoutB64: base64.StdEncoding.EncodeToString([]byte(out))}, nil
+	}}
+	telemetry := &FakeBrowserTelemetryService{EventsAutoPagingFunc: func(id string, query kernel.BrowserTelemetryEventsParams, opts ...option.RequestOption) *pagination.OffsetPaginationAutoPager[kernel.BrowserTelemetryEventsResponse] {
+		return pagination.NewOffsetPaginationAutoPager[kernel.BrowserTelemetryEventsResponse](nil, errors.New("telemetry archive unavailable"))
+	}}
+	b := BrowsersCmd{browsers: browsers, computer: &FakeComputerService{}, playwright: playwright, process: process, telemetry: telemetry}
+
+	out := filepath.Join(t.TempDir(), "report.zip")
+	require.NoError(t, b.Bugreport(context.Background(), BrowsersBugreportInput{Identifier: "sess-1", Output: out}))
+
+	files := readZip(t, out)
+	assert.Equal(t, "pngdata", files["screenshot.png"])
+	assert.Contains(t, files["page.json"], "access_token=[REDACTED]")
+	assert.NotContains(t, files["page.json"], "s3cr3t")
+	assert.Contains(t, files["browser.json"], "jwt=[REDACTED]")
+	assert.Contains(t, files["extensions.txt"], "uBlock")
+	assert.Contains(t, files["system.txt"], "Linux kernel-vm")
+
+	// A failing source is recorded in the manifest rather than aborting.
+	var manifest bugreportManifest
+	require.NoError(t, json.Unmarshal([]byte(files["manifest.json"]), &manifest))
+	assert.Equal(t, "sess-1", manifest.SessionID)
+	assert.Contains(t, manifest.Errors["network.ndjson"], "telemetry archive unavailable")
+	assert.NotContains(t, manifest.Files, "network.ndjson")
+	assert.Contains(t, manifest.Files, "screenshot.png")
+}
+
+func TestRedactDiagnostics(t *testing.T) {
+	cases := map[string]string{
+		"GET https://x.test/?id=1&api_key=abc123&q=ok": "GET https://x.test/?id=1&api_key=[REDACTED]&q=ok",
+		`"authorization":"Bearer abc.def"`:             `"authorization":"[REDACTED]"`,
+		"Cookie: sid=123; theme=dark":                  "Cookie: [REDACTED]",
+		"token is Bearer abcdef123":                    "token is Bearer [REDACTED]",
+		"jwt eyJhbGciOi.eyJzdWIiOi.c2lnbmF0dXJl":       "jwt [REDACTED]",
+		"key sk_live_ABCDEFGHIJKLMNOPQRST":             "key [REDACTED]",
+		"nothing to see here":                          "nothing to see here",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, redactDiagnostics(in), in)
+	}
+}
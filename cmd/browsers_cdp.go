@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+const defaultCDPProxyPort = 9222
+
+type BrowsersCDPInput struct {
+	Identifier string
+	Port       int
+}
+
+// cdpProxy serves a Kernel browser's CDP endpoint on a local port. Every
+// WebSocket connection, whatever its path, is forwarded to the session's
+// CdpWsURL, which carries the session's auth token; /json/version points
+// clients at the local endpoint.
+type cdpProxy struct {
+	b     BrowsersCmd
+	id    string
+	local string
+
+	mu     sync.Mutex
+	target *url.URL
+	// stale is set when an upstream handshake fails, so the next connection
+	// looks up the session's CDP URL again before dialing.
+	stale bool
+}
+
+func (p *cdpProxy) upstream(ctx context.Context) (*url.URL, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.target != nil && !p.stale {
+		return p.target, nil
+	}
+	br, err := p.b.getBrowser(ctx, p.id, kernel.BrowserGetParams{})
+	if err != nil {
+		return nil, util.CleanedUpSdkError{Err: err}
+	}
+	if br.CdpWsURL == "" {
+		return nil, fmt.Errorf("browser %s has no CDP URL", p.id)
+	}
+	target, err := url.Parse(br.CdpWsURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CDP URL: %w", err)
+	}
+	// http.Transport dials WebSocket upgrades over plain HTTP(S).
+	switch target.Scheme {
+	case "wss":
+		target.Scheme = "https"
+	case "ws":
+		target.Scheme = "http"
+	}
+	p.target, p.stale = target, false
+	return target, nil
+}
+
+func (p *cdpProxy) markStale() {
+	p.mu.Lock()
+	p.stale = true
+	p.mu.Unlock()
+}
+
+func (p *cdpProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		p.proxyWebSocket(w, r)
+		return
+	}
+	switch strings.TrimSuffix(r.URL.Path, "/") {
+	case "/json/version":
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"Browser":              "Kernel/" + p.id,
+			"Protocol-Version":     "1.3",
+			"webSocketDebuggerUrl": "ws://" + p.local + "/devtools/browser/" + p.id,
+		})
+	default:
+		http.Error(w, "only /json/version and WebSocket connections are supported", http.StatusNotFound)
+	}
+}
+
+func (p *cdpProxy) proxyWebSocket(w http.ResponseWriter, r *http.Request) {
+	target, err := p.upstream(r.Context())
+	if err != nil {
+		pterm.Warning.Printf("CDP connection refused: %v\n", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	rp := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.Out.URL = &url.URL{Scheme: target.Scheme, Host: target.Host, Path: target.Path, RawQuery: target.RawQuery}
+			pr.Out.Host = target.Host
+			// Local tools send their own Origin, which the remote end
+			// has no reason to accept.
+			pr.Out.Header.Del("Origin")
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			if resp.StatusCode != http.StatusSwitchingProtocols {
+				pterm.Warning.Printf("CDP handshake failed: %s\n", resp.Status)
+				p.markStale()
+			}
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			pterm.Warning.Printf("CDP connection failed: %v\n", err)
+			p.markStale()
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+	pterm.Debug.Printf("CDP client connected from %s\n", r.RemoteAddr)
+	rp.ServeHTTP(w, r)
+	pterm.Debug.Printf("CDP client %s disconnected\n", r.RemoteAddr)
+}
+
+// CDP serves the browser's DevTools protocol endpoint on 127.0.0.1 until
+// interrupted, so local Puppeteer, Playwright or DevTools can connect to it
+// as if it were a local Chrome.
+func (b BrowsersCmd) CDP(ctx context.Context, in BrowsersCDPInput) error {
+	if in.Port < 0 || in.Port > 65535 {
+		return fmt.Errorf("invalid --port %d", in.Port)
+	}
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(in.Port)))
+	if err != nil {
+		return fmt.Errorf("listen on port %d: %w", in.Port, err)
+	}
+	proxy := &cdpProxy{b: b, id: br.SessionID, local: ln.Addr().String()}
+	if _, err := proxy.upstream(ctx); err != nil {
+		ln.Close()
+		return err
+	}
+
+	srv := &http.Server{Handler: proxy, ReadHeaderTimeout: 10 * time.Second}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+
+	httpURL := "http://" + proxy.local
+	pterm.Info.Printf("Proxying CDP for %s on %s (Ctrl+C to stop)\n", br.SessionID, proxy.local)
+	PrintTableNoPad(pterm.TableData{
+		{"Client", "Connect with"},
+		{"Puppeteer", fmt.Sprintf("puppeteer.connect({ browserURL: '%s' })", httpURL)},
+		{"Playwright", fmt.Sprintf("chromium.connectOverCDP('%s')", httpURL)},
+		{"WebSocket", "ws://" + proxy.local + "/devtools/browser/" + br.SessionID},
+	}, true)
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	select {
+	case <-ctx.Done():
+	case err := <-errc:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Second)
+	defer cancel()
+	// Hijacked WebSocket connections aren't tracked by Shutdown; they end
+	// with the process.
+	return srv.Shutdown(shutdownCtx)
+}
+
+var browsersCDPCmd = &cobra.Command{
+	Use:   "cdp <id-or-name>",
+	Short: "Serve a browser's CDP endpoint on a local port",
+	Long: `Bridge a browser's Chrome DevTools Protocol endpoint to 127.0.0.1 so local
+Puppeteer, Playwright or other CDP clients can connect to it as if it were a
+local Chrome started with --remote-debugging-port.
+
+GET /json/version reports the local WebSocket URL, and every WebSocket
+connection is forwarded to the session with its auth token attached. Clients
+can disconnect and reconnect freely; if a connection fails, the session's CDP
+URL is looked up again before the next one. /json/list is not available, so
+tools that discover page targets through it won't find any.`,
+	Example: `  kernel browsers cdp abc123
+  kernel browsers cdp abc123 --port 9333`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBrowsersCDP,
+}
+
+func init() {
+	browsersCmd.AddCommand(browsersCDPCmd)
+	browsersCDPCmd.ValidArgsFunction = completeResourceArg("browser", completeBrowser)
+	browsersCDPCmd.Flags().Int("port", defaultCDPProxyPort, "Local port to listen on (0 picks a free port)")
+}
+
+func runBrowsersCDP(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	port, _ := cmd.Flags().GetInt("port")
+	svc := client.Browsers
+	b := BrowsersCmd{browsers: &svc}
+	return b.CDP(cmd.Context(), BrowsersCDPInput{Identifier: args[0], Port: port})
+}
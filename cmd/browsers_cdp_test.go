@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cdpEchoUpstream accepts WebSocket upgrades on /browser/cdp with jwt=good
+// and then echoes raw bytes back; anything else is rejected.
+func cdpEchoUpstream(t *testing.T, gotQuery *atomic.Value) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery.Store(r.URL.RawQuery)
+		if r.URL.Path != "/browser/cdp" || r.URL.Query().Get("jwt") != "good" || r.Header.Get("Origin") != "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		_ = rw.Flush()
+		_, _ = io.Copy(conn, rw)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func dialCDPUpgrade(t *testing.T, addr string) (net.Conn, *bufio.Reader, *http.Response) {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	_, err = io.WriteString(conn, "GET /devtools/browser/x HTTP/1.1\r\nHost: "+addr+"\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nOrigin: http://localhost\r\nSec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+	require.NoError(t, err)
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	require.NoError(t, err)
+	return conn, br, resp
+}
+
+func TestCDPProxy_ForwardsWebSocketWithToken(t *testing.T) {
+	var gotQuery atomic.Value
+	upstream := cdpEchoUpstream(t, &gotQuery)
+	fake := &FakeBrowsersService{GetFunc: func(ctx context.Context, id string, query kernel.BrowserGetParams, opts ...option.RequestOption) (*kernel.BrowserGetResponse, error) {
+		return &kernel.BrowserGetResponse{SessionID: id, CdpWsURL: "ws://" + upstream.Listener.Addr().String() + "/browser/cdp?jwt=good"}, nil
+	}}
+	proxy := &cdpProxy{b: BrowsersCmd{browsers: fake}, id: "sess-1"}
+	local := httptest.NewServer(proxy)
+	defer local.Close()
+	proxy.local = local.Listener.Addr().String()
+
+	conn, br, resp := dialCDPUpgrade(t, proxy.local)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	assert.Equal(t, "jwt=good", gotQuery.Load())
+
+	_, err := io.WriteString(conn, "ping")
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(br, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+}
+
+func TestCDPProxy_JSONVersionPointsAtLocalEndpoint(t *testing.T) {
+	proxy := &cdpProxy{id: "sess-1", local: "127.0.0.1:9222"}
+	rec := httptest.NewRecorder()
+	proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/json/version", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "ws://127.0.0.1:9222/devtools/browser/sess-1", body["webSocketDebuggerUrl"])
+}
+
+func TestCDPProxy_RefreshesURLAfterFailedHandshake(t *testing.T) {
+	var gotQuery atomic.Value
+	upstream := cdpEchoUpstream(t, &gotQuery)
+	var gets atomic.Int32
+	fake := &FakeBrowsersService{GetFunc: func(ctx context.Context, id string, query kernel.BrowserGetParams, opts ...option.RequestOption) (*kernel.BrowserGetResponse, error) {
+		// The first URL carries an expired token; the refreshed one works.
+		token := "expired"
+		if gets.Add(1) > 1 {
+			token = "good"
+		}
+		return &kernel.BrowserGetResponse{SessionID: id, CdpWsURL: "ws://" + upstream.Listener.Addr().String() + "/browser/cdp?jwt=" + token}, nil
+	}}
+	proxy := &cdpProxy{b: BrowsersCmd{browsers: fake}, id: "sess-1"}
+	local := httptest.NewServer(proxy)
+	defer local.Close()
+	proxy.local = local.Listener.Addr().String()
+
+	setupStdoutCapture(t)
+	_, _, resp := dialCDPUpgrade(t, proxy.local)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	_, _, resp = dialCDPUpgrade(t, proxy.local)
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	assert.Equal(t, int32(2), gets.Load())
+	assert.True(t, strings.Contains(gotQuery.Load().(string), "good"))
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var browsersConsoleCmd = &cobra.Command{
+	Use:   "console <id-or-name>",
+	Short: "Show a browser's page console messages",
+	Long: `Show console.log/warn/error messages and uncaught exceptions from a browser
+session's pages, with the script location of each, e.g. to see the JS error
+that broke a login form without opening DevTools. Without --follow, messages
+from the last --since are printed; with --follow, new ones stream until
+interrupted.
+
+Messages come from telemetry, so the session must capture the console
+category: kernel browsers update <id> --telemetry=console
+
+This is 'kernel browsers logs' limited to the console; use that command for
+network events and other filters.`,
+	Example: `  kernel browsers console abc123
+  kernel browsers console abc123 --follow --level error`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBrowsersConsole,
+}
+
+func init() {
+	browsersCmd.AddCommand(browsersConsoleCmd)
+	browsersConsoleCmd.ValidArgsFunction = completeResourceArg("browser", completeBrowser)
+	browsersConsoleCmd.Flags().BoolP("follow", "f", false, "Stream new messages until interrupted")
+	browsersConsoleCmd.Flags().String("level", "", "Minimum level to show: debug, info, warning (or warn) or error")
+	browsersConsoleCmd.Flags().String("since", "15m", "Without --follow, show messages since this time (RFC-3339 timestamp or duration)")
+	browsersConsoleCmd.Flags().StringP("output", "o", "", "Output format: json for newline-delimited JSON")
+	_ = browsersConsoleCmd.RegisterFlagCompletionFunc("level", cobra.FixedCompletions(browserLogLevels, cobra.ShellCompDirectiveNoFileComp))
+}
+
+func runBrowsersConsole(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	follow, _ := cmd.Flags().GetBool("follow")
+	level, _ := cmd.Flags().GetString("level")
+	since, _ := cmd.Flags().GetString("since")
+	output, _ := cmd.Flags().GetString("output")
+
+	filters := map[string]string{}
+	if level != "" {
+		level = strings.ToLower(level)
+		if level == "warn" {
+			level = "warning"
+		}
+		if !slices.Contains(browserLogLevels, level) {
+			return fmt.Errorf("invalid --level %q: must be one of %s", level, strings.Join(browserLogLevels, ", "))
+		}
+		filters["level"] = level
+	}
+	svc := client.Browsers
+	b := BrowsersCmd{browsers: &svc, telemetry: &svc.Telemetry}
+	return b.Logs(cmd.Context(), BrowsersLogsInput{
+		Identifier: args[0],
+		Follow:     follow,
+		Since:      since,
+		Filters:    filters,
+		Output:     output,
+	})
+}
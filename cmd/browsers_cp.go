@@ -0,0 +1,319 @@
+package cmd
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kernel/cli/pkg/table"
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// cpProgressThreshold is the transfer size from which cp shows a progress bar.
+const cpProgressThreshold = 1 << 20
+
+type BrowsersCpInput struct {
+	Src       string
+	Dst       string
+	Recursive bool
+}
+
+// remotePath is the <id>:<path> side of a cp.
+type remotePath struct {
+	Identifier string
+	Path       string
+}
+
+// parseRemotePath recognizes <id>:/absolute/path. Local paths that contain a
+// colon (e.g. C:\dir) don't match because the path must start with '/'.
+func parseRemotePath(arg string) (remotePath, bool) {
+	id, p, ok := strings.Cut(arg, ":")
+	if !ok || id == "" || strings.ContainsAny(id, `/\`) || !strings.HasPrefix(p, "/") {
+		return remotePath{}, false
+	}
+	return remotePath{Identifier: id, Path: p}, true
+}
+
+// Cp copies a file or, with Recursive, a directory between this machine and
+// a browser VM. Exactly one of Src and Dst is <id>:<path>. As with cp, a
+// destination that is an existing directory receives the source by name.
+func (b BrowsersCmd) Cp(ctx context.Context, in BrowsersCpInput) error {
+	if b.fs == nil {
+		return fmt.Errorf("fs service not available")
+	}
+	src, srcRemote := parseRemotePath(in.Src)
+	dst, dstRemote := parseRemotePath(in.Dst)
+	switch {
+	case srcRemote && dstRemote:
+		return fmt.Errorf("copying between two browsers is not supported; copy through a local path")
+	case !srcRemote && !dstRemote:
+		return fmt.Errorf("one of the paths must be <id>:/path in the browser VM")
+	case dstRemote:
+		return b.cpUpload(ctx, in.Src, dst, in.Recursive)
+	default:
+		return b.cpDownload(ctx, src, in.Dst, in.Recursive)
+	}
+}
+
+func (b BrowsersCmd) cpUpload(ctx context.Context, local string, dst remotePath, recursive bool) error {
+	info, err := os.Stat(local)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() && !recursive {
+		return fmt.Errorf("%s is a directory (use -r to copy directories)", local)
+	}
+	br, err := b.getBrowser(ctx, dst.Identifier, kernel.BrowserGetParams{})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	target := dst.Path
+	if existing, err := b.fs.FileInfo(ctx, br.SessionID, kernel.BrowserFFileInfoParams{Path: target}); err == nil && existing.IsDir {
+		target = path.Join(target, filepath.Base(filepath.Clean(local)))
+	} else if err != nil && !util.IsNotFound(err) {
+		return util.CleanedUpSdkError{Err: err}
+	}
+
+	if !info.IsDir() {
+		f, err := os.Open(local)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r, done := withCpProgress(f, info.Size(), "Uploading "+filepath.Base(local))
+		defer done()
+		params := kernel.BrowserFWriteFileParams{Path: target, Mode: kernel.Opt(fmt.Sprintf("%o", info.Mode().Perm()))}
+		if err := b.fs.WriteFile(ctx, br.SessionID, r, params); err != nil {
+			return util.CleanedUpSdkError{Err: err}
+		}
+		pterm.Success.Printf("Copied %s to %s:%s\n", local, dst.Identifier, target)
+		return nil
+	}
+
+	tmp, err := os.CreateTemp("", "kernel-cp-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	if err := zipDirWithModes(local, tmp); err != nil {
+		return fmt.Errorf("zip %s: %w", local, err)
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	r, done := withCpProgress(tmp, size, "Uploading "+filepath.Base(local))
+	defer done()
+	if err := b.fs.UploadZip(ctx, br.SessionID, kernel.BrowserFUploadZipParams{DestPath: target, ZipFile: r}); err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	pterm.Success.Printf("Copied %s to %s:%s\n", local, dst.Identifier, target)
+	return nil
+}
+
+func (b BrowsersCmd) cpDownload(ctx context.Context, src remotePath, local string, recursive bool) error {
+	br, err := b.getBrowser(ctx, src.Identifier, kernel.BrowserGetParams{})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	info, err := b.fs.FileInfo(ctx, br.SessionID, kernel.BrowserFFileInfoParams{Path: src.Path})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	if info.IsDir && !recursive {
+		return fmt.Errorf("%s:%s is a directory (use -r to copy directories)", src.Identifier, src.Path)
+	}
+	target := local
+	if st, err := os.Stat(local); err == nil && st.IsDir() {
+		target = filepath.Join(local, path.Base(src.Path))
+	}
+
+	if !info.IsDir {
+		res, err := b.fs.ReadFile(ctx, br.SessionID, kernel.BrowserFReadFileParams{Path: src.Path})
+		if err != nil {
+			return util.CleanedUpSdkError{Err: err}
+		}
+		defer res.Body.Close()
+		mode := parseRemoteFileMode(info.Mode)
+		f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+		if err != nil {
+			return err
+		}
+		r, done := withCpProgress(res.Body, info.SizeBytes, "Downloading "+path.Base(src.Path))
+		_, err = io.Copy(f, r)
+		done()
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("write %s: %w", target, err)
+		}
+		// OpenFile's mode only applies to new files and is subject to umask.
+		if err := os.Chmod(target, mode); err != nil {
+			return err
+		}
+		pterm.Success.Printf("Copied %s:%s to %s\n", src.Identifier, src.Path, target)
+		return nil
+	}
+
+	res, err := b.fs.DownloadDirZip(ctx, br.SessionID, kernel.BrowserFDownloadDirZipParams{Path: src.Path})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	defer res.Body.Close()
+	tmp, err := os.CreateTemp("", "kernel-cp-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	r, done := withCpProgress(res.Body, res.ContentLength, "Downloading "+path.Base(src.Path))
+	_, err = io.Copy(tmp, r)
+	done()
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("download %s: %w", src.Path, err)
+	}
+	if err := util.Unzip(tmp.Name(), target); err != nil {
+		return err
+	}
+	pterm.Success.Printf("Copied %s:%s to %s\n", src.Identifier, src.Path, target)
+	return nil
+}
+
+// zipDirWithModes writes every file under dir to w, keeping permission bits.
+func zipDirWithModes(dir string, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			pterm.Warning.Printf("Skipping %s: not a regular file\n", p)
+			return nil
+		}
+		hdr, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			hdr.Name += "/"
+			_, err = zw.CreateHeader(hdr)
+			return err
+		}
+		hdr.Method = zip.Deflate
+		fw, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(fw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// parseRemoteFileMode reads the mode the fs API reports, either octal
+// ("644", "0755") or symbolic ("-rwxr-xr-x"), defaulting to 0644.
+func parseRemoteFileMode(s string) os.FileMode {
+	if n, err := strconv.ParseUint(s, 8, 32); err == nil {
+		return os.FileMode(n).Perm()
+	}
+	if len(s) >= 9 {
+		perm := s[len(s)-9:]
+		var mode os.FileMode
+		for i, c := range perm {
+			if c != '-' {
+				mode |= 1 << uint(8-i)
+			}
+		}
+		return mode
+	}
+	return 0o644
+}
+
+// withCpProgress wraps r in a progress bar when the transfer is large and
+// stdout is a terminal. The returned func stops the bar.
+func withCpProgress(r io.Reader, size int64, title string) (io.Reader, func()) {
+	if size < cpProgressThreshold || !table.IsStdoutTTY() {
+		return r, func() {}
+	}
+	bar, err := pterm.DefaultProgressbar.WithTotal(int(size)).WithTitle(title).WithShowCount(false).Start()
+	if err != nil {
+		return r, func() {}
+	}
+	return &progressReader{r: r, bar: bar}, func() { _, _ = bar.Stop() }
+}
+
+type progressReader struct {
+	r   io.Reader
+	bar *pterm.ProgressbarPrinter
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.bar.Add(n)
+	}
+	return n, err
+}
+
+var browsersCpCmd = &cobra.Command{
+	Use:   "cp <src> <dst>",
+	Short: "Copy files to or from a browser VM",
+	Long: `Copy a file or directory between this machine and a browser VM. Write the VM
+side as <id-or-name>:/absolute/path. If the destination is an existing
+directory, the source is copied into it, as with cp.
+
+Directories need -r and are transferred as a zip. File permission bits are
+kept; ownership is not.`,
+	Example: `  kernel browsers cp ./data.csv abc123:/tmp/
+  kernel browsers cp abc123:/root/Downloads/report.pdf .
+  kernel browsers cp -r abc123:/tmp/screenshots ./screenshots`,
+	Args: cobra.ExactArgs(2),
+	RunE: runBrowsersCp,
+}
+
+func init() {
+	browsersCmd.AddCommand(browsersCpCmd)
+	browsersCpCmd.Flags().BoolP("recursive", "r", false, "Copy directories recursively")
+}
+
+func runBrowsersCp(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	recursive, _ := cmd.Flags().GetBool("recursive")
+	svc := client.Browsers
+	b := BrowsersCmd{browsers: &svc, fs: &svc.Fs}
+	return b.Cp(cmd.Context(), BrowsersCpInput{Src: args[0], Dst: args[1], Recursive: recursive})
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRemotePath(t *testing.T) {
+	rp, ok := parseRemotePath("abc123:/tmp/file.txt")
+	assert.True(t, ok)
+	assert.Equal(t, remotePath{Identifier: "abc123", Path: "/tmp/file.txt"}, rp)
+
+	for _, local := range []string{"./file.txt", "/tmp/file.txt", `C:\data`, "abc123:relative", "dir/x:/y"} {
+		_, ok := parseRemotePath(local)
+		assert.False(t, ok, local)
+	}
+}
+
+func TestParseRemoteFileMode(t *testing.T) {
+	assert.Equal(t, os.FileMode(0o755), parseRemoteFileMode("755"))
+	assert.Equal(t, os.FileMode(0o600), parseRemoteFileMode("0600"))
+	assert.Equal(t, os.FileMode(0o754), parseRemoteFileMode("-rwxr-xr--"))
+	assert.Equal(t, os.FileMode(0o644), parseRemoteFileMode(""))
+}
+
+func TestBrowsersCp_UploadFileIntoDirectory(t *testing.T) {
+	setupStdoutCapture(t)
+	local := filepath.Join(t.TempDir(), "run.sh")
+	require.NoError(t, os.WriteFile(local, []byte("echo hi\n"), 0o750))
+
+	var params kernel.BrowserFWriteFileParams
+	var written string
+	fs := &FakeFSService{
+		FileInfoFunc: func(ctx context.Context, id string, query kernel.BrowserFFileInfoParams, opts ...option.RequestOption) (*kernel.BrowserFFileInfoResponse, error) {
+			return &kernel.BrowserFFileInfoResponse{Path: query.Path, IsDir: true}, nil
+		},
+		WriteFileFunc: func(ctx context.Context, id string, contents io.Reader, body kernel.BrowserFWriteFileParams, opts ...option.RequestOption) error {
+			data, _ := io.ReadAll(contents)
+			params, written = body, string(data)
+			return nil
+		},
+	}
+	b := BrowsersCmd{browsers: execTestBrowsers(), fs: fs}
+	require.NoError(t, b.Cp(context.Background(), BrowsersCpInput{Src: local, Dst: "sess-1:/tmp"}))
+
+	assert.Equal(t, "/tmp/run.sh", params.Path)
+	assert.Equal(t, "750", params.Mode.Value)
+	assert.Equal(t, "echo hi\n", written)
+
+	err := b.Cp(context.Background(), BrowsersCpInput{Src: filepath.Dir(local), Dst: "sess-1:/tmp"})
+	assert.ErrorContains(t, err, "use -r")
+}
+
+func TestBrowsersCp_DownloadDirectory(t *testing.T) {
+	setupStdoutCapture(t)
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	w, err := zw.Create("a.txt")
+	require.NoError(t, err)
+	_, _ = w.Write([]byte("A"))
+	require.NoError(t, zw.Close())
+
+	fs := &FakeFSService{
+		FileInfoFunc: func(ctx context.Context, id string, query kernel.BrowserFFileInfoParams, opts ...option.RequestOption) (*kernel.BrowserFFileInfoResponse, error) {
+			return &kernel.BrowserFFileInfoResponse{Path: query.Path, IsDir: true}, nil
+		},
+		DownloadDirZipFunc: func(ctx context.Context, id string, query kernel.BrowserFDownloadDirZipParams, opts ...option.RequestOption) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(archive.Bytes())), ContentLength: int64(archive.Len())}, nil
+		},
+	}
+	dest := t.TempDir()
+	b := BrowsersCmd{browsers: execTestBrowsers(), fs: fs}
+	require.NoError(t, b.Cp(context.Background(), BrowsersCpInput{Src: "sess-1:/tmp/shots", Dst: dest, Recursive: true}))
+
+	data, err := os.ReadFile(filepath.Join(dest, "shots", "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "A", string(data))
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"golang.org/x/sync/errgroup"
+)
+
+const browsersDeleteAllPageSize = 100
+
+type BrowsersDeleteAllInput struct {
+	// OlderThan limits the deletion to sessions created at least this long ago.
+	OlderThan   time.Duration
+	Query       string
+	Tags        map[string]string
+	DryRun      bool
+	SkipConfirm bool
+	Concurrency int
+}
+
+// DeleteAll deletes every active browser session that matches the filters,
+// for cleaning up sessions a crashed script left running.
+func (b BrowsersCmd) DeleteAll(ctx context.Context, in BrowsersDeleteAllInput) error {
+	if in.OlderThan < 0 {
+		return fmt.Errorf("--older-than must not be negative")
+	}
+	if in.Concurrency <= 0 {
+		in.Concurrency = defaultBatchConcurrency
+	}
+
+	targets, err := b.listActiveBrowsers(ctx, in)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		pterm.Info.Println("No browsers to delete")
+		return nil
+	}
+
+	now := time.Now()
+	rows := pterm.TableData{{"Session ID", "Name", "Created At", "Age"}}
+	for _, br := range targets {
+		rows = append(rows, []string{br.SessionID, util.OrDash(br.Name), util.FormatLocal(br.CreatedAt), now.Sub(br.CreatedAt).Round(time.Second).String()})
+	}
+	PrintTableNoPad(rows, true)
+
+	if in.DryRun {
+		pterm.Info.Printf("Would delete %d browser(s)\n", len(targets))
+		return nil
+	}
+	if !in.SkipConfirm {
+		pterm.DefaultInteractiveConfirm.DefaultText = fmt.Sprintf("Delete these %d browser(s)?", len(targets))
+		ok, _ := pterm.DefaultInteractiveConfirm.Show()
+		if !ok {
+			pterm.Info.Println("Deletion cancelled")
+			return nil
+		}
+	}
+
+	var (
+		mu     sync.Mutex
+		failed int
+	)
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(in.Concurrency)
+	for _, br := range targets {
+		g.Go(func() error {
+			err := b.browsers.DeleteByID(gctx, br.SessionID)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && !util.IsNotFound(err) {
+				failed++
+				pterm.Error.Printf("Failed to delete browser %s: %v\n", br.SessionID, util.CleanedUpSdkError{Err: err})
+				return nil
+			}
+			b.releaseProfileLocks(br.SessionID)
+			pterm.Success.Printf("Deleted browser: %s\n", br.SessionID)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d of %d browser(s)", failed, len(targets))
+	}
+	pterm.Success.Printf("Deleted %d browser(s)\n", len(targets))
+	return nil
+}
+
+// listActiveBrowsers pages through the active sessions and keeps those
+// matching the filters.
+func (b BrowsersCmd) listActiveBrowsers(ctx context.Context, in BrowsersDeleteAllInput) ([]kernel.BrowserListResponse, error) {
+	cutoff := time.Now().Add(-in.OlderThan)
+	var targets []kernel.BrowserListResponse
+	for offset := int64(0); ; offset += browsersDeleteAllPageSize {
+		params := kernel.BrowserListParams{
+			Status: kernel.BrowserListParamsStatusActive,
+			Limit:  kernel.Opt(int64(browsersDeleteAllPageSize)),
+			Offset: kernel.Opt(offset),
+		}
+		if in.Query != "" {
+			params.Query = kernel.Opt(in.Query)
+		}
+		if len(in.Tags) > 0 {
+			params.Tags = in.Tags
+		}
+		page, err := b.browsers.List(ctx, params)
+		if err != nil {
+			return nil, util.CleanedUpSdkError{Err: err}
+		}
+		if page == nil {
+			break
+		}
+		for _, br := range page.Items {
+			if in.OlderThan == 0 || !br.CreatedAt.After(cutoff) {
+				targets = append(targets, br)
+			}
+		}
+		if len(page.Items) < browsersDeleteAllPageSize {
+			break
+		}
+	}
+	return targets, nil
+}
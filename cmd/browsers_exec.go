@@ -0,0 +1,392 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+// execStdinScript runs "$@" with stdin redirected from the file in $0, then
+// removes the file and exits with the command's status.
+const execStdinScript = `"$@" < "$0"; rc=$?; rm -f "$0"; exit $rc`
+
+type BrowsersExecInput struct {
+	Identifier string
+	// Argv is the command and its arguments, run without a shell.
+	Argv    []string
+	Cwd     string
+	Timeout int
+	AsUser  string
+	AsRoot  bool
+	Env     map[string]string
+	// Stdin, when set, is uploaded to the VM and fed to the command.
+	Stdin io.Reader
+	// Stdout and Stderr default to the process's own.
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Exec runs a command in the browser VM and writes its stdout and stderr
+// unchanged. A non-zero exit status is returned as an exitCodeError so the
+// CLI exits with the same code.
+func (b BrowsersCmd) Exec(ctx context.Context, in BrowsersExecInput) error {
+	if b.process == nil {
+		return fmt.Errorf("process service not available")
+	}
+	if len(in.Argv) == 0 {
+		return fmt.Errorf("a command is required: kernel browsers exec <id> -- <command> [args...]")
+	}
+	if in.Stdout == nil {
+		in.Stdout = os.Stdout
+	}
+	if in.Stderr == nil {
+		in.Stderr = os.Stderr
+	}
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+
+	params := kernel.BrowserProcessExecParams{Command: in.Argv[0], Args: in.Argv[1:]}
+	if in.Stdin != nil {
+		if b.fs == nil {
+			return fmt.Errorf("filesystem service not available for --stdin")
+		}
+		data, err := io.ReadAll(in.Stdin)
+		if err != nil {
+			return fmt.Errorf("read stdin: %w", err)
+		}
+		path := fmt.Sprintf("/tmp/kernel-exec-stdin-%d", time.Now().UnixNano())
+		if err := b.fs.WriteFile(ctx, br.SessionID, bytes.NewReader(data), kernel.BrowserFWriteFileParams{Path: path, Mode: kernel.Opt("644")}); err != nil {
+			return fmt.Errorf("upload stdin: %w", util.CleanedUpSdkError{Err: err})
+		}
+		defer func() {
+			// The script removes the file itself; this covers a failed exec.
+			_ = b.fs.DeleteFile(context.WithoutCancel(ctx), br.SessionID, kernel.BrowserFDeleteFileParams{Path: path})
+		}()
+		params.Command = "/bin/bash"
+		params.Args = append([]string{"-c", execStdinScript, path}, in.Argv...)
+	}
+	applyExecOptions(&params, in)
+
+	res, err := b.process.Exec(ctx, br.SessionID, params)
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	if err := writeBase64(in.Stdout, res.StdoutB64); err != nil {
+		return fmt.Errorf("stdout: %w", err)
+	}
+	if err := writeBase64(in.Stderr, res.StderrB64); err != nil {
+		return fmt.Errorf("stderr: %w", err)
+	}
+	pterm.Debug.Printf("exit code %d after %dms\n", res.ExitCode, res.DurationMs)
+
+	if res.ExitCode != 0 {
+		code := int(res.ExitCode)
+		if code < 0 || code > 255 {
+			code = 1
+		}
+		return exitCodeError{fmt.Errorf("command exited with code %d", res.ExitCode), code}
+	}
+	return nil
+}
+
+// applyExecOptions sets the optional exec parameters from in.
+func applyExecOptions(params *kernel.BrowserProcessExecParams, in BrowsersExecInput) {
+	if in.Cwd != "" {
+		params.Cwd = kernel.Opt(in.Cwd)
+	}
+	if in.Timeout > 0 {
+		params.TimeoutSec = kernel.Opt(int64(in.Timeout))
+	}
+	if in.AsUser != "" {
+		params.AsUser = kernel.Opt(in.AsUser)
+	}
+	if in.AsRoot {
+		params.AsRoot = kernel.Opt(true)
+	}
+	if len(in.Env) > 0 {
+		params.Env = in.Env
+	}
+}
+
+type BrowsersExecByTagsInput struct {
+	BrowsersExecInput
+	Tags        map[string]string
+	Concurrency int
+	Output      string
+}
+
+// browsersExecResult is one session's outcome of exec --label.
+type browsersExecResult struct {
+	SessionID  string `json:"session_id"`
+	Name       string `json:"name,omitempty"`
+	ExitCode   int64  `json:"exit_code"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (r browsersExecResult) ok() bool {
+	return r.Error == "" && r.ExitCode == 0
+}
+
+// ExecByTags runs a command in every active session with all of in.Tags,
+// with at most in.Concurrency commands in flight. Each session's output is
+// printed as a block once its command finishes, followed by a summary; with
+// JSON output the results are printed as an array instead. It fails if the
+// command failed anywhere.
+func (b BrowsersCmd) ExecByTags(ctx context.Context, in BrowsersExecByTagsInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if b.process == nil {
+		return fmt.Errorf("process service not available")
+	}
+	if len(in.Tags) == 0 {
+		return fmt.Errorf("--label needs at least one KEY=VALUE pair")
+	}
+	if len(in.Argv) == 0 {
+		return fmt.Errorf("a command is required: kernel browsers exec --label KEY=VALUE -- <command> [args...]")
+	}
+	if in.Concurrency <= 0 {
+		in.Concurrency = defaultBatchConcurrency
+	}
+	if in.Stdout == nil {
+		in.Stdout = os.Stdout
+	}
+	jsonOutput := in.Output == "json"
+
+	targets, err := b.listActiveBrowsers(ctx, BrowsersDeleteAllInput{Tags: in.Tags})
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		if jsonOutput {
+			return util.PrintJSON([]any{})
+		}
+		pterm.Info.Println("No active browsers match the labels")
+		return nil
+	}
+	if !jsonOutput {
+		pterm.Info.Printf("Running on %d browser(s), %d at a time…\n", len(targets), in.Concurrency)
+	}
+
+	params := kernel.BrowserProcessExecParams{Command: in.Argv[0], Args: in.Argv[1:]}
+	applyExecOptions(&params, in.BrowsersExecInput)
+
+	results := make([]browsersExecResult, len(targets))
+	var mu sync.Mutex
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(in.Concurrency)
+	for i, br := range targets {
+		g.Go(func() error {
+			r := browsersExecResult{SessionID: br.SessionID, Name: br.Name}
+			started := time.Now()
+			res, err := b.process.Exec(gctx, br.SessionID, params)
+			r.DurationMs = time.Since(started).Milliseconds()
+			if err != nil {
+				r.Error = util.CleanedUpSdkError{Err: err}.Error()
+			} else {
+				r.ExitCode = res.ExitCode
+				r.Stdout = decodeBase64Output(res.StdoutB64)
+				r.Stderr = decodeBase64Output(res.StderrB64)
+			}
+			results[i] = r
+
+			if !jsonOutput {
+				mu.Lock()
+				defer mu.Unlock()
+				printBrowsersExecResult(in.Stdout, r)
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	failed := 0
+	for _, r := range results {
+		if !r.ok() {
+			failed++
+		}
+	}
+	if jsonOutput {
+		if err := util.PrintJSON(results); err != nil {
+			return err
+		}
+	} else {
+		rows := pterm.TableData{{"Session ID", "Name", "Exit Code", "Duration", "Error"}}
+		for _, r := range results {
+			exit := strconv.FormatInt(r.ExitCode, 10)
+			if r.Error != "" {
+				exit = "-"
+			}
+			rows = append(rows, []string{r.SessionID, util.OrDash(r.Name), exit, (time.Duration(r.DurationMs) * time.Millisecond).String(), util.OrDash(r.Error)})
+		}
+		PrintTableNoPad(rows, true)
+	}
+	if failed > 0 {
+		return fmt.Errorf("command failed on %d of %d browser(s)", failed, len(results))
+	}
+	return nil
+}
+
+// printBrowsersExecResult writes one session's output under a header naming
+// the session and how the command ended.
+func printBrowsersExecResult(w io.Writer, r browsersExecResult) {
+	label := r.SessionID
+	if r.Name != "" {
+		label += " (" + r.Name + ")"
+	}
+	status := fmt.Sprintf("exit %d", r.ExitCode)
+	if r.Error != "" {
+		status = "error: " + r.Error
+	}
+	fmt.Fprintf(w, "==> %s: %s\n", label, status)
+	for _, out := range []string{r.Stdout, r.Stderr} {
+		if out == "" {
+			continue
+		}
+		fmt.Fprint(w, out)
+		if !strings.HasSuffix(out, "\n") {
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// decodeBase64Output decodes command output, keeping undecodable output as
+// is.
+func decodeBase64Output(b64 string) string {
+	data, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return b64
+	}
+	return string(data)
+}
+
+func writeBase64(w io.Writer, b64 string) error {
+	if b64 == "" {
+		return nil
+	}
+	data, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+var browsersExecCmd = &cobra.Command{
+	Use:   "exec <id-or-name> -- <command> [args...]",
+	Short: "Run a command in a browser VM",
+	Long: `Run a command in a browser VM and print its stdout and stderr as is. The CLI
+exits with the command's exit code, so scripts can check it.
+
+The command runs without a shell; use 'bash -c' for pipes and globs. With
+--stdin, this process's stdin is uploaded and fed to the command.
+
+With --label KEY=VALUE instead of an ID, the command runs in every active
+browser with that label, --concurrency at a time. Each browser's output is
+printed once its command finishes, followed by a summary of exit codes.
+
+For JSON output and timing details, use 'kernel browsers process exec'.`,
+	Example: `  kernel browsers exec abc123 -- ls -la /tmp
+  kernel browsers exec abc123 --as-root -- bash -c 'apt-get update && apt-get install -y jq'
+  cat data.json | kernel browsers exec abc123 --stdin -- jq .items
+  kernel browsers exec --label batch=run42 --concurrency 10 -- df -h /`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("tag") {
+			if cmd.ArgsLenAtDash() != 0 {
+				return fmt.Errorf("--label does not take browser IDs; put the command after --")
+			}
+			return cobra.MinimumNArgs(1)(cmd, args)
+		}
+		if cmd.Flags().Changed("concurrency") {
+			return fmt.Errorf("--concurrency requires --label")
+		}
+		return cobra.MinimumNArgs(2)(cmd, args)
+	},
+	RunE: runBrowsersExec,
+}
+
+func init() {
+	browsersCmd.AddCommand(browsersExecCmd)
+	browsersExecCmd.ValidArgsFunction = completeResourceArg("browser", completeBrowser)
+	browsersExecCmd.Flags().Bool("as-root", false, "Run as root")
+	browsersExecCmd.Flags().String("as-user", "", "Run as this user")
+	browsersExecCmd.Flags().String("cwd", "", "Working directory")
+	browsersExecCmd.Flags().Int("timeout", 0, "Timeout in seconds (default per server)")
+	browsersExecCmd.Flags().StringArrayP("env", "e", nil, "Environment variable KEY=VALUE (repeatable)")
+	browsersExecCmd.Flags().Bool("stdin", false, "Feed this process's stdin to the command")
+	browsersExecCmd.Flags().StringArray("tag", nil, "Run in every active browser with tag KEY=VALUE instead of one browser (repeatable; a browser must match every pair; alias --label)")
+	browsersExecCmd.Flags().Int("concurrency", defaultBatchConcurrency, "With --label, maximum number of browsers running the command at once")
+	browsersExecCmd.Flags().StringP("output", "o", "", "With --label, output format: json for an array of per-browser results")
+	browsersExecCmd.Flags().SetNormalizeFunc(labelFlagAsTag)
+	browsersExecCmd.MarkFlagsMutuallyExclusive("as-root", "as-user")
+	browsersExecCmd.MarkFlagsMutuallyExclusive("tag", "stdin")
+}
+
+func runBrowsersExec(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	asRoot, _ := cmd.Flags().GetBool("as-root")
+	asUser, _ := cmd.Flags().GetString("as-user")
+	cwd, _ := cmd.Flags().GetString("cwd")
+	timeout, _ := cmd.Flags().GetInt("timeout")
+	envSpecs, _ := cmd.Flags().GetStringArray("env")
+	useStdin, _ := cmd.Flags().GetBool("stdin")
+
+	env, malformed := parseKeyValueSpecs(envSpecs)
+	if len(malformed) > 0 {
+		return fmt.Errorf("invalid --env %q: expected KEY=VALUE", malformed[0])
+	}
+	svc := client.Browsers
+	b := BrowsersCmd{browsers: &svc, process: &svc.Process, fs: &svc.Fs}
+	if cmd.Flags().Changed("tag") {
+		tags, _ := tagsFromFlag(cmd, "tag")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		output, _ := cmd.Flags().GetString("output")
+		return b.ExecByTags(cmd.Context(), BrowsersExecByTagsInput{
+			BrowsersExecInput: BrowsersExecInput{
+				Argv:    args,
+				Cwd:     cwd,
+				Timeout: timeout,
+				AsUser:  asUser,
+				AsRoot:  asRoot,
+				Env:     env,
+			},
+			Tags:        tags,
+			Concurrency: concurrency,
+			Output:      output,
+		})
+	}
+	if cmd.Flags().Changed("output") {
+		return fmt.Errorf("--output requires --label; for JSON output from one browser, use 'kernel browsers process exec'")
+	}
+	in := BrowsersExecInput{
+		Identifier: args[0],
+		Argv:       args[1:],
+		Cwd:        cwd,
+		Timeout:    timeout,
+		AsUser:     asUser,
+		AsRoot:     asRoot,
+		Env:        env,
+	}
+	if useStdin {
+		in.Stdin = cmd.InOrStdin()
+	}
+	return b.Exec(cmd.Context(), in)
+}
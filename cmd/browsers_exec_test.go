@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/kernel/kernel-go-sdk/packages/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func execTestBrowsers() *FakeBrowsersService {
+	return &FakeBrowsersService{GetFunc: func(ctx context.Context, id string, query kernel.BrowserGetParams, opts ...option.RequestOption) (*kernel.BrowserGetResponse, error) {
+		return &kernel.BrowserGetResponse{SessionID: "sess-1"}, nil
+	}}
+}
+
+func TestBrowsersExec_PassesOutputAndExitCode(t *testing.T) {
+	var got kernel.BrowserProcessExecParams
+	proc := &FakeProcessService{ExecFunc: func(ctx context.Context, id string, body kernel.BrowserProcessExecParams, opts ...option.RequestOption) (*kernel.BrowserProcessExecResponse, error) {
+		got = body
+		return &kernel.BrowserProcessExecResponse{
+			ExitCode:  3,
+			StdoutB64: base64.StdEncoding.EncodeToString([]byte("out\n")),
+			StderrB64: base64.StdEncoding.EncodeToString([]byte("err\n")),
+		}, nil
+	}}
+	var stdout, stderr bytes.Buffer
+	b := BrowsersCmd{browsers: execTestBrowsers(), process: proc}
+	err := b.Exec(context.Background(), BrowsersExecInput{Identifier: "sess-1", Argv: []string{"ls", "-la", "/tmp"}, AsRoot: true, Stdout: &stdout, Stderr: &stderr})
+
+	var coded exitCodeError
+	require.True(t, errors.As(err, &coded))
+	assert.Equal(t, 3, coded.ExitCode())
+	assert.Equal(t, "ls", got.Command)
+	assert.Equal(t, []string{"-la", "/tmp"}, got.Args)
+	assert.True(t, got.AsRoot.Value)
+	assert.Equal(t, "out\n", stdout.String())
+	assert.Equal(t, "err\n", stderr.String())
+}
+
+func TestBrowsersExec_Stdin(t *testing.T) {
+	var uploadedPath, uploaded string
+	fs := &FakeFSService{WriteFileFunc: func(ctx context.Context, id string, contents io.Reader, body kernel.BrowserFWriteFileParams, opts ...option.RequestOption) error {
+		data, _ := io.ReadAll(contents)
+		uploadedPath, uploaded = body.Path, string(data)
+		return nil
+	}}
+	var got kernel.BrowserProcessExecParams
+	proc := &FakeProcessService{ExecFunc: func(ctx context.Context, id string, body kernel.BrowserProcessExecParams, opts ...option.RequestOption) (*kernel.BrowserProcessExecResponse, error) {
+		got = body
+		return &kernel.BrowserProcessExecResponse{}, nil
+	}}
+	b := BrowsersCmd{browsers: execTestBrowsers(), process: proc, fs: fs}
+	err := b.Exec(context.Background(), BrowsersExecInput{Identifier: "sess-1", Argv: []string{"jq", ".items"}, Stdin: strings.NewReader(`{"items":[]}`), Stdout: io.Discard, Stderr: io.Discard})
+
+	require.NoError(t, err)
+	assert.Equal(t, `{"items":[]}`, uploaded)
+	assert.Equal(t, "/bin/bash", got.Command)
+	assert.Equal(t, []string{"-c", execStdinScript, uploadedPath, "jq", ".items"}, got.Args)
+}
+
+func TestBrowsersExecByTags_RunsInEveryMatchingBrowser(t *testing.T) {
+	var listed kernel.BrowserListParams
+	browsers := &FakeBrowsersService{ListFunc: func(ctx context.Context, query kernel.BrowserListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.BrowserListResponse], error) {
+		listed = query
+		return &pagination.OffsetPagination[kernel.BrowserListResponse]{Items: []kernel.BrowserListResponse{
+			{SessionID: "sess-1", Name: "worker-1"},
+			{SessionID: "sess-2"},
+			{SessionID: "sess-3"},
+		}}, nil
+	}}
+	var mu sync.Mutex
+	ran := map[string]kernel.BrowserProcessExecParams{}
+	proc := &FakeProcessService{ExecFunc: func(ctx context.Context, id string, body kernel.BrowserProcessExecParams, opts ...option.RequestOption) (*kernel.BrowserProcessExecResponse, error) {
+		mu.Lock()
+		ran[id] = body
+		mu.Unlock()
+		switch id {
+		case "sess-2":
+			return &kernel.BrowserProcessExecResponse{ExitCode: 1, StderrB64: base64.StdEncoding.EncodeToString([]byte("disk full\n"))}, nil
+		case "sess-3":
+			return nil, errors.New("session unreachable")
+		}
+		return &kernel.BrowserProcessExecResponse{StdoutB64: base64.StdEncoding.EncodeToString([]byte("ok"))}, nil
+	}}
+	b := BrowsersCmd{browsers: browsers, process: proc}
+
+	var stdout bytes.Buffer
+	capturePtermOutput(t)
+	err := b.ExecByTags(context.Background(), BrowsersExecByTagsInput{
+		BrowsersExecInput: BrowsersExecInput{Argv: []string{"df", "-h"}, AsRoot: true, Stdout: &stdout},
+		Tags:              map[string]string{"batch": "run42"},
+		Concurrency:       2,
+	})
+
+	assert.EqualError(t, err, "command failed on 2 of 3 browser(s)")
+	assert.Equal(t, map[string]string{"batch": "run42"}, listed.Tags)
+	assert.Equal(t, kernel.BrowserListParamsStatusActive, listed.Status)
+	require.Len(t, ran, 3)
+	assert.Equal(t, "df", ran["sess-1"].Command)
+	assert.True(t, ran["sess-3"].AsRoot.Value)
+	assert.Contains(t, stdout.String(), "==> sess-1 (worker-1): exit 0\nok\n")
+	assert.Contains(t, stdout.String(), "==> sess-2: exit 1\ndisk full\n")
+	assert.Contains(t, stdout.String(), "==> sess-3: error: session unreachable\n")
+}
+
+func TestBrowsersExecByTags_JSON(t *testing.T) {
+	browsers := &FakeBrowsersService{ListFunc: func(ctx context.Context, query kernel.BrowserListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.BrowserListResponse], error) {
+		return &pagination.OffsetPagination[kernel.BrowserListResponse]{Items: []kernel.BrowserListResponse{{SessionID: "sess-1"}}}, nil
+	}}
+	proc := &FakeProcessService{ExecFunc: func(ctx context.Context, id string, body kernel.BrowserProcessExecParams, opts ...option.RequestOption) (*kernel.BrowserProcessExecResponse, error) {
+		return &kernel.BrowserProcessExecResponse{StdoutB64: base64.StdEncoding.EncodeToString([]byte("hi\n"))}, nil
+	}}
+	b := BrowsersCmd{browsers: browsers, process: proc}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = b.ExecByTags(context.Background(), BrowsersExecByTagsInput{
+			BrowsersExecInput: BrowsersExecInput{Argv: []string{"echo", "hi"}},
+			Tags:              map[string]string{"batch": "run42"},
+			Output:            "json",
+		})
+	})
+	require.NoError(t, err)
+	var results []browsersExecResult
+	require.NoError(t, json.Unmarshal([]byte(out), &results))
+	require.Len(t, results, 1)
+	assert.Equal(t, "sess-1", results[0].SessionID)
+	assert.Equal(t, "hi\n", results[0].Stdout)
+}
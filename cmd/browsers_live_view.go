@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+// newLiveViewProxy returns a handler that forwards every request, including
+// WebSocket upgrades, to the live view's origin. Only scheme and host of
+// target are used; the local URL keeps the hosted path and query.
+func newLiveViewProxy(target *url.URL) http.Handler {
+	origin := &url.URL{Scheme: target.Scheme, Host: target.Host}
+	return &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(origin)
+			r.Out.Host = origin.Host
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			pterm.Debug.Printf("live view proxy: %s %s: %v\n", r.Method, r.URL.Path, err)
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+}
+
+// serveLiveView proxies the live view on 127.0.0.1:port until ctx is done or
+// the process is interrupted, optionally opening or copying the local URL.
+func (b BrowsersCmd) serveLiveView(ctx context.Context, liveViewURL string, port int, open, copyURL bool) error {
+	target, err := url.Parse(liveViewURL)
+	if err != nil {
+		return fmt.Errorf("invalid live view URL: %w", err)
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		return fmt.Errorf("listen on port %d: %w", port, err)
+	}
+	local := url.URL{Scheme: "http", Host: ln.Addr().String(), Path: target.Path, RawQuery: target.RawQuery}
+
+	srv := &http.Server{Handler: newLiveViewProxy(target), ReadHeaderTimeout: 10 * time.Second}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+
+	pterm.Info.Printf("Proxying live view on %s (Ctrl+C to stop)\n", ln.Addr())
+	fmt.Println(local.String())
+	if copyURL {
+		copyOutput("live view URL", local.String())
+	}
+	if open {
+		b.openLiveView(local.String())
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	select {
+	case <-ctx.Done():
+	case err := <-errc:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Second)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
+
+func (b BrowsersCmd) openLiveView(u string) {
+	if b.openURL == nil {
+		return
+	}
+	if err := b.openURL(u); err != nil {
+		pterm.Warning.Printf("Could not open a browser: %v\n", err)
+	}
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiveViewProxy_ForwardsPathQueryAndHost(t *testing.T) {
+	var gotPath, gotQuery, gotHost string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery, gotHost = r.URL.Path, r.URL.RawQuery, r.Host
+		_, _ = io.WriteString(w, "live")
+	}))
+	defer upstream.Close()
+	target, err := url.Parse(upstream.URL + "/browser/live?jwt=abc")
+	require.NoError(t, err)
+
+	proxy := httptest.NewServer(newLiveViewProxy(target))
+	defer proxy.Close()
+
+	resp, err := http.Get(proxy.URL + "/browser/live?jwt=abc")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "live", string(body))
+	assert.Equal(t, "/browser/live", gotPath, "the path is not joined twice")
+	assert.Equal(t, "jwt=abc", gotQuery)
+	assert.Equal(t, target.Host, gotHost)
+}
+
+func TestBrowsersView_OpenUsesOpener(t *testing.T) {
+	setupStdoutCapture(t)
+	var opened string
+	fake := &FakeBrowsersService{GetFunc: func(ctx context.Context, id string, query kernel.BrowserGetParams, opts ...option.RequestOption) (*kernel.BrowserGetResponse, error) {
+		return &kernel.BrowserGetResponse{SessionID: id, BrowserLiveViewURL: "https://live.example/view"}, nil
+	}}
+	b := BrowsersCmd{browsers: fake, openURL: func(u string) error { opened = u; return nil }}
+
+	err := b.View(context.Background(), BrowsersViewInput{Identifier: "id", Open: true})
+	require.NoError(t, err)
+	assert.Equal(t, "https://live.example/view", opened)
+}
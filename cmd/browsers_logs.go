@@ -0,0 +1,353 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	kernel "github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// browserLogLevels are the levels --filter level= accepts, lowest first.
+var browserLogLevels = []string{"debug", "info", "warning", "error"}
+
+type BrowsersLogsInput struct {
+	Identifier string
+	// Network adds a summary line per network response or failed request.
+	Network bool
+	Follow  bool
+	// Since bounds the history read when not following (timestamp or duration).
+	Since   string
+	Filters map[string]string
+	Output  string
+}
+
+// browserLogLine is the summary logs prints for a console or network event.
+// It is also the JSONL record, so field names are part of the output format.
+type browserLogLine struct {
+	Time    time.Time `json:"time"`
+	Seq     int64     `json:"seq"`
+	Type    string    `json:"type"`
+	Level   string    `json:"level"`
+	Message string    `json:"message,omitempty"`
+	Method  string    `json:"method,omitempty"`
+	Status  int64     `json:"status,omitempty"`
+	URL     string    `json:"url,omitempty"`
+	// Source is the script location of a console message, as url:line:column.
+	Source string `json:"source,omitempty"`
+}
+
+// browserLogLineFromEvent summarizes a telemetry event. It returns false for
+// event types logs doesn't show.
+func browserLogLineFromEvent(seq int64, ev kernel.BrowserTelemetryEventUnion) (browserLogLine, bool) {
+	line := browserLogLine{Time: time.UnixMicro(ev.Ts), Seq: seq, Type: ev.Type}
+	switch ev.Type {
+	case "console_log":
+		data := ev.AsConsoleLog().Data
+		line.Level = normalizeBrowserLogLevel(data.Level)
+		line.Message = data.Text
+		line.URL = data.URL
+		line.Source = consoleStackSource(data.StackTrace)
+	case "console_error":
+		data := ev.AsConsoleError().Data
+		line.Level = "error"
+		line.Message = data.Text
+		line.URL = data.URL
+		if data.SourceURL != "" {
+			line.URL = data.SourceURL
+			if data.Line > 0 {
+				line.URL = fmt.Sprintf("%s:%d", data.SourceURL, data.Line)
+			}
+			line.Source = formatScriptLocation(data.SourceURL, data.Line, data.Column)
+		} else {
+			line.Source = consoleStackSource(data.StackTrace)
+		}
+	case "network_response":
+		data := ev.AsNetworkResponse().Data
+		line.Level = "info"
+		if data.Status >= 400 {
+			line.Level = "error"
+		}
+		line.Method = data.Method
+		line.Status = data.Status
+		line.URL = data.URL
+	case "network_loading_failed":
+		data := ev.AsNetworkLoadingFailed().Data
+		line.Level = "error"
+		line.Message = data.ErrorText
+		if data.Canceled {
+			line.Level = "warning"
+			line.Message = "canceled"
+		}
+		line.URL = data.URL
+	default:
+		return line, false
+	}
+	return line, true
+}
+
+// consoleStackSource returns the location of the console call: the first
+// frame with a script URL, as CDP lists the call site first. Frame positions
+// are zero-based and shown one-based, as in DevTools.
+func consoleStackSource(stack kernel.BrowserCallStack) string {
+	for _, f := range stack.CallFrames {
+		if f.URL != "" {
+			return formatScriptLocation(f.URL, f.LineNumber+1, f.ColumnNumber+1)
+		}
+	}
+	return ""
+}
+
+func formatScriptLocation(url string, line, column int64) string {
+	switch {
+	case line <= 0:
+		return url
+	case column <= 0:
+		return fmt.Sprintf("%s:%d", url, line)
+	}
+	return fmt.Sprintf("%s:%d:%d", url, line, column)
+}
+
+// normalizeBrowserLogLevel maps console API levels (log, warn, ...) onto
+// browserLogLevels.
+func normalizeBrowserLogLevel(level string) string {
+	switch strings.ToLower(level) {
+	case "error", "assert":
+		return "error"
+	case "warn", "warning":
+		return "warning"
+	case "debug", "verbose", "trace":
+		return "debug"
+	default:
+		return "info"
+	}
+}
+
+// browserLogFilter holds the parsed --filter values. The level filter is a
+// minimum, so level=warning also shows errors.
+type browserLogFilter struct {
+	minLevel int
+	types    []string
+	url      string
+}
+
+func parseBrowserLogFilters(filters map[string]string) (browserLogFilter, error) {
+	var f browserLogFilter
+	for key, val := range filters {
+		switch key {
+		case "level":
+			idx := slices.Index(browserLogLevels, strings.ToLower(val))
+			if idx < 0 {
+				return f, fmt.Errorf("invalid --filter level=%s: must be one of %s", val, strings.Join(browserLogLevels, ", "))
+			}
+			f.minLevel = idx
+		case "type":
+			f.types = strings.Split(val, ",")
+		case "url":
+			f.url = val
+		default:
+			return f, fmt.Errorf("unknown --filter key %q: use level, type or url", key)
+		}
+	}
+	return f, nil
+}
+
+func (f browserLogFilter) match(line browserLogLine) bool {
+	if slices.Index(browserLogLevels, line.Level) < f.minLevel {
+		return false
+	}
+	if len(f.types) > 0 && !slices.Contains(f.types, line.Type) {
+		return false
+	}
+	return f.url == "" || strings.Contains(line.URL, f.url)
+}
+
+// Logs prints a session's console messages and, with Network, a summary of
+// its network responses. It reads recent history from the telemetry archive,
+// or with Follow streams new events until interrupted. Telemetry must capture
+// the console (and network) categories for anything to show up.
+func (b BrowsersCmd) Logs(ctx context.Context, in BrowsersLogsInput) error {
+	if b.telemetry == nil {
+		return fmt.Errorf("telemetry service not available")
+	}
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	filter, err := parseBrowserLogFilters(in.Filters)
+	if err != nil {
+		return err
+	}
+	categories := []string{"console"}
+	if in.Network {
+		categories = append(categories, "network")
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	if missing := missingTelemetryCategories(br.Telemetry, categories); len(missing) > 0 && in.Output != "json" {
+		pterm.Warning.Printf("Telemetry isn't capturing %s for this session; enable it with: kernel browsers update %s --telemetry=%s\n",
+			strings.Join(missing, " or "), in.Identifier, strings.Join(append(telemetryEnabledCategories(br.Telemetry), missing...), ","))
+	}
+
+	emit := func(seq int64, ev kernel.BrowserTelemetryEventUnion) error {
+		if !slices.Contains(categories, ev.Category) {
+			return nil
+		}
+		line, ok := browserLogLineFromEvent(seq, ev)
+		if !ok || !filter.match(line) {
+			return nil
+		}
+		if in.Output == "json" {
+			return util.PrintJSONLine(line)
+		}
+		printBrowserLogLine(line)
+		return nil
+	}
+
+	if !in.Follow {
+		params := kernel.BrowserTelemetryEventsParams{Limit: kernel.Opt(int64(100))}
+		if in.Since != "" {
+			params.Since = kernel.Opt(in.Since)
+		}
+		// Categories go as repeated query params; see TelemetryEvents.
+		opts := make([]option.RequestOption, 0, len(categories))
+		for _, c := range categories {
+			opts = append(opts, option.WithQueryAdd("category", c))
+		}
+		pager := b.telemetry.EventsAutoPaging(ctx, br.SessionID, params, opts...)
+		for pager.Next() {
+			it := pager.Current()
+			if err := emit(it.Seq, it.Event); err != nil {
+				return err
+			}
+		}
+		if err := pager.Err(); err != nil {
+			return util.CleanedUpSdkError{Err: err}
+		}
+		return nil
+	}
+
+	stream := b.telemetry.StreamStreaming(ctx, br.SessionID, kernel.BrowserTelemetryStreamParams{})
+	defer stream.Close()
+	for stream.Next() {
+		ev := stream.Current()
+		if err := emit(ev.Seq, ev.Event); err != nil {
+			return err
+		}
+	}
+	if err := stream.Err(); err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled) {
+			return nil
+		}
+		return util.CleanedUpSdkError{Err: err}
+	}
+	return nil
+}
+
+// missingTelemetryCategories returns the wanted categories cfg doesn't capture.
+func missingTelemetryCategories(cfg kernel.BrowserTelemetryConfig, wanted []string) []string {
+	on := telemetryEnabledCategories(cfg)
+	var missing []string
+	for _, c := range wanted {
+		if !slices.Contains(on, c) {
+			missing = append(missing, c)
+		}
+	}
+	return missing
+}
+
+func printBrowserLogLine(line browserLogLine) {
+	ts := line.Time.Local().Format("15:04:05.000")
+	level := strings.ToUpper(line.Level)
+	switch line.Level {
+	case "error":
+		level = pterm.Red(level)
+	case "warning":
+		level = pterm.Yellow(level)
+	}
+	var msg string
+	switch line.Type {
+	case "network_response":
+		msg = fmt.Sprintf("%s %d %s", line.Method, line.Status, line.URL)
+	case "network_loading_failed":
+		msg = fmt.Sprintf("%s (%s)", line.URL, line.Message)
+	default:
+		msg = line.Message
+		if line.Source != "" {
+			msg += "  " + pterm.Gray(line.Source)
+		} else if line.Level == "error" && line.URL != "" {
+			msg += "  " + pterm.Gray(line.URL)
+		}
+	}
+	pterm.Printf("%s %-7s %s\n", ts, level, msg)
+}
+
+var browsersLogsCmd = &cobra.Command{
+	Use:   "logs <id-or-name>",
+	Short: "Show a browser's console and network logs",
+	Long: `Show console messages from a browser session, and with --network a line per
+network response or failed request. Without --follow, events from the last
+--since are printed; with --follow, new events stream until interrupted.
+
+Events come from telemetry, so the session must capture the console (and
+network) categories: kernel browsers update <id> --telemetry=console,network
+
+Filters (repeatable --filter key=value):
+  level=<debug|info|warning|error>  minimum level; HTTP 4xx/5xx and failed requests are errors
+  type=<event type,...>             e.g. console_error,network_loading_failed
+  url=<substring>                   request or source URL contains this
+
+For raw VM log files, use 'kernel browsers logs stream'.`,
+	Example: `  kernel browsers logs abc123
+  kernel browsers logs abc123 --follow --network --filter level=error
+  kernel browsers logs abc123 --since 1h -o json > console.jsonl`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBrowsersLogs,
+}
+
+func init() {
+	browsersLogsCmd.ValidArgsFunction = completeResourceArg("browser", completeBrowser)
+	browsersLogsCmd.Flags().BoolP("follow", "f", false, "Stream new events until interrupted")
+	browsersLogsCmd.Flags().Bool("network", false, "Include network responses and failed requests")
+	browsersLogsCmd.Flags().String("since", "15m", "Without --follow, show events since this time (RFC-3339 timestamp or duration)")
+	browsersLogsCmd.Flags().StringArray("filter", nil, "Filter as key=value: level, type or url (repeatable)")
+	browsersLogsCmd.Flags().StringP("output", "o", "", "Output format: json for newline-delimited JSON")
+}
+
+func runBrowsersLogs(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	follow, _ := cmd.Flags().GetBool("follow")
+	network, _ := cmd.Flags().GetBool("network")
+	since, _ := cmd.Flags().GetString("since")
+	filterSpecs, _ := cmd.Flags().GetStringArray("filter")
+	output, _ := cmd.Flags().GetString("output")
+
+	filters, malformed := parseKeyValueSpecs(filterSpecs)
+	if len(malformed) > 0 {
+		return fmt.Errorf("invalid --filter %q: expected key=value", malformed[0])
+	}
+	svc := client.Browsers
+	b := BrowsersCmd{browsers: &svc, telemetry: &svc.Telemetry}
+	return b.Logs(cmd.Context(), BrowsersLogsInput{
+		Identifier: args[0],
+		Network:    network,
+		Follow:     follow,
+		Since:      since,
+		Filters:    filters,
+		Output:     output,
+	})
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	kernel "github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/kernel/kernel-go-sdk/packages/pagination"
+	"github.com/kernel/kernel-go-sdk/packages/ssestream"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var browserLogsTestEvents = [][]byte{
+	[]byte(`{"seq":1,"event":{"category":"console","type":"console_log","ts":1700000000000000,"data":{"level":"log","text":"page ready"}}}`),
+	[]byte(`{"seq":2,"event":{"category":"console","type":"console_error","ts":1700000001000000,"data":{"text":"TypeError: x is undefined","source_url":"https://app.example/main.js","line":42}}}`),
+	[]byte(`{"seq":3,"event":{"category":"network","type":"network_response","ts":1700000002000000,"data":{"method":"GET","status":503,"url":"https://api.example/items"}}}`),
+	[]byte(`{"seq":5,"event":{"category":"console","type":"console_log","ts":1700000004000000,"data":{"level":"warning","text":"deprecated API","stack_trace":{"callFrames":[{"functionName":"init","url":"https://app.example/login.js","lineNumber":9,"columnNumber":4,"scriptId":"7"}]}}}}`),
+	[]byte(`{"seq":4,"event":{"category":"network","type":"network_response","ts":1700000003000000,"data":{"method":"GET","status":200,"url":"https://api.example/ok"}}}`),
+}
+
+func browserLogsTestCmd() BrowsersCmd {
+	telemetry := &FakeBrowserTelemetryService{StreamFunc: func() *ssestream.Stream[kernel.BrowserTelemetryStreamResponse] {
+		return ssestream.NewStream[kernel.BrowserTelemetryStreamResponse](&testDecoder{data: browserLogsTestEvents}, nil)
+	}}
+	return BrowsersCmd{browsers: execTestBrowsers(), telemetry: telemetry}
+}
+
+func TestBrowsersLogs_FollowConsoleOnly(t *testing.T) {
+	setupStdoutCapture(t)
+	b := browserLogsTestCmd()
+
+	err := b.Logs(context.Background(), BrowsersLogsInput{Identifier: "sess-1", Follow: true})
+	require.NoError(t, err)
+	out := outBuf.String()
+	assert.Contains(t, out, "page ready")
+	assert.Contains(t, out, "TypeError: x is undefined")
+	assert.Contains(t, out, "main.js:42")
+	assert.NotContains(t, out, "api.example", "network events need --network")
+	assert.Contains(t, out, "--telemetry=console", "warns when console telemetry is off")
+}
+
+func TestBrowsersLogs_FilterLevelErrorJSON(t *testing.T) {
+	b := browserLogsTestCmd()
+
+	var err error
+	out := captureStdout(t, func() {
+		err = b.Logs(context.Background(), BrowsersLogsInput{
+			Identifier: "sess-1",
+			Follow:     true,
+			Network:    true,
+			Filters:    map[string]string{"level": "error"},
+			Output:     "json",
+		})
+	})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 2)
+	var first, second browserLogLine
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, "console_error", first.Type)
+	assert.Equal(t, int64(2), first.Seq)
+	assert.Equal(t, "network_response", second.Type)
+	assert.Equal(t, int64(503), second.Status)
+	assert.Equal(t, "https://api.example/items", second.URL)
+}
+
+func TestBrowsersLogs_HistoryRequestsCategories(t *testing.T) {
+	setupStdoutCapture(t)
+	var gotQuery kernel.BrowserTelemetryEventsParams
+	var gotOpts int
+	telemetry := &FakeBrowserTelemetryService{EventsAutoPagingFunc: func(id string, query kernel.BrowserTelemetryEventsParams, opts ...option.RequestOption) *pagination.OffsetPaginationAutoPager[kernel.BrowserTelemetryEventsResponse] {
+		gotQuery, gotOpts = query, len(opts)
+		return pagination.NewOffsetPaginationAutoPager(&pagination.OffsetPagination[kernel.BrowserTelemetryEventsResponse]{}, nil)
+	}}
+	b := BrowsersCmd{browsers: execTestBrowsers(), telemetry: telemetry}
+
+	err := b.Logs(context.Background(), BrowsersLogsInput{Identifier: "sess-1", Network: true, Since: "1h"})
+	require.NoError(t, err)
+	assert.Equal(t, "1h", gotQuery.Since.Value)
+	assert.Equal(t, 2, gotOpts, "console and network are sent as repeated category params")
+}
+
+func TestBrowsersLogs_InvalidFilter(t *testing.T) {
+	b := browserLogsTestCmd()
+
+	err := b.Logs(context.Background(), BrowsersLogsInput{Identifier: "sess-1", Filters: map[string]string{"level": "loud"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --filter level=loud")
+
+	err = b.Logs(context.Background(), BrowsersLogsInput{Identifier: "sess-1", Filters: map[string]string{"host": "x"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown --filter key "host"`)
+}
+
+func TestBrowsersLogs_ConsoleSourceLocation(t *testing.T) {
+	pterm.DisableColor()
+	t.Cleanup(pterm.EnableColor)
+	setupStdoutCapture(t)
+	b := browserLogsTestCmd()
+
+	err := b.Logs(context.Background(), BrowsersLogsInput{Identifier: "sess-1", Follow: true, Filters: map[string]string{"level": "warning"}})
+	require.NoError(t, err)
+	out := outBuf.String()
+	assert.NotContains(t, out, "page ready")
+	assert.Contains(t, out, "deprecated API  https://app.example/login.js:10:5")
+	assert.Contains(t, out, "https://app.example/main.js:42")
+}
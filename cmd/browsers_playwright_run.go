@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/spf13/cobra"
+)
+
+const defaultPlaywrightRunTimeout = 120
+
+type BrowsersPlaywrightRunInput struct {
+	Identifier string
+	// Script is the local file to run, or "-" for stdin.
+	Script string
+	// Args are exposed to the script as the `args` object.
+	Args    map[string]string
+	Timeout int64
+	// Retries re-runs the script when it fails because the page navigated.
+	Retries int
+	Output  string
+}
+
+// PlaywrightRun runs a local script file in the browser's Playwright
+// environment. The script's stdout and stderr are passed through, its return
+// value is printed as JSON, and a failed script is an error.
+func (b BrowsersCmd) PlaywrightRun(ctx context.Context, in BrowsersPlaywrightRunInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if b.playwright == nil {
+		return fmt.Errorf("playwright service not available")
+	}
+	code, err := readPlaywrightScript(in.Script)
+	if err != nil {
+		return err
+	}
+	code, err = withPlaywrightArgs(code, in.Args)
+	if err != nil {
+		return err
+	}
+
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	params := kernel.BrowserPlaywrightExecuteParams{Code: code}
+	if in.Timeout > 0 {
+		params.TimeoutSec = kernel.Opt(in.Timeout)
+	}
+	res, err := executePlaywright(ctx, b.playwright, br.SessionID, params, in.Retries)
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+
+	if in.Output == "json" {
+		if err := util.PrintPrettyJSON(res); err != nil {
+			return err
+		}
+	} else {
+		util.WarnDecodeDrift(res)
+		if res.Stdout != "" {
+			fmt.Fprint(os.Stdout, ensureTrailingNewline(res.Stdout))
+		}
+		if res.Stderr != "" {
+			fmt.Fprint(os.Stderr, ensureTrailingNewline(res.Stderr))
+		}
+		if res.Result != nil {
+			if err := util.PrintJSON(res.Result); err != nil {
+				return err
+			}
+		}
+	}
+	if !res.Success {
+		return fmt.Errorf("script failed: %s", util.FirstOrDash(res.Error, "no error message"))
+	}
+	return nil
+}
+
+func readPlaywrightScript(path string) (string, error) {
+	if path == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("read script from stdin: %w", err)
+		}
+		return string(data), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read script: %w", err)
+	}
+	return string(data), nil
+}
+
+// withPlaywrightArgs prepends a declaration of the `args` object so the
+// script can read --arg values.
+func withPlaywrightArgs(code string, args map[string]string) (string, error) {
+	if args == nil {
+		args = map[string]string{}
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	return "const args = " + string(data) + ";\n" + code, nil
+}
+
+func ensureTrailingNewline(s string) string {
+	if s != "" && s[len(s)-1] != '\n' {
+		return s + "\n"
+	}
+	return s
+}
+
+var browsersPlaywrightRunCmd = &cobra.Command{
+	Use:   "run <id-or-name> <script|->",
+	Short: "Run a local Playwright script file against the browser",
+	Long: `Run a JavaScript/TypeScript file against the browser, the same way
+'playwright execute' runs inline code. The script runs as the body of an async
+function with page, context and browser in scope; whatever it returns is
+printed as JSON.
+
+--arg key=value pairs are available to the script as the args object
+(values are strings). The script's stdout and stderr are printed once it
+finishes, and a script that throws exits non-zero.`,
+	Example: `  kernel browsers playwright run abc123 scrape.js --arg url=https://example.com
+  cat check.ts | kernel browsers playwright run abc123 - --timeout 300 -o json`,
+	Args: cobra.ExactArgs(2),
+	RunE: runBrowsersPlaywrightRun,
+}
+
+func init() {
+	completeID := completeResourceArg("browser", completeBrowser)
+	browsersPlaywrightRunCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) == 1 {
+			// The script is a local file.
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return completeID(cmd, args, toComplete)
+	}
+	browsersPlaywrightRunCmd.Flags().Int64("timeout", defaultPlaywrightRunTimeout, "Maximum execution time in seconds")
+	browsersPlaywrightRunCmd.Flags().StringArray("arg", nil, "Argument as key=value, readable as args.key in the script (repeatable)")
+	browsersPlaywrightRunCmd.Flags().Int("retries", 0, playwrightRetriesFlagUsage)
+	addJSONOutputFlag(browsersPlaywrightRunCmd)
+}
+
+func runBrowsersPlaywrightRun(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	timeout, _ := cmd.Flags().GetInt64("timeout")
+	argSpecs, _ := cmd.Flags().GetStringArray("arg")
+	retries, _ := cmd.Flags().GetInt("retries")
+	output, _ := cmd.Flags().GetString("output")
+
+	scriptArgs, malformed := parseKeyValueSpecs(argSpecs)
+	if len(malformed) > 0 {
+		return fmt.Errorf("invalid --arg %q: expected key=value", malformed[0])
+	}
+	svc := client.Browsers
+	b := BrowsersCmd{browsers: &svc, playwright: &svc.Playwright}
+	return b.PlaywrightRun(cmd.Context(), BrowsersPlaywrightRunInput{
+		Identifier: args[0],
+		Script:     args[1],
+		Args:       scriptArgs,
+		Timeout:    timeout,
+		Retries:    retries,
+		Output:     output,
+	})
+}
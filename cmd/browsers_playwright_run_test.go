@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrowsersPlaywrightRun_SendsScriptWithArgs(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "title.js")
+	require.NoError(t, os.WriteFile(script, []byte("await page.goto(args.url);\nreturn { title: await page.title() };\n"), 0o644))
+	var gotCode string
+	var gotTimeout int64
+	pw := &fakePlaywright{executeFunc: func(id string, body kernel.BrowserPlaywrightExecuteParams) (*kernel.BrowserPlaywrightExecuteResponse, error) {
+		gotCode, gotTimeout = body.Code, body.TimeoutSec.Value
+		return &kernel.BrowserPlaywrightExecuteResponse{Success: true, Stdout: "navigated", Result: map[string]any{"title": "Example"}}, nil
+	}}
+	b := BrowsersCmd{browsers: execTestBrowsers(), playwright: pw}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = b.PlaywrightRun(context.Background(), BrowsersPlaywrightRunInput{
+			Identifier: "sess-1",
+			Script:     script,
+			Args:       map[string]string{"url": "https://example.com"},
+			Timeout:    120,
+		})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "const args = {\"url\":\"https://example.com\"};\nawait page.goto(args.url);\nreturn { title: await page.title() };\n", gotCode)
+	assert.Equal(t, int64(120), gotTimeout)
+	assert.Contains(t, out, "navigated\n")
+	assert.Contains(t, out, `"title": "Example"`)
+}
+
+func TestBrowsersPlaywrightRun_FailedScriptIsError(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "boom.js")
+	require.NoError(t, os.WriteFile(script, []byte("throw new Error('boom');"), 0o644))
+	pw := &fakePlaywright{executeFunc: func(id string, body kernel.BrowserPlaywrightExecuteParams) (*kernel.BrowserPlaywrightExecuteResponse, error) {
+		return &kernel.BrowserPlaywrightExecuteResponse{Success: false, Error: "Error: boom"}, nil
+	}}
+	b := BrowsersCmd{browsers: execTestBrowsers(), playwright: pw}
+
+	var err error
+	captureStdout(t, func() {
+		err = b.PlaywrightRun(context.Background(), BrowsersPlaywrightRunInput{Identifier: "sess-1", Script: script})
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "script failed: Error: boom")
+}
+
+func TestBrowsersPlaywrightRun_MissingScript(t *testing.T) {
+	pw := &fakePlaywright{}
+	b := BrowsersCmd{browsers: execTestBrowsers(), playwright: pw}
+
+	err := b.PlaywrightRun(context.Background(), BrowsersPlaywrightRunInput{Identifier: "sess-1", Script: filepath.Join(t.TempDir(), "nope.js")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "read script")
+	assert.Empty(t, pw.Calls())
+}
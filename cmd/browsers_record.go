@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+type BrowsersRecordStopInput struct {
+	Identifier string
+	// Output, if set, is where the stopped recording is saved.
+	Output string
+}
+
+// RecordStop stops the browser's active recordings without needing their
+// replay IDs, and optionally downloads the latest one.
+func (b BrowsersCmd) RecordStop(ctx context.Context, in BrowsersRecordStopInput) error {
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	items, err := b.replays.List(ctx, br.SessionID)
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	var active []kernel.BrowserReplayListResponse
+	if items != nil {
+		for _, r := range *items {
+			if r.FinishedAt.IsZero() {
+				active = append(active, r)
+			}
+		}
+	}
+	if len(active) == 0 {
+		return fmt.Errorf("browser %s has no active recording", br.SessionID)
+	}
+
+	latest := active[0]
+	for _, r := range active {
+		if err := b.replays.Stop(ctx, r.ReplayID, kernel.BrowserReplayStopParams{ID: br.SessionID}); err != nil {
+			return util.CleanedUpSdkError{Err: err}
+		}
+		pterm.Success.Printf("Stopped recording %s for browser %s\n", r.ReplayID, br.SessionID)
+		if r.StartedAt.After(latest.StartedAt) {
+			latest = r
+		}
+	}
+	if in.Output == "" {
+		return nil
+	}
+	return b.ReplaysDownload(ctx, BrowsersReplaysDownloadInput{Identifier: br.SessionID, ReplayID: latest.ReplayID, Output: in.Output})
+}
+
+var browsersRecordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Record a browser session",
+	Long: `Start and stop session recordings without tracking replay IDs. Recordings
+are listed and downloaded with "kernel browsers replays".`,
+	Example: `  kernel browsers record start abc123
+  # ... run the test ...
+  kernel browsers record stop abc123 -f failed-run.mp4`,
+}
+
+var browsersRecordStartCmd = &cobra.Command{
+	Use:   "start <id-or-name>",
+	Short: "Start recording a browser session",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runBrowsersReplaysStart,
+}
+
+var browsersRecordStopCmd = &cobra.Command{
+	Use:   "stop <id-or-name>",
+	Short: "Stop a browser's active recording and optionally download it",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runBrowsersRecordStop,
+}
+
+func init() {
+	browsersRecordStartCmd.Flags().Int("framerate", 0, "Recording framerate (fps)")
+	browsersRecordStartCmd.Flags().Int("max-duration", 0, "Maximum duration in seconds")
+	browsersRecordStartCmd.Flags().Bool("audio", false, "Record audio as well as video")
+	addJSONOutputFlag(browsersRecordStartCmd)
+	browsersRecordStopCmd.Flags().StringP("output-file", "f", "", "Save the recording to this path once stopped")
+	for _, c := range []*cobra.Command{browsersRecordStartCmd, browsersRecordStopCmd} {
+		c.ValidArgsFunction = completeResourceArg("browser", completeBrowser)
+		browsersRecordCmd.AddCommand(c)
+	}
+	browsersCmd.AddCommand(browsersRecordCmd)
+}
+
+func runBrowsersRecordStop(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	svc := client.Browsers
+	out, _ := cmd.Flags().GetString("output-file")
+	b := BrowsersCmd{browsers: &svc, replays: &svc.Replays}
+	return b.RecordStop(cmd.Context(), BrowsersRecordStopInput{Identifier: args[0], Output: out})
+}
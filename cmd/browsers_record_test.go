@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrowsersRecordStop_StopsActiveAndDownloads(t *testing.T) {
+	setupStdoutCapture(t)
+	started := time.Unix(100, 0)
+	replays := []kernel.BrowserReplayListResponse{
+		{ReplayID: "done", StartedAt: started.Add(-time.Hour), FinishedAt: started},
+		{ReplayID: "active", StartedAt: started},
+	}
+	var stopped, downloaded []string
+	fake := &FakeReplaysService{
+		ListFunc: func(ctx context.Context, id string, opts ...option.RequestOption) (*[]kernel.BrowserReplayListResponse, error) {
+			return &replays, nil
+		},
+		StopFunc: func(ctx context.Context, replayID string, body kernel.BrowserReplayStopParams, opts ...option.RequestOption) error {
+			stopped = append(stopped, replayID)
+			return nil
+		},
+		DownloadFunc: func(ctx context.Context, replayID string, query kernel.BrowserReplayDownloadParams, opts ...option.RequestOption) (*http.Response, error) {
+			downloaded = append(downloaded, replayID)
+			return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("mp4data"))}, nil
+		},
+	}
+	b := BrowsersCmd{browsers: newFakeBrowsersServiceWithSimpleGet(), replays: fake}
+	outPath := filepath.Join(t.TempDir(), "run.mp4")
+
+	require.NoError(t, b.RecordStop(context.Background(), BrowsersRecordStopInput{Identifier: "id", Output: outPath}))
+	assert.Equal(t, []string{"active"}, stopped)
+	assert.Equal(t, []string{"active"}, downloaded)
+	data, err := os.ReadFile(outPath)
+	require.NoError(t, err)
+	assert.Equal(t, "mp4data", string(data))
+	assert.Contains(t, outBuf.String(), "Stopped recording active")
+}
+
+func TestBrowsersRecordStop_NoActiveRecording(t *testing.T) {
+	fake := &FakeReplaysService{StopFunc: func(ctx context.Context, replayID string, body kernel.BrowserReplayStopParams, opts ...option.RequestOption) error {
+		t.Fatal("unexpected stop")
+		return nil
+	}}
+	b := BrowsersCmd{browsers: newFakeBrowsersServiceWithSimpleGet(), replays: fake}
+
+	err := b.RecordStop(context.Background(), BrowsersRecordStopInput{Identifier: "id"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no active recording")
+}
+
+func TestBrowsersReplaysDownload_DefaultsToLatest(t *testing.T) {
+	setupStdoutCapture(t)
+	replays := []kernel.BrowserReplayListResponse{
+		{ReplayID: "old", StartedAt: time.Unix(100, 0)},
+		{ReplayID: "new", StartedAt: time.Unix(200, 0)},
+	}
+	var got string
+	fake := &FakeReplaysService{
+		ListFunc: func(ctx context.Context, id string, opts ...option.RequestOption) (*[]kernel.BrowserReplayListResponse, error) {
+			return &replays, nil
+		},
+		DownloadFunc: func(ctx context.Context, replayID string, query kernel.BrowserReplayDownloadParams, opts ...option.RequestOption) (*http.Response, error) {
+			got = replayID
+			return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+		},
+	}
+	b := BrowsersCmd{browsers: newFakeBrowsersServiceWithSimpleGet(), replays: fake}
+
+	require.NoError(t, b.ReplaysDownload(context.Background(), BrowsersReplaysDownloadInput{Identifier: "id"}))
+	assert.Equal(t, "new", got)
+}
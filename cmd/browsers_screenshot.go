@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+type BrowsersScreenshotInput struct {
+	Identifier string
+	// Out is the PNG path; "-" writes to stdout.
+	Out      string
+	FullPage bool
+	Selector string
+}
+
+// Screenshot saves a PNG of a browser session. The plain case captures the
+// whole screen with the Computer API; full-page and element captures need
+// the page itself, so they go through Playwright.
+func (b BrowsersCmd) Screenshot(ctx context.Context, in BrowsersScreenshotInput) error {
+	if in.FullPage && in.Selector != "" {
+		return fmt.Errorf("--full-page and --selector can't be combined")
+	}
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+
+	var data []byte
+	if in.FullPage || in.Selector != "" {
+		data, err = b.pageScreenshot(ctx, br.SessionID, in.FullPage, in.Selector)
+	} else {
+		data, err = b.screenScreenshot(ctx, br.SessionID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if in.Out == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	out := in.Out
+	if out == "" {
+		out = fmt.Sprintf("screenshot-%s-%s.png", br.SessionID, time.Now().UTC().Format("20060102T150405Z"))
+	}
+	if err := os.WriteFile(out, data, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", out, err)
+	}
+	pterm.Success.Printf("Saved screenshot to %s\n", out)
+	return nil
+}
+
+func (b BrowsersCmd) screenScreenshot(ctx context.Context, sessionID string) ([]byte, error) {
+	if b.computer == nil {
+		return nil, fmt.Errorf("computer service not available")
+	}
+	res, err := b.computer.CaptureScreenshot(ctx, sessionID, kernel.BrowserComputerCaptureScreenshotParams{})
+	if err != nil {
+		return nil, util.CleanedUpSdkError{Err: err}
+	}
+	defer res.Body.Close()
+	return io.ReadAll(res.Body)
+}
+
+func (b BrowsersCmd) pageScreenshot(ctx context.Context, sessionID string, fullPage bool, selector string) ([]byte, error) {
+	if b.playwright == nil {
+		return nil, fmt.Errorf("playwright service not available")
+	}
+	code := fmt.Sprintf("return (await page.screenshot({ fullPage: %t })).toString('base64');", fullPage)
+	if selector != "" {
+		quoted, err := json.Marshal(selector)
+		if err != nil {
+			return nil, err
+		}
+		code = fmt.Sprintf("return (await page.locator(%s).first().screenshot()).toString('base64');", quoted)
+	}
+	res, err := executePlaywright(ctx, b.playwright, sessionID, kernel.BrowserPlaywrightExecuteParams{Code: code}, readOnlyPlaywrightRetries)
+	if err != nil {
+		return nil, util.CleanedUpSdkError{Err: err}
+	}
+	if !res.Success {
+		return nil, fmt.Errorf("screenshot failed: %s", util.FirstOrDash(res.Error, res.Stderr))
+	}
+	b64, ok := res.Result.(string)
+	if !ok || b64 == "" {
+		return nil, fmt.Errorf("screenshot failed: no image returned")
+	}
+	return base64.StdEncoding.DecodeString(b64)
+}
+
+var browsersScreenshotCmd = &cobra.Command{
+	Use:   "screenshot <id-or-name>",
+	Short: "Save a screenshot of a browser session",
+	Long: `Save a PNG screenshot of a browser session, e.g. as evidence from a CI run.
+
+By default the whole screen is captured. --full-page captures the entire
+scrollable page, and --selector captures the first element matching a CSS
+selector; both use the page's current tab.`,
+	Example: `  kernel browsers screenshot abc123
+  kernel browsers screenshot abc123 --full-page --out page.png
+  kernel browsers screenshot abc123 --selector '#checkout' --out - > checkout.png`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBrowsersScreenshot,
+}
+
+func init() {
+	browsersCmd.AddCommand(browsersScreenshotCmd)
+	browsersScreenshotCmd.ValidArgsFunction = completeResourceArg("browser", completeBrowser)
+	browsersScreenshotCmd.Flags().String("out", "", "Output PNG path, or - for stdout (default screenshot-<session>-<time>.png)")
+	browsersScreenshotCmd.Flags().Bool("full-page", false, "Capture the entire scrollable page")
+	browsersScreenshotCmd.Flags().String("selector", "", "Capture only the first element matching this CSS selector")
+	browsersScreenshotCmd.MarkFlagsMutuallyExclusive("full-page", "selector")
+}
+
+func runBrowsersScreenshot(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	out, _ := cmd.Flags().GetString("out")
+	fullPage, _ := cmd.Flags().GetBool("full-page")
+	selector, _ := cmd.Flags().GetString("selector")
+	svc := client.Browsers
+	b := BrowsersCmd{browsers: &svc, computer: &svc.Computer, playwright: &svc.Playwright}
+	return b.Screenshot(cmd.Context(), BrowsersScreenshotInput{Identifier: args[0], Out: out, FullPage: fullPage, Selector: selector})
+}
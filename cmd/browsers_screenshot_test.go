@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrowsersScreenshot_ScreenByDefault(t *testing.T) {
+	setupStdoutCapture(t)
+	out := filepath.Join(t.TempDir(), "shot.png")
+	pw := &fakePlaywright{}
+	b := BrowsersCmd{browsers: newFakeBrowsersServiceWithSimpleGet(), computer: &FakeComputerService{}, playwright: pw}
+
+	err := b.Screenshot(context.Background(), BrowsersScreenshotInput{Identifier: "id", Out: out})
+	require.NoError(t, err)
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "pngdata", string(data))
+	assert.Empty(t, pw.Calls())
+}
+
+func TestBrowsersScreenshot_SelectorUsesPlaywright(t *testing.T) {
+	setupStdoutCapture(t)
+	out := filepath.Join(t.TempDir(), "el.png")
+	pw := &fakePlaywright{executeFunc: func(id string, body kernel.BrowserPlaywrightExecuteParams) (*kernel.BrowserPlaywrightExecuteResponse, error) {
+		return &kernel.BrowserPlaywrightExecuteResponse{Success: true, Result: base64.StdEncoding.EncodeToString([]byte("elementpng"))}, nil
+	}}
+	b := BrowsersCmd{browsers: newFakeBrowsersServiceWithSimpleGet(), computer: &FakeComputerService{}, playwright: pw}
+
+	err := b.Screenshot(context.Background(), BrowsersScreenshotInput{Identifier: "id", Out: out, Selector: `button[name="go"]`})
+	require.NoError(t, err)
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "elementpng", string(data))
+	require.Len(t, pw.Calls(), 1)
+	assert.Contains(t, pw.Calls()[0], `page.locator("button[name=\"go\"]")`)
+}
+
+func TestBrowsersScreenshot_PlaywrightFailure(t *testing.T) {
+	setupStdoutCapture(t)
+	pw := &fakePlaywright{executeFunc: func(id string, body kernel.BrowserPlaywrightExecuteParams) (*kernel.BrowserPlaywrightExecuteResponse, error) {
+		return &kernel.BrowserPlaywrightExecuteResponse{Success: false, Error: "Timeout 30000ms exceeded"}, nil
+	}}
+	b := BrowsersCmd{browsers: newFakeBrowsersServiceWithSimpleGet(), playwright: pw}
+
+	err := b.Screenshot(context.Background(), BrowsersScreenshotInput{Identifier: "id", Out: filepath.Join(t.TempDir(), "x.png"), FullPage: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Timeout 30000ms exceeded")
+	assert.Contains(t, pw.Calls()[0], "fullPage: true")
+}
@@ -200,7 +200,7 @@ func (b BrowsersCmd) TelemetryStream(ctx context.Context, in BrowsersTelemetrySt
 	}
 	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer stop()
-	br, err := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{})
+	br, err := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -276,7 +276,7 @@ func (b BrowsersCmd) TelemetryEvents(ctx context.Context, in BrowsersTelemetryEv
 	// a 404 (ended or unknown session) is not fatal: fall back to the identifier
 	// as-is, since its archive may still be readable. Surface any other error.
 	sessionID := in.Identifier
-	if br, gerr := b.browsers.Get(ctx, in.Identifier, kernel.BrowserGetParams{}); gerr == nil {
+	if br, gerr := b.getBrowser(ctx, in.Identifier, kernel.BrowserGetParams{}); gerr == nil {
 		sessionID = br.SessionID
 	} else if !util.IsNotFound(gerr) {
 		return util.CleanedUpSdkError{Err: gerr}
@@ -356,12 +356,7 @@ func (b BrowsersCmd) TelemetryEvents(ctx context.Context, in BrowsersTelemetryEv
 			Events     []json.RawMessage `json:"events"`
 			NextOffset string            `json:"next_offset,omitempty"`
 		}{Events: events, NextOffset: nextOffset}
-		data, err := json.MarshalIndent(payload, "", "  ")
-		if err != nil {
-			return err
-		}
-		fmt.Println(string(data))
-		return nil
+		return util.PrintJSON(payload)
 	}
 
 	if len(items) == 0 {
@@ -11,9 +11,11 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/kernel/cli/pkg/lock"
 	"github.com/kernel/kernel-go-sdk"
 	"github.com/kernel/kernel-go-sdk/option"
 	"github.com/kernel/kernel-go-sdk/packages/pagination"
@@ -143,7 +145,7 @@ func TestBrowsersCurlRawUsesBrowserHTTPClient(t *testing.T) {
 		GetFunc: func(ctx context.Context, id string, query kernel.BrowserGetParams, opts ...option.RequestOption) (*kernel.BrowserGetResponse, error) {
 			getCalled = true
 			assert.Equal(t, "brw_123", id)
-			return &kernel.BrowserGetResponse{}, nil
+			return &kernel.BrowserGetResponse{SessionID: "brw_123"}, nil
 		},
 		HTTPClientFunc: func(id string, opts ...option.RequestOption) (*http.Client, error) {
 			assert.Equal(t, "brw_123", id)
@@ -486,6 +488,136 @@ func TestBrowsersCreate_WithNameAndTags(t *testing.T) {
 	assert.Contains(t, out, "env=staging, team=backend")
 }
 
+func TestBrowsersCreate_ProfileLockedByOtherOwnerFailsFast(t *testing.T) {
+	setupStdoutCapture(t)
+
+	store := &lock.Store{Dir: t.TempDir()}
+	_, err := store.Acquire(lock.ProfileResource("my-profile"), lock.Options{TTL: time.Hour, Owner: "ci-job-1"})
+	require.NoError(t, err)
+
+	called := false
+	fake := &FakeBrowsersService{
+		NewFunc: func(ctx context.Context, body kernel.BrowserNewParams, opts ...option.RequestOption) (*kernel.BrowserNewResponse, error) {
+			called = true
+			return &kernel.BrowserNewResponse{SessionID: "sess"}, nil
+		},
+	}
+	b := BrowsersCmd{browsers: fake, profileLocks: &profileLocker{store: store, owner: "ci-job-2"}}
+	err = b.Create(context.Background(), BrowsersCreateInput{ProfileName: "my-profile"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "locked by ci-job-1")
+	assert.False(t, called)
+}
+
+func TestBrowsersCreate_ProfileLockReleasedOnDelete(t *testing.T) {
+	setupStdoutCapture(t)
+
+	store := &lock.Store{Dir: t.TempDir()}
+	fake := &FakeBrowsersService{
+		NewFunc: func(ctx context.Context, body kernel.BrowserNewParams, opts ...option.RequestOption) (*kernel.BrowserNewResponse, error) {
+			return &kernel.BrowserNewResponse{SessionID: "sess-1"}, nil
+		},
+	}
+	b := BrowsersCmd{browsers: fake, profileLocks: &profileLocker{store: store, owner: "me"}}
+	require.NoError(t, b.Create(context.Background(), BrowsersCreateInput{ProfileName: "my-profile"}))
+
+	held, err := store.Get(lock.ProfileResource("my-profile"))
+	require.NoError(t, err)
+	require.NotNil(t, held)
+	assert.Equal(t, "sess-1", held.SessionID)
+
+	require.NoError(t, b.Delete(context.Background(), BrowsersDeleteInput{Identifier: "sess-1"}))
+	held, err = store.Get(lock.ProfileResource("my-profile"))
+	require.NoError(t, err)
+	assert.Nil(t, held)
+}
+
+func TestBrowsersCreateBatch_RespectsConcurrencyAndReportsFailures(t *testing.T) {
+	setupStdoutCapture(t)
+
+	var (
+		mu       sync.Mutex
+		inFlight int
+		peak     int
+		names    []string
+	)
+	fake := &FakeBrowsersService{
+		NewFunc: func(ctx context.Context, body kernel.BrowserNewParams, opts ...option.RequestOption) (*kernel.BrowserNewResponse, error) {
+			mu.Lock()
+			inFlight++
+			peak = max(peak, inFlight)
+			names = append(names, body.Name.Value)
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			if body.Name.Value == "load-3" {
+				return nil, errors.New("quota exceeded")
+			}
+			return &kernel.BrowserNewResponse{SessionID: "sess-" + body.Name.Value, Name: body.Name.Value}, nil
+		},
+	}
+
+	b := BrowsersCmd{browsers: fake}
+	sessions := expandBrowserCreateInput(BrowsersCreateInput{Headless: BoolFlag{Set: true, Value: true}}, 5, "load-")
+	err := b.CreateBatch(context.Background(), BrowsersBatchCreateInput{Sessions: sessions, Concurrency: 2})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 of 5")
+
+	assert.LessOrEqual(t, peak, 2)
+	assert.ElementsMatch(t, []string{"load-1", "load-2", "load-3", "load-4", "load-5"}, names)
+	out := outBuf.String()
+	assert.Contains(t, out, "sess-load-5")
+	assert.Contains(t, out, "quota exceeded")
+}
+
+func TestLoadBrowserManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "browsers.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`browsers:
+  - name_prefix: lt-
+    count: 2
+    headless: true
+    tags: {purpose: load}
+  - name: checkout
+    profile_name: shopper
+    chrome_policy: {HomepageLocation: "https://example.com"}
+`), 0o600))
+
+	sessions, err := loadBrowserManifest(path, true)
+	require.NoError(t, err)
+	require.Len(t, sessions, 3)
+	assert.Equal(t, "lt-1", sessions[0].Name)
+	assert.Equal(t, "lt-2", sessions[1].Name)
+	assert.Equal(t, BoolFlag{Set: true, Value: true}, sessions[1].Headless)
+	assert.Equal(t, "load", sessions[1].Tags["purpose"])
+	assert.Equal(t, "checkout", sessions[2].Name)
+	assert.Equal(t, "shopper", sessions[2].ProfileName)
+	assert.False(t, sessions[2].Headless.Set)
+	assert.JSONEq(t, `{"HomepageLocation":"https://example.com"}`, sessions[2].ChromePolicy)
+
+	require.NoError(t, os.WriteFile(path, []byte("browsers:\n  - name: dup\n    count: 3\n"), 0o600))
+	_, err = loadBrowserManifest(path, true)
+	assert.ErrorContains(t, err, "name_prefix")
+}
+
+func TestLoadBrowserManifest_ExpandsTemplates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "browsers.yaml")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "start.txt"), []byte("https://example.com/start\n"), 0o600))
+	require.NoError(t, os.WriteFile(path, []byte(`browsers:
+  - name: ci-{{env "KERNEL_TEST_RUN"}}
+    start_url: '{{file "start.txt"}}'
+`), 0o600))
+	t.Setenv("KERNEL_TEST_RUN", "42")
+
+	sessions, err := loadBrowserManifest(path, true)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "ci-42", sessions[0].Name)
+	assert.Equal(t, "https://example.com/start", sessions[0].StartURL)
+}
+
 func TestBrowsersCreate_WithChromePolicy(t *testing.T) {
 	setupStdoutCapture(t)
 
@@ -866,6 +998,78 @@ func TestBrowsersDelete_Success(t *testing.T) {
 	assert.Contains(t, out, "Successfully deleted (or already absent) browser: any")
 }
 
+func TestBrowsersDeleteAll_OlderThan(t *testing.T) {
+	setupStdoutCapture(t)
+
+	now := time.Now()
+	var listed kernel.BrowserListParams
+	var mu sync.Mutex
+	var deleted []string
+	fake := &FakeBrowsersService{
+		ListFunc: func(ctx context.Context, query kernel.BrowserListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.BrowserListResponse], error) {
+			listed = query
+			return &pagination.OffsetPagination[kernel.BrowserListResponse]{Items: []kernel.BrowserListResponse{
+				{SessionID: "old", CreatedAt: now.Add(-2 * time.Hour)},
+				{SessionID: "new", CreatedAt: now.Add(-10 * time.Minute)},
+			}}, nil
+		},
+		DeleteByIDFunc: func(ctx context.Context, id string, opts ...option.RequestOption) error {
+			mu.Lock()
+			defer mu.Unlock()
+			deleted = append(deleted, id)
+			return nil
+		},
+	}
+	b := BrowsersCmd{browsers: fake}
+
+	err := b.DeleteAll(context.Background(), BrowsersDeleteAllInput{OlderThan: time.Hour, DryRun: true})
+	assert.NoError(t, err)
+	assert.Empty(t, deleted)
+	assert.Equal(t, kernel.BrowserListParamsStatusActive, listed.Status)
+	assert.Contains(t, outBuf.String(), "Would delete 1 browser(s)")
+
+	err = b.DeleteAll(context.Background(), BrowsersDeleteAllInput{OlderThan: time.Hour, SkipConfirm: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"old"}, deleted)
+	assert.Contains(t, outBuf.String(), "Deleted 1 browser(s)")
+}
+
+func TestBrowsersDeleteAll_ReportsFailures(t *testing.T) {
+	setupStdoutCapture(t)
+
+	fake := &FakeBrowsersService{
+		ListFunc: func(ctx context.Context, query kernel.BrowserListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.BrowserListResponse], error) {
+			return &pagination.OffsetPagination[kernel.BrowserListResponse]{Items: []kernel.BrowserListResponse{
+				{SessionID: "a"}, {SessionID: "b"},
+			}}, nil
+		},
+		DeleteByIDFunc: func(ctx context.Context, id string, opts ...option.RequestOption) error {
+			if id == "b" {
+				return errors.New("boom")
+			}
+			return nil
+		},
+	}
+	b := BrowsersCmd{browsers: fake}
+	err := b.DeleteAll(context.Background(), BrowsersDeleteAllInput{SkipConfirm: true})
+	assert.EqualError(t, err, "failed to delete 1 of 2 browser(s)")
+	assert.Contains(t, outBuf.String(), "Failed to delete browser b")
+}
+
+func TestBrowsersDelete_LabelSelectsByTag(t *testing.T) {
+	cmd := &cobra.Command{Use: "delete"}
+	cmd.Flags().Bool("all", false, "")
+	cmd.Flags().StringArray("tag", nil, "")
+	cmd.Flags().SetNormalizeFunc(labelFlagAsTag)
+	require.NoError(t, cmd.ParseFlags([]string{"--label", "team=qa"}))
+
+	assert.True(t, deleteSelectsByFlags(cmd, nil))
+	assert.False(t, deleteSelectsByFlags(cmd, []string{"abc123"}))
+	tags, provided := tagsFromFlag(cmd, "tag")
+	assert.True(t, provided)
+	assert.Equal(t, map[string]string{"team": "qa"}, tags)
+}
+
 func TestBrowsersDelete_Failure(t *testing.T) {
 	setupStdoutCapture(t)
 
@@ -910,6 +1114,28 @@ func TestBrowsersView_ByID_PrintsURL(t *testing.T) {
 	assert.Contains(t, stdoutBuf.String(), "http://live-url")
 }
 
+func TestBrowsersView_CopyPutsURLOnClipboard(t *testing.T) {
+	setupStdoutCapture(t)
+	var copied []string
+	old := clipboardWriter
+	clipboardWriter = func(s string) error {
+		copied = append(copied, s)
+		return nil
+	}
+	t.Cleanup(func() { clipboardWriter = old })
+
+	fake := &FakeBrowsersService{
+		GetFunc: func(ctx context.Context, id string, query kernel.BrowserGetParams, opts ...option.RequestOption) (*kernel.BrowserGetResponse, error) {
+			return &kernel.BrowserGetResponse{SessionID: "abc", BrowserLiveViewURL: "http://live-url"}, nil
+		},
+	}
+	b := BrowsersCmd{browsers: fake}
+	captureStdout(t, func() {
+		require.NoError(t, b.View(context.Background(), BrowsersViewInput{Identifier: "abc", Output: "json", Copy: true}))
+	})
+	assert.Equal(t, []string{"http://live-url"}, copied)
+}
+
 func TestBrowsersView_NotFound(t *testing.T) {
 	setupStdoutCapture(t)
 
@@ -2395,3 +2621,59 @@ func TestBrowsersUpdate_AllMalformedTags_Errors(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no valid --tag")
 }
+
+func TestBrowsersAnnotate_MergesIntoExistingTags(t *testing.T) {
+	setupStdoutCapture(t)
+	fake, captured := captureUpdateParams(t)
+	fake.GetFunc = func(ctx context.Context, id string, query kernel.BrowserGetParams, opts ...option.RequestOption) (*kernel.BrowserGetResponse, error) {
+		return &kernel.BrowserGetResponse{SessionID: "session123", Tags: kernel.Tags{"owner": "bob", "env": "staging", "purpose": "old"}}, nil
+	}
+	b := BrowsersCmd{browsers: fake}
+
+	err := b.Annotate(context.Background(), BrowsersAnnotateInput{
+		Identifier: "session123",
+		Set:        map[string]string{"owner": "alice", "purpose": "auth run github"},
+		Unset:      []string{"env"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, kernel.Tags{"owner": "alice", "purpose": "auth run github"}, captured.Tags)
+	assert.Contains(t, outBuf.String(), "Tags: owner=alice, purpose=auth run github")
+}
+
+func TestBrowsersAnnotate_UnsetLastTag_SendsEmptyObject(t *testing.T) {
+	setupStdoutCapture(t)
+	fake, captured := captureUpdateParams(t)
+	fake.GetFunc = func(ctx context.Context, id string, query kernel.BrowserGetParams, opts ...option.RequestOption) (*kernel.BrowserGetResponse, error) {
+		return &kernel.BrowserGetResponse{SessionID: "session123", Tags: kernel.Tags{"owner": "bob"}}, nil
+	}
+	b := BrowsersCmd{browsers: fake}
+
+	err := b.Annotate(context.Background(), BrowsersAnnotateInput{Identifier: "session123", Unset: []string{"owner"}})
+
+	require.NoError(t, err)
+	raw, marshalErr := json.Marshal(*captured)
+	require.NoError(t, marshalErr)
+	assert.Contains(t, string(raw), `"tags":{}`)
+}
+
+func TestBrowsersAnnotate_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   BrowsersAnnotateInput
+		want string
+	}{
+		{"nothing to do", BrowsersAnnotateInput{Identifier: "session123"}, "must specify at least one --set KEY=VALUE or --unset KEY"},
+		{"all malformed", BrowsersAnnotateInput{Identifier: "session123", SetProvided: true}, "no valid --set"},
+		{"set and unset", BrowsersAnnotateInput{Identifier: "session123", Set: map[string]string{"a": "1"}, Unset: []string{"a"}}, `cannot both --set and --unset "a"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupStdoutCapture(t)
+			b := BrowsersCmd{browsers: &FakeBrowsersService{}}
+			err := b.Annotate(context.Background(), tt.in)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
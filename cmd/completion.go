@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// completionTimeout bounds each API call made while the user waits on <TAB>.
+	completionTimeout = 2 * time.Second
+	// completionCacheTTL is how long fetched candidates are reused, so repeated
+	// <TAB> presses don't each hit the API.
+	completionCacheTTL = 30 * time.Second
+	completionPageSize = 100
+)
+
+// completionLister fetches completion candidates, each "value" or
+// "value\tdescription".
+type completionLister func(ctx context.Context, client kernel.Client) ([]cobra.Completion, error)
+
+// completeResourceArg completes the first positional argument with resources
+// of kind fetched by list.
+func completeResourceArg(kind string, list completionLister) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return resourceCompletions(cmd, kind, list, toComplete, nil), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeResourceArgs completes every positional argument, skipping
+// resources already named on the command line.
+func completeResourceArgs(kind string, list completionLister) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		return resourceCompletions(cmd, kind, list, toComplete, args), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func resourceCompletions(cmd *cobra.Command, kind string, list completionLister, toComplete string, skip []string) []cobra.Completion {
+	client, ok := cmd.Context().Value(util.KernelClientKey).(kernel.Client)
+	if !ok {
+		return nil
+	}
+	project, _ := cmd.Flags().GetString("project")
+	key := strings.Join([]string{util.GetBaseURL(), resolveProjectSelection(project), kind}, " ")
+
+	items, err := cachedCompletions(completionCachePath(), key, time.Now(), func() ([]cobra.Completion, error) {
+		ctx, cancel := context.WithTimeout(cmd.Context(), completionTimeout)
+		defer cancel()
+		return list(ctx, client)
+	})
+	if err != nil {
+		cobra.CompDebugln("kernel: "+kind+" completion failed: "+err.Error(), true)
+		return nil
+	}
+	return filterCompletions(items, toComplete, skip)
+}
+
+// filterCompletions keeps candidates whose value starts with prefix and is
+// not in skip.
+func filterCompletions(items []cobra.Completion, prefix string, skip []string) []cobra.Completion {
+	var out []cobra.Completion
+	for _, item := range items {
+		value, _, _ := strings.Cut(item, "\t")
+		if strings.HasPrefix(value, prefix) && !slices.Contains(skip, value) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+type completionCacheEntry struct {
+	FetchedAt time.Time          `json:"fetched_at"`
+	Items     []cobra.Completion `json:"items"`
+}
+
+func completionCachePath() string {
+	dir, err := util.CacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "completions.json")
+}
+
+// cachedCompletions returns the candidates cached under key if they are
+// fresh, otherwise fetches and caches them. Cache errors are ignored; an
+// empty path disables caching.
+func cachedCompletions(path, key string, now time.Time, fetch func() ([]cobra.Completion, error)) ([]cobra.Completion, error) {
+	cache := map[string]completionCacheEntry{}
+	if path != "" {
+		if b, err := os.ReadFile(path); err == nil {
+			_ = json.Unmarshal(b, &cache)
+		}
+	}
+	if entry, ok := cache[key]; ok && now.Sub(entry.FetchedAt) < completionCacheTTL {
+		return entry.Items, nil
+	}
+
+	items, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		for k, entry := range cache {
+			if now.Sub(entry.FetchedAt) >= completionCacheTTL {
+				delete(cache, k)
+			}
+		}
+		cache[key] = completionCacheEntry{FetchedAt: now, Items: items}
+		if b, err := json.Marshal(cache); err == nil {
+			_ = os.WriteFile(path, b, 0o600)
+		}
+	}
+	return items, nil
+}
+
+func browserCompletions(ctx context.Context, svc BrowsersService) ([]cobra.Completion, error) {
+	page, err := svc.List(ctx, kernel.BrowserListParams{Limit: kernel.Opt(int64(completionPageSize))})
+	if err != nil || page == nil {
+		return nil, err
+	}
+	items := make([]cobra.Completion, 0, len(page.Items))
+	for _, b := range page.Items {
+		desc := "browser"
+		if b.Name != "" {
+			desc = b.Name
+		}
+		items = append(items, cobra.CompletionWithDesc(b.SessionID, desc))
+	}
+	return items, nil
+}
+
+func profileCompletions(ctx context.Context, svc ProfilesService) ([]cobra.Completion, error) {
+	page, err := svc.List(ctx, kernel.ProfileListParams{Limit: kernel.Opt(int64(completionPageSize))})
+	if err != nil || page == nil {
+		return nil, err
+	}
+	items := make([]cobra.Completion, 0, len(page.Items))
+	for _, p := range page.Items {
+		// Profiles are usually referred to by name; unnamed ones only have an ID.
+		if p.Name != "" {
+			items = append(items, cobra.CompletionWithDesc(p.Name, p.ID))
+		} else {
+			items = append(items, cobra.CompletionWithDesc(p.ID, "unnamed profile"))
+		}
+	}
+	return items, nil
+}
+
+func credentialCompletions(ctx context.Context, svc CredentialsService) ([]cobra.Completion, error) {
+	page, err := svc.List(ctx, kernel.CredentialListParams{Limit: kernel.Opt(int64(completionPageSize))})
+	if err != nil || page == nil {
+		return nil, err
+	}
+	items := make([]cobra.Completion, 0, len(page.Items))
+	for _, c := range page.Items {
+		items = append(items, cobra.CompletionWithDesc(c.Name, c.Domain))
+	}
+	return items, nil
+}
+
+func authConnectionCompletions(ctx context.Context, svc AuthConnectionService) ([]cobra.Completion, error) {
+	page, err := svc.List(ctx, kernel.AuthConnectionListParams{Limit: kernel.Opt(int64(completionPageSize))})
+	if err != nil || page == nil {
+		return nil, err
+	}
+	items := make([]cobra.Completion, 0, len(page.Items))
+	for _, a := range page.Items {
+		items = append(items, cobra.CompletionWithDesc(a.ID, a.Domain+" ("+a.ProfileName+")"))
+	}
+	return items, nil
+}
+
+var (
+	completeBrowser = func(ctx context.Context, client kernel.Client) ([]cobra.Completion, error) {
+		return browserCompletions(ctx, &client.Browsers)
+	}
+	completeProfile = func(ctx context.Context, client kernel.Client) ([]cobra.Completion, error) {
+		return profileCompletions(ctx, &client.Profiles)
+	}
+	completeCredential = func(ctx context.Context, client kernel.Client) ([]cobra.Completion, error) {
+		return credentialCompletions(ctx, &client.Credentials)
+	}
+	completeAuthConnection = func(ctx context.Context, client kernel.Client) ([]cobra.Completion, error) {
+		return authConnectionCompletions(ctx, &client.Auth.Connections)
+	}
+)
+
+// setBrowserIDCompletion completes the session ID argument of every command
+// under parent whose first argument is a browser.
+func setBrowserIDCompletion(parent *cobra.Command) {
+	for _, c := range parent.Commands() {
+		setBrowserIDCompletion(c)
+		fields := strings.Fields(c.Use)
+		if c.ValidArgsFunction != nil || len(fields) < 2 {
+			continue
+		}
+		switch fields[1] {
+		case "<id>", "<id-or-name>", "<session-id>":
+			c.ValidArgsFunction = completeResourceArg("browser", completeBrowser)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/kernel/kernel-go-sdk/packages/pagination"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedCompletions_ReusesFreshEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "completions.json")
+	now := time.Now()
+	fetches := 0
+	fetch := func() ([]cobra.Completion, error) {
+		fetches++
+		return []cobra.Completion{"a", "b"}, nil
+	}
+
+	items, err := cachedCompletions(path, "browser", now, fetch)
+	require.NoError(t, err)
+	assert.Equal(t, []cobra.Completion{"a", "b"}, items)
+
+	items, err = cachedCompletions(path, "browser", now.Add(completionCacheTTL/2), fetch)
+	require.NoError(t, err)
+	assert.Equal(t, []cobra.Completion{"a", "b"}, items)
+	assert.Equal(t, 1, fetches)
+
+	// Other kinds and stale entries are fetched again.
+	_, err = cachedCompletions(path, "profile", now, fetch)
+	require.NoError(t, err)
+	_, err = cachedCompletions(path, "browser", now.Add(completionCacheTTL), fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 3, fetches)
+
+	_, err = cachedCompletions(path, "credential", now, func() ([]cobra.Completion, error) {
+		return nil, errors.New("boom")
+	})
+	assert.EqualError(t, err, "boom")
+}
+
+func TestFilterCompletions(t *testing.T) {
+	items := []cobra.Completion{"abc\tfirst", "abd\tsecond", "xyz"}
+	assert.Equal(t, []cobra.Completion{"abc\tfirst", "abd\tsecond"}, filterCompletions(items, "ab", nil))
+	assert.Equal(t, []cobra.Completion{"abd\tsecond"}, filterCompletions(items, "ab", []string{"abc"}))
+	assert.Empty(t, filterCompletions(items, "q", nil))
+}
+
+func TestBrowserCompletions_DescribesSessions(t *testing.T) {
+	fake := &FakeBrowsersService{
+		ListFunc: func(ctx context.Context, query kernel.BrowserListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.BrowserListResponse], error) {
+			assert.Equal(t, int64(completionPageSize), query.Limit.Value)
+			return &pagination.OffsetPagination[kernel.BrowserListResponse]{Items: []kernel.BrowserListResponse{
+				{SessionID: "sess-1", Name: "checkout"},
+				{SessionID: "sess-2"},
+			}}, nil
+		},
+	}
+	items, err := browserCompletions(context.Background(), fake)
+	require.NoError(t, err)
+	assert.Equal(t, []cobra.Completion{"sess-1\tcheckout", "sess-2\tbrowser"}, items)
+}
+
+func TestSetBrowserIDCompletion_OnlyCompletesBrowserArgs(t *testing.T) {
+	root := &cobra.Command{Use: "browsers"}
+	get := &cobra.Command{Use: "get <id-or-name>"}
+	list := &cobra.Command{Use: "list"}
+	fs := &cobra.Command{Use: "fs"}
+	readFile := &cobra.Command{Use: "read-file <id>"}
+	fs.AddCommand(readFile)
+	root.AddCommand(get, list, fs)
+
+	setBrowserIDCompletion(root)
+	assert.NotNil(t, get.ValidArgsFunction)
+	assert.NotNil(t, readFile.ValidArgsFunction)
+	assert.Nil(t, list.ValidArgsFunction)
+	assert.Nil(t, fs.ValidArgsFunction)
+}
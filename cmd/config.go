@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/kernel/cli/pkg/config"
+	"github.com/kernel/cli/pkg/util"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// contextEnvVars maps context fields to the environment variables the rest of
+// the CLI reads them from.
+var contextEnvVars = []struct {
+	name  string
+	value func(config.Context) string
+}{
+	{"KERNEL_API_KEY", func(c config.Context) string { return c.APIKey }},
+	{"KERNEL_BASE_URL", func(c config.Context) string { return strings.Join(c.URLs(), ",") }},
+	{"KERNEL_PROJECT", func(c config.Context) string { return c.Project }},
+}
+
+// applyConfigContext exports the selected context's settings as the
+// KERNEL_* environment variables every command already honors. A context
+// named with --context or KERNEL_CONTEXT overrides those variables; the
+// current context only fills in ones that aren't set.
+func applyConfigContext(cfg *config.Config, name string) error {
+	explicit := name != ""
+	if !explicit {
+		name = cfg.CurrentContext
+	}
+	if name == "" {
+		return nil
+	}
+	c, ok := cfg.Contexts[name]
+	if !ok {
+		return fmt.Errorf("context %q not found; run 'kernel config get-contexts' to list contexts", name)
+	}
+	for _, v := range contextEnvVars {
+		value := v.value(c)
+		if value == "" || (!explicit && os.Getenv(v.name) != "") {
+			continue
+		}
+		if err := os.Setenv(v.name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveContextSelection mirrors resolveProjectSelection for --context.
+func resolveContextSelection(contextFlag string) string {
+	if contextFlag != "" {
+		return contextFlag
+	}
+	return os.Getenv("KERNEL_CONTEXT")
+}
+
+func maskAPIKey(key string) string {
+	if key == "" {
+		return ""
+	}
+	if len(key) >= 12 {
+		return key[:8] + "..." + key[len(key)-4:]
+	}
+	return strings.Repeat("*", len(key))
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage named contexts for multiple accounts and environments",
+	Long: `Manage named contexts stored in ~/.config/kernel/config.yaml. A context holds an
+API key, base URL and default project; select one per command with --context
+(or KERNEL_CONTEXT), or make it the default with 'kernel config use-context'.`,
+}
+
+var configSetContextCmd = &cobra.Command{
+	Use:   "set-context <name>",
+	Short: "Create or update a context",
+	Long:  "Create a context, or update the given fields of an existing one. Pass an empty value to clear a field.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigSetContext,
+}
+
+var configUseContextCmd = &cobra.Command{
+	Use:               "use-context <name>",
+	Short:             "Make a context the default for every command",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeContextName,
+	RunE:              runConfigUseContext,
+}
+
+var configGetContextsCmd = &cobra.Command{
+	Use:   "get-contexts",
+	Short: "List contexts",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigGetContexts,
+}
+
+func init() {
+	configSetContextCmd.Flags().String("api-key", "", "API key to authenticate with")
+	configSetContextCmd.Flags().String("base-url", "", "API base URL; a comma-separated list is stored as base_urls, with reads failing over in order")
+	configSetContextCmd.Flags().String("project", "", "Default project ID or name")
+	configSetContextCmd.Flags().Bool("use", false, "Also make this the current context")
+	configSetContextCmd.ValidArgsFunction = completeContextName
+
+	addJSONOutputFlag(configGetContextsCmd)
+
+	configCmd.AddCommand(configSetContextCmd)
+	configCmd.AddCommand(configUseContextCmd)
+	configCmd.AddCommand(configGetContextsCmd)
+}
+
+func runConfigSetContext(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if cfg.Contexts == nil {
+		cfg.Contexts = map[string]config.Context{}
+	}
+	c, existed := cfg.Contexts[name]
+	if cmd.Flags().Changed("api-key") {
+		c.APIKey, _ = cmd.Flags().GetString("api-key")
+	}
+	if cmd.Flags().Changed("base-url") {
+		baseURL, _ := cmd.Flags().GetString("base-url")
+		// A list is kept as base_urls so failover endpoints are explicit
+		c.BaseURL, c.BaseURLs = baseURL, nil
+		if urls := config.SplitURLs(baseURL); len(urls) > 1 {
+			c.BaseURL, c.BaseURLs = "", urls
+		}
+	}
+	if cmd.Flags().Changed("project") {
+		c.Project, _ = cmd.Flags().GetString("project")
+	}
+	cfg.Contexts[name] = c
+	if use, _ := cmd.Flags().GetBool("use"); use {
+		cfg.CurrentContext = name
+	}
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+
+	if existed {
+		pterm.Success.Printf("Updated context %s\n", name)
+	} else {
+		pterm.Success.Printf("Created context %s\n", name)
+	}
+	if cfg.CurrentContext == name {
+		pterm.Info.Printf("Current context is %s\n", name)
+	}
+	return nil
+}
+
+func runConfigUseContext(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Contexts[name]; !ok {
+		return fmt.Errorf("context %q not found; create it with 'kernel config set-context %s'", name, name)
+	}
+	cfg.CurrentContext = name
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+	pterm.Success.Printf("Switched to context %s\n", name)
+	return nil
+}
+
+type configContextRow struct {
+	Name     string   `json:"name"`
+	Current  bool     `json:"current"`
+	BaseURL  string   `json:"base_url,omitempty"`
+	BaseURLs []string `json:"base_urls,omitempty"`
+	Project  string   `json:"project,omitempty"`
+	APIKey   string   `json:"api_key,omitempty"`
+}
+
+func runConfigGetContexts(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	if err := validateJSONOutput(output); err != nil {
+		return err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(cfg.Contexts))
+	for name := range cfg.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	contexts := make([]configContextRow, 0, len(names))
+	for _, name := range names {
+		c := cfg.Contexts[name]
+		contexts = append(contexts, configContextRow{
+			Name:     name,
+			Current:  name == cfg.CurrentContext,
+			BaseURL:  c.BaseURL,
+			BaseURLs: c.BaseURLs,
+			Project:  c.Project,
+			APIKey:   maskAPIKey(c.APIKey),
+		})
+	}
+
+	if output == "json" {
+		return util.PrintJSON(contexts)
+	}
+	if len(contexts) == 0 {
+		pterm.Info.Println("No contexts found. Create one with 'kernel config set-context <name>'.")
+		return nil
+	}
+	rows := pterm.TableData{{"Current", "Name", "Base URL", "Project", "API Key"}}
+	for _, c := range contexts {
+		current := ""
+		if c.Current {
+			current = "*"
+		}
+		baseURL := c.BaseURL
+		if len(c.BaseURLs) > 0 {
+			baseURL = strings.Join(c.BaseURLs, ", ")
+		}
+		rows = append(rows, []string{current, c.Name, util.FirstOrDash(baseURL), util.FirstOrDash(c.Project), util.FirstOrDash(c.APIKey)})
+	}
+	PrintTableNoPad(rows, true)
+	return nil
+}
+
+func completeContextName(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []cobra.Completion
+	for name := range cfg.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return filterCompletions(names, toComplete, nil), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/kernel/cli/pkg/config"
+	"github.com/kernel/cli/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyConfigContext(t *testing.T) {
+	cfg := &config.Config{
+		CurrentContext: "prod",
+		Contexts: map[string]config.Context{
+			"prod":    {APIKey: "sk_prod", Project: "proj_prod"},
+			"staging": {APIKey: "sk_staging", BaseURL: "https://api.staging.example.com"},
+			"multi":   {BaseURLs: []string{"https://us.example.com", "https://eu.example.com"}},
+		},
+	}
+
+	t.Run("current context fills unset variables", func(t *testing.T) {
+		t.Setenv("KERNEL_API_KEY", "sk_env")
+		t.Setenv("KERNEL_BASE_URL", "")
+		t.Setenv("KERNEL_PROJECT", "")
+		require.NoError(t, applyConfigContext(cfg, ""))
+		assert.Equal(t, "sk_env", os.Getenv("KERNEL_API_KEY"))
+		assert.Equal(t, "proj_prod", os.Getenv("KERNEL_PROJECT"))
+		assert.Equal(t, "", os.Getenv("KERNEL_BASE_URL"))
+	})
+
+	t.Run("named context overrides the environment", func(t *testing.T) {
+		t.Setenv("KERNEL_API_KEY", "sk_env")
+		t.Setenv("KERNEL_BASE_URL", "")
+		t.Setenv("KERNEL_PROJECT", "")
+		require.NoError(t, applyConfigContext(cfg, "staging"))
+		assert.Equal(t, "sk_staging", os.Getenv("KERNEL_API_KEY"))
+		assert.Equal(t, "https://api.staging.example.com", os.Getenv("KERNEL_BASE_URL"))
+		assert.Equal(t, "", os.Getenv("KERNEL_PROJECT"))
+	})
+
+	t.Run("base_urls become the failover list", func(t *testing.T) {
+		t.Setenv("KERNEL_BASE_URL", "")
+		require.NoError(t, applyConfigContext(cfg, "multi"))
+		assert.Equal(t, "https://us.example.com,https://eu.example.com", os.Getenv("KERNEL_BASE_URL"))
+		assert.Equal(t, []string{"https://us.example.com", "https://eu.example.com"}, util.GetBaseURLs())
+	})
+
+	t.Run("unknown context", func(t *testing.T) {
+		err := applyConfigContext(cfg, "dev")
+		assert.ErrorContains(t, err, `context "dev" not found`)
+	})
+
+	t.Run("no context selected", func(t *testing.T) {
+		require.NoError(t, applyConfigContext(&config.Config{}, ""))
+	})
+}
+
+func TestConfigContexts_SetUseAndList(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	setupStdoutCapture(t)
+
+	set := configSetContextCmd
+	t.Cleanup(func() {
+		for _, name := range []string{"api-key", "base-url", "project", "use"} {
+			f := set.Flags().Lookup(name)
+			_ = f.Value.Set(f.DefValue)
+			f.Changed = false
+		}
+	})
+	require.NoError(t, set.Flags().Set("api-key", "sk_live_1234567890"))
+	require.NoError(t, set.Flags().Set("base-url", "https://api.staging.example.com"))
+	require.NoError(t, runConfigSetContext(set, []string{"staging"}))
+
+	// Updating one field keeps the others.
+	require.NoError(t, set.Flags().Set("api-key", "sk_live_abcdefghij"))
+	set.Flags().Lookup("base-url").Changed = false
+	require.NoError(t, runConfigSetContext(set, []string{"staging"}))
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, config.Context{APIKey: "sk_live_abcdefghij", BaseURL: "https://api.staging.example.com"}, cfg.Contexts["staging"])
+	assert.Empty(t, cfg.CurrentContext)
+
+	assert.ErrorContains(t, runConfigUseContext(configUseContextCmd, []string{"prod"}), `context "prod" not found`)
+	require.NoError(t, runConfigUseContext(configUseContextCmd, []string{"staging"}))
+	cfg, err = config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "staging", cfg.CurrentContext)
+
+	// A comma-separated --base-url is stored as a list
+	require.NoError(t, set.Flags().Set("base-url", "https://us.example.com, https://eu.example.com"))
+	require.NoError(t, runConfigSetContext(set, []string{"multi"}))
+	cfg, err = config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, config.Context{APIKey: "sk_live_abcdefghij", BaseURLs: []string{"https://us.example.com", "https://eu.example.com"}}, cfg.Contexts["multi"])
+
+	outBuf.Reset()
+	require.NoError(t, runConfigGetContexts(configGetContextsCmd, nil))
+	assert.Contains(t, outBuf.String(), "https://us.example.com, https://eu.example.com")
+	assert.Contains(t, outBuf.String(), "staging")
+	assert.Contains(t, outBuf.String(), "sk_live_...ghij")
+	assert.NotContains(t, outBuf.String(), "abcdefghij")
+}
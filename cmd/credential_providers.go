@@ -97,11 +97,11 @@ func (c CredentialProvidersCmd) List(ctx context.Context, in CredentialProviders
 
 	if in.Output == "json" {
 		if len(providers) == 0 {
-			fmt.Println("[]")
-			return nil
+			return util.PrintJSON([]any{})
 		}
 		return util.PrintPrettyJSONSlice(providers)
 	}
+	util.WarnDecodeDrift(providers)
 
 	if len(providers) == 0 {
 		pterm.Info.Println("No credential providers found")
@@ -136,6 +136,7 @@ func (c CredentialProvidersCmd) Get(ctx context.Context, in CredentialProvidersG
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(provider)
 	}
+	util.WarnDecodeDrift(provider)
 
 	tableData := pterm.TableData{
 		{"Property", "Value"},
@@ -195,6 +196,7 @@ func (c CredentialProvidersCmd) Create(ctx context.Context, in CredentialProvide
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(provider)
 	}
+	util.WarnDecodeDrift(provider)
 
 	pterm.Success.Printf("Created credential provider: %s\n", provider.ID)
 
@@ -247,6 +249,7 @@ func (c CredentialProvidersCmd) Update(ctx context.Context, in CredentialProvide
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(provider)
 	}
+	util.WarnDecodeDrift(provider)
 
 	pterm.Success.Printf("Updated credential provider: %s\n", provider.ID)
 	return nil
@@ -291,6 +294,7 @@ func (c CredentialProvidersCmd) Test(ctx context.Context, in CredentialProviders
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(result)
 	}
+	util.WarnDecodeDrift(result)
 
 	if result.Success {
 		pterm.Success.Println("Connection test successful")
@@ -328,11 +332,11 @@ func (c CredentialProvidersCmd) ListItems(ctx context.Context, in CredentialProv
 
 	if in.Output == "json" {
 		if len(result.Items) == 0 {
-			fmt.Println("[]")
-			return nil
+			return util.PrintJSON([]any{})
 		}
 		return util.PrintPrettyJSONSlice(result.Items)
 	}
+	util.WarnDecodeDrift(result)
 
 	if len(result.Items) == 0 {
 		pterm.Info.Println("No items found")
@@ -450,7 +454,7 @@ func init() {
 	addJSONOutputFlag(credentialProvidersCreateCmd)
 	credentialProvidersCreateCmd.Flags().String("name", "", "Human-readable name for this provider instance")
 	credentialProvidersCreateCmd.Flags().String("provider-type", "", "Provider type (e.g., onepassword)")
-	credentialProvidersCreateCmd.Flags().String("token", "", "Service account token for the provider")
+	credentialProvidersCreateCmd.Flags().String("token", "", "Service account token for the provider"+secretReferenceUsage)
 	credentialProvidersCreateCmd.Flags().Int64("cache-ttl", 0, "How long to cache credential lists in seconds (default 300)")
 	_ = credentialProvidersCreateCmd.MarkFlagRequired("name")
 	_ = credentialProvidersCreateCmd.MarkFlagRequired("provider-type")
@@ -459,7 +463,7 @@ func init() {
 	// Update flags
 	addJSONOutputFlag(credentialProvidersUpdateCmd)
 	credentialProvidersUpdateCmd.Flags().String("name", "", "New human-readable name for this provider instance")
-	credentialProvidersUpdateCmd.Flags().String("token", "", "New service account token (to rotate credentials)")
+	credentialProvidersUpdateCmd.Flags().String("token", "", "New service account token, to rotate credentials"+secretReferenceUsage)
 	credentialProvidersUpdateCmd.Flags().Int64("cache-ttl", 0, "How long to cache credential lists in seconds")
 	credentialProvidersUpdateCmd.Flags().Bool("enabled", true, "Whether the provider is enabled for credential lookups")
 	credentialProvidersUpdateCmd.Flags().Int64("priority", 0, "Priority order for credential lookups (lower numbers are checked first)")
@@ -506,7 +510,10 @@ func runCredentialProvidersCreate(cmd *cobra.Command, args []string) error {
 	output, _ := cmd.Flags().GetString("output")
 	name, _ := cmd.Flags().GetString("name")
 	providerType, _ := cmd.Flags().GetString("provider-type")
-	token, _ := cmd.Flags().GetString("token")
+	token, err := secretFlag(cmd, "token")
+	if err != nil {
+		return err
+	}
 	cacheTtl, _ := cmd.Flags().GetInt64("cache-ttl")
 
 	svc := client.CredentialProviders
@@ -524,7 +531,10 @@ func runCredentialProvidersUpdate(cmd *cobra.Command, args []string) error {
 	client := getKernelClient(cmd)
 	output, _ := cmd.Flags().GetString("output")
 	name, _ := cmd.Flags().GetString("name")
-	token, _ := cmd.Flags().GetString("token")
+	token, err := secretFlag(cmd, "token")
+	if err != nil {
+		return err
+	}
 	cacheTtl, _ := cmd.Flags().GetInt64("cache-ttl")
 	enabled, _ := cmd.Flags().GetBool("enabled")
 	priority, _ := cmd.Flags().GetInt64("priority")
@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+const defaultProviderCacheTTL = 300
+
+// setupPrompter asks the questions of an interactive setup. The pterm
+// implementation is used unless a test supplies its own.
+type setupPrompter interface {
+	Select(label string, options []string) (string, error)
+	Text(label, def string) (string, error)
+	Secret(label string) (string, error)
+	Confirm(label string, def bool) (bool, error)
+}
+
+type ptermPrompter struct{}
+
+func (ptermPrompter) Select(label string, options []string) (string, error) {
+	return pterm.DefaultInteractiveSelect.WithOptions(options).WithDefaultText(label).Show()
+}
+
+func (ptermPrompter) Text(label, def string) (string, error) {
+	v, err := pterm.DefaultInteractiveTextInput.WithDefaultValue(def).Show(label)
+	return strings.TrimSpace(v), err
+}
+
+func (ptermPrompter) Secret(label string) (string, error) {
+	return pterm.DefaultInteractiveTextInput.WithMask("*").Show(label)
+}
+
+func (ptermPrompter) Confirm(label string, def bool) (bool, error) {
+	return pterm.DefaultInteractiveConfirm.WithDefaultValue(def).Show(label)
+}
+
+// providerSetupTypes lists the provider types setup offers, with the label
+// shown for each.
+var providerSetupTypes = []struct {
+	Type  string
+	Label string
+	Name  string
+}{
+	{"onepassword", "onepassword - 1Password service account", "1password"},
+}
+
+// Setup walks through creating a credential provider: type, name and token,
+// then a connection test that lists the accessible vaults, then priority and
+// cache TTL. A provider whose token never passes the test is deleted again.
+func (c CredentialProvidersCmd) Setup(ctx context.Context, p setupPrompter) error {
+	labels := make([]string, len(providerSetupTypes))
+	for i, t := range providerSetupTypes {
+		labels[i] = t.Label
+	}
+	label, err := p.Select("Provider type:", labels)
+	if err != nil {
+		return err
+	}
+	kind := providerSetupTypes[0]
+	for _, t := range providerSetupTypes {
+		if t.Label == label {
+			kind = t
+		}
+	}
+
+	name, err := p.Text("Name", kind.Name)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = kind.Name
+	}
+	token, err := promptProviderToken(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	pterm.Info.Printf("Creating credential provider (%s)...\n", kind.Type)
+	provider, err := c.providers.New(ctx, kernel.CredentialProviderNewParams{
+		CreateCredentialProviderRequest: kernel.CreateCredentialProviderRequestParam{
+			Name:         name,
+			Token:        token,
+			ProviderType: kernel.CreateCredentialProviderRequestProviderType(kind.Type),
+		},
+	})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	pterm.Success.Printf("Created credential provider: %s\n", provider.ID)
+
+	result, err := c.testProviderUntilValid(ctx, p, provider.ID)
+	if err != nil {
+		return err
+	}
+	pterm.Success.Println("Connection test successful")
+	if len(result.Vaults) > 0 {
+		pterm.Info.Println("Accessible vaults:")
+		tableData := pterm.TableData{{"Vault ID", "Vault Name"}}
+		for _, v := range result.Vaults {
+			tableData = append(tableData, []string{v.ID, v.Name})
+		}
+		PrintTableNoPad(tableData, true)
+	} else {
+		pterm.Warning.Println("The token works but can't see any vaults; grant the service account vault access before relying on it")
+	}
+
+	priority, err := promptInt(p, "Priority (lower numbers are checked first)", provider.Priority)
+	if err != nil {
+		return err
+	}
+	ttl, err := promptInt(p, "Cache TTL in seconds", defaultProviderCacheTTL)
+	if err != nil {
+		return err
+	}
+	if priority != provider.Priority || ttl != defaultProviderCacheTTL {
+		params := kernel.CredentialProviderUpdateParams{}
+		if priority != provider.Priority {
+			params.UpdateCredentialProviderRequest.Priority = kernel.Opt(priority)
+		}
+		if ttl != defaultProviderCacheTTL {
+			params.UpdateCredentialProviderRequest.CacheTtlSeconds = kernel.Opt(ttl)
+		}
+		if _, err := c.providers.Update(ctx, provider.ID, params); err != nil {
+			return util.CleanedUpSdkError{Err: err}
+		}
+	}
+
+	PrintTableNoPad(pterm.TableData{
+		{"Property", "Value"},
+		{"ID", provider.ID},
+		{"Name", name},
+		{"Provider Type", kind.Type},
+		{"Priority", strconv.FormatInt(priority, 10)},
+		{"Cache TTL", fmt.Sprintf("%ds", ttl)},
+	}, true)
+	pterm.Info.Printf("List the items it can see with: kernel credential-providers list-items %s\n", provider.ID)
+	return nil
+}
+
+// testProviderUntilValid runs the connection test, offering to replace the
+// token after each failure. If the user gives up, the provider is deleted
+// (after confirming) and an error is returned.
+func (c CredentialProvidersCmd) testProviderUntilValid(ctx context.Context, p setupPrompter, id string) (*kernel.CredentialProviderTestResult, error) {
+	for {
+		pterm.Info.Println("Testing connection...")
+		result, err := c.providers.Test(ctx, id)
+		if err != nil {
+			return nil, util.CleanedUpSdkError{Err: err}
+		}
+		if result.Success {
+			return result, nil
+		}
+		pterm.Error.Printf("Connection test failed: %s\n", util.OrDash(result.Error))
+
+		retry, err := p.Confirm("Enter a different token?", true)
+		if err != nil {
+			return nil, err
+		}
+		if !retry {
+			break
+		}
+		token, err := promptProviderToken(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		params := kernel.CredentialProviderUpdateParams{}
+		params.UpdateCredentialProviderRequest.Token = kernel.Opt(token)
+		if _, err := c.providers.Update(ctx, id, params); err != nil {
+			return nil, util.CleanedUpSdkError{Err: err}
+		}
+	}
+
+	remove, err := p.Confirm(fmt.Sprintf("Delete credential provider '%s'?", id), true)
+	if err != nil {
+		return nil, err
+	}
+	if remove {
+		if err := c.providers.Delete(ctx, id); err != nil {
+			return nil, util.CleanedUpSdkError{Err: err}
+		}
+		pterm.Info.Printf("Deleted credential provider: %s\n", id)
+	}
+	return nil, fmt.Errorf("credential provider setup did not complete: connection test failed")
+}
+
+// promptProviderToken asks for a token with masked input. A secret
+// reference (see util.ResolveSecret) is resolved.
+func promptProviderToken(ctx context.Context, p setupPrompter) (string, error) {
+	for {
+		raw, err := p.Secret("Service account token (or a secret reference such as op:... or env:...)")
+		if err != nil {
+			return "", err
+		}
+		token, err := util.ResolveSecret(ctx, strings.TrimSpace(raw))
+		if err != nil {
+			pterm.Warning.Printf("%v\n", err)
+			continue
+		}
+		if token != "" {
+			return token, nil
+		}
+		pterm.Warning.Println("The token can't be empty")
+	}
+}
+
+// promptInt asks for a non-negative integer, re-asking until it gets one.
+func promptInt(p setupPrompter, label string, def int64) (int64, error) {
+	for {
+		raw, err := p.Text(label, strconv.FormatInt(def, 10))
+		if err != nil {
+			return 0, err
+		}
+		if raw == "" {
+			return def, nil
+		}
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err == nil && n >= 0 {
+			return n, nil
+		}
+		pterm.Warning.Printf("%q is not a non-negative whole number\n", raw)
+	}
+}
+
+var credentialProvidersSetupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Set up a credential provider interactively",
+	Long: `Walk through creating a credential provider: pick the provider type, enter the
+service account token (masked), check it with a connection test that lists the
+vaults it can access, then choose the lookup priority and cache TTL.
+
+Use 'kernel credential-providers create' for scripts.`,
+	Args: cobra.NoArgs,
+	RunE: runCredentialProvidersSetup,
+}
+
+func init() {
+	credentialProvidersCmd.AddCommand(credentialProvidersSetupCmd)
+}
+
+func runCredentialProvidersSetup(cmd *cobra.Command, args []string) error {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("setup is interactive; use 'kernel credential-providers create' when stdin is not a terminal")
+	}
+	client := getKernelClient(cmd)
+	svc := client.CredentialProviders
+	c := CredentialProvidersCmd{providers: &svc}
+	return c.Setup(cmd.Context(), ptermPrompter{})
+}
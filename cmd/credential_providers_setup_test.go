@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedPrompter answers setup questions from queues, failing the test if
+// a question comes with no answer left.
+type scriptedPrompter struct {
+	t        *testing.T
+	texts    []string
+	secrets  []string
+	confirms []bool
+}
+
+func (p *scriptedPrompter) Select(label string, options []string) (string, error) {
+	return options[0], nil
+}
+
+func (p *scriptedPrompter) Text(label, def string) (string, error) {
+	if len(p.texts) == 0 {
+		p.t.Fatalf("unexpected text prompt %q", label)
+	}
+	v := p.texts[0]
+	p.texts = p.texts[1:]
+	return v, nil
+}
+
+func (p *scriptedPrompter) Secret(label string) (string, error) {
+	if len(p.secrets) == 0 {
+		p.t.Fatalf("unexpected secret prompt %q", label)
+	}
+	v := p.secrets[0]
+	p.secrets = p.secrets[1:]
+	return v, nil
+}
+
+func (p *scriptedPrompter) Confirm(label string, def bool) (bool, error) {
+	if len(p.confirms) == 0 {
+		p.t.Fatalf("unexpected confirm %q", label)
+	}
+	v := p.confirms[0]
+	p.confirms = p.confirms[1:]
+	return v, nil
+}
+
+func TestCredentialProvidersSetup_RetriesTokenThenConfigures(t *testing.T) {
+	setupStdoutCapture(t)
+	t.Setenv("OP_SERVICE_ACCOUNT_TOKEN", "ops_good")
+
+	var created kernel.CredentialProviderNewParams
+	var updates []kernel.CredentialProviderUpdateParams
+	tests := 0
+	fake := &FakeCredentialProvidersService{
+		NewFunc: func(ctx context.Context, body kernel.CredentialProviderNewParams, opts ...option.RequestOption) (*kernel.CredentialProvider, error) {
+			created = body
+			return &kernel.CredentialProvider{ID: "cp_1", Name: body.CreateCredentialProviderRequest.Name, Priority: 0}, nil
+		},
+		UpdateFunc: func(ctx context.Context, id string, body kernel.CredentialProviderUpdateParams, opts ...option.RequestOption) (*kernel.CredentialProvider, error) {
+			updates = append(updates, body)
+			return &kernel.CredentialProvider{ID: id}, nil
+		},
+		TestFunc: func(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.CredentialProviderTestResult, error) {
+			tests++
+			if tests == 1 {
+				return &kernel.CredentialProviderTestResult{Error: "invalid token"}, nil
+			}
+			return &kernel.CredentialProviderTestResult{Success: true, Vaults: []kernel.CredentialProviderTestResultVault{{ID: "v1", Name: "Engineering"}}}, nil
+		},
+	}
+	p := &scriptedPrompter{
+		t:        t,
+		texts:    []string{"team-1p", "nope", "2", ""},
+		secrets:  []string{"ops_typo", "env:OP_SERVICE_ACCOUNT_TOKEN"},
+		confirms: []bool{true},
+	}
+
+	c := CredentialProvidersCmd{providers: fake}
+	require.NoError(t, c.Setup(context.Background(), p))
+
+	assert.Equal(t, "team-1p", created.CreateCredentialProviderRequest.Name)
+	assert.Equal(t, "ops_typo", created.CreateCredentialProviderRequest.Token)
+	assert.Equal(t, kernel.CreateCredentialProviderRequestProviderTypeOnepassword, created.CreateCredentialProviderRequest.ProviderType)
+	require.Len(t, updates, 2)
+	assert.Equal(t, "ops_good", updates[0].UpdateCredentialProviderRequest.Token.Value)
+	assert.Equal(t, int64(2), updates[1].UpdateCredentialProviderRequest.Priority.Value)
+	assert.False(t, updates[1].UpdateCredentialProviderRequest.CacheTtlSeconds.Valid(), "default TTL is not sent")
+
+	out := outBuf.String()
+	assert.Contains(t, out, "Connection test failed: invalid token")
+	assert.Contains(t, out, "Engineering")
+	assert.Contains(t, out, `"nope" is not a non-negative whole number`)
+	assert.Contains(t, out, "list-items cp_1")
+}
+
+func TestCredentialProvidersSetup_DeletesProviderWhenTestIsAbandoned(t *testing.T) {
+	setupStdoutCapture(t)
+
+	var deleted string
+	fake := &FakeCredentialProvidersService{
+		NewFunc: func(ctx context.Context, body kernel.CredentialProviderNewParams, opts ...option.RequestOption) (*kernel.CredentialProvider, error) {
+			return &kernel.CredentialProvider{ID: "cp_2"}, nil
+		},
+		TestFunc: func(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.CredentialProviderTestResult, error) {
+			return &kernel.CredentialProviderTestResult{Error: "forbidden"}, nil
+		},
+		DeleteFunc: func(ctx context.Context, id string, opts ...option.RequestOption) error {
+			deleted = id
+			return nil
+		},
+		UpdateFunc: func(ctx context.Context, id string, body kernel.CredentialProviderUpdateParams, opts ...option.RequestOption) (*kernel.CredentialProvider, error) {
+			return nil, fmt.Errorf("unexpected update")
+		},
+	}
+	p := &scriptedPrompter{t: t, texts: []string{""}, secrets: []string{"ops_bad"}, confirms: []bool{false, true}}
+
+	c := CredentialProvidersCmd{providers: fake}
+	err := c.Setup(context.Background(), p)
+	assert.ErrorContains(t, err, "connection test failed")
+	assert.Equal(t, "cp_2", deleted)
+}
@@ -12,10 +12,17 @@ import (
 
 // FakeCredentialProvidersService is a configurable fake implementing CredentialProvidersService.
 type FakeCredentialProvidersService struct {
-	ListFunc func(ctx context.Context, query kernel.CredentialProviderListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.CredentialProvider], error)
+	NewFunc    func(ctx context.Context, body kernel.CredentialProviderNewParams, opts ...option.RequestOption) (*kernel.CredentialProvider, error)
+	UpdateFunc func(ctx context.Context, id string, body kernel.CredentialProviderUpdateParams, opts ...option.RequestOption) (*kernel.CredentialProvider, error)
+	ListFunc   func(ctx context.Context, query kernel.CredentialProviderListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.CredentialProvider], error)
+	DeleteFunc func(ctx context.Context, id string, opts ...option.RequestOption) error
+	TestFunc   func(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.CredentialProviderTestResult, error)
 }
 
 func (f *FakeCredentialProvidersService) New(ctx context.Context, body kernel.CredentialProviderNewParams, opts ...option.RequestOption) (*kernel.CredentialProvider, error) {
+	if f.NewFunc != nil {
+		return f.NewFunc(ctx, body, opts...)
+	}
 	return &kernel.CredentialProvider{}, nil
 }
 
@@ -24,6 +31,9 @@ func (f *FakeCredentialProvidersService) Get(ctx context.Context, id string, opt
 }
 
 func (f *FakeCredentialProvidersService) Update(ctx context.Context, id string, body kernel.CredentialProviderUpdateParams, opts ...option.RequestOption) (*kernel.CredentialProvider, error) {
+	if f.UpdateFunc != nil {
+		return f.UpdateFunc(ctx, id, body, opts...)
+	}
 	return &kernel.CredentialProvider{}, nil
 }
 
@@ -35,10 +45,16 @@ func (f *FakeCredentialProvidersService) List(ctx context.Context, query kernel.
 }
 
 func (f *FakeCredentialProvidersService) Delete(ctx context.Context, id string, opts ...option.RequestOption) error {
+	if f.DeleteFunc != nil {
+		return f.DeleteFunc(ctx, id, opts...)
+	}
 	return nil
 }
 
 func (f *FakeCredentialProvidersService) Test(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.CredentialProviderTestResult, error) {
+	if f.TestFunc != nil {
+		return f.TestFunc(ctx, id, opts...)
+	}
 	return &kernel.CredentialProviderTestResult{}, nil
 }
 
@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kernel/cli/pkg/table"
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// otpauthKey is a TOTP key from an otpauth:// provisioning URI.
+type otpauthKey struct {
+	Secret    string
+	Issuer    string
+	Account   string
+	Algorithm string
+	Digits    int
+	Period    int
+}
+
+// standard reports whether the key uses the parameters nearly every site
+// and authenticator assume: SHA1, 6 digits, 30 seconds.
+func (k otpauthKey) standard() bool {
+	return k.Algorithm == "SHA1" && k.Digits == 6 && k.Period == 30
+}
+
+// parseOTPAuthURI parses a Key URI as used in 2FA provisioning QR codes:
+// otpauth://totp/Issuer:account?secret=BASE32&issuer=Issuer
+func parseOTPAuthURI(raw string) (otpauthKey, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || !strings.EqualFold(u.Scheme, "otpauth") {
+		return otpauthKey{}, fmt.Errorf("not an otpauth:// URI")
+	}
+	if !strings.EqualFold(u.Host, "totp") {
+		return otpauthKey{}, fmt.Errorf("unsupported OTP type %q: only totp is supported", u.Host)
+	}
+
+	q := u.Query()
+	key := otpauthKey{
+		Secret:    normalizeTotpSecret(q.Get("secret")),
+		Issuer:    q.Get("issuer"),
+		Algorithm: strings.ToUpper(q.Get("algorithm")),
+		Digits:    6,
+		Period:    30,
+	}
+	if key.Secret == "" {
+		return otpauthKey{}, fmt.Errorf("otpauth URI has no secret")
+	}
+	if _, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(key.Secret); err != nil {
+		return otpauthKey{}, fmt.Errorf("otpauth secret is not valid base32")
+	}
+
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		key.Account = strings.TrimSpace(account)
+		if key.Issuer == "" {
+			key.Issuer = issuer
+		}
+	} else {
+		key.Account = label
+	}
+
+	switch key.Algorithm {
+	case "":
+		key.Algorithm = "SHA1"
+	case "SHA1", "SHA256", "SHA512":
+	default:
+		return otpauthKey{}, fmt.Errorf("unsupported TOTP algorithm %q", key.Algorithm)
+	}
+	if v := q.Get("digits"); v != "" {
+		if key.Digits, err = strconv.Atoi(v); err != nil || key.Digits < 6 || key.Digits > 8 {
+			return otpauthKey{}, fmt.Errorf("invalid TOTP digits %q", v)
+		}
+	}
+	if v := q.Get("period"); v != "" {
+		if key.Period, err = strconv.Atoi(v); err != nil || key.Period <= 0 {
+			return otpauthKey{}, fmt.Errorf("invalid TOTP period %q", v)
+		}
+	}
+	return key, nil
+}
+
+// normalizeTotpSecret uppercases a base32 secret and strips the spaces and
+// padding that sites often show it with.
+func normalizeTotpSecret(s string) string {
+	s = strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+	return strings.TrimRight(s, "=")
+}
+
+// totpKeyFromFlags reads --totp-uri. It returns nil when it isn't set.
+func totpKeyFromFlags(cmd *cobra.Command) (*otpauthKey, error) {
+	uri, _ := cmd.Flags().GetString("totp-uri")
+	if uri == "" {
+		return nil, nil
+	}
+	key, err := parseOTPAuthURI(uri)
+	if err != nil {
+		return nil, fmt.Errorf("--totp-uri: %w", err)
+	}
+	return &key, nil
+}
+
+// addTotpKeyFlags registers --totp-uri as an alternative to --totp-secret.
+func addTotpKeyFlags(cmd *cobra.Command) {
+	cmd.Flags().String("totp-uri", "", "otpauth://totp/... provisioning URI to take the TOTP secret from (the text of a 2FA setup QR code; decode an image with a QR reader such as zbarimg -q --raw)")
+	cmd.MarkFlagsMutuallyExclusive("totp-secret", "totp-uri")
+}
+
+// code returns the key's TOTP code at t (RFC 6238).
+func (k otpauthKey) code(t time.Time) (string, error) {
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(k.Secret)
+	if err != nil {
+		return "", err
+	}
+	var newHash func() hash.Hash
+	switch k.Algorithm {
+	case "SHA256":
+		newHash = sha256.New
+	case "SHA512":
+		newHash = sha512.New
+	default:
+		newHash = sha1.New
+	}
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(t.Unix()/int64(k.Period)))
+	mac := hmac.New(newHash, secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0xf
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < k.Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", k.Digits, value%mod), nil
+}
+
+// matches reports whether code is the key's code at t or one step either
+// side of it, allowing for clock skew.
+func (k otpauthKey) matches(code string, t time.Time) bool {
+	step := time.Duration(k.Period) * time.Second
+	for _, at := range []time.Time{t, t.Add(-step), t.Add(step)} {
+		if c, err := k.code(at); err == nil && c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// warnNonstandardTotp flags keys whose codes may not match what Kernel
+// generates, since only the secret is stored.
+func warnNonstandardTotp(key otpauthKey, output string) {
+	if key.standard() || output == "json" {
+		return
+	}
+	pterm.Warning.Printf("This key uses %s, %d digits and a %ds period; only the secret is stored, so generated codes may not match the site's.\n",
+		key.Algorithm, key.Digits, key.Period)
+}
+
+// verifyTotp checks that the code Kernel generates for a stored secret
+// matches the one computed locally from the provisioning key, and prints it.
+// apiCode is the code returned with the create or update response, if any.
+func (c CredentialsCmd) verifyTotp(ctx context.Context, id string, key otpauthKey, apiCode string) {
+	if apiCode == "" {
+		resp, err := c.credentials.TotpCode(ctx, id)
+		if err != nil {
+			pterm.Warning.Printf("Could not fetch a TOTP code to verify the secret: %v\n", util.CleanedUpSdkError{Err: err})
+			return
+		}
+		apiCode = resp.Code
+	}
+	if key.matches(apiCode, time.Now()) {
+		pterm.Success.Printf("TOTP secret verified: current code %s matches the provisioning key\n", apiCode)
+		return
+	}
+	local, _ := key.code(time.Now())
+	pterm.Warning.Printf("Kernel's current TOTP code (%s) does not match the provisioning key's (%s)\n", apiCode, local)
+}
+
+// copyTotpCode puts code on the clipboard. Failing to copy only warns, since
+// the code is shown anyway.
+func (c CredentialsCmd) copyTotpCode(code, output string) bool {
+	copyFn := c.clipboard
+	if copyFn == nil {
+		copyFn = util.CopyToClipboard
+	}
+	if err := copyFn(code); err != nil {
+		pterm.Warning.Printf("Could not copy the TOTP code: %v\n", err)
+		return false
+	}
+	if output != "json" {
+		pterm.Debug.Println("TOTP code copied to clipboard")
+	}
+	return true
+}
+
+// watchTotpCode shows a credential's current TOTP code with a countdown to
+// the end of its window, fetching the next code once it expires.
+func (c CredentialsCmd) watchTotpCode(ctx context.Context, in CredentialsTotpCodeInput) error {
+	if in.Interval <= 0 {
+		in.Interval = time.Second
+	}
+	jsonOutput := in.Output == "json"
+
+	var area *pterm.AreaPrinter
+	if !jsonOutput {
+		pterm.Info.Printf("Watching TOTP code for %s (Ctrl+C to stop)...\n", in.Identifier)
+		if table.IsStdoutTTY() {
+			area, _ = pterm.DefaultArea.Start()
+			defer func() { _ = area.Stop() }()
+		}
+	}
+
+	copyCodes := in.Copy
+	var current *kernel.CredentialTotpCodeResponse
+	for {
+		now := time.Now()
+		if current == nil || !now.Before(current.ExpiresAt) {
+			resp, err := c.credentials.TotpCode(ctx, in.Identifier)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return util.CleanedUpSdkError{Err: err}
+			}
+			if current == nil || resp.Code != current.Code {
+				// Stop copying after a failure rather than warning every window.
+				if copyCodes {
+					copyCodes = c.copyTotpCode(resp.Code, in.Output)
+				}
+				switch {
+				case jsonOutput:
+					_ = util.PrintJSONLine(resp)
+				case area == nil:
+					pterm.Printf("%s (expires %s)\n", resp.Code, util.FormatLocal(resp.ExpiresAt))
+				}
+			}
+			current = resp
+		}
+		if area != nil {
+			area.Update(renderTotpCountdown(current.Code, current.ExpiresAt.Sub(now), in.Copy && copyCodes))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(in.Interval):
+		}
+	}
+}
+
+// renderTotpCountdown draws a code and a bar showing how much of its window
+// is left.
+func renderTotpCountdown(code string, left time.Duration, copied bool) string {
+	const width = 30
+	secs := int(left.Round(time.Second) / time.Second)
+	if secs < 0 {
+		secs = 0
+	}
+	filled := min(secs, width)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+
+	style := pterm.FgGreen
+	if secs <= 5 {
+		style = pterm.FgRed
+	} else if secs <= 10 {
+		style = pterm.FgYellow
+	}
+	line := fmt.Sprintf("%s  %s %2ds", pterm.Bold.Sprint(code), style.Sprint(bar), secs)
+	if copied {
+		line += pterm.Gray("  (copied)")
+	}
+	return line
+}
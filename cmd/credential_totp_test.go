@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOTPAuthURI(t *testing.T) {
+	key, err := parseOTPAuthURI("otpauth://totp/ACME%20Co:jane@acme.com?secret=jbsw%20y3dp%20ehpk%203pxp&period=60")
+	require.NoError(t, err)
+	assert.Equal(t, otpauthKey{
+		Secret:    "JBSWY3DPEHPK3PXP",
+		Issuer:    "ACME Co",
+		Account:   "jane@acme.com",
+		Algorithm: "SHA1",
+		Digits:    6,
+		Period:    60,
+	}, key)
+	assert.False(t, key.standard())
+
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{"https://example.com/?secret=JBSWY3DPEHPK3PXP", "not an otpauth:// URI"},
+		{"otpauth://hotp/ACME?secret=JBSWY3DPEHPK3PXP&counter=1", "only totp is supported"},
+		{"otpauth://totp/ACME", "has no secret"},
+		{"otpauth://totp/ACME?secret=not-base32!", "not valid base32"},
+		{"otpauth://totp/ACME?secret=JBSWY3DPEHPK3PXP&algorithm=MD5", `unsupported TOTP algorithm "MD5"`},
+		{"otpauth://totp/ACME?secret=JBSWY3DPEHPK3PXP&digits=4", `invalid TOTP digits "4"`},
+	}
+	for _, tt := range tests {
+		_, err := parseOTPAuthURI(tt.uri)
+		require.Error(t, err, tt.uri)
+		assert.Contains(t, err.Error(), tt.want)
+	}
+}
+
+func TestOTPAuthKeyCode_RFC6238(t *testing.T) {
+	// Test vectors from RFC 6238 appendix B (SHA1 seed "12345678901234567890").
+	key := otpauthKey{Secret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", Algorithm: "SHA1", Digits: 8, Period: 30}
+	for unix, want := range map[int64]string{59: "94287082", 1111111109: "07081804", 2000000000: "69279037"} {
+		got, err := key.code(time.Unix(unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, want, got, "T=%d", unix)
+	}
+	assert.True(t, key.matches("94287082", time.Unix(89, 0)), "previous step allowed for clock skew")
+	assert.False(t, key.matches("94287082", time.Unix(200, 0)))
+}
+
+func TestCredentialsCreate_VerifiesTotpKey(t *testing.T) {
+	key, err := parseOTPAuthURI("otpauth://totp/ACME:jane?secret=JBSWY3DPEHPK3PXP&issuer=ACME")
+	require.NoError(t, err)
+	current, err := key.code(time.Now())
+	require.NoError(t, err)
+
+	var sentSecret string
+	fake := &FakeCredentialsService{
+		NewFunc: func(ctx context.Context, body kernel.CredentialNewParams, opts ...option.RequestOption) (*kernel.Credential, error) {
+			sentSecret = body.CreateCredentialRequest.TotpSecret.Value
+			return &kernel.Credential{ID: "cred_1", Name: "acme", HasTotpSecret: true}, nil
+		},
+		TotpCodeFunc: func(ctx context.Context, idOrName string, opts ...option.RequestOption) (*kernel.CredentialTotpCodeResponse, error) {
+			assert.Equal(t, "cred_1", idOrName)
+			return &kernel.CredentialTotpCodeResponse{Code: current}, nil
+		},
+	}
+	c := CredentialsCmd{credentials: fake}
+
+	setupStdoutCapture(t)
+	err = c.Create(context.Background(), CredentialsCreateInput{
+		Name:    "acme",
+		Domain:  "acme.com",
+		Values:  map[string]string{"username": "jane", "password": "pw"},
+		TotpKey: &key,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", sentSecret)
+	assert.Contains(t, outBuf.String(), "TOTP secret verified: current code "+current)
+}
+
+func TestCredentialsTotpCode_WatchRefreshesExpiredCodes(t *testing.T) {
+	setupStdoutCapture(t)
+
+	codes := []string{"111111", "222222", "333333"}
+	var fetches int
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fake := &FakeCredentialsService{
+		TotpCodeFunc: func(ctx context.Context, idOrName string, opts ...option.RequestOption) (*kernel.CredentialTotpCodeResponse, error) {
+			code := codes[fetches]
+			fetches++
+			if fetches == len(codes) {
+				cancel()
+			}
+			// Every code is already expired, so each tick fetches the next.
+			return &kernel.CredentialTotpCodeResponse{Code: code, ExpiresAt: time.Now().Add(-time.Second)}, nil
+		},
+	}
+	var copied []string
+	c := CredentialsCmd{credentials: fake, clipboard: func(s string) error {
+		copied = append(copied, s)
+		return nil
+	}}
+
+	err := c.TotpCode(ctx, CredentialsTotpCodeInput{Identifier: "acme", Watch: true, Copy: true, Interval: time.Millisecond})
+	require.NoError(t, err)
+	assert.Equal(t, codes, copied)
+	out := outBuf.String()
+	for _, code := range codes {
+		assert.Contains(t, out, code)
+	}
+}
+
+func TestCredentialsTotpCode_CopyFailureOnlyWarns(t *testing.T) {
+	setupStdoutCapture(t)
+
+	fake := &FakeCredentialsService{
+		TotpCodeFunc: func(ctx context.Context, idOrName string, opts ...option.RequestOption) (*kernel.CredentialTotpCodeResponse, error) {
+			return &kernel.CredentialTotpCodeResponse{Code: "123456", ExpiresAt: time.Now().Add(20 * time.Second)}, nil
+		},
+	}
+	c := CredentialsCmd{credentials: fake, clipboard: func(string) error { return util.ErrNoClipboard }}
+
+	require.NoError(t, c.TotpCode(context.Background(), CredentialsTotpCodeInput{Identifier: "acme", Copy: true}))
+	out := outBuf.String()
+	assert.Contains(t, out, "123456")
+	assert.Contains(t, out, "Could not copy the TOTP code")
+}
+
+func TestRenderTotpCountdown(t *testing.T) {
+	line := pterm.RemoveColorFromString(renderTotpCountdown("123456", 12*time.Second, true))
+	assert.Contains(t, line, "123456")
+	assert.Contains(t, line, strings.Repeat("█", 12)+strings.Repeat("░", 18))
+	assert.Contains(t, line, "12s")
+	assert.Contains(t, line, "(copied)")
+
+	line = pterm.RemoveColorFromString(renderTotpCountdown("123456", -time.Second, false))
+	assert.Contains(t, line, strings.Repeat("░", 30)+"  0s")
+	assert.NotContains(t, line, "copied")
+}
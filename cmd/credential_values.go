@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// credentialValueSources collects credential field values from every place
+// they can come from. Only a literal --value puts the secret itself on the
+// command line; the others, and --value secret references such as
+// vault:kv/data/github#password, keep it out of shell history and ps.
+type credentialValueSources struct {
+	// Pairs are --value name=value pairs.
+	Pairs []string
+	// EnvPairs are --value-env name=ENV_VAR pairs.
+	EnvPairs []string
+	// Prompt lists fields to ask for interactively.
+	Prompt []string
+	// Stdin, when set, holds a JSON object of name to value.
+	Stdin io.Reader
+	// NoTemplate sends --value values as given, without expanding template
+	// functions.
+	NoTemplate bool
+}
+
+func addCredentialValueFlags(cmd *cobra.Command, valueUsage string) {
+	cmd.Flags().StringArray("value", []string{}, valueUsage+"; a value may be a secret reference: "+strings.Join(util.SecretSchemes(), ":, ")+":")
+	cmd.Flags().StringArray("value-env", []string{}, "Field name=ENV_VAR pair: read the value from an environment variable (repeatable)")
+	cmd.Flags().Bool("values-from-stdin", false, `Read field values from stdin as a JSON object, e.g. {"username":"me","password":"..."}`)
+	cmd.Flags().StringArray("prompt", []string{}, "Field name to prompt for with masked input (repeatable)")
+	addNoTemplateFlag(cmd, "--value values")
+	cmd.MarkFlagsMutuallyExclusive("values-from-stdin", "prompt")
+}
+
+// credentialValuesFromFlags resolves the flags added by addCredentialValueFlags.
+func credentialValuesFromFlags(cmd *cobra.Command) (map[string]string, error) {
+	var src credentialValueSources
+	src.Pairs, _ = cmd.Flags().GetStringArray("value")
+	src.EnvPairs, _ = cmd.Flags().GetStringArray("value-env")
+	src.Prompt, _ = cmd.Flags().GetStringArray("prompt")
+	src.NoTemplate, _ = cmd.Flags().GetBool("no-template")
+	if fromStdin, _ := cmd.Flags().GetBool("values-from-stdin"); fromStdin {
+		src.Stdin = cmd.InOrStdin()
+	}
+	return src.resolve(cmd.Context(), promptCredentialValue)
+}
+
+// secretFlag reads a string flag that may hold a secret reference (see
+// util.ResolveSecret) and returns the resolved secret.
+func secretFlag(cmd *cobra.Command, name string) (string, error) {
+	raw, _ := cmd.Flags().GetString(name)
+	value, err := util.ResolveSecret(cmd.Context(), raw)
+	if err != nil {
+		return "", fmt.Errorf("--%s: %w", name, err)
+	}
+	return value, nil
+}
+
+// secretReferenceUsage is appended to the help of flags that accept secret
+// references.
+var secretReferenceUsage = " (or a secret reference: " + strings.Join(util.SecretSchemes(), ":, ") + ":)"
+
+// resolve merges every source into one map. A field may be set by only one
+// source, so a typo can't silently override a value.
+func (s credentialValueSources) resolve(ctx context.Context, prompt func(field string) (string, error)) (map[string]string, error) {
+	values := make(map[string]string)
+	set := func(field, value, source string) error {
+		if field == "" {
+			return fmt.Errorf("%s: field name is empty", source)
+		}
+		if _, dup := values[field]; dup {
+			return fmt.Errorf("field %q is set more than once", field)
+		}
+		values[field] = value
+		return nil
+	}
+
+	for _, pair := range s.Pairs {
+		field, raw, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid value format: %s (expected key=value)", pair)
+		}
+		value, err := util.ResolveSecret(ctx, raw)
+		if err == nil && value == raw && !s.NoTemplate {
+			value, err = util.ExpandTemplate(raw, "")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("value %s: %w", field, err)
+		}
+		if err := set(field, value, "--value"); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, pair := range s.EnvPairs {
+		field, envVar, ok := strings.Cut(pair, "=")
+		if !ok || envVar == "" {
+			return nil, fmt.Errorf("invalid --value-env format: %s (expected key=ENV_VAR)", pair)
+		}
+		value, ok := os.LookupEnv(envVar)
+		if !ok {
+			return nil, fmt.Errorf("--value-env %s: environment variable %s is not set", field, envVar)
+		}
+		if err := set(field, value, "--value-env"); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.Stdin != nil {
+		var fromStdin map[string]string
+		if err := json.NewDecoder(s.Stdin).Decode(&fromStdin); err != nil {
+			return nil, fmt.Errorf("--values-from-stdin: expected a JSON object of string values: %w", err)
+		}
+		fields := make([]string, 0, len(fromStdin))
+		for field := range fromStdin {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			if err := set(field, fromStdin[field], "--values-from-stdin"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, field := range s.Prompt {
+		if _, dup := values[field]; dup {
+			return nil, fmt.Errorf("field %q is set more than once", field)
+		}
+		value, err := prompt(field)
+		if err != nil {
+			return nil, fmt.Errorf("--prompt %s: %w", field, err)
+		}
+		if err := set(field, value, "--prompt"); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func promptCredentialValue(field string) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("stdin is not a terminal; use --value-env or --values-from-stdin instead")
+	}
+	return pterm.DefaultInteractiveTextInput.WithMask("*").Show(field)
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialValueSources_Resolve(t *testing.T) {
+	t.Setenv("SITE_PASSWORD", "from-env")
+	var prompted []string
+	prompt := func(field string) (string, error) {
+		prompted = append(prompted, field)
+		return "typed-" + field, nil
+	}
+
+	values, err := credentialValueSources{
+		Pairs:    []string{"username=me"},
+		EnvPairs: []string{"password=SITE_PASSWORD"},
+		Prompt:   []string{"pin"},
+		Stdin:    strings.NewReader(`{"email":"me@example.com"}`),
+	}.resolve(context.Background(), prompt)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"username": "me",
+		"password": "from-env",
+		"email":    "me@example.com",
+		"pin":      "typed-pin",
+	}, values)
+	assert.Equal(t, []string{"pin"}, prompted)
+}
+
+func TestCredentialValueSources_ResolvesSecretReferences(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pw")
+	require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0o600))
+	t.Setenv("SITE_USER", "me")
+
+	values, err := credentialValueSources{
+		Pairs: []string{"password=file:" + path, "username=env:SITE_USER", "note=literal:env:SITE_USER"},
+	}.resolve(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"password": "from-file",
+		"username": "me",
+		"note":     "env:SITE_USER",
+	}, values)
+
+	_, err = credentialValueSources{Pairs: []string{"password=env:SITE_UNSET"}}.resolve(context.Background(), nil)
+	assert.ErrorContains(t, err, "value password: env:SITE_UNSET")
+}
+
+func TestCredentialValueSources_NoTemplate(t *testing.T) {
+	values, err := credentialValueSources{Pairs: []string{"greeting=Hi {{name}}"}, NoTemplate: true}.resolve(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Hi {{name}}", values["greeting"])
+
+	_, err = credentialValueSources{Pairs: []string{"greeting=Hi {{name}}"}}.resolve(context.Background(), nil)
+	assert.ErrorContains(t, err, `function "name" not defined`)
+}
+
+func TestCredentialValueSources_ResolveErrors(t *testing.T) {
+	noPrompt := func(string) (string, error) { return "", errors.New("no terminal") }
+
+	tests := []struct {
+		name string
+		src  credentialValueSources
+		want string
+	}{
+		{"bad pair", credentialValueSources{Pairs: []string{"username"}}, "expected key=value"},
+		{"bad env pair", credentialValueSources{EnvPairs: []string{"password"}}, "expected key=ENV_VAR"},
+		{"unset env", credentialValueSources{EnvPairs: []string{"password=KERNEL_TEST_UNSET_VAR"}}, "KERNEL_TEST_UNSET_VAR is not set"},
+		{"bad stdin", credentialValueSources{Stdin: strings.NewReader(`["a"]`)}, "expected a JSON object"},
+		{"duplicate", credentialValueSources{Pairs: []string{"password=a"}, Stdin: strings.NewReader(`{"password":"b"}`)}, `field "password" is set more than once`},
+		{"prompt fails", credentialValueSources{Prompt: []string{"password"}}, "--prompt password: no terminal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.src.resolve(context.Background(), noPrompt)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
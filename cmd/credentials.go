@@ -3,7 +3,10 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"strings"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/kernel/cli/pkg/util"
 	"github.com/kernel/kernel-go-sdk"
@@ -26,6 +29,10 @@ type CredentialsService interface {
 // CredentialsCmd handles credential operations independent of cobra.
 type CredentialsCmd struct {
 	credentials CredentialsService
+	// connections is optional; it is only needed to find a credential's usage.
+	connections AuthConnectionService
+	// clipboard defaults to util.CopyToClipboard; tests replace it.
+	clipboard func(string) error
 }
 
 type CredentialsListInput struct {
@@ -46,6 +53,7 @@ type CredentialsCreateInput struct {
 	Values      map[string]string
 	SSOProvider string
 	TotpSecret  string
+	TotpKey     *otpauthKey
 	Output      string
 }
 
@@ -54,6 +62,7 @@ type CredentialsUpdateInput struct {
 	Name        string
 	SSOProvider string
 	TotpSecret  string
+	TotpKey     *otpauthKey
 	Values      map[string]string
 	Output      string
 }
@@ -65,7 +74,11 @@ type CredentialsDeleteInput struct {
 
 type CredentialsTotpCodeInput struct {
 	Identifier string
-	Output     string
+	Watch      bool
+	Copy       bool
+	// Interval is how often --watch redraws the countdown.
+	Interval time.Duration
+	Output   string
 }
 
 func (c CredentialsCmd) List(ctx context.Context, in CredentialsListInput) error {
@@ -96,11 +109,11 @@ func (c CredentialsCmd) List(ctx context.Context, in CredentialsListInput) error
 
 	if in.Output == "json" {
 		if len(credentials) == 0 {
-			fmt.Println("[]")
-			return nil
+			return util.PrintJSON([]any{})
 		}
 		return util.PrintPrettyJSONSlice(credentials)
 	}
+	util.WarnDecodeDrift(credentials)
 
 	if len(credentials) == 0 {
 		pterm.Info.Println("No credentials found")
@@ -144,6 +157,7 @@ func (c CredentialsCmd) Get(ctx context.Context, in CredentialsGetInput) error {
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(cred)
 	}
+	util.WarnDecodeDrift(cred)
 
 	ssoProvider := cred.SSOProvider
 	if ssoProvider == "" {
@@ -181,7 +195,7 @@ func (c CredentialsCmd) Create(ctx context.Context, in CredentialsCreateInput) e
 		return fmt.Errorf("--domain is required")
 	}
 	if len(in.Values) == 0 {
-		return fmt.Errorf("at least one --value is required")
+		return fmt.Errorf("at least one of --value, --value-env, --values-from-stdin or --prompt is required")
 	}
 
 	params := kernel.CredentialNewParams{
@@ -194,6 +208,10 @@ func (c CredentialsCmd) Create(ctx context.Context, in CredentialsCreateInput) e
 	if in.SSOProvider != "" {
 		params.CreateCredentialRequest.SSOProvider = kernel.Opt(in.SSOProvider)
 	}
+	if in.TotpKey != nil {
+		in.TotpSecret = in.TotpKey.Secret
+		warnNonstandardTotp(*in.TotpKey, in.Output)
+	}
 	if in.TotpSecret != "" {
 		params.CreateCredentialRequest.TotpSecret = kernel.Opt(in.TotpSecret)
 	}
@@ -210,6 +228,7 @@ func (c CredentialsCmd) Create(ctx context.Context, in CredentialsCreateInput) e
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(cred)
 	}
+	util.WarnDecodeDrift(cred)
 
 	pterm.Success.Printf("Created credential: %s\n", cred.ID)
 
@@ -237,6 +256,9 @@ func (c CredentialsCmd) Create(ctx context.Context, in CredentialsCreateInput) e
 	if cred.TotpCode != "" {
 		pterm.Info.Printf("Initial TOTP Code: %s (expires: %s)\n", cred.TotpCode, util.FormatLocal(cred.TotpCodeExpiresAt))
 	}
+	if in.TotpKey != nil {
+		c.verifyTotp(ctx, cred.ID, *in.TotpKey, cred.TotpCode)
+	}
 
 	return nil
 }
@@ -255,6 +277,10 @@ func (c CredentialsCmd) Update(ctx context.Context, in CredentialsUpdateInput) e
 	if in.SSOProvider != "" {
 		params.UpdateCredentialRequest.SSOProvider = kernel.Opt(in.SSOProvider)
 	}
+	if in.TotpKey != nil {
+		in.TotpSecret = in.TotpKey.Secret
+		warnNonstandardTotp(*in.TotpKey, in.Output)
+	}
 	if in.TotpSecret != "" {
 		params.UpdateCredentialRequest.TotpSecret = kernel.Opt(in.TotpSecret)
 	}
@@ -274,8 +300,12 @@ func (c CredentialsCmd) Update(ctx context.Context, in CredentialsUpdateInput) e
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(cred)
 	}
+	util.WarnDecodeDrift(cred)
 
 	pterm.Success.Printf("Updated credential: %s\n", cred.ID)
+	if in.TotpKey != nil {
+		c.verifyTotp(ctx, cred.ID, *in.TotpKey, cred.TotpCode)
+	}
 	return nil
 }
 
@@ -306,14 +336,22 @@ func (c CredentialsCmd) TotpCode(ctx context.Context, in CredentialsTotpCodeInpu
 		return err
 	}
 
+	if in.Watch {
+		return c.watchTotpCode(ctx, in)
+	}
+
 	resp, err := c.credentials.TotpCode(ctx, in.Identifier)
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
+	if in.Copy {
+		c.copyTotpCode(resp.Code, in.Output)
+	}
 
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(resp)
 	}
+	util.WarnDecodeDrift(resp)
 
 	tableData := pterm.TableData{
 		{"Property", "Value"},
@@ -360,8 +398,21 @@ Examples:
   # Create a credential with TOTP for 2FA
   kernel credentials create --name "my-2fa-site" --domain "example.com" --value "username=myuser" --value "password=mypass" --totp-secret "JBSWY3DPEHPK3PXP"
 
+  # Take the TOTP secret from the otpauth:// URI in the site's 2FA setup QR code
+  # (decode the image with a QR reader such as zbarimg)
+  kernel credentials create --name "my-2fa-site" --domain "example.com" --value "username=myuser" --prompt password --totp-uri "$(zbarimg -q --raw ~/Downloads/2fa-qr.png)"
+
   # Create a credential with SSO provider
-  kernel credentials create --name "google-sso" --domain "example.com" --value "email=user@gmail.com" --value "password=mypass" --sso-provider google`,
+  kernel credentials create --name "google-sso" --domain "example.com" --value "email=user@gmail.com" --value "password=mypass" --sso-provider google
+
+  # Keep the password out of shell history and ps
+  kernel credentials create --name "my-site" --domain "example.com" --value "username=myuser" --prompt password
+  kernel credentials create --name "my-site" --domain "example.com" --value "username=myuser" --value-env password=SITE_PASSWORD
+  jq -n --arg pw "$SITE_PASSWORD" '{username: "myuser", password: $pw}' | kernel credentials create --name "my-site" --domain "example.com" --values-from-stdin
+
+  # Pull values from a secret manager (env:, file:, op:, vault:, aws-sm:, keychain:)
+  kernel credentials create --name "github" --domain "github.com" --value "username=myuser" --value "password=vault:kv/data/github#password"
+  kernel credentials create --name "github" --domain "github.com" --value "username=myuser" --value "password=op:Private/GitHub/password"`,
 	Args: cobra.NoArgs,
 	RunE: runCredentialsCreate,
 }
@@ -369,9 +420,12 @@ Examples:
 var credentialsUpdateCmd = &cobra.Command{
 	Use:   "update <id-or-name>",
 	Short: "Update a credential",
-	Long:  `Update a credential's name, SSO provider, TOTP secret, or values.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runCredentialsUpdate,
+	Long: `Update a credential's name, SSO provider, TOTP secret, or values.
+
+The TOTP secret can also be taken from a provisioning URI (--totp-uri), the
+otpauth:// text of a 2FA setup QR code.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCredentialsUpdate,
 }
 
 var credentialsDeleteCmd = &cobra.Command{
@@ -384,9 +438,15 @@ var credentialsDeleteCmd = &cobra.Command{
 var credentialsTotpCodeCmd = &cobra.Command{
 	Use:   "totp-code <id-or-name>",
 	Short: "Get the current TOTP code for a credential",
-	Long:  `Returns the current 6-digit TOTP code for a credential with a configured totp_secret.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runCredentialsTotpCode,
+	Long: `Returns the current 6-digit TOTP code for a credential with a configured totp_secret.
+
+With --watch, the code is shown with a live countdown to the end of its window
+and refreshed when it expires. With --copy, each code is also placed on the
+clipboard.`,
+	Example: `  kernel credentials totp-code my-creds
+  kernel credentials totp-code my-creds --watch --copy`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCredentialsTotpCode,
 }
 
 func init() {
@@ -397,6 +457,10 @@ func init() {
 	credentialsCmd.AddCommand(credentialsDeleteCmd)
 	credentialsCmd.AddCommand(credentialsTotpCodeCmd)
 
+	for _, c := range []*cobra.Command{credentialsGetCmd, credentialsUpdateCmd, credentialsDeleteCmd, credentialsTotpCodeCmd} {
+		c.ValidArgsFunction = completeResourceArg("credential", completeCredential)
+	}
+
 	// List flags
 	addJSONOutputFlag(credentialsListCmd)
 	credentialsListCmd.Flags().String("domain", "", "Filter by domain")
@@ -410,9 +474,10 @@ func init() {
 	addJSONOutputFlag(credentialsCreateCmd)
 	credentialsCreateCmd.Flags().String("name", "", "Unique name for the credential (required)")
 	credentialsCreateCmd.Flags().String("domain", "", "Target domain this credential is for (required)")
-	credentialsCreateCmd.Flags().StringArray("value", []string{}, "Field name=value pair (repeatable, e.g., --value username=myuser --value password=mypass)")
+	addCredentialValueFlags(credentialsCreateCmd, "Field name=value pair (repeatable, e.g., --value username=myuser --value password=mypass)")
 	credentialsCreateCmd.Flags().String("sso-provider", "", "SSO provider (e.g., google, github, microsoft)")
-	credentialsCreateCmd.Flags().String("totp-secret", "", "Base32-encoded TOTP secret for 2FA")
+	credentialsCreateCmd.Flags().String("totp-secret", "", "Base32-encoded TOTP secret for 2FA"+secretReferenceUsage)
+	addTotpKeyFlags(credentialsCreateCmd)
 	_ = credentialsCreateCmd.MarkFlagRequired("name")
 	_ = credentialsCreateCmd.MarkFlagRequired("domain")
 
@@ -420,14 +485,17 @@ func init() {
 	addJSONOutputFlag(credentialsUpdateCmd)
 	credentialsUpdateCmd.Flags().String("name", "", "New name for the credential")
 	credentialsUpdateCmd.Flags().String("sso-provider", "", "SSO provider (set to empty string to remove)")
-	credentialsUpdateCmd.Flags().String("totp-secret", "", "Base32-encoded TOTP secret (set to empty string to remove)")
-	credentialsUpdateCmd.Flags().StringArray("value", []string{}, "Field name=value pair to update (repeatable)")
+	credentialsUpdateCmd.Flags().String("totp-secret", "", "Base32-encoded TOTP secret"+secretReferenceUsage+"; empty string removes it")
+	addTotpKeyFlags(credentialsUpdateCmd)
+	addCredentialValueFlags(credentialsUpdateCmd, "Field name=value pair to update (repeatable)")
 
 	// Delete flags
 	credentialsDeleteCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
 
 	// TOTP code flags
 	addJSONOutputFlag(credentialsTotpCodeCmd)
+	credentialsTotpCodeCmd.Flags().Bool("watch", false, "Keep showing the current code with a countdown, refreshing it as it expires (JSON: one line per code)")
+	credentialsTotpCodeCmd.Flags().Bool("copy", false, "Copy the code to the clipboard (every new code with --watch)")
 }
 
 func runCredentialsList(cmd *cobra.Command, args []string) error {
@@ -464,18 +532,18 @@ func runCredentialsCreate(cmd *cobra.Command, args []string) error {
 	output, _ := cmd.Flags().GetString("output")
 	name, _ := cmd.Flags().GetString("name")
 	domain, _ := cmd.Flags().GetString("domain")
-	valuePairs, _ := cmd.Flags().GetStringArray("value")
 	ssoProvider, _ := cmd.Flags().GetString("sso-provider")
-	totpSecret, _ := cmd.Flags().GetString("totp-secret")
-
-	// Parse value pairs into map
-	values := make(map[string]string)
-	for _, pair := range valuePairs {
-		parts := strings.SplitN(pair, "=", 2)
-		if len(parts) != 2 {
-			return fmt.Errorf("invalid value format: %s (expected key=value)", pair)
-		}
-		values[parts[0]] = parts[1]
+	totpSecret, err := secretFlag(cmd, "totp-secret")
+	if err != nil {
+		return err
+	}
+	totpKey, err := totpKeyFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	values, err := credentialValuesFromFlags(cmd)
+	if err != nil {
+		return err
 	}
 
 	svc := client.Credentials
@@ -486,6 +554,7 @@ func runCredentialsCreate(cmd *cobra.Command, args []string) error {
 		Values:      values,
 		SSOProvider: ssoProvider,
 		TotpSecret:  totpSecret,
+		TotpKey:     totpKey,
 		Output:      output,
 	})
 }
@@ -495,17 +564,17 @@ func runCredentialsUpdate(cmd *cobra.Command, args []string) error {
 	output, _ := cmd.Flags().GetString("output")
 	name, _ := cmd.Flags().GetString("name")
 	ssoProvider, _ := cmd.Flags().GetString("sso-provider")
-	totpSecret, _ := cmd.Flags().GetString("totp-secret")
-	valuePairs, _ := cmd.Flags().GetStringArray("value")
-
-	// Parse value pairs into map
-	values := make(map[string]string)
-	for _, pair := range valuePairs {
-		parts := strings.SplitN(pair, "=", 2)
-		if len(parts) != 2 {
-			return fmt.Errorf("invalid value format: %s (expected key=value)", pair)
-		}
-		values[parts[0]] = parts[1]
+	totpSecret, err := secretFlag(cmd, "totp-secret")
+	if err != nil {
+		return err
+	}
+	totpKey, err := totpKeyFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	values, err := credentialValuesFromFlags(cmd)
+	if err != nil {
+		return err
 	}
 
 	svc := client.Credentials
@@ -515,6 +584,7 @@ func runCredentialsUpdate(cmd *cobra.Command, args []string) error {
 		Name:        name,
 		SSOProvider: ssoProvider,
 		TotpSecret:  totpSecret,
+		TotpKey:     totpKey,
 		Values:      values,
 		Output:      output,
 	})
@@ -535,11 +605,22 @@ func runCredentialsDelete(cmd *cobra.Command, args []string) error {
 func runCredentialsTotpCode(cmd *cobra.Command, args []string) error {
 	client := getKernelClient(cmd)
 	output, _ := cmd.Flags().GetString("output")
+	watch, _ := cmd.Flags().GetBool("watch")
+	copyCode, _ := cmd.Flags().GetBool("copy")
+
+	ctx := cmd.Context()
+	if watch {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+	}
 
 	svc := client.Credentials
 	c := CredentialsCmd{credentials: &svc}
-	return c.TotpCode(cmd.Context(), CredentialsTotpCodeInput{
+	return c.TotpCode(ctx, CredentialsTotpCodeInput{
 		Identifier: args[0],
+		Watch:      watch,
+		Copy:       copyCode,
 		Output:     output,
 	})
 }
@@ -0,0 +1,406 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+const credentialsExportPageSize = 100
+
+// credentialRecord is one credential in an import or export file. Export
+// fills Values with empty strings for each stored field, since the API never
+// returns values or TOTP secrets; fill them in before importing.
+type credentialRecord struct {
+	Name        string            `json:"name"`
+	Domain      string            `json:"domain"`
+	SSOProvider string            `json:"sso_provider,omitempty"`
+	TotpSecret  string            `json:"totp_secret,omitempty"`
+	Values      map[string]string `json:"values"`
+	// HasTotpSecret is informational on export; import ignores it.
+	HasTotpSecret bool `json:"has_totp_secret,omitempty"`
+}
+
+// credentialImportRow is a record plus where it came from in the file.
+type credentialImportRow struct {
+	Row    int
+	Record credentialRecord
+}
+
+type credentialImportResult struct {
+	// Row is the CSV line number, or the 1-based position in a JSON array.
+	Row    int    `json:"row"`
+	Name   string `json:"name"`
+	Domain string `json:"domain"`
+	// Status is "created", "valid" (dry run) or "failed".
+	Status string `json:"status"`
+	ID     string `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type CredentialsImportInput struct {
+	File string
+	// Map renames CSV columns or JSON value keys, e.g. "User Name" -> username.
+	Map    map[string]string
+	DryRun bool
+	Output string
+}
+
+type CredentialsExportInput struct {
+	Domain string
+	Output string
+}
+
+// Import creates one credential per row of a CSV or JSON file. Every row is
+// attempted; failures are reported per row rather than stopping the import.
+func (c CredentialsCmd) Import(ctx context.Context, in CredentialsImportInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if in.File == "" {
+		return fmt.Errorf("--file is required")
+	}
+	rows, err := readCredentialImportFile(in.File, in.Map)
+	if err != nil {
+		return err
+	}
+
+	results := make([]credentialImportResult, 0, len(rows))
+	failed := 0
+	for _, row := range rows {
+		rec := row.Record
+		res := credentialImportResult{Row: row.Row, Name: rec.Name, Domain: rec.Domain}
+		if err := validateCredentialRecord(rec); err != nil {
+			res.Status, res.Error = "failed", err.Error()
+		} else if in.DryRun {
+			res.Status = "valid"
+		} else if cred, err := c.credentials.New(ctx, credentialNewParams(rec)); err != nil {
+			res.Status, res.Error = "failed", util.CleanedUpSdkError{Err: err}.Error()
+		} else {
+			res.Status, res.ID = "created", cred.ID
+		}
+		if res.Status == "failed" {
+			failed++
+		}
+		results = append(results, res)
+	}
+
+	if in.Output == "json" {
+		if err := util.PrintJSON(results); err != nil {
+			return err
+		}
+	} else {
+		rows := pterm.TableData{{"Row", "Name", "Domain", "Status", "Error"}}
+		for _, r := range results {
+			rows = append(rows, []string{fmt.Sprintf("%d", r.Row), util.FirstOrDash(r.Name), util.FirstOrDash(r.Domain), r.Status, util.FirstOrDash(r.Error)})
+		}
+		PrintTableNoPad(rows, true)
+		switch {
+		case in.DryRun && failed == 0:
+			pterm.Success.Printf("All %d credentials are valid (dry run; nothing was created)\n", len(results))
+		case !in.DryRun && failed == 0:
+			pterm.Success.Printf("Imported %d credentials\n", len(results))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d credentials failed to import", failed, len(results))
+	}
+	return nil
+}
+
+func credentialNewParams(rec credentialRecord) kernel.CredentialNewParams {
+	params := kernel.CredentialNewParams{
+		CreateCredentialRequest: kernel.CreateCredentialRequestParam{
+			Name:   rec.Name,
+			Domain: rec.Domain,
+			Values: rec.Values,
+		},
+	}
+	if rec.SSOProvider != "" {
+		params.CreateCredentialRequest.SSOProvider = kernel.Opt(rec.SSOProvider)
+	}
+	if rec.TotpSecret != "" {
+		params.CreateCredentialRequest.TotpSecret = kernel.Opt(rec.TotpSecret)
+	}
+	return params
+}
+
+func validateCredentialRecord(rec credentialRecord) error {
+	if rec.Name == "" {
+		return fmt.Errorf("name is empty")
+	}
+	if rec.Domain == "" {
+		return fmt.Errorf("domain is empty")
+	}
+	if len(rec.Values) == 0 {
+		return fmt.Errorf("no field values")
+	}
+	var empty []string
+	for field, value := range rec.Values {
+		if value == "" {
+			empty = append(empty, field)
+		}
+	}
+	if len(empty) > 0 {
+		sort.Strings(empty)
+		return fmt.Errorf("empty value for %s", strings.Join(empty, ", "))
+	}
+	return nil
+}
+
+// readCredentialImportFile reads a .csv or .json file, or JSON from stdin
+// when path is "-".
+func readCredentialImportFile(path string, mapping map[string]string) ([]credentialImportRow, error) {
+	var r io.Reader
+	if path == "-" {
+		r = os.Stdin
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		defer f.Close()
+		r = f
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return parseCredentialCSV(r, mapping)
+	}
+	return parseCredentialJSON(r, mapping)
+}
+
+// parseCredentialCSV reads a header row, then one credential per line. The
+// name, domain, sso_provider and totp_secret columns fill those fields; every
+// other non-empty column is a field value named after its (mapped) header.
+func parseCredentialCSV(r io.Reader, mapping map[string]string) ([]credentialImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read CSV header: %w", err)
+	}
+	columns := make([]string, len(header))
+	for i, h := range header {
+		h = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+		if mapped, ok := mapping[h]; ok {
+			h = mapped
+		}
+		columns[i] = h
+	}
+
+	var rows []credentialImportRow
+	for line := 2; ; line++ {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read CSV: %w", err)
+		}
+		rec := credentialRecord{Values: map[string]string{}}
+		for i, value := range fields {
+			if i >= len(columns) || columns[i] == "" {
+				continue
+			}
+			switch columns[i] {
+			case "name":
+				rec.Name = strings.TrimSpace(value)
+			case "domain":
+				rec.Domain = strings.TrimSpace(value)
+			case "sso_provider":
+				rec.SSOProvider = strings.TrimSpace(value)
+			case "totp_secret":
+				rec.TotpSecret = strings.TrimSpace(value)
+			default:
+				if value != "" {
+					rec.Values[columns[i]] = value
+				}
+			}
+		}
+		rows = append(rows, credentialImportRow{Row: line, Record: rec})
+	}
+	return rows, nil
+}
+
+// parseCredentialJSON reads an array of credential records, the format
+// export writes. mapping renames value keys.
+func parseCredentialJSON(r io.Reader, mapping map[string]string) ([]credentialImportRow, error) {
+	var records []credentialRecord
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, fmt.Errorf("expected a JSON array of credentials: %w", err)
+	}
+	rows := make([]credentialImportRow, 0, len(records))
+	for i, rec := range records {
+		if len(mapping) > 0 && len(rec.Values) > 0 {
+			renamed := make(map[string]string, len(rec.Values))
+			for field, value := range rec.Values {
+				if mapped, ok := mapping[field]; ok {
+					field = mapped
+				}
+				renamed[field] = value
+			}
+			rec.Values = renamed
+		}
+		rows = append(rows, credentialImportRow{Row: i + 1, Record: rec})
+	}
+	return rows, nil
+}
+
+// Export lists credentials in the import file format. Values and TOTP
+// secrets are write-only in the API, so each record carries its field names
+// with empty values to fill in.
+func (c CredentialsCmd) Export(ctx context.Context, in CredentialsExportInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+
+	var records []credentialRecord
+	for offset := int64(0); ; offset += credentialsExportPageSize {
+		params := kernel.CredentialListParams{Limit: kernel.Opt(int64(credentialsExportPageSize)), Offset: kernel.Opt(offset)}
+		if in.Domain != "" {
+			params.Domain = kernel.Opt(in.Domain)
+		}
+		page, err := c.credentials.List(ctx, params)
+		if err != nil {
+			return util.CleanedUpSdkError{Err: err}
+		}
+		if page == nil {
+			break
+		}
+		for _, listed := range page.Items {
+			// List responses omit value_keys; fetch each credential for them.
+			cred, err := c.credentials.Get(ctx, listed.ID)
+			if err != nil {
+				return util.CleanedUpSdkError{Err: err}
+			}
+			values := make(map[string]string, len(cred.ValueKeys))
+			for _, key := range cred.ValueKeys {
+				values[key] = ""
+			}
+			records = append(records, credentialRecord{
+				Name:          cred.Name,
+				Domain:        cred.Domain,
+				SSOProvider:   cred.SSOProvider,
+				Values:        values,
+				HasTotpSecret: cred.HasTotpSecret,
+			})
+		}
+		if len(page.Items) < credentialsExportPageSize {
+			break
+		}
+	}
+
+	if in.Output == "json" {
+		if records == nil {
+			records = []credentialRecord{}
+		}
+		if err := util.PrintJSON(records); err != nil {
+			return err
+		}
+		pterm.Info.WithWriter(os.Stderr).Println("Values and TOTP secrets are never returned by the API; fill them in before importing.")
+		return nil
+	}
+
+	if len(records) == 0 {
+		pterm.Info.Println("No credentials found")
+		return nil
+	}
+	rows := pterm.TableData{{"Name", "Domain", "Fields", "Has TOTP", "SSO Provider"}}
+	for _, rec := range records {
+		fields := make([]string, 0, len(rec.Values))
+		for field := range rec.Values {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		hasTOTP := "-"
+		if rec.HasTotpSecret {
+			hasTOTP = "Yes"
+		}
+		rows = append(rows, []string{rec.Name, rec.Domain, util.JoinOrDash(fields...), hasTOTP, util.FirstOrDash(rec.SSOProvider)})
+	}
+	PrintTableNoPad(rows, true)
+	pterm.Info.Println("Use -o json to write an import file.")
+	return nil
+}
+
+var credentialsImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Create credentials in bulk from a CSV or JSON file",
+	Long: `Create one credential per row of a CSV or JSON file. Every row is attempted and
+reported on its own; the command fails if any row did.
+
+CSV files need a header row. The name, domain, sso_provider and totp_secret
+columns fill those fields, and every other column becomes a field value named
+after its header. Rename columns with --map, e.g. --map "User Name=username".
+
+JSON files are an array in the format 'kernel credentials export -o json' writes:
+  [{"name": "acme", "domain": "acme.com", "values": {"username": "me", "password": "..."}}]`,
+	Example: `  kernel credentials import --file creds.csv --map "Login=username" --map "Secret=password" --dry-run
+  kernel credentials import --file creds.json`,
+	Args: cobra.NoArgs,
+	RunE: runCredentialsImport,
+}
+
+var credentialsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export credentials in the import file format",
+	Long: `List credentials as an import file for another org or project. The API never
+returns stored values or TOTP secrets, so each credential is exported with its
+field names and empty values to fill in before running 'kernel credentials import'.`,
+	Example: `  kernel credentials export --domain example.com -o json > creds.json`,
+	Args:    cobra.NoArgs,
+	RunE:    runCredentialsExport,
+}
+
+func init() {
+	addJSONOutputFlag(credentialsImportCmd)
+	credentialsImportCmd.Flags().StringP("file", "f", "", "CSV or JSON file to import (use - for JSON on stdin) (required)")
+	credentialsImportCmd.Flags().StringArray("map", []string{}, "Rename a CSV column or JSON value key, as column=field (repeatable)")
+	credentialsImportCmd.Flags().Bool("dry-run", false, "Validate every row without creating anything")
+	_ = credentialsImportCmd.MarkFlagRequired("file")
+	credentialsCmd.AddCommand(credentialsImportCmd)
+
+	addJSONOutputFlag(credentialsExportCmd)
+	credentialsExportCmd.Flags().String("domain", "", "Only export credentials for this domain")
+	credentialsCmd.AddCommand(credentialsExportCmd)
+}
+
+func runCredentialsImport(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	output, _ := cmd.Flags().GetString("output")
+	file, _ := cmd.Flags().GetString("file")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	mapPairs, _ := cmd.Flags().GetStringArray("map")
+	mapping := make(map[string]string, len(mapPairs))
+	for _, pair := range mapPairs {
+		from, to, ok := strings.Cut(pair, "=")
+		if !ok || from == "" || to == "" {
+			return fmt.Errorf("invalid --map value %q (expected column=field)", pair)
+		}
+		mapping[from] = to
+	}
+
+	svc := client.Credentials
+	c := CredentialsCmd{credentials: &svc}
+	return c.Import(cmd.Context(), CredentialsImportInput{File: file, Map: mapping, DryRun: dryRun, Output: output})
+}
+
+func runCredentialsExport(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	output, _ := cmd.Flags().GetString("output")
+	domain, _ := cmd.Flags().GetString("domain")
+
+	svc := client.Credentials
+	c := CredentialsCmd{credentials: &svc}
+	return c.Export(cmd.Context(), CredentialsExportInput{Domain: domain, Output: output})
+}
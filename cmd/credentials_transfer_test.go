@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/kernel/kernel-go-sdk/packages/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// FakeCredentialsService implements CredentialsService for tests.
+type FakeCredentialsService struct {
+	NewFunc      func(ctx context.Context, body kernel.CredentialNewParams, opts ...option.RequestOption) (*kernel.Credential, error)
+	GetFunc      func(ctx context.Context, idOrName string, opts ...option.RequestOption) (*kernel.Credential, error)
+	ListFunc     func(ctx context.Context, query kernel.CredentialListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.Credential], error)
+	TotpCodeFunc func(ctx context.Context, idOrName string, opts ...option.RequestOption) (*kernel.CredentialTotpCodeResponse, error)
+}
+
+func (f *FakeCredentialsService) New(ctx context.Context, body kernel.CredentialNewParams, opts ...option.RequestOption) (*kernel.Credential, error) {
+	if f.NewFunc != nil {
+		return f.NewFunc(ctx, body, opts...)
+	}
+	return &kernel.Credential{ID: "cred_new", Name: body.CreateCredentialRequest.Name}, nil
+}
+
+func (f *FakeCredentialsService) Get(ctx context.Context, idOrName string, opts ...option.RequestOption) (*kernel.Credential, error) {
+	if f.GetFunc != nil {
+		return f.GetFunc(ctx, idOrName, opts...)
+	}
+	return &kernel.Credential{ID: idOrName}, nil
+}
+
+func (f *FakeCredentialsService) Update(ctx context.Context, idOrName string, body kernel.CredentialUpdateParams, opts ...option.RequestOption) (*kernel.Credential, error) {
+	return &kernel.Credential{ID: idOrName}, nil
+}
+
+func (f *FakeCredentialsService) List(ctx context.Context, query kernel.CredentialListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.Credential], error) {
+	if f.ListFunc != nil {
+		return f.ListFunc(ctx, query, opts...)
+	}
+	return &pagination.OffsetPagination[kernel.Credential]{Items: []kernel.Credential{}}, nil
+}
+
+func (f *FakeCredentialsService) Delete(ctx context.Context, idOrName string, opts ...option.RequestOption) error {
+	return nil
+}
+
+func (f *FakeCredentialsService) TotpCode(ctx context.Context, idOrName string, opts ...option.RequestOption) (*kernel.CredentialTotpCodeResponse, error) {
+	if f.TotpCodeFunc != nil {
+		return f.TotpCodeFunc(ctx, idOrName, opts...)
+	}
+	return &kernel.CredentialTotpCodeResponse{}, nil
+}
+
+func writeTempFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestParseCredentialCSV_MapsColumns(t *testing.T) {
+	csvData := "\ufeffname,domain,Login,Secret,totp_secret,notes\n" +
+		"acme,acme.com,me,hunter2,JBSWY3DPEHPK3PXP,\n" +
+		"globex,globex.com,you,,,\n"
+
+	rows, err := parseCredentialCSV(strings.NewReader(csvData), map[string]string{"Login": "username", "Secret": "password"})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	assert.Equal(t, 2, rows[0].Row)
+	assert.Equal(t, credentialRecord{
+		Name:       "acme",
+		Domain:     "acme.com",
+		TotpSecret: "JBSWY3DPEHPK3PXP",
+		Values:     map[string]string{"username": "me", "password": "hunter2"},
+	}, rows[0].Record)
+	assert.Equal(t, 3, rows[1].Row)
+	assert.Equal(t, map[string]string{"username": "you"}, rows[1].Record.Values)
+}
+
+func TestCredentialsImport_ReportsPerRowErrors(t *testing.T) {
+	path := writeTempFile(t, "creds.json", `[
+		{"name": "acme", "domain": "acme.com", "values": {"user": "me", "password": "pw"}},
+		{"name": "globex", "domain": "globex.com", "values": {"user": "you", "password": ""}},
+		{"name": "initech", "domain": "initech.com", "values": {"user": "them", "password": "pw"}}
+	]`)
+
+	var created []kernel.CredentialNewParams
+	fake := &FakeCredentialsService{
+		NewFunc: func(ctx context.Context, body kernel.CredentialNewParams, opts ...option.RequestOption) (*kernel.Credential, error) {
+			if body.CreateCredentialRequest.Name == "initech" {
+				return nil, errors.New("credential already exists")
+			}
+			created = append(created, body)
+			return &kernel.Credential{ID: "cred_" + body.CreateCredentialRequest.Name}, nil
+		},
+	}
+	c := CredentialsCmd{credentials: fake}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = c.Import(context.Background(), CredentialsImportInput{File: path, Map: map[string]string{"user": "username"}, Output: "json"})
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 of 3 credentials failed to import")
+
+	require.Len(t, created, 1)
+	assert.Equal(t, map[string]string{"username": "me", "password": "pw"}, created[0].CreateCredentialRequest.Values)
+
+	assert.Contains(t, out, `"status": "created"`)
+	assert.Contains(t, out, `"id": "cred_acme"`)
+	assert.Contains(t, out, `"error": "empty value for password"`)
+	assert.Contains(t, out, `"error": "credential already exists"`)
+}
+
+func TestCredentialsImport_DryRunCreatesNothing(t *testing.T) {
+	path := writeTempFile(t, "creds.csv", "name,domain,username,password\nacme,acme.com,me,pw\n")
+	fake := &FakeCredentialsService{
+		NewFunc: func(ctx context.Context, body kernel.CredentialNewParams, opts ...option.RequestOption) (*kernel.Credential, error) {
+			t.Fatal("dry run must not create credentials")
+			return nil, nil
+		},
+	}
+	c := CredentialsCmd{credentials: fake}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = c.Import(context.Background(), CredentialsImportInput{File: path, DryRun: true, Output: "json"})
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, `"status": "valid"`)
+}
+
+func TestCredentialsExport_WritesImportTemplate(t *testing.T) {
+	var gotDomain string
+	fake := &FakeCredentialsService{
+		ListFunc: func(ctx context.Context, query kernel.CredentialListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.Credential], error) {
+			gotDomain = query.Domain.Value
+			return &pagination.OffsetPagination[kernel.Credential]{Items: []kernel.Credential{{ID: "cred_1", Name: "acme"}}}, nil
+		},
+		GetFunc: func(ctx context.Context, idOrName string, opts ...option.RequestOption) (*kernel.Credential, error) {
+			assert.Equal(t, "cred_1", idOrName)
+			return &kernel.Credential{ID: "cred_1", Name: "acme", Domain: "acme.com", ValueKeys: []string{"username", "password"}, HasTotpSecret: true}, nil
+		},
+	}
+	c := CredentialsCmd{credentials: fake}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = c.Export(context.Background(), CredentialsExportInput{Domain: "acme.com", Output: "json"})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "acme.com", gotDomain)
+	assert.Contains(t, out, `"name": "acme"`)
+	assert.Contains(t, out, `"password": ""`)
+	assert.Contains(t, out, `"has_totp_secret": true`)
+
+	rows, err := parseCredentialJSON(strings.NewReader(out), nil)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "acme.com", rows[0].Record.Domain)
+}
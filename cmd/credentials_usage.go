@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// credentialUsageTimelineLimit is how many recent timeline events are read
+// per connection to find its last login attempt.
+const credentialUsageTimelineLimit = 20
+
+type CredentialsUsageInput struct {
+	Identifier string
+	Output     string
+}
+
+type credentialUsageConnection struct {
+	ID          string `json:"id"`
+	Domain      string `json:"domain"`
+	ProfileName string `json:"profile_name"`
+	Status      string `json:"status"`
+	// LastSuccessAt is the last time the connection logged in, from its
+	// last_auth_at or a successful login/reauth in its timeline.
+	LastSuccessAt     *time.Time `json:"last_success_at,omitempty"`
+	LastAttemptAt     *time.Time `json:"last_attempt_at,omitempty"`
+	LastAttemptStatus string     `json:"last_attempt_status,omitempty"`
+}
+
+type credentialUsage struct {
+	ID          string                      `json:"id"`
+	Name        string                      `json:"name"`
+	Domain      string                      `json:"domain"`
+	LastUsedAt  *time.Time                  `json:"last_used_at,omitempty"`
+	Connections []credentialUsageConnection `json:"connections"`
+}
+
+// Usage lists the managed auth connections that log in with a credential
+// and when it was last used successfully, so unused credentials can be
+// retired.
+func (c CredentialsCmd) Usage(ctx context.Context, in CredentialsUsageInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if c.connections == nil {
+		return fmt.Errorf("auth connection service not available")
+	}
+
+	cred, err := c.credentials.Get(ctx, in.Identifier)
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	conns, err := AuthConnectionCmd{svc: c.connections}.listLoginAllConnections(ctx, loginAllFilter{Status: "ANY"})
+	if err != nil {
+		return err
+	}
+
+	usage := credentialUsage{ID: cred.ID, Name: cred.Name, Domain: cred.Domain, Connections: []credentialUsageConnection{}}
+	for _, conn := range conns {
+		// Connections backed by an external provider name its item, not a
+		// Kernel credential
+		if conn.Credential.Provider != "" || conn.Credential.Name != cred.Name {
+			continue
+		}
+		u, err := c.connectionUsage(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if u.LastSuccessAt != nil && (usage.LastUsedAt == nil || u.LastSuccessAt.After(*usage.LastUsedAt)) {
+			usage.LastUsedAt = u.LastSuccessAt
+		}
+		usage.Connections = append(usage.Connections, u)
+	}
+
+	if in.Output == "json" {
+		return util.PrintJSON(usage)
+	}
+
+	if len(usage.Connections) == 0 {
+		pterm.Info.Printf("No auth connections use credential %s\n", cred.Name)
+		pterm.Info.Printf("Delete it with: kernel credentials delete %s\n", cred.Name)
+		return nil
+	}
+	tableData := pterm.TableData{{"Connection ID", "Domain", "Profile", "Status", "Last Success", "Last Attempt"}}
+	for _, u := range usage.Connections {
+		lastAttempt := "-"
+		if u.LastAttemptAt != nil {
+			lastAttempt = fmt.Sprintf("%s (%s)", util.FormatLocal(*u.LastAttemptAt), u.LastAttemptStatus)
+		}
+		tableData = append(tableData, []string{
+			u.ID,
+			u.Domain,
+			u.ProfileName,
+			u.Status,
+			formatOptionalTime(u.LastSuccessAt),
+			lastAttempt,
+		})
+	}
+	PrintTableNoPad(tableData, true)
+	if usage.LastUsedAt != nil {
+		pterm.Info.Printf("Last used successfully %s\n", util.FormatLocal(*usage.LastUsedAt))
+	} else {
+		pterm.Info.Println("Never used successfully by these connections")
+	}
+	return nil
+}
+
+// connectionUsage reads when a connection last logged in and its most
+// recent login or reauth attempt.
+func (c CredentialsCmd) connectionUsage(ctx context.Context, conn kernel.ManagedAuth) (credentialUsageConnection, error) {
+	u := credentialUsageConnection{
+		ID:          conn.ID,
+		Domain:      conn.Domain,
+		ProfileName: conn.ProfileName,
+		Status:      string(conn.Status),
+	}
+	if !conn.LastAuthAt.IsZero() {
+		t := conn.LastAuthAt
+		u.LastSuccessAt = &t
+	}
+
+	page, err := c.connections.Timeline(ctx, conn.ID, kernel.AuthConnectionTimelineParams{
+		Limit: kernel.Opt(int64(credentialUsageTimelineLimit)),
+	})
+	if err != nil {
+		return u, util.CleanedUpSdkError{Err: err}
+	}
+	if page == nil {
+		return u, nil
+	}
+	// The API returns newest-first
+	for _, ev := range page.Items {
+		if ev.Type == kernel.ManagedAuthTimelineEventTypeHealthCheck {
+			continue
+		}
+		if u.LastAttemptAt == nil {
+			t := ev.Timestamp
+			u.LastAttemptAt = &t
+			u.LastAttemptStatus = string(ev.Status)
+		}
+		if ev.Status == kernel.ManagedAuthTimelineEventStatusSuccess {
+			if u.LastSuccessAt == nil || ev.Timestamp.After(*u.LastSuccessAt) {
+				t := ev.Timestamp
+				u.LastSuccessAt = &t
+			}
+			break
+		}
+	}
+	return u, nil
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return util.FormatLocal(*t)
+}
+
+var credentialsUsageCmd = &cobra.Command{
+	Use:   "usage <id-or-name>",
+	Short: "Show which auth connections use a credential and when it was last used",
+	Long: `Show the managed auth connections that log in with a credential, their last
+successful login and most recent attempt, and when the credential was last used
+successfully, so unused credentials can be retired with confidence.
+
+Only Kernel credentials linked by name are matched; connections backed by an
+external credential provider are not.`,
+	Example: `  kernel credentials usage my-creds
+  kernel credentials usage my-creds -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCredentialsUsage,
+}
+
+func init() {
+	credentialsCmd.AddCommand(credentialsUsageCmd)
+	credentialsUsageCmd.ValidArgsFunction = completeResourceArg("credential", completeCredential)
+	addJSONOutputFlag(credentialsUsageCmd)
+}
+
+func runCredentialsUsage(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	output, _ := cmd.Flags().GetString("output")
+
+	svc := client.Credentials
+	connections := client.Auth.Connections
+	c := CredentialsCmd{credentials: &svc, connections: &connections}
+	return c.Usage(cmd.Context(), CredentialsUsageInput{Identifier: args[0], Output: output})
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/kernel/kernel-go-sdk/packages/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialsUsage_ListsLinkedConnections(t *testing.T) {
+	lastAuth := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	retried := time.Date(2026, 6, 3, 10, 0, 0, 0, time.UTC)
+	creds := &FakeCredentialsService{
+		GetFunc: func(ctx context.Context, idOrName string, opts ...option.RequestOption) (*kernel.Credential, error) {
+			return &kernel.Credential{ID: "cred_1", Name: "github", Domain: "github.com"}, nil
+		},
+	}
+	conns := &FakeAuthConnectionService{
+		ListFunc: func(ctx context.Context, query kernel.AuthConnectionListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.ManagedAuth], error) {
+			return &pagination.OffsetPagination[kernel.ManagedAuth]{Items: []kernel.ManagedAuth{
+				{ID: "ma_1", Domain: "github.com", Status: kernel.ManagedAuthStatusAuthenticated, Credential: kernel.ManagedAuthCredential{Name: "github"}, LastAuthAt: lastAuth},
+				{ID: "ma_2", Domain: "github.com", Status: kernel.ManagedAuthStatusNeedsAuth, Credential: kernel.ManagedAuthCredential{Name: "github"}},
+				{ID: "ma_3", Domain: "github.com", Credential: kernel.ManagedAuthCredential{Name: "github", Provider: "my-1p"}},
+				{ID: "ma_4", Domain: "gitlab.com", Credential: kernel.ManagedAuthCredential{Name: "gitlab"}},
+			}}, nil
+		},
+		TimelineFunc: func(ctx context.Context, id string, query kernel.AuthConnectionTimelineParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.ManagedAuthTimelineEvent], error) {
+			if id != "ma_2" {
+				return &pagination.OffsetPagination[kernel.ManagedAuthTimelineEvent]{}, nil
+			}
+			return &pagination.OffsetPagination[kernel.ManagedAuthTimelineEvent]{Items: []kernel.ManagedAuthTimelineEvent{
+				{Type: "health_check", Status: "NEEDS_AUTH", Timestamp: retried.Add(time.Hour)},
+				{Type: "reauth", Status: "FAILED", Timestamp: retried},
+				{Type: "login", Status: "SUCCESS", Timestamp: lastAuth.Add(24 * time.Hour)},
+			}}, nil
+		},
+	}
+
+	c := CredentialsCmd{credentials: creds, connections: conns}
+	var err error
+	out := captureStdout(t, func() {
+		err = c.Usage(context.Background(), CredentialsUsageInput{Identifier: "github", Output: "json"})
+	})
+	require.NoError(t, err)
+
+	var usage credentialUsage
+	require.NoError(t, json.Unmarshal([]byte(out), &usage))
+	require.Len(t, usage.Connections, 2, "provider-backed and other credentials' connections are left out")
+	assert.Equal(t, "ma_1", usage.Connections[0].ID)
+	assert.Equal(t, lastAuth, *usage.Connections[0].LastSuccessAt)
+	assert.Nil(t, usage.Connections[0].LastAttemptAt)
+
+	second := usage.Connections[1]
+	assert.Equal(t, retried, *second.LastAttemptAt, "health checks are not attempts")
+	assert.Equal(t, "FAILED", second.LastAttemptStatus)
+	assert.Equal(t, lastAuth.Add(24*time.Hour), *second.LastSuccessAt)
+	assert.Equal(t, lastAuth.Add(24*time.Hour), *usage.LastUsedAt)
+}
+
+func TestCredentialsUsage_Unused(t *testing.T) {
+	buf := capturePtermOutput(t)
+	creds := &FakeCredentialsService{
+		GetFunc: func(ctx context.Context, idOrName string, opts ...option.RequestOption) (*kernel.Credential, error) {
+			return &kernel.Credential{ID: "cred_1", Name: "old"}, nil
+		},
+	}
+	c := CredentialsCmd{credentials: creds, connections: &FakeAuthConnectionService{}}
+	require.NoError(t, c.Usage(context.Background(), CredentialsUsageInput{Identifier: "old"}))
+	assert.Contains(t, buf.String(), "No auth connections use credential old")
+}
@@ -10,11 +10,13 @@ import (
 	"net/http"
 	"net/textproto"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/kernel/cli/pkg/rundir"
 	"github.com/kernel/cli/pkg/util"
 	kernel "github.com/kernel/kernel-go-sdk"
 	"github.com/kernel/kernel-go-sdk/option"
@@ -74,7 +76,14 @@ func init() {
 	deployCmd.Flags().String("region", "", "Deployment region (currently only aws.us-east-1a)")
 	deployCmd.Flags().StringArrayP("env", "e", []string{}, "Set environment variables (e.g., KEY=value). May be specified multiple times")
 	deployCmd.Flags().StringArray("env-file", []string{}, "Read environment variables from a file (.env format). May be specified multiple times")
+	deployCmd.Flags().String("app", "", "Name of the app being deployed, whose stored environment (see kernel app env) is applied and whose concurrent deploys are refused; detected from the entrypoint when omitted")
 	deployCmd.Flags().StringP("output", "o", "", "Output format: json for JSONL streaming output")
+	deployCmd.Flags().Bool("watch", false, "Redeploy whenever files in the entrypoint's directory change, until interrupted")
+	deployCmd.Flags().StringArray("watch-ignore", nil, "With --watch, glob of files or directories whose changes don't redeploy (repeatable; .gitignore is honored)")
+	deployCmd.Flags().Duration("debounce", defaultDeployWatchDebounce, "With --watch, wait until files have been unchanged this long before redeploying")
+	deployCmd.Flags().String("invoke", "", "With --watch, invoke this action synchronously after each successful deploy as a smoke test")
+	deployCmd.Flags().String("invoke-payload", "", "JSON payload for the --invoke smoke test")
+	addRunDirFlag(deployCmd)
 
 	// Subcommands under deploy
 	addJSONOutputFlag(deployGetCmd)
@@ -102,13 +111,14 @@ func init() {
 	deployGithubCmd.Flags().String("path", "", "Optional subdirectory within the repo (e.g., apps/api)")
 	deployGithubCmd.Flags().String("github-token", "", "GitHub token for private repositories (PAT or installation access token)")
 	deployGithubCmd.Flags().String("region", "aws.us-east-1a", "Deployment region (currently only aws.us-east-1a)")
+	deployGithubCmd.Flags().String("app", "", "Name of the app being deployed; its variables stored with 'kernel app env' are applied, and concurrent deploys of it from anywhere on this machine are refused")
 	_ = deployGithubCmd.MarkFlagRequired("url")
 	_ = deployGithubCmd.MarkFlagRequired("ref")
 	_ = deployGithubCmd.MarkFlagRequired("entrypoint")
 	deployCmd.AddCommand(deployGithubCmd)
 }
 
-func runDeployGithub(cmd *cobra.Command, args []string) error {
+func runDeployGithub(cmd *cobra.Command, args []string) (err error) {
 	client := getKernelClient(cmd)
 
 	repoURL, _ := cmd.Flags().GetString("url")
@@ -126,29 +136,22 @@ func runDeployGithub(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Collect env vars similar to runDeploy
-	envPairs, _ := cmd.Flags().GetStringArray("env")
-	envFiles, _ := cmd.Flags().GetStringArray("env-file")
+	envVars, err := readDeployEnv(cmd)
+	if err != nil {
+		return err
+	}
 
-	envVars := make(map[string]string)
-	// Load from files first
-	for _, envFile := range envFiles {
-		fileVars, err := godotenv.Read(envFile)
-		if err != nil {
-			return fmt.Errorf("failed to read env file %s: %w", envFile, err)
-		}
-		for k, v := range fileVars {
-			envVars[k] = v
-		}
+	source := repoURL + "#" + ref + ":" + path.Join(subpath, entrypoint)
+	appName, _ := cmd.Flags().GetString("app")
+	if err := applyDefaultAppEnv(appEnvScope(cmd), appName, envVars, output); err != nil {
+		return err
 	}
-	// Override with --env
-	for _, kv := range envPairs {
-		parts := strings.SplitN(kv, "=", 2)
-		if len(parts) != 2 {
-			return fmt.Errorf("invalid env variable format: %s (expected KEY=value)", kv)
-		}
-		envVars[parts[0]] = parts[1]
+	resource, what := deployRunLock(appName, version, source)
+	guard, ctx, err := acquireRunGuard(cmd.Context(), defaultRunLockStore(), resource, what)
+	if err != nil {
+		return err
 	}
+	defer func() { err = guard.Release(err) }()
 
 	// Build the multipart request body directly for source-based deploy
 
@@ -209,7 +212,7 @@ func runDeployGithub(cmd *cobra.Command, args []string) error {
 	_, _ = part.Write(srcJSON)
 	_ = mw.Close()
 
-	reqHTTP, _ := http.NewRequestWithContext(cmd.Context(), http.MethodPost, strings.TrimRight(baseURL, "/")+"/deployments", &body)
+	reqHTTP, _ := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(baseURL, "/")+"/deployments", &body)
 	reqHTTP.Header.Set("Authorization", "Bearer "+apiKey)
 	reqHTTP.Header.Set("Content-Type", mw.FormDataContentType())
 	httpResp, err := http.DefaultClient.Do(reqHTTP)
@@ -228,7 +231,7 @@ func runDeployGithub(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("decode deployment response: %w", err)
 	}
 
-	return followDeployment(cmd.Context(), client, depCreated.ID, startTime, output,
+	return followDeployment(ctx, client, depCreated.ID, startTime, output,
 		option.WithBaseURL(baseURL),
 		option.WithHeader("Authorization", "Bearer "+apiKey),
 		option.WithMaxRetries(0),
@@ -251,6 +254,14 @@ func runDeploy(cmd *cobra.Command, args []string) (err error) {
 	if version == "" {
 		version = "latest"
 	}
+	watch, _ := cmd.Flags().GetBool("watch")
+	if !watch {
+		for _, name := range []string{"watch-ignore", "debounce", "invoke", "invoke-payload"} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--%s only applies with --watch", name)
+			}
+		}
+	}
 	resolvedEntrypoint, err := filepath.Abs(entrypoint)
 	if err != nil {
 		return fmt.Errorf("failed to resolve entrypoint: %w", err)
@@ -259,7 +270,72 @@ func runDeploy(cmd *cobra.Command, args []string) (err error) {
 		return fmt.Errorf("entrypoint %s does not exist", resolvedEntrypoint)
 	}
 
-	sourceDir := filepath.Dir(resolvedEntrypoint)
+	envVars, err := readDeployEnv(cmd)
+	if err != nil {
+		return err
+	}
+	appName, _ := cmd.Flags().GetString("app")
+	if appName == "" {
+		appName = detectAppName(resolvedEntrypoint)
+	}
+	if err := applyDefaultAppEnv(appEnvScope(cmd), appName, envVars, output); err != nil {
+		return err
+	}
+	in := deploySourceInput{
+		AppName:    appName,
+		SourceDir:  filepath.Dir(resolvedEntrypoint),
+		Entrypoint: filepath.Base(resolvedEntrypoint),
+		Version:    version,
+		Force:      force,
+		Region:     region,
+		EnvVars:    envVars,
+		Output:     output,
+	}
+	run, err := startRunDir(cmd, args)
+	if err != nil {
+		return err
+	}
+	defer func() { finishRunDir(run, err) }()
+	run.Set("version", version)
+	if watch {
+		return runDeployWatch(cmd, client, in)
+	}
+	_, err = deploySource(cmd.Context(), client, in, startTime)
+	return err
+}
+
+type deploySourceInput struct {
+	// AppName is the app the source registers, if known.
+	AppName   string
+	SourceDir string
+	// Entrypoint is relative to SourceDir.
+	Entrypoint string
+	Version    string
+	Force      bool
+	Region     string
+	EnvVars    map[string]string
+	Output     string
+}
+
+// deploySource zips in.SourceDir and deploys it, returning the deployment ID.
+func deploySource(ctx context.Context, client kernel.Client, in deploySourceInput, startTime time.Time) (id string, err error) {
+	resource, what := deployRunLock(in.AppName, in.Version, in.SourceDir)
+	guard, ctx, err := acquireRunGuard(ctx, defaultRunLockStore(), resource, what)
+	if err != nil {
+		return "", err
+	}
+	defer func() { err = guard.Release(err) }()
+
+	zipPath, err := zipDeploySource(in.SourceDir, in.Output)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(zipPath)
+	return uploadDeploySource(ctx, client, zipPath, in, startTime)
+}
+
+// zipDeploySource compresses sourceDir into a temp zip, honoring .gitignore.
+func zipDeploySource(sourceDir, output string) (string, error) {
 	var spinner *pterm.SpinnerPrinter
 	if output != "json" {
 		spinner, _ = pterm.DefaultSpinner.Start("Compressing files...")
@@ -270,71 +346,101 @@ func runDeploy(cmd *cobra.Command, args []string) (err error) {
 		if spinner != nil {
 			spinner.Fail("Failed to compress files")
 		}
-		return err
+		os.Remove(tmpFile)
+		return "", err
 	}
 	if spinner != nil {
 		spinner.Success("Compressed files")
 	}
-	defer os.Remove(tmpFile)
+	if output == "json" {
+		ev, err := newBundleCreatedEvent(sourceDir, tmpFile)
+		if err != nil {
+			os.Remove(tmpFile)
+			return "", fmt.Errorf("failed to read bundle: %w", err)
+		}
+		emitDeployEvent(ev)
+	}
+	return tmpFile, nil
+}
 
-	// make io.Reader from tmpFile
-	file, err := os.Open(tmpFile)
+// uploadDeploySource deploys a zip written by zipDeploySource, follows the
+// deployment and returns its ID. Once it's running, the source hash is
+// recorded so "kernel invoke --app-dir" can tell whether the deployed code
+// is current.
+func uploadDeploySource(ctx context.Context, client kernel.Client, zipPath string, in deploySourceInput, startTime time.Time) (string, error) {
+	version := in.Version
+	if version == "" {
+		version = "latest"
+	}
+	params := kernel.DeploymentNewParams{
+		Version:           kernel.Opt(version),
+		Force:             kernel.Opt(in.Force),
+		EntrypointRelPath: kernel.Opt(in.Entrypoint),
+		EnvVars:           in.EnvVars,
+	}
+	if in.Region != "" {
+		if in.Region != string(kernel.DeploymentNewParamsRegionAwsUsEast1a) {
+			return "", fmt.Errorf("invalid --region value: %s (must be %s)", in.Region, kernel.DeploymentNewParamsRegionAwsUsEast1a)
+		}
+		params.Region = kernel.DeploymentNewParamsRegion(in.Region)
+	}
+	hash, hashErr := deploySourceHash(zipPath, in.Entrypoint)
+
+	file, err := os.Open(zipPath)
 	if err != nil {
-		return fmt.Errorf("failed to open tmpFile: %w", err)
+		return "", fmt.Errorf("failed to open tmpFile: %w", err)
 	}
 	defer file.Close()
+	params.File = file
 
-	// Gather environment variables from --env and --env-file flags
+	logger.Debug("deploying app", logger.Args("version", version, "force", in.Force, "entrypoint", in.Entrypoint))
+	if in.Output != "json" {
+		pterm.Info.Println("Deploying...")
+	}
+	reqOpts := []option.RequestOption{option.WithMaxRetries(0)}
+	if in.Output == "json" {
+		reqOpts = append(reqOpts, option.WithMiddleware(uploadProgressMiddleware(func(ev uploadProgressEvent) { emitDeployEvent(ev) })))
+	}
+	resp, err := client.Deployments.New(ctx, params, reqOpts...)
+	if err != nil {
+		return "", util.CleanedUpSdkError{Err: err}
+	}
+	rundir.FromContext(ctx).Set("deployment_id", resp.ID)
+	if err := followDeployment(ctx, client, resp.ID, startTime, in.Output, option.WithMaxRetries(0)); err != nil {
+		return resp.ID, err
+	}
+	if hashErr == nil {
+		if err := recordDeployedSource(deployedSourcesPath(), resp.ID, hash, time.Now()); err != nil {
+			logger.Debug("failed to record deployed source", logger.Args("error", err))
+		}
+	}
+	return resp.ID, nil
+}
+
+// readDeployEnv collects environment variables from --env-file and --env;
+// --env wins over files.
+func readDeployEnv(cmd *cobra.Command) (map[string]string, error) {
 	envPairs, _ := cmd.Flags().GetStringArray("env")
 	envFiles, _ := cmd.Flags().GetStringArray("env-file")
 
 	envVars := make(map[string]string)
-
-	// Load from env files first so that explicit --env overrides them
 	for _, envFile := range envFiles {
 		fileVars, err := godotenv.Read(envFile)
 		if err != nil {
-			return fmt.Errorf("failed to read env file %s: %w", envFile, err)
+			return nil, fmt.Errorf("failed to read env file %s: %w", envFile, err)
 		}
 		for k, v := range fileVars {
 			envVars[k] = v
 		}
 	}
-
-	// Parse KEY=value pairs provided via --env
 	for _, kv := range envPairs {
 		parts := strings.SplitN(kv, "=", 2)
 		if len(parts) != 2 {
-			return fmt.Errorf("invalid env variable format: %s (expected KEY=value)", kv)
+			return nil, fmt.Errorf("invalid env variable format: %s (expected KEY=value)", kv)
 		}
 		envVars[parts[0]] = parts[1]
 	}
-
-	logger.Debug("deploying app", logger.Args("version", version, "force", force, "entrypoint", filepath.Base(resolvedEntrypoint)))
-	if output != "json" {
-		pterm.Info.Println("Deploying...")
-	}
-
-	params := kernel.DeploymentNewParams{
-		File:              file,
-		Version:           kernel.Opt(version),
-		Force:             kernel.Opt(force),
-		EntrypointRelPath: kernel.Opt(filepath.Base(resolvedEntrypoint)),
-		EnvVars:           envVars,
-	}
-	if region != "" {
-		if region != string(kernel.DeploymentNewParamsRegionAwsUsEast1a) {
-			return fmt.Errorf("invalid --region value: %s (must be %s)", region, kernel.DeploymentNewParamsRegionAwsUsEast1a)
-		}
-		params.Region = kernel.DeploymentNewParamsRegion(region)
-	}
-
-	resp, err := client.Deployments.New(cmd.Context(), params, option.WithMaxRetries(0))
-	if err != nil {
-		return util.CleanedUpSdkError{Err: err}
-	}
-
-	return followDeployment(cmd.Context(), client, resp.ID, startTime, output, option.WithMaxRetries(0))
+	return envVars, nil
 }
 
 func runDeployGet(cmd *cobra.Command, args []string) error {
@@ -353,6 +459,7 @@ func runDeployGet(cmd *cobra.Command, args []string) error {
 	if output == "json" {
 		return util.PrintPrettyJSON(deployment)
 	}
+	util.WarnDecodeDrift(deployment)
 
 	tableData := pterm.TableData{
 		{"Property", "Value"},
@@ -540,11 +647,11 @@ func runDeployHistory(cmd *cobra.Command, args []string) error {
 
 	if output == "json" {
 		if deployments == nil || len(deployments.Items) == 0 {
-			fmt.Println("[]")
-			return nil
+			return util.PrintJSON([]any{})
 		}
 		return util.PrintPrettyJSONSlice(deployments.Items)
 	}
+	util.WarnDecodeDrift(deployments)
 
 	if deployments == nil || len(deployments.Items) == 0 {
 		pterm.Info.Println("No deployments found")
@@ -623,34 +730,38 @@ func runDeployHistory(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// recordDeploymentEvent adds a follow event to the run's events, its log
+// lines to the run's logs, and the deployment's terminal state to the run's
+// result file.
+func recordDeploymentEvent(run *rundir.Run, data kernel.DeploymentFollowResponseUnion) {
+	run.Event(data)
+	switch data.Event {
+	case "log":
+		run.Logf("%s", data.AsLog().Message)
+	case "deployment_state":
+		dep := data.AsDeploymentState().Deployment
+		switch dep.Status {
+		case string(kernel.DeploymentGetResponseStatusRunning), string(kernel.DeploymentGetResponseStatusFailed), string(kernel.DeploymentGetResponseStatusStopped):
+			_ = run.WriteJSON("deployment.json", dep, "the deployment's final state")
+		}
+	}
+}
+
 func followDeployment(ctx context.Context, client kernel.Client, deploymentID string, startTime time.Time, output string, opts ...option.RequestOption) error {
 	stream := client.Deployments.FollowStreaming(ctx, deploymentID, kernel.DeploymentFollowParams{}, opts...)
 	jsonOutput := output == "json"
+	if jsonOutput {
+		emitDeployEvent(buildStartedEvent{deployEventHeader: newDeployEventHeader(deployEventBuildStarted), DeploymentID: deploymentID})
+	}
+	run := rundir.FromContext(ctx)
 
 	for stream.Next() {
 		data := stream.Current()
+		recordDeploymentEvent(run, data)
 
 		if jsonOutput {
-			// Output each event as a JSON line
-			bs, err := json.Marshal(data)
-			if err == nil {
-				fmt.Println(string(bs))
-			}
-			// Check for terminal states
-			if data.Event == "deployment_state" {
-				deploymentState := data.AsDeploymentState()
-				status := deploymentState.Deployment.Status
-				if status == string(kernel.DeploymentGetResponseStatusFailed) ||
-					status == string(kernel.DeploymentGetResponseStatusStopped) {
-					return fmt.Errorf("deployment %s: %s", status, deploymentState.Deployment.StatusReason)
-				}
-				if status == string(kernel.DeploymentGetResponseStatusRunning) {
-					return nil
-				}
-			}
-			if data.Event == "error" {
-				errorEv := data.AsErrorEvent()
-				return fmt.Errorf("%s: %s", errorEv.Error.Code, errorEv.Error.Message)
+			if done, err := emitDeployFollowEvent(deploymentID, data, startTime); done {
+				return err
 			}
 			continue
 		}
@@ -691,7 +802,9 @@ func followDeployment(ctx context.Context, client kernel.Client, deploymentID st
 	}
 
 	if serr := stream.Err(); serr != nil {
-		if !jsonOutput {
+		if jsonOutput {
+			emitDeployEvent(deployErrorEvent{deployEventHeader: newDeployEventHeader(deployEventError), DeploymentID: deploymentID, Code: "stream_error", Message: serr.Error()})
+		} else {
 			pterm.Error.Println("✖ Stream error")
 			pterm.Error.Printf("Deployment ID: %s\n", deploymentID)
 			pterm.Info.Printf("View logs: kernel deploy logs %s --since 1h\n", deploymentID)
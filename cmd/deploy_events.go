@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+)
+
+// Deploy progress events, printed one per line by "kernel deploy -o json".
+// Each event is a flat object whose type field selects its schema; field
+// names are part of the output format, so only add fields.
+const (
+	deployEventBundleCreated  = "bundle_created"
+	deployEventUploadProgress = "upload_progress"
+	deployEventBuildStarted   = "build_started"
+	deployEventBuildLog       = "build_log"
+	deployEventVersionCreated = "version_created"
+	deployEventSucceeded      = "deploy_succeeded"
+	deployEventFailed         = "deploy_failed"
+	deployEventError          = "error"
+	// deployEventSourceChanged starts each redeploy of deploy --watch
+	deployEventSourceChanged = "source_changed"
+)
+
+// uploadProgressStep is the share of the upload between progress events.
+const uploadProgressStep = 0.05
+
+type deployEventHeader struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+}
+
+func newDeployEventHeader(typ string) deployEventHeader {
+	return deployEventHeader{Type: typ, Time: time.Now().UTC()}
+}
+
+// bundleCreatedEvent reports the zip built from the source directory.
+type bundleCreatedEvent struct {
+	deployEventHeader
+	SourceDir string `json:"source_dir"`
+	Files     int    `json:"files"`
+	SizeBytes int64  `json:"size_bytes"`
+	SHA256    string `json:"sha256"`
+}
+
+// uploadProgressEvent reports bytes of the deploy request sent so far.
+// TotalBytes is 0 when the request size isn't known.
+type uploadProgressEvent struct {
+	deployEventHeader
+	BytesSent  int64 `json:"bytes_sent"`
+	TotalBytes int64 `json:"total_bytes"`
+}
+
+type buildStartedEvent struct {
+	deployEventHeader
+	DeploymentID string `json:"deployment_id"`
+}
+
+type buildLogEvent struct {
+	deployEventHeader
+	DeploymentID string    `json:"deployment_id"`
+	Timestamp    time.Time `json:"timestamp"`
+	Message      string    `json:"message"`
+}
+
+type versionCreatedEvent struct {
+	deployEventHeader
+	DeploymentID string   `json:"deployment_id"`
+	AppName      string   `json:"app_name"`
+	Version      string   `json:"version"`
+	Actions      []string `json:"actions"`
+}
+
+// deployFinishedEvent is the last event of a deployment that ran to a
+// terminal state: deploy_succeeded or deploy_failed.
+type deployFinishedEvent struct {
+	deployEventHeader
+	DeploymentID string `json:"deployment_id"`
+	Status       string `json:"status"`
+	Reason       string `json:"reason,omitempty"`
+	DurationMS   int64  `json:"duration_ms"`
+}
+
+// sourceChangedEvent lists the files whose change triggered a redeploy.
+type sourceChangedEvent struct {
+	deployEventHeader
+	SourceDir string   `json:"source_dir"`
+	Files     []string `json:"files"`
+}
+
+type deployErrorEvent struct {
+	deployEventHeader
+	DeploymentID string `json:"deployment_id,omitempty"`
+	Code         string `json:"code"`
+	Message      string `json:"message"`
+}
+
+func emitDeployEvent(ev any) {
+	_ = util.PrintJSONLine(ev)
+}
+
+// newBundleCreatedEvent describes the deploy zip at zipPath.
+func newBundleCreatedEvent(sourceDir, zipPath string) (bundleCreatedEvent, error) {
+	ev := bundleCreatedEvent{deployEventHeader: newDeployEventHeader(deployEventBundleCreated), SourceDir: sourceDir}
+	st, err := os.Stat(zipPath)
+	if err != nil {
+		return ev, err
+	}
+	ev.SizeBytes = st.Size()
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return ev, err
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if !f.FileInfo().IsDir() {
+			ev.Files++
+		}
+	}
+	ev.SHA256, err = fileSHA256Hex(zipPath)
+	return ev, err
+}
+
+// uploadProgressMiddleware reports the request body as it's sent, every
+// uploadProgressStep of it (every MiB when the size is unknown) and once
+// when it's all sent.
+func uploadProgressMiddleware(emit func(uploadProgressEvent)) option.Middleware {
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		if req.Body == nil || req.Body == http.NoBody {
+			return next(req)
+		}
+		total := max(req.ContentLength, 0)
+		step := int64(1 << 20)
+		if total > 0 {
+			step = max(int64(float64(total)*uploadProgressStep), 1)
+		}
+		req.Body = &uploadProgressReader{ReadCloser: req.Body, total: total, step: step, emit: emit}
+		return next(req)
+	}
+}
+
+type uploadProgressReader struct {
+	io.ReadCloser
+	sent, reported int64
+	total, step    int64
+	done           bool
+	emit           func(uploadProgressEvent)
+}
+
+func (p *uploadProgressReader) Read(b []byte) (int, error) {
+	n, err := p.ReadCloser.Read(b)
+	p.sent += int64(n)
+	finished := err == io.EOF || (p.total > 0 && p.sent >= p.total)
+	if !p.done && (finished || p.sent-p.reported >= p.step) {
+		p.reported = p.sent
+		p.done = finished
+		p.emit(uploadProgressEvent{
+			deployEventHeader: newDeployEventHeader(deployEventUploadProgress),
+			BytesSent:         p.sent,
+			TotalBytes:        p.total,
+		})
+	}
+	return n, err
+}
+
+// emitDeployFollowEvent prints the typed event for a deployment stream
+// event. done is set once the deployment reached a terminal state, with
+// the error to return for a failed deployment.
+func emitDeployFollowEvent(deploymentID string, data kernel.DeploymentFollowResponseUnion, startTime time.Time) (done bool, err error) {
+	switch data.Event {
+	case "log":
+		logEv := data.AsLog()
+		emitDeployEvent(buildLogEvent{
+			deployEventHeader: newDeployEventHeader(deployEventBuildLog),
+			DeploymentID:      deploymentID,
+			Timestamp:         logEv.Timestamp,
+			Message:           logEv.Message,
+		})
+	case "app_version_summary":
+		summary := data.AsDeploymentFollowResponseAppVersionSummaryEvent()
+		actions := make([]string, 0, len(summary.Actions))
+		for _, a := range summary.Actions {
+			actions = append(actions, a.Name)
+		}
+		emitDeployEvent(versionCreatedEvent{
+			deployEventHeader: newDeployEventHeader(deployEventVersionCreated),
+			DeploymentID:      deploymentID,
+			AppName:           summary.AppName,
+			Version:           summary.Version,
+			Actions:           actions,
+		})
+	case "deployment_state":
+		dep := data.AsDeploymentState().Deployment
+		ev := deployFinishedEvent{
+			DeploymentID: deploymentID,
+			Status:       dep.Status,
+			DurationMS:   time.Since(startTime).Milliseconds(),
+		}
+		switch dep.Status {
+		case string(kernel.DeploymentGetResponseStatusRunning):
+			ev.deployEventHeader = newDeployEventHeader(deployEventSucceeded)
+			emitDeployEvent(ev)
+			return true, nil
+		case string(kernel.DeploymentGetResponseStatusFailed), string(kernel.DeploymentGetResponseStatusStopped):
+			ev.deployEventHeader = newDeployEventHeader(deployEventFailed)
+			ev.Reason = dep.StatusReason
+			emitDeployEvent(ev)
+			return true, fmt.Errorf("deployment %s: %s", dep.Status, dep.StatusReason)
+		}
+	case "error":
+		errEv := data.AsErrorEvent()
+		emitDeployEvent(deployErrorEvent{
+			deployEventHeader: newDeployEventHeader(deployEventError),
+			DeploymentID:      deploymentID,
+			Code:              errEv.Error.Code,
+			Message:           errEv.Error.Message,
+		})
+		return true, fmt.Errorf("%s: %s", errEv.Error.Code, errEv.Error.Message)
+	}
+	return false, nil
+}
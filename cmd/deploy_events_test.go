@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deployTestServer accepts one deployment and streams events for it.
+func deployTestServer(t *testing.T, events ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/deployments":
+			_, _ = io.Copy(io.Discard, r.Body)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"id":"dep_1","status":"queued","created_at":"2026-01-01T00:00:00Z","region":"aws.us-east-1a"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/deployments/dep_1/events":
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, ev := range events {
+				fmt.Fprintf(w, "data: %s\n\n", ev)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func deployEventTypes(t *testing.T, out string) ([]string, []map[string]any) {
+	t.Helper()
+	var types []string
+	var events []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var ev map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &ev), line)
+		types = append(types, ev["type"].(string))
+		events = append(events, ev)
+	}
+	return types, events
+}
+
+func TestDeploySourceJSONEvents(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "cache"))
+	prevLogger := logger
+	logger = pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	t.Cleanup(func() { logger = prevLogger })
+	src := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(src, "index.ts"), []byte("export {}"), 0o644))
+
+	server := deployTestServer(t,
+		`{"event":"log","message":"installing\n","timestamp":"2026-01-01T00:00:01Z"}`,
+		`{"event":"app_version_summary","id":"ver_1","app_name":"my-app","version":"latest","actions":[{"name":"run"}],"region":"aws.us-east-1a","env_vars":{}}`,
+		`{"event":"deployment_state","deployment":{"id":"dep_1","status":"running","created_at":"2026-01-01T00:00:00Z","region":"aws.us-east-1a"},"timestamp":"2026-01-01T00:00:02Z"}`,
+	)
+	client := kernel.NewClient(option.WithBaseURL(server.URL), option.WithAPIKey("test"))
+
+	var err error
+	out := captureStdout(t, func() {
+		_, err = deploySource(context.Background(), client, deploySourceInput{SourceDir: src, Entrypoint: "index.ts", Output: "json"}, time.Now())
+	})
+	require.NoError(t, err)
+
+	types, events := deployEventTypes(t, out)
+	require.GreaterOrEqual(t, len(types), 6)
+	assert.Equal(t, "bundle_created", types[0])
+	assert.Equal(t, "upload_progress", types[1])
+	assert.Equal(t, []string{"build_started", "build_log", "version_created", "deploy_succeeded"}, types[len(types)-4:])
+
+	assert.EqualValues(t, 1, events[0]["files"])
+	assert.Len(t, events[0]["sha256"], 64)
+	last := events[len(types)-5]
+	assert.Equal(t, "upload_progress", last["type"])
+	assert.Equal(t, last["total_bytes"], last["bytes_sent"], "the final progress event covers the whole request")
+	assert.Equal(t, "installing\n", events[len(types)-3]["message"])
+	assert.Equal(t, []any{"run"}, events[len(types)-2]["actions"])
+	assert.Equal(t, "dep_1", events[len(types)-1]["deployment_id"])
+}
+
+func TestFollowDeploymentJSONFailure(t *testing.T) {
+	server := deployTestServer(t,
+		`{"event":"deployment_state","deployment":{"id":"dep_1","status":"failed","status_reason":"build failed","created_at":"2026-01-01T00:00:00Z","region":"aws.us-east-1a"},"timestamp":"2026-01-01T00:00:02Z"}`,
+	)
+	client := kernel.NewClient(option.WithBaseURL(server.URL), option.WithAPIKey("test"))
+
+	var err error
+	out := captureStdout(t, func() {
+		err = followDeployment(context.Background(), client, "dep_1", time.Now(), "json")
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "build failed")
+	types, events := deployEventTypes(t, out)
+	assert.Equal(t, []string{"build_started", "deploy_failed"}, types)
+	assert.Equal(t, "failed", events[1]["status"])
+	assert.Equal(t, "build failed", events[1]["reason"])
+}
+
+func TestUploadProgressMiddleware(t *testing.T) {
+	var got []uploadProgressEvent
+	mw := uploadProgressMiddleware(func(ev uploadProgressEvent) { got = append(got, ev) })
+	body := bytes.Repeat([]byte("x"), 1000)
+	req := httptest.NewRequest(http.MethodPost, "/deployments", bytes.NewReader(body))
+	_, err := mw(req, func(req *http.Request) (*http.Response, error) {
+		buf := make([]byte, 100)
+		for {
+			if _, err := req.Body.Read(buf); err != nil {
+				break
+			}
+		}
+		return nil, nil
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 10, "one event per 5% step, reads of 10%")
+	assert.EqualValues(t, 100, got[0].BytesSent)
+	assert.EqualValues(t, 1000, got[9].BytesSent)
+	assert.EqualValues(t, 1000, got[9].TotalBytes)
+}
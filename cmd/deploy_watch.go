@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/boyter/gocodewalker"
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultDeployWatchDebounce = 500 * time.Millisecond
+	// deployWatchPollInterval is how often the source tree is rescanned.
+	deployWatchPollInterval = 500 * time.Millisecond
+	// deployWatchShownChanges caps the changed paths named per redeploy.
+	deployWatchShownChanges = 3
+)
+
+// defaultDeployWatchIgnore are never watched, on top of .gitignore rules:
+// VCS metadata and dependency and cache directories that tools rewrite.
+var defaultDeployWatchIgnore = []string{".git", "node_modules", "__pycache__", ".venv", ".DS_Store"}
+
+// deployWatchFile is what the watcher compares to notice a file changed.
+type deployWatchFile struct {
+	size    int64
+	modTime time.Time
+	mode    os.FileMode
+}
+
+// deployWatchSnapshot maps watched files, by slash-separated path relative
+// to the source directory, to their stat.
+type deployWatchSnapshot map[string]deployWatchFile
+
+// changedFiles lists the paths added, removed or modified since prev.
+func (s deployWatchSnapshot) changedFiles(prev deployWatchSnapshot) []string {
+	var changed []string
+	for path, f := range s {
+		if old, ok := prev[path]; !ok || old != f {
+			changed = append(changed, path)
+		}
+	}
+	for path := range prev {
+		if _, ok := s[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+// snapshotDeploySource stats the files in dir that a deploy would zip,
+// honoring .gitignore like util.ZipDirectory, minus those matching ignore.
+func snapshotDeploySource(dir string, ignore []string) (deployWatchSnapshot, error) {
+	fileQueue := make(chan *gocodewalker.File, 256)
+	walker := gocodewalker.NewFileWalker(dir, fileQueue)
+	walker.IncludeHidden = true
+	defer walker.Terminate()
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- walker.Start()
+	}()
+
+	snap := deployWatchSnapshot{}
+	for f := range fileQueue {
+		rel, err := filepath.Rel(dir, f.Location)
+		if err != nil {
+			return nil, err
+		}
+		rel = filepath.ToSlash(rel)
+		if deployWatchIgnored(rel, ignore) {
+			continue
+		}
+		st, err := os.Lstat(f.Location)
+		if err != nil {
+			// Removed between listing and stat; the next scan settles it
+			continue
+		}
+		snap[rel] = deployWatchFile{size: st.Size(), modTime: st.ModTime(), mode: st.Mode()}
+	}
+	if err := <-errChan; err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// deployWatchIgnored reports whether rel, or any directory it's in, matches
+// one of the glob patterns. Patterns with a slash match the whole path.
+func deployWatchIgnored(rel string, patterns []string) bool {
+	parts := strings.Split(rel, "/")
+	for _, p := range patterns {
+		p = strings.TrimSuffix(filepath.ToSlash(p), "/")
+		if strings.Contains(p, "/") {
+			for i := range parts {
+				if ok, _ := filepath.Match(p, strings.Join(parts[:i+1], "/")); ok {
+					return true
+				}
+			}
+			continue
+		}
+		for _, part := range parts {
+			if ok, _ := filepath.Match(p, part); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deployWatcher redeploys a source directory whenever its files settle
+// after a change.
+type deployWatcher struct {
+	dir      string
+	ignore   []string
+	debounce time.Duration
+	interval time.Duration
+	// deploy runs one deploy; first is set for the initial one.
+	deploy func(ctx context.Context, first bool) error
+	// onChange is told which files triggered a redeploy.
+	onChange func(files []string)
+	// onError reports a failed deploy; watching goes on.
+	onError func(err error)
+}
+
+// Run deploys once, then polls the source tree and redeploys after each
+// change once no file has changed for the debounce period. Changes made
+// during a deploy trigger another one after it. It returns when ctx ends.
+func (w deployWatcher) Run(ctx context.Context) error {
+	base, err := snapshotDeploySource(w.dir, w.ignore)
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", w.dir, err)
+	}
+	if err := w.deploy(ctx, true); err != nil && ctx.Err() == nil {
+		w.onError(err)
+	}
+
+	last := base
+	var lastChange time.Time
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		cur, err := snapshotDeploySource(w.dir, w.ignore)
+		if err != nil {
+			w.onError(fmt.Errorf("failed to scan %s: %w", w.dir, err))
+			continue
+		}
+		if len(cur.changedFiles(last)) > 0 {
+			last = cur
+			lastChange = time.Now()
+			continue
+		}
+		changed := cur.changedFiles(base)
+		if len(changed) == 0 || time.Since(lastChange) < w.debounce {
+			continue
+		}
+		base = cur
+		w.onChange(changed)
+		if err := w.deploy(ctx, false); err != nil && ctx.Err() == nil {
+			w.onError(err)
+		}
+	}
+}
+
+type deployWatchSmoke struct {
+	Action  string
+	Payload string
+}
+
+// runDeployWatch deploys in.SourceDir and redeploys it on every change
+// until interrupted.
+func runDeployWatch(cmd *cobra.Command, client kernel.Client, in deploySourceInput) error {
+	ignore, _ := cmd.Flags().GetStringArray("watch-ignore")
+	debounce, _ := cmd.Flags().GetDuration("debounce")
+	smokeAction, _ := cmd.Flags().GetString("invoke")
+	smokePayload, _ := cmd.Flags().GetString("invoke-payload")
+	if smokePayload != "" && smokeAction == "" {
+		return fmt.Errorf("--invoke-payload requires --invoke")
+	}
+	if debounce < 0 {
+		return fmt.Errorf("--debounce must not be negative")
+	}
+	jsonOutput := in.Output == "json"
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Progress goes to stderr with -o json so stdout stays JSONL
+	errPrinter := pterm.Error
+	infoPrinter := pterm.Info
+	if jsonOutput {
+		errPrinter = *errPrinter.WithWriter(os.Stderr)
+		infoPrinter = *infoPrinter.WithWriter(os.Stderr)
+	}
+
+	w := deployWatcher{
+		dir:      in.SourceDir,
+		ignore:   append(slices.Clone(defaultDeployWatchIgnore), ignore...),
+		debounce: debounce,
+		interval: deployWatchPollInterval,
+		deploy: func(ctx context.Context, first bool) error {
+			deployIn := in
+			// Redeploys replace the version the first deploy created
+			deployIn.Force = in.Force || !first
+			id, err := deploySource(ctx, client, deployIn, time.Now())
+			if err != nil {
+				return err
+			}
+			if smokeAction != "" {
+				return smokeInvoke(ctx, client, id, in.Version, deployWatchSmoke{Action: smokeAction, Payload: smokePayload}, in.Output)
+			}
+			return nil
+		},
+		onChange: func(files []string) {
+			if jsonOutput {
+				emitDeployEvent(sourceChangedEvent{
+					deployEventHeader: newDeployEventHeader(deployEventSourceChanged),
+					SourceDir:         in.SourceDir,
+					Files:             files,
+				})
+				return
+			}
+			shown := files
+			more := ""
+			if len(files) > deployWatchShownChanges {
+				shown = files[:deployWatchShownChanges]
+				more = fmt.Sprintf(" and %d more", len(files)-deployWatchShownChanges)
+			}
+			pterm.Info.Printf("Changed: %s%s; redeploying\n", strings.Join(shown, ", "), more)
+		},
+		onError: func(err error) {
+			errPrinter.Println(err.Error())
+		},
+	}
+	infoPrinter.Printf("Watching %s for changes (Ctrl+C to stop)...\n", in.SourceDir)
+	if err := w.Run(ctx); err != nil {
+		return err
+	}
+	infoPrinter.Println("Stopped watching")
+	return nil
+}
+
+// smokeInvoke runs one synchronous invocation of the app deployed by
+// deploymentID and reports whether it succeeded.
+func smokeInvoke(ctx context.Context, client kernel.Client, deploymentID, version string, smoke deployWatchSmoke, output string) error {
+	if version == "" {
+		version = "latest"
+	}
+	apps, err := client.Apps.List(ctx, kernel.AppListParams{Version: kernel.Opt(version)})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	appName := ""
+	if apps != nil {
+		for _, app := range apps.Items {
+			if app.Deployment == deploymentID {
+				appName = app.AppName
+				break
+			}
+		}
+	}
+	if appName == "" {
+		return fmt.Errorf("no app found for deployment %s to invoke", deploymentID)
+	}
+
+	params := kernel.InvocationNewParams{
+		AppName:    appName,
+		ActionName: smoke.Action,
+		Version:    version,
+		Async:      kernel.Opt(false),
+	}
+	if smoke.Payload != "" {
+		params.Payload = kernel.Opt(smoke.Payload)
+	}
+	if output != "json" {
+		pterm.Info.Printf("Invoking \"%s\" (action: %s, version: %s)…\n", appName, smoke.Action, version)
+	}
+	resp, err := client.Invocations.New(ctx, params, option.WithMaxRetries(0))
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	if output == "json" {
+		if err := util.PrintJSONLine(resp); err != nil {
+			return err
+		}
+	} else {
+		printResult(resp.Status == kernel.InvocationNewResponseStatusSucceeded, resp.Output)
+	}
+	if resp.Status != kernel.InvocationNewResponseStatusSucceeded {
+		return fmt.Errorf("invocation %s of %s: %s", resp.ID, smoke.Action, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeployWatchIgnored(t *testing.T) {
+	patterns := []string{"node_modules", "*.log", "dist/", "src/generated/*"}
+	for rel, want := range map[string]bool{
+		"index.ts":                  false,
+		"node_modules/x/index.js":   true,
+		"debug.log":                 true,
+		"logs/app.log":              true,
+		"dist/bundle.js":            true,
+		"src/dist/bundle.js":        true,
+		"src/generated/types.ts":    true,
+		"src/generated/deep/api.ts": true,
+		"generated/types.ts":        false,
+		"src/index.ts":              false,
+	} {
+		assert.Equal(t, want, deployWatchIgnored(rel, patterns), rel)
+	}
+}
+
+func TestSnapshotDeploySource_ChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	write("index.ts", "a")
+	write("lib/util.ts", "a")
+	write(".gitignore", "out/\n")
+	write("out/build.js", "a")
+	write("app.log", "a")
+
+	before, err := snapshotDeploySource(dir, []string{"*.log"})
+	require.NoError(t, err)
+	assert.Contains(t, before, "lib/util.ts")
+	assert.NotContains(t, before, "out/build.js", ".gitignore is honored")
+	assert.NotContains(t, before, "app.log")
+
+	write("lib/util.ts", "changed")
+	write("new.ts", "a")
+	require.NoError(t, os.Remove(filepath.Join(dir, "index.ts")))
+	write("out/build.js", "changed")
+	write("app.log", "changed")
+
+	after, err := snapshotDeploySource(dir, []string{"*.log"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"index.ts", "lib/util.ts", "new.ts"}, after.changedFiles(before))
+	assert.Empty(t, after.changedFiles(after))
+}
+
+func TestDeployWatcher_RedeploysAfterChangesSettle(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.ts"), []byte("v1"), 0o644))
+
+	var mu sync.Mutex
+	var deploys []bool
+	var changes [][]string
+	deployed := make(chan struct{}, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := deployWatcher{
+		dir:      dir,
+		ignore:   []string{"*.tmp"},
+		debounce: 50 * time.Millisecond,
+		interval: 10 * time.Millisecond,
+		deploy: func(ctx context.Context, first bool) error {
+			mu.Lock()
+			deploys = append(deploys, first)
+			mu.Unlock()
+			deployed <- struct{}{}
+			return nil
+		},
+		onChange: func(files []string) {
+			mu.Lock()
+			changes = append(changes, files)
+			mu.Unlock()
+		},
+		onError: func(err error) { t.Errorf("unexpected error: %v", err) },
+	}
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+
+	<-deployed
+	// Ignored files don't redeploy
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "scratch.tmp"), []byte("x"), 0o644))
+	time.Sleep(150 * time.Millisecond)
+	// A burst of edits is one redeploy
+	for i := range 3 {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "index.ts"), []byte{'v', byte('2' + i), 'x'}, 0o644))
+		time.Sleep(15 * time.Millisecond)
+	}
+	select {
+	case <-deployed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no redeploy after the source changed")
+	}
+	time.Sleep(150 * time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []bool{true, false}, deploys)
+	assert.Equal(t, [][]string{{"index.ts"}}, changes)
+}
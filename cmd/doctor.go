@@ -0,0 +1,460 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/kernel/cli/pkg/auth"
+	"github.com/kernel/cli/pkg/util"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/zalando/go-keyring"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	doctorOK   = "ok"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+// doctorCheck is the outcome of one check. fix, when set, repairs what the
+// check found; FixDescription says what it would do.
+type doctorCheck struct {
+	Name           string `json:"name"`
+	Status         string `json:"status"`
+	Detail         string `json:"detail,omitempty"`
+	FixDescription string `json:"fix,omitempty"`
+	Fixed          bool   `json:"fixed,omitempty"`
+	FixError       string `json:"fix_error,omitempty"`
+	// confirm marks fixes that change the system outside the CLI's own
+	// files, which need confirmation unless --yes is passed.
+	confirm bool
+	fix     func() error
+}
+
+// DoctorCmd checks the local CLI setup and optionally repairs it.
+type DoctorCmd struct {
+	root      *cobra.Command
+	configDir string
+	cacheDir  string
+	home      string
+	shell     string
+	goos      string
+	lookPath  func(file string) (string, error)
+	// run executes an install command, streaming its output to the user.
+	run     func(args []string) error
+	confirm func(msg string) bool
+}
+
+type DoctorInput struct {
+	Fix    bool
+	Yes    bool
+	Output string
+}
+
+// newDoctorCmd returns a DoctorCmd for this machine. Directories are worked
+// out without creating them, so a missing one can be reported.
+func newDoctorCmd() (DoctorCmd, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return DoctorCmd{}, err
+	}
+	cacheBase := os.Getenv("XDG_CACHE_HOME")
+	if cacheBase == "" {
+		cacheBase = filepath.Join(home, ".cache")
+	}
+	return DoctorCmd{
+		root:      rootCmd,
+		configDir: filepath.Join(home, ".config", "kernel"),
+		cacheDir:  filepath.Join(cacheBase, "kernel"),
+		home:      home,
+		shell:     filepath.Base(os.Getenv("SHELL")),
+		goos:      runtime.GOOS,
+		lookPath:  exec.LookPath,
+		run: func(args []string) error {
+			c := exec.Command(args[0], args[1:]...)
+			c.Stdin = os.Stdin
+			c.Stdout = os.Stdout
+			c.Stderr = os.Stderr
+			return c.Run()
+		},
+		confirm: func(msg string) bool {
+			pterm.DefaultInteractiveConfirm.DefaultText = msg
+			ok, _ := pterm.DefaultInteractiveConfirm.Show()
+			return ok
+		},
+	}, nil
+}
+
+// Run performs every check and, with --fix, applies the available fixes.
+// It fails if any check is still failing at the end.
+func (d DoctorCmd) Run(in DoctorInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	checks := d.checks()
+	if in.Fix {
+		for i := range checks {
+			// JSON output can't be mixed with prompts
+			d.applyFix(&checks[i], in.Yes, in.Output != "json")
+		}
+	}
+
+	failed := 0
+	for _, c := range checks {
+		if c.Status == doctorFail && !c.Fixed {
+			failed++
+		}
+	}
+	if in.Output == "json" {
+		if err := util.PrintJSON(checks); err != nil {
+			return err
+		}
+	} else {
+		d.print(checks, in.Fix)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+func (d DoctorCmd) applyFix(c *doctorCheck, yes, interactive bool) {
+	if c.Status == doctorOK || c.fix == nil {
+		return
+	}
+	if c.confirm && !yes && (!interactive || !d.confirm(fmt.Sprintf("%s: %s?", c.Name, c.FixDescription))) {
+		return
+	}
+	if err := c.fix(); err != nil {
+		c.FixError = err.Error()
+		return
+	}
+	c.Fixed = true
+}
+
+func (d DoctorCmd) print(checks []doctorCheck, fixing bool) {
+	rows := [][]string{{"Check", "Status", "Detail"}}
+	fixable := 0
+	for _, c := range checks {
+		status := c.Status
+		detail := c.Detail
+		switch {
+		case c.Fixed:
+			status = "fixed"
+			detail = c.FixDescription
+		case c.FixError != "":
+			detail = fmt.Sprintf("%s (fix failed: %s)", detail, c.FixError)
+		case c.Status != doctorOK && c.fix != nil:
+			fixable++
+		}
+		rows = append(rows, []string{c.Name, status, detail})
+	}
+	PrintTableNoPad(rows, true)
+	if fixable > 0 && !fixing {
+		pterm.Info.Printf("Run 'kernel doctor --fix' to repair %d issue(s)\n", fixable)
+	}
+}
+
+func (d DoctorCmd) checks() []doctorCheck {
+	checks := []doctorCheck{
+		d.checkDir("config directory", d.configDir),
+		d.checkDir("cache directory", d.cacheDir),
+		d.checkConfigFile(),
+	}
+	for _, name := range []string{"completions.json", "prompt.json", "deployed_sources.json", "update-check.json"} {
+		checks = append(checks, d.checkCacheFile(name))
+	}
+	checks = append(checks,
+		d.checkSchedules(),
+		d.checkTokenStorage(),
+		d.checkCompletion(),
+		d.checkWebsocat(),
+	)
+	return checks
+}
+
+// checkDir expects an owner-only directory, since it may hold credentials.
+func (d DoctorCmd) checkDir(name, dir string) doctorCheck {
+	c := doctorCheck{Name: name, Status: doctorOK, Detail: dir}
+	fi, err := os.Stat(dir)
+	switch {
+	case os.IsNotExist(err):
+		c.Status = doctorWarn
+		c.Detail = dir + " doesn't exist"
+		c.FixDescription = "create " + dir
+		c.fix = func() error { return os.MkdirAll(dir, 0o700) }
+	case err != nil:
+		c.Status = doctorFail
+		c.Detail = err.Error()
+	case !fi.IsDir():
+		c.Status = doctorFail
+		c.Detail = dir + " is not a directory"
+	case d.goos != "windows" && fi.Mode().Perm()&0o077 != 0:
+		c.Status = doctorWarn
+		c.Detail = fmt.Sprintf("%s is readable by other users (%04o)", dir, fi.Mode().Perm())
+		c.FixDescription = "restrict " + dir + " to its owner"
+		c.fix = func() error { return os.Chmod(dir, 0o700) }
+	}
+	return c
+}
+
+// checkConfigFile only reports a broken config.yaml: it holds contexts and
+// API keys, so it is left for the user to repair.
+func (d DoctorCmd) checkConfigFile() doctorCheck {
+	path := filepath.Join(d.configDir, "config.yaml")
+	c := doctorCheck{Name: "config.yaml", Status: doctorOK, Detail: path}
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		c.Detail = "not present (no contexts configured)"
+		return c
+	}
+	if err == nil {
+		var v map[string]any
+		err = yaml.Unmarshal(b, &v)
+	}
+	if err != nil {
+		c.Status = doctorFail
+		c.Detail = fmt.Sprintf("%s: %v; edit it or remove it and re-create contexts with 'kernel config'", path, err)
+	}
+	return c
+}
+
+// checkCacheFile flags a cache file that isn't valid JSON. Caches are
+// rebuilt on demand, so the fix just removes it.
+func (d DoctorCmd) checkCacheFile(name string) doctorCheck {
+	path := filepath.Join(d.cacheDir, name)
+	c := doctorCheck{Name: "cache " + name, Status: doctorOK, Detail: "valid"}
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		c.Detail = "not present"
+		return c
+	}
+	if err == nil && json.Valid(b) {
+		return c
+	}
+	c.Status = doctorFail
+	if err != nil {
+		c.Detail = err.Error()
+	} else {
+		c.Detail = path + " is corrupted"
+	}
+	c.FixDescription = "remove " + path + " (it is rebuilt when needed)"
+	c.fix = func() error { return os.Remove(path) }
+	return c
+}
+
+// checkSchedules flags a corrupted schedules.json. It holds schedules and
+// their run history, so the fix moves it aside rather than deleting it.
+func (d DoctorCmd) checkSchedules() doctorCheck {
+	path := filepath.Join(d.configDir, "schedules.json")
+	c := doctorCheck{Name: "schedules", Status: doctorOK, Detail: "valid"}
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		c.Detail = "no schedules saved"
+		return c
+	}
+	var v []json.RawMessage
+	if err == nil && json.Unmarshal(b, &v) == nil {
+		return c
+	}
+	c.Status = doctorFail
+	c.Detail = path + " is corrupted"
+	if err != nil {
+		c.Detail = err.Error()
+	}
+	backup := fmt.Sprintf("%s.corrupt-%d", path, time.Now().Unix())
+	c.FixDescription = "move " + path + " to " + backup
+	c.fix = func() error { return os.Rename(path, backup) }
+	return c
+}
+
+// checkTokenStorage prefers the OS keychain for OAuth tokens. Tokens land in
+// the plaintext credentials file only when the keychain was unavailable at
+// login; once it works, the fix moves them into it.
+func (d DoctorCmd) checkTokenStorage() doctorCheck {
+	c := doctorCheck{Name: "token storage", Status: doctorOK}
+	path := filepath.Join(d.configDir, "credentials")
+	fileData, fileErr := os.ReadFile(path)
+	_, keyErr := keyring.Get(auth.KeyringService, auth.KeyringUser)
+	keychainUsable := keyErr == nil || errors.Is(keyErr, keyring.ErrNotFound)
+
+	switch {
+	case fileErr != nil && keyErr == nil:
+		c.Detail = "OS keychain"
+	case fileErr != nil:
+		c.Detail = "no stored login"
+		if !keychainUsable {
+			c.Detail += fmt.Sprintf("; the OS keychain is unavailable (%v), so 'kernel login' would store tokens in %s", keyErr, path)
+		}
+	case !keychainUsable:
+		c.Detail = fmt.Sprintf("plaintext file %s; the OS keychain is unavailable (%v)", path, keyErr)
+	case keyErr == nil:
+		// LoadTokens reads the keychain first, so the file is a stale leftover
+		c.Status = doctorWarn
+		c.Detail = fmt.Sprintf("OS keychain, with a stale plaintext copy in %s", path)
+		c.FixDescription = "remove the stale plaintext tokens in " + path
+		c.fix = func() error { return os.Remove(path) }
+	default:
+		c.Status = doctorWarn
+		c.Detail = fmt.Sprintf("plaintext file %s, but the OS keychain is available", path)
+		c.FixDescription = "move the tokens in " + path + " to the OS keychain"
+		c.fix = func() error {
+			var tokens auth.TokenStorage
+			if err := json.Unmarshal(fileData, &tokens); err != nil {
+				return fmt.Errorf("%s is corrupted; run 'kernel login' again", path)
+			}
+			if err := keyring.Set(auth.KeyringService, auth.KeyringUser, string(fileData)); err != nil {
+				return fmt.Errorf("failed to store tokens in the keychain: %w", err)
+			}
+			return os.Remove(path)
+		}
+	}
+	return c
+}
+
+// completionPath returns where shell loads completions for kernel from
+// without extra setup, or "" for shells doctor can't install into.
+func (d DoctorCmd) completionPath() string {
+	switch d.shell {
+	case "bash":
+		base := os.Getenv("XDG_DATA_HOME")
+		if base == "" {
+			base = filepath.Join(d.home, ".local", "share")
+		}
+		return filepath.Join(base, "bash-completion", "completions", "kernel")
+	case "zsh":
+		return filepath.Join(d.home, ".zfunc", "_kernel")
+	case "fish":
+		base := os.Getenv("XDG_CONFIG_HOME")
+		if base == "" {
+			base = filepath.Join(d.home, ".config")
+		}
+		return filepath.Join(base, "fish", "completions", "kernel.fish")
+	}
+	return ""
+}
+
+func (d DoctorCmd) checkCompletion() doctorCheck {
+	c := doctorCheck{Name: "shell completion", Status: doctorOK}
+	path := d.completionPath()
+	if path == "" {
+		c.Detail = "unknown shell; see 'kernel completion --help'"
+		if d.shell != "" && d.shell != "." {
+			c.Detail = fmt.Sprintf("can't install for %s; see 'kernel completion --help'", d.shell)
+		}
+		return c
+	}
+	if _, err := os.Stat(path); err == nil {
+		c.Detail = fmt.Sprintf("installed for %s (%s)", d.shell, path)
+		return c
+	}
+	c.Status = doctorWarn
+	c.Detail = fmt.Sprintf("not installed for %s", d.shell)
+	c.FixDescription = fmt.Sprintf("install %s completion to %s", d.shell, path)
+	if d.shell == "zsh" {
+		c.FixDescription += "; add 'fpath=(~/.zfunc $fpath)' before compinit in ~/.zshrc if it isn't there"
+	}
+	c.fix = func() error { return d.installCompletion(path) }
+	return c
+}
+
+func (d DoctorCmd) installCompletion(path string) error {
+	var buf bytes.Buffer
+	var err error
+	switch d.shell {
+	case "bash":
+		err = d.root.GenBashCompletionV2(&buf, true)
+	case "zsh":
+		err = d.root.GenZshCompletion(&buf)
+	case "fish":
+		err = d.root.GenFishCompletion(&buf, true)
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// websocatInstallCommand picks a package manager that can install websocat
+// on this machine, or nil if there's none.
+func (d DoctorCmd) websocatInstallCommand() []string {
+	candidates := [][]string{{"brew", "install", "websocat"}}
+	if d.goos == "windows" {
+		candidates = [][]string{{"scoop", "install", "websocat"}}
+	}
+	candidates = append(candidates, []string{"cargo", "install", "websocat"})
+	for _, args := range candidates {
+		if _, err := d.lookPath(args[0]); err == nil {
+			return args
+		}
+	}
+	return nil
+}
+
+func (d DoctorCmd) checkWebsocat() doctorCheck {
+	c := doctorCheck{Name: "websocat", Status: doctorOK}
+	if path, err := d.lookPath("websocat"); err == nil {
+		c.Detail = path
+		return c
+	}
+	c.Status = doctorWarn
+	c.Detail = "not found in PATH; needed by 'kernel browsers ssh'"
+	args := d.websocatInstallCommand()
+	if args == nil {
+		c.Detail += "; install it from https://github.com/vi/websocat/releases"
+		return c
+	}
+	c.FixDescription = "install websocat with '" + strings.Join(args, " ") + "'"
+	c.confirm = true
+	c.fix = func() error { return d.run(args) }
+	return c
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the local CLI setup and repair common problems",
+	Long: `Check the local CLI setup: config and cache directories, corrupted cache and
+schedule files, where login tokens are stored, shell completion, and the
+websocat binary used by "kernel browsers ssh".
+
+With --fix, repair what can be repaired: create missing directories, remove
+corrupted caches, move corrupted schedules aside, move plaintext tokens into
+the OS keychain, install shell completion, and install websocat with the
+platform package manager (after confirmation unless --yes is passed).`,
+	Example: `  kernel doctor
+  kernel doctor --fix
+  kernel doctor --fix --yes`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	doctorCmd.Flags().Bool("fix", false, "Repair the problems found")
+	doctorCmd.Flags().BoolP("yes", "y", false, "Install packages without asking for confirmation")
+	addJSONOutputFlag(doctorCmd)
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	fix, _ := cmd.Flags().GetBool("fix")
+	yes, _ := cmd.Flags().GetBool("yes")
+	output, _ := cmd.Flags().GetString("output")
+	d, err := newDoctorCmd()
+	if err != nil {
+		return err
+	}
+	return d.Run(DoctorInput{Fix: fix, Yes: yes, Output: output})
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kernel/cli/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+)
+
+func newTestDoctorCmd(t *testing.T) DoctorCmd {
+	home := t.TempDir()
+	// Completion paths follow these when set
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+	return DoctorCmd{
+		root:      rootCmd,
+		configDir: filepath.Join(home, ".config", "kernel"),
+		cacheDir:  filepath.Join(home, ".cache", "kernel"),
+		home:      home,
+		shell:     "fish",
+		goos:      "linux",
+		lookPath:  func(file string) (string, error) { return "", errors.New("not found") },
+		run:       func(args []string) error { return errors.New("unexpected install") },
+		confirm:   func(msg string) bool { return false },
+	}
+}
+
+func findDoctorCheck(t *testing.T, checks []doctorCheck, name string) doctorCheck {
+	t.Helper()
+	for _, c := range checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("no %q check", name)
+	return doctorCheck{}
+}
+
+func TestDoctor_FixCreatesDirsAndRemovesCorruptedCaches(t *testing.T) {
+	keyring.MockInit()
+	d := newTestDoctorCmd(t)
+	require.NoError(t, os.MkdirAll(d.cacheDir, 0o755))
+	corrupted := filepath.Join(d.cacheDir, "completions.json")
+	require.NoError(t, os.WriteFile(corrupted, []byte(`{"broken`), 0o600))
+
+	checks := d.checks()
+	assert.Equal(t, doctorWarn, findDoctorCheck(t, checks, "config directory").Status)
+	assert.Equal(t, doctorWarn, findDoctorCheck(t, checks, "cache directory").Status)
+	assert.Equal(t, doctorFail, findDoctorCheck(t, checks, "cache completions.json").Status)
+	assert.Equal(t, doctorOK, findDoctorCheck(t, checks, "cache prompt.json").Status)
+
+	capturePtermOutput(t)
+	out := captureStdout(t, func() {
+		require.NoError(t, d.Run(DoctorInput{Fix: true, Output: "json"}))
+	})
+	var results []doctorCheck
+	require.NoError(t, json.Unmarshal([]byte(out), &results))
+	assert.True(t, findDoctorCheck(t, results, "cache completions.json").Fixed)
+
+	fi, err := os.Stat(d.configDir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), fi.Mode().Perm())
+	fi, err = os.Stat(d.cacheDir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), fi.Mode().Perm())
+	assert.NoFileExists(t, corrupted)
+
+	// Fish completion was installed where fish autoloads it
+	assert.FileExists(t, d.completionPath())
+	assert.Equal(t, doctorOK, findDoctorCheck(t, d.checks(), "shell completion").Status)
+}
+
+func TestDoctor_FailsWithoutFix(t *testing.T) {
+	keyring.MockInit()
+	d := newTestDoctorCmd(t)
+	require.NoError(t, os.MkdirAll(d.configDir, 0o700))
+	schedules := filepath.Join(d.configDir, "schedules.json")
+	require.NoError(t, os.WriteFile(schedules, []byte("not json"), 0o600))
+
+	capturePtermOutput(t)
+	captureStdout(t, func() {
+		assert.ErrorContains(t, d.Run(DoctorInput{}), "1 check(s) failed")
+	})
+	assert.FileExists(t, schedules)
+
+	captureStdout(t, func() {
+		require.NoError(t, d.Run(DoctorInput{Fix: true}))
+	})
+	assert.NoFileExists(t, schedules)
+	matches, err := filepath.Glob(schedules + ".corrupt-*")
+	require.NoError(t, err)
+	assert.Len(t, matches, 1)
+}
+
+func TestDoctor_MovesPlaintextTokensToKeychain(t *testing.T) {
+	keyring.MockInit()
+	d := newTestDoctorCmd(t)
+	require.NoError(t, os.MkdirAll(d.configDir, 0o700))
+	tokenFile := filepath.Join(d.configDir, "credentials")
+	data := `{"access_token":"at","refresh_token":"rt","org_id":"org"}`
+	require.NoError(t, os.WriteFile(tokenFile, []byte(data), 0o600))
+
+	c := d.checkTokenStorage()
+	require.Equal(t, doctorWarn, c.Status)
+	d.applyFix(&c, false, true)
+	require.True(t, c.Fixed, c.FixError)
+
+	stored, err := keyring.Get(auth.KeyringService, auth.KeyringUser)
+	require.NoError(t, err)
+	assert.Equal(t, data, stored)
+	assert.NoFileExists(t, tokenFile)
+	assert.Equal(t, "OS keychain", d.checkTokenStorage().Detail)
+}
+
+func TestDoctor_WebsocatInstallNeedsConfirmation(t *testing.T) {
+	d := newTestDoctorCmd(t)
+	d.lookPath = func(file string) (string, error) {
+		if file == "brew" {
+			return "/opt/homebrew/bin/brew", nil
+		}
+		return "", errors.New("not found")
+	}
+	var ran [][]string
+	d.run = func(args []string) error {
+		ran = append(ran, args)
+		return nil
+	}
+
+	c := d.checkWebsocat()
+	assert.Equal(t, "install websocat with 'brew install websocat'", c.FixDescription)
+
+	d.applyFix(&c, false, true)
+	assert.False(t, c.Fixed)
+	assert.Empty(t, ran)
+
+	// JSON output never prompts, so installs need --yes
+	d.confirm = func(msg string) bool { return true }
+	d.applyFix(&c, false, false)
+	assert.Empty(t, ran)
+
+	d.applyFix(&c, false, true)
+	assert.True(t, c.Fixed)
+	assert.Equal(t, [][]string{{"brew", "install", "websocat"}}, ran)
+}
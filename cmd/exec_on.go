@@ -0,0 +1,347 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path"
+	"slices"
+	"strings"
+	"syscall"
+	"text/template"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+const execOnPageSize = 100
+
+// execOnEvents are the event names exec-on can detect.
+var execOnEvents = []string{
+	"browser.created",
+	"browser.deleted",
+	"invocation.created",
+	"invocation.succeeded",
+	"invocation.failed",
+}
+
+// execOnEvent is what KERNEL_EVENT_JSON holds; --run templates see its
+// fields shell-quoted (see execOnTemplateData).
+type execOnEvent struct {
+	Event  string    `json:"event"`
+	ID     string    `json:"id"`
+	Name   string    `json:"name,omitempty"`
+	Status string    `json:"status,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+type ExecOnInput struct {
+	// Events are event names or path.Match patterns such as "browser.*".
+	Events   []string
+	Run      string
+	Interval time.Duration
+}
+
+// ExecOnCmd runs a local command for each matching resource event. The API
+// has no event stream, so events are derived by polling resource lists and
+// comparing each round with the last.
+type ExecOnCmd struct {
+	browsers    BrowsersService
+	invocations InvocationLister
+	// run executes one rendered command; tests replace it.
+	run func(ctx context.Context, command string, env []string) error
+}
+
+func (e ExecOnCmd) ExecOn(ctx context.Context, in ExecOnInput) error {
+	if len(in.Events) == 0 {
+		return fmt.Errorf("at least one --event is required (one of %s)", strings.Join(execOnEvents, ", "))
+	}
+	var wanted []string
+	for _, pattern := range in.Events {
+		var matched bool
+		for _, name := range execOnEvents {
+			if ok, err := path.Match(pattern, name); err != nil {
+				return fmt.Errorf("invalid --event %q: %w", pattern, err)
+			} else if ok {
+				matched = true
+				if !slices.Contains(wanted, name) {
+					wanted = append(wanted, name)
+				}
+			}
+		}
+		if !matched {
+			return fmt.Errorf("unknown --event %q: must match one of %s", pattern, strings.Join(execOnEvents, ", "))
+		}
+	}
+	tmpl, err := template.New("run").Option("missingkey=error").Parse(in.Run)
+	if err != nil {
+		return fmt.Errorf("invalid --run template: %w", err)
+	}
+	if in.Interval <= 0 {
+		in.Interval = 5 * time.Second
+	}
+	if e.run == nil {
+		if _, err := exec.LookPath("sh"); err != nil {
+			return fmt.Errorf("exec-on runs --run with sh, which wasn't found in PATH (on Windows, run it from Git Bash or WSL)")
+		}
+		e.run = runLocalShell
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	p := execOnPoller{
+		e:           e,
+		browsers:    slices.ContainsFunc(wanted, func(n string) bool { return strings.HasPrefix(n, "browser.") }),
+		invocations: slices.ContainsFunc(wanted, func(n string) bool { return strings.HasPrefix(n, "invocation.") }),
+		since:       time.Now(),
+	}
+	// The first round only records what already exists.
+	if _, err := p.poll(ctx); err != nil {
+		return err
+	}
+	pterm.Info.Printf("Watching for %s every %s (Ctrl+C to stop)\n", strings.Join(wanted, ", "), in.Interval)
+
+	ticker := time.NewTicker(in.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		events, err := p.poll(ctx)
+		if err != nil {
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return nil
+			}
+			// A failed round is retried on the next tick; state is unchanged.
+			pterm.Warning.Printf("Poll failed: %v\n", err)
+			continue
+		}
+		for _, ev := range events {
+			if !slices.Contains(wanted, ev.Event) {
+				continue
+			}
+			if err := e.dispatch(ctx, tmpl, ev); err != nil {
+				pterm.Warning.Printf("%s %s: %v\n", ev.Event, ev.ID, err)
+			}
+		}
+	}
+}
+
+// execOnTemplateData is an event with every field shell-quoted, since names
+// come from the API and must not be able to inject commands into --run.
+type execOnTemplateData struct {
+	Event, ID, Name, Status, Time string
+}
+
+func (e ExecOnCmd) dispatch(ctx context.Context, tmpl *template.Template, ev execOnEvent) error {
+	var b strings.Builder
+	err := tmpl.Execute(&b, execOnTemplateData{
+		Event:  shellQuote(ev.Event),
+		ID:     shellQuote(ev.ID),
+		Name:   shellQuote(ev.Name),
+		Status: shellQuote(ev.Status),
+		Time:   shellQuote(ev.Time.Format(time.RFC3339)),
+	})
+	if err != nil {
+		return fmt.Errorf("render --run: %w", err)
+	}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	env := []string{
+		"KERNEL_EVENT=" + ev.Event,
+		"KERNEL_EVENT_ID=" + ev.ID,
+		"KERNEL_EVENT_NAME=" + ev.Name,
+		"KERNEL_EVENT_STATUS=" + ev.Status,
+		"KERNEL_EVENT_JSON=" + string(data),
+	}
+	pterm.Info.Printf("%s %s: %s\n", ev.Event, ev.ID, b.String())
+	return e.run(ctx, b.String(), env)
+}
+
+func runLocalShell(ctx context.Context, command string, env []string) error {
+	c := exec.CommandContext(ctx, "sh", "-c", command)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	c.Env = append(os.Environ(), env...)
+	return c.Run()
+}
+
+// shellQuote single-quotes s for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// execOnPoller diffs successive resource lists into events.
+type execOnPoller struct {
+	e           ExecOnCmd
+	browsers    bool
+	invocations bool
+	since       time.Time
+
+	seeded          bool
+	liveBrowsers    map[string]string
+	invocationState map[string]string
+}
+
+func (p *execOnPoller) poll(ctx context.Context) ([]execOnEvent, error) {
+	var (
+		browsers    map[string]kernel.BrowserListResponse
+		invocations []kernel.InvocationListResponse
+		err         error
+	)
+	if p.browsers {
+		if browsers, err = p.listBrowsers(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if p.invocations {
+		if invocations, err = p.listInvocations(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	var events []execOnEvent
+	if p.browsers {
+		next := make(map[string]string, len(browsers))
+		for id, br := range browsers {
+			next[id] = br.Name
+			if _, ok := p.liveBrowsers[id]; !ok && p.seeded {
+				events = append(events, execOnEvent{Event: "browser.created", ID: id, Name: br.Name, Status: "active", Time: br.CreatedAt})
+			}
+		}
+		for id, name := range p.liveBrowsers {
+			if _, ok := next[id]; !ok {
+				events = append(events, execOnEvent{Event: "browser.deleted", ID: id, Name: name, Status: "deleted", Time: now})
+			}
+		}
+		p.liveBrowsers = next
+	}
+	if p.invocations {
+		if p.invocationState == nil {
+			p.invocationState = map[string]string{}
+		}
+		for _, inv := range invocations {
+			status := string(inv.Status)
+			prev, seen := p.invocationState[inv.ID]
+			p.invocationState[inv.ID] = status
+			if !p.seeded || prev == status {
+				continue
+			}
+			name := inv.AppName + ":" + inv.ActionName
+			if !seen {
+				events = append(events, execOnEvent{Event: "invocation.created", ID: inv.ID, Name: name, Status: status, Time: inv.StartedAt})
+			}
+			switch inv.Status {
+			case kernel.InvocationListResponseStatusSucceeded, kernel.InvocationListResponseStatusFailed:
+				events = append(events, execOnEvent{Event: "invocation." + status, ID: inv.ID, Name: name, Status: status, Time: inv.FinishedAt})
+			}
+		}
+	}
+	p.seeded = true
+	slices.SortStableFunc(events, func(a, b execOnEvent) int { return a.Time.Compare(b.Time) })
+	return events, nil
+}
+
+func (p *execOnPoller) listBrowsers(ctx context.Context) (map[string]kernel.BrowserListResponse, error) {
+	out := map[string]kernel.BrowserListResponse{}
+	for offset := int64(0); ; offset += execOnPageSize {
+		page, err := p.e.browsers.List(ctx, kernel.BrowserListParams{
+			Status: kernel.BrowserListParamsStatusActive,
+			Limit:  kernel.Opt(int64(execOnPageSize)),
+			Offset: kernel.Opt(offset),
+		})
+		if err != nil {
+			return nil, util.CleanedUpSdkError{Err: err}
+		}
+		if page == nil {
+			break
+		}
+		for _, br := range page.Items {
+			out[br.SessionID] = br
+		}
+		if len(page.Items) < execOnPageSize {
+			break
+		}
+	}
+	return out, nil
+}
+
+// listInvocations returns invocations started since exec-on did.
+func (p *execOnPoller) listInvocations(ctx context.Context) ([]kernel.InvocationListResponse, error) {
+	var out []kernel.InvocationListResponse
+	for offset := int64(0); ; offset += execOnPageSize {
+		page, err := p.e.invocations.List(ctx, kernel.InvocationListParams{
+			Since:  kernel.Opt(p.since.UTC().Format(time.RFC3339)),
+			Limit:  kernel.Opt(int64(execOnPageSize)),
+			Offset: kernel.Opt(offset),
+		})
+		if err != nil {
+			return nil, util.CleanedUpSdkError{Err: err}
+		}
+		if page == nil {
+			break
+		}
+		out = append(out, page.Items...)
+		if len(page.Items) < execOnPageSize {
+			break
+		}
+	}
+	return out, nil
+}
+
+var execOnCmd = &cobra.Command{
+	Use:   "exec-on",
+	Short: "Run a local command whenever a resource event happens",
+	Long: `Run a local shell command for each matching event, e.g. to tag new browsers or
+notify on failed invocations, until interrupted.
+
+Events: ` + strings.Join(execOnEvents, ", ") + `
+--event accepts patterns such as 'browser.*' and can be repeated.
+
+--run is a Go template over the event: {{.Event}}, {{.ID}}, {{.Name}},
+{{.Status}} and {{.Time}}. Every value is inserted already shell-quoted, so
+don't wrap them in quotes yourself. The command also gets KERNEL_EVENT,
+KERNEL_EVENT_ID, KERNEL_EVENT_NAME, KERNEL_EVENT_STATUS and KERNEL_EVENT_JSON
+in its environment. Commands run with sh, so on Windows exec-on needs Git
+Bash or WSL.
+
+Events are detected by polling every --interval, so a browser created and
+deleted between two polls is missed, and only invocations started after
+exec-on do are tracked. Commands run one at a time; a failing command is
+reported and watching continues.`,
+	Example: `  kernel exec-on --event browser.created --run './tag.sh {{.ID}}'
+  kernel exec-on --event invocation.failed --run 'notify-send "Invocation failed" {{.Name}}'`,
+	Args: cobra.NoArgs,
+	RunE: runExecOn,
+}
+
+func init() {
+	execOnCmd.Flags().StringArray("event", nil, "Event name or pattern to react to (repeatable)")
+	execOnCmd.Flags().String("run", "", "Command to run for each event (Go template, run with sh -c)")
+	execOnCmd.Flags().Duration("interval", 5*time.Second, "How often to poll for changes")
+	_ = execOnCmd.MarkFlagRequired("event")
+	_ = execOnCmd.MarkFlagRequired("run")
+	_ = execOnCmd.RegisterFlagCompletionFunc("event", cobra.FixedCompletions(execOnEvents, cobra.ShellCompDirectiveNoFileComp))
+}
+
+func runExecOn(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	events, _ := cmd.Flags().GetStringArray("event")
+	run, _ := cmd.Flags().GetString("run")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	e := ExecOnCmd{browsers: &client.Browsers, invocations: &client.Invocations}
+	return e.ExecOn(cmd.Context(), ExecOnInput{Events: events, Run: run, Interval: interval})
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/kernel/kernel-go-sdk/packages/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// execOnBrowsers returns a fake whose List serves rounds[i] on the i-th call,
+// repeating the last round afterwards.
+func execOnBrowsers(rounds ...[]kernel.BrowserListResponse) *FakeBrowsersService {
+	var calls atomic.Int32
+	return &FakeBrowsersService{ListFunc: func(ctx context.Context, query kernel.BrowserListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.BrowserListResponse], error) {
+		i := int(calls.Add(1)) - 1
+		if i >= len(rounds) {
+			i = len(rounds) - 1
+		}
+		return &pagination.OffsetPagination[kernel.BrowserListResponse]{Items: rounds[i]}, nil
+	}}
+}
+
+func TestExecOnPoller_BrowserCreatedAndDeleted(t *testing.T) {
+	old := kernel.BrowserListResponse{SessionID: "old"}
+	fresh := kernel.BrowserListResponse{SessionID: "new", Name: "worker-1"}
+	p := execOnPoller{e: ExecOnCmd{browsers: execOnBrowsers([]kernel.BrowserListResponse{old}, []kernel.BrowserListResponse{fresh})}, browsers: true}
+
+	events, err := p.poll(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, events, "the first round only records existing browsers")
+
+	events, err = p.poll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	byName := map[string]execOnEvent{}
+	for _, ev := range events {
+		byName[ev.Event] = ev
+	}
+	assert.Equal(t, "new", byName["browser.created"].ID)
+	assert.Equal(t, "worker-1", byName["browser.created"].Name)
+	assert.Equal(t, "old", byName["browser.deleted"].ID)
+}
+
+func TestExecOnPoller_InvocationTransitions(t *testing.T) {
+	round := 0
+	rounds := [][]kernel.InvocationListResponse{
+		nil,
+		{{ID: "inv-1", AppName: "app", ActionName: "run", Status: kernel.InvocationListResponseStatusRunning}},
+		{{ID: "inv-1", AppName: "app", ActionName: "run", Status: kernel.InvocationListResponseStatusFailed}},
+	}
+	lister := &fakeInvocationListerFunc{fn: func(query kernel.InvocationListParams) []kernel.InvocationListResponse {
+		assert.True(t, query.Since.Valid(), "only invocations started since exec-on are listed")
+		items := rounds[round]
+		round++
+		return items
+	}}
+	p := execOnPoller{e: ExecOnCmd{invocations: lister}, invocations: true, since: time.Now()}
+
+	_, err := p.poll(context.Background())
+	require.NoError(t, err)
+	events, err := p.poll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "invocation.created", events[0].Event)
+	assert.Equal(t, "app:run", events[0].Name)
+
+	events, err = p.poll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "invocation.failed", events[0].Event)
+}
+
+func TestExecOn_RunsTemplatedCommand(t *testing.T) {
+	setupStdoutCapture(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var gotCommand string
+	var gotEnv []string
+	e := ExecOnCmd{
+		browsers: execOnBrowsers(nil, []kernel.BrowserListResponse{{SessionID: "b1", Name: "it's"}}),
+		run: func(ctx context.Context, command string, env []string) error {
+			gotCommand, gotEnv = command, env
+			cancel()
+			return nil
+		},
+	}
+
+	err := e.ExecOn(ctx, ExecOnInput{Events: []string{"browser.*"}, Run: "./tag.sh {{.ID}} {{.Name}}", Interval: time.Millisecond})
+	require.NoError(t, err)
+	assert.Equal(t, `./tag.sh 'b1' 'it'\''s'`, gotCommand, "every value is shell-quoted")
+	assert.Contains(t, gotEnv, "KERNEL_EVENT=browser.created")
+	assert.Contains(t, gotEnv, "KERNEL_EVENT_ID=b1")
+	assert.Contains(t, gotEnv, "KERNEL_EVENT_NAME=it's")
+}
+
+func TestExecOn_RejectsUnknownEvent(t *testing.T) {
+	e := ExecOnCmd{browsers: &FakeBrowsersService{}}
+
+	err := e.ExecOn(context.Background(), ExecOnInput{Events: []string{"profile.created"}, Run: "true"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown --event "profile.created"`)
+}
+
+type fakeInvocationListerFunc struct {
+	fn func(query kernel.InvocationListParams) []kernel.InvocationListResponse
+}
+
+func (f *fakeInvocationListerFunc) List(ctx context.Context, query kernel.InvocationListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.InvocationListResponse], error) {
+	return &pagination.OffsetPagination[kernel.InvocationListResponse]{Items: f.fn(query)}, nil
+}
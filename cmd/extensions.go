@@ -1,13 +1,16 @@
 package cmd
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/kernel/cli/pkg/extensions"
@@ -56,6 +59,9 @@ type ExtensionsListInput struct {
 	Limit  int
 	Offset int
 	Output string
+	// Versions reads each extension's manifest version, which costs one
+	// archive download per extension.
+	Versions bool
 }
 
 type ExtensionsGetInput struct {
@@ -66,11 +72,14 @@ type ExtensionsGetInput struct {
 type ExtensionsDeleteInput struct {
 	Identifier  string
 	SkipConfirm bool
+	Output      string
 }
 
 type ExtensionsDownloadInput struct {
 	Identifier string
-	Output     string
+	// Output is the directory the archive is extracted into.
+	Output       string
+	OutputFormat string
 }
 
 type ExtensionsDownloadWebStoreInput struct {
@@ -80,7 +89,8 @@ type ExtensionsDownloadWebStoreInput struct {
 }
 
 type ExtensionsUploadInput struct {
-	Dir    string
+	// Path is an unpacked extension directory or a .zip of one.
+	Path   string
 	Name   string
 	Output string
 }
@@ -115,31 +125,63 @@ func (e ExtensionsCmd) List(ctx context.Context, in ExtensionsListInput) error {
 		items = page.Items
 	}
 
+	var versions []string
+	if in.Versions {
+		versions = make([]string, len(items))
+		for i, it := range items {
+			v, err := e.extensionVersion(ctx, it.ID)
+			if err != nil {
+				pterm.Debug.Printf("Failed to read version of extension %s: %v\n", it.ID, err)
+			}
+			versions[i] = v
+		}
+	}
+
 	if in.Output == "json" {
 		if len(items) == 0 {
-			fmt.Println("[]")
-			return nil
+			return util.PrintJSON([]any{})
+		}
+		if versions == nil {
+			return util.PrintPrettyJSONSlice(items)
 		}
-		return util.PrintPrettyJSONSlice(items)
+		util.WarnDecodeDrift(items)
+		out := make([]map[string]any, len(items))
+		for i, it := range items {
+			if err := json.Unmarshal([]byte(it.RawJSON()), &out[i]); err != nil || out[i] == nil {
+				out[i] = map[string]any{"id": it.ID}
+			}
+			if versions[i] != "" {
+				out[i]["version"] = versions[i]
+			}
+		}
+		return util.PrintJSON(out)
 	}
+	util.WarnDecodeDrift(items)
 
 	if len(items) == 0 {
 		pterm.Info.Println("No extensions found")
 		return nil
 	}
-	rows := pterm.TableData{{"Extension ID", "Name", "Created At", "Size (bytes)", "Last Used At"}}
-	for _, it := range items {
+	header := []string{"Extension ID", "Name", "Created At", "Size (bytes)", "Last Used At"}
+	if versions != nil {
+		header = []string{"Extension ID", "Name", "Version", "Created At", "Size (bytes)", "Last Used At"}
+	}
+	rows := pterm.TableData{header}
+	for i, it := range items {
 		name := it.Name
 		if name == "" {
 			name = "-"
 		}
-		rows = append(rows, []string{
-			it.ID,
-			name,
+		row := []string{it.ID, name}
+		if versions != nil {
+			row = append(row, util.FirstOrDash(versions[i]))
+		}
+		row = append(row,
 			util.FormatLocal(it.CreatedAt),
 			fmt.Sprintf("%d", it.SizeBytes),
 			util.FormatLocal(it.LastUsedAt),
-		})
+		)
+		rows = append(rows, row)
 	}
 	PrintTableNoPad(rows, true)
 	return nil
@@ -162,6 +204,7 @@ func (e ExtensionsCmd) Get(ctx context.Context, in ExtensionsGetInput) error {
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(item)
 	}
+	util.WarnDecodeDrift(item)
 
 	name := item.Name
 	if name == "" {
@@ -178,10 +221,16 @@ func (e ExtensionsCmd) Get(ctx context.Context, in ExtensionsGetInput) error {
 }
 
 func (e ExtensionsCmd) Delete(ctx context.Context, in ExtensionsDeleteInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
 	if in.Identifier == "" {
 		pterm.Error.Println("Missing identifier")
 		return nil
 	}
+	if in.Output == "json" && !in.SkipConfirm {
+		return fmt.Errorf("--output json requires --yes, since it can't prompt for confirmation")
+	}
 
 	if !in.SkipConfirm {
 		msg := fmt.Sprintf("Are you sure you want to delete extension '%s'?", in.Identifier)
@@ -195,16 +244,25 @@ func (e ExtensionsCmd) Delete(ctx context.Context, in ExtensionsDeleteInput) err
 
 	if err := e.extensions.Delete(ctx, in.Identifier); err != nil {
 		if util.IsNotFound(err) {
+			if in.Output == "json" {
+				return util.PrintJSON(map[string]any{"id": in.Identifier, "deleted": false})
+			}
 			pterm.Info.Printf("Extension '%s' not found\n", in.Identifier)
 			return nil
 		}
 		return util.CleanedUpSdkError{Err: err}
 	}
+	if in.Output == "json" {
+		return util.PrintJSON(map[string]any{"id": in.Identifier, "deleted": true})
+	}
 	pterm.Success.Printf("Deleted extension: %s\n", in.Identifier)
 	return nil
 }
 
 func (e ExtensionsCmd) Download(ctx context.Context, in ExtensionsDownloadInput) error {
+	if err := validateJSONOutput(in.OutputFormat); err != nil {
+		return err
+	}
 	if in.Identifier == "" {
 		pterm.Error.Println("Missing identifier")
 		return nil
@@ -266,6 +324,15 @@ func (e ExtensionsCmd) Download(ctx context.Context, in ExtensionsDownloadInput)
 		pterm.Error.Printf("Failed to extract zip: %v\n", err)
 		return nil
 	}
+	if in.OutputFormat == "json" {
+		out := map[string]any{"id": in.Identifier, "path": outDir}
+		if manifest, err := os.ReadFile(filepath.Join(outDir, "manifest.json")); err == nil {
+			if v := manifestVersion(manifest); v != "" {
+				out["version"] = v
+			}
+		}
+		return util.PrintJSON(out)
+	}
 	pterm.Success.Printf("Extracted extension to %s\n", outDir)
 	return nil
 }
@@ -358,37 +425,43 @@ func (e ExtensionsCmd) Upload(ctx context.Context, in ExtensionsUploadInput) err
 		return err
 	}
 
-	if in.Dir == "" {
+	if in.Path == "" {
 		return fmt.Errorf("missing directory argument")
 	}
-	absDir, err := filepath.Abs(in.Dir)
+	absPath, err := filepath.Abs(in.Path)
 	if err != nil {
-		return fmt.Errorf("failed to resolve directory: %w", err)
-	}
-	stat, err := os.Stat(absDir)
-	if err != nil || !stat.IsDir() {
-		return fmt.Errorf("directory %s does not exist", absDir)
+		return fmt.Errorf("failed to resolve path: %w", err)
 	}
-
-	// Pre-flight size check
-	if in.Output != "json" {
-		pterm.Info.Println("Compressing extension directory...")
+	stat, err := os.Stat(absPath)
+	if err != nil {
+		return fmt.Errorf("%s does not exist", absPath)
 	}
 
-	tmpFile := filepath.Join(os.TempDir(), fmt.Sprintf("kernel_ext_%d.zip", time.Now().UnixNano()))
-
-	if err := util.ZipDirectory(absDir, tmpFile, &defaultExtensionExclusions); err != nil {
-		pterm.Error.Println("Failed to zip directory")
-		return err
+	var bundle string
+	switch {
+	case stat.IsDir():
+		// Pre-flight size check
+		if in.Output != "json" {
+			pterm.Info.Println("Compressing extension directory...")
+		}
+		bundle = filepath.Join(os.TempDir(), fmt.Sprintf("kernel_ext_%d.zip", time.Now().UnixNano()))
+		if err := util.ZipDirectory(absPath, bundle, &defaultExtensionExclusions); err != nil {
+			pterm.Error.Println("Failed to zip directory")
+			return err
+		}
+		defer os.Remove(bundle)
+	case strings.EqualFold(filepath.Ext(absPath), ".zip"):
+		bundle = absPath
+	default:
+		return fmt.Errorf("%s is not a directory or a .zip file", absPath)
 	}
-	defer os.Remove(tmpFile)
 
-	fileInfo, err := os.Stat(tmpFile)
+	fileInfo, err := os.Stat(bundle)
 	if err != nil {
 		return fmt.Errorf("failed to stat zip: %w", err)
 	}
 
-	if in.Output != "json" {
+	if in.Output != "json" && stat.IsDir() {
 		pterm.Success.Printf("Created bundle: %s\n", util.FormatBytes(fileInfo.Size()))
 	}
 
@@ -402,9 +475,16 @@ func (e ExtensionsCmd) Upload(ctx context.Context, in ExtensionsUploadInput) err
 		return fmt.Errorf("bundle exceeds maximum size")
 	}
 
-	f, err := os.Open(tmpFile)
+	zr, err := zip.OpenReader(bundle)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid zip: %w", bundle, err)
+	}
+	version := zipManifestVersion(&zr.Reader)
+	_ = zr.Close()
+
+	f, err := os.Open(bundle)
 	if err != nil {
-		return fmt.Errorf("failed to open temp zip: %w", err)
+		return fmt.Errorf("failed to open zip: %w", err)
 	}
 	defer f.Close()
 
@@ -425,6 +505,7 @@ func (e ExtensionsCmd) Upload(ctx context.Context, in ExtensionsUploadInput) err
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(item)
 	}
+	util.WarnDecodeDrift(item)
 
 	name := item.Name
 	if name == "" {
@@ -433,12 +514,65 @@ func (e ExtensionsCmd) Upload(ctx context.Context, in ExtensionsUploadInput) err
 	rows := pterm.TableData{{"Property", "Value"}}
 	rows = append(rows, []string{"ID", item.ID})
 	rows = append(rows, []string{"Name", name})
+	if version != "" {
+		rows = append(rows, []string{"Version", version})
+	}
 	rows = append(rows, []string{"Created At", util.FormatLocal(item.CreatedAt)})
 	rows = append(rows, []string{"Size (bytes)", fmt.Sprintf("%d", item.SizeBytes)})
 	PrintTableNoPad(rows, true)
 	return nil
 }
 
+// extensionVersion downloads an extension's archive and returns the version
+// from its manifest.json.
+func (e ExtensionsCmd) extensionVersion(ctx context.Context, idOrName string) (string, error) {
+	res, err := e.extensions.Download(ctx, idOrName)
+	if err != nil {
+		return "", util.CleanedUpSdkError{Err: err}
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read archive: %w", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("archive is not a valid zip: %w", err)
+	}
+	return zipManifestVersion(zr), nil
+}
+
+// zipManifestVersion returns the version from the manifest.json at the root
+// of an extension archive, or "" if it has none.
+func zipManifestVersion(zr *zip.Reader) string {
+	for _, f := range zr.File {
+		if f.Name != "manifest.json" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return ""
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(io.LimitReader(rc, 1<<20))
+		if err != nil {
+			return ""
+		}
+		return manifestVersion(data)
+	}
+	return ""
+}
+
+func manifestVersion(manifest []byte) string {
+	var m struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(manifest, &m); err != nil {
+		return ""
+	}
+	return m.Version
+}
+
 // --- Cobra wiring ---
 
 var extensionsCmd = &cobra.Command{
@@ -457,9 +591,10 @@ var extensionsListCmd = &cobra.Command{
 		output, _ := cmd.Flags().GetString("output")
 		limit, _ := cmd.Flags().GetInt("limit")
 		offset, _ := cmd.Flags().GetInt("offset")
+		versions, _ := cmd.Flags().GetBool("versions")
 		svc := client.Extensions
 		e := ExtensionsCmd{extensions: &svc}
-		return e.List(cmd.Context(), ExtensionsListInput{Limit: limit, Offset: offset, Output: output})
+		return e.List(cmd.Context(), ExtensionsListInput{Limit: limit, Offset: offset, Output: output, Versions: versions})
 	},
 }
 
@@ -483,9 +618,10 @@ var extensionsDeleteCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client := getKernelClient(cmd)
 		skip, _ := cmd.Flags().GetBool("yes")
+		output, _ := cmd.Flags().GetString("output")
 		svc := client.Extensions
 		e := ExtensionsCmd{extensions: &svc}
-		return e.Delete(cmd.Context(), ExtensionsDeleteInput{Identifier: args[0], SkipConfirm: skip})
+		return e.Delete(cmd.Context(), ExtensionsDeleteInput{Identifier: args[0], SkipConfirm: skip, Output: output})
 	},
 }
 
@@ -496,9 +632,10 @@ var extensionsDownloadCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client := getKernelClient(cmd)
 		out, _ := cmd.Flags().GetString("to")
+		output, _ := cmd.Flags().GetString("output")
 		svc := client.Extensions
 		e := ExtensionsCmd{extensions: &svc}
-		return e.Download(cmd.Context(), ExtensionsDownloadInput{Identifier: args[0], Output: out})
+		return e.Download(cmd.Context(), ExtensionsDownloadInput{Identifier: args[0], Output: out, OutputFormat: output})
 	},
 }
 
@@ -517,16 +654,19 @@ var extensionsDownloadWebStoreCmd = &cobra.Command{
 }
 
 var extensionsUploadCmd = &cobra.Command{
-	Use:   "upload <directory>",
-	Short: "Upload an unpacked browser extension directory",
-	Args:  cobra.ExactArgs(1),
+	Use:   "upload <directory|zip>",
+	Short: "Upload an unpacked browser extension directory or a zip of one",
+	Long: `Upload a browser extension. A directory is zipped first, skipping files
+matched by .gitignore and common build and test artifacts; a .zip is uploaded
+as is.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client := getKernelClient(cmd)
 		name, _ := cmd.Flags().GetString("name")
 		output, _ := cmd.Flags().GetString("output")
 		svc := client.Extensions
 		e := ExtensionsCmd{extensions: &svc}
-		return e.Upload(cmd.Context(), ExtensionsUploadInput{Dir: args[0], Name: name, Output: output})
+		return e.Upload(cmd.Context(), ExtensionsUploadInput{Path: args[0], Name: name, Output: output})
 	},
 }
 
@@ -535,7 +675,8 @@ var extensionsBuildWebBotAuthCmd = &cobra.Command{
 	Short: "Build the Cloudflare web-bot-auth extension for Kernel",
 	Long: `Download, build, and prepare the Cloudflare web-bot-auth extension with Kernel-specific configurations.
 					Defaults to RFC9421 test key (works with Cloudflare's test site).
-					Uploads it to Kernel as 'web-bot-auth'. Optionally accepts a custom JWK or PEM key file.`,
+					Uploads it to Kernel as 'web-bot-auth'. Optionally accepts a custom JWK or PEM key file.
+					Building needs Node.js and npm; use --prebuilt on machines without them.`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		output, _ := cmd.Flags().GetString("to")
@@ -543,6 +684,11 @@ var extensionsBuildWebBotAuthCmd = &cobra.Command{
 		keyPath, _ := cmd.Flags().GetString("key")
 		uploadName, _ := cmd.Flags().GetString("upload")
 		signatureAgentURL, _ := cmd.Flags().GetString("signature-agent")
+		prebuilt, _ := cmd.Flags().GetBool("prebuilt")
+		prebuiltURL, _ := cmd.Flags().GetString("prebuilt-url")
+		noCache, _ := cmd.Flags().GetBool("no-cache")
+		sourceDir, _ := cmd.Flags().GetString("source-dir")
+		archiveSHA256, _ := cmd.Flags().GetString("sha256")
 		// Use upload name for extension name, or default to "web-bot-auth"
 		extensionName := "web-bot-auth"
 		if uploadName != "" {
@@ -557,6 +703,11 @@ var extensionsBuildWebBotAuthCmd = &cobra.Command{
 			ExtensionName:     extensionName,
 			AutoUpload:        uploadName != "",
 			SignatureAgentURL: signatureAgentURL,
+			Prebuilt:          prebuilt,
+			PrebuiltURL:       prebuiltURL,
+			NoCache:           noCache,
+			SourceDir:         sourceDir,
+			ArchiveSHA256:     archiveSHA256,
 		})
 		if err != nil {
 			return err
@@ -569,7 +720,7 @@ var extensionsBuildWebBotAuthCmd = &cobra.Command{
 			e := ExtensionsCmd{extensions: &svc}
 			pterm.Info.Println("Uploading extension to Kernel...")
 			return e.Upload(cmd.Context(), ExtensionsUploadInput{
-				Dir:  result.OutputDir,
+				Path: result.OutputDir,
 				Name: extensionName,
 			})
 		}
@@ -589,8 +740,11 @@ func init() {
 
 	addJSONOutputFlag(extensionsListCmd)
 	addJSONOutputFlag(extensionsGetCmd)
+	addJSONOutputFlag(extensionsDeleteCmd)
+	addJSONOutputFlag(extensionsDownloadCmd)
 	extensionsListCmd.Flags().Int("limit", 0, "Maximum number of extensions to return")
 	extensionsListCmd.Flags().Int("offset", 0, "Number of extensions to skip (for pagination)")
+	extensionsListCmd.Flags().Bool("versions", false, "Show each extension's manifest version (downloads every archive)")
 	extensionsDeleteCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
 	extensionsDownloadCmd.Flags().String("to", "", "Output zip file path")
 	extensionsDownloadWebStoreCmd.Flags().String("to", "", "Output zip file path for the downloaded archive")
@@ -601,5 +755,10 @@ func init() {
 	extensionsBuildWebBotAuthCmd.Flags().String("url", "http://127.0.0.1:10001", "Base URL for update.xml and policy templates")
 	extensionsBuildWebBotAuthCmd.Flags().String("key", "", "Path to Ed25519 private key file (JWK or PEM format)")
 	extensionsBuildWebBotAuthCmd.Flags().String("upload", "", "Upload extension to Kernel with specified name (e.g., --upload web-bot-auth)")
+	extensionsBuildWebBotAuthCmd.Flags().Bool("prebuilt", false, "Start from the prebuilt release instead of building with npm; only the key and URLs are applied locally, so Node isn't needed")
+	extensionsBuildWebBotAuthCmd.Flags().String("prebuilt-url", "", "Prebuilt archive to use instead of the release (implies --prebuilt)")
+	extensionsBuildWebBotAuthCmd.Flags().Bool("no-cache", false, "Download the source archive again instead of using the copy cached in ~/.cache/kernel/sources")
+	extensionsBuildWebBotAuthCmd.Flags().String("sha256", "", "Expected sha256 of the downloaded archive; overrides the checksum pinned for the release")
+	extensionsBuildWebBotAuthCmd.Flags().String("source-dir", "", "Build from a local web-bot-auth checkout instead of downloading (for offline builds)")
 	extensionsBuildWebBotAuthCmd.Flags().String("signature-agent", "", "Base URL of the signature agent (e.g., https://agent.example.com). Verifiers will look up /.well-known/http-message-signatures-directory at this URL.")
 }
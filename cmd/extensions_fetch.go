@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var webStoreIDPattern = regexp.MustCompile(`^[a-p]{32}$`)
+
+type ExtensionsFetchInput struct {
+	// WebStoreID is a Chrome Web Store extension ID or listing URL.
+	WebStoreID string
+	Out        string
+	OS         string
+	Output     string
+}
+
+type extensionsFetchResult struct {
+	WebStoreID      string `json:"webstore_id,omitempty"`
+	Name            string `json:"name"`
+	Version         string `json:"version"`
+	ManifestVersion int    `json:"manifest_version"`
+	Path            string `json:"path"`
+}
+
+// Fetch downloads an extension from the Chrome Web Store and unpacks it into
+// a directory that loads as an unpacked extension and can be uploaded.
+func (e ExtensionsCmd) Fetch(ctx context.Context, in ExtensionsFetchInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	storeURL, id, err := webStoreURL(in.WebStoreID)
+	if err != nil {
+		return err
+	}
+	if in.Out == "" {
+		return fmt.Errorf("--out is required")
+	}
+	params := kernel.ExtensionDownloadFromChromeStoreParams{URL: storeURL}
+	switch in.OS {
+	case "", string(kernel.ExtensionDownloadFromChromeStoreParamsOsLinux):
+	case string(kernel.ExtensionDownloadFromChromeStoreParamsOsMac), string(kernel.ExtensionDownloadFromChromeStoreParamsOsWin):
+		params.Os = kernel.ExtensionDownloadFromChromeStoreParamsOs(in.OS)
+	default:
+		return fmt.Errorf("--os must be one of mac, win, linux")
+	}
+	outDir, err := filepath.Abs(in.Out)
+	if err != nil {
+		return fmt.Errorf("failed to resolve output path: %w", err)
+	}
+	if entries, err := os.ReadDir(outDir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("output directory must be empty: %s", outDir)
+	}
+
+	if in.Output != "json" {
+		pterm.Info.Printf("Downloading %s from the Chrome Web Store...\n", util.FirstOrDash(id, storeURL))
+	}
+	res, err := e.extensions.DownloadFromChromeStore(ctx, params)
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read download: %w", err)
+	}
+	payload, err := crxZipPayload(data)
+	if err != nil {
+		return err
+	}
+
+	tmpZip, err := os.CreateTemp("", "kernel-webstore-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create temp zip: %w", err)
+	}
+	defer os.Remove(tmpZip.Name())
+	if _, err := tmpZip.Write(payload); err != nil {
+		_ = tmpZip.Close()
+		return fmt.Errorf("failed to write temp zip: %w", err)
+	}
+	_ = tmpZip.Close()
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := util.Unzip(tmpZip.Name(), outDir); err != nil {
+		return fmt.Errorf("failed to extract extension: %w", err)
+	}
+	// Web Store packages carry signed hashes in _metadata; Chrome refuses to
+	// load unpacked extensions with "_" entries, so drop it.
+	if err := os.RemoveAll(filepath.Join(outDir, "_metadata")); err != nil {
+		return fmt.Errorf("failed to remove _metadata: %w", err)
+	}
+
+	result, err := readUnpackedExtension(outDir)
+	if err != nil {
+		return err
+	}
+	result.WebStoreID = id
+	if in.Output == "json" {
+		return util.PrintJSON(result)
+	}
+
+	rows := pterm.TableData{{"Property", "Value"}}
+	rows = append(rows, []string{"Name", result.Name})
+	rows = append(rows, []string{"Version", result.Version})
+	rows = append(rows, []string{"Manifest Version", fmt.Sprintf("%d", result.ManifestVersion)})
+	rows = append(rows, []string{"Path", result.Path})
+	PrintTableNoPad(rows, true)
+	if result.ManifestVersion < 3 {
+		pterm.Warning.Printf("%s is a Manifest V%d extension; current Chrome versions only run Manifest V3\n", result.Name, result.ManifestVersion)
+	}
+	pterm.Info.Printf("Upload it with: kernel extensions upload %s\n", outDir)
+	return nil
+}
+
+// webStoreURL turns an extension ID into its Web Store listing URL. URLs
+// are passed through, with the ID taken from the path when present.
+func webStoreURL(idOrURL string) (storeURL, id string, err error) {
+	idOrURL = strings.TrimSpace(idOrURL)
+	if webStoreIDPattern.MatchString(idOrURL) {
+		return "https://chromewebstore.google.com/detail/" + idOrURL, idOrURL, nil
+	}
+	if strings.HasPrefix(idOrURL, "https://") || strings.HasPrefix(idOrURL, "http://") {
+		for _, part := range strings.Split(idOrURL, "/") {
+			if webStoreIDPattern.MatchString(part) {
+				id = part
+			}
+		}
+		return idOrURL, id, nil
+	}
+	return "", "", fmt.Errorf("%q is not a Chrome Web Store extension ID (32 letters a-p) or URL", idOrURL)
+}
+
+// crxZipPayload returns the zip inside a CRX (v2 or v3) package, or data
+// itself when it's already a zip.
+func crxZipPayload(data []byte) ([]byte, error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return data, nil
+	}
+	if !bytes.HasPrefix(data, []byte("Cr24")) || len(data) < 12 {
+		return nil, fmt.Errorf("download is neither a CRX nor a zip")
+	}
+	var start uint64
+	switch version := binary.LittleEndian.Uint32(data[4:8]); version {
+	case 2:
+		if len(data) < 16 {
+			return nil, fmt.Errorf("truncated CRX header")
+		}
+		start = 16 + uint64(binary.LittleEndian.Uint32(data[8:12])) + uint64(binary.LittleEndian.Uint32(data[12:16]))
+	case 3:
+		start = 12 + uint64(binary.LittleEndian.Uint32(data[8:12]))
+	default:
+		return nil, fmt.Errorf("unsupported CRX version %d", version)
+	}
+	if start > uint64(len(data)) {
+		return nil, fmt.Errorf("truncated CRX header")
+	}
+	return data[start:], nil
+}
+
+// readUnpackedExtension reads the name, version and manifest version of the
+// extension in dir, resolving a localized name from its default locale.
+func readUnpackedExtension(dir string) (*extensionsFetchResult, error) {
+	b, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		return nil, fmt.Errorf("extension has no manifest.json: %w", err)
+	}
+	var manifest struct {
+		Name            string `json:"name"`
+		Version         string `json:"version"`
+		ManifestVersion int    `json:"manifest_version"`
+		DefaultLocale   string `json:"default_locale"`
+	}
+	// Manifests may start with a BOM
+	if err := json.Unmarshal(bytes.TrimPrefix(b, []byte("\xef\xbb\xbf")), &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest.json: %w", err)
+	}
+	name := manifest.Name
+	if key, ok := strings.CutPrefix(name, "__MSG_"); ok && manifest.DefaultLocale != "" {
+		key = strings.TrimSuffix(key, "__")
+		var messages map[string]struct {
+			Message string `json:"message"`
+		}
+		if b, err := os.ReadFile(filepath.Join(dir, "_locales", manifest.DefaultLocale, "messages.json")); err == nil {
+			_ = json.Unmarshal(bytes.TrimPrefix(b, []byte("\xef\xbb\xbf")), &messages)
+		}
+		for k, m := range messages {
+			if strings.EqualFold(k, key) && m.Message != "" {
+				name = m.Message
+				break
+			}
+		}
+	}
+	return &extensionsFetchResult{Name: name, Version: manifest.Version, ManifestVersion: manifest.ManifestVersion, Path: dir}, nil
+}
+
+var extensionsFetchCmd = &cobra.Command{
+	Use:   "fetch",
+	Short: "Download and unpack an extension from the Chrome Web Store",
+	Long: `Download an extension from the Chrome Web Store by ID and unpack it into a
+directory that Chrome loads as an unpacked extension, ready for
+"kernel extensions upload". The Web Store's _metadata signatures are removed,
+since unpacked extensions can't contain them.`,
+	Example: `  kernel extensions fetch --webstore-id cjpalhdlnbpafiamejdnhcphjbkeiagm --out ./ublock
+  kernel extensions upload ./ublock --name ublock`,
+	Args: cobra.NoArgs,
+	RunE: runExtensionsFetch,
+}
+
+func init() {
+	extensionsCmd.AddCommand(extensionsFetchCmd)
+	extensionsFetchCmd.Flags().String("webstore-id", "", "Chrome Web Store extension ID (or listing URL)")
+	extensionsFetchCmd.Flags().String("out", "", "Directory to unpack the extension into (must be empty or not exist)")
+	extensionsFetchCmd.Flags().String("os", "", "Target OS: mac, win, or linux (default linux)")
+	addJSONOutputFlag(extensionsFetchCmd)
+	_ = extensionsFetchCmd.MarkFlagRequired("webstore-id")
+	_ = extensionsFetchCmd.MarkFlagRequired("out")
+}
+
+func runExtensionsFetch(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	id, _ := cmd.Flags().GetString("webstore-id")
+	out, _ := cmd.Flags().GetString("out")
+	osFlag, _ := cmd.Flags().GetString("os")
+	output, _ := cmd.Flags().GetString("output")
+	svc := client.Extensions
+	e := ExtensionsCmd{extensions: &svc}
+	return e.Fetch(cmd.Context(), ExtensionsFetchInput{WebStoreID: id, Out: out, OS: osFlag, Output: output})
+}
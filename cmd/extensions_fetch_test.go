@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// crx3 wraps files in a CRX3 package with a dummy signed header.
+func crx3(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var zbuf bytes.Buffer
+	zw := zip.NewWriter(&zbuf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, _ = w.Write([]byte(content))
+	}
+	require.NoError(t, zw.Close())
+
+	header := []byte("signed header proto")
+	var crx bytes.Buffer
+	crx.WriteString("Cr24")
+	_ = binary.Write(&crx, binary.LittleEndian, uint32(3))
+	_ = binary.Write(&crx, binary.LittleEndian, uint32(len(header)))
+	crx.Write(header)
+	crx.Write(zbuf.Bytes())
+	return crx.Bytes()
+}
+
+func TestExtensionsFetch_UnpacksCRX(t *testing.T) {
+	const id = "cjpalhdlnbpafiamejdnhcphjbkeiagm"
+	pkg := crx3(t, map[string]string{
+		"manifest.json":                    `{"name":"__MSG_extName__","version":"1.58.0","manifest_version":3,"default_locale":"en"}`,
+		"_locales/en/messages.json":        `{"extName":{"message":"uBlock Origin Lite"}}`,
+		"_metadata/verified_contents.json": `[]`,
+		"js/background.js":                 `// bg`,
+	})
+	var gotURL string
+	fake := &FakeExtensionsService{DownloadFromChromeStoreFn: func(ctx context.Context, query kernel.ExtensionDownloadFromChromeStoreParams, opts ...option.RequestOption) (*http.Response, error) {
+		gotURL = query.URL
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewReader(pkg)), Header: http.Header{}}, nil
+	}}
+	e := ExtensionsCmd{extensions: fake}
+	outDir := filepath.Join(t.TempDir(), "ext")
+
+	var err error
+	out := captureStdout(t, func() {
+		err = e.Fetch(context.Background(), ExtensionsFetchInput{WebStoreID: id, Out: outDir, Output: "json"})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://chromewebstore.google.com/detail/"+id, gotURL)
+
+	var res extensionsFetchResult
+	require.NoError(t, json.Unmarshal([]byte(out), &res))
+	assert.Equal(t, extensionsFetchResult{WebStoreID: id, Name: "uBlock Origin Lite", Version: "1.58.0", ManifestVersion: 3, Path: outDir}, res)
+	assert.FileExists(t, filepath.Join(outDir, "js", "background.js"))
+	assert.NoDirExists(t, filepath.Join(outDir, "_metadata"))
+}
+
+func TestExtensionsFetch_RejectsBadInput(t *testing.T) {
+	e := ExtensionsCmd{extensions: &FakeExtensionsService{}}
+	err := e.Fetch(context.Background(), ExtensionsFetchInput{WebStoreID: "not-an-id", Out: t.TempDir()})
+	assert.ErrorContains(t, err, "not a Chrome Web Store extension ID")
+
+	full := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(full, "x"), nil, 0o644))
+	err = e.Fetch(context.Background(), ExtensionsFetchInput{WebStoreID: "cjpalhdlnbpafiamejdnhcphjbkeiagm", Out: full})
+	assert.ErrorContains(t, err, "must be empty")
+}
+
+func TestCRXZipPayload(t *testing.T) {
+	zipData := []byte("PK\x03\x04rest")
+	got, err := crxZipPayload(zipData)
+	require.NoError(t, err)
+	assert.Equal(t, zipData, got)
+
+	var v2 bytes.Buffer
+	v2.WriteString("Cr24")
+	_ = binary.Write(&v2, binary.LittleEndian, []uint32{2, 3, 2})
+	v2.WriteString("keysg")
+	v2.Write(zipData)
+	got, err = crxZipPayload(v2.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, zipData, got)
+
+	_, err = crxZipPayload([]byte("<html>"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+type ExtensionsKeygenInput struct {
+	Algo    string
+	OutPEM  string
+	OutJWK  string
+	OutJWKS string
+	Force   bool
+	Output  string
+}
+
+type extensionsKeygenResult struct {
+	Kid  string `json:"kid"`
+	X    string `json:"x"`
+	PEM  string `json:"pem,omitempty"`
+	JWK  string `json:"jwk,omitempty"`
+	JWKS string `json:"jwks,omitempty"`
+}
+
+// Keygen generates a web-bot-auth signing key and writes it as PEM, JWK
+// and/or the JWKS an origin should trust.
+func (e ExtensionsCmd) Keygen(in ExtensionsKeygenInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if !strings.EqualFold(in.Algo, "ed25519") {
+		return fmt.Errorf("unsupported --algo %q: web-bot-auth signs with ed25519", in.Algo)
+	}
+	if in.OutPEM == "" && in.OutJWK == "" && in.OutJWKS == "" {
+		return fmt.Errorf("pass at least one of --out-pem, --out-jwk or --out-jwks")
+	}
+	for _, path := range []string{in.OutPEM, in.OutJWK, in.OutJWKS} {
+		if path == "" || in.Force {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists; pass --force to overwrite", path)
+		}
+	}
+
+	pemData, err := util.GenerateEd25519PEM()
+	if err != nil {
+		return err
+	}
+	jwkData, err := util.ConvertPEMToJWK(string(pemData))
+	if err != nil {
+		return err
+	}
+	public, err := util.ToPublicJWK(jwkData)
+	if err != nil {
+		return err
+	}
+	jwks, err := json.MarshalIndent(util.JWKS{Keys: []util.PublicJWK{public}}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	res := extensionsKeygenResult{Kid: public.Kid, X: public.X}
+	// Private key files are only readable by the owner
+	if in.OutPEM != "" {
+		if err := os.WriteFile(in.OutPEM, pemData, 0600); err != nil {
+			return fmt.Errorf("failed to write PEM: %w", err)
+		}
+		res.PEM = in.OutPEM
+	}
+	if in.OutJWK != "" {
+		if err := os.WriteFile(in.OutJWK, []byte(jwkData+"\n"), 0600); err != nil {
+			return fmt.Errorf("failed to write JWK: %w", err)
+		}
+		res.JWK = in.OutJWK
+	}
+	if in.OutJWKS != "" {
+		if err := os.WriteFile(in.OutJWKS, append(jwks, '\n'), 0644); err != nil {
+			return fmt.Errorf("failed to write JWKS: %w", err)
+		}
+		res.JWKS = in.OutJWKS
+	}
+
+	if in.Output == "json" {
+		return util.PrintJSON(res)
+	}
+	rows := pterm.TableData{{"Property", "Value"}}
+	rows = append(rows, []string{"Key ID", res.Kid})
+	rows = append(rows, []string{"Public Key (x)", res.X})
+	rows = append(rows, []string{"PEM", util.FirstOrDash(res.PEM)})
+	rows = append(rows, []string{"JWK", util.FirstOrDash(res.JWK)})
+	rows = append(rows, []string{"JWKS", util.FirstOrDash(res.JWKS)})
+	PrintTableNoPad(rows, true)
+	if res.PEM != "" {
+		pterm.Info.Printf("Build an extension with it: kernel extensions build-web-bot-auth --key %s\n", res.PEM)
+	} else if res.JWK != "" {
+		pterm.Info.Printf("Build an extension with it: kernel extensions build-web-bot-auth --key %s\n", res.JWK)
+	}
+	return nil
+}
+
+type ExtensionsKeyVerifyInput struct {
+	PEMPath string
+	JWKPath string
+	Output  string
+}
+
+type extensionsKeyVerifyResult struct {
+	Match bool   `json:"match"`
+	Kid   string `json:"kid"`
+	X     string `json:"x"`
+}
+
+// VerifyKey checks that a PEM private key and a JWK (or a JWKS containing
+// it) are the same Ed25519 key.
+func (e ExtensionsCmd) VerifyKey(in ExtensionsKeyVerifyInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	pemData, err := os.ReadFile(in.PEMPath)
+	if err != nil {
+		return fmt.Errorf("failed to read PEM: %w", err)
+	}
+	jwkData, err := os.ReadFile(in.JWKPath)
+	if err != nil {
+		return fmt.Errorf("failed to read JWK: %w", err)
+	}
+	public, err := verifyKeyAgainstJWKOrJWKS(string(pemData), jwkData)
+	if err != nil {
+		return fmt.Errorf("%s and %s don't match: %w", in.PEMPath, in.JWKPath, err)
+	}
+
+	res := extensionsKeyVerifyResult{Match: true, Kid: public.Kid, X: public.X}
+	if in.Output == "json" {
+		return util.PrintJSON(res)
+	}
+	pterm.Success.Printf("%s and %s are the same key (kid %s)\n", in.PEMPath, in.JWKPath, res.Kid)
+	return nil
+}
+
+// verifyKeyAgainstJWKOrJWKS matches pemData against a single JWK, or against
+// any key of a JWKS.
+func verifyKeyAgainstJWKOrJWKS(pemData string, jwkData []byte) (util.PublicJWK, error) {
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.Unmarshal(jwkData, &set); err != nil || set.Keys == nil {
+		return util.VerifyKeyPair(pemData, string(jwkData))
+	}
+	var lastErr error = fmt.Errorf("the JWKS has no keys")
+	for _, key := range set.Keys {
+		public, err := util.VerifyKeyPair(pemData, string(key))
+		if err == nil {
+			return public, nil
+		}
+		lastErr = err
+	}
+	if len(set.Keys) > 1 {
+		return util.PublicJWK{}, fmt.Errorf("no key in the JWKS matches the PEM")
+	}
+	return util.PublicJWK{}, lastErr
+}
+
+var extensionsKeygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Generate a web-bot-auth signing key",
+	Long: `Generate an Ed25519 signing key for "kernel extensions build-web-bot-auth"
+and write it as a PKCS#8 PEM, a private JWK and/or the JWKS the origin should
+trust. Private key files are written with mode 0600.`,
+	Example: `  kernel extensions keygen --out-pem key.pem --out-jwk key.json --out-jwks jwks.json
+  kernel extensions keygen verify --pem key.pem --jwk jwks.json`,
+	Args: cobra.NoArgs,
+	RunE: runExtensionsKeygen,
+}
+
+var extensionsKeygenVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check that a PEM key and a JWK are the same key",
+	Long: `Check that an Ed25519 PEM private key and a JWK are the same key. --jwk may
+be a private JWK, a public JWK, or a JWKS that should contain the key.`,
+	Args: cobra.NoArgs,
+	RunE: runExtensionsKeygenVerify,
+}
+
+func init() {
+	extensionsCmd.AddCommand(extensionsKeygenCmd)
+	extensionsKeygenCmd.AddCommand(extensionsKeygenVerifyCmd)
+
+	extensionsKeygenCmd.Flags().String("algo", "ed25519", "Key algorithm (only ed25519 is supported)")
+	extensionsKeygenCmd.Flags().String("out-pem", "", "Write the private key in PEM format to this path")
+	extensionsKeygenCmd.Flags().String("out-jwk", "", "Write the private key in JWK format to this path")
+	extensionsKeygenCmd.Flags().String("out-jwks", "", "Write the public JWKS to this path")
+	extensionsKeygenCmd.Flags().Bool("force", false, "Overwrite existing output files")
+	addJSONOutputFlag(extensionsKeygenCmd)
+
+	extensionsKeygenVerifyCmd.Flags().String("pem", "", "Path to the Ed25519 private key in PEM format")
+	extensionsKeygenVerifyCmd.Flags().String("jwk", "", "Path to the JWK or JWKS")
+	addJSONOutputFlag(extensionsKeygenVerifyCmd)
+	_ = extensionsKeygenVerifyCmd.MarkFlagRequired("pem")
+	_ = extensionsKeygenVerifyCmd.MarkFlagRequired("jwk")
+}
+
+func runExtensionsKeygen(cmd *cobra.Command, args []string) error {
+	algo, _ := cmd.Flags().GetString("algo")
+	outPEM, _ := cmd.Flags().GetString("out-pem")
+	outJWK, _ := cmd.Flags().GetString("out-jwk")
+	outJWKS, _ := cmd.Flags().GetString("out-jwks")
+	force, _ := cmd.Flags().GetBool("force")
+	output, _ := cmd.Flags().GetString("output")
+	return ExtensionsCmd{}.Keygen(ExtensionsKeygenInput{
+		Algo: algo, OutPEM: outPEM, OutJWK: outJWK, OutJWKS: outJWKS, Force: force, Output: output,
+	})
+}
+
+func runExtensionsKeygenVerify(cmd *cobra.Command, args []string) error {
+	pemPath, _ := cmd.Flags().GetString("pem")
+	jwkPath, _ := cmd.Flags().GetString("jwk")
+	output, _ := cmd.Flags().GetString("output")
+	return ExtensionsCmd{}.VerifyKey(ExtensionsKeyVerifyInput{PEMPath: pemPath, JWKPath: jwkPath, Output: output})
+}
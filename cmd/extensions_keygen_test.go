@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtensionsKeygen_WritesMatchingKeyFiles(t *testing.T) {
+	dir := t.TempDir()
+	in := ExtensionsKeygenInput{
+		Algo:    "ed25519",
+		OutPEM:  filepath.Join(dir, "key.pem"),
+		OutJWK:  filepath.Join(dir, "key.json"),
+		OutJWKS: filepath.Join(dir, "jwks.json"),
+	}
+	require.NoError(t, ExtensionsCmd{}.Keygen(in))
+
+	info, err := os.Stat(in.OutPEM)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	var jwks util.JWKS
+	data, err := os.ReadFile(in.OutJWKS)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &jwks))
+	require.Len(t, jwks.Keys, 1)
+	assert.NotContains(t, string(data), `"d"`)
+
+	for _, jwkPath := range []string{in.OutJWK, in.OutJWKS} {
+		assert.NoError(t, ExtensionsCmd{}.VerifyKey(ExtensionsKeyVerifyInput{PEMPath: in.OutPEM, JWKPath: jwkPath}), jwkPath)
+	}
+
+	// Existing files are kept unless --force
+	err = ExtensionsCmd{}.Keygen(in)
+	assert.ErrorContains(t, err, "already exists")
+	in.Force = true
+	require.NoError(t, ExtensionsCmd{}.Keygen(in))
+}
+
+func TestExtensionsKeygen_RejectsUnsupportedAlgo(t *testing.T) {
+	err := ExtensionsCmd{}.Keygen(ExtensionsKeygenInput{Algo: "rsa", OutPEM: filepath.Join(t.TempDir(), "key.pem")})
+	assert.ErrorContains(t, err, "unsupported --algo")
+}
+
+func TestExtensionsKeyVerify_Mismatch(t *testing.T) {
+	dir := t.TempDir()
+	first := ExtensionsKeygenInput{Algo: "ed25519", OutPEM: filepath.Join(dir, "a.pem")}
+	second := ExtensionsKeygenInput{Algo: "ed25519", OutJWKS: filepath.Join(dir, "b.json")}
+	require.NoError(t, ExtensionsCmd{}.Keygen(first))
+	require.NoError(t, ExtensionsCmd{}.Keygen(second))
+
+	err := ExtensionsCmd{}.VerifyKey(ExtensionsKeyVerifyInput{PEMPath: first.OutPEM, JWKPath: second.OutJWKS})
+	assert.ErrorContains(t, err, "don't match")
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// extensionContentTypes are the types Chrome expects for the files an
+// enterprise-policy install fetches.
+var extensionContentTypes = map[string]string{
+	".crx":   "application/x-chrome-extension",
+	".xml":   "application/xml",
+	".json":  "application/json",
+	".plist": "application/xml",
+	".templ": "text/plain; charset=utf-8",
+}
+
+var updateXMLCodebase = regexp.MustCompile(`codebase=['"]([^'"]+)['"]`)
+
+// extensionArtifacts serves a built extension directory (as written by
+// build-web-bot-auth) so that update.xml, the .crx and the policies resolve
+// against baseURL instead of the host they were built for.
+type extensionArtifacts struct {
+	dir     string
+	name    string
+	builtOn string
+	baseURL string
+}
+
+// newExtensionArtifacts reads the host and extension name the artifacts in
+// dir were built for from update.xml's codebase, which build-web-bot-auth
+// sets to <host>/extensions/<name>/<file>.crx.
+func newExtensionArtifacts(dir, baseURL string) (*extensionArtifacts, error) {
+	updateXML, err := os.ReadFile(filepath.Join(dir, "update.xml"))
+	if err != nil {
+		return nil, fmt.Errorf("no update.xml in %s; build the extension with \"kernel extensions build-web-bot-auth\" first", dir)
+	}
+	m := updateXMLCodebase.FindSubmatch(updateXML)
+	if m == nil {
+		return nil, fmt.Errorf("update.xml in %s has no codebase", dir)
+	}
+	codebase := string(m[1])
+	a := &extensionArtifacts{dir: dir, baseURL: strings.TrimRight(baseURL, "/")}
+	if host, rest, ok := strings.Cut(codebase, "/extensions/"); ok {
+		a.builtOn = host
+		a.name, _, _ = strings.Cut(rest, "/")
+	} else if i := strings.LastIndex(codebase, "/"); i > 0 {
+		a.builtOn = codebase[:i]
+	} else {
+		return nil, fmt.Errorf("update.xml in %s has an invalid codebase %q", dir, codebase)
+	}
+	return a, nil
+}
+
+// updateURL is where Chrome policies should point to install the extension.
+func (a *extensionArtifacts) updateURL() string {
+	if a.name == "" {
+		return a.baseURL + "/update.xml"
+	}
+	return a.baseURL + "/extensions/" + a.name + "/update.xml"
+}
+
+func (a *extensionArtifacts) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// Files are served both at the root and under /extensions/<name>/, the
+	// layout the built URLs use
+	p := path.Clean("/" + r.URL.Path)
+	if a.name != "" {
+		if rest, ok := strings.CutPrefix(p, "/extensions/"+a.name); ok && (rest == "" || rest[0] == '/') {
+			p = path.Clean("/" + rest)
+		}
+	}
+	base := path.Base(p)
+	// The signing key must never leave the machine; dotfiles aren't artifacts
+	if base == "private_key.pem" || strings.HasPrefix(base, ".") {
+		http.NotFound(w, r)
+		return
+	}
+	file := filepath.Join(a.dir, filepath.FromSlash(p))
+	st, err := os.Stat(file)
+	if err != nil || st.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	ext := path.Ext(base)
+	if ct, ok := extensionContentTypes[ext]; ok {
+		w.Header().Set("Content-Type", ct)
+	}
+	pterm.Debug.Printf("%s %s\n", r.Method, r.URL.Path)
+
+	switch ext {
+	case ".xml", ".json", ".plist", ".templ":
+		content, err := os.ReadFile(file)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		content = bytes.ReplaceAll(content, []byte(a.builtOn), []byte(a.baseURL))
+		http.ServeContent(w, r, base, st.ModTime(), bytes.NewReader(content))
+	default:
+		http.ServeFile(w, r, file)
+	}
+}
+
+type ExtensionsServeInput struct {
+	Dir  string
+	Host string
+	Port int
+}
+
+// Serve hosts a built extension directory until ctx is done or the process
+// is interrupted.
+func (e ExtensionsCmd) Serve(ctx context.Context, in ExtensionsServeInput) error {
+	dir, err := filepath.Abs(in.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve directory: %w", err)
+	}
+	addr := net.JoinHostPort(in.Host, strconv.Itoa(in.Port))
+	artifacts, err := newExtensionArtifacts(dir, "http://"+addr)
+	if err != nil {
+		return err
+	}
+
+	rows := pterm.TableData{{"File", "URL"}}
+	rows = append(rows, []string{"Update manifest", artifacts.updateURL()})
+	rows = append(rows, []string{"Extension", strings.TrimSuffix(artifacts.updateURL(), "update.xml") + "http-message-signatures-extension.crx"})
+	for _, policy := range []string{"policy.json", "com.google.Chrome.managed.plist"} {
+		if _, err := os.Stat(filepath.Join(dir, "policy", policy)); err == nil {
+			rows = append(rows, []string{"Policy", artifacts.baseURL + "/policy/" + policy})
+		}
+	}
+	PrintTableNoPad(rows, true)
+	if artifacts.builtOn != artifacts.baseURL {
+		pterm.Info.Printf("Rewriting %s to %s in served manifests and policies\n", artifacts.builtOn, artifacts.baseURL)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return runHTTPServer(ctx, addr, artifacts)
+}
+
+var extensionsServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve built extension artifacts locally for policy installs",
+	Long: `Serve a directory written by "kernel extensions build-web-bot-auth" over HTTP,
+so Chrome can install the extension through enterprise policy before it's
+uploaded anywhere.
+
+The .crx, update.xml and policy files are served with the content types Chrome
+expects, both at the root and under /extensions/<name>/. The host the
+extension was built for is rewritten to the local address in update.xml and
+the policies. private_key.pem is never served.`,
+	Example: `  kernel extensions build-web-bot-auth --to ./web-bot-auth --prebuilt
+  kernel extensions serve --dir ./web-bot-auth --port 8000`,
+	Args: cobra.NoArgs,
+	RunE: runExtensionsServe,
+}
+
+func init() {
+	extensionsCmd.AddCommand(extensionsServeCmd)
+	extensionsServeCmd.Flags().String("dir", "./web-bot-auth", "Directory of built extension artifacts")
+	extensionsServeCmd.Flags().String("host", "127.0.0.1", "Address to listen on")
+	extensionsServeCmd.Flags().Int("port", 8000, "Port to listen on")
+}
+
+func runExtensionsServe(cmd *cobra.Command, args []string) error {
+	dir, _ := cmd.Flags().GetString("dir")
+	host, _ := cmd.Flags().GetString("host")
+	port, _ := cmd.Flags().GetInt("port")
+	return ExtensionsCmd{}.Serve(cmd.Context(), ExtensionsServeInput{Dir: dir, Host: host, Port: port})
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtensionArtifacts_ServesWithLocalHost(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	write("update.xml", `<app appid='abc'><updatecheck codebase='http://127.0.0.1:10001/extensions/web-bot-auth/http-message-signatures-extension.crx'/></app>`)
+	write("http-message-signatures-extension.crx", "crx")
+	write("policy/policy.json", `{"update_url":"http://127.0.0.1:10001/extensions/web-bot-auth/update.xml"}`)
+	write("private_key.pem", "secret")
+
+	srv := httptest.NewUnstartedServer(nil)
+	artifacts, err := newExtensionArtifacts(dir, "http://"+srv.Listener.Addr().String())
+	require.NoError(t, err)
+	srv.Config.Handler = artifacts
+	srv.Start()
+	defer srv.Close()
+
+	get := func(path string) (*http.Response, string) {
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	resp, body := get("/extensions/web-bot-auth/update.xml")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/xml", resp.Header.Get("Content-Type"))
+	assert.Contains(t, body, srv.URL+"/extensions/web-bot-auth/http-message-signatures-extension.crx")
+
+	resp, body = get("/extensions/web-bot-auth/http-message-signatures-extension.crx")
+	assert.Equal(t, "application/x-chrome-extension", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "crx", body)
+
+	_, body = get("/policy/policy.json")
+	assert.Contains(t, body, srv.URL+"/extensions/web-bot-auth/update.xml")
+
+	for _, path := range []string{"/private_key.pem", "/extensions/web-bot-auth/private_key.pem", "/../update.xml/../private_key.pem"} {
+		resp, _ = get(path)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, path)
+	}
+}
+
+func TestNewExtensionArtifacts_RequiresUpdateXML(t *testing.T) {
+	_, err := newExtensionArtifacts(t.TempDir(), "http://127.0.0.1:8000")
+	assert.ErrorContains(t, err, "build-web-bot-auth")
+}
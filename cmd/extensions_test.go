@@ -4,6 +4,7 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"os"
@@ -214,7 +215,7 @@ func TestExtensionsUpload_Success(t *testing.T) {
 		return &kernel.ExtensionUploadResponse{ID: "e1", Name: "myext", CreatedAt: time.Unix(0, 0), SizeBytes: 10}, nil
 	}}
 	e := ExtensionsCmd{extensions: fake}
-	_ = e.Upload(context.Background(), ExtensionsUploadInput{Dir: dir, Name: "myext"})
+	_ = e.Upload(context.Background(), ExtensionsUploadInput{Path: dir, Name: "myext"})
 	out := buf.String()
 	assert.Contains(t, out, "ID")
 	assert.Contains(t, out, "e1")
@@ -225,6 +226,102 @@ func TestExtensionsUpload_Success(t *testing.T) {
 func TestExtensionsUpload_InvalidDir(t *testing.T) {
 	fake := &FakeExtensionsService{}
 	e := ExtensionsCmd{extensions: fake}
-	err := e.Upload(context.Background(), ExtensionsUploadInput{Dir: "/does/not/exist"})
+	err := e.Upload(context.Background(), ExtensionsUploadInput{Path: "/does/not/exist"})
 	assert.Error(t, err)
 }
+
+func manifestZip(t *testing.T, manifest string) []byte {
+	t.Helper()
+	var zbuf bytes.Buffer
+	zw := zip.NewWriter(&zbuf)
+	w, err := zw.Create("manifest.json")
+	assert.NoError(t, err)
+	_, _ = w.Write([]byte(manifest))
+	assert.NoError(t, zw.Close())
+	return zbuf.Bytes()
+}
+
+func TestExtensionsUpload_Zip(t *testing.T) {
+	buf := capturePtermOutput(t)
+	zipPath := filepath.Join(t.TempDir(), "ext.zip")
+	assert.NoError(t, os.WriteFile(zipPath, manifestZip(t, `{"name":"myext","version":"1.2.3"}`), 0644))
+
+	var uploaded []byte
+	fake := &FakeExtensionsService{UploadFunc: func(ctx context.Context, body kernel.ExtensionUploadParams, opts ...option.RequestOption) (*kernel.ExtensionUploadResponse, error) {
+		uploaded, _ = io.ReadAll(body.File)
+		return &kernel.ExtensionUploadResponse{ID: "e1", Name: "myext", CreatedAt: time.Unix(0, 0), SizeBytes: 10}, nil
+	}}
+	e := ExtensionsCmd{extensions: fake}
+	err := e.Upload(context.Background(), ExtensionsUploadInput{Path: zipPath})
+	assert.NoError(t, err)
+	want, _ := os.ReadFile(zipPath)
+	assert.Equal(t, want, uploaded, "zip should be uploaded as is")
+	out := buf.String()
+	assert.NotContains(t, out, "Compressing")
+	assert.Contains(t, out, "1.2.3")
+}
+
+func TestExtensionsUpload_RejectsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	assert.NoError(t, os.WriteFile(path, []byte("{}"), 0644))
+	e := ExtensionsCmd{extensions: &FakeExtensionsService{}}
+	err := e.Upload(context.Background(), ExtensionsUploadInput{Path: path})
+	assert.ErrorContains(t, err, "not a directory or a .zip file")
+}
+
+func TestExtensionsList_Versions(t *testing.T) {
+	rows := []kernel.ExtensionListResponse{{ID: "e1", Name: "alpha"}, {ID: "e2", Name: "beta"}}
+	fake := &FakeExtensionsService{
+		ListFunc: func(ctx context.Context, query kernel.ExtensionListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.ExtensionListResponse], error) {
+			return &pagination.OffsetPagination[kernel.ExtensionListResponse]{Items: rows}, nil
+		},
+		DownloadFunc: func(ctx context.Context, idOrName string, opts ...option.RequestOption) (*http.Response, error) {
+			body := []byte("not a zip")
+			if idOrName == "e1" {
+				body = manifestZip(t, `{"version":"2.0.1"}`)
+			}
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewReader(body)), Header: http.Header{}}, nil
+		},
+	}
+	e := ExtensionsCmd{extensions: fake}
+	var err error
+	out := captureStdout(t, func() {
+		err = e.List(context.Background(), ExtensionsListInput{Output: "json", Versions: true})
+	})
+	assert.NoError(t, err)
+
+	var got []map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(out), &got))
+	if assert.Len(t, got, 2) {
+		assert.Equal(t, "e1", got[0]["id"])
+		assert.Equal(t, "2.0.1", got[0]["version"])
+		assert.NotContains(t, got[1], "version")
+	}
+}
+
+func TestExtensionsDelete_JSON(t *testing.T) {
+	e := ExtensionsCmd{extensions: &FakeExtensionsService{}}
+	var err error
+	out := captureStdout(t, func() {
+		err = e.Delete(context.Background(), ExtensionsDeleteInput{Identifier: "e1", SkipConfirm: true, Output: "json"})
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":"e1","deleted":true}`, out)
+
+	err = e.Delete(context.Background(), ExtensionsDeleteInput{Identifier: "e1", Output: "json"})
+	assert.ErrorContains(t, err, "--yes")
+}
+
+func TestExtensionsDownload_JSON(t *testing.T) {
+	fake := &FakeExtensionsService{DownloadFunc: func(ctx context.Context, idOrName string, opts ...option.RequestOption) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewReader(manifestZip(t, `{"version":"0.4"}`))), Header: http.Header{}}, nil
+	}}
+	e := ExtensionsCmd{extensions: fake}
+	outDir := filepath.Join(t.TempDir(), "ext")
+	var err error
+	out := captureStdout(t, func() {
+		err = e.Download(context.Background(), ExtensionsDownloadInput{Identifier: "e1", Output: outDir, OutputFormat: "json"})
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":"e1","path":"`+outDir+`","version":"0.4"}`, out)
+}
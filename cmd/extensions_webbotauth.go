@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kernel/cli/pkg/extensions"
+	"github.com/kernel/cli/pkg/util"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+type ExtensionsRotateKeyInput struct {
+	Dir     string
+	KeyPath string
+	CRXKey  string
+	Output  string
+}
+
+// RotateKey swaps the signing key of a built web-bot-auth extension and
+// prints the JWKS origins should trust.
+func (e ExtensionsCmd) RotateKey(in ExtensionsRotateKeyInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	res, err := extensions.RotateWebBotAuthKey(extensions.RotateWebBotAuthKeyInput{
+		Dir:        in.Dir,
+		KeyPath:    in.KeyPath,
+		CRXKeyPath: in.CRXKey,
+	})
+	if err != nil {
+		return err
+	}
+	if in.Output == "json" {
+		return util.PrintJSON(res)
+	}
+
+	rows := pterm.TableData{{"Property", "Value"}}
+	rows = append(rows, []string{"Directory", res.Dir})
+	rows = append(rows, []string{"Extension ID", util.FirstOrDash(res.ExtensionID)})
+	rows = append(rows, []string{"Version", fmt.Sprintf("%s → %s", res.PreviousVersion, res.Version)})
+	rows = append(rows, []string{"Key ID", res.KeyID})
+	rows = append(rows, []string{"Previous Key ID", res.PreviousKeyID})
+	switch {
+	case res.CRX != "":
+		rows = append(rows, []string{"CRX", "re-signed"})
+	case res.CRXRemoved:
+		rows = append(rows, []string{"CRX", "removed"})
+	}
+	PrintTableNoPad(rows, true)
+	if res.CRXRemoved {
+		pterm.Warning.Println("Removed the .crx, which still carried the old key. Pass --crx-key to re-sign it for policy installs, or upload the unpacked extension")
+	}
+
+	pterm.Info.Println("JWKS the origin should trust:")
+	if err := util.PrintJSON(res.JWKS); err != nil {
+		return err
+	}
+	if len(res.JWKS.Keys) > 1 {
+		pterm.Info.Printf("Drop key %s once browsers run version %s\n", res.PreviousKeyID, res.Version)
+	}
+	pterm.Info.Printf("Upload the new version with: kernel extensions upload %s\n", res.Dir)
+	return nil
+}
+
+var extensionsWebBotAuthCmd = &cobra.Command{
+	Use:   "webbotauth",
+	Short: "Manage built web-bot-auth extensions",
+}
+
+var extensionsWebBotAuthRotateKeyCmd = &cobra.Command{
+	Use:   "rotate-key",
+	Short: "Swap the signing key of a built web-bot-auth extension",
+	Long: `Swap the Ed25519 signing key of an extension built by
+"kernel extensions build-web-bot-auth" without rebuilding it: the JWK in the
+bundled scripts and private_key.pem are replaced, and the version in
+manifest.json and update.xml is bumped so installed copies update.
+
+The .crx is re-signed with --crx-key (RSA or ECDSA P-256), which must be the
+key the extension ID in update.xml comes from. Without it the stale .crx is
+removed. Prints the JWKS origins should trust: the new key, plus the old one
+until browsers have updated.`,
+	Example: `  kernel extensions webbotauth rotate-key --dir ./web-bot-auth --key new.pem
+  kernel extensions webbotauth rotate-key --dir ./web-bot-auth --key new.jwk --crx-key crx.pem -o json`,
+	Args: cobra.NoArgs,
+	RunE: runExtensionsWebBotAuthRotateKey,
+}
+
+func init() {
+	extensionsCmd.AddCommand(extensionsWebBotAuthCmd)
+	extensionsWebBotAuthCmd.AddCommand(extensionsWebBotAuthRotateKeyCmd)
+	extensionsWebBotAuthRotateKeyCmd.Flags().String("dir", "./web-bot-auth", "Directory of the built extension")
+	extensionsWebBotAuthRotateKeyCmd.Flags().String("key", "", "Path to the new Ed25519 private key (JWK or PEM format)")
+	extensionsWebBotAuthRotateKeyCmd.Flags().String("crx-key", "", "Path to the key to re-sign the .crx with (RSA or ECDSA P-256 PEM)")
+	addJSONOutputFlag(extensionsWebBotAuthRotateKeyCmd)
+	_ = extensionsWebBotAuthRotateKeyCmd.MarkFlagRequired("key")
+}
+
+func runExtensionsWebBotAuthRotateKey(cmd *cobra.Command, args []string) error {
+	dir, _ := cmd.Flags().GetString("dir")
+	key, _ := cmd.Flags().GetString("key")
+	crxKey, _ := cmd.Flags().GetString("crx-key")
+	output, _ := cmd.Flags().GetString("output")
+	return ExtensionsCmd{}.RotateKey(ExtensionsRotateKeyInput{Dir: dir, KeyPath: key, CRXKey: crxKey, Output: output})
+}
@@ -1,5 +1,24 @@
 package cmd
 
+import (
+	"github.com/kernel/cli/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// addNoTemplateFlag adds --no-template to commands whose inputs go through
+// util.ExpandTemplate, for inputs that contain a literal "{{".
+func addNoTemplateFlag(cmd *cobra.Command, what string) {
+	cmd.Flags().Bool("no-template", false, "Use "+what+" as given, without expanding {{...}} template functions")
+}
+
+// expandTemplateFlag expands s unless --no-template is set.
+func expandTemplateFlag(cmd *cobra.Command, s, dir string) (string, error) {
+	if noTemplate, _ := cmd.Flags().GetBool("no-template"); noTemplate {
+		return s, nil
+	}
+	return util.ExpandTemplate(s, dir)
+}
+
 // BoolFlag captures whether a boolean flag was set explicitly and its value.
 type BoolFlag struct {
 	Set   bool
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+)
+
+const (
+	// minIDPrefixLen keeps very short inputs from matching half the account.
+	minIDPrefixLen         = 4
+	idPrefixPageSize int64 = 100
+)
+
+// idPageLister returns one page of candidate IDs from a list endpoint,
+// narrowed server-side by query where the API supports it.
+type idPageLister func(ctx context.Context, query string, offset int64) ([]string, error)
+
+func browserIDLister(svc BrowsersService) idPageLister {
+	return func(ctx context.Context, query string, offset int64) ([]string, error) {
+		page, err := svc.List(ctx, kernel.BrowserListParams{Query: kernel.Opt(query), Limit: kernel.Opt(idPrefixPageSize), Offset: kernel.Opt(offset)})
+		if err != nil || page == nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(page.Items))
+		for _, b := range page.Items {
+			ids = append(ids, b.SessionID)
+		}
+		return ids, nil
+	}
+}
+
+func authConnectionIDLister(svc AuthConnectionService) idPageLister {
+	return func(ctx context.Context, query string, offset int64) ([]string, error) {
+		page, err := svc.List(ctx, kernel.AuthConnectionListParams{Query: kernel.Opt(query), Limit: kernel.Opt(idPrefixPageSize), Offset: kernel.Opt(offset)})
+		if err != nil || page == nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(page.Items))
+		for _, a := range page.Items {
+			ids = append(ids, a.ID)
+		}
+		return ids, nil
+	}
+}
+
+func invocationIDLister(svc *kernel.InvocationService) idPageLister {
+	return func(ctx context.Context, query string, offset int64) ([]string, error) {
+		page, err := svc.List(ctx, kernel.InvocationListParams{Query: kernel.Opt(query), Limit: kernel.Opt(idPrefixPageSize), Offset: kernel.Opt(offset)})
+		if err != nil || page == nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(page.Items))
+		for _, inv := range page.Items {
+			ids = append(ids, inv.ID)
+		}
+		return ids, nil
+	}
+}
+
+// resolveIDPrefix expands prefix to the single ID that starts with it.
+// It returns "" with no error when nothing matches, and an error listing the
+// candidates when the prefix is ambiguous.
+func resolveIDPrefix(ctx context.Context, kind, prefix string, list idPageLister) (string, error) {
+	var matches []string
+	for offset := int64(0); ; offset += idPrefixPageSize {
+		ids, err := list(ctx, prefix, offset)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s ID prefix %q: %w", kind, prefix, err)
+		}
+		for _, id := range ids {
+			if strings.HasPrefix(id, prefix) {
+				matches = append(matches, id)
+			}
+		}
+		if len(matches) > 1 || int64(len(ids)) < idPrefixPageSize {
+			break
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", nil
+	case 1:
+		pterm.Debug.Printf("Resolved %s ID prefix %q → %s\n", kind, prefix, matches[0])
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%s ID prefix %q is ambiguous; it matches %s", kind, prefix, strings.Join(matches, ", "))
+	}
+}
+
+// withIDPrefix calls fn with id and, if the API reports it as not found and it
+// could be a shortened ID, retries once with the unique ID it's a prefix of.
+// A 404 means nothing happened, so retrying is safe for mutating calls too.
+func withIDPrefix[T any](ctx context.Context, kind, id string, list idPageLister, fn func(id string) (T, error)) (T, error) {
+	res, err := fn(id)
+	if err == nil || !util.IsNotFound(err) || len(id) < minIDPrefixLen {
+		return res, err
+	}
+	full, rerr := resolveIDPrefix(ctx, kind, id, list)
+	if rerr != nil {
+		return res, rerr
+	}
+	if full == "" || full == id {
+		return res, err
+	}
+	return fn(full)
+}
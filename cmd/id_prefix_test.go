@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/kernel/kernel-go-sdk/packages/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func staticIDLister(ids ...string) idPageLister {
+	return func(ctx context.Context, query string, offset int64) ([]string, error) {
+		if offset > 0 {
+			return nil, nil
+		}
+		return ids, nil
+	}
+}
+
+func TestResolveIDPrefix(t *testing.T) {
+	ctx := context.Background()
+
+	id, err := resolveIDPrefix(ctx, "browser", "ab12", staticIDLister("ab12cd34", "zz99ab12"))
+	require.NoError(t, err)
+	assert.Equal(t, "ab12cd34", id)
+
+	id, err = resolveIDPrefix(ctx, "browser", "ffff", staticIDLister("ab12cd34"))
+	require.NoError(t, err)
+	assert.Empty(t, id)
+
+	_, err = resolveIDPrefix(ctx, "browser", "ab12", staticIDLister("ab12cd34", "ab12ef56"))
+	assert.ErrorContains(t, err, `browser ID prefix "ab12" is ambiguous`)
+	assert.ErrorContains(t, err, "ab12ef56")
+}
+
+func TestBrowsersGet_ExpandsIDPrefixAfterNotFound(t *testing.T) {
+	var gotIDs []string
+	fake := &FakeBrowsersService{
+		GetFunc: func(ctx context.Context, id string, query kernel.BrowserGetParams, opts ...option.RequestOption) (*kernel.BrowserGetResponse, error) {
+			gotIDs = append(gotIDs, id)
+			if id != "ab12cd34ef56" {
+				return nil, &kernel.Error{StatusCode: http.StatusNotFound}
+			}
+			return &kernel.BrowserGetResponse{SessionID: id}, nil
+		},
+		ListFunc: func(ctx context.Context, query kernel.BrowserListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.BrowserListResponse], error) {
+			assert.Equal(t, "ab12", query.Query.Value)
+			return &pagination.OffsetPagination[kernel.BrowserListResponse]{Items: []kernel.BrowserListResponse{{SessionID: "ab12cd34ef56"}}}, nil
+		},
+	}
+	b := BrowsersCmd{browsers: fake}
+
+	br, err := b.getBrowser(context.Background(), "ab12", kernel.BrowserGetParams{})
+	require.NoError(t, err)
+	assert.Equal(t, "ab12cd34ef56", br.SessionID)
+	assert.Equal(t, []string{"ab12", "ab12cd34ef56"}, gotIDs)
+
+	// Too short to be treated as a prefix: the original 404 is returned.
+	_, err = b.getBrowser(context.Background(), "ab", kernel.BrowserGetParams{})
+	require.Error(t, err)
+	var apiErr *kernel.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+}
@@ -4,16 +4,20 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/kernel/cli/pkg/rundir"
 	"github.com/kernel/cli/pkg/update"
 	"github.com/kernel/cli/pkg/util"
 	"github.com/kernel/kernel-go-sdk"
@@ -68,29 +72,62 @@ var invocationDeleteBrowsersCmd = &cobra.Command{
 
 func init() {
 	invokeCmd.Flags().StringP("version", "v", "latest", "Specify a version of the app to invoke (optional, defaults to 'latest')")
-	invokeCmd.Flags().StringP("payload", "p", "", "JSON payload for the invocation (optional)")
+	invokeCmd.Flags().StringP("payload", "p", "", "JSON payload for the invocation (optional; supports {{env \"VAR\"}}, {{now}}, {{uuid}}, {{file \"path\"}})")
 	invokeCmd.Flags().StringP("payload-file", "f", "", "Path to a JSON file containing the payload (use '-' for stdin)")
 	invokeCmd.Flags().BoolP("sync", "s", false, "Invoke synchronously (default false). A synchronous invocation will open a long-lived HTTP POST to the Kernel API to wait for the invocation to complete. This will time out after 60 seconds, so only use this option if you expect your invocation to complete in less than 60 seconds. The default is to invoke asynchronously, in which case the CLI will open an SSE connection to the Kernel API after submitting the invocation and wait for the invocation to complete.")
 	invokeCmd.Flags().Int64("async-timeout", 0, "Timeout in seconds for async invocations (min 10, max 3600). Only applies when async mode is used.")
 	invokeCmd.Flags().String("since", "", "Show invocation events since the given time when following async execution")
 	invokeCmd.Flags().StringP("output", "o", "", "Output format: json for JSONL streaming output")
+	invokeCmd.Flags().Bool("compress", false, "Gzip the request body, for payloads near the size limit")
+	invokeCmd.Flags().Bool("detach", false, "Submit the invocation and print only its ID (JSON with -o json) without following it; see 'kernel wait invocation'")
+	invokeCmd.MarkFlagsMutuallyExclusive("detach", "sync")
+	invokeCmd.MarkFlagsMutuallyExclusive("detach", "since")
+	invokeCmd.Flags().String("app-dir", "", "Local source of the app; deploy it first when it differs from the deployed version")
+	invokeCmd.Flags().Bool("no-deploy", false, "With --app-dir, invoke the deployed version without checking the local source")
+	invokeCmd.Flags().StringArrayP("env", "e", []string{}, "Environment variables (KEY=value) for an --app-dir deploy. May be specified multiple times")
+	invokeCmd.Flags().StringArray("env-file", []string{}, "Read environment variables for an --app-dir deploy from a file (.env format). May be specified multiple times")
+	invokeCmd.Flags().String("payload-lines", "", "Create one invocation per line of this JSONL file (use '-' for stdin) and follow them all")
+	addNoTemplateFlag(invokeCmd, "payloads")
+	invokeCmd.Flags().Int("concurrency", defaultFanoutConcurrency, "With --payload-lines, maximum invocations in flight at once")
+	invokeCmd.Flags().String("results-dir", "", "With --payload-lines, write each invocation's result to line-<n>.json in this directory")
+	invokeCmd.Flags().Int("retries", 0, "Retry an invocation that doesn't succeed up to this many times, each with a fresh invocation")
+	invokeCmd.Flags().Duration("retry-backoff", defaultRetryBackoff, "With --retries, wait this long before the first retry, doubling for each further retry")
+	invokeCmd.Flags().StringSlice("retry-on", defaultRetryOn, "With --retries, which outcomes to retry: failed, timeout (a failure caused by a timeout), error (the invocation couldn't be created or followed; it may still be running, so a retry can start a second copy)")
+	invokeCmd.MarkFlagsMutuallyExclusive("retries", "detach")
+	invokeCmd.Flags().Bool("copy", false, "Copy the invocation ID to the clipboard once it's created")
+	invokeCmd.Flags().String("output-file", "", "Write the invocation's raw output to this file and print only a summary line")
+	invokeCmd.MarkFlagsMutuallyExclusive("output-file", "detach")
+	invokeCmd.Flags().String("schedule", "", "Save a recurring invocation on this cron schedule (e.g. \"*/5 * * * *\") instead of invoking now; payload templates are expanded once, when it's saved. See 'kernel schedules'")
 	invokeCmd.MarkFlagsMutuallyExclusive("payload", "payload-file")
+	addRunDirFlag(invokeCmd)
 
-	invocationHistoryCmd.Flags().Int("limit", 100, "Max invocations to return (default 100)")
+	invocationHistoryCmd.Flags().Int("limit", 100, "Max invocations to return, fetching as many pages as needed (0 = all)")
 	invocationHistoryCmd.Flags().String("action", "", "Filter by action name")
 	invocationHistoryCmd.Flags().StringP("app", "a", "", "Filter by app name")
 	invocationHistoryCmd.Flags().String("deployment-id", "", "Filter by deployment ID")
 	invocationHistoryCmd.Flags().Int("offset", 0, "Number of results to skip")
-	invocationHistoryCmd.Flags().String("since", "", "Show invocations that started since the given time")
+	invocationHistoryCmd.Flags().String("since", "", "Show invocations that started since this time: a duration ago (2h, 7d), a date or an RFC-3339 timestamp")
+	invocationHistoryCmd.Flags().String("until", "", "Show invocations that started before this time: a duration ago (2h, 7d), a date or an RFC-3339 timestamp")
 	invocationHistoryCmd.Flags().String("status", "", "Filter by invocation status: queued, running, succeeded, failed")
+	invocationHistoryCmd.Flags().Bool("failed-only", false, "Only show failed invocations (same as --status failed)")
+	invocationHistoryCmd.MarkFlagsMutuallyExclusive("status", "failed-only")
 	invocationHistoryCmd.Flags().String("version", "", "Filter by invocation version")
+	invocationHistoryCmd.Flags().Bool("watch", false, "Keep the table up to date, refreshing every few seconds until interrupted (JSON: one line per new or changed invocation)")
 	addJSONOutputFlag(invocationHistoryCmd)
 	invokeCmd.AddCommand(invocationHistoryCmd)
 
+	addJSONOutputFlag(invocationHistoryGetCmd)
+	invocationHistoryGetCmd.Flags().String("output-file", "", "Write the invocation's raw output to this file and print only a summary line")
+	invocationHistoryCmd.AddCommand(invocationHistoryGetCmd)
+
+	addJSONOutputFlag(invocationCompareCmd)
+	invokeCmd.AddCommand(invocationCompareCmd)
+
 	addJSONOutputFlag(invocationBrowsersCmd)
 	invokeCmd.AddCommand(invocationBrowsersCmd)
 
 	addJSONOutputFlag(invocationGetCmd)
+	invocationGetCmd.Flags().String("output-file", "", "Write the invocation's raw output to this file and print only a summary line")
 	invokeCmd.AddCommand(invocationGetCmd)
 
 	invocationUpdateCmd.Flags().String("status", "", "New invocation status: succeeded or failed")
@@ -101,7 +138,7 @@ func init() {
 	invokeCmd.AddCommand(invocationDeleteBrowsersCmd)
 }
 
-func runInvoke(cmd *cobra.Command, args []string) error {
+func runInvoke(cmd *cobra.Command, args []string) (err error) {
 	if len(args) != 2 {
 		return fmt.Errorf("requires exactly 2 arguments: <app_name> <action_name>")
 	}
@@ -121,6 +158,7 @@ func runInvoke(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("version cannot be an empty string")
 	}
 	isSync, _ := cmd.Flags().GetBool("sync")
+	detach, _ := cmd.Flags().GetBool("detach")
 	asyncTimeout, _ := cmd.Flags().GetInt64("async-timeout")
 	since, _ := cmd.Flags().GetString("since")
 	params := kernel.InvocationNewParams{
@@ -140,28 +178,172 @@ func runInvoke(cmd *cobra.Command, args []string) error {
 	if hasPayload {
 		params.Payload = kernel.Opt(payloadStr)
 	}
+	compress, _ := cmd.Flags().GetBool("compress")
+	warning, err := invocationBodyWarning(params, compress)
+	if err != nil {
+		return err
+	}
+	if warning != "" {
+		pterm.Warning.WithWriter(os.Stderr).Println(warning)
+	}
+	payloadLines, _ := cmd.Flags().GetString("payload-lines")
+	if payloadLines == "" && (cmd.Flags().Changed("concurrency") || cmd.Flags().Changed("results-dir")) {
+		return fmt.Errorf("--concurrency and --results-dir only apply with --payload-lines")
+	}
+	if payloadLines != "" {
+		for _, name := range []string{"payload", "payload-file", "sync", "detach", "since", "schedule", "copy", "retries", "output-file"} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--%s can't be combined with --payload-lines", name)
+			}
+		}
+	}
+	retry, err := invokeRetryPolicyFromFlags(cmd, jsonOutput)
+	if err != nil {
+		return err
+	}
+	if cron, _ := cmd.Flags().GetString("schedule"); cmd.Flags().Changed("schedule") {
+		for _, name := range []string{"sync", "detach", "since", "compress", "app-dir", "retries", "output-file"} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--%s can't be combined with --schedule", name)
+			}
+		}
+		s, err := newSchedulesCmd(nil)
+		if err != nil {
+			return err
+		}
+		return s.Add(SchedulesAddInput{
+			Cron:                cron,
+			App:                 appName,
+			Action:              actionName,
+			Version:             version,
+			Payload:             payloadStr,
+			AsyncTimeoutSeconds: asyncTimeout,
+			Output:              output,
+		})
+	}
+	reqOpts := []option.RequestOption{option.WithMaxRetries(0)}
+	if compress {
+		reqOpts = append(reqOpts, option.WithMiddleware(util.GzipRequestMiddleware()))
+	}
 	// we don't really care to cancel the context, we just want to handle signals
 	ctx, _ := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
 	cmd.SetContext(ctx)
+	run, err := startRunDir(cmd, args)
+	if err != nil {
+		return err
+	}
+	defer func() { finishRunDir(run, err) }()
+	run.Set("app", appName)
+	run.Set("action", actionName)
+	run.Set("version", version)
 
-	if !jsonOutput {
+	appDir, _ := cmd.Flags().GetString("app-dir")
+	noDeploy, _ := cmd.Flags().GetBool("no-deploy")
+	if appDir == "" && (cmd.Flags().Changed("env") || cmd.Flags().Changed("env-file") || noDeploy) {
+		return fmt.Errorf("--env, --env-file and --no-deploy only apply with --app-dir")
+	}
+	if appDir != "" && !noDeploy {
+		envVars, err := readDeployEnv(cmd)
+		if err != nil {
+			return err
+		}
+		if err := deployIfChanged(cmd.Context(), client, deployIfChangedInput{
+			AppName:  appName,
+			Version:  version,
+			AppDir:   appDir,
+			EnvVars:  envVars,
+			EnvScope: appEnvScope(cmd),
+			Output:   output,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if payloadLines != "" {
+		noTemplate, _ := cmd.Flags().GetBool("no-template")
+		payloads, err := readPayloadLinesFile(payloadLines, !noTemplate)
+		if err != nil {
+			return err
+		}
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		resultsDir, _ := cmd.Flags().GetString("results-dir")
+		return InvokeFanoutCmd{invocations: &client.Invocations}.Run(cmd.Context(), InvokeFanoutInput{
+			App:                 appName,
+			Action:              actionName,
+			Version:             version,
+			Payloads:            payloads,
+			Concurrency:         concurrency,
+			AsyncTimeoutSeconds: asyncTimeout,
+			ResultsDir:          resultsDir,
+			ProgressInterval:    fanoutProgressInterval,
+			Output:              output,
+		})
+	}
+
+	if !jsonOutput && !detach {
 		pterm.Info.Printf("Invoking \"%s\" (action: %s, version: %s)…\n", appName, actionName, version)
 	}
+	copyID, _ := cmd.Flags().GetBool("copy")
+	outputFile, _ := cmd.Flags().GetString("output-file")
+	follow := invokeFollowOptions{Since: since, Detach: detach, Copy: copyID, JSON: jsonOutput, OutputFile: outputFile, StartTime: startTime}
+	last, err := retry.run(cmd.Context(), func() (invokeAttempt, error) {
+		follow.StartTime = time.Now()
+		return invokeOnce(cmd.Context(), client, params, reqOpts, follow)
+	})
+	run.Set("result", last.Result)
+	if err != nil && last.InvocationID == "" {
+		return reportInvokeCreateError(err, compress, jsonOutput)
+	}
+	return err
+}
+
+// invokeFollowOptions controls how invokeOnce reports an invocation.
+type invokeFollowOptions struct {
+	Since  string
+	Detach bool
+	Copy   bool
+	JSON   bool
+	// OutputFile receives the raw output in place of printing it.
+	OutputFile string
+	StartTime  time.Time
+}
+
+// invokeOnce creates one invocation and, unless detached, follows it to a
+// terminal state. The attempt records how it ended; the error is what the
+// invoke command returns for it. A failed invocation is only an error in
+// JSON mode. When the invocation couldn't be created the attempt has no ID
+// and the error is the SDK's, for reportInvokeCreateError.
+func invokeOnce(ctx context.Context, client kernel.Client, params kernel.InvocationNewParams, reqOpts []option.RequestOption, opts invokeFollowOptions) (attempt invokeAttempt, err error) {
+	attempt = invokeAttempt{StartedAt: opts.StartTime}
+	defer func() {
+		attempt.DurationMs = time.Since(attempt.StartedAt).Milliseconds()
+		if err != nil && attempt.Result == "" {
+			attempt.Result = invokeResultError
+			attempt.Error = err.Error()
+		}
+	}()
+	jsonOutput := opts.JSON
+	run := rundir.FromContext(ctx)
 
 	// Create the invocation
-	resp, err := client.Invocations.New(cmd.Context(), params, option.WithMaxRetries(0))
+	resp, err := client.Invocations.New(ctx, params, reqOpts...)
 	if err != nil {
+		attempt.Error = util.CleanedUpSdkError{Err: err}.Error()
+		attempt.Result = invokeResultError
+		return attempt, err
+	}
+	attempt.InvocationID = resp.ID
+	run.Set("invocation_id", resp.ID)
+	if opts.Copy {
+		copyOutput("invocation ID", resp.ID)
+	}
+	// Detached invocations print only what a scheduler needs to track the job.
+	if opts.Detach {
 		if jsonOutput {
-			// In JSON mode, output error as JSON object
-			errObj := map[string]interface{}{"error": err.Error()}
-			if apiErr, ok := err.(*kernel.Error); ok {
-				errObj["status_code"] = apiErr.StatusCode
-			}
-			bs, _ := json.Marshal(errObj)
-			fmt.Println(string(bs))
-			return fmt.Errorf("invocation failed: %w", err)
+			return attempt, util.PrintJSONLine(resp)
 		}
-		return handleSdkError(err)
+		fmt.Println(resp.ID)
+		return attempt, nil
 	}
 	// Log the invocation ID for user reference
 	if !jsonOutput {
@@ -171,33 +353,41 @@ func runInvoke(cmd *cobra.Command, args []string) error {
 	// before this function returns
 	cleanupDone := make(chan struct{})
 	cleanupStarted := atomic.Bool{}
+	finished := atomic.Bool{}
 	defer func() {
+		finished.Store(true)
 		if cleanupStarted.Load() {
 			<-cleanupDone
 		}
 	}()
 
 	if resp.Status != kernel.InvocationNewResponseStatusQueued {
+		attempt.setStatus(string(resp.Status), resp.StatusReason)
+		_ = run.WriteJSON(invokeResultFile, resp, invokeResultDescription)
+		succeeded := resp.Status == kernel.InvocationNewResponseStatusSucceeded
+		if err := reportResult(succeeded, resp.Output, opts.OutputFile, jsonOutput); err != nil {
+			return attempt, err
+		}
 		if jsonOutput {
-			bs, _ := json.Marshal(resp)
-			fmt.Println(string(bs))
-			return nil
+			return attempt, util.PrintJSONLine(resp)
 		}
-		succeeded := resp.Status == kernel.InvocationNewResponseStatusSucceeded
-		printResult(succeeded, resp.Output)
 
-		duration := time.Since(startTime)
+		duration := time.Since(opts.StartTime)
 		if succeeded {
 			pterm.Success.Printfln("✔ Completed in %s", duration.Round(time.Millisecond))
-			return nil
+			return attempt, nil
 		}
-		return nil
+		return attempt, nil
 	}
 
-	// On cancel, mark the invocation as failed via the update endpoint
+	// On cancel, mark the invocation as failed via the update endpoint. An
+	// earlier attempt that already finished is left alone.
 	once := sync.Once{}
-	onCancel(cmd.Context(), func() {
+	onCancel(ctx, func() {
 		once.Do(func() {
+			if finished.Load() {
+				return
+			}
 			cleanupStarted.Store(true)
 			defer close(cleanupDone)
 			if !jsonOutput {
@@ -225,32 +415,36 @@ func runInvoke(cmd *cobra.Command, args []string) error {
 	})
 
 	// Start following events
-	stream := client.Invocations.FollowStreaming(cmd.Context(), resp.ID, kernel.InvocationFollowParams{
-		Since: kernel.Opt(since),
+	stream := client.Invocations.FollowStreaming(ctx, resp.ID, kernel.InvocationFollowParams{
+		Since: kernel.Opt(opts.Since),
 	}, option.WithMaxRetries(0))
 	for stream.Next() {
 		ev := stream.Current()
+		run.Event(ev)
+		recordInvocationEvent(run, ev)
 
 		if jsonOutput {
 			// Output each event as a JSON line
-			bs, err := json.Marshal(ev)
-			if err == nil {
-				fmt.Println(string(bs))
-			}
+			_ = util.PrintJSONLine(ev)
 			// Check for terminal states
 			if ev.Event == "invocation_state" {
 				stateEv := ev.AsInvocationState()
 				status := stateEv.Invocation.Status
-				if status == string(kernel.InvocationGetResponseStatusSucceeded) {
-					return nil
-				}
-				if status == string(kernel.InvocationGetResponseStatusFailed) {
-					return fmt.Errorf("invocation failed")
+				if status == string(kernel.InvocationGetResponseStatusSucceeded) || status == string(kernel.InvocationGetResponseStatusFailed) {
+					attempt.setStatus(status, stateEv.Invocation.StatusReason)
+					succeeded := status == string(kernel.InvocationGetResponseStatusSucceeded)
+					if err := reportResult(succeeded, stateEv.Invocation.Output, opts.OutputFile, true); err != nil {
+						return attempt, err
+					}
+					if !succeeded {
+						return attempt, fmt.Errorf("invocation failed")
+					}
+					return attempt, nil
 				}
 			}
 			if ev.Event == "error" {
 				errEv := ev.AsError()
-				return fmt.Errorf("%s: %s", errEv.Error.Code, errEv.Error.Message)
+				return attempt, fmt.Errorf("%s: %s", errEv.Error.Code, errEv.Error.Message)
 			}
 			continue
 		}
@@ -265,28 +459,90 @@ func runInvoke(cmd *cobra.Command, args []string) error {
 			stateEv := ev.AsInvocationState()
 			status := stateEv.Invocation.Status
 			if status == string(kernel.InvocationGetResponseStatusSucceeded) || status == string(kernel.InvocationGetResponseStatusFailed) {
+				attempt.setStatus(status, stateEv.Invocation.StatusReason)
 				// Finished – print output and exit accordingly
 				succeeded := status == string(kernel.InvocationGetResponseStatusSucceeded)
-				printResult(succeeded, stateEv.Invocation.Output)
+				if err := reportResult(succeeded, stateEv.Invocation.Output, opts.OutputFile, false); err != nil {
+					return attempt, err
+				}
 
-				duration := time.Since(startTime)
+				duration := time.Since(opts.StartTime)
 				if succeeded {
 					pterm.Success.Printfln("✔ Completed in %s", duration.Round(time.Millisecond))
-					return nil
+					return attempt, nil
 				}
-				return nil
+				return attempt, nil
 			}
 
 		case "error":
 			errEv := ev.AsError()
-			return fmt.Errorf("%s: %s", errEv.Error.Code, errEv.Error.Message)
+			return attempt, fmt.Errorf("%s: %s", errEv.Error.Code, errEv.Error.Message)
 		}
 	}
 
 	if serr := stream.Err(); serr != nil {
-		return fmt.Errorf("stream error: %w", serr)
+		return attempt, fmt.Errorf("stream error: %w", serr)
 	}
-	return nil
+	return attempt, nil
+}
+
+// invokeRetryPolicyFromFlags reads --retries, --retry-backoff and --retry-on.
+func invokeRetryPolicyFromFlags(cmd *cobra.Command, jsonOutput bool) (invokeRetryPolicy, error) {
+	retries, _ := cmd.Flags().GetInt("retries")
+	backoff, _ := cmd.Flags().GetDuration("retry-backoff")
+	retryOn, _ := cmd.Flags().GetStringSlice("retry-on")
+	if retries < 0 {
+		return invokeRetryPolicy{}, fmt.Errorf("--retries can't be negative")
+	}
+	if retries == 0 && (cmd.Flags().Changed("retry-backoff") || cmd.Flags().Changed("retry-on")) {
+		return invokeRetryPolicy{}, fmt.Errorf("--retry-backoff and --retry-on only apply with --retries")
+	}
+	if backoff < 0 {
+		return invokeRetryPolicy{}, fmt.Errorf("--retry-backoff can't be negative")
+	}
+	on, err := parseRetryOn(retryOn)
+	if err != nil {
+		return invokeRetryPolicy{}, err
+	}
+	return invokeRetryPolicy{Retries: retries, Backoff: backoff, On: on, JSON: jsonOutput}, nil
+}
+
+const (
+	invokeResultFile        = "result.json"
+	invokeResultDescription = "the finished invocation, with its output"
+)
+
+// recordInvocationEvent writes log lines to the run's logs and a finished
+// invocation to its result file.
+func recordInvocationEvent(run *rundir.Run, ev kernel.InvocationFollowResponseUnion) {
+	switch ev.Event {
+	case "log":
+		run.Logf("%s", ev.AsLog().Message)
+	case "invocation_state":
+		inv := ev.AsInvocationState().Invocation
+		if inv.Status == string(kernel.InvocationGetResponseStatusSucceeded) || inv.Status == string(kernel.InvocationGetResponseStatusFailed) {
+			_ = run.WriteJSON(invokeResultFile, inv, invokeResultDescription)
+		}
+	}
+}
+
+// reportInvokeCreateError reports an invocation that couldn't be created: as
+// a JSON line in JSON mode, otherwise with troubleshooting tips.
+func reportInvokeCreateError(err error, compress, jsonOutput bool) error {
+	explained := explainPayloadRejection(err, compress)
+	if jsonOutput {
+		// In JSON mode, output error as JSON object
+		errObj := map[string]interface{}{"error": explained.Error()}
+		if apiErr, ok := err.(*kernel.Error); ok {
+			errObj["status_code"] = apiErr.StatusCode
+		}
+		_ = util.PrintJSONLine(errObj)
+		return fmt.Errorf("invocation failed: %w", explained)
+	}
+	if explained != err {
+		return explained
+	}
+	return handleSdkError(err)
 }
 
 // handleSdkError prints helpful diagnostics similar to runDeploy
@@ -312,6 +568,37 @@ func handleSdkError(err error) error {
 	return nil
 }
 
+// reportResult prints an invocation's result, or with outputFile writes the
+// raw output there and prints only a summary line. JSON output carries the
+// result in its events, so it prints nothing.
+func reportResult(success bool, output, outputFile string, jsonOutput bool) error {
+	if outputFile == "" {
+		if !jsonOutput {
+			printResult(success, output)
+		}
+		return nil
+	}
+	if err := writeInvocationOutput(outputFile, output); err != nil {
+		return err
+	}
+	if !jsonOutput {
+		printer := pterm.Success
+		if !success {
+			printer = pterm.Error
+		}
+		printer.Printf("Output (%s) written to %s\n", util.FormatBytes(int64(len(output))), outputFile)
+	}
+	return nil
+}
+
+// writeInvocationOutput writes output to path byte for byte.
+func writeInvocationOutput(path, output string) error {
+	if err := os.WriteFile(path, []byte(output), 0o644); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
 func printResult(success bool, output string) {
 	output = formatJSONValue(output)
 	// use pterm.Success if succeeded, pterm.Error if failed
@@ -349,6 +636,7 @@ func printInvocationDetails(id, appName, actionName, version string, startedAt,
 	if !finishedAt.IsZero() {
 		table = append(table, []string{"Finished At", util.FormatLocal(finishedAt)})
 	}
+	table = append(table, []string{"Duration", invocationDuration(status, startedAt, finishedAt, time.Now())})
 	if statusReason != "" {
 		table = append(table, []string{"Status Reason", statusReason})
 	}
@@ -362,16 +650,69 @@ func printInvocationDetails(id, appName, actionName, version string, startedAt,
 	PrintTableNoPad(table, true)
 }
 
+// largeInvocationBody is the request body size above which invoke warns that
+// the API may reject the invocation. The API doesn't publish its limit, so
+// this is only a warning; an actual 413 is explained by
+// explainPayloadRejection.
+const largeInvocationBody = 1 << 20
+
+// invocationBodyWarning returns a warning when the request body is larger than
+// largeInvocationBody, or "" when it isn't. With compress the gzipped size is
+// what counts.
+func invocationBodyWarning(params kernel.InvocationNewParams, compress bool) (string, error) {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return "", fmt.Errorf("encode invocation: %w", err)
+	}
+	size := len(body)
+	if compress {
+		gz, err := util.Gzip(body)
+		if err != nil {
+			return "", fmt.Errorf("compress invocation: %w", err)
+		}
+		size = len(gz)
+	}
+	if size <= largeInvocationBody {
+		return "", nil
+	}
+	if compress {
+		return fmt.Sprintf("Payload is %s after compression and may be rejected as too large; consider passing large inputs by reference (e.g. a URL or file in storage)", util.FormatBytes(int64(size))), nil
+	}
+	return fmt.Sprintf("Payload is %s and may be rejected as too large; consider --compress, or passing large inputs by reference (e.g. a URL or file in storage)", util.FormatBytes(int64(size))), nil
+}
+
+// explainPayloadRejection replaces the API's bare 413 and 415 responses with
+// errors that say what to change.
+func explainPayloadRejection(err error, compress bool) error {
+	var apiErr *kernel.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	switch {
+	case apiErr.StatusCode == http.StatusRequestEntityTooLarge && compress:
+		return fmt.Errorf("the API rejected the payload as too large even when compressed; pass large inputs by reference instead: %w", err)
+	case apiErr.StatusCode == http.StatusRequestEntityTooLarge:
+		return fmt.Errorf("the API rejected the payload as too large; retry with --compress, or pass large inputs by reference: %w", err)
+	case apiErr.StatusCode == http.StatusUnsupportedMediaType && compress:
+		return fmt.Errorf("the API does not accept compressed invocations; retry without --compress: %w", err)
+	}
+	return err
+}
+
 // getPayload reads the payload from either --payload flag or --payload-file flag.
 // Returns the payload string, whether a payload was explicitly provided, and any error.
 // The second return value (hasPayload) is true when the user explicitly set a payload,
-// even if that payload is an empty string.
+// even if that payload is an empty string. Template functions (see util.ExpandTemplate)
+// are expanded before the JSON is validated, unless --no-template is set.
 func getPayload(cmd *cobra.Command) (payload string, hasPayload bool, err error) {
 	payloadStr, _ := cmd.Flags().GetString("payload")
 	payloadFile, _ := cmd.Flags().GetString("payload-file")
 
 	// If --payload was explicitly set, use it (even if empty string)
 	if cmd.Flags().Changed("payload") {
+		if payloadStr, err = expandTemplateFlag(cmd, payloadStr, ""); err != nil {
+			return "", false, fmt.Errorf("payload: %w", err)
+		}
 		// Validate JSON unless empty string explicitly set
 		if payloadStr != "" {
 			var v interface{}
@@ -400,7 +741,13 @@ func getPayload(cmd *cobra.Command) (payload string, hasPayload bool, err error)
 			}
 		}
 
-		payloadStr = strings.TrimSpace(string(data))
+		dir := ""
+		if payloadFile != "-" {
+			dir = filepath.Dir(payloadFile)
+		}
+		if payloadStr, err = expandTemplateFlag(cmd, strings.TrimSpace(string(data)), dir); err != nil {
+			return "", false, fmt.Errorf("payload file: %w", err)
+		}
 		// Validate JSON unless empty
 		if payloadStr != "" {
 			var v interface{}
@@ -414,139 +761,6 @@ func getPayload(cmd *cobra.Command) (payload string, hasPayload bool, err error)
 	return "", false, nil
 }
 
-func runInvocationHistory(cmd *cobra.Command, args []string) error {
-	client := getKernelClient(cmd)
-
-	lim, _ := cmd.Flags().GetInt("limit")
-	actionFilter, _ := cmd.Flags().GetString("action")
-	appFilter, _ := cmd.Flags().GetString("app")
-	deploymentID, _ := cmd.Flags().GetString("deployment-id")
-	offset, _ := cmd.Flags().GetInt("offset")
-	since, _ := cmd.Flags().GetString("since")
-	statusFilter, _ := cmd.Flags().GetString("status")
-	versionFilter, _ := cmd.Flags().GetString("version")
-	output, _ := cmd.Flags().GetString("output")
-
-	if err := validateJSONOutput(output); err != nil {
-		return err
-	}
-
-	// Build parameters for the API call
-	params := kernel.InvocationListParams{
-		Limit: kernel.Opt(int64(lim)),
-	}
-
-	if actionFilter != "" {
-		params.ActionName = kernel.Opt(actionFilter)
-	}
-	// Only add app filter if specified
-	if appFilter != "" {
-		params.AppName = kernel.Opt(appFilter)
-	}
-	if deploymentID != "" {
-		params.DeploymentID = kernel.Opt(deploymentID)
-	}
-	if offset > 0 {
-		params.Offset = kernel.Opt(int64(offset))
-	}
-	if since != "" {
-		params.Since = kernel.Opt(since)
-	}
-
-	// Only add version filter if specified
-	if versionFilter != "" {
-		params.Version = kernel.Opt(versionFilter)
-	}
-	if statusFilter != "" {
-		switch strings.ToLower(statusFilter) {
-		case "queued":
-			params.Status = kernel.InvocationListParamsStatusQueued
-		case "running":
-			params.Status = kernel.InvocationListParamsStatusRunning
-		case "succeeded":
-			params.Status = kernel.InvocationListParamsStatusSucceeded
-		case "failed":
-			params.Status = kernel.InvocationListParamsStatusFailed
-		default:
-			return fmt.Errorf("invalid --status value: %s (must be queued, running, succeeded, or failed)", statusFilter)
-		}
-	}
-
-	// Build debug message based on filters
-	if output != "json" {
-		if appFilter != "" && versionFilter != "" {
-			pterm.Debug.Printf("Listing invocations for app '%s' version '%s'...\n", appFilter, versionFilter)
-		} else if appFilter != "" {
-			pterm.Debug.Printf("Listing invocations for app '%s'...\n", appFilter)
-		} else if versionFilter != "" {
-			pterm.Debug.Printf("Listing invocations for version '%s'...\n", versionFilter)
-		} else {
-			pterm.Debug.Printf("Listing all invocations...\n")
-		}
-	}
-
-	// Make a single API call to get invocations
-	invocations, err := client.Invocations.List(cmd.Context(), params)
-	if err != nil {
-		pterm.Error.Printf("Failed to list invocations: %v\n", err)
-		return nil
-	}
-
-	if output == "json" {
-		if len(invocations.Items) == 0 {
-			fmt.Println("[]")
-			return nil
-		}
-		return util.PrintPrettyJSONSlice(invocations.Items)
-	}
-
-	table := pterm.TableData{{"Invocation ID", "App Name", "Action", "Version", "Status", "Started At", "Duration", "Output"}}
-
-	for _, inv := range invocations.Items {
-		started := util.FormatLocal(inv.StartedAt)
-		status := string(inv.Status)
-
-		// Calculate duration
-		var duration string
-		if !inv.FinishedAt.IsZero() {
-			dur := inv.FinishedAt.Sub(inv.StartedAt)
-			duration = dur.Round(time.Millisecond).String()
-		} else if status == "running" {
-			dur := time.Since(inv.StartedAt)
-			duration = dur.Round(time.Second).String() + " (running)"
-		} else {
-			duration = "-"
-		}
-
-		// Truncate output for display
-		output := inv.Output
-		if len(output) > 50 {
-			output = output[:47] + "..."
-		}
-		if output == "" {
-			output = "-"
-		}
-
-		table = append(table, []string{
-			inv.ID,
-			inv.AppName,
-			inv.ActionName,
-			inv.Version,
-			status,
-			started,
-			duration,
-			output,
-		})
-	}
-
-	if len(table) == 1 {
-		pterm.Info.Println("No invocations found.")
-	} else {
-		pterm.DefaultTable.WithHasHeader().WithData(table).Render()
-	}
-	return nil
-}
-
 func runInvocationBrowsers(cmd *cobra.Command, args []string) error {
 	client := getKernelClient(cmd)
 	invocationID := args[0]
@@ -556,7 +770,10 @@ func runInvocationBrowsers(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	resp, err := client.Invocations.ListBrowsers(cmd.Context(), invocationID)
+	resp, err := withIDPrefix(cmd.Context(), "invocation", invocationID, invocationIDLister(&client.Invocations), func(id string) (*kernel.InvocationListBrowsersResponse, error) {
+		invocationID = id
+		return client.Invocations.ListBrowsers(cmd.Context(), id)
+	})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -568,11 +785,11 @@ func runInvocationBrowsers(cmd *cobra.Command, args []string) error {
 
 	if output == "json" {
 		if len(resp.Browsers) == 0 {
-			fmt.Println("[]")
-			return nil
+			return util.PrintJSON([]any{})
 		}
 		return util.PrintPrettyJSONSlice(resp.Browsers)
 	}
+	util.WarnDecodeDrift(resp)
 
 	if len(resp.Browsers) == 0 {
 		pterm.Info.Printf("No active browsers found for invocation %s\n", invocationID)
@@ -607,19 +824,33 @@ func runInvocationBrowsers(cmd *cobra.Command, args []string) error {
 func runInvocationGet(cmd *cobra.Command, args []string) error {
 	client := getKernelClient(cmd)
 	output, _ := cmd.Flags().GetString("output")
+	outputFile, _ := cmd.Flags().GetString("output-file")
 
 	if err := validateJSONOutput(output); err != nil {
 		return err
 	}
 
-	resp, err := client.Invocations.Get(cmd.Context(), args[0])
+	resp, err := withIDPrefix(cmd.Context(), "invocation", args[0], invocationIDLister(&client.Invocations), func(id string) (*kernel.InvocationGetResponse, error) {
+		return client.Invocations.Get(cmd.Context(), id)
+	})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
 
+	if outputFile != "" {
+		if err := writeInvocationOutput(outputFile, resp.Output); err != nil {
+			return err
+		}
+		if output != "json" {
+			pterm.Success.Printf("Invocation %s (%s, %s): output (%s) written to %s\n", resp.ID, resp.Status,
+				invocationDuration(string(resp.Status), resp.StartedAt, resp.FinishedAt, time.Now()), util.FormatBytes(int64(len(resp.Output))), outputFile)
+			return nil
+		}
+	}
 	if output == "json" {
 		return util.PrintPrettyJSON(resp)
 	}
+	util.WarnDecodeDrift(resp)
 
 	printInvocationDetails(
 		resp.ID,
@@ -662,7 +893,9 @@ func runInvocationUpdate(cmd *cobra.Command, args []string) error {
 		params.Output = kernel.Opt(output)
 	}
 
-	resp, err := client.Invocations.Update(cmd.Context(), args[0], params)
+	resp, err := withIDPrefix(cmd.Context(), "invocation", args[0], invocationIDLister(&client.Invocations), func(id string) (*kernel.InvocationUpdateResponse, error) {
+		return client.Invocations.Update(cmd.Context(), id, params)
+	})
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -684,10 +917,15 @@ func runInvocationUpdate(cmd *cobra.Command, args []string) error {
 
 func runInvocationDeleteBrowsers(cmd *cobra.Command, args []string) error {
 	client := getKernelClient(cmd)
-	if err := client.Invocations.DeleteBrowsers(cmd.Context(), args[0]); err != nil {
+	invocationID := args[0]
+	_, err := withIDPrefix(cmd.Context(), "invocation", invocationID, invocationIDLister(&client.Invocations), func(id string) (struct{}, error) {
+		invocationID = id
+		return struct{}{}, client.Invocations.DeleteBrowsers(cmd.Context(), id)
+	})
+	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
 
-	pterm.Success.Printf("Deleted browsers for invocation %s\n", args[0])
+	pterm.Success.Printf("Deleted browsers for invocation %s\n", invocationID)
 	return nil
 }
@@ -0,0 +1,401 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+// invocationCompareMaxLogLines caps the log lines aligned per invocation, as
+// alignment is quadratic in the number of lines.
+const invocationCompareMaxLogLines = 1000
+
+// logNumberRun matches the numbers in log lines (timestamps, counters, IDs)
+// that differ between runs without the runs having diverged.
+var logNumberRun = regexp.MustCompile(`[0-9]+`)
+
+// prefixInvocationGetter accepts unambiguous invocation ID prefixes.
+type prefixInvocationGetter struct {
+	svc *kernel.InvocationService
+}
+
+func (g prefixInvocationGetter) Get(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.InvocationGetResponse, error) {
+	return withIDPrefix(ctx, "invocation", id, invocationIDLister(g.svc), func(id string) (*kernel.InvocationGetResponse, error) {
+		return g.svc.Get(ctx, id, opts...)
+	})
+}
+
+// InvocationCompareCmd compares two invocations.
+type InvocationCompareCmd struct {
+	invocations InvocationGetter
+	follower    InvocationFollower
+	idleTimeout time.Duration
+}
+
+type InvocationCompareInput struct {
+	A      string
+	B      string
+	Output string
+}
+
+// invocationFieldDiff is one top-level property of both invocations.
+type invocationFieldDiff struct {
+	Field string `json:"field"`
+	A     string `json:"a"`
+	B     string `json:"b"`
+	Same  bool   `json:"same"`
+}
+
+// jsonValueDiff is a JSON path whose value differs; a missing side is null.
+type jsonValueDiff struct {
+	Path string  `json:"path"`
+	A    *string `json:"a"`
+	B    *string `json:"b"`
+}
+
+type compareLogLine struct {
+	// Offset is the time since the invocation started.
+	Offset  time.Duration
+	Message string
+}
+
+func (l compareLogLine) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"offset_ms": l.Offset.Milliseconds(), "message": l.Message})
+}
+
+// logAlignmentRow is one row of the aligned logs: a line both invocations
+// logged, or a line only one of them did.
+type logAlignmentRow struct {
+	A *compareLogLine `json:"a"`
+	B *compareLogLine `json:"b"`
+}
+
+func (r logAlignmentRow) diverges() bool { return r.A == nil || r.B == nil }
+
+// Compare shows how two invocations differ: their properties, payloads,
+// outputs and logs. Logs are aligned on the lines both wrote, ignoring
+// numbers, with the lines only one wrote ordered by time since start.
+func (c InvocationCompareCmd) Compare(ctx context.Context, in InvocationCompareInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if c.idleTimeout == 0 {
+		c.idleTimeout = appLogsIdleTimeout
+	}
+	jsonOutput := in.Output == "json"
+	if !jsonOutput {
+		pterm.Info.Println("Reading both invocations and their logs...")
+	}
+
+	var (
+		invs [2]*kernel.InvocationGetResponse
+		logs [2][]compareLogLine
+	)
+	g, gctx := errgroup.WithContext(ctx)
+	for i, id := range []string{in.A, in.B} {
+		g.Go(func() error {
+			inv, err := c.invocations.Get(gctx, id)
+			if err != nil {
+				return fmt.Errorf("invocation %s: %w", id, util.CleanedUpSdkError{Err: err})
+			}
+			invs[i] = inv
+			logs[i], err = c.logs(gctx, inv)
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	a, b := invs[0], invs[1]
+
+	fields := compareInvocationFields(a, b, time.Now())
+	payload := diffJSONValues(a.Payload, b.Payload)
+	output := diffJSONValues(a.Output, b.Output)
+	rows := alignInvocationLogs(logs[0], logs[1])
+
+	if jsonOutput {
+		return util.PrintJSON(map[string]any{
+			"a":       a.ID,
+			"b":       b.ID,
+			"fields":  fields,
+			"payload": payload,
+			"output":  output,
+			"logs":    rows,
+		})
+	}
+
+	if a.AppName != b.AppName || a.ActionName != b.ActionName {
+		pterm.Warning.Printf("These are invocations of different actions (%s/%s and %s/%s)\n", a.AppName, a.ActionName, b.AppName, b.ActionName)
+	}
+	table := pterm.TableData{{"", "Property", "A", "B"}}
+	for _, f := range fields {
+		table = append(table, []string{diffMarker(!f.Same), f.Field, f.A, f.B})
+	}
+	PrintTableNoPad(table, true)
+	printJSONValueDiffs("Payload", payload)
+	printJSONValueDiffs("Output", output)
+	printLogAlignment(rows, len(logs[0]), len(logs[1]))
+	return nil
+}
+
+// logs reads an invocation's log lines, up to invocationCompareMaxLogLines.
+func (c InvocationCompareCmd) logs(ctx context.Context, inv *kernel.InvocationGetResponse) ([]compareLogLine, error) {
+	var lines []compareLogLine
+	streamer := AppLogsCmd{follower: c.follower, now: time.Now, idleTimeout: c.idleTimeout}
+	err := streamer.stream(ctx, kernel.InvocationListResponse{ID: inv.ID, ActionName: inv.ActionName}, kernel.InvocationFollowParams{}, false, func(e appLogEntry) {
+		if e.Event != "log" || len(lines) >= invocationCompareMaxLogLines {
+			return
+		}
+		lines = append(lines, compareLogLine{Offset: e.Timestamp.Sub(inv.StartedAt), Message: strings.TrimRight(e.Message, "\r\n")})
+	})
+	return lines, err
+}
+
+func compareInvocationFields(a, b *kernel.InvocationGetResponse, now time.Time) []invocationFieldDiff {
+	pairs := [][3]string{
+		{"ID", a.ID, b.ID},
+		{"App Name", a.AppName, b.AppName},
+		{"Action", a.ActionName, b.ActionName},
+		{"Version", a.Version, b.Version},
+		{"Status", string(a.Status), string(b.Status)},
+		{"Status Reason", util.FirstOrDash(a.StatusReason), util.FirstOrDash(b.StatusReason)},
+		{"Started At", util.FormatLocal(a.StartedAt), util.FormatLocal(b.StartedAt)},
+		{"Duration", invocationDuration(string(a.Status), a.StartedAt, a.FinishedAt, now), invocationDuration(string(b.Status), b.StartedAt, b.FinishedAt, now)},
+	}
+	fields := make([]invocationFieldDiff, 0, len(pairs))
+	for _, p := range pairs {
+		// IDs and start times always differ; that's not a divergence.
+		same := p[1] == p[2] || p[0] == "ID" || p[0] == "Started At"
+		if p[0] == "Duration" {
+			same = !durationsDiverge(a, b)
+		}
+		fields = append(fields, invocationFieldDiff{Field: p[0], A: p[1], B: p[2], Same: same})
+	}
+	return fields
+}
+
+// durationsDiverge reports finished invocations whose durations differ by
+// more than half of the shorter one.
+func durationsDiverge(a, b *kernel.InvocationGetResponse) bool {
+	if a.FinishedAt.IsZero() || b.FinishedAt.IsZero() {
+		return false
+	}
+	da, db := a.FinishedAt.Sub(a.StartedAt), b.FinishedAt.Sub(b.StartedAt)
+	if da > db {
+		da, db = db, da
+	}
+	return db-da > da/2
+}
+
+// diffJSONValues compares two JSON documents leaf by leaf. Values that
+// aren't JSON compare as a whole.
+func diffJSONValues(a, b string) []jsonValueDiff {
+	fa, fb := flattenJSONString(a), flattenJSONString(b)
+	paths := map[string]bool{}
+	for p := range fa {
+		paths[p] = true
+	}
+	for p := range fb {
+		paths[p] = true
+	}
+	diffs := []jsonValueDiff{}
+	for p := range paths {
+		va, okA := fa[p]
+		vb, okB := fb[p]
+		if okA && okB && va == vb {
+			continue
+		}
+		d := jsonValueDiff{Path: p}
+		if okA {
+			d.A = &va
+		}
+		if okB {
+			d.B = &vb
+		}
+		diffs = append(diffs, d)
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs
+}
+
+func flattenJSONString(s string) map[string]string {
+	out := map[string]string{}
+	if s == "" {
+		return out
+	}
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		out["$"] = s
+		return out
+	}
+	flattenJSON("$", v, out)
+	return out
+}
+
+func flattenJSON(path string, v any, out map[string]string) {
+	switch t := v.(type) {
+	case map[string]any:
+		if len(t) == 0 {
+			out[path] = "{}"
+		}
+		for k, child := range t {
+			flattenJSON(path+"."+k, child, out)
+		}
+	case []any:
+		if len(t) == 0 {
+			out[path] = "[]"
+		}
+		for i, child := range t {
+			flattenJSON(path+"["+strconv.Itoa(i)+"]", child, out)
+		}
+	default:
+		b, _ := json.Marshal(t)
+		out[path] = string(b)
+	}
+}
+
+// alignInvocationLogs pairs the lines both invocations logged, in order and
+// ignoring numbers, using a longest common subsequence. Between paired lines,
+// the lines only one invocation logged are ordered by time since start.
+func alignInvocationLogs(a, b []compareLogLine) []logAlignmentRow {
+	key := func(l compareLogLine) string { return logNumberRun.ReplaceAllString(l.Message, "#") }
+	n, m := len(a), len(b)
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if key(a[i]) == key(b[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	rows := []logAlignmentRow{}
+	var pending []logAlignmentRow
+	flush := func() {
+		sort.SliceStable(pending, func(x, y int) bool { return pendingOffset(pending[x]) < pendingOffset(pending[y]) })
+		rows = append(rows, pending...)
+		pending = nil
+	}
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && key(a[i]) == key(b[j]):
+			flush()
+			rows = append(rows, logAlignmentRow{A: &a[i], B: &b[j]})
+			i++
+			j++
+		case j == m || (i < n && lcs[i+1][j] >= lcs[i][j+1]):
+			pending = append(pending, logAlignmentRow{A: &a[i]})
+			i++
+		default:
+			pending = append(pending, logAlignmentRow{B: &b[j]})
+			j++
+		}
+	}
+	flush()
+	return rows
+}
+
+func pendingOffset(r logAlignmentRow) time.Duration {
+	if r.A != nil {
+		return r.A.Offset
+	}
+	return r.B.Offset
+}
+
+func diffMarker(differs bool) string {
+	if differs {
+		return pterm.Yellow("≠")
+	}
+	return ""
+}
+
+func printJSONValueDiffs(title string, diffs []jsonValueDiff) {
+	if len(diffs) == 0 {
+		pterm.Success.Printf("%s: identical\n", title)
+		return
+	}
+	pterm.Warning.Printf("%s: %d difference(s)\n", title, len(diffs))
+	show := func(v *string) string {
+		if v == nil {
+			return pterm.Red("missing")
+		}
+		return *v
+	}
+	rows := pterm.TableData{{"Path", "A", "B"}}
+	for _, d := range diffs {
+		rows = append(rows, []string{d.Path, show(d.A), show(d.B)})
+	}
+	PrintTableNoPad(rows, true)
+}
+
+func printLogAlignment(rows []logAlignmentRow, countA, countB int) {
+	if len(rows) == 0 {
+		pterm.Info.Println("Logs: neither invocation logged anything")
+		return
+	}
+	first := -1
+	for i, r := range rows {
+		if r.diverges() {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		pterm.Success.Printf("Logs: the same %d line(s), ignoring numbers\n", len(rows))
+	} else {
+		pterm.Warning.Printf("Logs: diverge at row %d (%d line(s) in A, %d in B)\n", first+1, countA, countB)
+	}
+	show := func(l *compareLogLine) (string, string) {
+		if l == nil {
+			return "", ""
+		}
+		return "+" + l.Offset.Round(time.Millisecond).String(), l.Message
+	}
+	table := pterm.TableData{{"", "+A", "A", "+B", "B"}}
+	for _, r := range rows {
+		offA, msgA := show(r.A)
+		offB, msgB := show(r.B)
+		table = append(table, []string{diffMarker(r.diverges()), offA, msgA, offB, msgB})
+	}
+	PrintTableNoPad(table, true)
+}
+
+var invocationCompareCmd = &cobra.Command{
+	Use:   "compare <invocation_a> <invocation_b>",
+	Short: "Compare two invocations: properties, payloads, outputs and logs",
+	Long: `Compare two invocations, typically of the same action, to find out why one
+behaved differently. Shows their properties side by side, the JSON paths where
+their payloads and outputs differ, and their logs aligned on the lines both
+wrote (numbers are ignored when matching), marking lines only one of them
+wrote.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runInvocationCompare,
+}
+
+func runInvocationCompare(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	output, _ := cmd.Flags().GetString("output")
+	c := InvocationCompareCmd{
+		invocations: prefixInvocationGetter{svc: &client.Invocations},
+		follower:    &client.Invocations,
+	}
+	return c.Compare(cmd.Context(), InvocationCompareInput{A: args[0], B: args[1], Output: output})
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffJSONValues(t *testing.T) {
+	diffs := diffJSONValues(`{"url":"https://a","opts":{"retries":1,"tags":["x"]}}`, `{"url":"https://a","opts":{"retries":2,"tags":[]},"debug":true}`)
+	str := func(s string) *string { return &s }
+	assert.Equal(t, []jsonValueDiff{
+		{Path: "$.debug", B: str("true")},
+		{Path: "$.opts.retries", A: str("1"), B: str("2")},
+		{Path: "$.opts.tags", B: str("[]")},
+		{Path: "$.opts.tags[0]", A: str(`"x"`)},
+	}, diffs)
+
+	assert.Equal(t, []jsonValueDiff{{Path: "$", A: str("plain"), B: str("text")}}, diffJSONValues("plain", "text"))
+	assert.Empty(t, diffJSONValues(`{"a":1}`, `{ "a": 1 }`))
+}
+
+func TestAlignInvocationLogs(t *testing.T) {
+	line := func(ms int, msg string) compareLogLine {
+		return compareLogLine{Offset: time.Duration(ms) * time.Millisecond, Message: msg}
+	}
+	a := []compareLogLine{line(0, "start"), line(10, "fetched 12 items"), line(20, "retrying"), line(40, "done")}
+	b := []compareLogLine{line(0, "start"), line(15, "fetched 7 items"), line(18, "cache miss"), line(30, "error: timeout")}
+
+	rows := alignInvocationLogs(a, b)
+	var got [][2]string
+	for _, r := range rows {
+		var pair [2]string
+		if r.A != nil {
+			pair[0] = r.A.Message
+		}
+		if r.B != nil {
+			pair[1] = r.B.Message
+		}
+		got = append(got, pair)
+	}
+	assert.Equal(t, [][2]string{
+		{"start", "start"},
+		{"fetched 12 items", "fetched 7 items"},
+		{"", "cache miss"},
+		{"retrying", ""},
+		{"", "error: timeout"},
+		{"done", ""},
+	}, got)
+}
+
+func TestInvocationCompare_JSON(t *testing.T) {
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	invs := map[string]*kernel.InvocationGetResponse{
+		"inv_a": {ID: "inv_a", AppName: "app", ActionName: "scrape", Version: "1", Status: kernel.InvocationGetResponseStatusSucceeded,
+			StartedAt: start, FinishedAt: start.Add(time.Second), Payload: `{"page":1}`, Output: `{"ok":true}`},
+		"inv_b": {ID: "inv_b", AppName: "app", ActionName: "scrape", Version: "2", Status: kernel.InvocationGetResponseStatusFailed,
+			StartedAt: start.Add(time.Hour), FinishedAt: start.Add(time.Hour + 5*time.Second), Payload: `{"page":1}`},
+	}
+	c := InvocationCompareCmd{
+		invocations: fakeInvocationGetter(func(ctx context.Context, id string) (*kernel.InvocationGetResponse, error) {
+			return invs[id], nil
+		}),
+		follower: &fakeInvocationFollower{events: map[string][]string{
+			"inv_a": {`{"event":"log","message":"start\n","timestamp":"2026-05-01T12:00:00.1Z"}`},
+			"inv_b": {`{"event":"log","message":"start\n","timestamp":"2026-05-01T13:00:00.3Z"}`},
+		}},
+	}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = c.Compare(context.Background(), InvocationCompareInput{A: "inv_a", B: "inv_b", Output: "json"})
+	})
+	require.NoError(t, err)
+
+	var res struct {
+		Fields  []invocationFieldDiff `json:"fields"`
+		Payload []jsonValueDiff       `json:"payload"`
+		Output  []jsonValueDiff       `json:"output"`
+		Logs    []map[string]struct {
+			OffsetMs int64  `json:"offset_ms"`
+			Message  string `json:"message"`
+		} `json:"logs"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &res))
+	var differing []string
+	for _, f := range res.Fields {
+		if !f.Same {
+			differing = append(differing, f.Field)
+		}
+	}
+	assert.Equal(t, []string{"Version", "Status", "Duration"}, differing)
+	assert.Empty(t, res.Payload)
+	require.Len(t, res.Output, 1)
+	assert.Equal(t, "$.ok", res.Output[0].Path)
+	require.Len(t, res.Logs, 1)
+	assert.Equal(t, int64(100), res.Logs[0]["a"].OffsetMs)
+	assert.Equal(t, int64(300), res.Logs[0]["b"].OffsetMs)
+	assert.Equal(t, "start", res.Logs[0]["b"].Message)
+}
@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+)
+
+// maxDeployedSources bounds the deployment -> source hash records kept.
+const maxDeployedSources = 200
+
+// defaultEntrypoints are tried, in order, when an app dir is deployed for
+// the first time. They match the entrypoints of the create templates.
+var defaultEntrypoints = []string{"index.ts", "main.py", "index.js", "main.ts"}
+
+type deployedSource struct {
+	SourceHash string    `json:"source_hash"`
+	DeployedAt time.Time `json:"deployed_at"`
+}
+
+func deployedSourcesPath() string {
+	dir, err := util.CacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "deployed_sources.json")
+}
+
+func loadDeployedSources(path string) map[string]deployedSource {
+	sources := map[string]deployedSource{}
+	if path == "" {
+		return sources
+	}
+	if b, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(b, &sources)
+	}
+	return sources
+}
+
+// recordDeployedSource remembers the source hash a deployment was made
+// from, keeping the newest maxDeployedSources records. An empty path
+// disables recording.
+func recordDeployedSource(path, deploymentID, hash string, now time.Time) error {
+	if path == "" {
+		return nil
+	}
+	sources := loadDeployedSources(path)
+	sources[deploymentID] = deployedSource{SourceHash: hash, DeployedAt: now}
+	if len(sources) > maxDeployedSources {
+		ids := make([]string, 0, len(sources))
+		for id := range sources {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return sources[ids[i]].DeployedAt.After(sources[ids[j]].DeployedAt) })
+		for _, id := range ids[maxDeployedSources:] {
+			delete(sources, id)
+		}
+	}
+	b, err := json.Marshal(sources)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o600)
+}
+
+// deploySourceHash hashes the entrypoint and the files in a deploy zip.
+// Unlike the archive's own checksum it doesn't depend on entry order or
+// timestamps, so zipping unchanged source gives the same hash.
+func deploySourceHash(zipPath, entrypoint string) (string, error) {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	files := slices.Clone(r.File)
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+
+	h := sha256.New()
+	fmt.Fprintf(h, "entrypoint %s\n", entrypoint)
+	for _, f := range files {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return "", err
+		}
+		fh := sha256.New()
+		_, err = io.Copy(fh, rc)
+		rc.Close()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s %o %x\n", f.Name, f.Mode(), fh.Sum(nil))
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// resolveAppEntrypoint picks the entrypoint to deploy dir with: the one the
+// current deployment used if dir still has it, else the first default found.
+func resolveAppEntrypoint(dir, deployed string) (string, error) {
+	candidates := defaultEntrypoints
+	if deployed != "" {
+		candidates = append([]string{deployed}, candidates...)
+	}
+	for _, name := range candidates {
+		if st, err := os.Stat(filepath.Join(dir, name)); err == nil && !st.IsDir() {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("no entrypoint found in %s (looked for %s); deploy it once with \"kernel deploy <entrypoint>\"", dir, strings.Join(candidates, ", "))
+}
+
+// missingEnvVars lists the env vars the deployed version sets that a
+// redeploy wouldn't. The API redacts values for CLI callers, so only
+// non-empty deployed values can be carried over; they're added to envVars.
+func missingEnvVars(deployed, envVars map[string]string) []string {
+	var missing []string
+	for k, v := range deployed {
+		if _, ok := envVars[k]; ok {
+			continue
+		}
+		if v != "" {
+			envVars[k] = v
+			continue
+		}
+		missing = append(missing, k)
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+type deployIfChangedInput struct {
+	AppName string
+	Version string
+	AppDir  string
+	EnvVars map[string]string
+	// EnvScope selects the stored app env to apply (see appEnvScope).
+	EnvScope string
+	Output   string
+}
+
+// deployIfChanged deploys in.AppDir as in.Version of in.AppName unless the
+// deployed version was built from the same source. The deployed source is
+// matched by the hash recorded when this machine deployed it, or by the
+// archive checksum the API keeps.
+func deployIfChanged(ctx context.Context, client kernel.Client, in deployIfChangedInput) (err error) {
+	startTime := time.Now()
+	dir, err := filepath.Abs(in.AppDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve app dir: %w", err)
+	}
+	if st, err := os.Stat(dir); err != nil || !st.IsDir() {
+		return fmt.Errorf("app dir %s is not a directory", dir)
+	}
+
+	apps, err := client.Apps.List(ctx, kernel.AppListParams{
+		AppName: kernel.Opt(in.AppName),
+		Version: kernel.Opt(in.Version),
+	})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	var app *kernel.AppListResponse
+	if apps != nil {
+		for i := range apps.Items {
+			if apps.Items[i].AppName == in.AppName && apps.Items[i].Version == in.Version {
+				app = &apps.Items[i]
+				break
+			}
+		}
+	}
+	var deployment *kernel.DeploymentGetResponse
+	if app != nil && app.Deployment != "" {
+		if deployment, err = client.Deployments.Get(ctx, app.Deployment); err != nil {
+			return util.CleanedUpSdkError{Err: err}
+		}
+	}
+
+	var deployedEntrypoint string
+	if deployment != nil {
+		deployedEntrypoint = deployment.EntrypointRelPath
+	}
+	entrypoint, err := resolveAppEntrypoint(dir, deployedEntrypoint)
+	if err != nil {
+		return err
+	}
+
+	resource, what := deployRunLock(in.AppName, in.Version, dir)
+	guard, ctx, err := acquireRunGuard(ctx, defaultRunLockStore(), resource, what)
+	if err != nil {
+		return err
+	}
+	defer func() { err = guard.Release(err) }()
+
+	zipPath, err := zipDeploySource(dir, in.Output)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(zipPath)
+	hash, err := deploySourceHash(zipPath, entrypoint)
+	if err != nil {
+		return fmt.Errorf("failed to hash app source: %w", err)
+	}
+
+	if deployment != nil && entrypoint == deployment.EntrypointRelPath {
+		recorded := loadDeployedSources(deployedSourcesPath())[deployment.ID].SourceHash
+		archiveSum, _ := fileSHA256Hex(zipPath)
+		if recorded == hash || (deployment.SourceChecksum != "" && deployment.SourceChecksum == archiveSum) {
+			if in.Output != "json" {
+				pterm.Info.Printf("Deployed source of \"%s\" (version: %s) is up to date\n", in.AppName, in.Version)
+			}
+			return nil
+		}
+	}
+
+	if in.EnvVars == nil {
+		in.EnvVars = map[string]string{}
+	}
+	if err := applyDefaultAppEnv(in.EnvScope, in.AppName, in.EnvVars, in.Output); err != nil {
+		return err
+	}
+	if app != nil {
+		if missing := missingEnvVars(app.EnvVars, in.EnvVars); len(missing) > 0 {
+			return fmt.Errorf("version %s of \"%s\" sets %s; pass them with --env or --env-file to redeploy, or use --no-deploy", in.Version, in.AppName, strings.Join(missing, ", "))
+		}
+	}
+	if in.Output != "json" {
+		if app == nil {
+			pterm.Info.Printf("\"%s\" (version: %s) isn't deployed; deploying %s\n", in.AppName, in.Version, dir)
+		} else {
+			pterm.Info.Printf("Source in %s changed since the deployed version; redeploying\n", dir)
+		}
+	}
+	_, err = uploadDeploySource(ctx, client, zipPath, deploySourceInput{
+		AppName:    in.AppName,
+		SourceDir:  dir,
+		Entrypoint: entrypoint,
+		Version:    in.Version,
+		// Replacing the version being invoked is the point
+		Force:   app != nil,
+		EnvVars: in.EnvVars,
+		Output:  in.Output,
+	}, startTime)
+	return err
+}
+
+func fileSHA256Hex(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
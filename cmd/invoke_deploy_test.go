@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func zipAndHash(t *testing.T, dir, entrypoint string) string {
+	t.Helper()
+	zipPath := filepath.Join(t.TempDir(), "src.zip")
+	require.NoError(t, util.ZipDirectory(dir, zipPath, nil))
+	hash, err := deploySourceHash(zipPath, entrypoint)
+	require.NoError(t, err)
+	return hash
+}
+
+func TestDeploySourceHash(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.ts"), []byte("export {}"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "lib"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "lib", "a.ts"), []byte("a"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "lib", "b.ts"), []byte("b"), 0o644))
+
+	first := zipAndHash(t, dir, "index.ts")
+	// Rezipping unchanged source, even with new timestamps, hashes the same
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "lib", "a.ts"), later, later))
+	assert.Equal(t, first, zipAndHash(t, dir, "index.ts"))
+
+	assert.NotEqual(t, first, zipAndHash(t, dir, "lib/a.ts"), "entrypoint is part of the hash")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "lib", "b.ts"), []byte("b2"), 0o644))
+	assert.NotEqual(t, first, zipAndHash(t, dir, "index.ts"))
+}
+
+func TestRecordDeployedSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deployed_sources.json")
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < maxDeployedSources+5; i++ {
+		require.NoError(t, recordDeployedSource(path, fmt.Sprintf("dep_%d", i), fmt.Sprintf("hash_%d", i), start.Add(time.Duration(i)*time.Minute)))
+	}
+	sources := loadDeployedSources(path)
+	assert.Len(t, sources, maxDeployedSources)
+	assert.NotContains(t, sources, "dep_4", "oldest records are dropped")
+	assert.Equal(t, "hash_5", sources["dep_5"].SourceHash)
+
+	assert.NoError(t, recordDeployedSource("", "dep", "hash", start))
+	assert.Empty(t, loadDeployedSources(filepath.Join(t.TempDir(), "missing.json")))
+}
+
+func TestResolveAppEntrypoint(t *testing.T) {
+	dir := t.TempDir()
+	_, err := resolveAppEntrypoint(dir, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "kernel deploy <entrypoint>")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.py"), []byte(""), 0o644))
+	got, err := resolveAppEntrypoint(dir, "")
+	require.NoError(t, err)
+	assert.Equal(t, "main.py", got)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.py"), []byte(""), 0o644))
+	got, err = resolveAppEntrypoint(dir, "app.py")
+	require.NoError(t, err)
+	assert.Equal(t, "app.py", got, "the deployed entrypoint wins")
+
+	got, err = resolveAppEntrypoint(dir, "gone.py")
+	require.NoError(t, err)
+	assert.Equal(t, "main.py", got)
+}
+
+func TestMissingEnvVars(t *testing.T) {
+	envVars := map[string]string{"API_KEY": "new"}
+	missing := missingEnvVars(map[string]string{"API_KEY": "", "TOKEN": "", "DEBUG": "true"}, envVars)
+	assert.Equal(t, []string{"TOKEN"}, missing)
+	assert.Equal(t, map[string]string{"API_KEY": "new", "DEBUG": "true"}, envVars)
+}
@@ -0,0 +1,354 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kernel/cli/pkg/rundir"
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/pterm/pterm"
+)
+
+const (
+	defaultFanoutConcurrency = 10
+	fanoutPollInterval       = 2 * time.Second
+	fanoutProgressInterval   = 5 * time.Second
+)
+
+// InvokeFanoutCmd creates one invocation per payload and follows them all.
+type InvokeFanoutCmd struct {
+	invocations InvocationsService
+}
+
+type InvokeFanoutInput struct {
+	App                 string
+	Action              string
+	Version             string
+	Payloads            []fanoutPayload
+	Concurrency         int
+	AsyncTimeoutSeconds int64
+	ResultsDir          string
+	PollInterval        time.Duration
+	ProgressInterval    time.Duration
+	Output              string
+}
+
+// fanoutPayload is one non-blank line of a --payload-lines file.
+type fanoutPayload struct {
+	Line    int
+	Payload string
+}
+
+// fanoutResult is what --results-dir and -o json record per invocation.
+type fanoutResult struct {
+	Line         int             `json:"line"`
+	InvocationID string          `json:"invocation_id,omitempty"`
+	Status       string          `json:"status"`
+	StatusReason string          `json:"status_reason,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	Payload      json.RawMessage `json:"payload,omitempty"`
+	Output       json.RawMessage `json:"output,omitempty"`
+	StartedAt    time.Time       `json:"started_at"`
+	DurationMs   int64           `json:"duration_ms"`
+}
+
+func (r fanoutResult) ok() bool {
+	return r.Status == string(kernel.InvocationGetResponseStatusSucceeded)
+}
+
+// readPayloadLines reads one JSON payload per line, skipping blank lines.
+// With expand, template functions are expanded per line, so {{uuid}} differs
+// per invocation; {{file}} paths are relative to dir.
+func readPayloadLines(r io.Reader, dir string, expand bool) ([]fanoutPayload, error) {
+	var payloads []fanoutPayload
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		expanded := text
+		if expand {
+			var err error
+			if expanded, err = util.ExpandTemplate(text, dir); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		if !json.Valid([]byte(expanded)) {
+			return nil, fmt.Errorf("line %d: invalid JSON payload", line)
+		}
+		payloads = append(payloads, fanoutPayload{Line: line, Payload: expanded})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(payloads) == 0 {
+		return nil, fmt.Errorf("no payloads found")
+	}
+	return payloads, nil
+}
+
+// readPayloadLinesFile reads --payload-lines from a file, or stdin for "-".
+func readPayloadLinesFile(path string, expand bool) ([]fanoutPayload, error) {
+	if path == "-" {
+		return readPayloadLines(os.Stdin, "", expand)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payload lines: %w", err)
+	}
+	defer f.Close()
+	payloads, err := readPayloadLines(f, filepath.Dir(path), expand)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return payloads, nil
+}
+
+// fanoutStats tracks progress across workers.
+type fanoutStats struct {
+	mu        sync.Mutex
+	inFlight  int
+	succeeded int
+	failed    int
+	errored   int
+}
+
+func (s *fanoutStats) record(r fanoutResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight--
+	switch {
+	case r.Error != "":
+		s.errored++
+	case r.ok():
+		s.succeeded++
+	default:
+		s.failed++
+	}
+}
+
+// Run invokes the action once per payload with at most Concurrency
+// invocations in flight, and fails if any invocation didn't succeed.
+func (f InvokeFanoutCmd) Run(ctx context.Context, in InvokeFanoutInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if in.Concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	if in.PollInterval <= 0 {
+		in.PollInterval = fanoutPollInterval
+	}
+	if in.ResultsDir != "" {
+		if err := os.MkdirAll(in.ResultsDir, 0o755); err != nil {
+			return fmt.Errorf("create results directory: %w", err)
+		}
+	}
+	jsonOutput := in.Output == "json"
+	total := len(in.Payloads)
+	if !jsonOutput {
+		pterm.Info.Printf("Invoking \"%s\" (action: %s, version: %s) %d times, %d at a time…\n", in.App, in.Action, in.Version, total, in.Concurrency)
+	}
+
+	run := rundir.FromContext(ctx)
+	stats := &fanoutStats{}
+	var outMu sync.Mutex
+	var writeErr error
+	startedAt := time.Now()
+
+	var progress <-chan time.Time
+	if !jsonOutput && in.ProgressInterval > 0 {
+		pt := time.NewTicker(in.ProgressInterval)
+		defer pt.Stop()
+		progress = pt.C
+	}
+
+	sem := make(chan struct{}, in.Concurrency)
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, p := range in.Payloads {
+			select {
+			case <-ctx.Done():
+				return
+			case sem <- struct{}{}:
+			}
+			stats.mu.Lock()
+			stats.inFlight++
+			stats.mu.Unlock()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				r := f.invokeOne(ctx, in, p)
+				stats.record(r)
+
+				outMu.Lock()
+				defer outMu.Unlock()
+				run.Event(r)
+				if err := writeFanoutResult(in.ResultsDir, r); err != nil && writeErr == nil {
+					writeErr = err
+				}
+				if jsonOutput {
+					_ = util.PrintJSONLine(r)
+				} else if !r.ok() {
+					pterm.Warning.Printf("Line %d: %s\n", r.Line, fanoutFailure(r))
+				}
+			}()
+		}
+		wg.Wait()
+	}()
+
+wait:
+	for {
+		select {
+		case <-done:
+			break wait
+		case <-progress:
+			printFanoutProgress(stats, total, time.Since(startedAt))
+		}
+	}
+	if writeErr != nil {
+		return fmt.Errorf("write results: %w", writeErr)
+	}
+
+	stats.mu.Lock()
+	succeeded, failed, errored := stats.succeeded, stats.failed, stats.errored
+	stats.mu.Unlock()
+	notRun := total - succeeded - failed - errored
+	if !jsonOutput {
+		printFanoutSummary(total, succeeded, failed, errored, notRun, time.Since(startedAt), in.ResultsDir)
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("interrupted with %d of %d payloads not run", notRun, total)
+	}
+	if failed+errored > 0 {
+		return fmt.Errorf("%d of %d invocations did not succeed", failed+errored, total)
+	}
+	return nil
+}
+
+// invokeOne submits one invocation and polls it to a terminal state.
+func (f InvokeFanoutCmd) invokeOne(ctx context.Context, in InvokeFanoutInput, p fanoutPayload) (r fanoutResult) {
+	r = fanoutResult{Line: p.Line, Payload: json.RawMessage(p.Payload), StartedAt: time.Now()}
+	defer func() { r.DurationMs = time.Since(r.StartedAt).Milliseconds() }()
+
+	params := kernel.InvocationNewParams{
+		AppName:    in.App,
+		ActionName: in.Action,
+		Version:    in.Version,
+		Async:      kernel.Opt(true),
+		Payload:    kernel.Opt(p.Payload),
+	}
+	if in.AsyncTimeoutSeconds > 0 {
+		params.AsyncTimeoutSeconds = kernel.Opt(in.AsyncTimeoutSeconds)
+	}
+	resp, err := f.invocations.New(ctx, params, option.WithMaxRetries(0))
+	if err != nil {
+		r.Status = "error"
+		r.Error = util.CleanedUpSdkError{Err: err}.Error()
+		return r
+	}
+	r.InvocationID = resp.ID
+	r.Status = string(resp.Status)
+	r.Output = fanoutOutput(resp.Output)
+	for r.Status == string(kernel.InvocationGetResponseStatusQueued) || r.Status == string(kernel.InvocationGetResponseStatusRunning) {
+		select {
+		case <-ctx.Done():
+			r.Error = "interrupted while waiting for the invocation"
+			return r
+		case <-time.After(in.PollInterval):
+		}
+		inv, err := f.invocations.Get(ctx, resp.ID)
+		if err != nil {
+			if ctx.Err() != nil {
+				r.Error = "interrupted while waiting for the invocation"
+			} else {
+				r.Error = util.CleanedUpSdkError{Err: err}.Error()
+			}
+			return r
+		}
+		r.Status = string(inv.Status)
+		r.StatusReason = inv.StatusReason
+		r.Output = fanoutOutput(inv.Output)
+	}
+	return r
+}
+
+// fanoutOutput keeps a JSON output as-is and quotes anything else.
+func fanoutOutput(output string) json.RawMessage {
+	if output == "" {
+		return nil
+	}
+	if json.Valid([]byte(output)) {
+		return json.RawMessage(output)
+	}
+	b, _ := json.Marshal(output)
+	return b
+}
+
+func fanoutFailure(r fanoutResult) string {
+	if r.Error != "" {
+		return r.Error
+	}
+	msg := fmt.Sprintf("invocation %s %s", r.InvocationID, r.Status)
+	if r.StatusReason != "" {
+		msg += ": " + r.StatusReason
+	}
+	return msg
+}
+
+// writeFanoutResult writes r to dir as line-<n>.json, named by its line in
+// the payload file. An empty dir writes nothing.
+func writeFanoutResult(dir string, r fanoutResult) error {
+	if dir == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, fmt.Sprintf("line-%05d.json", r.Line)), append(b, '\n'), 0o644)
+}
+
+func printFanoutProgress(stats *fanoutStats, total int, elapsed time.Duration) {
+	stats.mu.Lock()
+	inFlight, ok, failed, errored := stats.inFlight, stats.succeeded, stats.failed, stats.errored
+	stats.mu.Unlock()
+	pterm.Info.Printf("[%s] done=%d/%d in-flight=%d ok=%d failed=%d errors=%d\n",
+		elapsed.Round(time.Second), ok+failed+errored, total, inFlight, ok, failed, errored)
+}
+
+func printFanoutSummary(total, succeeded, failed, errored, notRun int, elapsed time.Duration, resultsDir string) {
+	rows := pterm.TableData{
+		{"Property", "Value"},
+		{"Payloads", strconv.Itoa(total)},
+		{"Succeeded", strconv.Itoa(succeeded)},
+		{"Failed", strconv.Itoa(failed)},
+		{"Errors", strconv.Itoa(errored)},
+	}
+	if notRun > 0 {
+		rows = append(rows, []string{"Not run", strconv.Itoa(notRun)})
+	}
+	rows = append(rows, []string{"Elapsed", elapsed.Round(time.Millisecond).String()})
+	PrintTableNoPad(rows, true)
+	if resultsDir != "" {
+		pterm.Success.Printf("Results written to %s\n", resultsDir)
+	}
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPayloadLines(t *testing.T) {
+	payloads, err := readPayloadLines(strings.NewReader("{\"n\":1}\n\n  {\"id\":\"{{uuid}}\"}  \n[1,2]\n"), "", true)
+	require.NoError(t, err)
+	require.Len(t, payloads, 3)
+	assert.Equal(t, fanoutPayload{Line: 1, Payload: `{"n":1}`}, payloads[0])
+	assert.Equal(t, 3, payloads[1].Line)
+	assert.NotContains(t, payloads[1].Payload, "{{uuid}}")
+	assert.Equal(t, 4, payloads[2].Line)
+
+	_, err = readPayloadLines(strings.NewReader("{\"ok\":true}\n{not json}\n"), "", true)
+	assert.EqualError(t, err, "line 2: invalid JSON payload")
+
+	_, err = readPayloadLines(strings.NewReader("\n\n"), "", true)
+	assert.EqualError(t, err, "no payloads found")
+
+	payloads, err = readPayloadLines(strings.NewReader(`{"tpl":"Hi {{name}}"}`), "", false)
+	require.NoError(t, err)
+	assert.Equal(t, `{"tpl":"Hi {{name}}"}`, payloads[0].Payload)
+}
+
+func TestInvokeFanout_RunsAllWithinConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	var mu sync.Mutex
+	polls := map[string]int{}
+	fake := &fakeLoadtestInvocations{
+		newFunc: func(ctx context.Context, body kernel.InvocationNewParams) (*kernel.InvocationNewResponse, error) {
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			var payload struct{ N int }
+			require.NoError(t, json.Unmarshal([]byte(body.Payload.Value), &payload))
+			if payload.N == 3 {
+				inFlight.Add(-1)
+				return nil, errors.New("rate limited")
+			}
+			return &kernel.InvocationNewResponse{ID: "inv_" + body.Payload.Value, Status: kernel.InvocationNewResponseStatusQueued}, nil
+		},
+		getFunc: func(ctx context.Context, id string) (*kernel.InvocationGetResponse, error) {
+			mu.Lock()
+			polls[id]++
+			n := polls[id]
+			mu.Unlock()
+			if n < 2 {
+				return &kernel.InvocationGetResponse{ID: id, Status: kernel.InvocationGetResponseStatusRunning}, nil
+			}
+			inFlight.Add(-1)
+			if strings.Contains(id, `"n":2`) {
+				return &kernel.InvocationGetResponse{ID: id, Status: kernel.InvocationGetResponseStatusFailed, StatusReason: "boom"}, nil
+			}
+			return &kernel.InvocationGetResponse{ID: id, Status: kernel.InvocationGetResponseStatusSucceeded, Output: `{"ok":true}`}, nil
+		},
+	}
+
+	var payloads []fanoutPayload
+	for i := 1; i <= 6; i++ {
+		payloads = append(payloads, fanoutPayload{Line: i, Payload: `{"n":` + string(rune('0'+i)) + `}`})
+	}
+	dir := filepath.Join(t.TempDir(), "results")
+	f := InvokeFanoutCmd{invocations: fake}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = f.Run(context.Background(), InvokeFanoutInput{
+			App: "app", Action: "act", Version: "latest", Payloads: payloads,
+			Concurrency: 2, ResultsDir: dir, PollInterval: time.Millisecond, Output: "json",
+		})
+	})
+	assert.EqualError(t, err, "2 of 6 invocations did not succeed")
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 6)
+	byLine := map[int]fanoutResult{}
+	for _, l := range lines {
+		var r fanoutResult
+		require.NoError(t, json.Unmarshal([]byte(l), &r))
+		byLine[r.Line] = r
+	}
+	assert.Equal(t, "succeeded", byLine[1].Status)
+	assert.JSONEq(t, `{"ok":true}`, string(byLine[1].Output))
+	assert.Equal(t, "failed", byLine[2].Status)
+	assert.Equal(t, "boom", byLine[2].StatusReason)
+	assert.Equal(t, "rate limited", byLine[3].Error)
+
+	b, err := os.ReadFile(filepath.Join(dir, "line-00002.json"))
+	require.NoError(t, err)
+	var r fanoutResult
+	require.NoError(t, json.Unmarshal(b, &r))
+	assert.Equal(t, 2, r.Line)
+	assert.JSONEq(t, `{"n":2}`, string(r.Payload))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 6)
+}
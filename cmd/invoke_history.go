@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/kernel/cli/pkg/table"
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+const (
+	invocationHistoryPageSize      = 100
+	invocationHistoryWatchInterval = 5 * time.Second
+)
+
+var invocationHistoryGetCmd = &cobra.Command{
+	Use:   "get <invocation_id>",
+	Short: "Show an invocation with its full payload, output and timing",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runInvocationGet,
+}
+
+// InvocationHistoryCmd lists past invocations.
+type InvocationHistoryCmd struct {
+	invocations InvocationLister
+	now         func() time.Time
+}
+
+type InvocationHistoryInput struct {
+	// Limit is how many invocations to show; 0 shows all of them.
+	Limit        int
+	Offset       int
+	App          string
+	Action       string
+	Version      string
+	DeploymentID string
+	Status       string
+	Since        string
+	Until        string
+	Watch        bool
+	Interval     time.Duration
+	Output       string
+}
+
+// historyQuery is InvocationHistoryInput validated into API parameters plus
+// the filters applied locally.
+type historyQuery struct {
+	params kernel.InvocationListParams
+	until  time.Time
+	limit  int
+}
+
+func (h InvocationHistoryCmd) query(in InvocationHistoryInput) (historyQuery, error) {
+	if in.Limit < 0 {
+		return historyQuery{}, fmt.Errorf("--limit can't be negative")
+	}
+	q := historyQuery{limit: in.Limit}
+	if in.Action != "" {
+		q.params.ActionName = kernel.Opt(in.Action)
+	}
+	if in.App != "" {
+		q.params.AppName = kernel.Opt(in.App)
+	}
+	if in.DeploymentID != "" {
+		q.params.DeploymentID = kernel.Opt(in.DeploymentID)
+	}
+	if in.Version != "" {
+		q.params.Version = kernel.Opt(in.Version)
+	}
+	if in.Status != "" {
+		switch strings.ToLower(in.Status) {
+		case "queued":
+			q.params.Status = kernel.InvocationListParamsStatusQueued
+		case "running":
+			q.params.Status = kernel.InvocationListParamsStatusRunning
+		case "succeeded":
+			q.params.Status = kernel.InvocationListParamsStatusSucceeded
+		case "failed":
+			q.params.Status = kernel.InvocationListParamsStatusFailed
+		default:
+			return historyQuery{}, fmt.Errorf("invalid --status value: %s (must be queued, running, succeeded, or failed)", in.Status)
+		}
+	}
+	now := h.now()
+	if in.Since != "" {
+		since, ok := util.ParseTimeOrAgo(in.Since, now)
+		if !ok {
+			return historyQuery{}, fmt.Errorf("invalid --since %q: use a duration like 2h or 7d, a date (2006-01-02) or an RFC-3339 timestamp", in.Since)
+		}
+		q.params.Since = kernel.Opt(since.UTC().Format(time.RFC3339))
+	}
+	if in.Until != "" {
+		until, ok := util.ParseTimeOrAgo(in.Until, now)
+		if !ok {
+			return historyQuery{}, fmt.Errorf("invalid --until %q: use a duration like 2h or 7d, a date (2006-01-02) or an RFC-3339 timestamp", in.Until)
+		}
+		q.until = until
+	}
+	if in.Offset > 0 {
+		q.params.Offset = kernel.Opt(int64(in.Offset))
+	}
+	return q, nil
+}
+
+// fetch pages through invocations until it has q.limit of them that started
+// before q.until, or there are no more. The API has no upper time bound, so
+// --until is applied here.
+func (h InvocationHistoryCmd) fetch(ctx context.Context, q historyQuery) ([]kernel.InvocationListResponse, error) {
+	var items []kernel.InvocationListResponse
+	offset := q.params.Offset.Value
+	for {
+		params := q.params
+		pageSize := invocationHistoryPageSize
+		if remaining := q.limit - len(items); q.limit > 0 && q.until.IsZero() && remaining < pageSize {
+			pageSize = remaining
+		}
+		params.Limit = kernel.Opt(int64(pageSize))
+		params.Offset = kernel.Opt(offset)
+		page, err := h.invocations.List(ctx, params)
+		if err != nil {
+			return nil, util.CleanedUpSdkError{Err: err}
+		}
+		if page == nil {
+			return items, nil
+		}
+		for _, inv := range page.Items {
+			if !q.until.IsZero() && inv.StartedAt.After(q.until) {
+				continue
+			}
+			items = append(items, inv)
+			if q.limit > 0 && len(items) == q.limit {
+				return items, nil
+			}
+		}
+		if len(page.Items) < pageSize {
+			return items, nil
+		}
+		offset += int64(len(page.Items))
+	}
+}
+
+// List shows past invocations matching the filters, or with Watch keeps
+// showing them until ctx is done.
+func (h InvocationHistoryCmd) List(ctx context.Context, in InvocationHistoryInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if h.now == nil {
+		h.now = time.Now
+	}
+	q, err := h.query(in)
+	if err != nil {
+		return err
+	}
+	if in.Watch {
+		return h.watch(ctx, in, q)
+	}
+
+	if in.Output != "json" {
+		pterm.Debug.Println("Listing invocations...")
+	}
+	items, err := h.fetch(ctx, q)
+	if err != nil {
+		return err
+	}
+	if in.Output == "json" {
+		if len(items) == 0 {
+			return util.PrintJSON([]any{})
+		}
+		return util.PrintPrettyJSONSlice(items)
+	}
+	util.WarnDecodeDrift(items)
+	if len(items) == 0 {
+		pterm.Info.Println("No invocations found.")
+		return nil
+	}
+	out, err := renderInvocationHistory(items, h.now())
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
+
+// watch refetches the history every interval. Relative --since and --until
+// values are fixed when watching starts. The table is redrawn in place on a
+// terminal and reprinted on change otherwise; JSON output prints each new or
+// changed invocation as a line.
+func (h InvocationHistoryCmd) watch(ctx context.Context, in InvocationHistoryInput, q historyQuery) error {
+	if in.Interval <= 0 {
+		in.Interval = invocationHistoryWatchInterval
+	}
+	jsonOutput := in.Output == "json"
+	var area *pterm.AreaPrinter
+	if !jsonOutput {
+		pterm.Info.Printf("Watching invocations every %s (Ctrl+C to stop)...\n", in.Interval)
+		if table.IsStdoutTTY() {
+			area, _ = pterm.DefaultArea.Start()
+			defer func() { _ = area.Stop() }()
+		}
+	}
+
+	seen := map[string]kernel.InvocationListResponseStatus{}
+	var last string
+	for {
+		items, err := h.fetch(ctx, q)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			// Oldest first, so lines read in the order things happened.
+			for i := len(items) - 1; i >= 0; i-- {
+				inv := items[i]
+				if status, ok := seen[inv.ID]; !ok || status != inv.Status {
+					seen[inv.ID] = inv.Status
+					_ = util.PrintJSONLine(inv)
+				}
+			}
+		} else {
+			out := "No invocations found.\n"
+			if len(items) > 0 {
+				if out, err = renderInvocationHistory(items, h.now()); err != nil {
+					return err
+				}
+			}
+			if area != nil {
+				area.Update(out)
+			} else if out != last {
+				fmt.Print(out)
+			}
+			last = out
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(in.Interval):
+		}
+	}
+}
+
+func renderInvocationHistory(items []kernel.InvocationListResponse, now time.Time) (string, error) {
+	data := pterm.TableData{{"Invocation ID", "App Name", "Action", "Version", "Status", "Started At", "Duration", "Output"}}
+	for _, inv := range items {
+		// Truncate output for display
+		output := inv.Output
+		if len(output) > 50 {
+			output = output[:47] + "..."
+		}
+		if output == "" {
+			output = "-"
+		}
+		data = append(data, []string{
+			inv.ID,
+			inv.AppName,
+			inv.ActionName,
+			inv.Version,
+			string(inv.Status),
+			util.FormatLocal(inv.StartedAt),
+			invocationDuration(string(inv.Status), inv.StartedAt, inv.FinishedAt, now),
+			output,
+		})
+	}
+	return pterm.DefaultTable.WithHasHeader().WithData(data).Srender()
+}
+
+// invocationDuration is how long an invocation took, or has been running.
+func invocationDuration(status string, startedAt, finishedAt, now time.Time) string {
+	switch {
+	case !finishedAt.IsZero():
+		return finishedAt.Sub(startedAt).Round(time.Millisecond).String()
+	case status == string(kernel.InvocationGetResponseStatusRunning):
+		return now.Sub(startedAt).Round(time.Second).String() + " (running)"
+	}
+	return "-"
+}
+
+func runInvocationHistory(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	limit, _ := cmd.Flags().GetInt("limit")
+	action, _ := cmd.Flags().GetString("action")
+	app, _ := cmd.Flags().GetString("app")
+	deploymentID, _ := cmd.Flags().GetString("deployment-id")
+	offset, _ := cmd.Flags().GetInt("offset")
+	since, _ := cmd.Flags().GetString("since")
+	until, _ := cmd.Flags().GetString("until")
+	status, _ := cmd.Flags().GetString("status")
+	failedOnly, _ := cmd.Flags().GetBool("failed-only")
+	version, _ := cmd.Flags().GetString("version")
+	watch, _ := cmd.Flags().GetBool("watch")
+	output, _ := cmd.Flags().GetString("output")
+	if failedOnly {
+		status = string(kernel.InvocationListParamsStatusFailed)
+	}
+
+	ctx := cmd.Context()
+	if watch {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+	}
+	h := InvocationHistoryCmd{invocations: &client.Invocations}
+	return h.List(ctx, InvocationHistoryInput{
+		Limit:        limit,
+		Offset:       offset,
+		App:          app,
+		Action:       action,
+		Version:      version,
+		DeploymentID: deploymentID,
+		Status:       status,
+		Since:        since,
+		Until:        until,
+		Watch:        watch,
+		Output:       output,
+	})
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pagedInvocations serves n invocations, newest first and one minute apart
+// ending at end, honouring limit and offset, and records each query.
+func pagedInvocations(n int, end time.Time, queries *[]kernel.InvocationListParams) *fakeInvocationListerFunc {
+	return &fakeInvocationListerFunc{fn: func(query kernel.InvocationListParams) []kernel.InvocationListResponse {
+		*queries = append(*queries, query)
+		var items []kernel.InvocationListResponse
+		for i := int(query.Offset.Value); i < n && len(items) < int(query.Limit.Value); i++ {
+			var inv kernel.InvocationListResponse
+			_ = json.Unmarshal([]byte(fmt.Sprintf(`{"id":"inv_%03d","status":"succeeded","started_at":%q}`,
+				i, end.Add(-time.Duration(i)*time.Minute).Format(time.RFC3339))), &inv)
+			items = append(items, inv)
+		}
+		return items
+	}}
+}
+
+func TestInvocationHistory_PagesPastPageSize(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	var queries []kernel.InvocationListParams
+	h := InvocationHistoryCmd{invocations: pagedInvocations(250, now, &queries), now: func() time.Time { return now }}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = h.List(context.Background(), InvocationHistoryInput{Limit: 0, Output: "json"})
+	})
+	require.NoError(t, err)
+	var items []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(out), &items))
+	assert.Len(t, items, 250)
+	require.Len(t, queries, 3)
+	assert.Equal(t, int64(200), queries[2].Offset.Value)
+
+	queries = nil
+	out = captureStdout(t, func() {
+		err = h.List(context.Background(), InvocationHistoryInput{Limit: 120, Output: "json"})
+	})
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(out), &items))
+	assert.Len(t, items, 120)
+	require.Len(t, queries, 2)
+	assert.Equal(t, int64(20), queries[1].Limit.Value)
+}
+
+func TestInvocationHistory_SinceUntilAndFailedOnly(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	var queries []kernel.InvocationListParams
+	h := InvocationHistoryCmd{invocations: pagedInvocations(10, now, &queries), now: func() time.Time { return now }}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = h.List(context.Background(), InvocationHistoryInput{
+			Limit:  3,
+			Since:  "2h",
+			Until:  "5m",
+			Status: "failed",
+			Output: "json",
+		})
+	})
+	require.NoError(t, err)
+	require.Len(t, queries, 1)
+	assert.Equal(t, "2026-05-01T10:00:00Z", queries[0].Since.Value)
+	assert.Equal(t, kernel.InvocationListParamsStatusFailed, queries[0].Status)
+
+	var items []kernel.InvocationListResponse
+	require.NoError(t, json.Unmarshal([]byte(out), &items))
+	require.Len(t, items, 3)
+	assert.Equal(t, "inv_005", items[0].ID)
+}
+
+func TestInvocationHistory_RejectsBadFilters(t *testing.T) {
+	h := InvocationHistoryCmd{invocations: &fakeInvocationListerFunc{}}
+
+	err := h.List(context.Background(), InvocationHistoryInput{Until: "last tuesday"})
+	assert.EqualError(t, err, `invalid --until "last tuesday": use a duration like 2h or 7d, a date (2006-01-02) or an RFC-3339 timestamp`)
+
+	err = h.List(context.Background(), InvocationHistoryInput{Status: "crashed"})
+	assert.EqualError(t, err, "invalid --status value: crashed (must be queued, running, succeeded, or failed)")
+}
+
+func TestInvocationDuration(t *testing.T) {
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "1.5s", invocationDuration("succeeded", start, start.Add(1500*time.Millisecond), start))
+	assert.Equal(t, "2m0s (running)", invocationDuration("running", start, time.Time{}, start.Add(2*time.Minute)))
+	assert.Equal(t, "-", invocationDuration("queued", start, time.Time{}, start))
+}
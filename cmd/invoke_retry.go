@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+)
+
+// How an invocation attempt ended. The last three are what --retry-on
+// accepts.
+const (
+	invokeResultSucceeded = "succeeded"
+	invokeResultFailed    = "failed"
+	invokeResultTimeout   = "timeout"
+	invokeResultError     = "error"
+)
+
+const defaultRetryBackoff = 5 * time.Second
+
+// defaultRetryOn leaves out invokeResultError: the CLI losing track of an
+// invocation doesn't mean it stopped, so retrying could run a second copy.
+var defaultRetryOn = []string{invokeResultTimeout}
+
+// invokeAttempt records one invocation made by kernel invoke.
+type invokeAttempt struct {
+	Attempt      int       `json:"attempt"`
+	InvocationID string    `json:"invocation_id,omitempty"`
+	Result       string    `json:"result"`
+	Status       string    `json:"status,omitempty"`
+	StatusReason string    `json:"status_reason,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	StartedAt    time.Time `json:"started_at"`
+	DurationMs   int64     `json:"duration_ms"`
+}
+
+// setStatus records a terminal invocation status. A failure whose reason
+// mentions a timeout counts as a timeout rather than a failure.
+func (a *invokeAttempt) setStatus(status, reason string) {
+	a.Status = status
+	a.StatusReason = reason
+	switch status {
+	case string(kernel.InvocationGetResponseStatusSucceeded):
+		a.Result = invokeResultSucceeded
+	case string(kernel.InvocationGetResponseStatusFailed):
+		lower := strings.ToLower(reason)
+		if strings.Contains(lower, "timeout") || strings.Contains(lower, "timed out") {
+			a.Result = invokeResultTimeout
+		} else {
+			a.Result = invokeResultFailed
+		}
+	}
+}
+
+func (a invokeAttempt) describe() string {
+	var msg string
+	switch {
+	case a.InvocationID == "":
+		msg = "couldn't create the invocation"
+	case a.Result == invokeResultError:
+		msg = fmt.Sprintf("lost track of invocation %s", a.InvocationID)
+	default:
+		msg = fmt.Sprintf("invocation %s %s", a.InvocationID, a.Result)
+	}
+	if a.Error != "" {
+		return msg + ": " + a.Error
+	}
+	return msg + reasonSuffix(a.StatusReason)
+}
+
+// invokeRetryPolicy is how kernel invoke retries an attempt that didn't
+// succeed. Each retry is a fresh invocation.
+type invokeRetryPolicy struct {
+	Retries int
+	Backoff time.Duration
+	On      map[string]bool
+	JSON    bool
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// parseRetryOn validates --retry-on values.
+func parseRetryOn(values []string) (map[string]bool, error) {
+	on := map[string]bool{}
+	for _, v := range values {
+		v = strings.ToLower(strings.TrimSpace(v))
+		switch v {
+		case invokeResultFailed, invokeResultTimeout, invokeResultError:
+			on[v] = true
+		case "":
+		default:
+			return nil, fmt.Errorf("invalid --retry-on value: %s (must be failed, timeout or error)", v)
+		}
+	}
+	if len(on) == 0 {
+		return nil, fmt.Errorf("--retry-on needs at least one of failed, timeout or error")
+	}
+	return on, nil
+}
+
+// backoff returns the wait before retry n (1-based): Backoff, doubling for
+// each further retry.
+func (p invokeRetryPolicy) backoff(n int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < n && d < time.Hour; i++ {
+		d *= 2
+	}
+	return d
+}
+
+// run calls attempt until it succeeds, ends in a way the policy doesn't
+// retry, or the retries run out, and returns the last attempt. With retries
+// configured it then prints a summary of every attempt, and exhausting them
+// is an error.
+func (p invokeRetryPolicy) run(ctx context.Context, attempt func() (invokeAttempt, error)) (invokeAttempt, error) {
+	sleep := p.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+	var attempts []invokeAttempt
+	for n := 1; ; n++ {
+		a, err := attempt()
+		a.Attempt = n
+		attempts = append(attempts, a)
+		if p.Retries == 0 {
+			return a, err
+		}
+		if n > p.Retries || !p.On[a.Result] || ctx.Err() != nil {
+			p.printSummary(attempts)
+			if err == nil && a.Result != invokeResultSucceeded && a.Result != "" {
+				err = fmt.Errorf("%s after %d attempt(s)", a.describe(), n)
+			}
+			return a, err
+		}
+		wait := p.backoff(n)
+		if !p.JSON {
+			pterm.Warning.Printf("Attempt %d of %d: %s; retrying in %s…\n", n, p.Retries+1, a.describe(), wait)
+		}
+		if err := sleep(ctx, wait); err != nil {
+			p.printSummary(attempts)
+			return a, fmt.Errorf("interrupted before retrying: %s", a.describe())
+		}
+	}
+}
+
+// printSummary prints every attempt as a final JSON line, or as a table when
+// there was more than one.
+func (p invokeRetryPolicy) printSummary(attempts []invokeAttempt) {
+	last := attempts[len(attempts)-1]
+	if p.JSON {
+		_ = util.PrintJSONLine(map[string]any{
+			"event":     "attempts",
+			"succeeded": last.Result == invokeResultSucceeded,
+			"attempts":  attempts,
+		})
+		return
+	}
+	if len(attempts) == 1 {
+		return
+	}
+	rows := pterm.TableData{{"Attempt", "Invocation ID", "Result", "Duration", "Reason"}}
+	for _, a := range attempts {
+		id := a.InvocationID
+		if id == "" {
+			id = "-"
+		}
+		reason := a.StatusReason
+		if a.Error != "" {
+			reason = a.Error
+		}
+		if reason == "" {
+			reason = "-"
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(a.Attempt),
+			id,
+			a.Result,
+			(time.Duration(a.DurationMs) * time.Millisecond).String(),
+			reason,
+		})
+	}
+	PrintTableNoPad(rows, true)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
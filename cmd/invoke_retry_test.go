@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeAttemptSetStatus(t *testing.T) {
+	var a invokeAttempt
+	a.setStatus("failed", "Invocation timed out after 300s")
+	assert.Equal(t, invokeResultTimeout, a.Result)
+	a.setStatus("failed", "TypeError: x is undefined")
+	assert.Equal(t, invokeResultFailed, a.Result)
+	a.setStatus("succeeded", "")
+	assert.Equal(t, invokeResultSucceeded, a.Result)
+}
+
+func TestParseRetryOn(t *testing.T) {
+	on, err := parseRetryOn([]string{"Timeout", " failed "})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"timeout": true, "failed": true}, on)
+
+	_, err = parseRetryOn([]string{"crashed"})
+	assert.EqualError(t, err, "invalid --retry-on value: crashed (must be failed, timeout or error)")
+}
+
+func TestInvokeRetryPolicy_DefaultDoesntRetryErrors(t *testing.T) {
+	require.NoError(t, invokeCmd.Flags().Set("retries", "2"))
+	t.Cleanup(func() { _ = invokeCmd.Flags().Set("retries", "0") })
+
+	policy, err := invokeRetryPolicyFromFlags(invokeCmd, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{invokeResultTimeout: true}, policy.On, "a lost invocation may still be running")
+}
+
+// retrySequence returns an attempt func that ends each attempt with the next
+// result in order.
+func retrySequence(results ...string) (func() (invokeAttempt, error), *int) {
+	calls := 0
+	return func() (invokeAttempt, error) {
+		r := results[calls]
+		calls++
+		a := invokeAttempt{InvocationID: "inv_" + string(rune('0'+calls)), Result: r}
+		if r == invokeResultError {
+			return invokeAttempt{Result: r, Error: "503 Service Unavailable"}, errors.New("503 Service Unavailable")
+		}
+		return a, nil
+	}, &calls
+}
+
+func TestInvokeRetryPolicy_RetriesUntilSuccess(t *testing.T) {
+	var waits []time.Duration
+	p := invokeRetryPolicy{
+		Retries: 3,
+		Backoff: time.Second,
+		On:      map[string]bool{invokeResultTimeout: true, invokeResultError: true},
+		sleep: func(ctx context.Context, d time.Duration) error {
+			waits = append(waits, d)
+			return nil
+		},
+	}
+	attempt, calls := retrySequence(invokeResultError, invokeResultTimeout, invokeResultSucceeded)
+
+	buf := capturePtermOutput(t)
+	last, err := p.run(context.Background(), attempt)
+	require.NoError(t, err)
+	assert.Equal(t, 3, *calls)
+	assert.Equal(t, 3, last.Attempt)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, waits)
+	assert.Contains(t, buf.String(), "Attempt 1 of 4: couldn't create the invocation: 503 Service Unavailable; retrying in 1s")
+	assert.Contains(t, buf.String(), "Attempt 2 of 4: invocation inv_2 timeout; retrying in 2s")
+}
+
+func TestInvokeRetryPolicy_StopsOnOutcomeNotRetried(t *testing.T) {
+	p := invokeRetryPolicy{
+		Retries: 3,
+		On:      map[string]bool{invokeResultTimeout: true},
+		JSON:    true,
+		sleep:   func(ctx context.Context, d time.Duration) error { return nil },
+	}
+	attempt, calls := retrySequence(invokeResultTimeout, invokeResultFailed, invokeResultSucceeded)
+
+	var err error
+	out := captureStdout(t, func() {
+		_, err = p.run(context.Background(), attempt)
+	})
+	assert.EqualError(t, err, "invocation inv_2 failed after 2 attempt(s)")
+	assert.Equal(t, 2, *calls)
+
+	var summary struct {
+		Event     string          `json:"event"`
+		Succeeded bool            `json:"succeeded"`
+		Attempts  []invokeAttempt `json:"attempts"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(out)), &summary))
+	assert.Equal(t, "attempts", summary.Event)
+	assert.False(t, summary.Succeeded)
+	require.Len(t, summary.Attempts, 2)
+	assert.Equal(t, invokeResultTimeout, summary.Attempts[0].Result)
+	assert.Equal(t, 2, summary.Attempts[1].Attempt)
+}
+
+func TestInvokeRetryPolicy_ExhaustedKeepsCreateError(t *testing.T) {
+	p := invokeRetryPolicy{
+		Retries: 1,
+		On:      map[string]bool{invokeResultError: true},
+		sleep:   func(ctx context.Context, d time.Duration) error { return nil },
+	}
+	attempt, calls := retrySequence(invokeResultError, invokeResultError)
+
+	capturePtermOutput(t)
+	last, err := p.run(context.Background(), attempt)
+	assert.EqualError(t, err, "503 Service Unavailable")
+	assert.Empty(t, last.InvocationID)
+	assert.Equal(t, 2, *calls)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvocationBodyWarning(t *testing.T) {
+	small := kernel.InvocationNewParams{AppName: "app", ActionName: "run", Version: "latest", Payload: kernel.Opt(`{"a":1}`)}
+	warning, err := invocationBodyWarning(small, false)
+	require.NoError(t, err)
+	assert.Empty(t, warning)
+
+	// 2MB of repetitive JSON: large raw, small once compressed.
+	big := small
+	big.Payload = kernel.Opt(`{"rows":"` + strings.Repeat("x", 2<<20) + `"}`)
+	warning, err = invocationBodyWarning(big, false)
+	require.NoError(t, err)
+	assert.Contains(t, warning, "may be rejected as too large; consider --compress")
+	warning, err = invocationBodyWarning(big, true)
+	require.NoError(t, err)
+	assert.Empty(t, warning)
+}
+
+func TestExplainPayloadRejection(t *testing.T) {
+	apiErr := func(status int) error {
+		req := &http.Request{Method: http.MethodPost, URL: &url.URL{Scheme: "https", Host: "api.example.com", Path: "/invocations"}}
+		return &kernel.Error{StatusCode: status, Request: req, Response: &http.Response{StatusCode: status}}
+	}
+
+	err := explainPayloadRejection(apiErr(http.StatusRequestEntityTooLarge), false)
+	assert.Contains(t, err.Error(), "retry with --compress")
+	var target *kernel.Error
+	assert.True(t, errors.As(err, &target), "the API error stays in the chain")
+
+	err = explainPayloadRejection(apiErr(http.StatusUnsupportedMediaType), true)
+	assert.Contains(t, err.Error(), "retry without --compress")
+
+	other := apiErr(http.StatusBadRequest)
+	assert.Same(t, other, explainPayloadRejection(other, true))
+}
+
+func TestReportResult_OutputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "result.json")
+	buf := capturePtermOutput(t)
+	out := captureStdout(t, func() {
+		require.NoError(t, reportResult(true, `{"rows":[1,2,3]}`, path, false))
+	})
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"rows":[1,2,3]}`, string(b))
+	assert.NotContains(t, out+buf.String(), "rows")
+	assert.Contains(t, buf.String(), "Output (16 B) written to "+path)
+
+	err = reportResult(false, "x", filepath.Join(t.TempDir(), "missing", "out.bin"), true)
+	assert.ErrorContains(t, err, "failed to write output")
+}
+
+func TestGetPayload_NoTemplate(t *testing.T) {
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("payload", "", "")
+		cmd.Flags().String("payload-file", "", "")
+		addNoTemplateFlag(cmd, "payloads")
+		require.NoError(t, cmd.ParseFlags(args))
+		return cmd
+	}
+
+	_, _, err := getPayload(newCmd("--payload", `{"tpl":"Hi {{name}}"}`))
+	assert.ErrorContains(t, err, `function "name" not defined`)
+
+	payload, ok, err := getPayload(newCmd("--payload", `{"tpl":"Hi {{name}}"}`, "--no-template"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, `{"tpl":"Hi {{name}}"}`, payload)
+}
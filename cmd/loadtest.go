@@ -0,0 +1,522 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// InvocationsService defines the subset of the Kernel SDK invocation client
+// used to submit and poll invocations.
+type InvocationsService interface {
+	New(ctx context.Context, body kernel.InvocationNewParams, opts ...option.RequestOption) (*kernel.InvocationNewResponse, error)
+	Get(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.InvocationGetResponse, error)
+}
+
+// LoadtestCmd is a cobra-independent command handler for load tests.
+type LoadtestCmd struct {
+	invocations InvocationsService
+}
+
+type LoadtestInvokeInput struct {
+	App              string
+	Action           string
+	Version          string
+	Payload          string
+	HasPayload       bool
+	Rate             float64 // invocations per second
+	Duration         time.Duration
+	MaxInFlight      int
+	PollInterval     time.Duration
+	ProgressInterval time.Duration
+	ReportFile       string
+	Output           string
+}
+
+// loadtestBucketBounds are the upper bounds of the latency histogram buckets;
+// a final +Inf bucket catches everything slower.
+var loadtestBucketBounds = []time.Duration{
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+	5 * time.Minute,
+}
+
+type LoadtestLatency struct {
+	MinMs  int64 `json:"min_ms"`
+	MeanMs int64 `json:"mean_ms"`
+	P50Ms  int64 `json:"p50_ms"`
+	P90Ms  int64 `json:"p90_ms"`
+	P99Ms  int64 `json:"p99_ms"`
+	MaxMs  int64 `json:"max_ms"`
+}
+
+type LoadtestBucket struct {
+	// LeMs is the bucket's inclusive upper bound in milliseconds; 0 means +Inf.
+	LeMs  int64 `json:"le_ms"`
+	Count int   `json:"count"`
+}
+
+// LoadtestReport summarizes a completed load test run.
+type LoadtestReport struct {
+	App           string           `json:"app"`
+	Action        string           `json:"action"`
+	Version       string           `json:"version"`
+	RatePerSecond float64          `json:"rate_per_second"`
+	Duration      string           `json:"duration"`
+	StartedAt     time.Time        `json:"started_at"`
+	FinishedAt    time.Time        `json:"finished_at"`
+	Sent          int              `json:"sent"`
+	Succeeded     int              `json:"succeeded"`
+	Failed        int              `json:"failed"`
+	Errored       int              `json:"errored"`
+	Skipped       int              `json:"skipped"`
+	ErrorRate     float64          `json:"error_rate"`
+	Throughput    float64          `json:"throughput_per_second"`
+	Latency       LoadtestLatency  `json:"latency"`
+	Histogram     []LoadtestBucket `json:"histogram"`
+	Errors        map[string]int   `json:"errors,omitempty"`
+}
+
+// loadtestStats accumulates results from concurrent invocations.
+type loadtestStats struct {
+	mu        sync.Mutex
+	sent      int
+	inFlight  int
+	succeeded int
+	failed    int
+	errored   int
+	skipped   int
+	latencies []time.Duration
+	errors    map[string]int
+}
+
+func (s *loadtestStats) record(latency time.Duration, status kernel.InvocationGetResponseStatus, errMsg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight--
+	switch {
+	case errMsg != "":
+		s.errored++
+		if s.errors == nil {
+			s.errors = map[string]int{}
+		}
+		s.errors[errMsg]++
+		return
+	case status == kernel.InvocationGetResponseStatusSucceeded:
+		s.succeeded++
+	default:
+		s.failed++
+	}
+	s.latencies = append(s.latencies, latency)
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return sorted[max(0, min(idx, len(sorted)-1))]
+}
+
+func summarizeLatencies(latencies []time.Duration) (LoadtestLatency, []LoadtestBucket) {
+	buckets := make([]LoadtestBucket, len(loadtestBucketBounds)+1)
+	for i, b := range loadtestBucketBounds {
+		buckets[i].LeMs = b.Milliseconds()
+	}
+	if len(latencies) == 0 {
+		return LoadtestLatency{}, buckets
+	}
+	sorted := slices.Clone(latencies)
+	slices.Sort(sorted)
+	var total time.Duration
+	for _, l := range sorted {
+		total += l
+		i := sort.Search(len(loadtestBucketBounds), func(i int) bool { return l <= loadtestBucketBounds[i] })
+		buckets[i].Count++
+	}
+	return LoadtestLatency{
+		MinMs:  sorted[0].Milliseconds(),
+		MeanMs: (total / time.Duration(len(sorted))).Milliseconds(),
+		P50Ms:  percentile(sorted, 50).Milliseconds(),
+		P90Ms:  percentile(sorted, 90).Milliseconds(),
+		P99Ms:  percentile(sorted, 99).Milliseconds(),
+		MaxMs:  sorted[len(sorted)-1].Milliseconds(),
+	}, buckets
+}
+
+// maxLoadtestRate caps the arrival rate, in invocations per second. Higher
+// rates are more than one CLI process can drive, and would round the ticker
+// interval down to nothing.
+const maxLoadtestRate = 1000
+
+// parseLoadtestRate parses a rate such as "5/s", "120/m", "1000/h", or a bare
+// number (per second) into invocations per second.
+func parseLoadtestRate(s string) (float64, error) {
+	num, unit, hasUnit := strings.Cut(strings.TrimSpace(s), "/")
+	n, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+	if err != nil || !(n > 0) || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("invalid rate %q: expected a positive number such as 5/s", s)
+	}
+	if hasUnit {
+		switch strings.TrimSpace(unit) {
+		case "s", "sec", "second":
+		case "m", "min", "minute":
+			n /= 60
+		case "h", "hour":
+			n /= 3600
+		default:
+			return 0, fmt.Errorf("invalid rate unit %q: use s, m, or h", unit)
+		}
+	}
+	if n > maxLoadtestRate {
+		return 0, fmt.Errorf("invalid rate %q: at most %d/s is supported", s, maxLoadtestRate)
+	}
+	return n, nil
+}
+
+// Invoke drives invocations at a fixed arrival rate for the configured
+// duration, then waits for in-flight invocations to finish and reports.
+// Arrivals that would exceed MaxInFlight are skipped rather than queued so
+// that a slow backend doesn't silently lower the offered load.
+func (l LoadtestCmd) Invoke(ctx context.Context, in LoadtestInvokeInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if !(in.Rate > 0) || in.Rate > maxLoadtestRate {
+		return fmt.Errorf("--rate must be positive and at most %d/s", maxLoadtestRate)
+	}
+	if in.Duration <= 0 {
+		return fmt.Errorf("--duration must be positive")
+	}
+	// Compared in seconds so very low rates can't overflow a Duration
+	if 1/in.Rate > in.Duration.Seconds() {
+		return fmt.Errorf("--rate %s/s starts no invocations within --duration %s", strconv.FormatFloat(in.Rate, 'f', -1, 64), in.Duration)
+	}
+	if in.MaxInFlight < 1 {
+		return fmt.Errorf("--max-in-flight must be at least 1")
+	}
+	if in.PollInterval <= 0 {
+		in.PollInterval = time.Second
+	}
+	jsonOutput := in.Output == "json"
+
+	params := kernel.InvocationNewParams{
+		AppName:    in.App,
+		ActionName: in.Action,
+		Version:    in.Version,
+		Async:      kernel.Opt(true),
+	}
+	if in.HasPayload {
+		params.Payload = kernel.Opt(in.Payload)
+	}
+
+	if !jsonOutput {
+		pterm.Info.Printf("Load testing \"%s\" (action: %s, version: %s) at %s/s for %s…\n", in.App, in.Action, in.Version, strconv.FormatFloat(in.Rate, 'f', -1, 64), in.Duration)
+	}
+
+	stats := &loadtestStats{}
+	startedAt := time.Now()
+	var wg sync.WaitGroup
+
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / in.Rate))
+	defer ticker.Stop()
+	deadline := time.NewTimer(in.Duration)
+	defer deadline.Stop()
+	var progress <-chan time.Time
+	if !jsonOutput && in.ProgressInterval > 0 {
+		pt := time.NewTicker(in.ProgressInterval)
+		defer pt.Stop()
+		progress = pt.C
+	}
+
+	fire := func() {
+		stats.mu.Lock()
+		if stats.inFlight >= in.MaxInFlight {
+			stats.skipped++
+			stats.mu.Unlock()
+			return
+		}
+		stats.sent++
+		stats.inFlight++
+		stats.mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			status, err := l.invokeOnce(ctx, params, in.PollInterval)
+			errMsg := ""
+			if err != nil {
+				errMsg = util.CleanedUpSdkError{Err: err}.Error()
+			}
+			stats.record(time.Since(start), status, errMsg)
+		}()
+	}
+
+	fire()
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-deadline.C:
+			break loop
+		case <-ticker.C:
+			fire()
+		case <-progress:
+			printLoadtestProgress(stats, time.Since(startedAt))
+		}
+	}
+
+	if !jsonOutput {
+		stats.mu.Lock()
+		pending := stats.inFlight
+		stats.mu.Unlock()
+		if pending > 0 {
+			pterm.Info.Printf("Waiting for %d in-flight invocation(s) to finish…\n", pending)
+		}
+	}
+	wg.Wait()
+
+	report := buildLoadtestReport(in, stats, startedAt, time.Now())
+	if in.ReportFile != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(in.ReportFile, append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("write report: %w", err)
+		}
+	}
+
+	if jsonOutput {
+		return util.PrintJSON(report)
+	}
+	printLoadtestReport(report)
+	if in.ReportFile != "" {
+		pterm.Success.Printf("Report written to %s\n", in.ReportFile)
+	}
+	return nil
+}
+
+// invokeOnce submits one invocation and polls it to a terminal state.
+func (l LoadtestCmd) invokeOnce(ctx context.Context, params kernel.InvocationNewParams, pollInterval time.Duration) (kernel.InvocationGetResponseStatus, error) {
+	resp, err := l.invocations.New(ctx, params, option.WithMaxRetries(0))
+	if err != nil {
+		return "", err
+	}
+	status := kernel.InvocationGetResponseStatus(resp.Status)
+	for status == kernel.InvocationGetResponseStatusQueued || status == kernel.InvocationGetResponseStatusRunning {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(pollInterval):
+		}
+		inv, err := l.invocations.Get(ctx, resp.ID)
+		if err != nil {
+			return "", err
+		}
+		status = inv.Status
+	}
+	return status, nil
+}
+
+func buildLoadtestReport(in LoadtestInvokeInput, stats *loadtestStats, startedAt, finishedAt time.Time) LoadtestReport {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	latency, histogram := summarizeLatencies(stats.latencies)
+	report := LoadtestReport{
+		App:           in.App,
+		Action:        in.Action,
+		Version:       in.Version,
+		RatePerSecond: in.Rate,
+		Duration:      in.Duration.String(),
+		StartedAt:     startedAt.UTC(),
+		FinishedAt:    finishedAt.UTC(),
+		Sent:          stats.sent,
+		Succeeded:     stats.succeeded,
+		Failed:        stats.failed,
+		Errored:       stats.errored,
+		Skipped:       stats.skipped,
+		Latency:       latency,
+		Histogram:     histogram,
+		Errors:        stats.errors,
+	}
+	if completed := stats.succeeded + stats.failed + stats.errored; completed > 0 {
+		report.ErrorRate = float64(stats.failed+stats.errored) / float64(completed)
+	}
+	if elapsed := finishedAt.Sub(startedAt).Seconds(); elapsed > 0 {
+		report.Throughput = float64(stats.succeeded) / elapsed
+	}
+	return report
+}
+
+func printLoadtestProgress(stats *loadtestStats, elapsed time.Duration) {
+	stats.mu.Lock()
+	latency, _ := summarizeLatencies(stats.latencies)
+	sent, inFlight, ok, failed, errored, skipped := stats.sent, stats.inFlight, stats.succeeded, stats.failed, stats.errored, stats.skipped
+	stats.mu.Unlock()
+
+	errRate := 0.0
+	if completed := ok + failed + errored; completed > 0 {
+		errRate = float64(failed+errored) / float64(completed) * 100
+	}
+	pterm.Info.Printf("[%s] sent=%d in-flight=%d ok=%d failed=%d errors=%d skipped=%d err-rate=%.1f%% p50=%dms p99=%dms\n",
+		elapsed.Round(time.Second), sent, inFlight, ok, failed, errored, skipped, errRate, latency.P50Ms, latency.P99Ms)
+}
+
+func printLoadtestReport(r LoadtestReport) {
+	pterm.DefaultSection.Println("Load test report")
+	rows := pterm.TableData{
+		{"Property", "Value"},
+		{"Target", fmt.Sprintf("%s/%s@%s", r.App, r.Action, r.Version)},
+		{"Rate", fmt.Sprintf("%s/s for %s", strconv.FormatFloat(r.RatePerSecond, 'f', -1, 64), r.Duration)},
+		{"Sent", strconv.Itoa(r.Sent)},
+		{"Succeeded", strconv.Itoa(r.Succeeded)},
+		{"Failed", strconv.Itoa(r.Failed)},
+		{"Errors", strconv.Itoa(r.Errored)},
+		{"Skipped (max in-flight)", strconv.Itoa(r.Skipped)},
+		{"Error rate", fmt.Sprintf("%.2f%%", r.ErrorRate*100)},
+		{"Throughput", fmt.Sprintf("%.2f/s", r.Throughput)},
+		{"Latency min/mean/max", fmt.Sprintf("%dms / %dms / %dms", r.Latency.MinMs, r.Latency.MeanMs, r.Latency.MaxMs)},
+		{"Latency p50/p90/p99", fmt.Sprintf("%dms / %dms / %dms", r.Latency.P50Ms, r.Latency.P90Ms, r.Latency.P99Ms)},
+	}
+	PrintTableNoPad(rows, true)
+
+	total := 0
+	for _, b := range r.Histogram {
+		total += b.Count
+	}
+	if total > 0 {
+		hist := pterm.TableData{{"Latency", "Count", ""}}
+		for _, b := range r.Histogram {
+			label := "> " + (time.Duration(r.Histogram[len(r.Histogram)-2].LeMs) * time.Millisecond).String()
+			if b.LeMs > 0 {
+				label = "≤ " + (time.Duration(b.LeMs) * time.Millisecond).String()
+			}
+			bar := strings.Repeat("█", int(math.Round(float64(b.Count)/float64(total)*40)))
+			hist = append(hist, []string{label, strconv.Itoa(b.Count), bar})
+		}
+		PrintTableNoPad(hist, true)
+	}
+
+	if len(r.Errors) > 0 {
+		errRows := pterm.TableData{{"Error", "Count"}}
+		msgs := make([]string, 0, len(r.Errors))
+		for msg := range r.Errors {
+			msgs = append(msgs, msg)
+		}
+		sort.Slice(msgs, func(i, j int) bool { return r.Errors[msgs[i]] > r.Errors[msgs[j]] })
+		for _, msg := range msgs {
+			errRows = append(errRows, []string{msg, strconv.Itoa(r.Errors[msg])})
+		}
+		PrintTableNoPad(errRows, true)
+	}
+}
+
+var loadtestCmd = &cobra.Command{
+	Use:   "loadtest",
+	Short: "Drive sustained load against deployed apps",
+	Long:  "Run soak and load tests against Kernel resources to validate capacity before launches.",
+}
+
+var loadtestInvokeCmd = &cobra.Command{
+	Use:   "invoke <app_name> <action_name>",
+	Short: "Invoke an action at a fixed rate and report latency and errors",
+	Long: `Invoke an action at a fixed arrival rate for a fixed duration.
+
+Each invocation is submitted asynchronously and polled until it finishes; its
+latency is measured from submission to completion. Progress is printed
+periodically, and a final report with latency percentiles, a histogram, and
+error counts is printed (and optionally written with --report).
+
+Press Ctrl+C to stop early; in-flight invocations are still reported.`,
+	Example: "  kernel loadtest invoke my-app my-action --rate 5/s --duration 10m --payload-file p.json --report report.json",
+	Args:    cobra.ExactArgs(2),
+	RunE:    runLoadtestInvoke,
+}
+
+func init() {
+	loadtestInvokeCmd.Flags().StringP("version", "v", "latest", "Version of the app to invoke")
+	loadtestInvokeCmd.Flags().StringP("payload", "p", "", "JSON payload for each invocation (supports template functions, see kernel invoke --help)")
+	loadtestInvokeCmd.Flags().StringP("payload-file", "f", "", "Path to a JSON file containing the payload (use '-' for stdin)")
+	addNoTemplateFlag(loadtestInvokeCmd, "the payload")
+	loadtestInvokeCmd.MarkFlagsMutuallyExclusive("payload", "payload-file")
+	loadtestInvokeCmd.Flags().String("rate", "1/s", "Invocation arrival rate, e.g. 5/s, 120/m, 1000/h")
+	loadtestInvokeCmd.Flags().Duration("duration", time.Minute, "How long to keep submitting invocations")
+	loadtestInvokeCmd.Flags().Int("max-in-flight", 100, "Maximum concurrent invocations; arrivals beyond this are skipped")
+	loadtestInvokeCmd.Flags().Duration("poll-interval", time.Second, "How often to poll running invocations for completion")
+	loadtestInvokeCmd.Flags().Duration("progress-interval", 10*time.Second, "How often to print live stats (0 to disable)")
+	loadtestInvokeCmd.Flags().String("report", "", "Write the final report as JSON to this file")
+	addJSONOutputFlag(loadtestInvokeCmd)
+	loadtestCmd.AddCommand(loadtestInvokeCmd)
+}
+
+func runLoadtestInvoke(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+
+	version, _ := cmd.Flags().GetString("version")
+	rateStr, _ := cmd.Flags().GetString("rate")
+	duration, _ := cmd.Flags().GetDuration("duration")
+	maxInFlight, _ := cmd.Flags().GetInt("max-in-flight")
+	pollInterval, _ := cmd.Flags().GetDuration("poll-interval")
+	progressInterval, _ := cmd.Flags().GetDuration("progress-interval")
+	reportFile, _ := cmd.Flags().GetString("report")
+	output, _ := cmd.Flags().GetString("output")
+
+	if version == "" {
+		return fmt.Errorf("version cannot be an empty string")
+	}
+	rate, err := parseLoadtestRate(rateStr)
+	if err != nil {
+		return err
+	}
+	payload, hasPayload, err := getPayload(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	svc := client.Invocations
+	l := LoadtestCmd{invocations: &svc}
+	return l.Invoke(ctx, LoadtestInvokeInput{
+		App:              args[0],
+		Action:           args[1],
+		Version:          version,
+		Payload:          payload,
+		HasPayload:       hasPayload,
+		Rate:             rate,
+		Duration:         duration,
+		MaxInFlight:      maxInFlight,
+		PollInterval:     pollInterval,
+		ProgressInterval: progressInterval,
+		ReportFile:       reportFile,
+		Output:           output,
+	})
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLoadtestInvocations struct {
+	newFunc func(ctx context.Context, body kernel.InvocationNewParams) (*kernel.InvocationNewResponse, error)
+	getFunc func(ctx context.Context, id string) (*kernel.InvocationGetResponse, error)
+}
+
+func (f *fakeLoadtestInvocations) New(ctx context.Context, body kernel.InvocationNewParams, opts ...option.RequestOption) (*kernel.InvocationNewResponse, error) {
+	return f.newFunc(ctx, body)
+}
+
+func (f *fakeLoadtestInvocations) Get(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.InvocationGetResponse, error) {
+	return f.getFunc(ctx, id)
+}
+
+func TestParseLoadtestRate(t *testing.T) {
+	for in, want := range map[string]float64{"5/s": 5, "120/m": 2, "3600/h": 1, "2.5": 2.5} {
+		got, err := parseLoadtestRate(in)
+		require.NoError(t, err, in)
+		assert.InDelta(t, want, got, 1e-9, in)
+	}
+	for _, in := range []string{"", "0/s", "-1/s", "5/d", "fast", "NaN", "1e10/s", "1001", "3600001/h"} {
+		_, err := parseLoadtestRate(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestSummarizeLatencies(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*10*time.Millisecond)
+	}
+	lat, hist := summarizeLatencies(latencies)
+	assert.Equal(t, int64(10), lat.MinMs)
+	assert.Equal(t, int64(500), lat.P50Ms)
+	assert.Equal(t, int64(900), lat.P90Ms)
+	assert.Equal(t, int64(990), lat.P99Ms)
+	assert.Equal(t, int64(1000), lat.MaxMs)
+	assert.Equal(t, 10, hist[0].Count) // <= 100ms
+	assert.Equal(t, 15, hist[1].Count) // <= 250ms
+	assert.Equal(t, int64(0), hist[len(hist)-1].LeMs)
+}
+
+func TestLoadtestInvoke_WritesReport(t *testing.T) {
+	setupStdoutCapture(t)
+
+	var calls atomic.Int32
+	fake := &fakeLoadtestInvocations{
+		newFunc: func(ctx context.Context, body kernel.InvocationNewParams) (*kernel.InvocationNewResponse, error) {
+			assert.Equal(t, "my-app", body.AppName)
+			assert.Equal(t, `{"k":1}`, body.Payload.Value)
+			switch calls.Add(1) % 3 {
+			case 0:
+				return nil, errors.New("boom")
+			case 1:
+				return &kernel.InvocationNewResponse{ID: "inv", Status: kernel.InvocationNewResponseStatusQueued}, nil
+			default:
+				return &kernel.InvocationNewResponse{ID: "inv-done", Status: kernel.InvocationNewResponseStatusFailed}, nil
+			}
+		},
+		getFunc: func(ctx context.Context, id string) (*kernel.InvocationGetResponse, error) {
+			return &kernel.InvocationGetResponse{ID: id, Status: kernel.InvocationGetResponseStatusSucceeded}, nil
+		},
+	}
+
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	l := LoadtestCmd{invocations: fake}
+	err := l.Invoke(context.Background(), LoadtestInvokeInput{
+		App:          "my-app",
+		Action:       "run",
+		Version:      "latest",
+		Payload:      `{"k":1}`,
+		HasPayload:   true,
+		Rate:         100,
+		Duration:     200 * time.Millisecond,
+		MaxInFlight:  50,
+		PollInterval: time.Millisecond,
+		ReportFile:   reportPath,
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+	var report LoadtestReport
+	require.NoError(t, json.Unmarshal(data, &report))
+
+	assert.Equal(t, int(calls.Load()), report.Sent)
+	assert.Equal(t, report.Sent, report.Succeeded+report.Failed+report.Errored)
+	assert.Positive(t, report.Succeeded)
+	assert.Positive(t, report.Failed)
+	assert.Positive(t, report.Errored)
+	assert.Equal(t, report.Errored, report.Errors["boom"])
+	assert.InDelta(t, float64(report.Failed+report.Errored)/float64(report.Sent), report.ErrorRate, 1e-9)
+	assert.Contains(t, outBuf.String(), "Load test report")
+}
+
+func TestLoadtestInvoke_SkipsBeyondMaxInFlight(t *testing.T) {
+	setupStdoutCapture(t)
+
+	release := make(chan struct{})
+	fake := &fakeLoadtestInvocations{
+		newFunc: func(ctx context.Context, body kernel.InvocationNewParams) (*kernel.InvocationNewResponse, error) {
+			<-release
+			return &kernel.InvocationNewResponse{ID: "inv", Status: kernel.InvocationNewResponseStatusSucceeded}, nil
+		},
+	}
+	time.AfterFunc(150*time.Millisecond, func() { close(release) })
+
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	l := LoadtestCmd{invocations: fake}
+	err := l.Invoke(context.Background(), LoadtestInvokeInput{
+		App: "a", Action: "b", Version: "latest",
+		Rate: 100, Duration: 100 * time.Millisecond, MaxInFlight: 1,
+		ReportFile: reportPath,
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+	var report LoadtestReport
+	require.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, 1, report.Sent)
+	assert.Equal(t, 1, report.Succeeded)
+	assert.Positive(t, report.Skipped)
+}
+
+func TestLoadtestInvoke_RejectsUnusableRates(t *testing.T) {
+	l := LoadtestCmd{invocations: &fakeLoadtestInvocations{}}
+	in := LoadtestInvokeInput{App: "a", Action: "b", Version: "latest", Duration: time.Minute, MaxInFlight: 1}
+
+	// A ticker interval that rounds to zero would panic
+	in.Rate = 1e10
+	assert.ErrorContains(t, l.Invoke(context.Background(), in), "at most 1000/s")
+
+	// So would one that overflows a Duration
+	in.Rate = 1e-300
+	assert.ErrorContains(t, l.Invoke(context.Background(), in), "starts no invocations within --duration 1m0s")
+}
@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/kernel/cli/pkg/util"
@@ -12,11 +16,16 @@ import (
 )
 
 var logsCmd = &cobra.Command{
-	Use:     "logs <app_name>",
+	Use:     "logs [app_name]",
 	Aliases: []string{"log"},
 	Short:   "Show logs for a Kernel application",
-	Args:    cobra.ExactArgs(1),
-	RunE:    runLogs,
+	Long: `Show logs for a Kernel application.
+
+With an app name, shows the logs of the app's deployment, or of one invocation
+with --invocation. With --app (or --action), shows the logs of the app's recent
+invocations together, each line prefixed with the invocation it came from.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runLogs,
 }
 
 func init() {
@@ -25,18 +34,68 @@ func init() {
 	logsCmd.Flags().String("since", "s", "How far back to retrieve logs. Supports duration formats: ns, us, ms, s, m, h (e.g., 5m, 2h, 1h30m). Note: 'd' for days is NOT supported - use hours instead. Can also specify timestamps: 2006-01-02 (day), 2006-01-02T15:04 (minute), 2006-01-02T15:04:05 (second), 2006-01-02T15:04:05.000 (ms). Maximum lookback is 167h (just under 7 days). Defaults to 5m if not following, 5s if following.")
 	logsCmd.Flags().Bool("with-timestamps", false, "Include timestamps in each log line")
 	logsCmd.Flags().StringP("invocation", "i", "", "Show logs for a specific invocation/run of the app. Accepts full ID or unambiguous prefix. If the invocation is still running, streaming respects --follow.")
+	logsCmd.Flags().String("app", "", "Show the logs of this app's recent invocations together, each line prefixed with its invocation")
+	logsCmd.Flags().String("action", "", "With --app, only show logs from invocations of this action")
+	logsCmd.Flags().Int("limit", appLogsDefaultLimit, "With --app, max recent invocations to show logs from")
+	logsCmd.MarkFlagsMutuallyExclusive("app", "invocation")
+	logsCmd.MarkFlagsMutuallyExclusive("action", "invocation")
+	addJSONOutputFlag(logsCmd)
 	rootCmd.AddCommand(logsCmd)
 }
 
 func runLogs(cmd *cobra.Command, args []string) error {
 	client := getKernelClient(cmd)
 
-	appName := args[0]
+	appFlag, _ := cmd.Flags().GetString("app")
+	action, _ := cmd.Flags().GetString("action")
 	version, _ := cmd.Flags().GetString("version")
 	follow, _ := cmd.Flags().GetBool("follow")
 	since, _ := cmd.Flags().GetString("since")
 	timestamps, _ := cmd.Flags().GetBool("with-timestamps")
 	invocationRef, _ := cmd.Flags().GetString("invocation")
+	output, _ := cmd.Flags().GetString("output")
+
+	var appName string
+	switch {
+	case len(args) == 1 && appFlag != "" && appFlag != args[0]:
+		return fmt.Errorf("pass the app name as an argument or with --app, not both")
+	case len(args) == 1:
+		appName = args[0]
+	case appFlag != "":
+		appName = appFlag
+	default:
+		return fmt.Errorf("requires an app name: kernel logs <app_name> or kernel logs --app <app_name>")
+	}
+
+	if appFlag != "" || action != "" {
+		if !cmd.Flags().Changed("since") {
+			since = ""
+		}
+		if !cmd.Flags().Changed("version") {
+			version = ""
+		}
+		limit, _ := cmd.Flags().GetInt("limit")
+		ctx := cmd.Context()
+		if follow {
+			var stop context.CancelFunc
+			ctx, stop = signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+		}
+		a := AppLogsCmd{invocations: &client.Invocations, follower: &client.Invocations}
+		return a.Run(ctx, AppLogsInput{
+			App:        appName,
+			Action:     action,
+			Version:    version,
+			Since:      since,
+			Limit:      limit,
+			Follow:     follow,
+			Timestamps: timestamps,
+			Output:     output,
+		})
+	}
+	if output != "" {
+		return fmt.Errorf("--output is only supported with --app or --action")
+	}
 	if version == "" {
 		version = "latest"
 	}
@@ -50,7 +109,9 @@ func runLogs(cmd *cobra.Command, args []string) error {
 
 	// If an invocation is specified, stream invocation-specific logs and return
 	if invocationRef != "" {
-		inv, err := client.Invocations.Get(cmd.Context(), invocationRef)
+		inv, err := withIDPrefix(cmd.Context(), "invocation", invocationRef, invocationIDLister(&client.Invocations), func(id string) (*kernel.InvocationGetResponse, error) {
+			return client.Invocations.Get(cmd.Context(), id)
+		})
 		if err != nil {
 			return fmt.Errorf("failed to get invocation: %w", err)
 		}
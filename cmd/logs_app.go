@@ -0,0 +1,343 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/kernel/kernel-go-sdk/packages/ssestream"
+	"github.com/pterm/pterm"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	appLogsDefaultLimit   = 20
+	appLogsConcurrency    = 5
+	appLogsIdleTimeout    = 3 * time.Second
+	appLogsPollInterval   = 5 * time.Second
+	appLogsDefaultSince   = "1h"
+	appLogsFollowSince    = "5m"
+	appLogsPrefixIDLength = 8
+)
+
+// InvocationFollower streams an invocation's events.
+type InvocationFollower interface {
+	FollowStreaming(ctx context.Context, id string, query kernel.InvocationFollowParams, opts ...option.RequestOption) *ssestream.Stream[kernel.InvocationFollowResponseUnion]
+}
+
+// AppLogsCmd shows the logs of an app's invocations together.
+type AppLogsCmd struct {
+	invocations  InvocationLister
+	follower     InvocationFollower
+	now          func() time.Time
+	idleTimeout  time.Duration
+	pollInterval time.Duration
+}
+
+type AppLogsInput struct {
+	App     string
+	Action  string
+	Version string
+	// Since is how far back to look for invocations; see util.ParseTimeOrAgo.
+	Since string
+	// Limit caps how many recent invocations the backlog reads logs from.
+	Limit      int
+	Follow     bool
+	Timestamps bool
+	Output     string
+}
+
+// appLogEntry is one line of aggregated output, and the JSONL record for it.
+type appLogEntry struct {
+	Event        string    `json:"event"`
+	InvocationID string    `json:"invocation_id"`
+	Action       string    `json:"action"`
+	Timestamp    time.Time `json:"timestamp"`
+	Message      string    `json:"message"`
+}
+
+var appLogsPrefixColors = []pterm.Color{pterm.FgCyan, pterm.FgYellow, pterm.FgGreen, pterm.FgMagenta, pterm.FgBlue, pterm.FgLightRed}
+
+// appLogsPrinter prints entries with a prefix naming their invocation, each
+// invocation in its own color.
+type appLogsPrinter struct {
+	mu         sync.Mutex
+	json       bool
+	timestamps bool
+	colors     map[string]pterm.Color
+}
+
+func (p *appLogsPrinter) print(e appLogEntry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.json {
+		_ = util.PrintJSONLine(e)
+		return
+	}
+	color, ok := p.colors[e.InvocationID]
+	if !ok {
+		color = appLogsPrefixColors[len(p.colors)%len(appLogsPrefixColors)]
+		p.colors[e.InvocationID] = color
+	}
+	id := e.InvocationID
+	if len(id) > appLogsPrefixIDLength {
+		id = id[:appLogsPrefixIDLength]
+	}
+	prefix := color.Sprintf("%s %s |", id, e.Action)
+	if p.timestamps {
+		prefix += " " + util.FormatLocal(e.Timestamp)
+	}
+	msg := strings.TrimSuffix(e.Message, "\n")
+	if e.Event == "error" {
+		msg = pterm.Red(msg)
+	}
+	fmt.Printf("%s %s\n", prefix, msg)
+}
+
+// Run prints the logs of the app's invocations that started since in.Since,
+// ordered by time. With Follow it then keeps streaming new lines from running
+// and newly started invocations until ctx is done.
+func (a AppLogsCmd) Run(ctx context.Context, in AppLogsInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if a.now == nil {
+		a.now = time.Now
+	}
+	if a.idleTimeout == 0 {
+		a.idleTimeout = appLogsIdleTimeout
+	}
+	if a.pollInterval == 0 {
+		a.pollInterval = appLogsPollInterval
+	}
+	if in.Limit <= 0 {
+		in.Limit = appLogsDefaultLimit
+	}
+	if in.Since == "" {
+		in.Since = appLogsDefaultSince
+		if in.Follow {
+			in.Since = appLogsFollowSince
+		}
+	}
+	cutoff := a.now()
+	since, ok := util.ParseTimeOrAgo(in.Since, cutoff)
+	if !ok {
+		return fmt.Errorf("invalid --since %q: use a duration like 2h or 7d, a date (2006-01-02) or an RFC-3339 timestamp", in.Since)
+	}
+
+	params := kernel.InvocationListParams{
+		AppName: kernel.Opt(in.App),
+		Since:   kernel.Opt(since.UTC().Format(time.RFC3339)),
+		Limit:   kernel.Opt(int64(in.Limit)),
+	}
+	if in.Action != "" {
+		params.ActionName = kernel.Opt(in.Action)
+	}
+	if in.Version != "" {
+		params.Version = kernel.Opt(in.Version)
+	}
+	page, err := a.invocations.List(ctx, params)
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	var invs []kernel.InvocationListResponse
+	if page != nil {
+		invs = page.Items
+	}
+
+	jsonOutput := in.Output == "json"
+	printer := &appLogsPrinter{json: jsonOutput, timestamps: in.Timestamps, colors: map[string]pterm.Color{}}
+	if !jsonOutput {
+		target := fmt.Sprintf("app \"%s\"", in.App)
+		if in.Action != "" {
+			target += fmt.Sprintf(" (action: %s)", in.Action)
+		}
+		pterm.Info.Printf("Showing logs from %d invocation(s) of %s since %s...\n", len(invs), target, util.FormatLocal(since))
+	}
+
+	backlog, err := a.backlog(ctx, invs, cutoff)
+	if err != nil {
+		return err
+	}
+	for _, e := range backlog {
+		printer.print(e)
+	}
+	if !in.Follow {
+		return nil
+	}
+
+	if !jsonOutput {
+		pterm.Info.Println("Following new logs. Press Ctrl+C to exit")
+	}
+	return a.follow(ctx, params, invs, cutoff, printer)
+}
+
+// backlog reads the logs each invocation wrote up to cutoff, a few
+// invocations at a time, and returns them ordered by time.
+func (a AppLogsCmd) backlog(ctx context.Context, invs []kernel.InvocationListResponse, cutoff time.Time) ([]appLogEntry, error) {
+	var (
+		mu      sync.Mutex
+		entries []appLogEntry
+	)
+	var g errgroup.Group
+	g.SetLimit(appLogsConcurrency)
+	for _, inv := range invs {
+		g.Go(func() error {
+			add := func(e appLogEntry) {
+				mu.Lock()
+				entries = append(entries, e)
+				mu.Unlock()
+			}
+			// One invocation's logs being unavailable shouldn't hide the rest.
+			err := a.stream(ctx, inv, kernel.InvocationFollowParams{}, false, func(e appLogEntry) {
+				if e.Event == "log" && e.Timestamp.After(cutoff) {
+					return
+				}
+				add(e)
+			})
+			if err != nil {
+				add(appLogEntry{Event: "error", InvocationID: inv.ID, Action: inv.ActionName, Timestamp: cutoff, Message: err.Error()})
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Timestamp.Before(entries[j].Timestamp) })
+	return entries, nil
+}
+
+// follow streams lines written after cutoff by invocations still running and
+// by any that start later, which it finds by polling, until ctx is done.
+func (a AppLogsCmd) follow(ctx context.Context, params kernel.InvocationListParams, invs []kernel.InvocationListResponse, cutoff time.Time, printer *appLogsPrinter) error {
+	followParams := kernel.InvocationFollowParams{Since: kernel.Opt(cutoff.UTC().Format(time.RFC3339Nano))}
+	seen := map[string]bool{}
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	start := func(inv kernel.InvocationListResponse) {
+		seen[inv.ID] = true
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := a.stream(ctx, inv, followParams, true, func(e appLogEntry) {
+				if e.Event == "log" && !e.Timestamp.After(cutoff) {
+					return
+				}
+				printer.print(e)
+			})
+			if err != nil && ctx.Err() == nil && !printer.json {
+				pterm.Warning.Printf("Stopped following invocation %s: %v\n", inv.ID, err)
+			}
+		}()
+	}
+	for _, inv := range invs {
+		seen[inv.ID] = true
+		if inv.Status == kernel.InvocationListResponseStatusQueued || inv.Status == kernel.InvocationListResponseStatusRunning {
+			start(inv)
+		}
+	}
+
+	// New invocations are looked for from the cutoff on, so the poll doesn't
+	// page back through the backlog.
+	params.Since = kernel.Opt(cutoff.UTC().Format(time.RFC3339))
+	params.Limit = kernel.Opt(int64(invocationHistoryPageSize))
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(a.pollInterval):
+		}
+		page, err := a.invocations.List(ctx, params)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if !printer.json {
+				pterm.Warning.Printf("Failed to check for new invocations: %v\n", util.CleanedUpSdkError{Err: err})
+			}
+			continue
+		}
+		if page == nil {
+			continue
+		}
+		// Oldest first, so prefixes are colored in the order invocations started.
+		for i := len(page.Items) - 1; i >= 0; i-- {
+			if inv := page.Items[i]; !seen[inv.ID] {
+				start(inv)
+			}
+		}
+	}
+}
+
+// stream reads an invocation's events until it finishes or the stream ends.
+// Without follow it also stops once the stream has been idle for
+// a.idleTimeout, as a running invocation's stream stays open.
+func (a AppLogsCmd) stream(ctx context.Context, inv kernel.InvocationListResponse, params kernel.InvocationFollowParams, follow bool, emit func(appLogEntry)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream := a.follower.FollowStreaming(ctx, inv.ID, params, option.WithMaxRetries(0))
+	defer stream.Close()
+
+	events := make(chan kernel.InvocationFollowResponseUnion)
+	go func() {
+		defer close(events)
+		for stream.Next() {
+			select {
+			case events <- stream.Current():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	idle := time.NewTimer(a.idleTimeout)
+	defer idle.Stop()
+	for {
+		var idleC <-chan time.Time
+		if !follow {
+			idleC = idle.C
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-idleC:
+			return nil
+		case ev, ok := <-events:
+			if !ok {
+				if err := stream.Err(); err != nil && ctx.Err() == nil {
+					return fmt.Errorf("failed to follow invocation %s: %w", inv.ID, err)
+				}
+				return nil
+			}
+			if !idle.Stop() {
+				select {
+				case <-idle.C:
+				default:
+				}
+			}
+			idle.Reset(a.idleTimeout)
+			switch ev.Event {
+			case "log":
+				log := ev.AsLog()
+				emit(appLogEntry{Event: "log", InvocationID: inv.ID, Action: inv.ActionName, Timestamp: log.Timestamp, Message: log.Message})
+			case "error":
+				errEv := ev.AsError()
+				emit(appLogEntry{Event: "error", InvocationID: inv.ID, Action: inv.ActionName, Timestamp: a.now(), Message: fmt.Sprintf("%s: %s", errEv.Error.Code, errEv.Error.Message)})
+				return nil
+			case "invocation_state":
+				status := ev.AsInvocationState().Invocation.Status
+				if status == string(kernel.InvocationGetResponseStatusSucceeded) || status == string(kernel.InvocationGetResponseStatusFailed) {
+					return nil
+				}
+			}
+		}
+	}
+}
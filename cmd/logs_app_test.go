@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/kernel/kernel-go-sdk/packages/ssestream"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeInvocationFollower struct {
+	events map[string][]string
+}
+
+func (f *fakeInvocationFollower) FollowStreaming(ctx context.Context, id string, query kernel.InvocationFollowParams, opts ...option.RequestOption) *ssestream.Stream[kernel.InvocationFollowResponseUnion] {
+	var data [][]byte
+	for _, e := range f.events[id] {
+		data = append(data, []byte(e))
+	}
+	return ssestream.NewStream[kernel.InvocationFollowResponseUnion](&testDecoder{data: data}, nil)
+}
+
+func appLogsFixture(queries *[]kernel.InvocationListParams) AppLogsCmd {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	return AppLogsCmd{
+		invocations: &fakeInvocationListerFunc{fn: func(query kernel.InvocationListParams) []kernel.InvocationListResponse {
+			*queries = append(*queries, query)
+			return []kernel.InvocationListResponse{
+				{ID: "inv_bbbbbbbbbb", ActionName: "scrape", Status: kernel.InvocationListResponseStatusSucceeded},
+				{ID: "inv_aaaaaaaaaa", ActionName: "scrape", Status: kernel.InvocationListResponseStatusFailed},
+			}
+		}},
+		follower: &fakeInvocationFollower{events: map[string][]string{
+			"inv_aaaaaaaaaa": {
+				`{"event":"log","message":"a1\n","timestamp":"2026-05-01T11:00:00Z"}`,
+				`{"event":"log","message":"a2","timestamp":"2026-05-01T11:00:02Z"}`,
+				`{"event":"invocation_state","invocation":{"status":"failed"}}`,
+				`{"event":"log","message":"after the end","timestamp":"2026-05-01T11:00:03Z"}`,
+			},
+			"inv_bbbbbbbbbb": {
+				`{"event":"log","message":"b1","timestamp":"2026-05-01T11:00:01Z"}`,
+				`{"event":"log","message":"too new","timestamp":"2026-05-01T12:00:01Z"}`,
+			},
+		}},
+		now: func() time.Time { return now },
+	}
+}
+
+func TestAppLogs_MergesInvocationsByTime(t *testing.T) {
+	var queries []kernel.InvocationListParams
+	a := appLogsFixture(&queries)
+
+	capturePtermOutput(t)
+	var err error
+	out := captureStdout(t, func() {
+		err = a.Run(context.Background(), AppLogsInput{App: "my-app", Action: "scrape", Since: "2h"})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "inv_aaaa scrape | a1\ninv_bbbb scrape | b1\ninv_aaaa scrape | a2\n", pterm.RemoveColorFromString(out))
+
+	require.Len(t, queries, 1)
+	assert.Equal(t, "my-app", queries[0].AppName.Value)
+	assert.Equal(t, "scrape", queries[0].ActionName.Value)
+	assert.Equal(t, "2026-05-01T10:00:00Z", queries[0].Since.Value)
+	assert.Equal(t, int64(appLogsDefaultLimit), queries[0].Limit.Value)
+}
+
+func TestAppLogs_JSONLines(t *testing.T) {
+	var queries []kernel.InvocationListParams
+	a := appLogsFixture(&queries)
+
+	var err error
+	out := captureStdout(t, func() {
+		err = a.Run(context.Background(), AppLogsInput{App: "my-app", Output: "json"})
+	})
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 3)
+	var first appLogEntry
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, appLogEntry{
+		Event:        "log",
+		InvocationID: "inv_aaaaaaaaaa",
+		Action:       "scrape",
+		Timestamp:    time.Date(2026, 5, 1, 11, 0, 0, 0, time.UTC),
+		Message:      "a1\n",
+	}, first)
+}
+
+func TestAppLogs_RejectsBadSince(t *testing.T) {
+	a := AppLogsCmd{}
+	err := a.Run(context.Background(), AppLogsInput{App: "my-app", Since: "yesterday"})
+	assert.EqualError(t, err, `invalid --since "yesterday": use a duration like 2h or 7d, a date (2006-01-02) or an RFC-3339 timestamp`)
+}
@@ -1,10 +1,16 @@
 package cmd
 
 import (
+	"os"
+
 	"github.com/kernel/cli/pkg/util"
+	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 )
 
+// clipboardWriter copies values for --copy; tests replace it.
+var clipboardWriter = util.CopyToClipboard
+
 func validateJSONOutput(output string) error {
 	return util.ValidateJSONOutput(output)
 }
@@ -12,3 +18,19 @@ func validateJSONOutput(output string) error {
 func addJSONOutputFlag(cmd *cobra.Command) {
 	util.AddJSONOutputFlag(cmd)
 }
+
+// copyOutput puts value on the clipboard for --copy. Failing to copy only
+// warns, since the value is printed anyway. Messages go to stderr so that
+// stdout still holds just the output, e.g. for url=$(kernel ... --copy).
+func copyOutput(what, value string) {
+	warn := *pterm.Warning.WithWriter(os.Stderr)
+	if value == "" {
+		warn.Printf("No %s to copy\n", what)
+		return
+	}
+	if err := clipboardWriter(value); err != nil {
+		warn.Printf("Could not copy the %s: %v\n", what, err)
+		return
+	}
+	pterm.Info.WithWriter(os.Stderr).Printf("Copied the %s to the clipboard\n", what)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -17,3 +18,22 @@ func TestValidateJSONOutput(t *testing.T) {
 	assert.Contains(t, err.Error(), `"yaml"`)
 	assert.Contains(t, err.Error(), "omit --output")
 }
+
+func TestCopyOutput(t *testing.T) {
+	var copied []string
+	old := clipboardWriter
+	t.Cleanup(func() { clipboardWriter = old })
+
+	clipboardWriter = func(s string) error {
+		copied = append(copied, s)
+		return nil
+	}
+	copyOutput("invocation ID", "inv_123")
+	assert.Equal(t, []string{"inv_123"}, copied)
+
+	// Nothing to copy and a failed copy only warn
+	copyOutput("live view URL", "")
+	assert.Len(t, copied, 1)
+	clipboardWriter = func(string) error { return errors.New("no clipboard") }
+	copyOutput("hosted URL", "https://example.com")
+}
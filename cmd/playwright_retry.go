@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+)
+
+const (
+	// readOnlyPlaywrightRetries is how many times the CLI's own read-only
+	// scripts (screenshots, bug reports, profile reads) are re-run after a
+	// transient error. User scripts only re-run with --retries, since running
+	// them again may repeat whatever side effects they already had.
+	readOnlyPlaywrightRetries = 2
+	playwrightMaxRetryDelay  = 4 * time.Second
+)
+
+const playwrightRetriesFlagUsage = "Re-run the script up to this many times if it fails because the page navigated mid-run. The whole script re-runs, so only use it with scripts that are safe to repeat"
+
+var playwrightRetryBaseDelay = 500 * time.Millisecond
+
+// transientPlaywrightErrors are lowercase fragments of script errors caused
+// by the page navigating or reloading while the script ran. Running the
+// script again once the new document is up usually works.
+var transientPlaywrightErrors = []string{
+	"execution context was destroyed",
+	"cannot find context with specified id",
+	"inspected target navigated or closed",
+}
+
+// retryablePlaywrightResult reports whether a failed script hit one of
+// transientPlaywrightErrors. API errors aren't considered: the SDK already
+// retries 429s, 5xx and connection failures.
+func retryablePlaywrightResult(res *kernel.BrowserPlaywrightExecuteResponse) bool {
+	if res == nil || res.Success {
+		return false
+	}
+	msg := strings.ToLower(res.Error + "\n" + res.Stderr)
+	for _, fragment := range transientPlaywrightErrors {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// executePlaywright runs a script like pw.Execute, re-running it up to
+// retries times with backoff while it fails with a transient error. The last
+// response is returned either way, so callers handle res.Success as before.
+func executePlaywright(ctx context.Context, pw BrowserPlaywrightService, id string, params kernel.BrowserPlaywrightExecuteParams, retries int) (*kernel.BrowserPlaywrightExecuteResponse, error) {
+	for attempt := 0; ; attempt++ {
+		res, err := pw.Execute(ctx, id, params)
+		if err != nil || attempt >= retries || !retryablePlaywrightResult(res) {
+			return res, err
+		}
+		delay := min(playwrightRetryBaseDelay<<attempt, playwrightMaxRetryDelay)
+		pterm.Debug.Printf("Playwright script failed (%s); retrying in %s\n", res.Error, delay)
+		select {
+		case <-ctx.Done():
+			return res, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
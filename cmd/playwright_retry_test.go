@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noPlaywrightRetryDelay(t *testing.T) {
+	saved := playwrightRetryBaseDelay
+	playwrightRetryBaseDelay = 0
+	t.Cleanup(func() { playwrightRetryBaseDelay = saved })
+}
+
+func TestExecutePlaywright_RetriesNavigationErrors(t *testing.T) {
+	noPlaywrightRetryDelay(t)
+	var n int
+	pw := &fakePlaywright{executeFunc: func(id string, body kernel.BrowserPlaywrightExecuteParams) (*kernel.BrowserPlaywrightExecuteResponse, error) {
+		n++
+		if n == 1 {
+			return &kernel.BrowserPlaywrightExecuteResponse{Error: "page.evaluate: Execution context was destroyed, most likely because of a navigation"}, nil
+		}
+		return &kernel.BrowserPlaywrightExecuteResponse{Success: true, Result: "ok"}, nil
+	}}
+
+	res, err := executePlaywright(context.Background(), pw, "sess-1", kernel.BrowserPlaywrightExecuteParams{Code: "return 1"}, readOnlyPlaywrightRetries)
+	require.NoError(t, err)
+	assert.True(t, res.Success)
+	assert.Len(t, pw.Calls(), 2)
+}
+
+func TestExecutePlaywright_GivesUpAfterRetries(t *testing.T) {
+	noPlaywrightRetryDelay(t)
+	pw := &fakePlaywright{executeFunc: func(id string, body kernel.BrowserPlaywrightExecuteParams) (*kernel.BrowserPlaywrightExecuteResponse, error) {
+		return &kernel.BrowserPlaywrightExecuteResponse{Error: "Cannot find context with specified id"}, nil
+	}}
+
+	res, err := executePlaywright(context.Background(), pw, "sess-1", kernel.BrowserPlaywrightExecuteParams{}, 2)
+	require.NoError(t, err)
+	assert.False(t, res.Success)
+	assert.Len(t, pw.Calls(), 3)
+}
+
+func TestExecutePlaywright_DoesNotRetryScriptErrors(t *testing.T) {
+	noPlaywrightRetryDelay(t)
+	pw := &fakePlaywright{executeFunc: func(id string, body kernel.BrowserPlaywrightExecuteParams) (*kernel.BrowserPlaywrightExecuteResponse, error) {
+		return &kernel.BrowserPlaywrightExecuteResponse{Error: "ReferenceError: foo is not defined"}, nil
+	}}
+
+	res, err := executePlaywright(context.Background(), pw, "sess-1", kernel.BrowserPlaywrightExecuteParams{}, readOnlyPlaywrightRetries)
+	require.NoError(t, err)
+	assert.False(t, res.Success)
+	assert.Len(t, pw.Calls(), 1)
+}
+
+func TestPlaywrightRetriesFlag_UserScriptsDontRetryByDefault(t *testing.T) {
+	for _, name := range []string{"execute", "run"} {
+		cmd, _, err := browsersCmd.Find([]string{"playwright", name})
+		require.NoError(t, err)
+		assert.Equal(t, "0", cmd.Flags().Lookup("retries").DefValue, name)
+	}
+}
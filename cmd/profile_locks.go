@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kernel/cli/pkg/lock"
+	"github.com/kernel/cli/pkg/util"
+	"github.com/pterm/pterm"
+)
+
+// defaultProfileLockTTL bounds automatic profile locks taken by commands that
+// hand a profile to a long-lived browser session.
+const defaultProfileLockTTL = 30 * time.Minute
+
+// profileLocker takes local advisory locks on profiles so that two automations
+// on this machine don't drive the same profile at once.
+type profileLocker struct {
+	profiles ProfilesService
+	store    *lock.Store
+	owner    string
+}
+
+// newProfileLocker returns a locker backed by the default lock store, or nil
+// if the store is unavailable (locking is best-effort in that case). Each
+// process is its own owner, so concurrent automations contend for profiles.
+func newProfileLocker(profiles ProfilesService) *profileLocker {
+	store, err := lock.DefaultStore()
+	if err != nil {
+		pterm.Debug.Printf("Profile locking disabled: %v\n", err)
+		return nil
+	}
+	return &profileLocker{profiles: profiles, store: store, owner: lock.ProcessOwner()}
+}
+
+// resource resolves a profile ID or name to its lock key. Profiles are keyed
+// by ID so that locking by name and by ID contend for the same lock; unknown
+// profiles (e.g. ones about to be created) fall back to the given name.
+func (l *profileLocker) resource(ctx context.Context, idOrName string) (string, error) {
+	if l.profiles != nil {
+		prof, err := l.profiles.Get(ctx, idOrName)
+		if err != nil && !util.IsNotFound(err) {
+			return "", util.CleanedUpSdkError{Err: err}
+		}
+		if err == nil && prof != nil && prof.ID != "" {
+			return lock.ProfileResource(prof.ID), nil
+		}
+	}
+	return lock.ProfileResource(idOrName), nil
+}
+
+// Acquire locks a profile, failing fast with the current holder when another
+// owner has it. A lock the user took with "kernel profiles lock" doesn't
+// block their own processes: Acquire then returns a nil lock and leaves the
+// user's lock in place.
+func (l *profileLocker) Acquire(ctx context.Context, idOrName string, opts lock.Options) (*lock.Lock, error) {
+	resource, err := l.resource(ctx, idOrName)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Owner == "" {
+		opts.Owner = l.owner
+	}
+	held, err := l.store.Acquire(resource, opts)
+	if err != nil {
+		var heldErr *lock.HeldError
+		if errors.As(err, &heldErr) && lock.IsProcessOwner(opts.Owner, heldErr.Lock.Owner) {
+			pterm.Debug.Printf("Profile '%s' is locked by %s; using it under that lock\n", idOrName, heldErr.Lock.Owner)
+			return nil, nil
+		}
+		if errors.As(err, &heldErr) {
+			return nil, fmt.Errorf("profile '%s' is in use: %w (run 'kernel profiles unlock %s --force' to override)", idOrName, err, idOrName)
+		}
+		return nil, fmt.Errorf("lock profile: %w", err)
+	}
+	return held, nil
+}
+
+// Release drops a lock taken by this owner.
+func (l *profileLocker) Release(held *lock.Lock) {
+	if held == nil {
+		return
+	}
+	if err := l.store.Release(held.Resource, held.Owner, false); err != nil {
+		pterm.Debug.Printf("Failed to release %s: %v\n", held.Resource, err)
+	}
+}
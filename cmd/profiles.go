@@ -10,7 +10,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/kernel/cli/pkg/lock"
 	"github.com/kernel/cli/pkg/util"
 	"github.com/kernel/kernel-go-sdk"
 	"github.com/kernel/kernel-go-sdk/option"
@@ -27,6 +29,7 @@ type ProfilesService interface {
 	List(ctx context.Context, query kernel.ProfileListParams, opts ...option.RequestOption) (res *pagination.OffsetPagination[kernel.Profile], err error)
 	Delete(ctx context.Context, idOrName string, opts ...option.RequestOption) (err error)
 	New(ctx context.Context, body kernel.ProfileNewParams, opts ...option.RequestOption) (res *kernel.Profile, err error)
+	Update(ctx context.Context, idOrName string, body kernel.ProfileUpdateParams, opts ...option.RequestOption) (res *kernel.Profile, err error)
 	Download(ctx context.Context, idOrName string, opts ...option.RequestOption) (res *http.Response, err error)
 }
 
@@ -47,6 +50,12 @@ type ProfilesCreateInput struct {
 	Output string
 }
 
+type ProfilesUpdateInput struct {
+	Identifier string
+	Name       string
+	Output     string
+}
+
 type ProfilesDeleteInput struct {
 	Identifier  string
 	SkipConfirm bool
@@ -57,9 +66,26 @@ type ProfilesDownloadInput struct {
 	To         string
 }
 
+type ProfilesLockInput struct {
+	Identifier string
+	TTL        time.Duration
+	Owner      string
+	Output     string
+}
+
+type ProfilesUnlockInput struct {
+	Identifier string
+	Owner      string
+	Force      bool
+}
+
 // ProfilesCmd handles profile operations independent of cobra.
 type ProfilesCmd struct {
 	profiles ProfilesService
+	locks    *lock.Store
+	// browsers and playwright are optional; only diff needs them.
+	browsers   BrowsersService
+	playwright BrowserPlaywrightService
 }
 
 func (p ProfilesCmd) List(ctx context.Context, in ProfilesListInput) error {
@@ -105,11 +131,11 @@ func (p ProfilesCmd) List(ctx context.Context, in ProfilesListInput) error {
 
 	if in.Output == "json" {
 		if len(items) == 0 {
-			fmt.Println("[]")
-			return nil
+			return util.PrintJSON([]any{})
 		}
 		return util.PrintPrettyJSONSlice(items)
 	}
+	util.WarnDecodeDrift(items)
 
 	if len(items) == 0 {
 		pterm.Info.Println("No profiles found")
@@ -155,8 +181,7 @@ func (p ProfilesCmd) Get(ctx context.Context, in ProfilesGetInput) error {
 	}
 	if item == nil || item.ID == "" {
 		if in.Output == "json" {
-			fmt.Println("null")
-			return nil
+			return util.PrintJSON(nil)
 		}
 		pterm.Error.Printf("Profile '%s' not found\n", in.Identifier)
 		return nil
@@ -165,6 +190,7 @@ func (p ProfilesCmd) Get(ctx context.Context, in ProfilesGetInput) error {
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(item)
 	}
+	util.WarnDecodeDrift(item)
 
 	name := item.Name
 	if name == "" {
@@ -197,6 +223,7 @@ func (p ProfilesCmd) Create(ctx context.Context, in ProfilesCreateInput) error {
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(item)
 	}
+	util.WarnDecodeDrift(item)
 
 	name := item.Name
 	if name == "" {
@@ -211,6 +238,35 @@ func (p ProfilesCmd) Create(ctx context.Context, in ProfilesCreateInput) error {
 	return nil
 }
 
+// Update renames a profile. Browsers, pools and auth connections refer to
+// profiles by ID as well as name, so only name-based references need updating.
+func (p ProfilesCmd) Update(ctx context.Context, in ProfilesUpdateInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if in.Name == "" {
+		return fmt.Errorf("--name is required")
+	}
+
+	item, err := p.profiles.Update(ctx, in.Identifier, kernel.ProfileUpdateParams{Name: in.Name})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+
+	if in.Output == "json" {
+		return util.PrintPrettyJSON(item)
+	}
+	util.WarnDecodeDrift(item)
+
+	pterm.Success.Printf("Renamed profile '%s' to '%s'\n", in.Identifier, item.Name)
+	rows := pterm.TableData{{"Property", "Value"}}
+	rows = append(rows, []string{"ID", item.ID})
+	rows = append(rows, []string{"Name", util.OrDash(item.Name)})
+	rows = append(rows, []string{"Updated At", util.FormatLocal(item.UpdatedAt)})
+	PrintTableNoPad(rows, true)
+	return nil
+}
+
 func (p ProfilesCmd) Delete(ctx context.Context, in ProfilesDeleteInput) error {
 	// Resolve using Get first; treat not found as success with a message
 	item, err := p.profiles.Get(ctx, in.Identifier)
@@ -277,6 +333,73 @@ func (p ProfilesCmd) Download(ctx context.Context, in ProfilesDownloadInput) err
 	return nil
 }
 
+func (p ProfilesCmd) Lock(ctx context.Context, in ProfilesLockInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if in.TTL <= 0 {
+		return fmt.Errorf("--ttl must be positive")
+	}
+	owner := in.Owner
+	if owner == "" {
+		owner = lock.DefaultOwner()
+	}
+
+	locker := profileLocker{profiles: p.profiles, store: p.locks, owner: owner}
+	held, err := locker.Acquire(ctx, in.Identifier, lock.Options{TTL: in.TTL, Note: "kernel profiles lock"})
+	if err != nil {
+		return err
+	}
+
+	if in.Output == "json" {
+		return util.PrintJSON(held)
+	}
+
+	pterm.Success.Printf("Locked profile '%s' until %s\n", in.Identifier, util.FormatLocal(held.ExpiresAt))
+	rows := pterm.TableData{{"Property", "Value"}}
+	rows = append(rows, []string{"Resource", held.Resource})
+	rows = append(rows, []string{"Owner", held.Owner})
+	rows = append(rows, []string{"Acquired At", util.FormatLocal(held.AcquiredAt)})
+	rows = append(rows, []string{"Expires At", util.FormatLocal(held.ExpiresAt)})
+	PrintTableNoPad(rows, true)
+	return nil
+}
+
+func (p ProfilesCmd) Unlock(ctx context.Context, in ProfilesUnlockInput) error {
+	owner := in.Owner
+	if owner == "" {
+		owner = lock.DefaultOwner()
+	}
+	locker := profileLocker{profiles: p.profiles, store: p.locks, owner: owner}
+	resource, err := locker.resource(ctx, in.Identifier)
+	if err != nil {
+		return err
+	}
+
+	existing, err := p.locks.Get(resource)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		pterm.Info.Printf("Profile '%s' is not locked\n", in.Identifier)
+		return nil
+	}
+	// Locks taken automatically by the user's own commands are theirs to
+	// release without --force.
+	if lock.IsProcessOwner(existing.Owner, owner) {
+		owner = existing.Owner
+	}
+	if err := p.locks.Release(resource, owner, in.Force); err != nil {
+		var held *lock.HeldError
+		if errors.As(err, &held) {
+			return fmt.Errorf("%w; use --force to release another owner's lock", err)
+		}
+		return err
+	}
+	pterm.Success.Printf("Unlocked profile '%s'\n", in.Identifier)
+	return nil
+}
+
 // extractProfileArchive streams a zstd-compressed tar archive into destDir.
 // Files and directories are created relative to destDir; symlinks and other
 // special entry types are skipped. Path-traversal entries are rejected.
@@ -366,6 +489,14 @@ var profilesCreateCmd = &cobra.Command{
 	RunE:  runProfilesCreate,
 }
 
+var profilesUpdateCmd = &cobra.Command{
+	Use:   "update <id-or-name>",
+	Short: "Rename a profile",
+	Long:  "Rename a profile. Anything that refers to the profile by its old name (scripts, --profile-name flags) must be updated; references by ID keep working.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfilesUpdate,
+}
+
 var profilesDeleteCmd = &cobra.Command{
 	Use:   "delete <id-or-name>",
 	Short: "Delete a profile by ID or name",
@@ -381,12 +512,43 @@ var profilesDownloadCmd = &cobra.Command{
 	RunE:  runProfilesDownload,
 }
 
+var profilesLockCmd = &cobra.Command{
+	Use:   "lock <id-or-name>",
+	Short: "Lock a profile to prevent concurrent use",
+	Long: `Take a local advisory lock on a profile. While the lock is held, "kernel browsers create"
+and "kernel auth connections login" fail fast for other owners instead of letting two automations
+modify the same profile at once.
+
+Locks are stored under ~/.config/kernel/locks and only coordinate processes on this machine.
+The owner defaults to user@host. Commands that lock profiles automatically use a separate
+owner per process, so two of them contend even when run by the same user; give them the same
+KERNEL_LOCK_OWNER to let them share a lock.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProfilesLock,
+}
+
+var profilesUnlockCmd = &cobra.Command{
+	Use:   "unlock <id-or-name>",
+	Short: "Release a profile lock",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfilesUnlock,
+}
+
 func init() {
 	profilesCmd.AddCommand(profilesListCmd)
 	profilesCmd.AddCommand(profilesGetCmd)
 	profilesCmd.AddCommand(profilesCreateCmd)
+	profilesCmd.AddCommand(profilesUpdateCmd)
 	profilesCmd.AddCommand(profilesDeleteCmd)
 	profilesCmd.AddCommand(profilesDownloadCmd)
+	profilesCmd.AddCommand(profilesLockCmd)
+	profilesCmd.AddCommand(profilesUnlockCmd)
+	profilesCmd.AddCommand(profilesDiffCmd)
+
+	for _, c := range []*cobra.Command{profilesGetCmd, profilesUpdateCmd, profilesDeleteCmd, profilesDownloadCmd, profilesLockCmd, profilesUnlockCmd} {
+		c.ValidArgsFunction = completeResourceArg("profile", completeProfile)
+	}
+	profilesDiffCmd.ValidArgsFunction = completeResourceArgs("profile", completeProfile)
 
 	addJSONOutputFlag(profilesListCmd)
 	profilesListCmd.Flags().Int("per-page", 20, "Items per page (default 20)")
@@ -395,9 +557,19 @@ func init() {
 	addJSONOutputFlag(profilesGetCmd)
 	addJSONOutputFlag(profilesCreateCmd)
 	profilesCreateCmd.Flags().String("name", "", "Optional unique profile name")
+	addJSONOutputFlag(profilesUpdateCmd)
+	profilesUpdateCmd.Flags().String("name", "", "New unique profile name (required)")
+	_ = profilesUpdateCmd.MarkFlagRequired("name")
 	profilesDeleteCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
 	profilesDownloadCmd.Flags().String("to", "", "Directory to extract the profile into (required)")
 	_ = profilesDownloadCmd.MarkFlagRequired("to")
+	addJSONOutputFlag(profilesLockCmd)
+	profilesLockCmd.Flags().Duration("ttl", defaultProfileLockTTL, "How long to hold the lock")
+	profilesLockCmd.Flags().String("owner", "", "Lock owner (default $KERNEL_LOCK_OWNER or user@host)")
+	profilesUnlockCmd.Flags().String("owner", "", "Lock owner (default $KERNEL_LOCK_OWNER or user@host)")
+	profilesUnlockCmd.Flags().Bool("force", false, "Release the lock even if another owner holds it")
+	addJSONOutputFlag(profilesDiffCmd)
+	profilesDiffCmd.Flags().String("domain", "", "Only compare cookies for this domain and its subdomains")
 }
 
 func runProfilesList(cmd *cobra.Command, args []string) error {
@@ -434,6 +606,15 @@ func runProfilesCreate(cmd *cobra.Command, args []string) error {
 	return p.Create(cmd.Context(), ProfilesCreateInput{Name: name, Output: output})
 }
 
+func runProfilesUpdate(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	name, _ := cmd.Flags().GetString("name")
+	output, _ := cmd.Flags().GetString("output")
+	svc := client.Profiles
+	p := ProfilesCmd{profiles: &svc}
+	return p.Update(cmd.Context(), ProfilesUpdateInput{Identifier: args[0], Name: name, Output: output})
+}
+
 func runProfilesDelete(cmd *cobra.Command, args []string) error {
 	client := getKernelClient(cmd)
 	skip, _ := cmd.Flags().GetBool("yes")
@@ -449,3 +630,30 @@ func runProfilesDownload(cmd *cobra.Command, args []string) error {
 	p := ProfilesCmd{profiles: &svc}
 	return p.Download(cmd.Context(), ProfilesDownloadInput{Identifier: args[0], To: to})
 }
+
+func runProfilesLock(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	ttl, _ := cmd.Flags().GetDuration("ttl")
+	owner, _ := cmd.Flags().GetString("owner")
+	output, _ := cmd.Flags().GetString("output")
+	store, err := lock.DefaultStore()
+	if err != nil {
+		return err
+	}
+	svc := client.Profiles
+	p := ProfilesCmd{profiles: &svc, locks: store}
+	return p.Lock(cmd.Context(), ProfilesLockInput{Identifier: args[0], TTL: ttl, Owner: owner, Output: output})
+}
+
+func runProfilesUnlock(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	owner, _ := cmd.Flags().GetString("owner")
+	force, _ := cmd.Flags().GetBool("force")
+	store, err := lock.DefaultStore()
+	if err != nil {
+		return err
+	}
+	svc := client.Profiles
+	p := ProfilesCmd{profiles: &svc, locks: store}
+	return p.Unlock(cmd.Context(), ProfilesUnlockInput{Identifier: args[0], Owner: owner, Force: force})
+}
@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// profileCookiesWithValuesScript reads every cookie, values included, so
+// they can be replayed into another profile. The values only travel between
+// the two browsers; they are never printed.
+const profileCookiesWithValuesScript = `return await context.cookies();`
+
+type ProfilesCloneInput struct {
+	Source string
+	Dest   string
+	// Wait blocks until the cloned profile's data has been saved.
+	Wait         bool
+	WaitTimeout  time.Duration
+	PollInterval time.Duration
+	Output       string
+}
+
+// Clone creates Dest and copies Source's cookies into it, which carries over
+// the logged-in state of most sites. There is no server-side copy, so the
+// cookies are read in a headless browser on Source and written in one on
+// Dest that saves back when it is deleted. Local storage and IndexedDB are
+// not copied.
+func (p ProfilesCmd) Clone(ctx context.Context, in ProfilesCloneInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if p.browsers == nil || p.playwright == nil {
+		return fmt.Errorf("browser services not available for profile clone")
+	}
+	if in.WaitTimeout <= 0 {
+		in.WaitTimeout = 5 * time.Minute
+	}
+	if in.PollInterval <= 0 {
+		in.PollInterval = 2 * time.Second
+	}
+	jsonOutput := in.Output == "json"
+	const steps = 4
+	progress := func(n int, format string, args ...any) {
+		if !jsonOutput {
+			pterm.Info.Printf("[%d/%d] %s\n", n, steps, fmt.Sprintf(format, args...))
+		}
+	}
+
+	src, err := p.profiles.Get(ctx, in.Source)
+	if err != nil {
+		return fmt.Errorf("profile %s: %w", in.Source, util.CleanedUpSdkError{Err: err})
+	}
+	if existing, err := p.profiles.Get(ctx, in.Dest); err == nil && existing != nil && existing.ID != "" {
+		return fmt.Errorf("profile %s already exists", in.Dest)
+	} else if err != nil && !util.IsNotFound(err) {
+		return util.CleanedUpSdkError{Err: err}
+	}
+
+	progress(1, "Reading cookies from %s", in.Source)
+	cookies, err := p.readProfileCookies(ctx, src.ID)
+	if err != nil {
+		return fmt.Errorf("profile %s: %w", in.Source, err)
+	}
+	if len(cookies) == 0 && !jsonOutput {
+		pterm.Warning.Printf("Profile %s has no cookies; the clone will start empty\n", in.Source)
+	}
+
+	progress(2, "Creating profile %s", in.Dest)
+	dst, err := p.profiles.New(ctx, kernel.ProfileNewParams{Name: kernel.Opt(in.Dest)})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+
+	progress(3, "Writing %d cookies into %s", len(cookies), in.Dest)
+	if err := p.writeProfileCookies(ctx, dst.ID, cookies); err != nil {
+		// Don't leave a half-made clone behind under the requested name.
+		if derr := p.profiles.Delete(context.WithoutCancel(ctx), dst.ID); derr != nil && !jsonOutput {
+			pterm.Warning.Printf("Failed to delete incomplete profile %s: %v\n", in.Dest, util.CleanedUpSdkError{Err: derr})
+		}
+		return fmt.Errorf("profile %s: %w", in.Dest, err)
+	}
+
+	if in.Wait {
+		progress(4, "Waiting for %s to be saved", in.Dest)
+		if err := p.waitForProfileData(ctx, dst.ID, in.WaitTimeout, in.PollInterval); err != nil {
+			return err
+		}
+	} else {
+		progress(4, "Saving %s in the background (use --wait to block until it is ready)", in.Dest)
+	}
+
+	if jsonOutput {
+		return util.PrintJSON(map[string]any{"source": src, "profile": dst, "cookies": len(cookies), "saved": in.Wait})
+	}
+	pterm.Success.Printf("Cloned %s to %s (%d cookies)\n", in.Source, in.Dest, len(cookies))
+	PrintTableNoPad(pterm.TableData{
+		{"Property", "Value"},
+		{"ID", dst.ID},
+		{"Name", dst.Name},
+		{"Cloned From", src.ID},
+	}, true)
+	return nil
+}
+
+// readProfileCookies loads a profile into a headless browser that does not
+// save back and returns its cookies as Playwright reports them.
+func (p ProfilesCmd) readProfileCookies(ctx context.Context, profileID string) ([]json.RawMessage, error) {
+	var raw []byte
+	err := p.withProfileBrowser(ctx, profileID, false, func(sessionID string) error {
+		res, err := executePlaywright(ctx, p.playwright, sessionID, kernel.BrowserPlaywrightExecuteParams{Code: profileCookiesWithValuesScript}, readOnlyPlaywrightRetries)
+		if err != nil {
+			return util.CleanedUpSdkError{Err: err}
+		}
+		if !res.Success {
+			return fmt.Errorf("reading cookies failed: %s", util.FirstOrDash(res.Error, res.Stderr))
+		}
+		raw, err = json.Marshal(res.Result)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	var cookies []json.RawMessage
+	if err := json.Unmarshal(raw, &cookies); err != nil {
+		return nil, fmt.Errorf("unexpected cookie list: %w", err)
+	}
+	return cookies, nil
+}
+
+// writeProfileCookies adds cookies in a browser on the profile that saves
+// its changes when it is deleted.
+func (p ProfilesCmd) writeProfileCookies(ctx context.Context, profileID string, cookies []json.RawMessage) error {
+	if cookies == nil {
+		cookies = []json.RawMessage{}
+	}
+	list, err := json.Marshal(cookies)
+	if err != nil {
+		return err
+	}
+	return p.withProfileBrowser(ctx, profileID, true, func(sessionID string) error {
+		code := fmt.Sprintf("await context.addCookies(%s);\nreturn (await context.cookies()).length;", list)
+		res, err := executePlaywright(ctx, p.playwright, sessionID, kernel.BrowserPlaywrightExecuteParams{Code: code}, readOnlyPlaywrightRetries)
+		if err != nil {
+			return util.CleanedUpSdkError{Err: err}
+		}
+		if !res.Success {
+			return fmt.Errorf("writing cookies failed: %s", util.FirstOrDash(res.Error, res.Stderr))
+		}
+		return nil
+	})
+}
+
+// withProfileBrowser runs fn against a temporary headless browser on a
+// profile and deletes the browser afterwards, which is when a saving
+// browser writes the profile back.
+func (p ProfilesCmd) withProfileBrowser(ctx context.Context, profileID string, save bool, fn func(sessionID string) error) error {
+	br, err := p.browsers.New(ctx, kernel.BrowserNewParams{
+		Headless: kernel.Opt(true),
+		Profile:  kernel.BrowserProfileParam{ID: kernel.Opt(profileID), SaveChanges: kernel.Opt(save)},
+	})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	fnErr := fn(br.SessionID)
+	if err := p.browsers.DeleteByID(context.WithoutCancel(ctx), br.SessionID); err != nil && !util.IsNotFound(err) {
+		if save && fnErr == nil {
+			return fmt.Errorf("delete browser %s to save the profile: %w", br.SessionID, util.CleanedUpSdkError{Err: err})
+		}
+		pterm.Warning.Printf("Failed to delete temporary browser %s: %v\n", br.SessionID, util.CleanedUpSdkError{Err: err})
+	}
+	return fnErr
+}
+
+// waitForProfileData polls until the profile has saved data to download.
+func (p ProfilesCmd) waitForProfileData(ctx context.Context, profileID string, timeout, interval time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		res, err := p.profiles.Download(ctx, profileID)
+		if err == nil {
+			// Only the status matters; don't download the archive.
+			res.Body.Close()
+			if res.StatusCode == http.StatusOK {
+				return nil
+			}
+		} else if ctx.Err() == nil {
+			return util.CleanedUpSdkError{Err: err}
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out after %s waiting for profile %s to be saved", timeout, profileID)
+		case <-time.After(interval):
+		}
+	}
+}
+
+var profilesCloneCmd = &cobra.Command{
+	Use:   "clone <source> <new-name>",
+	Short: "Copy a profile's cookies into a new profile",
+	Long: `Create a new profile with the source profile's cookies, carrying over the
+logged-in state of most sites, e.g. to give a second bot its own copy of an
+authenticated profile.
+
+The cookies are read in a temporary headless browser on the source profile and
+written in one on the new profile, which saves when it is deleted. Local
+storage and IndexedDB are not copied. With --wait the command returns once the
+new profile's data has been saved.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runProfilesClone,
+}
+
+var profilesRenameCmd = &cobra.Command{
+	Use:   "rename <id-or-name> <new-name>",
+	Short: "Rename a profile",
+	Long:  "Rename a profile; the same as 'kernel profiles update <id-or-name> --name <new-name>'.",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runProfilesRename,
+}
+
+func init() {
+	profilesCmd.AddCommand(profilesCloneCmd)
+	profilesCmd.AddCommand(profilesRenameCmd)
+	profilesCloneCmd.ValidArgsFunction = completeResourceArg("profile", completeProfile)
+	profilesRenameCmd.ValidArgsFunction = completeResourceArg("profile", completeProfile)
+
+	profilesCloneCmd.Flags().Bool("wait", false, "Wait until the new profile's data has been saved")
+	profilesCloneCmd.Flags().Duration("wait-timeout", 5*time.Minute, "How long --wait waits")
+	addJSONOutputFlag(profilesCloneCmd)
+	addJSONOutputFlag(profilesRenameCmd)
+}
+
+func runProfilesClone(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	wait, _ := cmd.Flags().GetBool("wait")
+	waitTimeout, _ := cmd.Flags().GetDuration("wait-timeout")
+	output, _ := cmd.Flags().GetString("output")
+
+	svc := client.Profiles
+	p := ProfilesCmd{profiles: &svc, browsers: &client.Browsers, playwright: &client.Browsers.Playwright}
+	return p.Clone(cmd.Context(), ProfilesCloneInput{
+		Source:      args[0],
+		Dest:        args[1],
+		Wait:        wait,
+		WaitTimeout: waitTimeout,
+		Output:      output,
+	})
+}
+
+func runProfilesRename(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	output, _ := cmd.Flags().GetString("output")
+	svc := client.Profiles
+	p := ProfilesCmd{profiles: &svc}
+	return p.Update(cmd.Context(), ProfilesUpdateInput{Identifier: args[0], Name: args[1], Output: output})
+}
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+// profileCookiesScript lists a context's cookies without their values, so
+// secrets never leave the browser.
+const profileCookiesScript = `return (await context.cookies()).map(c => ({
+  name: c.name, domain: c.domain, path: c.path, expires: c.expires, secure: c.secure, httpOnly: c.httpOnly,
+}));`
+
+// sessionCookieName matches cookie names that usually carry a login session.
+var sessionCookieName = regexp.MustCompile(`(?i)sess|sid|auth|token|login|jwt`)
+
+type ProfilesDiffInput struct {
+	A      string
+	B      string
+	Domain string
+	Output string
+}
+
+type profileCookie struct {
+	Name   string `json:"name"`
+	Domain string `json:"domain"`
+	Path   string `json:"path"`
+	// Expires is a Unix timestamp, or -1 for cookies that end with the browser session.
+	Expires  float64 `json:"expires"`
+	Secure   bool    `json:"secure"`
+	HTTPOnly bool    `json:"httpOnly"`
+}
+
+// profileCookieDiff is one cookie's presence and expiry in each profile.
+type profileCookieDiff struct {
+	Domain string `json:"domain"`
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	// Status is "only_in_a", "only_in_b", "expiry_differs" or "same".
+	Status string         `json:"status"`
+	A      *profileCookie `json:"a,omitempty"`
+	B      *profileCookie `json:"b,omitempty"`
+	// Session marks cookies that look like they hold a login session: either
+	// the name suggests it or the cookie expires with the browser session.
+	Session bool `json:"session"`
+}
+
+// Diff compares the cookies two profiles carry by loading each into a
+// short-lived headless browser that does not save changes back.
+func (p ProfilesCmd) Diff(ctx context.Context, in ProfilesDiffInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if p.browsers == nil || p.playwright == nil {
+		return fmt.Errorf("browser services not available for profile diff")
+	}
+
+	if in.Output != "json" {
+		pterm.Info.Println("Loading each profile in a temporary browser...")
+	}
+	var a, b []profileCookie
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() (err error) { a, err = p.profileCookies(gctx, in.A); return err })
+	g.Go(func() (err error) { b, err = p.profileCookies(gctx, in.B); return err })
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	diffs := diffProfileCookies(filterCookiesByDomain(a, in.Domain), filterCookiesByDomain(b, in.Domain))
+	if in.Output == "json" {
+		return util.PrintJSON(map[string]any{"a": in.A, "b": in.B, "cookies": diffs})
+	}
+	printProfileCookieDiff(in.A, in.B, diffs)
+	return nil
+}
+
+func (p ProfilesCmd) profileCookies(ctx context.Context, idOrName string) ([]profileCookie, error) {
+	prof, err := p.profiles.Get(ctx, idOrName)
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %w", idOrName, util.CleanedUpSdkError{Err: err})
+	}
+	br, err := p.browsers.New(ctx, kernel.BrowserNewParams{
+		Headless: kernel.Opt(true),
+		Profile:  kernel.BrowserProfileParam{ID: kernel.Opt(prof.ID), SaveChanges: kernel.Opt(false)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %w", idOrName, util.CleanedUpSdkError{Err: err})
+	}
+	defer func() {
+		if err := p.browsers.DeleteByID(context.WithoutCancel(ctx), br.SessionID); err != nil && !util.IsNotFound(err) {
+			pterm.Warning.Printf("Failed to delete temporary browser %s: %v\n", br.SessionID, util.CleanedUpSdkError{Err: err})
+		}
+	}()
+
+	res, err := executePlaywright(ctx, p.playwright, br.SessionID, kernel.BrowserPlaywrightExecuteParams{Code: profileCookiesScript}, readOnlyPlaywrightRetries)
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %w", idOrName, util.CleanedUpSdkError{Err: err})
+	}
+	if !res.Success {
+		return nil, fmt.Errorf("profile %s: reading cookies failed: %s", idOrName, util.FirstOrDash(res.Error, res.Stderr))
+	}
+	raw, err := json.Marshal(res.Result)
+	if err != nil {
+		return nil, err
+	}
+	var cookies []profileCookie
+	if err := json.Unmarshal(raw, &cookies); err != nil {
+		return nil, fmt.Errorf("profile %s: unexpected cookie list: %w", idOrName, err)
+	}
+	return cookies, nil
+}
+
+// filterCookiesByDomain keeps cookies sent to domain or any of its subdomains.
+func filterCookiesByDomain(cookies []profileCookie, domain string) []profileCookie {
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	if domain == "" {
+		return cookies
+	}
+	var out []profileCookie
+	for _, c := range cookies {
+		d := strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+		if d == domain || strings.HasSuffix(d, "."+domain) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func diffProfileCookies(a, b []profileCookie) []profileCookieDiff {
+	key := func(c profileCookie) string { return c.Domain + "\x00" + c.Name + "\x00" + c.Path }
+	byKey := map[string]*profileCookieDiff{}
+	for i := range a {
+		c := &a[i]
+		byKey[key(*c)] = &profileCookieDiff{Domain: c.Domain, Name: c.Name, Path: c.Path, A: c}
+	}
+	for i := range b {
+		c := &b[i]
+		if d, ok := byKey[key(*c)]; ok {
+			d.B = c
+		} else {
+			byKey[key(*c)] = &profileCookieDiff{Domain: c.Domain, Name: c.Name, Path: c.Path, B: c}
+		}
+	}
+
+	diffs := make([]profileCookieDiff, 0, len(byKey))
+	for _, d := range byKey {
+		switch {
+		case d.B == nil:
+			d.Status = "only_in_a"
+		case d.A == nil:
+			d.Status = "only_in_b"
+		case cookieExpiryBucket(d.A.Expires) != cookieExpiryBucket(d.B.Expires):
+			d.Status = "expiry_differs"
+		default:
+			d.Status = "same"
+		}
+		d.Session = sessionCookieName.MatchString(d.Name) || (d.A != nil && d.A.Expires <= 0) || (d.B != nil && d.B.Expires <= 0)
+		diffs = append(diffs, *d)
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Domain != diffs[j].Domain {
+			return strings.TrimPrefix(diffs[i].Domain, ".") < strings.TrimPrefix(diffs[j].Domain, ".")
+		}
+		if diffs[i].Name != diffs[j].Name {
+			return diffs[i].Name < diffs[j].Name
+		}
+		return diffs[i].Path < diffs[j].Path
+	})
+	return diffs
+}
+
+// cookieExpiryBucket rounds expiries to the day so cookies refreshed a few
+// seconds apart still compare as the same.
+func cookieExpiryBucket(expires float64) int64 {
+	if expires <= 0 {
+		return -1
+	}
+	return int64(expires) / 86400
+}
+
+func formatCookieExpiry(c *profileCookie) string {
+	switch {
+	case c == nil:
+		return pterm.Red("missing")
+	case c.Expires <= 0:
+		return "session"
+	default:
+		return util.FormatLocal(time.Unix(int64(c.Expires), 0))
+	}
+}
+
+func printProfileCookieDiff(a, b string, diffs []profileCookieDiff) {
+	same := 0
+	rows := pterm.TableData{{"Domain", "Name", "Path", a, b, "Difference"}}
+	var missingSession []string
+	for _, d := range diffs {
+		if d.Status == "same" {
+			same++
+			continue
+		}
+		var note string
+		switch d.Status {
+		case "only_in_a":
+			note = "only in " + a
+		case "only_in_b":
+			note = "only in " + b
+		default:
+			note = "expiry differs"
+		}
+		if d.Session && d.Status != "expiry_differs" {
+			note = pterm.Red(note + " (session cookie)")
+			missingSession = append(missingSession, d.Name)
+		}
+		rows = append(rows, []string{d.Domain, d.Name, d.Path, formatCookieExpiry(d.A), formatCookieExpiry(d.B), note})
+	}
+
+	if len(rows) == 1 {
+		pterm.Success.Printf("No cookie differences (%d cookies compared)\n", same)
+		return
+	}
+	PrintTableNoPad(rows, true)
+	pterm.Info.Printf("%d differing, %d identical cookies (values are not compared)\n", len(rows)-1, same)
+	if len(missingSession) > 0 {
+		pterm.Warning.Printf("Session cookies present in only one profile: %s\n", strings.Join(missingSession, ", "))
+	}
+}
+
+var profilesDiffCmd = &cobra.Command{
+	Use:   "diff <a> <b>",
+	Short: "Compare the cookies stored in two profiles",
+	Long: `Compare cookie names, domains, paths and expiries (never values) between two
+profiles, highlighting session cookies that only one of them has. Useful for
+working out why a login works with one profile but not another.
+
+Each profile is loaded into a temporary headless browser that does not save
+changes back to the profile, and the browser is deleted afterwards.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runProfilesDiff,
+}
+
+func runProfilesDiff(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	output, _ := cmd.Flags().GetString("output")
+	domain, _ := cmd.Flags().GetString("domain")
+
+	svc := client.Profiles
+	p := ProfilesCmd{profiles: &svc, browsers: &client.Browsers, playwright: &client.Browsers.Playwright}
+	return p.Diff(cmd.Context(), ProfilesDiffInput{A: args[0], B: args[1], Domain: domain, Output: output})
+}
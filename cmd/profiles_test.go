@@ -11,14 +11,17 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/kernel/cli/pkg/lock"
 	"github.com/kernel/kernel-go-sdk"
 	"github.com/kernel/kernel-go-sdk/option"
 	"github.com/kernel/kernel-go-sdk/packages/pagination"
 	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // FakeProfilesService implements ProfilesService
@@ -27,6 +30,7 @@ type FakeProfilesService struct {
 	ListFunc     func(ctx context.Context, query kernel.ProfileListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.Profile], error)
 	DeleteFunc   func(ctx context.Context, idOrName string, opts ...option.RequestOption) error
 	NewFunc      func(ctx context.Context, body kernel.ProfileNewParams, opts ...option.RequestOption) (*kernel.Profile, error)
+	UpdateFunc   func(ctx context.Context, idOrName string, body kernel.ProfileUpdateParams, opts ...option.RequestOption) (*kernel.Profile, error)
 	DownloadFunc func(ctx context.Context, idOrName string, opts ...option.RequestOption) (*http.Response, error)
 }
 
@@ -54,6 +58,12 @@ func (f *FakeProfilesService) Download(ctx context.Context, idOrName string, opt
 	}
 	return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("")), Header: http.Header{}}, nil
 }
+func (f *FakeProfilesService) Update(ctx context.Context, idOrName string, body kernel.ProfileUpdateParams, opts ...option.RequestOption) (*kernel.Profile, error) {
+	if f.UpdateFunc != nil {
+		return f.UpdateFunc(ctx, idOrName, body, opts...)
+	}
+	return &kernel.Profile{ID: idOrName, Name: body.Name, UpdatedAt: time.Unix(0, 0)}, nil
+}
 func (f *FakeProfilesService) New(ctx context.Context, body kernel.ProfileNewParams, opts ...option.RequestOption) (*kernel.Profile, error) {
 	if f.NewFunc != nil {
 		return f.NewFunc(ctx, body, opts...)
@@ -184,6 +194,23 @@ func TestProfilesCreate_Error(t *testing.T) {
 	assert.Contains(t, err.Error(), "fail")
 }
 
+func TestProfilesUpdate_Renames(t *testing.T) {
+	buf := capturePtermOutput(t)
+	var gotID, gotName string
+	fake := &FakeProfilesService{UpdateFunc: func(ctx context.Context, idOrName string, body kernel.ProfileUpdateParams, opts ...option.RequestOption) (*kernel.Profile, error) {
+		gotID, gotName = idOrName, body.Name
+		return &kernel.Profile{ID: "p1", Name: body.Name, UpdatedAt: time.Unix(0, 0)}, nil
+	}}
+	p := ProfilesCmd{profiles: fake}
+	assert.NoError(t, p.Update(context.Background(), ProfilesUpdateInput{Identifier: "old", Name: "new"}))
+	assert.Equal(t, "old", gotID)
+	assert.Equal(t, "new", gotName)
+	assert.Contains(t, buf.String(), "Renamed profile 'old' to 'new'")
+
+	err := p.Update(context.Background(), ProfilesUpdateInput{Identifier: "old"})
+	assert.ErrorContains(t, err, "--name is required")
+}
+
 func TestProfilesDelete_ConfirmNotFound(t *testing.T) {
 	buf := capturePtermOutput(t)
 	fake := &FakeProfilesService{GetFunc: func(ctx context.Context, idOrName string, opts ...option.RequestOption) (*kernel.Profile, error) {
@@ -291,3 +318,208 @@ func TestProfilesDownload_PathTraversalRejected(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "illegal entry path")
 }
+
+func TestProfilesLock_AcquireAndConflict(t *testing.T) {
+	buf := capturePtermOutput(t)
+	store := &lock.Store{Dir: t.TempDir()}
+	fake := &FakeProfilesService{}
+	p := ProfilesCmd{profiles: fake, locks: store}
+
+	err := p.Lock(context.Background(), ProfilesLockInput{Identifier: "p1", TTL: time.Minute, Owner: "alice"})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "Locked profile 'p1'")
+
+	err = p.Lock(context.Background(), ProfilesLockInput{Identifier: "p1", TTL: time.Minute, Owner: "bob"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "locked by alice")
+
+	err = p.Unlock(context.Background(), ProfilesUnlockInput{Identifier: "p1", Owner: "bob"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "--force")
+
+	err = p.Unlock(context.Background(), ProfilesUnlockInput{Identifier: "p1", Owner: "bob", Force: true})
+	assert.NoError(t, err)
+	held, err := store.Get(lock.ProfileResource("p1"))
+	assert.NoError(t, err)
+	assert.Nil(t, held)
+}
+
+func TestProfilesLock_KeyedByProfileID(t *testing.T) {
+	_ = capturePtermOutput(t)
+	store := &lock.Store{Dir: t.TempDir()}
+	fake := &FakeProfilesService{GetFunc: func(ctx context.Context, idOrName string, opts ...option.RequestOption) (*kernel.Profile, error) {
+		return &kernel.Profile{ID: "prof_123", Name: "my-bot"}, nil
+	}}
+	p := ProfilesCmd{profiles: fake, locks: store}
+
+	assert.NoError(t, p.Lock(context.Background(), ProfilesLockInput{Identifier: "my-bot", TTL: time.Minute, Owner: "alice"}))
+	held, err := store.Get(lock.ProfileResource("prof_123"))
+	assert.NoError(t, err)
+	if assert.NotNil(t, held) {
+		assert.Equal(t, "alice", held.Owner)
+	}
+}
+
+func TestProfilesLock_OwnLocksDontBlockOwnCommands(t *testing.T) {
+	_ = capturePtermOutput(t)
+	store := &lock.Store{Dir: t.TempDir()}
+	p := ProfilesCmd{profiles: &FakeProfilesService{}, locks: store}
+	auto := profileLocker{store: store, owner: "alice#42"}
+
+	// A manual lock doesn't stop the same user's browsers create
+	require.NoError(t, p.Lock(context.Background(), ProfilesLockInput{Identifier: "p1", TTL: time.Minute, Owner: "alice"}))
+	held, err := auto.Acquire(context.Background(), "p1", lock.Options{TTL: time.Minute})
+	require.NoError(t, err)
+	assert.Nil(t, held, "the manual lock is left in place")
+	_, err = (&profileLocker{store: store, owner: "bob#7"}).Acquire(context.Background(), "p1", lock.Options{TTL: time.Minute})
+	assert.ErrorContains(t, err, "locked by alice")
+	require.NoError(t, p.Unlock(context.Background(), ProfilesUnlockInput{Identifier: "p1", Owner: "alice"}))
+
+	// And the user can unlock their own automatic lock without --force
+	_, err = auto.Acquire(context.Background(), "p2", lock.Options{TTL: time.Minute})
+	require.NoError(t, err)
+	require.NoError(t, p.Unlock(context.Background(), ProfilesUnlockInput{Identifier: "p2", Owner: "alice"}))
+	held, err = store.Get(lock.ProfileResource("p2"))
+	require.NoError(t, err)
+	assert.Nil(t, held)
+}
+
+func TestProfilesDiff_ComparesCookiesWithoutValues(t *testing.T) {
+	setupStdoutCapture(t)
+
+	cookies := map[string]any{
+		"sess-a": []any{
+			map[string]any{"name": "sid", "domain": ".example.com", "path": "/", "expires": -1},
+			map[string]any{"name": "prefs", "domain": "example.com", "path": "/", "expires": 1893456000},
+			map[string]any{"name": "other", "domain": "other.test", "path": "/", "expires": 1893456000},
+		},
+		"sess-b": []any{
+			map[string]any{"name": "prefs", "domain": "example.com", "path": "/", "expires": 1893456100},
+		},
+	}
+	var mu sync.Mutex
+	var deleted []string
+	browsers := &FakeBrowsersService{
+		NewFunc: func(ctx context.Context, body kernel.BrowserNewParams, opts ...option.RequestOption) (*kernel.BrowserNewResponse, error) {
+			assert.False(t, body.Profile.SaveChanges.Value)
+			return &kernel.BrowserNewResponse{SessionID: "sess-" + body.Profile.ID.Value}, nil
+		},
+		DeleteByIDFunc: func(ctx context.Context, id string, opts ...option.RequestOption) error {
+			mu.Lock()
+			defer mu.Unlock()
+			deleted = append(deleted, id)
+			return nil
+		},
+	}
+	pw := &fakePlaywright{executeFunc: func(id string, body kernel.BrowserPlaywrightExecuteParams) (*kernel.BrowserPlaywrightExecuteResponse, error) {
+		assert.NotContains(t, body.Code, "c.value")
+		return &kernel.BrowserPlaywrightExecuteResponse{Success: true, Result: cookies[id]}, nil
+	}}
+	p := ProfilesCmd{profiles: &FakeProfilesService{}, browsers: browsers, playwright: pw}
+
+	err := p.Diff(context.Background(), ProfilesDiffInput{A: "a", B: "b", Domain: "example.com"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"sess-a", "sess-b"}, deleted)
+	out := outBuf.String()
+	assert.Contains(t, out, "only in a (session cookie)")
+	assert.Contains(t, out, "1 differing, 1 identical")
+	assert.NotContains(t, out, "other.test")
+}
+
+func TestProfilesClone_CopiesCookies(t *testing.T) {
+	buf := capturePtermOutput(t)
+	var created kernel.ProfileNewParams
+	profiles := &FakeProfilesService{
+		GetFunc: func(ctx context.Context, idOrName string, opts ...option.RequestOption) (*kernel.Profile, error) {
+			if idOrName == "bot-a" {
+				return &kernel.Profile{ID: "p-src", Name: "bot-a"}, nil
+			}
+			return nil, &kernel.Error{StatusCode: http.StatusNotFound}
+		},
+		NewFunc: func(ctx context.Context, body kernel.ProfileNewParams, opts ...option.RequestOption) (*kernel.Profile, error) {
+			created = body
+			return &kernel.Profile{ID: "p-dst", Name: body.Name.Value}, nil
+		},
+		DownloadFunc: func(ctx context.Context, idOrName string, opts ...option.RequestOption) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("")), Header: http.Header{}}, nil
+		},
+	}
+	var saves []bool
+	var deleted []string
+	browsers := &FakeBrowsersService{
+		NewFunc: func(ctx context.Context, body kernel.BrowserNewParams, opts ...option.RequestOption) (*kernel.BrowserNewResponse, error) {
+			saves = append(saves, body.Profile.SaveChanges.Value)
+			return &kernel.BrowserNewResponse{SessionID: body.Profile.ID.Value + "-browser"}, nil
+		},
+		DeleteByIDFunc: func(ctx context.Context, id string, opts ...option.RequestOption) error {
+			deleted = append(deleted, id)
+			return nil
+		},
+	}
+	pw := &fakePlaywright{executeFunc: func(id string, body kernel.BrowserPlaywrightExecuteParams) (*kernel.BrowserPlaywrightExecuteResponse, error) {
+		if id == "p-src-browser" {
+			return &kernel.BrowserPlaywrightExecuteResponse{Success: true, Result: []any{
+				map[string]any{"name": "sid", "value": "secret", "domain": ".example.com"},
+			}}, nil
+		}
+		return &kernel.BrowserPlaywrightExecuteResponse{Success: true, Result: 1}, nil
+	}}
+
+	p := ProfilesCmd{profiles: profiles, browsers: browsers, playwright: pw}
+	err := p.Clone(context.Background(), ProfilesCloneInput{Source: "bot-a", Dest: "bot-b", Wait: true, PollInterval: time.Millisecond})
+	assert.NoError(t, err)
+	assert.Equal(t, "bot-b", created.Name.Value)
+	assert.Equal(t, []bool{false, true}, saves)
+	assert.Equal(t, []string{"p-src-browser", "p-dst-browser"}, deleted)
+	assert.Len(t, pw.calls, 2)
+	assert.Contains(t, pw.calls[1], `"value":"secret"`)
+
+	out := buf.String()
+	assert.Contains(t, out, "[1/4] Reading cookies from bot-a")
+	assert.Contains(t, out, "[4/4] Waiting for bot-b to be saved")
+	assert.Contains(t, out, "Cloned bot-a to bot-b (1 cookies)")
+	assert.NotContains(t, out, "secret")
+}
+
+func TestProfilesClone_DestinationExists(t *testing.T) {
+	profiles := &FakeProfilesService{GetFunc: func(ctx context.Context, idOrName string, opts ...option.RequestOption) (*kernel.Profile, error) {
+		return &kernel.Profile{ID: "p-" + idOrName, Name: idOrName}, nil
+	}}
+	p := ProfilesCmd{profiles: profiles, browsers: &FakeBrowsersService{}, playwright: &fakePlaywright{}}
+	err := p.Clone(context.Background(), ProfilesCloneInput{Source: "bot-a", Dest: "bot-b"})
+	assert.ErrorContains(t, err, "profile bot-b already exists")
+}
+
+func TestProfilesClone_WriteFailureDeletesClone(t *testing.T) {
+	_ = capturePtermOutput(t)
+	var deletedProfile string
+	profiles := &FakeProfilesService{
+		GetFunc: func(ctx context.Context, idOrName string, opts ...option.RequestOption) (*kernel.Profile, error) {
+			if idOrName == "bot-a" {
+				return &kernel.Profile{ID: "p-src"}, nil
+			}
+			return nil, &kernel.Error{StatusCode: http.StatusNotFound}
+		},
+		NewFunc: func(ctx context.Context, body kernel.ProfileNewParams, opts ...option.RequestOption) (*kernel.Profile, error) {
+			return &kernel.Profile{ID: "p-dst"}, nil
+		},
+		DeleteFunc: func(ctx context.Context, idOrName string, opts ...option.RequestOption) error {
+			deletedProfile = idOrName
+			return nil
+		},
+	}
+	browsers := &FakeBrowsersService{NewFunc: func(ctx context.Context, body kernel.BrowserNewParams, opts ...option.RequestOption) (*kernel.BrowserNewResponse, error) {
+		return &kernel.BrowserNewResponse{SessionID: body.Profile.ID.Value + "-browser"}, nil
+	}}
+	pw := &fakePlaywright{executeFunc: func(id string, body kernel.BrowserPlaywrightExecuteParams) (*kernel.BrowserPlaywrightExecuteResponse, error) {
+		if id == "p-src-browser" {
+			return &kernel.BrowserPlaywrightExecuteResponse{Success: true, Result: []any{}}, nil
+		}
+		return &kernel.BrowserPlaywrightExecuteResponse{Success: false, Error: "invalid cookie"}, nil
+	}}
+
+	p := ProfilesCmd{profiles: profiles, browsers: browsers, playwright: pw}
+	err := p.Clone(context.Background(), ProfilesCloneInput{Source: "bot-a", Dest: "bot-b"})
+	assert.ErrorContains(t, err, "writing cookies failed: invalid cookie")
+	assert.Equal(t, "p-dst", deletedProfile)
+}
@@ -163,11 +163,11 @@ func (c ProjectsCmd) LimitsGet(ctx context.Context, in ProjectsLimitsGetInput) e
 
 	if in.Output == "json" {
 		if limits == nil {
-			fmt.Println("null")
-			return nil
+			return util.PrintJSON(nil)
 		}
 		return util.PrintPrettyJSON(limits)
 	}
+	util.WarnDecodeDrift(limits)
 
 	renderProjectLimits(limits)
 	return nil
@@ -215,11 +215,11 @@ func (c ProjectsCmd) LimitsSet(ctx context.Context, in ProjectsLimitsSetInput) e
 
 	if in.Output == "json" {
 		if limits == nil {
-			fmt.Println("null")
-			return nil
+			return util.PrintJSON(nil)
 		}
 		return util.PrintPrettyJSON(limits)
 	}
+	util.WarnDecodeDrift(limits)
 
 	pterm.Success.Println("Project limits updated:")
 	renderProjectLimits(limits)
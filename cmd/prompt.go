@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kernel/cli/pkg/auth"
+	"github.com/kernel/cli/pkg/config"
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultPromptCacheTTL = 30 * time.Second
+	// promptFetchTimeout bounds the API calls made when the cache is stale;
+	// the prompt falls back to the last known counts rather than wait longer.
+	promptFetchTimeout = 1500 * time.Millisecond
+	promptPageSize     = 100
+)
+
+// promptCounts is the cached part of the prompt. A failed refresh keeps the
+// previous counts and records the error, so a broken connection is retried
+// once per TTL rather than on every render.
+type promptCounts struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	// Org is cached too because reading it may touch the OS keychain.
+	Org         string `json:"org,omitempty"`
+	Known       bool   `json:"known"`
+	Browsers    int64  `json:"browsers"`
+	Invocations int64  `json:"invocations"`
+	Error       string `json:"error,omitempty"`
+}
+
+// promptInfo is what the prompt shows, and the -o json record.
+type promptInfo struct {
+	Context     string `json:"context,omitempty"`
+	Org         string `json:"org,omitempty"`
+	Project     string `json:"project,omitempty"`
+	Browsers    *int64 `json:"browsers,omitempty"`
+	Invocations *int64 `json:"running_invocations,omitempty"`
+}
+
+type PromptInput struct {
+	Context string
+	Project string
+	// Shell wraps color codes so bash or zsh don't count them toward the
+	// prompt width: "bash", "zsh" or "" for raw ANSI.
+	Shell  string
+	TTL    time.Duration
+	Output string
+}
+
+// PromptCmd prints a one-line summary for shell prompts.
+type PromptCmd struct {
+	// services is only called when the cache is stale, so a fresh cache
+	// never touches auth or the network.
+	services func() (BrowsersService, InvocationLister, error)
+	// org returns the organization name; it is also only called on refresh.
+	org       func() string
+	cachePath string
+	cacheKey  string
+	now       func() time.Time
+}
+
+func (p PromptCmd) Prompt(ctx context.Context, in PromptInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if in.Shell != "" && in.Shell != "bash" && in.Shell != "zsh" {
+		return fmt.Errorf("invalid --shell %q: must be bash or zsh", in.Shell)
+	}
+	if in.TTL <= 0 {
+		in.TTL = defaultPromptCacheTTL
+	}
+	if p.now == nil {
+		p.now = time.Now
+	}
+
+	counts := p.counts(ctx, in.TTL)
+	info := promptInfo{Context: in.Context, Org: counts.Org, Project: in.Project}
+	if counts.Known {
+		info.Browsers, info.Invocations = &counts.Browsers, &counts.Invocations
+	}
+	if in.Output == "json" {
+		return util.PrintJSON(info)
+	}
+	if line := renderPrompt(info, in.Shell); line != "" {
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// counts returns the cached counts, refreshing them first when they are
+// older than ttl.
+func (p PromptCmd) counts(ctx context.Context, ttl time.Duration) promptCounts {
+	cache := map[string]promptCounts{}
+	if p.cachePath != "" {
+		if b, err := os.ReadFile(p.cachePath); err == nil {
+			_ = json.Unmarshal(b, &cache)
+		}
+	}
+	now := p.now()
+	prev, ok := cache[p.cacheKey]
+	if ok && now.Sub(prev.FetchedAt) < ttl {
+		return prev
+	}
+
+	next := prev
+	if fresh, err := p.fetch(ctx); err != nil {
+		next.Error = err.Error()
+	} else {
+		next = fresh
+	}
+	next.FetchedAt = now
+	if p.org != nil {
+		next.Org = p.org()
+	}
+
+	if p.cachePath != "" {
+		for k, entry := range cache {
+			if now.Sub(entry.FetchedAt) >= 24*time.Hour {
+				delete(cache, k)
+			}
+		}
+		cache[p.cacheKey] = next
+		if b, err := json.Marshal(cache); err == nil {
+			_ = os.WriteFile(p.cachePath, b, 0o600)
+		}
+	}
+	return next
+}
+
+func (p PromptCmd) fetch(ctx context.Context) (promptCounts, error) {
+	if p.services == nil {
+		return promptCounts{}, fmt.Errorf("not authenticated")
+	}
+	browsers, invocations, err := p.services()
+	if err != nil {
+		return promptCounts{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, promptFetchTimeout)
+	defer cancel()
+
+	var c promptCounts
+	for offset := int64(0); ; offset += promptPageSize {
+		page, err := browsers.List(ctx, kernel.BrowserListParams{
+			Status: kernel.BrowserListParamsStatusActive,
+			Limit:  kernel.Opt(int64(promptPageSize)),
+			Offset: kernel.Opt(offset),
+		})
+		if err != nil {
+			return c, util.CleanedUpSdkError{Err: err}
+		}
+		if page == nil {
+			break
+		}
+		c.Browsers += int64(len(page.Items))
+		if len(page.Items) < promptPageSize {
+			break
+		}
+	}
+	running, err := QuotaCmd{invocations: invocations}.countRunningInvocations(ctx)
+	if err != nil {
+		return c, err
+	}
+	c.Invocations = running
+	c.Known = true
+	return c, nil
+}
+
+// renderPrompt formats info as e.g. "kernel:prod@acme/web b:3 i:1". It is
+// empty when there is nothing to show.
+func renderPrompt(info promptInfo, shell string) string {
+	var where string
+	if info.Context != "" {
+		where += ":" + pterm.Cyan(info.Context)
+	}
+	if info.Org != "" {
+		where += "@" + pterm.Magenta(info.Org)
+	}
+	if info.Project != "" {
+		where += "/" + pterm.Blue(info.Project)
+	}
+	if where == "" && info.Browsers == nil {
+		return ""
+	}
+	parts := []string{"kernel" + where}
+	if info.Browsers != nil {
+		parts = append(parts, pterm.Green(fmt.Sprintf("b:%d", *info.Browsers)))
+	}
+	if info.Invocations != nil && *info.Invocations > 0 {
+		parts = append(parts, pterm.Yellow(fmt.Sprintf("i:%d", *info.Invocations)))
+	}
+	return wrapPromptEscapes(strings.Join(parts, " "), shell)
+}
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// wrapPromptEscapes marks color codes as zero-width for the shell's prompt
+// line editor. Bash reads \[ and \] before running $(...), so its output
+// needs readline's raw \001 and \002 markers instead.
+func wrapPromptEscapes(s, shell string) string {
+	switch shell {
+	case "bash":
+		return ansiEscape.ReplaceAllString(s, "\x01$0\x02")
+	case "zsh":
+		return ansiEscape.ReplaceAllString(s, `%{$0%}`)
+	}
+	return s
+}
+
+func promptCachePath() string {
+	dir, err := util.CacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "prompt.json")
+}
+
+// promptOrgName reads the organization from stored OAuth tokens. API keys
+// carry no organization name.
+func promptOrgName() string {
+	if os.Getenv("KERNEL_API_KEY") != "" {
+		return ""
+	}
+	tokens, err := auth.LoadTokens()
+	if err != nil {
+		return ""
+	}
+	if claims, err := parseJWT(tokens.AccessToken); err == nil && claims != nil {
+		return claims.OrgName
+	}
+	return ""
+}
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Print a short status line for your shell prompt",
+	Long: `Print the current context, organization and project with counts of active
+browsers (b:) and running invocations (i:, shown when non-zero), for embedding
+in PS1 or a starship custom module.
+
+Counts are cached in ~/.cache/kernel for --ttl, so most renders make no API
+calls. When the cache is stale, the refresh is bounded to a short timeout and
+the last known counts are shown if it fails. Nothing is printed when there is
+nothing to show, e.g. when logged out.`,
+	Example: `  # bash
+  PS1='$(kernel prompt --shell bash) \w \$ '
+
+  # zsh (with setopt PROMPT_SUBST)
+  PROMPT='$(kernel prompt --shell zsh) %~ %# '
+
+  # starship.toml
+  [custom.kernel]
+  command = "kernel prompt"
+  when = true`,
+	Args: cobra.NoArgs,
+	RunE: runPrompt,
+}
+
+func init() {
+	promptCmd.Flags().String("shell", "", "Wrap color codes for bash or zsh prompts (bash, zsh)")
+	promptCmd.Flags().Duration("ttl", defaultPromptCacheTTL, "How long cached counts are reused")
+	addJSONOutputFlag(promptCmd)
+	_ = promptCmd.RegisterFlagCompletionFunc("shell", cobra.FixedCompletions([]string{"bash", "zsh"}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+func runPrompt(cmd *cobra.Command, args []string) error {
+	shell, _ := cmd.Flags().GetString("shell")
+	ttl, _ := cmd.Flags().GetDuration("ttl")
+	output, _ := cmd.Flags().GetString("output")
+	contextName, _ := cmd.Flags().GetString("context")
+	project, _ := cmd.Flags().GetString("project")
+
+	contextName = resolveContextSelection(contextName)
+	if contextName == "" {
+		if cfg, err := config.Load(); err == nil {
+			contextName = cfg.CurrentContext
+		}
+	}
+	project = resolveProjectSelection(project)
+
+	p := PromptCmd{
+		services: func() (BrowsersService, InvocationLister, error) {
+			// Token refresh warnings would end up in the prompt.
+			pterm.DisableOutput()
+			defer pterm.EnableOutput()
+			client, err := newAuthenticatedClient(cmd)
+			if err != nil {
+				return nil, nil, err
+			}
+			return &client.Browsers, &client.Invocations, nil
+		},
+		org:       promptOrgName,
+		cachePath: promptCachePath(),
+		cacheKey:  strings.Join([]string{util.GetBaseURL(), contextName, project}, " "),
+	}
+	return p.Prompt(cmd.Context(), PromptInput{
+		Context: contextName,
+		Project: project,
+		Shell:   shell,
+		TTL:     ttl,
+		Output:  output,
+	})
+}
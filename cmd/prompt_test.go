@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/kernel/kernel-go-sdk/packages/pagination"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func promptTestCmd(t *testing.T, calls *int, fail *bool) PromptCmd {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	browsers := &FakeBrowsersService{ListFunc: func(ctx context.Context, query kernel.BrowserListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.BrowserListResponse], error) {
+		return &pagination.OffsetPagination[kernel.BrowserListResponse]{Items: []kernel.BrowserListResponse{{SessionID: "a"}, {SessionID: "b"}}}, nil
+	}}
+	invocations := &fakeInvocationListerFunc{fn: func(query kernel.InvocationListParams) []kernel.InvocationListResponse {
+		return []kernel.InvocationListResponse{{ID: "inv-1"}}
+	}}
+	return PromptCmd{
+		services: func() (BrowsersService, InvocationLister, error) {
+			*calls++
+			if *fail {
+				return nil, nil, errors.New("offline")
+			}
+			return browsers, invocations, nil
+		},
+		org:       func() string { return "acme" },
+		cachePath: filepath.Join(t.TempDir(), "prompt.json"),
+		cacheKey:  "key",
+		now:       func() time.Time { return now },
+	}
+}
+
+func TestPrompt_CachesCounts(t *testing.T) {
+	pterm.DisableColor()
+	t.Cleanup(pterm.EnableColor)
+	var calls int
+	var fail bool
+	p := promptTestCmd(t, &calls, &fail)
+
+	out := captureStdout(t, func() {
+		require.NoError(t, p.Prompt(context.Background(), PromptInput{Context: "prod", Project: "web"}))
+	})
+	assert.Equal(t, "kernel:prod@acme/web b:2 i:1\n", out)
+
+	// A second render inside the TTL is served from the cache.
+	out = captureStdout(t, func() {
+		require.NoError(t, p.Prompt(context.Background(), PromptInput{Context: "prod", Project: "web"}))
+	})
+	assert.Equal(t, "kernel:prod@acme/web b:2 i:1\n", out)
+	assert.Equal(t, 1, calls)
+}
+
+func TestPrompt_StaleCountsSurviveFailedRefresh(t *testing.T) {
+	pterm.DisableColor()
+	t.Cleanup(pterm.EnableColor)
+	var calls int
+	var fail bool
+	p := promptTestCmd(t, &calls, &fail)
+	captureStdout(t, func() {
+		require.NoError(t, p.Prompt(context.Background(), PromptInput{}))
+	})
+
+	fail = true
+	later := p.now().Add(time.Minute)
+	p.now = func() time.Time { return later }
+	out := captureStdout(t, func() {
+		require.NoError(t, p.Prompt(context.Background(), PromptInput{}))
+	})
+	assert.Equal(t, "kernel@acme b:2 i:1\n", out)
+	assert.Equal(t, 2, calls)
+}
+
+func TestPrompt_PrintsNothingWhenLoggedOut(t *testing.T) {
+	p := PromptCmd{cachePath: filepath.Join(t.TempDir(), "prompt.json")}
+
+	out := captureStdout(t, func() {
+		require.NoError(t, p.Prompt(context.Background(), PromptInput{}))
+	})
+	assert.Empty(t, out)
+}
+
+func TestWrapPromptEscapes(t *testing.T) {
+	colored := "\x1b[36mprod\x1b[0m"
+	assert.Equal(t, "\x01\x1b[36m\x02prod\x01\x1b[0m\x02", wrapPromptEscapes(colored, "bash"))
+	assert.Equal(t, "%{\x1b[36m%}prod%{\x1b[0m%}", wrapPromptEscapes(colored, "zsh"))
+	assert.Equal(t, colored, wrapPromptEscapes(colored, ""))
+}
@@ -17,10 +17,18 @@ func (p ProxyCmd) Check(ctx context.Context, in ProxyCheckInput) error {
 	}
 
 	if in.Output != "json" {
-		pterm.Info.Printf("Running health check on proxy %s...\n", in.ID)
+		if in.URL != "" {
+			pterm.Info.Printf("Checking proxy %s against %s...\n", in.ID, in.URL)
+		} else {
+			pterm.Info.Printf("Running health check on proxy %s...\n", in.ID)
+		}
 	}
 
-	proxy, err := p.proxies.Check(ctx, in.ID, kernel.ProxyCheckParams{})
+	params := kernel.ProxyCheckParams{}
+	if in.URL != "" {
+		params.URL = kernel.Opt(in.URL)
+	}
+	proxy, err := p.proxies.Check(ctx, in.ID, params)
 	if err != nil {
 		return util.CleanedUpSdkError{Err: err}
 	}
@@ -28,6 +36,7 @@ func (p ProxyCmd) Check(ctx context.Context, in ProxyCheckInput) error {
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(proxy)
 	}
+	util.WarnDecodeDrift(proxy)
 
 	// Display proxy details after check
 	rows := pterm.TableData{{"Property", "Value"}}
@@ -49,9 +58,9 @@ func (p ProxyCmd) Check(ctx context.Context, in ProxyCheckInput) error {
 	}
 	rows = append(rows, []string{"Protocol", protocol})
 
-	// Display IP address if available
+	// Display the egress IP if available
 	if proxy.IPAddress != "" {
-		rows = append(rows, []string{"IP Address", proxy.IPAddress})
+		rows = append(rows, []string{"Egress IP", proxy.IPAddress})
 	}
 
 	// Display type-specific config details
@@ -151,7 +160,8 @@ func getProxyCheckConfigRows(proxy *kernel.ProxyCheckResponse) [][]string {
 func runProxiesCheck(cmd *cobra.Command, args []string) error {
 	client := util.GetKernelClient(cmd)
 	output, _ := cmd.Flags().GetString("output")
+	url, _ := cmd.Flags().GetString("url")
 	svc := client.Proxies
 	p := ProxyCmd{proxies: &svc}
-	return p.Check(cmd.Context(), ProxyCheckInput{ID: args[0], Output: output})
+	return p.Check(cmd.Context(), ProxyCheckInput{ID: args[0], URL: url, Output: output})
 }
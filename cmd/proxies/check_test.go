@@ -34,3 +34,30 @@ func TestProxyCheck_ShowsBypassHosts(t *testing.T) {
 	assert.Contains(t, output, "internal.service.local")
 	assert.Contains(t, output, "Proxy health check passed")
 }
+
+func TestProxyCheck_URLAndEgressIP(t *testing.T) {
+	buf := captureOutput(t)
+
+	var got kernel.ProxyCheckParams
+	fake := &FakeProxyService{
+		CheckFunc: func(ctx context.Context, id string, body kernel.ProxyCheckParams, opts ...option.RequestOption) (*kernel.ProxyCheckResponse, error) {
+			got = body
+			return &kernel.ProxyCheckResponse{
+				ID:        id,
+				Type:      kernel.ProxyCheckResponseTypeIsp,
+				IPAddress: "203.0.113.7",
+				Status:    kernel.ProxyCheckResponseStatusAvailable,
+			}, nil
+		},
+	}
+
+	p := ProxyCmd{proxies: fake}
+	err := p.Check(context.Background(), ProxyCheckInput{ID: "proxy-1", URL: "https://example.com"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com", got.URL.Value)
+	output := buf.String()
+	assert.Contains(t, output, "Checking proxy proxy-1 against https://example.com")
+	assert.Contains(t, output, "Egress IP")
+	assert.Contains(t, output, "203.0.113.7")
+}
@@ -173,6 +173,7 @@ func (p ProxyCmd) Create(ctx context.Context, in ProxyCreateInput) error {
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(proxy)
 	}
+	util.WarnDecodeDrift(proxy)
 
 	pterm.Success.Printf("Successfully created proxy\n")
 
@@ -24,6 +24,7 @@ func (p ProxyCmd) Get(ctx context.Context, in ProxyGetInput) error {
 	if in.Output == "json" {
 		return util.PrintPrettyJSON(item)
 	}
+	util.WarnDecodeDrift(item)
 
 	// Display proxy details
 	rows := pterm.TableData{{"Property", "Value"}}
@@ -40,11 +40,11 @@ func (p ProxyCmd) List(ctx context.Context, in ProxyListInput) error {
 
 	if in.Output == "json" {
 		if len(items) == 0 {
-			fmt.Println("[]")
-			return nil
+			return util.PrintJSON([]any{})
 		}
 		return util.PrintPrettyJSONSlice(items)
 	}
+	util.WarnDecodeDrift(items)
 
 	if len(items) == 0 {
 		pterm.Info.Println("No proxy configurations found")
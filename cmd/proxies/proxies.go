@@ -64,11 +64,17 @@ var proxiesDeleteCmd = &cobra.Command{
 }
 
 var proxiesCheckCmd = &cobra.Command{
-	Use:   "check <id>",
-	Short: "Run a health check on a proxy",
-	Long:  "Run a health check on a proxy to verify it's working and update its status.",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runProxiesCheck,
+	Use:     "check <id>",
+	Aliases: []string{"test"},
+	Short:   "Run a health check on a proxy",
+	Long: `Run a health check on a proxy to verify it's working and update its status.
+The result includes the egress IP the proxy used.
+
+With --url the check connects to that URL instead of the default test URLs,
+e.g. to confirm a site is reachable through the proxy. Such a check does not
+update the proxy's status.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProxiesCheck,
 }
 
 func init() {
@@ -114,4 +120,5 @@ func init() {
 
 	// Check flags
 	addJSONOutputFlag(proxiesCheckCmd)
+	proxiesCheckCmd.Flags().String("url", "", "Check connectivity to this http(s) URL instead of the default test URLs")
 }
@@ -62,6 +62,9 @@ type ProxyDeleteInput struct {
 }
 
 type ProxyCheckInput struct {
-	ID     string
+	ID string
+	// URL, when set, is the target the check connects to instead of the
+	// default test URLs. Such a check does not update the proxy's status.
+	URL    string
 	Output string
 }
@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pkg/browser"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+const defaultQuickstartAuthTimeout = 10 * time.Minute
+
+// QuickstartCmd runs guided walkthroughs that chain several commands.
+type QuickstartCmd struct {
+	profiles    ProfilesService
+	credentials CredentialsService
+	auth        AuthConnectionCmd
+	browsers    BrowsersService
+	openURL     func(url string) error
+}
+
+type QuickstartAuthInput struct {
+	Domain string
+	// ProfileName defaults to the domain.
+	ProfileName string
+	Timeout     time.Duration
+	// PollInterval overrides how often the login flow is checked.
+	PollInterval time.Duration
+}
+
+// Auth takes a domain from nothing to a logged-in browser: it creates (or
+// reuses) a profile, a credential with prompted username and password, and
+// an auth connection, runs the login, and then opens a browser on that
+// profile. Steps Kernel can't finish alone, such as MFA, are handed to the
+// user through the hosted login page.
+func (q QuickstartCmd) Auth(ctx context.Context, p setupPrompter, in QuickstartAuthInput) error {
+	domain, err := normalizeQuickstartDomain(in.Domain)
+	if err != nil {
+		return err
+	}
+	if in.ProfileName == "" {
+		in.ProfileName = domain
+	}
+	if in.Timeout <= 0 {
+		in.Timeout = defaultQuickstartAuthTimeout
+	}
+	if in.PollInterval <= 0 {
+		in.PollInterval = 2 * time.Second
+	}
+	if q.openURL == nil {
+		q.openURL = browser.OpenURL
+	}
+
+	pterm.DefaultSection.Println("1/4 Profile")
+	if err := q.ensureProfile(ctx, in.ProfileName); err != nil {
+		return err
+	}
+
+	pterm.DefaultSection.Println("2/4 Credential")
+	credName, err := q.ensureCredential(ctx, p, domain)
+	if err != nil {
+		return err
+	}
+
+	pterm.DefaultSection.Println("3/4 Login")
+	conn, err := q.ensureAuthConnection(ctx, domain, in.ProfileName, credName)
+	if err != nil {
+		return err
+	}
+	if err := q.login(ctx, *conn, in); err != nil {
+		return err
+	}
+
+	pterm.DefaultSection.Println("4/4 Browser")
+	pterm.Info.Printf("Creating a browser with profile '%s'...\n", in.ProfileName)
+	br, err := q.browsers.New(ctx, kernel.BrowserNewParams{
+		Profile: kernel.BrowserProfileParam{Name: kernel.Opt(in.ProfileName)},
+	})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	PrintTableNoPad(buildBrowserTableData(br.SessionID, br.CdpWsURL, br.BrowserLiveViewURL, br.Profile, "", "", nil), true)
+	if br.BrowserLiveViewURL != "" {
+		if err := q.openURL(br.BrowserLiveViewURL); err != nil {
+			pterm.Warning.Printf("Could not open a browser; visit %s\n", br.BrowserLiveViewURL)
+		}
+	}
+
+	pterm.Success.Printf("Done: %s is logged in to %s.\n", br.SessionID, domain)
+	pterm.Info.Printf("Delete the browser when you're finished: kernel browsers delete %s\n", br.SessionID)
+	pterm.Info.Printf("Start more logged-in browsers with: kernel browsers create --profile-name %s\n", in.ProfileName)
+	return nil
+}
+
+// normalizeQuickstartDomain accepts a bare domain or a URL and returns the
+// host.
+func normalizeQuickstartDomain(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("--domain is required")
+	}
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil || u.Hostname() == "" {
+			return "", fmt.Errorf("invalid --domain %q", raw)
+		}
+		return strings.ToLower(u.Hostname()), nil
+	}
+	if strings.ContainsAny(raw, "/ ") {
+		return "", fmt.Errorf("invalid --domain %q: expected a domain such as example.com", raw)
+	}
+	return strings.ToLower(raw), nil
+}
+
+func (q QuickstartCmd) ensureProfile(ctx context.Context, name string) error {
+	if _, err := q.profiles.Get(ctx, name); err == nil {
+		pterm.Info.Printf("Using existing profile '%s'\n", name)
+		return nil
+	} else if !util.IsNotFound(err) {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	if _, err := q.profiles.New(ctx, kernel.ProfileNewParams{Name: kernel.Opt(name)}); err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	pterm.Success.Printf("Created profile '%s'\n", name)
+	return nil
+}
+
+// ensureCredential reuses the credential named after the domain, or prompts
+// for a username and password and stores them as that credential.
+func (q QuickstartCmd) ensureCredential(ctx context.Context, p setupPrompter, domain string) (string, error) {
+	if _, err := q.credentials.Get(ctx, domain); err == nil {
+		pterm.Info.Printf("Using existing credential '%s'\n", domain)
+		return domain, nil
+	} else if !util.IsNotFound(err) {
+		return "", util.CleanedUpSdkError{Err: err}
+	}
+
+	pterm.Info.Printf("Enter your %s login. It is stored encrypted in Kernel and filled in during login.\n", domain)
+	username, err := p.Text("Username or email", "")
+	if err != nil {
+		return "", err
+	}
+	password, err := p.Secret("Password")
+	if err != nil {
+		return "", err
+	}
+	if username == "" || password == "" {
+		return "", fmt.Errorf("username and password are required")
+	}
+	cred, err := q.credentials.New(ctx, kernel.CredentialNewParams{
+		CreateCredentialRequest: kernel.CreateCredentialRequestParam{
+			Name:   domain,
+			Domain: domain,
+			Values: map[string]string{"username": username, "password": password},
+		},
+	})
+	if err != nil {
+		return "", util.CleanedUpSdkError{Err: err}
+	}
+	pterm.Success.Printf("Created credential '%s'\n", cred.Name)
+	return cred.Name, nil
+}
+
+// ensureAuthConnection reuses the connection for domain and profile, or
+// creates one linked to the credential.
+func (q QuickstartCmd) ensureAuthConnection(ctx context.Context, domain, profile, credName string) (*kernel.ManagedAuth, error) {
+	conns, err := q.auth.listLoginAllConnections(ctx, loginAllFilter{Status: "ANY", Domain: domain, Profile: profile})
+	if err != nil {
+		return nil, err
+	}
+	if len(conns) > 0 {
+		pterm.Info.Printf("Using existing auth connection %s\n", conns[0].ID)
+		return &conns[0], nil
+	}
+	conn, err := q.auth.svc.New(ctx, kernel.AuthConnectionNewParams{
+		ManagedAuthCreateRequest: kernel.ManagedAuthCreateRequestParam{
+			Domain:      domain,
+			ProfileName: profile,
+			Credential:  kernel.ManagedAuthCreateRequestCredentialParam{Name: kernel.Opt(credName)},
+		},
+	})
+	if err != nil {
+		return nil, util.CleanedUpSdkError{Err: err}
+	}
+	pterm.Success.Printf("Created auth connection %s\n", conn.ID)
+	return conn, nil
+}
+
+// login runs the connection's login flow. When the flow stops on input the
+// credential doesn't cover, the hosted login page is opened and the flow is
+// followed until the user finishes it there or the timeout runs out.
+func (q QuickstartCmd) login(ctx context.Context, conn kernel.ManagedAuth, in QuickstartAuthInput) error {
+	if conn.Status == kernel.ManagedAuthStatusAuthenticated && conn.FlowStatus != kernel.ManagedAuthFlowStatusInProgress {
+		pterm.Info.Println("Already logged in")
+		return nil
+	}
+	deadline := time.Now().Add(in.Timeout)
+	opts := AuthConnectionLoginAllInput{Timeout: in.Timeout, PollInterval: in.PollInterval, HandoffGrace: loginAllHandoffGrace}
+	pterm.Info.Println("Logging in...")
+	for handedOff := false; ; handedOff = true {
+		res := q.auth.loginOne(ctx, conn, opts, printAuthWatchChange)
+		switch res.Outcome {
+		case loginAllLoggedIn:
+			pterm.Success.Println("Logged in")
+			return nil
+		case loginAllNeedsHuman:
+			if handedOff {
+				return fmt.Errorf("login still needs input; finish it at %s or run: kernel auth connections login %s", util.OrDash(res.HostedURL), conn.ID)
+			}
+			pterm.Warning.Println("Kernel needs your help to finish signing in (for example an MFA code).")
+			if res.HostedURL != "" {
+				pterm.Info.Printf("Complete it here: %s\n", res.HostedURL)
+				if err := q.openURL(res.HostedURL); err != nil {
+					pterm.Debug.Printf("open hosted URL: %v\n", err)
+				}
+			}
+			// Keep following the same flow, now allowing the user the rest of
+			// the timeout to act.
+			conn.FlowStatus = kernel.ManagedAuthFlowStatusInProgress
+			opts.Timeout = time.Until(deadline)
+			opts.HandoffGrace = opts.Timeout
+			if opts.Timeout > 0 {
+				continue
+			}
+		}
+		msg := util.FirstOrDash(res.Error, string(res.FlowStep))
+		return fmt.Errorf("login %s (%s); check it with: kernel auth connections logs %s", res.Outcome, msg, conn.ID)
+	}
+}
+
+var quickstartCmd = &cobra.Command{
+	Use:   "quickstart",
+	Short: "Guided walkthroughs of common Kernel workflows",
+}
+
+var quickstartAuthCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Log in to a site once and open a browser that stays logged in",
+	Long: `Walk through managed auth end to end for one site:
+
+  1. create a profile to hold the session (reused if it exists)
+  2. prompt for a username and password and save them as a credential
+  3. create an auth connection and run its login; if the site asks for
+     something the credential can't answer, such as an MFA code, the hosted
+     login page opens for you to finish
+  4. create a browser with the logged-in profile and open its live view
+
+The profile and credential are named after the domain. The browser is left
+running; delete it with 'kernel browsers delete <id>' when you're done.`,
+	Example: `  kernel quickstart auth --domain example.com`,
+	Args:    cobra.NoArgs,
+	RunE:    runQuickstartAuth,
+}
+
+func init() {
+	quickstartCmd.AddCommand(quickstartAuthCmd)
+	quickstartAuthCmd.Flags().String("domain", "", "Domain to log in to (e.g. example.com)")
+	quickstartAuthCmd.Flags().String("profile-name", "", "Profile to store the session in (default: the domain)")
+	quickstartAuthCmd.Flags().Duration("timeout", defaultQuickstartAuthTimeout, "How long to wait for the login to finish")
+	_ = quickstartAuthCmd.MarkFlagRequired("domain")
+}
+
+func runQuickstartAuth(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	domain, _ := cmd.Flags().GetString("domain")
+	profileName, _ := cmd.Flags().GetString("profile-name")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+
+	q := QuickstartCmd{
+		profiles:    &client.Profiles,
+		credentials: &client.Credentials,
+		auth:        AuthConnectionCmd{svc: &client.Auth.Connections},
+		browsers:    &client.Browsers,
+	}
+	return q.Auth(cmd.Context(), ptermPrompter{}, QuickstartAuthInput{Domain: domain, ProfileName: profileName, Timeout: timeout})
+}
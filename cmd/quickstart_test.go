@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/kernel/kernel-go-sdk/packages/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func quickstartNotFound() error {
+	return &kernel.Error{StatusCode: http.StatusNotFound}
+}
+
+func TestQuickstartAuth_HappyPath(t *testing.T) {
+	setupStdoutCapture(t)
+	var createdProfile, createdConnProfile, browserProfile string
+	var credValues map[string]string
+	var opened []string
+
+	q := QuickstartCmd{
+		profiles: &FakeProfilesService{
+			GetFunc: func(ctx context.Context, idOrName string, opts ...option.RequestOption) (*kernel.Profile, error) {
+				return nil, quickstartNotFound()
+			},
+			NewFunc: func(ctx context.Context, body kernel.ProfileNewParams, opts ...option.RequestOption) (*kernel.Profile, error) {
+				createdProfile = body.Name.Value
+				return &kernel.Profile{ID: "prof-1", Name: body.Name.Value}, nil
+			},
+		},
+		credentials: &FakeCredentialsService{
+			GetFunc: func(ctx context.Context, idOrName string, opts ...option.RequestOption) (*kernel.Credential, error) {
+				return nil, quickstartNotFound()
+			},
+			NewFunc: func(ctx context.Context, body kernel.CredentialNewParams, opts ...option.RequestOption) (*kernel.Credential, error) {
+				credValues = body.CreateCredentialRequest.Values
+				return &kernel.Credential{ID: "cred-1", Name: body.CreateCredentialRequest.Name}, nil
+			},
+		},
+		auth: AuthConnectionCmd{svc: &FakeAuthConnectionService{
+			NewFunc: func(ctx context.Context, body kernel.AuthConnectionNewParams, opts ...option.RequestOption) (*kernel.ManagedAuth, error) {
+				createdConnProfile = body.ManagedAuthCreateRequest.ProfileName
+				assert.Equal(t, "example.com", body.ManagedAuthCreateRequest.Credential.Name.Value)
+				return &kernel.ManagedAuth{ID: "conn-1", Domain: "example.com", ProfileName: createdConnProfile}, nil
+			},
+			GetFunc: func(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.ManagedAuth, error) {
+				return &kernel.ManagedAuth{ID: id, FlowStatus: kernel.ManagedAuthFlowStatusSuccess}, nil
+			},
+		}},
+		browsers: &FakeBrowsersService{NewFunc: func(ctx context.Context, body kernel.BrowserNewParams, opts ...option.RequestOption) (*kernel.BrowserNewResponse, error) {
+			browserProfile = body.Profile.Name.Value
+			return &kernel.BrowserNewResponse{SessionID: "sess-1", BrowserLiveViewURL: "https://live.example/sess-1"}, nil
+		}},
+		openURL: func(u string) error { opened = append(opened, u); return nil },
+	}
+	p := &scriptedPrompter{t: t, texts: []string{"me@example.com"}, secrets: []string{"hunter2"}}
+
+	err := q.Auth(context.Background(), p, QuickstartAuthInput{Domain: "https://Example.com/login", PollInterval: time.Millisecond})
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", createdProfile)
+	assert.Equal(t, map[string]string{"username": "me@example.com", "password": "hunter2"}, credValues)
+	assert.Equal(t, "example.com", createdConnProfile)
+	assert.Equal(t, "example.com", browserProfile)
+	assert.Equal(t, []string{"https://live.example/sess-1"}, opened)
+	assert.NotContains(t, outBuf.String(), "hunter2")
+}
+
+func TestQuickstartAuth_ReusesExistingAndSkipsLogin(t *testing.T) {
+	setupStdoutCapture(t)
+	logins := 0
+	q := QuickstartCmd{
+		profiles:    &FakeProfilesService{},
+		credentials: &FakeCredentialsService{},
+		auth: AuthConnectionCmd{svc: &FakeAuthConnectionService{
+			ListFunc: func(ctx context.Context, query kernel.AuthConnectionListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.ManagedAuth], error) {
+				return &pagination.OffsetPagination[kernel.ManagedAuth]{Items: []kernel.ManagedAuth{{ID: "conn-1", Status: kernel.ManagedAuthStatusAuthenticated}}}, nil
+			},
+			LoginFunc: func(ctx context.Context, id string, body kernel.AuthConnectionLoginParams, opts ...option.RequestOption) (*kernel.LoginResponse, error) {
+				logins++
+				return &kernel.LoginResponse{}, nil
+			},
+		}},
+		browsers: &FakeBrowsersService{NewFunc: func(ctx context.Context, body kernel.BrowserNewParams, opts ...option.RequestOption) (*kernel.BrowserNewResponse, error) {
+			return &kernel.BrowserNewResponse{SessionID: "sess-1"}, nil
+		}},
+		openURL: func(string) error { return nil },
+	}
+	// No prompts are expected: the credential already exists.
+	p := &scriptedPrompter{t: t}
+
+	err := q.Auth(context.Background(), p, QuickstartAuthInput{Domain: "example.com"})
+	require.NoError(t, err)
+	assert.Zero(t, logins)
+	assert.Contains(t, outBuf.String(), "Already logged in")
+}
+
+func TestNormalizeQuickstartDomain(t *testing.T) {
+	for in, want := range map[string]string{
+		"example.com":                "example.com",
+		"Example.COM":                "example.com",
+		"https://app.example.com/in": "app.example.com",
+	} {
+		got, err := normalizeQuickstartDomain(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got)
+	}
+	_, err := normalizeQuickstartDomain("example.com/login")
+	assert.Error(t, err)
+}
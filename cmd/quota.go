@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/kernel/kernel-go-sdk/packages/pagination"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+const quotaPageSize = 100
+
+// InvocationLister is the subset of the invocations client that quota uses.
+type InvocationLister interface {
+	List(ctx context.Context, query kernel.InvocationListParams, opts ...option.RequestOption) (res *pagination.OffsetPagination[kernel.InvocationListResponse], err error)
+}
+
+// OrgLimitsGetter reads the organization's limits.
+type OrgLimitsGetter interface {
+	Get(ctx context.Context, opts ...option.RequestOption) (res *kernel.OrgLimits, err error)
+}
+
+// QuotaCmd reports concurrency limits next to current usage.
+type QuotaCmd struct {
+	browsers      BrowsersService
+	pools         BrowserPoolsService
+	invocations   InvocationLister
+	orgLimits     OrgLimitsGetter
+	projects      ProjectListService
+	projectLimits ProjectLimitsService
+}
+
+type QuotaInput struct {
+	// Project, when set, adds the project's limits.
+	Project string
+	// WarnAt is the usage percentage at or above which the command fails.
+	WarnAt float64
+	Output string
+}
+
+type quotaRow struct {
+	Name  string `json:"name"`
+	Scope string `json:"scope"`
+	Used  int64  `json:"used"`
+	// Limit is nil when there is no cap.
+	Limit   *int64   `json:"limit"`
+	Percent *float64 `json:"percent"`
+	Over    bool     `json:"over"`
+}
+
+func newQuotaRow(name, scope string, used int64, limit *int64, warnAt float64) quotaRow {
+	row := quotaRow{Name: name, Scope: scope, Used: used, Limit: limit}
+	if limit != nil && *limit > 0 {
+		pct := float64(used) / float64(*limit) * 100
+		row.Percent = &pct
+		row.Over = pct >= warnAt
+	}
+	return row
+}
+
+// Quota shows the organization's or, with a project, the project's
+// concurrency limits against what is running now. Requests are already
+// scoped to the selected project, so the counts are the project's. It returns an error when
+// any usage is at or above WarnAt percent, so scripts can use it as a
+// pre-flight check. The API exposes no usage-based quotas (invocation
+// minutes, storage), so only concurrency is covered.
+func (q QuotaCmd) Quota(ctx context.Context, in QuotaInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+
+	org, err := q.orgLimits.Get(ctx)
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	browsersUsed, err := q.countBrowsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	var rows []quotaRow
+	scope := "organization"
+	if in.Project == "" {
+		rows = append(rows, newQuotaRow("Concurrent browsers", scope, browsersUsed, limitOrNil(org.MaxConcurrentSessions, org.JSON.MaxConcurrentSessions.Valid()), in.WarnAt))
+	} else {
+		projectID, err := resolveProjectArg(ctx, q.projects, in.Project)
+		if err != nil {
+			return err
+		}
+		limits, err := q.projectLimits.Get(ctx, projectID)
+		if err != nil {
+			return util.CleanedUpSdkError{Err: err}
+		}
+		scope = "project " + in.Project
+		// Without a project override the org-wide default applies.
+		browserLimit := limitOrNil(org.DefaultProjectMaxConcurrentSessions, org.JSON.DefaultProjectMaxConcurrentSessions.Valid())
+		var invocationLimit *int64
+		if limits != nil {
+			if l := limitOrNil(limits.MaxConcurrentSessions, limits.JSON.MaxConcurrentSessions.Valid()); l != nil {
+				browserLimit = l
+			}
+			invocationLimit = limitOrNil(limits.MaxConcurrentInvocations, limits.JSON.MaxConcurrentInvocations.Valid())
+		}
+		invocationsUsed, err := q.countRunningInvocations(ctx)
+		if err != nil {
+			return err
+		}
+		rows = append(rows,
+			newQuotaRow("Concurrent browsers", scope, browsersUsed, browserLimit, in.WarnAt),
+			newQuotaRow("Concurrent invocations", scope, invocationsUsed, invocationLimit, in.WarnAt),
+		)
+	}
+
+	var over []string
+	for _, r := range rows {
+		if r.Over {
+			over = append(over, fmt.Sprintf("%s (%s) at %.0f%%", strings.ToLower(r.Name), r.Scope, *r.Percent))
+		}
+	}
+
+	if in.Output == "json" {
+		if err := util.PrintJSON(map[string]any{"quotas": rows, "warn_at": in.WarnAt}); err != nil {
+			return err
+		}
+	} else {
+		tableData := pterm.TableData{{"Quota", "Scope", "Used", "Limit", "Usage"}}
+		for _, r := range rows {
+			used := strconv.FormatInt(r.Used, 10)
+			limit, usage := "unlimited", "-"
+			if r.Limit != nil {
+				limit = strconv.FormatInt(*r.Limit, 10)
+			}
+			if r.Percent != nil {
+				usage = fmt.Sprintf("%.0f%%", *r.Percent)
+				if r.Over {
+					usage = pterm.Red(usage)
+				}
+			}
+			tableData = append(tableData, []string{r.Name, r.Scope, used, limit, usage})
+		}
+		PrintTableNoPad(tableData, true)
+	}
+
+	if len(over) > 0 {
+		return fmt.Errorf("quota at or above %.0f%%: %s", in.WarnAt, strings.Join(over, ", "))
+	}
+	return nil
+}
+
+func limitOrNil(v int64, set bool) *int64 {
+	if !set {
+		return nil
+	}
+	return &v
+}
+
+// countBrowsers counts running sessions plus the idle browsers pools keep
+// reserved, since both count toward the concurrency limit.
+func (q QuotaCmd) countBrowsers(ctx context.Context) (int64, error) {
+	var n int64
+	for offset := int64(0); ; offset += quotaPageSize {
+		page, err := q.browsers.List(ctx, kernel.BrowserListParams{
+			Status: kernel.BrowserListParamsStatusActive,
+			Limit:  kernel.Opt(int64(quotaPageSize)),
+			Offset: kernel.Opt(offset),
+		})
+		if err != nil {
+			return 0, util.CleanedUpSdkError{Err: err}
+		}
+		if page == nil {
+			break
+		}
+		n += int64(len(page.Items))
+		if len(page.Items) < quotaPageSize {
+			break
+		}
+	}
+	for offset := int64(0); ; offset += quotaPageSize {
+		page, err := q.pools.List(ctx, kernel.BrowserPoolListParams{Limit: kernel.Opt(int64(quotaPageSize)), Offset: kernel.Opt(offset)})
+		if err != nil {
+			return 0, util.CleanedUpSdkError{Err: err}
+		}
+		if page == nil {
+			break
+		}
+		for _, p := range page.Items {
+			n += p.AvailableCount
+		}
+		if len(page.Items) < quotaPageSize {
+			break
+		}
+	}
+	return n, nil
+}
+
+func (q QuotaCmd) countRunningInvocations(ctx context.Context) (int64, error) {
+	var n int64
+	for offset := int64(0); ; offset += quotaPageSize {
+		page, err := q.invocations.List(ctx, kernel.InvocationListParams{
+			Status: kernel.InvocationListParamsStatusRunning,
+			Limit:  kernel.Opt(int64(quotaPageSize)),
+			Offset: kernel.Opt(offset),
+		})
+		if err != nil {
+			return 0, util.CleanedUpSdkError{Err: err}
+		}
+		if page == nil {
+			break
+		}
+		n += int64(len(page.Items))
+		if len(page.Items) < quotaPageSize {
+			break
+		}
+	}
+	return n, nil
+}
+
+// parseWarnAt accepts a percentage such as "90%" or "90".
+func parseWarnAt(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+	if err != nil || v <= 0 || v > 100 {
+		return 0, fmt.Errorf("invalid --warn-at %q: expected a percentage between 0 and 100, e.g. 90%%", s)
+	}
+	return v, nil
+}
+
+var quotaCmd = &cobra.Command{
+	Use:   "quota",
+	Short: "Show concurrency limits and current usage",
+	Long: `Show the concurrency limits that apply to you next to what is running now:
+browsers (including idle pool browsers) and, with --project, invocations.
+
+Exits non-zero when any usage is at or above --warn-at, so it can gate
+automation. The API has no usage-based quotas such as invocation minutes or
+storage, so those aren't shown.`,
+	Example: `  kernel quota
+  kernel quota --project staging --warn-at 80% -o json`,
+	Args: cobra.NoArgs,
+	RunE: runQuota,
+}
+
+func init() {
+	quotaCmd.Flags().String("warn-at", "90%", "Fail when any usage is at or above this percentage")
+	addJSONOutputFlag(quotaCmd)
+}
+
+func runQuota(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	warnAtRaw, _ := cmd.Flags().GetString("warn-at")
+	output, _ := cmd.Flags().GetString("output")
+	projectFlag, _ := cmd.Flags().GetString("project")
+	warnAt, err := parseWarnAt(warnAtRaw)
+	if err != nil {
+		return err
+	}
+
+	q := QuotaCmd{
+		browsers:      &client.Browsers,
+		pools:         &client.BrowserPools,
+		invocations:   &client.Invocations,
+		orgLimits:     &client.Organization.Limits,
+		projects:      &client.Projects,
+		projectLimits: &client.Projects.Limits,
+	}
+	return q.Quota(cmd.Context(), QuotaInput{Project: resolveProjectSelection(projectFlag), WarnAt: warnAt, Output: output})
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/kernel/kernel-go-sdk/packages/pagination"
+	"github.com/kernel/kernel-go-sdk/packages/respjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeInvocationLister struct {
+	items []kernel.InvocationListResponse
+}
+
+func (f *fakeInvocationLister) List(ctx context.Context, query kernel.InvocationListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.InvocationListResponse], error) {
+	return &pagination.OffsetPagination[kernel.InvocationListResponse]{Items: f.items}, nil
+}
+
+type fakeOrgLimits struct {
+	limits kernel.OrgLimits
+}
+
+func (f *fakeOrgLimits) Get(ctx context.Context, opts ...option.RequestOption) (*kernel.OrgLimits, error) {
+	return &f.limits, nil
+}
+
+func quotaTestCmd(activeBrowsers int, poolAvailable int64, maxSessions int64) QuotaCmd {
+	org := kernel.OrgLimits{MaxConcurrentSessions: maxSessions}
+	org.JSON.MaxConcurrentSessions = respjson.NewField("set")
+	return QuotaCmd{
+		browsers: &FakeBrowsersService{
+			ListFunc: func(ctx context.Context, query kernel.BrowserListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.BrowserListResponse], error) {
+				return &pagination.OffsetPagination[kernel.BrowserListResponse]{Items: make([]kernel.BrowserListResponse, activeBrowsers)}, nil
+			},
+		},
+		pools: &FakeBrowserPoolsService{
+			ListFunc: func(ctx context.Context, query kernel.BrowserPoolListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.BrowserPool], error) {
+				return &pagination.OffsetPagination[kernel.BrowserPool]{Items: []kernel.BrowserPool{{AvailableCount: poolAvailable}}}, nil
+			},
+		},
+		invocations:   &fakeInvocationLister{},
+		orgLimits:     &fakeOrgLimits{limits: org},
+		projects:      &FakeProjectsService{},
+		projectLimits: &FakeProjectLimitsService{},
+	}
+}
+
+func TestQuota_BelowThreshold(t *testing.T) {
+	setupStdoutCapture(t)
+	q := quotaTestCmd(3, 2, 10)
+
+	err := q.Quota(context.Background(), QuotaInput{WarnAt: 90})
+	require.NoError(t, err)
+	out := outBuf.String()
+	assert.Contains(t, out, "Concurrent browsers")
+	assert.Contains(t, out, "50%")
+}
+
+func TestQuota_AtThresholdFails(t *testing.T) {
+	setupStdoutCapture(t)
+	q := quotaTestCmd(8, 1, 10)
+
+	err := q.Quota(context.Background(), QuotaInput{WarnAt: 90})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "concurrent browsers (organization) at 90%")
+}
+
+func TestQuota_ProjectLimits(t *testing.T) {
+	setupStdoutCapture(t)
+	q := quotaTestCmd(1, 0, 100)
+	q.invocations = &fakeInvocationLister{items: make([]kernel.InvocationListResponse, 4)}
+	q.projectLimits = &FakeProjectLimitsService{
+		GetFunc: func(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.ProjectLimits, error) {
+			assert.Equal(t, "cm1234567890abcdefghijkl", id)
+			limits := &kernel.ProjectLimits{MaxConcurrentInvocations: 4}
+			limits.JSON.MaxConcurrentInvocations = respjson.NewField("4")
+			return limits, nil
+		},
+	}
+
+	err := q.Quota(context.Background(), QuotaInput{Project: "cm1234567890abcdefghijkl", WarnAt: 90})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "concurrent invocations")
+	assert.NotContains(t, err.Error(), "browsers")
+	assert.Contains(t, outBuf.String(), "unlimited")
+}
+
+func TestParseWarnAt(t *testing.T) {
+	v, err := parseWarnAt("85%")
+	require.NoError(t, err)
+	assert.Equal(t, 85.0, v)
+	v, err = parseWarnAt("90")
+	require.NoError(t, err)
+	assert.Equal(t, 90.0, v)
+	_, err = parseWarnAt("150%")
+	assert.Error(t, err)
+	_, err = parseWarnAt("lots")
+	assert.Error(t, err)
+}
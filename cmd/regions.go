@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// regionHealth is the result of probing one configured API endpoint.
+type regionHealth struct {
+	Endpoint  string `json:"endpoint"`
+	Role      string `json:"role"`
+	Healthy   bool   `json:"healthy"`
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+var regionsCmd = &cobra.Command{
+	Use:   "regions",
+	Short: "Inspect configured API endpoints",
+	Long:  "Inspect the API endpoints configured via KERNEL_BASE_URL. A comma-separated list enables automatic failover of read requests to the fallback endpoints.",
+}
+
+var regionsStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show health and latency for each configured API endpoint",
+	Args:  cobra.NoArgs,
+	RunE:  runRegionsStatus,
+}
+
+func init() {
+	addJSONOutputFlag(regionsStatusCmd)
+	regionsStatusCmd.Flags().Duration("timeout", 5*time.Second, "Per-endpoint health check timeout")
+	regionsCmd.AddCommand(regionsStatusCmd)
+}
+
+func runRegionsStatus(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	if err := validateJSONOutput(output); err != nil {
+		return err
+	}
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+
+	results := probeRegions(cmd.Context(), &http.Client{Timeout: timeout}, util.GetBaseURLs())
+
+	if output == "json" {
+		return util.PrintJSON(results)
+	}
+
+	rows := pterm.TableData{{"Endpoint", "Role", "Status", "Latency"}}
+	for _, r := range results {
+		status := pterm.Green(r.Status)
+		if !r.Healthy {
+			status = pterm.Red(r.Status)
+		}
+		rows = append(rows, []string{r.Endpoint, r.Role, status, fmt.Sprintf("%dms", r.LatencyMs)})
+	}
+	PrintTableNoPad(rows, true)
+	return nil
+}
+
+// probeRegions checks {base}/health on every endpoint concurrently, returning
+// results in the configured priority order.
+func probeRegions(ctx context.Context, client *http.Client, baseURLs []string) []regionHealth {
+	results := make([]regionHealth, len(baseURLs))
+	var wg sync.WaitGroup
+	for i, base := range baseURLs {
+		role := "fallback"
+		if i == 0 {
+			role = "primary"
+		}
+		results[i] = regionHealth{Endpoint: base, Role: role}
+		wg.Add(1)
+		go func(r *regionHealth) {
+			defer wg.Done()
+			probeRegion(ctx, client, r)
+		}(&results[i])
+	}
+	wg.Wait()
+	return results
+}
+
+func probeRegion(ctx context.Context, client *http.Client, r *regionHealth) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.Endpoint+"/health", nil)
+	if err != nil {
+		r.Status = err.Error()
+		return
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	r.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		r.Status = "unreachable"
+		return
+	}
+	resp.Body.Close()
+	r.Status = resp.Status
+	r.Healthy = resp.StatusCode >= 200 && resp.StatusCode < 300
+}
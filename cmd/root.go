@@ -15,6 +15,7 @@ import (
 	"github.com/kernel/cli/cmd/mcp"
 	"github.com/kernel/cli/cmd/proxies"
 	"github.com/kernel/cli/pkg/auth"
+	"github.com/kernel/cli/pkg/config"
 	"github.com/kernel/cli/pkg/table"
 	"github.com/kernel/cli/pkg/update"
 	"github.com/kernel/cli/pkg/util"
@@ -78,6 +79,36 @@ func getKernelClient(cmd *cobra.Command) kernel.Client {
 	return util.GetKernelClient(cmd)
 }
 
+// newAuthenticatedClient builds the API client commands use: CLI version and
+// project headers, regional failover and timings, with API key or OAuth auth.
+func newAuthenticatedClient(cmd *cobra.Command) (*kernel.Client, error) {
+	clientOpts := []option.RequestOption{
+		option.WithHeader("X-Kernel-Cli-Version", metadata.Version),
+	}
+
+	// KERNEL_BASE_URL may list several regional endpoints; pin the SDK to the
+	// primary and fail idempotent reads over to the rest.
+	if baseURLs := util.GetBaseURLs(); len(baseURLs) > 1 {
+		clientOpts = append(clientOpts,
+			option.WithBaseURL(baseURLs[0]),
+			option.WithMiddleware(util.FailoverMiddleware(baseURLs)),
+		)
+	}
+
+	if apiTimings != nil {
+		clientOpts = append(clientOpts, option.WithMiddleware(apiTimings.Middleware()))
+	}
+
+	projectVal, _ := cmd.Flags().GetString("project")
+	projectVal = resolveProjectSelection(projectVal)
+
+	if projectVal != "" {
+		clientOpts = append(clientOpts, option.WithHeader("X-Kernel-Project-Id", projectVal))
+	}
+
+	return auth.GetAuthenticatedClient(clientOpts...)
+}
+
 // isAuthExempt returns true if the command should skip auth.
 func isAuthExempt(cmd *cobra.Command) bool {
 	// Root command doesn't need auth
@@ -85,24 +116,65 @@ func isAuthExempt(cmd *cobra.Command) bool {
 		return true
 	}
 
-	// Walk up to find the top-level command (direct child of rootCmd)
-	topLevel := cmd
-	for topLevel.Parent() != nil && topLevel.Parent() != rootCmd {
-		topLevel = topLevel.Parent()
-	}
+	topLevel := topLevelCommand(cmd)
 
 	// Check if the top-level command is in the exempt list
 	switch topLevel.Name() {
-	case "login", "logout", "help", "completion", "create", "mcp", "upgrade", "status":
+	case "login", "logout", "help", "completion", "create", "mcp", "upgrade", "status", "regions", "version", "config", "prompt", "api", "doctor":
 		return true
 	case "auth":
-		// Only exempt the auth command itself (status display), not its subcommands
-		return cmd == topLevel
+		// Only exempt the auth command itself (status display) and the local
+		// login page probe, not the subcommands that call the API
+		return cmd == topLevel || cmd == authConnectionsDiscoverCmd
+	case "extensions":
+		// Serving and re-keying local build artifacts and generating keys
+		// doesn't touch the API
+		return cmd == extensionsServeCmd || cmd == extensionsWebBotAuthRotateKeyCmd ||
+			cmd == extensionsKeygenCmd || cmd == extensionsKeygenVerifyCmd
+	case "schedules":
+		// Schedules are stored locally; only running them calls the API
+		return cmd != schedulesRunCmd
 	}
 
 	return false
 }
 
+// topLevelCommand walks up to the direct child of rootCmd that cmd belongs to.
+func topLevelCommand(cmd *cobra.Command) *cobra.Command {
+	topLevel := cmd
+	for topLevel.Parent() != nil && topLevel.Parent() != rootCmd {
+		topLevel = topLevel.Parent()
+	}
+	return topLevel
+}
+
+// loadConfigContext loads config.yaml and applies the selected context. An
+// unparseable config.yaml only fails commands that need a context: those given
+// --context or KERNEL_CONTEXT, and those that call the API, whose credentials
+// may come from the current context. Other commands warn and go on without it.
+func loadConfigContext(cmd *cobra.Command) (*config.Config, error) {
+	switch topLevelCommand(cmd).Name() {
+	case "config", "doctor":
+		// Config commands edit contexts and doctor diagnoses config.yaml, so
+		// both must work even when it is broken.
+		return nil, nil
+	}
+	contextFlag, _ := cmd.Flags().GetString("context")
+	contextName := resolveContextSelection(contextFlag)
+	cfg, err := config.Load()
+	if err != nil {
+		if contextName != "" || !isAuthExempt(cmd) {
+			return nil, err
+		}
+		pterm.Warning.WithWriter(os.Stderr).Printf("Ignoring config: %v (run 'kernel doctor' for details)\n", err)
+		return nil, nil
+	}
+	if err := applyConfigContext(cfg, contextName); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
 func resolveProjectSelection(projectFlag string) string {
 	if projectFlag != "" {
 		return projectFlag
@@ -115,6 +187,10 @@ func init() {
 	rootCmd.PersistentFlags().BoolP("no-color", "", false, "Disable color output")
 	rootCmd.PersistentFlags().String("log-level", "warn", "Set the log level (trace, debug, info, warn, error, fatal, print)")
 	rootCmd.PersistentFlags().String("project", "", "Project ID or name to scope all requests to (or set KERNEL_PROJECT env var)")
+	rootCmd.PersistentFlags().String("context", "", "Named context from 'kernel config' to use for this command (or set KERNEL_CONTEXT env var)")
+	_ = rootCmd.RegisterFlagCompletionFunc("context", completeContextName)
+	rootCmd.PersistentFlags().Bool("timings", false, "Print how many API calls the command made and how long they took (or set KERNEL_TIMINGS=1, or timings: true in config.yaml)")
+	rootCmd.PersistentFlags().Bool("strict-decode", false, "Warn on stderr when API responses contain unknown fields or omit required ones, in any output format")
 	rootCmd.SilenceUsage = true
 	rootCmd.SilenceErrors = true
 	cobra.OnInitialize(initConfig)
@@ -127,25 +203,32 @@ func init() {
 		if noColor, _ := cmd.Flags().GetBool("no-color"); noColor {
 			pterm.DisableStyling()
 		}
-
-		// Skip auth check for commands that don't need it (including children, e.g., "completion zsh")
-		if isAuthExempt(cmd) {
-			return nil
+		strictDecode, _ := cmd.Flags().GetBool("strict-decode")
+		util.SetStrictDecode(strictDecode)
+		if err := util.ApplyOutputTemplate(cmd); err != nil {
+			return err
 		}
 
-		clientOpts := []option.RequestOption{
-			option.WithHeader("X-Kernel-Cli-Version", metadata.Version),
+		cfg, err := loadConfigContext(cmd)
+		if err != nil {
+			return err
+		}
+		if timingsEnabled(cmd, cfg) {
+			apiTimings = &util.CallTimings{}
 		}
 
-		projectVal, _ := cmd.Flags().GetString("project")
-		projectVal = resolveProjectSelection(projectVal)
-
-		if projectVal != "" {
-			clientOpts = append(clientOpts, option.WithHeader("X-Kernel-Project-Id", projectVal))
+		// Skip auth check for commands that don't need it (including children, e.g., "completion zsh")
+		if isAuthExempt(cmd) {
+			return nil
 		}
 
-		client, err := auth.GetAuthenticatedClient(clientOpts...)
+		client, err := newAuthenticatedClient(cmd)
 		if err != nil {
+			// Completing commands and flags works logged out; only resource
+			// IDs need the API.
+			if cmd.Name() == cobra.ShellCompRequestCmd {
+				return nil
+			}
 			return fmt.Errorf("authentication required: %w", err)
 		}
 
@@ -157,8 +240,14 @@ func init() {
 	// Register subcommands
 	rootCmd.AddCommand(deployCmd)
 	rootCmd.AddCommand(invokeCmd)
+	rootCmd.AddCommand(waitCmd)
+	rootCmd.AddCommand(loadtestCmd)
+	rootCmd.AddCommand(serveCmd)
 	rootCmd.AddCommand(browsersCmd)
 	rootCmd.AddCommand(browserPoolsCmd)
+	rootCmd.AddCommand(sandboxCmd)
+	rootCmd.AddCommand(quickstartCmd)
+	rootCmd.AddCommand(sshKeysCmd)
 	rootCmd.AddCommand(appCmd)
 	rootCmd.AddCommand(profilesCmd)
 	rootCmd.AddCommand(proxies.ProxiesCmd)
@@ -170,8 +259,16 @@ func init() {
 	rootCmd.AddCommand(mcp.MCPCmd)
 	rootCmd.AddCommand(upgradeCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(quotaCmd)
+	rootCmd.AddCommand(execOnCmd)
+	rootCmd.AddCommand(promptCmd)
+	rootCmd.AddCommand(regionsCmd)
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(apiCmd)
+	rootCmd.AddCommand(configCmd)
 
 	rootCmd.PersistentPostRunE = func(cmd *cobra.Command, args []string) error {
+		printAPITimings(cmd, apiTimings)
 		// running synchronously so we never slow the command
 		update.MaybeShowMessage(cmd.Context(), metadata.Version, 24*time.Hour)
 		return nil
@@ -252,6 +349,10 @@ func Execute(m Metadata) {
 		}),
 	); err != nil {
 		// fang takes care of printing the error
+		var coded interface{ ExitCode() int }
+		if errors.As(err, &coded) {
+			os.Exit(coded.ExitCode())
+		}
 		os.Exit(1)
 	}
 }
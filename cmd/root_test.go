@@ -1,10 +1,16 @@
 package cmd
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
 )
 
 func TestIsAuthExempt(t *testing.T) {
@@ -33,6 +39,66 @@ func TestIsAuthExempt(t *testing.T) {
 			cmd:      createCmd,
 			expected: true,
 		},
+		{
+			name:     "config subcommands are exempt",
+			cmd:      configSetContextCmd,
+			expected: true,
+		},
+		{
+			name:     "auth connections discover is exempt",
+			cmd:      authConnectionsDiscoverCmd,
+			expected: true,
+		},
+		{
+			name:     "prompt is exempt so it stays quiet when logged out",
+			cmd:      promptCmd,
+			expected: true,
+		},
+		{
+			name:     "api coverage is exempt since it only inspects the binary",
+			cmd:      apiCoverageCmd,
+			expected: true,
+		},
+		{
+			name:     "extensions serve is exempt since it only serves local files",
+			cmd:      extensionsServeCmd,
+			expected: true,
+		},
+		{
+			name:     "extensions webbotauth rotate-key is exempt since it only edits local files",
+			cmd:      extensionsWebBotAuthRotateKeyCmd,
+			expected: true,
+		},
+		{
+			name:     "extensions keygen is exempt since it only writes local files",
+			cmd:      extensionsKeygenCmd,
+			expected: true,
+		},
+		{
+			name:     "extensions keygen verify is exempt since it only reads local files",
+			cmd:      extensionsKeygenVerifyCmd,
+			expected: true,
+		},
+		{
+			name:     "schedules list is exempt since schedules are stored locally",
+			cmd:      schedulesListCmd,
+			expected: true,
+		},
+		{
+			name:     "schedules run requires auth",
+			cmd:      schedulesRunCmd,
+			expected: false,
+		},
+		{
+			name:     "extensions list requires auth",
+			cmd:      extensionsListCmd,
+			expected: false,
+		},
+		{
+			name:     "auth connections create requires auth",
+			cmd:      authConnectionsCreateCmd,
+			expected: false,
+		},
 		{
 			name:     "browser-pools create subcommand requires auth",
 			cmd:      browserPoolsCreateCmd,
@@ -94,3 +160,68 @@ func TestResolveProjectSelection(t *testing.T) {
 		assert.Equal(t, "", resolveProjectSelection(""))
 	})
 }
+
+// executeRoot runs the real command tree with args, resetting the flags it
+// set so later tests start clean.
+func executeRoot(t *testing.T, args ...string) error {
+	t.Helper()
+	t.Setenv("KERNEL_NO_UPDATE_CHECK", "1")
+	t.Cleanup(func() {
+		reset := func(f *pflag.Flag) {
+			if f.Changed {
+				_ = f.Value.Set(f.DefValue)
+				f.Changed = false
+			}
+		}
+		rootCmd.PersistentFlags().VisitAll(reset)
+		if c, _, err := rootCmd.Find(args); err == nil {
+			c.Flags().VisitAll(reset)
+		}
+		rootCmd.SetArgs(nil)
+	})
+	rootCmd.SetArgs(args)
+	return rootCmd.Execute()
+}
+
+func TestCorruptConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("KERNEL_CONTEXT", "")
+	keyring.MockInit()
+	configPath := filepath.Join(home, ".config", "kernel", "config.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0o700))
+	require.NoError(t, os.WriteFile(configPath, []byte("contexts: [\n"), 0o600))
+
+	t.Run("doctor reports it", func(t *testing.T) {
+		capturePtermOutput(t)
+		var err error
+		out := captureStdout(t, func() {
+			err = executeRoot(t, "doctor", "-o", "json")
+		})
+		assert.ErrorContains(t, err, "check(s) failed")
+		var checks []map[string]any
+		require.NoError(t, json.Unmarshal([]byte(out), &checks))
+		var found bool
+		for _, c := range checks {
+			if c["name"] == "config.yaml" {
+				found = true
+				assert.Equal(t, "fail", c["status"])
+				assert.Contains(t, c["detail"], "did not find expected node content")
+			}
+		}
+		assert.True(t, found, "no config.yaml check in %s", out)
+	})
+
+	t.Run("auth-exempt commands only warn", func(t *testing.T) {
+		capturePtermOutput(t)
+		captureStdout(t, func() {
+			require.NoError(t, executeRoot(t, "version"))
+		})
+	})
+
+	t.Run("an explicit context still fails", func(t *testing.T) {
+		capturePtermOutput(t)
+		err := executeRoot(t, "--context", "staging", "version")
+		assert.ErrorContains(t, err, "did not find expected node content")
+	})
+}
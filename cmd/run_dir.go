@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kernel/cli/pkg/rundir"
+	"github.com/spf13/cobra"
+)
+
+// addRunDirFlag adds --run-dir to a composite command. Given without a value
+// it records under .kernel, the conventional root.
+func addRunDirFlag(cmd *cobra.Command) {
+	cmd.Flags().String("run-dir", "", "Record this run (manifest, events, logs and results) in <dir>/runs/<run-id>/; --run-dir alone uses .kernel")
+	cmd.Flags().Lookup("run-dir").NoOptDefVal = rundir.DefaultRoot
+}
+
+// startRunDir starts recording the run when --run-dir is set and puts it in
+// the command's context, where the code that follows the run finds it with
+// rundir.FromContext. It returns nil without --run-dir. Only positional
+// arguments are recorded, since flags may carry secrets.
+func startRunDir(cmd *cobra.Command, args []string) (*rundir.Run, error) {
+	if !cmd.Flags().Changed("run-dir") {
+		return nil, nil
+	}
+	root, _ := cmd.Flags().GetString("run-dir")
+	run, err := rundir.Start(root, cmd.CommandPath(), args, metadata.Version)
+	if err != nil {
+		return nil, err
+	}
+	cmd.SetContext(rundir.NewContext(cmd.Context(), run))
+	return run, nil
+}
+
+// finishRunDir records how the run ended and says where the record is, on
+// stderr so JSON output stays clean.
+func finishRunDir(run *rundir.Run, runErr error) {
+	if run == nil {
+		return
+	}
+	if err := run.Finish(runErr); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write run record: %v\n", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Run recorded in %s\n", run.Dir)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kernel/cli/pkg/rundir"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runDirTestCmd(t *testing.T, flags ...string) *cobra.Command {
+	t.Helper()
+	root := &cobra.Command{Use: "kernel"}
+	cmd := &cobra.Command{Use: "invoke"}
+	root.AddCommand(cmd)
+	addRunDirFlag(cmd)
+	require.NoError(t, cmd.ParseFlags(flags))
+	cmd.SetContext(context.Background())
+	return cmd
+}
+
+func TestStartRunDir_WithoutFlagRecordsNothing(t *testing.T) {
+	cmd := runDirTestCmd(t)
+	run, err := startRunDir(cmd, []string{"app", "act"})
+	require.NoError(t, err)
+	assert.Nil(t, run)
+	assert.Nil(t, rundir.FromContext(cmd.Context()))
+}
+
+func TestStartRunDir_RecordsRun(t *testing.T) {
+	root := t.TempDir()
+	cmd := runDirTestCmd(t, "--run-dir="+root)
+	run, err := startRunDir(cmd, []string{"app", "act"})
+	require.NoError(t, err)
+	require.NotNil(t, run)
+	assert.Same(t, run, rundir.FromContext(cmd.Context()))
+	assert.Equal(t, filepath.Join(root, "runs"), filepath.Dir(run.Dir))
+
+	run.Set("invocation_id", "inv_1")
+	finishRunDir(run, errors.New("invocation failed"))
+
+	b, err := os.ReadFile(filepath.Join(run.Dir, rundir.ManifestFile))
+	require.NoError(t, err)
+	var m rundir.Manifest
+	require.NoError(t, json.Unmarshal(b, &m))
+	assert.Equal(t, "kernel invoke", m.Command)
+	assert.Equal(t, []string{"app", "act"}, m.Args)
+	assert.Equal(t, rundir.StatusFailed, m.Status)
+	assert.Equal(t, "inv_1", m.Metadata["invocation_id"])
+}
+
+func TestRunDirFlag_DefaultsToConventionalRoot(t *testing.T) {
+	cmd := runDirTestCmd(t, "--run-dir")
+	root, _ := cmd.Flags().GetString("run-dir")
+	assert.Equal(t, ".kernel", root)
+}
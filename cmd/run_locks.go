@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kernel/cli/pkg/lock"
+	"github.com/kernel/cli/pkg/util"
+	"github.com/pterm/pterm"
+)
+
+// runLockTTL bounds how long a run that died without releasing its lock
+// keeps blocking others; live runs refresh the lock every runLockRefresh.
+const runLockTTL = 2 * time.Minute
+
+var runLockRefresh = 30 * time.Second
+
+// runGuard holds a local advisory lock on a resource for the duration of one
+// command run, so a second run against the same resource on this machine
+// fails fast instead of interleaving with the first.
+type runGuard struct {
+	store    *lock.Store
+	resource string
+	opts     lock.Options
+	cancel   context.CancelCauseFunc
+	// expiresAt is when the lock lapses unless refreshed again.
+	expiresAt time.Time
+	// lost is set by refresh before done is closed.
+	lost error
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func newRunID() string {
+	var b [6]byte
+	_, _ = rand.Read(b[:])
+	return "run_" + hex.EncodeToString(b[:])
+}
+
+// acquireRunGuard locks resource for this run. what describes the run in the
+// error another run gets, e.g. "deploy of ./app". The run should go on under
+// the returned context, which is canceled if the lock is lost. A nil store
+// disables the guard.
+func acquireRunGuard(ctx context.Context, store *lock.Store, resource, what string) (*runGuard, context.Context, error) {
+	if store == nil {
+		return nil, ctx, nil
+	}
+	runID := newRunID()
+	opts := lock.Options{
+		TTL: runLockTTL,
+		// Every run is its own owner so runs by the same user still contend.
+		Owner: lock.DefaultOwner() + "#" + runID,
+		Note:  what,
+		RunID: runID,
+	}
+	held, err := store.Acquire(resource, opts)
+	if err != nil {
+		var held *lock.HeldError
+		if errors.As(err, &held) {
+			return nil, ctx, runInProgressError(held.Lock)
+		}
+		return nil, ctx, fmt.Errorf("lock %s: %w", resource, err)
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	g := &runGuard{store: store, resource: resource, opts: opts, cancel: cancel, expiresAt: held.ExpiresAt, stop: make(chan struct{}), done: make(chan struct{})}
+	go g.refresh()
+	return g, ctx, nil
+}
+
+func runInProgressError(l lock.Lock) error {
+	what := util.FirstOrDash(l.Note, l.Resource)
+	return fmt.Errorf("another %s is already running (run %s, pid %d, started %s); wait for it to finish, or up to %s after it exits uncleanly",
+		what, util.FirstOrDash(l.RunID), l.PID, util.FormatLocal(l.AcquiredAt), runLockTTL)
+}
+
+// refresh extends the lock until the run ends. If the lock was released or
+// taken over meanwhile, e.g. after this process was suspended past the TTL,
+// another run may be going on, so this one is stopped.
+func (g *runGuard) refresh() {
+	defer close(g.done)
+	ticker := time.NewTicker(runLockRefresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-g.stop:
+			return
+		case <-ticker.C:
+			held, err := g.store.Refresh(g.resource, g.opts.Owner, g.opts.TTL)
+			var lost *lock.LostError
+			switch {
+			case err == nil:
+				g.expiresAt = held.ExpiresAt
+			case errors.As(err, &lost):
+				g.lost = fmt.Errorf("stopped %s: %w", g.opts.Note, err)
+				pterm.Error.WithWriter(os.Stderr).Println(g.lost)
+				g.cancel(g.lost)
+				return
+			case err != nil:
+				pterm.Warning.WithWriter(os.Stderr).Printf("Failed to refresh the lock on %s; another run may start after %s: %v\n",
+					g.resource, util.FormatLocal(g.expiresAt), err)
+			}
+		}
+	}
+}
+
+// Release stops refreshing and drops the lock. It returns runErr, the error
+// the run ended with, unless the run lost its lock, which is returned
+// instead. It is safe on a nil guard.
+func (g *runGuard) Release(runErr error) error {
+	if g == nil {
+		return runErr
+	}
+	g.stopOnce.Do(func() { close(g.stop) })
+	<-g.done
+	g.cancel(nil)
+	if g.lost != nil {
+		return g.lost
+	}
+	if err := g.store.Release(g.resource, g.opts.Owner, false); err != nil {
+		pterm.Debug.Printf("Failed to release %s: %v\n", g.resource, err)
+	}
+	return runErr
+}
+
+// deployRunLock returns the lock resource and description for a deploy. Two
+// deploys of one app version contend even from different checkouts; the
+// source location only keys the lock when the app's name isn't known.
+func deployRunLock(app, version, source string) (resource, what string) {
+	if app == "" {
+		return lock.DeployResource(source), "deploy of " + source
+	}
+	return lock.AppDeployResource(app, version), fmt.Sprintf("deploy of \"%s\" (version: %s)", app, version)
+}
+
+// defaultRunLockStore returns the default lock store, or nil if it is
+// unavailable (the guard is best-effort in that case).
+func defaultRunLockStore() *lock.Store {
+	store, err := lock.DefaultStore()
+	if err != nil {
+		pterm.Debug.Printf("Run locking disabled: %v\n", err)
+		return nil
+	}
+	return store
+}
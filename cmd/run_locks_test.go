@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kernel/cli/pkg/lock"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunGuard_SecondRunFailsWithFirstRunID(t *testing.T) {
+	store := &lock.Store{Dir: t.TempDir()}
+
+	first, _, err := acquireRunGuard(context.Background(), store, lock.DeployResource("/src/app"), "deploy of /src/app")
+	require.NoError(t, err)
+	held, err := store.Get(lock.DeployResource("/src/app"))
+	require.NoError(t, err)
+	require.NotNil(t, held)
+
+	_, _, err = acquireRunGuard(context.Background(), store, lock.DeployResource("/src/app"), "deploy of /src/app")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "another deploy of /src/app is already running (run "+held.RunID)
+
+	// Other resources are independent.
+	other, _, err := acquireRunGuard(context.Background(), store, lock.DeployResource("/src/other"), "deploy of /src/other")
+	require.NoError(t, err)
+	require.NoError(t, other.Release(nil))
+
+	require.NoError(t, first.Release(nil))
+	second, _, err := acquireRunGuard(context.Background(), store, lock.DeployResource("/src/app"), "deploy of /src/app")
+	require.NoError(t, err)
+	require.NoError(t, second.Release(nil))
+
+	// A nil store disables the guard.
+	none, _, err := acquireRunGuard(context.Background(), nil, "x", "x")
+	require.NoError(t, err)
+	require.NoError(t, none.Release(nil))
+}
+
+func TestDeployRunLock_KeysOnAppWhenKnown(t *testing.T) {
+	store := &lock.Store{Dir: t.TempDir()}
+	resource, what := deployRunLock("my-app", "latest", "/checkout-a")
+	first, _, err := acquireRunGuard(context.Background(), store, resource, what)
+	require.NoError(t, err)
+
+	// The same app deployed from a second checkout contends.
+	resource, what = deployRunLock("my-app", "latest", "/checkout-b")
+	_, _, err = acquireRunGuard(context.Background(), store, resource, what)
+	assert.ErrorContains(t, err, `another deploy of "my-app" (version: latest) is already running`)
+
+	// Other versions, and sources whose app isn't known, don't.
+	resource, what = deployRunLock("my-app", "v2", "/checkout-b")
+	other, _, err := acquireRunGuard(context.Background(), store, resource, what)
+	require.NoError(t, err)
+	require.NoError(t, other.Release(nil))
+	resource, what = deployRunLock("", "latest", "/checkout-b")
+	assert.Equal(t, "deploy of /checkout-b", what)
+	other, _, err = acquireRunGuard(context.Background(), store, resource, what)
+	require.NoError(t, err)
+	require.NoError(t, other.Release(nil))
+
+	require.NoError(t, first.Release(nil))
+}
+
+func TestRunGuard_StopsTheRunWhenTheLockIsLost(t *testing.T) {
+	refresh := runLockRefresh
+	runLockRefresh = 10 * time.Millisecond
+	t.Cleanup(func() { runLockRefresh = refresh })
+	store := &lock.Store{Dir: t.TempDir()}
+	resource := lock.DeployResource("/src/app")
+	capturePtermOutput(t)
+
+	guard, ctx, err := acquireRunGuard(context.Background(), store, resource, "deploy of /src/app")
+	require.NoError(t, err)
+
+	// Another run takes the lock over, as if this one had been suspended
+	// past its TTL.
+	require.NoError(t, store.Release(resource, "", true))
+	_, err = store.Acquire(resource, lock.Options{TTL: time.Minute, Owner: "other-run"})
+	require.NoError(t, err)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("run context wasn't canceled")
+	}
+	var lost *lock.LostError
+	assert.ErrorAs(t, context.Cause(ctx), &lost)
+
+	err = guard.Release(ctx.Err())
+	assert.ErrorContains(t, err, "stopped deploy of /src/app: lost the lock on "+resource+": it is now held by other-run")
+	// The other run's lock is left alone.
+	held, err := store.Get(resource)
+	require.NoError(t, err)
+	assert.Equal(t, "other-run", held.Owner)
+}
+
+func TestAuthConnectionsFollow_FailsFastWhileAnotherFollowRuns(t *testing.T) {
+	store := &lock.Store{Dir: t.TempDir()}
+	guard, _, err := acquireRunGuard(context.Background(), store, lock.AuthConnectionResource("conn-1"), "auth connections follow of conn-1")
+	require.NoError(t, err)
+	defer guard.Release(nil)
+
+	fake := &FakeAuthConnectionService{
+		GetFunc: func(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.ManagedAuth, error) {
+			return &kernel.ManagedAuth{ID: "conn-1"}, nil
+		},
+	}
+	c := AuthConnectionCmd{svc: fake, runLocks: store}
+
+	err = c.Follow(context.Background(), AuthConnectionFollowInput{ID: "conn-1"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "another auth connections follow of conn-1 is already running")
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pkg/browser"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultSandboxTTL = 15 * time.Minute
+	// sandboxDeleteTimeout bounds the cleanup that runs after the TTL or an
+	// interrupt has already canceled the session's context.
+	sandboxDeleteTimeout = 30 * time.Second
+)
+
+var errSandboxExpired = errors.New("sandbox TTL reached")
+
+const (
+	sandboxOpen       = "Open live view"
+	sandboxExec       = "Exec a shell command"
+	sandboxScreenshot = "Take a screenshot"
+	sandboxDelete     = "Delete and exit"
+)
+
+// SandboxCmd runs a throwaway browser session behind an interactive menu.
+type SandboxCmd struct {
+	browsers BrowsersCmd
+	// choose and ask replace the interactive menu and text prompt; interrupt
+	// must be called if the user presses Ctrl+C inside one.
+	choose  func(options []string, interrupt func()) (string, error)
+	ask     func(label string, interrupt func()) (string, error)
+	openURL func(url string) error
+}
+
+type SandboxInput struct {
+	TTL time.Duration
+}
+
+func (s SandboxCmd) Run(ctx context.Context, in SandboxInput) error {
+	if in.TTL <= 0 {
+		return fmt.Errorf("--ttl must be positive")
+	}
+	if s.choose == nil {
+		s.choose = chooseSandboxAction
+	}
+	if s.ask == nil {
+		s.ask = askSandboxInput
+	}
+	if s.openURL == nil {
+		s.openURL = browser.OpenURL
+	}
+
+	// The inactivity timeout is a server-side backstop in case this process is
+	// killed before it can delete the browser.
+	timeout := min(max(int64(in.TTL.Seconds()), 10), 259200)
+	pterm.Info.Println("Creating sandbox browser...")
+	br, err := s.browsers.browsers.New(ctx, kernel.BrowserNewParams{TimeoutSeconds: kernel.Opt(timeout)})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+	defer s.delete(ctx, br.SessionID)
+
+	table := buildBrowserTableData(br.SessionID, br.CdpWsURL, br.BrowserLiveViewURL, kernel.Profile{}, "", "", nil)
+	table = append(table,
+		[]string{"SSH", "kernel browsers ssh " + br.SessionID},
+		[]string{"Expires", time.Now().Add(in.TTL).Local().Format(time.Kitchen) + " (deleted on exit)"},
+	)
+	PrintTableNoPad(table, true)
+
+	ctx, cancelTTL := context.WithTimeoutCause(ctx, in.TTL, errSandboxExpired)
+	defer cancelTTL()
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Prompts block on the terminal and can't observe ctx, so the menu runs
+	// on its own goroutine and is abandoned if the TTL or a signal fires.
+	done := make(chan error, 1)
+	go func() { done <- s.menu(ctx, br, func() { cancel(context.Canceled) }) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if errors.Is(context.Cause(ctx), errSandboxExpired) {
+			pterm.Warning.Printf("\nSandbox expired after %s\n", in.TTL)
+		} else {
+			fmt.Println()
+		}
+		return nil
+	}
+}
+
+func (s SandboxCmd) menu(ctx context.Context, br *kernel.BrowserNewResponse, interrupt func()) error {
+	for ctx.Err() == nil {
+		choice, err := s.choose([]string{sandboxOpen, sandboxExec, sandboxScreenshot, sandboxDelete}, interrupt)
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		switch choice {
+		case sandboxOpen:
+			if br.BrowserLiveViewURL == "" {
+				pterm.Warning.Println("This browser has no live view")
+			} else if err := s.openURL(br.BrowserLiveViewURL); err != nil {
+				pterm.Warning.Printf("Could not open a browser; visit %s\n", br.BrowserLiveViewURL)
+			}
+		case sandboxExec:
+			line, err := s.ask("Command", interrupt)
+			if err != nil {
+				return err
+			}
+			if line == "" || ctx.Err() != nil {
+				continue
+			}
+			if err := s.browsers.ProcessExec(ctx, BrowsersProcessExecInput{Identifier: br.SessionID, Command: "bash", Args: []string{"-lc", line}}); err != nil {
+				pterm.Error.Println(err)
+			}
+		case sandboxScreenshot:
+			to := fmt.Sprintf("sandbox-%s-%s.png", br.SessionID, time.Now().Format("20060102-150405"))
+			if err := s.browsers.ComputerScreenshot(ctx, BrowsersComputerScreenshotInput{Identifier: br.SessionID, To: to}); err != nil {
+				pterm.Error.Println(err)
+			}
+		case sandboxDelete:
+			return nil
+		}
+	}
+	return nil
+}
+
+// delete removes the sandbox browser even when ctx has already been canceled.
+func (s SandboxCmd) delete(ctx context.Context, id string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sandboxDeleteTimeout)
+	defer cancel()
+	if err := s.browsers.Delete(ctx, BrowsersDeleteInput{Identifier: id}); err != nil {
+		pterm.Error.Printf("Failed to delete sandbox browser %s: %v\nDelete it with: kernel browsers delete %s\n", id, err, id)
+	}
+}
+
+func chooseSandboxAction(options []string, interrupt func()) (string, error) {
+	return pterm.DefaultInteractiveSelect.
+		WithOptions(options).
+		WithDefaultText("Sandbox").
+		WithOnInterruptFunc(interrupt).
+		Show()
+}
+
+func askSandboxInput(label string, interrupt func()) (string, error) {
+	return pterm.DefaultInteractiveTextInput.
+		WithOnInterruptFunc(interrupt).
+		Show(label)
+}
+
+var sandboxCmd = &cobra.Command{
+	Use:   "sandbox",
+	Short: "Start a disposable browser to experiment with",
+	Long: `Create a throwaway browser with no profile, print its live view, CDP and SSH
+details, and offer a menu to open it, run commands in it, or take screenshots.
+
+The browser is deleted when you exit the menu, press Ctrl+C, or the TTL runs out.`,
+	Args: cobra.NoArgs,
+	RunE: runSandbox,
+}
+
+func init() {
+	sandboxCmd.Flags().Duration("ttl", defaultSandboxTTL, "Delete the sandbox after this long")
+}
+
+func runSandbox(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	ttl, _ := cmd.Flags().GetDuration("ttl")
+
+	svc := client.Browsers
+	s := SandboxCmd{browsers: BrowsersCmd{browsers: &svc, process: &svc.Process, computer: &svc.Computer}}
+	return s.Run(cmd.Context(), SandboxInput{TTL: ttl})
+}
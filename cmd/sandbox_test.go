@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSandboxFakes(deleted *[]string) *FakeBrowsersService {
+	return &FakeBrowsersService{
+		NewFunc: func(ctx context.Context, body kernel.BrowserNewParams, opts ...option.RequestOption) (*kernel.BrowserNewResponse, error) {
+			return &kernel.BrowserNewResponse{SessionID: "sbx_1", CdpWsURL: "wss://cdp", BrowserLiveViewURL: "https://live"}, nil
+		},
+		DeleteByIDFunc: func(ctx context.Context, id string, opts ...option.RequestOption) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			*deleted = append(*deleted, id)
+			return nil
+		},
+	}
+}
+
+func TestSandbox_RunsMenuActionsAndDeletesOnExit(t *testing.T) {
+	setupStdoutCapture(t)
+
+	var deleted, opened []string
+	var execs []kernel.BrowserProcessExecParams
+	proc := &FakeProcessService{ExecFunc: func(ctx context.Context, id string, body kernel.BrowserProcessExecParams, opts ...option.RequestOption) (*kernel.BrowserProcessExecResponse, error) {
+		execs = append(execs, body)
+		return &kernel.BrowserProcessExecResponse{}, nil
+	}}
+	fake := newSandboxFakes(&deleted)
+	fake.GetFunc = func(ctx context.Context, id string, query kernel.BrowserGetParams, opts ...option.RequestOption) (*kernel.BrowserGetResponse, error) {
+		return &kernel.BrowserGetResponse{SessionID: id}, nil
+	}
+	choices := []string{sandboxOpen, sandboxExec, sandboxDelete}
+	s := SandboxCmd{
+		browsers: BrowsersCmd{browsers: fake, process: proc},
+		choose: func(options []string, interrupt func()) (string, error) {
+			choice := choices[0]
+			choices = choices[1:]
+			return choice, nil
+		},
+		ask:     func(label string, interrupt func()) (string, error) { return "ls /tmp | wc -l", nil },
+		openURL: func(url string) error { opened = append(opened, url); return nil },
+	}
+
+	require.NoError(t, s.Run(context.Background(), SandboxInput{TTL: time.Minute}))
+	assert.Equal(t, []string{"https://live"}, opened)
+	require.Len(t, execs, 1)
+	assert.Equal(t, "bash", execs[0].Command)
+	assert.Equal(t, []string{"-lc", "ls /tmp | wc -l"}, execs[0].Args)
+	assert.Equal(t, []string{"sbx_1"}, deleted)
+	assert.Contains(t, outBuf.String(), "kernel browsers ssh sbx_1")
+}
+
+func TestSandbox_DeletesWhenTTLExpires(t *testing.T) {
+	setupStdoutCapture(t)
+
+	var deleted []string
+	var gotTimeout int64
+	fake := newSandboxFakes(&deleted)
+	newFunc := fake.NewFunc
+	fake.NewFunc = func(ctx context.Context, body kernel.BrowserNewParams, opts ...option.RequestOption) (*kernel.BrowserNewResponse, error) {
+		gotTimeout = body.TimeoutSeconds.Value
+		return newFunc(ctx, body, opts...)
+	}
+	block := make(chan struct{})
+	t.Cleanup(func() { close(block) })
+	s := SandboxCmd{
+		browsers: BrowsersCmd{browsers: fake},
+		choose: func(options []string, interrupt func()) (string, error) {
+			<-block
+			return sandboxDelete, nil
+		},
+	}
+
+	require.NoError(t, s.Run(context.Background(), SandboxInput{TTL: 20 * time.Millisecond}))
+	assert.Equal(t, []string{"sbx_1"}, deleted)
+	assert.Equal(t, int64(10), gotTimeout)
+	assert.Contains(t, outBuf.String(), "Sandbox expired")
+}
+
+func TestSandbox_DeletesOnInterrupt(t *testing.T) {
+	setupStdoutCapture(t)
+
+	var deleted []string
+	s := SandboxCmd{
+		browsers: BrowsersCmd{browsers: newSandboxFakes(&deleted)},
+		choose: func(options []string, interrupt func()) (string, error) {
+			interrupt()
+			return "", nil
+		},
+	}
+
+	require.NoError(t, s.Run(context.Background(), SandboxInput{TTL: time.Minute}))
+	assert.Equal(t, []string{"sbx_1"}, deleted)
+}
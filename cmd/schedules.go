@@ -0,0 +1,482 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/kernel/cli/pkg/schedule"
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// schedulesRunPollInterval is how often "schedules run" checks for due
+// schedules; cron resolution is a minute, so this keeps runs within seconds.
+const schedulesRunPollInterval = 10 * time.Second
+
+// SchedulesCmd manages recurring invocations kept in a local schedule store.
+type SchedulesCmd struct {
+	store       *schedule.Store
+	invocations InvocationsService
+	now         func() time.Time
+}
+
+func (s SchedulesCmd) currentTime() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+type SchedulesAddInput struct {
+	Cron                string
+	App                 string
+	Action              string
+	Version             string
+	Payload             string
+	AsyncTimeoutSeconds int64
+	Output              string
+}
+
+// Add saves a recurring invocation.
+func (s SchedulesCmd) Add(in SchedulesAddInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	sched, err := s.store.Add(schedule.Schedule{
+		Cron:                in.Cron,
+		App:                 in.App,
+		Action:              in.Action,
+		Version:             in.Version,
+		Payload:             in.Payload,
+		AsyncTimeoutSeconds: in.AsyncTimeoutSeconds,
+	})
+	if err != nil {
+		return err
+	}
+	if in.Output == "json" {
+		return util.PrintJSON(sched)
+	}
+	pterm.Success.Printf("Scheduled %s (action: %s, version: %s) at %q as %s\n", sched.App, sched.Action, sched.Version, sched.Cron, sched.ID)
+	pterm.Info.Printf("Next run: %s\n", util.FormatLocal(sched.Next(s.currentTime())))
+	pterm.Info.Println("Schedules run while 'kernel schedules run' is running on this machine")
+	return nil
+}
+
+type SchedulesListInput struct {
+	Output string
+}
+
+type scheduleListItem struct {
+	schedule.Schedule
+	NextRunAt time.Time `json:"next_run_at,omitzero"`
+}
+
+// List prints the saved schedules with when each is next due.
+func (s SchedulesCmd) List(in SchedulesListInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	schedules, err := s.store.List()
+	if err != nil {
+		return err
+	}
+	now := s.currentTime()
+	if in.Output == "json" {
+		items := make([]scheduleListItem, 0, len(schedules))
+		for _, sched := range schedules {
+			items = append(items, scheduleListItem{Schedule: sched, NextRunAt: sched.Next(now)})
+		}
+		return util.PrintJSON(items)
+	}
+	if len(schedules) == 0 {
+		pterm.Info.Println("No schedules found")
+		return nil
+	}
+	rows := pterm.TableData{{"ID", "Schedule", "App", "Action", "Version", "Next Run", "Last Run", "Last Invocation", "Last Error"}}
+	for _, sched := range schedules {
+		rows = append(rows, []string{
+			sched.ID,
+			sched.Cron,
+			sched.App,
+			sched.Action,
+			sched.Version,
+			util.FormatLocal(sched.Next(now)),
+			util.FormatLocal(sched.LastRunAt),
+			util.FirstOrDash(sched.LastInvocationID),
+			util.FirstOrDash(sched.LastError),
+		})
+	}
+	PrintTableNoPad(rows, true)
+	return nil
+}
+
+type SchedulesDeleteInput struct {
+	ID string
+}
+
+// Delete removes a schedule by ID or unique ID prefix.
+func (s SchedulesCmd) Delete(in SchedulesDeleteInput) error {
+	removed, err := s.store.Delete(in.ID)
+	if err != nil {
+		return err
+	}
+	if removed == nil {
+		return fmt.Errorf("schedule '%s' not found", in.ID)
+	}
+	pterm.Success.Printf("Deleted schedule %s (%s %s at %q)\n", removed.ID, removed.App, removed.Action, removed.Cron)
+	return nil
+}
+
+type SchedulesExplainInput struct {
+	Cron string
+	Next int
+	// Timezone is an IANA name such as Europe/Berlin; empty means local.
+	Timezone string
+	Output   string
+}
+
+type scheduleExplanation struct {
+	Cron        string      `json:"cron"`
+	Description string      `json:"description"`
+	Timezone    string      `json:"timezone"`
+	NextRuns    []time.Time `json:"next_runs"`
+}
+
+// Explain describes a cron expression and lists its next runs in the given
+// timezone, which defaults to this machine's, where "schedules run" evaluates
+// schedules.
+func (s SchedulesCmd) Explain(in SchedulesExplainInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if in.Next < 0 {
+		return fmt.Errorf("--next can't be negative")
+	}
+	c, err := schedule.ParseCron(in.Cron)
+	if err != nil {
+		return err
+	}
+	loc, zone := time.Local, localZoneName()
+	if in.Timezone != "" {
+		zone = in.Timezone
+		if loc, err = time.LoadLocation(in.Timezone); err != nil {
+			return fmt.Errorf("unknown --timezone %q: use an IANA name such as America/New_York", in.Timezone)
+		}
+	}
+
+	now := s.currentTime().In(loc)
+	runs := []time.Time{}
+	for t := now; len(runs) < in.Next; {
+		if t = c.Next(t); t.IsZero() {
+			break
+		}
+		runs = append(runs, t)
+	}
+
+	if in.Output == "json" {
+		return util.PrintJSON(scheduleExplanation{Cron: c.String(), Description: c.Describe(), Timezone: zone, NextRuns: runs})
+	}
+	_, offset := now.Zone()
+	rows := pterm.TableData{
+		{"Cron", c.String()},
+		{"Meaning", c.Describe()},
+		{"Timezone", fmt.Sprintf("%s (%s, UTC%s)", zone, now.Format("MST"), formatUTCOffset(offset))},
+	}
+	PrintTableNoPad(rows, false)
+	if local := localZoneName(); zone != local {
+		pterm.Warning.Printf("'kernel schedules run' uses this machine's timezone (%s), not %s\n", local, zone)
+	}
+	if in.Next == 0 {
+		return nil
+	}
+	if len(runs) == 0 {
+		pterm.Warning.Println("This expression never matches (e.g. February 30th)")
+		return nil
+	}
+
+	table := pterm.TableData{{"#", "Run At", "UTC", "In"}}
+	offsetChanges := false
+	for i, t := range runs {
+		if _, o := t.Zone(); o != offset {
+			offsetChanges = true
+		}
+		table = append(table, []string{
+			strconv.Itoa(i + 1),
+			t.Format("Mon " + util.DefaultTimeLayout),
+			t.UTC().Format("Mon 2006-01-02 15:04"),
+			strings.TrimSuffix(t.Sub(now).Round(time.Minute).String(), "0s"),
+		})
+	}
+	PrintTableNoPad(table, true)
+	if offsetChanges {
+		pterm.Warning.Println("The UTC offset changes between these runs (daylight saving time); runs stay at the same local time, so their UTC times shift")
+	}
+	return nil
+}
+
+// localZoneName names this machine's timezone, such as Europe/Berlin, or
+// returns "Local" when it can't tell.
+func localZoneName() string {
+	if tz := os.Getenv("TZ"); tz != "" {
+		return tz
+	}
+	if target, err := os.Readlink("/etc/localtime"); err == nil {
+		if _, name, ok := strings.Cut(target, "zoneinfo/"); ok {
+			return name
+		}
+	}
+	return time.Local.String()
+}
+
+// formatUTCOffset formats seconds east of UTC as +02:00.
+func formatUTCOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign, seconds = "-", -seconds
+	}
+	return fmt.Sprintf("%s%02d:%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+type SchedulesRunInput struct {
+	Output string
+}
+
+type scheduleRunEvent struct {
+	ScheduleID   string    `json:"schedule_id"`
+	App          string    `json:"app"`
+	Action       string    `json:"action"`
+	Version      string    `json:"version"`
+	RanAt        time.Time `json:"ran_at"`
+	InvocationID string    `json:"invocation_id,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// Run submits each schedule's invocation whenever it falls due, until ctx
+// ends. Runs missed while nothing was running aren't made up.
+func (s SchedulesCmd) Run(ctx context.Context, in SchedulesRunInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if in.Output != "json" {
+		pterm.Info.Printf("Running schedules from %s (Ctrl+C to stop)...\n", s.store.Path)
+	}
+	lastRun := map[string]time.Time{}
+	started := s.currentTime()
+	ticker := time.NewTicker(schedulesRunPollInterval)
+	defer ticker.Stop()
+	for {
+		// The store is re-read every tick so added and deleted schedules
+		// apply without a restart
+		if err := s.runDue(ctx, started, lastRun, in.Output); err != nil {
+			pterm.Error.Println(err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			if in.Output != "json" {
+				pterm.Info.Println("Stopped running schedules")
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDue submits every schedule whose next run after its last one (or after
+// started, whichever is later) has come.
+func (s SchedulesCmd) runDue(ctx context.Context, started time.Time, lastRun map[string]time.Time, output string) error {
+	schedules, err := s.store.List()
+	if err != nil {
+		return err
+	}
+	now := s.currentTime()
+	for _, sched := range schedules {
+		base := started
+		if sched.LastRunAt.After(base) {
+			base = sched.LastRunAt
+		}
+		if t, ok := lastRun[sched.ID]; ok && t.After(base) {
+			base = t
+		}
+		next := sched.Next(base)
+		if next.IsZero() || next.After(now) {
+			continue
+		}
+		lastRun[sched.ID] = now
+		invocationID, runErr := s.invoke(ctx, sched)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := s.store.RecordRun(sched.ID, now, invocationID, runErr); err != nil {
+			pterm.Warning.Printf("Failed to record run of %s: %v\n", sched.ID, err)
+		}
+		s.printRun(scheduleRunEvent{
+			ScheduleID:   sched.ID,
+			App:          sched.App,
+			Action:       sched.Action,
+			Version:      sched.Version,
+			RanAt:        now,
+			InvocationID: invocationID,
+			Error:        errString(runErr),
+		}, output)
+	}
+	return nil
+}
+
+// invoke submits sched's invocation asynchronously without following it.
+func (s SchedulesCmd) invoke(ctx context.Context, sched schedule.Schedule) (string, error) {
+	params := kernel.InvocationNewParams{
+		AppName:    sched.App,
+		ActionName: sched.Action,
+		Version:    sched.Version,
+		Async:      kernel.Opt(true),
+	}
+	if params.Version == "" {
+		params.Version = "latest"
+	}
+	if sched.Payload != "" {
+		params.Payload = kernel.Opt(sched.Payload)
+	}
+	if sched.AsyncTimeoutSeconds > 0 {
+		params.AsyncTimeoutSeconds = kernel.Opt(sched.AsyncTimeoutSeconds)
+	}
+	resp, err := s.invocations.New(ctx, params, option.WithMaxRetries(0))
+	if err != nil {
+		return "", util.CleanedUpSdkError{Err: err}
+	}
+	return resp.ID, nil
+}
+
+func (s SchedulesCmd) printRun(ev scheduleRunEvent, output string) {
+	if output == "json" {
+		if err := util.PrintJSONLine(ev); err != nil {
+			pterm.Error.Println(err.Error())
+		}
+		return
+	}
+	if ev.Error != "" {
+		pterm.Error.Printf("%s: %s %s failed to start: %s\n", ev.ScheduleID, ev.App, ev.Action, ev.Error)
+		return
+	}
+	pterm.Success.Printf("%s: started %s %s (invocation %s)\n", ev.ScheduleID, ev.App, ev.Action, ev.InvocationID)
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+var schedulesCmd = &cobra.Command{
+	Use:     "schedules",
+	Aliases: []string{"schedule"},
+	Short:   "Manage scheduled invocations",
+	Long: `Manage recurring invocations created with "kernel invoke --schedule".
+
+Schedules are stored on this machine and run while "kernel schedules run" is
+running; runs missed while it isn't are skipped.`,
+}
+
+var schedulesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List scheduled invocations",
+	Args:  cobra.NoArgs,
+	RunE:  runSchedulesList,
+}
+
+var schedulesDeleteCmd = &cobra.Command{
+	Use:   "delete <id>",
+	Short: "Delete a scheduled invocation",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSchedulesDelete,
+}
+
+var schedulesExplainCmd = &cobra.Command{
+	Use:   "explain <cron>",
+	Short: "Describe a cron expression and preview its next runs",
+	Long: `Describe a five-field cron expression (or @hourly, @daily, ...) in words and
+list its next run times in this machine's timezone, which is the timezone
+"kernel schedules run" evaluates schedules in, alongside UTC.`,
+	Example: `  kernel schedules explain "0 */6 * * *"
+  kernel schedules explain "30 9 * * 1-5" --next 10 --timezone America/New_York`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSchedulesExplain,
+}
+
+var schedulesRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run scheduled invocations in the foreground",
+	Long: `Submit each scheduled invocation whenever its cron expression falls due,
+until interrupted. Invocations are submitted asynchronously and not followed;
+use "kernel schedules list" to see each schedule's last invocation.`,
+	Args: cobra.NoArgs,
+	RunE: runSchedulesRun,
+}
+
+func init() {
+	schedulesCmd.AddCommand(schedulesListCmd)
+	schedulesCmd.AddCommand(schedulesDeleteCmd)
+	schedulesCmd.AddCommand(schedulesRunCmd)
+	schedulesCmd.AddCommand(schedulesExplainCmd)
+
+	addJSONOutputFlag(schedulesListCmd)
+	schedulesRunCmd.Flags().StringP("output", "o", "", "Output format: json for JSONL output of each run")
+	schedulesExplainCmd.Flags().Int("next", 5, "Number of upcoming run times to list")
+	schedulesExplainCmd.Flags().String("timezone", "", "Show run times in this IANA timezone (e.g. Europe/Berlin) instead of this machine's")
+	addJSONOutputFlag(schedulesExplainCmd)
+
+	rootCmd.AddCommand(schedulesCmd)
+}
+
+func newSchedulesCmd(invocations InvocationsService) (SchedulesCmd, error) {
+	store, err := schedule.DefaultStore()
+	if err != nil {
+		return SchedulesCmd{}, err
+	}
+	return SchedulesCmd{store: store, invocations: invocations}, nil
+}
+
+func runSchedulesList(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	s, err := newSchedulesCmd(nil)
+	if err != nil {
+		return err
+	}
+	return s.List(SchedulesListInput{Output: output})
+}
+
+func runSchedulesDelete(cmd *cobra.Command, args []string) error {
+	s, err := newSchedulesCmd(nil)
+	if err != nil {
+		return err
+	}
+	return s.Delete(SchedulesDeleteInput{ID: args[0]})
+}
+
+func runSchedulesExplain(cmd *cobra.Command, args []string) error {
+	next, _ := cmd.Flags().GetInt("next")
+	timezone, _ := cmd.Flags().GetString("timezone")
+	output, _ := cmd.Flags().GetString("output")
+	return SchedulesCmd{}.Explain(SchedulesExplainInput{Cron: args[0], Next: next, Timezone: timezone, Output: output})
+}
+
+func runSchedulesRun(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	client := getKernelClient(cmd)
+	s, err := newSchedulesCmd(&client.Invocations)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return s.Run(ctx, SchedulesRunInput{Output: output})
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kernel/cli/pkg/schedule"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSchedulesCmd(t *testing.T, now *time.Time, invocations InvocationsService) SchedulesCmd {
+	store := &schedule.Store{Path: filepath.Join(t.TempDir(), "schedules.json"), Now: func() time.Time { return *now }}
+	return SchedulesCmd{store: store, invocations: invocations, now: func() time.Time { return *now }}
+}
+
+func TestSchedulesAdd_ListJSON(t *testing.T) {
+	now := time.Date(2026, 1, 2, 10, 7, 0, 0, time.UTC)
+	s := newTestSchedulesCmd(t, &now, nil)
+
+	capturePtermOutput(t)
+	require.NoError(t, s.Add(SchedulesAddInput{Cron: "*/5 * * * *", App: "app", Action: "sync", Version: "latest", Payload: `{"a":1}`}))
+
+	out := captureStdout(t, func() {
+		require.NoError(t, s.List(SchedulesListInput{Output: "json"}))
+	})
+	var items []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(out), &items))
+	require.Len(t, items, 1)
+	assert.Equal(t, "*/5 * * * *", items[0]["cron"])
+	assert.Equal(t, `{"a":1}`, items[0]["payload"])
+	assert.Equal(t, "2026-01-02T10:10:00Z", items[0]["next_run_at"])
+	assert.NotContains(t, items[0], "last_run_at")
+}
+
+func TestSchedulesAdd_InvalidCron(t *testing.T) {
+	now := time.Now()
+	s := newTestSchedulesCmd(t, &now, nil)
+	err := s.Add(SchedulesAddInput{Cron: "5 minutes", App: "app", Action: "sync", Version: "latest"})
+	assert.ErrorContains(t, err, "invalid cron expression")
+}
+
+func TestSchedulesDelete(t *testing.T) {
+	now := time.Now()
+	s := newTestSchedulesCmd(t, &now, nil)
+	sched, err := s.store.Add(schedule.Schedule{Cron: "@daily", App: "app", Action: "sync"})
+	require.NoError(t, err)
+
+	buf := capturePtermOutput(t)
+	require.NoError(t, s.Delete(SchedulesDeleteInput{ID: sched.ID}))
+	assert.Contains(t, buf.String(), "Deleted schedule "+sched.ID)
+
+	assert.ErrorContains(t, s.Delete(SchedulesDeleteInput{ID: sched.ID}), "not found")
+}
+
+func TestSchedulesRunDue(t *testing.T) {
+	var submitted []kernel.InvocationNewParams
+	fake := &fakeLoadtestInvocations{newFunc: func(ctx context.Context, body kernel.InvocationNewParams) (*kernel.InvocationNewResponse, error) {
+		submitted = append(submitted, body)
+		if body.ActionName == "broken" {
+			return nil, errors.New("action not found")
+		}
+		return &kernel.InvocationNewResponse{ID: "inv_" + body.ActionName}, nil
+	}}
+	started := time.Date(2026, 1, 2, 10, 4, 50, 0, time.UTC)
+	now := started
+	s := newTestSchedulesCmd(t, &now, fake)
+	every5, err := s.store.Add(schedule.Schedule{Cron: "*/5 * * * *", App: "app", Action: "sync", Payload: `{"x":1}`, AsyncTimeoutSeconds: 120})
+	require.NoError(t, err)
+	broken, err := s.store.Add(schedule.Schedule{Cron: "* * * * *", App: "app", Action: "broken", Version: "v2"})
+	require.NoError(t, err)
+
+	capturePtermOutput(t)
+	lastRun := map[string]time.Time{}
+	runDue := func() {
+		require.NoError(t, s.runDue(context.Background(), started, lastRun, ""))
+	}
+
+	// Nothing is due before the first minute boundary after starting
+	runDue()
+	assert.Empty(t, submitted)
+
+	now = time.Date(2026, 1, 2, 10, 5, 3, 0, time.UTC)
+	runDue()
+	require.Len(t, submitted, 2)
+	assert.Equal(t, "latest", submitted[0].Version)
+	assert.True(t, submitted[0].Async.Value)
+	assert.Equal(t, `{"x":1}`, submitted[0].Payload.Value)
+	assert.Equal(t, int64(120), submitted[0].AsyncTimeoutSeconds.Value)
+	assert.Equal(t, "v2", submitted[1].Version)
+
+	// A second check within the same minute doesn't run them again
+	now = now.Add(10 * time.Second)
+	runDue()
+	assert.Len(t, submitted, 2)
+
+	now = time.Date(2026, 1, 2, 10, 6, 1, 0, time.UTC)
+	runDue()
+	require.Len(t, submitted, 3)
+	assert.Equal(t, "broken", submitted[2].ActionName)
+
+	got, err := s.store.Get(every5.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "inv_sync", got.LastInvocationID)
+	assert.Empty(t, got.LastError)
+	got, err = s.store.Get(broken.ID)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 2, 10, 6, 1, 0, time.UTC), got.LastRunAt)
+	assert.Contains(t, got.LastError, "action not found")
+}
+
+func TestSchedulesExplain_JSONInTimezone(t *testing.T) {
+	// 2026-03-28 12:00 UTC; Berlin moves to summer time on the 29th
+	now := time.Date(2026, 3, 28, 12, 0, 0, 0, time.UTC)
+	s := SchedulesCmd{now: func() time.Time { return now }}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = s.Explain(SchedulesExplainInput{Cron: "0 */6 * * *", Next: 3, Timezone: "Europe/Berlin", Output: "json"})
+	})
+	require.NoError(t, err)
+	var res scheduleExplanation
+	require.NoError(t, json.Unmarshal([]byte(out), &res))
+	assert.Equal(t, "At minute 0 past every 6th hour", res.Description)
+	assert.Equal(t, "Europe/Berlin", res.Timezone)
+	require.Len(t, res.NextRuns, 3)
+	// 18:00 CET, then midnight CET, then 06:00 CEST
+	assert.Equal(t, time.Date(2026, 3, 28, 17, 0, 0, 0, time.UTC), res.NextRuns[0].UTC())
+	assert.Equal(t, time.Date(2026, 3, 28, 23, 0, 0, 0, time.UTC), res.NextRuns[1].UTC())
+	assert.Equal(t, time.Date(2026, 3, 29, 4, 0, 0, 0, time.UTC), res.NextRuns[2].UTC())
+}
+
+func TestSchedulesExplain_Invalid(t *testing.T) {
+	s := SchedulesCmd{}
+	assert.ErrorContains(t, s.Explain(SchedulesExplainInput{Cron: "0 */6 * *"}), "want 5 fields")
+	assert.EqualError(t, s.Explain(SchedulesExplainInput{Cron: "@daily", Timezone: "Mars/Olympus"}), `unknown --timezone "Mars/Olympus": use an IANA name such as America/New_York`)
+}
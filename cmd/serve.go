@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run long-lived servers that expose Kernel to other tools",
+}
+
+func init() {
+	serveCmd.AddCommand(serveSlackCmd)
+	serveCmd.AddCommand(serveAPICmd)
+	serveCmd.AddCommand(serveMCPCmd)
+}
+
+// runHTTPServer serves handler on addr until ctx is cancelled, then shuts down
+// gracefully, giving in-flight requests a few seconds to finish.
+func runHTTPServer(ctx context.Context, addr string, handler http.Handler) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.Serve(ln) }()
+	pterm.Info.Printf("Listening on %s (Ctrl+C to stop)\n", ln.Addr())
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	pterm.Info.Println("Server stopped")
+	return nil
+}
@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+const apiGatewayMaxBodyBytes = 1 << 20
+
+// apiGateway is a small authenticated REST facade over common CLI operations.
+// It serves SDK responses verbatim so callers see the same JSON as -o json.
+type apiGateway struct {
+	token       string
+	browsers    BrowsersService
+	auth        AuthConnectionService
+	invocations InvocationsService
+}
+
+// apiBrowserCreateRequest mirrors the most common `browsers create` flags.
+type apiBrowserCreateRequest struct {
+	Name           string            `json:"name"`
+	Headless       *bool             `json:"headless"`
+	Stealth        *bool             `json:"stealth"`
+	TimeoutSeconds int               `json:"timeout_seconds"`
+	ProfileID      string            `json:"profile_id"`
+	ProfileName    string            `json:"profile_name"`
+	SaveChanges    *bool             `json:"save_changes"`
+	ProxyID        string            `json:"proxy_id"`
+	StartURL       string            `json:"start_url"`
+	Extensions     []string          `json:"extensions"`
+	Viewport       string            `json:"viewport"`
+	Tags           map[string]string `json:"tags"`
+}
+
+type apiInvocationRequest struct {
+	App     string          `json:"app"`
+	Action  string          `json:"action"`
+	Version string          `json:"version"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+type apiAuthLoginRequest struct {
+	ProxyID string `json:"proxy_id"`
+}
+
+// apiError is an error carrying the HTTP status to report.
+type apiError struct {
+	status int
+	msg    string
+}
+
+func (e apiError) Error() string { return e.msg }
+
+func badRequest(format string, args ...any) error {
+	return apiError{status: http.StatusBadRequest, msg: fmt.Sprintf(format, args...)}
+}
+
+func (g *apiGateway) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	mux.Handle("GET /v1/browsers", g.handle(g.listBrowsers))
+	mux.Handle("POST /v1/browsers", g.handle(g.createBrowser))
+	mux.Handle("GET /v1/browsers/{id}", g.handle(g.getBrowser))
+	mux.Handle("DELETE /v1/browsers/{id}", g.handle(g.deleteBrowser))
+	mux.Handle("GET /v1/auth/connections/{id}", g.handle(g.getAuthConnection))
+	mux.Handle("POST /v1/auth/connections/{id}/login", g.handle(g.loginAuthConnection))
+	mux.Handle("POST /v1/invocations", g.handle(g.createInvocation))
+	mux.Handle("GET /v1/invocations/{id}", g.handle(g.getInvocation))
+	return mux
+}
+
+// handle wraps an endpoint with bearer-token auth and JSON error reporting.
+func (g *apiGateway) handle(fn func(w http.ResponseWriter, r *http.Request) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(g.token)) != 1 {
+			writeAPIError(w, apiError{status: http.StatusUnauthorized, msg: "missing or invalid bearer token"})
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, apiGatewayMaxBodyBytes)
+		if err := fn(w, r); err != nil {
+			pterm.Debug.Printf("%s %s: %v\n", r.Method, r.URL.Path, err)
+			writeAPIError(w, err)
+		}
+	})
+}
+
+func writeAPIError(w http.ResponseWriter, err error) {
+	status := http.StatusBadGateway
+	var ae apiError
+	var sdkErr *kernel.Error
+	switch {
+	case errors.As(err, &ae):
+		status = ae.status
+	case errors.As(err, &sdkErr):
+		status = sdkErr.StatusCode
+		err = util.CleanedUpSdkError{Err: err}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+func writeAPIJSON(w http.ResponseWriter, status int, v util.RawJSONProvider) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if raw := v.RawJSON(); raw != "" {
+		_, err := io.WriteString(w, raw)
+		return err
+	}
+	return json.NewEncoder(w).Encode(v)
+}
+
+func decodeAPIBody(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return badRequest("invalid request body: %v", err)
+	}
+	return nil
+}
+
+func (g *apiGateway) listBrowsers(w http.ResponseWriter, r *http.Request) error {
+	q := r.URL.Query()
+	params := kernel.BrowserListParams{Status: kernel.BrowserListParamsStatusActive}
+	if v := q.Get("query"); v != "" {
+		params.Query = kernel.Opt(v)
+	}
+	if tags, invalid := parseKeyValueSpecs(q["tag"]); len(invalid) > 0 {
+		return badRequest("invalid tag %q (expected KEY=VALUE)", invalid[0])
+	} else if len(tags) > 0 {
+		params.Tags = tags
+	}
+	page, err := g.browsers.List(r.Context(), params)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if page == nil || page.RawJSON() == "" {
+		items := []kernel.BrowserListResponse{}
+		if page != nil {
+			items = page.Items
+		}
+		return json.NewEncoder(w).Encode(items)
+	}
+	_, err = io.WriteString(w, page.RawJSON())
+	return err
+}
+
+func (g *apiGateway) createBrowser(w http.ResponseWriter, r *http.Request) error {
+	var req apiBrowserCreateRequest
+	if err := decodeAPIBody(r, &req); err != nil {
+		return err
+	}
+	params, err := buildBrowserNewParams(BrowsersCreateInput{
+		TimeoutSeconds:     req.TimeoutSeconds,
+		Headless:           optionalBoolFlag(req.Headless),
+		Stealth:            optionalBoolFlag(req.Stealth),
+		ProfileID:          req.ProfileID,
+		ProfileName:        req.ProfileName,
+		ProfileSaveChanges: optionalBoolFlag(req.SaveChanges),
+		ProxyID:            req.ProxyID,
+		StartURL:           req.StartURL,
+		Extensions:         req.Extensions,
+		Viewport:           req.Viewport,
+		Name:               req.Name,
+		Tags:               req.Tags,
+	})
+	if err != nil {
+		return badRequest("%v", err)
+	}
+	browser, err := g.browsers.New(r.Context(), params)
+	if err != nil {
+		return err
+	}
+	return writeAPIJSON(w, http.StatusCreated, browser)
+}
+
+func (g *apiGateway) getBrowser(w http.ResponseWriter, r *http.Request) error {
+	browser, err := g.browsers.Get(r.Context(), r.PathValue("id"), kernel.BrowserGetParams{})
+	if err != nil {
+		return err
+	}
+	return writeAPIJSON(w, http.StatusOK, browser)
+}
+
+func (g *apiGateway) deleteBrowser(w http.ResponseWriter, r *http.Request) error {
+	if err := g.browsers.DeleteByID(r.Context(), r.PathValue("id")); err != nil && !util.IsNotFound(err) {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (g *apiGateway) getAuthConnection(w http.ResponseWriter, r *http.Request) error {
+	auth, err := g.auth.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	return writeAPIJSON(w, http.StatusOK, auth)
+}
+
+func (g *apiGateway) loginAuthConnection(w http.ResponseWriter, r *http.Request) error {
+	var req apiAuthLoginRequest
+	if err := decodeAPIBody(r, &req); err != nil {
+		return err
+	}
+	params := kernel.AuthConnectionLoginParams{}
+	if req.ProxyID != "" {
+		params.Proxy = kernel.AuthConnectionLoginParamsProxy{ID: kernel.Opt(req.ProxyID)}
+	}
+	resp, err := g.auth.Login(r.Context(), r.PathValue("id"), params)
+	if err != nil {
+		return err
+	}
+	return writeAPIJSON(w, http.StatusOK, resp)
+}
+
+func (g *apiGateway) createInvocation(w http.ResponseWriter, r *http.Request) error {
+	var req apiInvocationRequest
+	if err := decodeAPIBody(r, &req); err != nil {
+		return err
+	}
+	if req.App == "" || req.Action == "" {
+		return badRequest("app and action are required")
+	}
+	if req.Version == "" {
+		req.Version = "latest"
+	}
+	params := kernel.InvocationNewParams{
+		AppName:    req.App,
+		ActionName: req.Action,
+		Version:    req.Version,
+		Async:      kernel.Opt(true),
+	}
+	if len(req.Payload) > 0 {
+		params.Payload = kernel.Opt(string(req.Payload))
+	}
+	resp, err := g.invocations.New(r.Context(), params)
+	if err != nil {
+		return err
+	}
+	return writeAPIJSON(w, http.StatusAccepted, resp)
+}
+
+func (g *apiGateway) getInvocation(w http.ResponseWriter, r *http.Request) error {
+	inv, err := g.invocations.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	return writeAPIJSON(w, http.StatusOK, inv)
+}
+
+var serveAPICmd = &cobra.Command{
+	Use:   "api",
+	Short: "Serve a local REST API over common CLI operations",
+	Long: `Run a local HTTP server exposing common operations with the CLI's current credentials
+and project, so internal tools can integrate without embedding the Go SDK.
+
+Every request except GET /healthz must send "Authorization: Bearer <token>". The token
+comes from --token or KERNEL_SERVE_TOKEN; if neither is set a random token is generated
+and printed at startup.
+
+Endpoints:
+  GET    /v1/browsers                        List active browsers (?query=, ?tag=KEY=VALUE)
+  POST   /v1/browsers                        Create a browser
+  GET    /v1/browsers/{id}                   Get a browser by ID or name
+  DELETE /v1/browsers/{id}                   Delete a browser
+  GET    /v1/auth/connections/{id}           Get a managed auth connection
+  POST   /v1/auth/connections/{id}/login     Start a login flow
+  POST   /v1/invocations                     Invoke an app action asynchronously
+  GET    /v1/invocations/{id}                Get an invocation`,
+	Args: cobra.NoArgs,
+	RunE: runServeAPI,
+}
+
+func init() {
+	serveAPICmd.Flags().String("listen", "127.0.0.1:7777", "Address to listen on")
+	serveAPICmd.Flags().String("token", "", "Bearer token clients must present (defaults to KERNEL_SERVE_TOKEN, or a generated token)")
+}
+
+func runServeAPI(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	listen, _ := cmd.Flags().GetString("listen")
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = os.Getenv("KERNEL_SERVE_TOKEN")
+	}
+	if token == "" {
+		buf := make([]byte, 24)
+		if _, err := rand.Read(buf); err != nil {
+			return fmt.Errorf("generate token: %w", err)
+		}
+		token = hex.EncodeToString(buf)
+		pterm.Info.Printf("Generated API token: %s\n", token)
+	}
+	if host, _, err := net.SplitHostPort(listen); err == nil {
+		if ip := net.ParseIP(host); host == "" || (ip != nil && !ip.IsLoopback()) {
+			pterm.Warning.Printf("Listening on %s exposes your Kernel credentials beyond this machine\n", listen)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	browsers := client.Browsers
+	auth := client.Auth.Connections
+	invocations := client.Invocations
+	g := &apiGateway{token: token, browsers: &browsers, auth: &auth, invocations: &invocations}
+	return runHTTPServer(ctx, listen, g.routes())
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeInvocations struct {
+	newFunc func(ctx context.Context, body kernel.InvocationNewParams) (*kernel.InvocationNewResponse, error)
+}
+
+func (f *fakeInvocations) New(ctx context.Context, body kernel.InvocationNewParams, opts ...option.RequestOption) (*kernel.InvocationNewResponse, error) {
+	return f.newFunc(ctx, body)
+}
+
+func (f *fakeInvocations) Get(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.InvocationGetResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func doAPIRequest(t *testing.T, h http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPIGateway_RequiresToken(t *testing.T) {
+	h := (&apiGateway{token: "s3cret", browsers: &FakeBrowsersService{}}).routes()
+
+	assert.Equal(t, http.StatusOK, doAPIRequest(t, h, http.MethodGet, "/healthz", "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, doAPIRequest(t, h, http.MethodGet, "/v1/browsers", "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, doAPIRequest(t, h, http.MethodGet, "/v1/browsers", "wrong", "").Code)
+	assert.Equal(t, http.StatusOK, doAPIRequest(t, h, http.MethodGet, "/v1/browsers", "s3cret", "").Code)
+}
+
+func TestAPIGateway_CreateBrowserMapsRequest(t *testing.T) {
+	var captured kernel.BrowserNewParams
+	fake := &FakeBrowsersService{
+		NewFunc: func(ctx context.Context, body kernel.BrowserNewParams, opts ...option.RequestOption) (*kernel.BrowserNewResponse, error) {
+			captured = body
+			return &kernel.BrowserNewResponse{SessionID: "sess-1"}, nil
+		},
+	}
+	h := (&apiGateway{token: "t", browsers: fake}).routes()
+
+	rec := doAPIRequest(t, h, http.MethodPost, "/v1/browsers", "t", `{"name":"ci","headless":true,"tags":{"team":"qa"}}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "ci", captured.Name.Value)
+	assert.True(t, captured.Headless.Value)
+	assert.Equal(t, "qa", captured.Tags["team"])
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, "sess-1", got["session_id"])
+
+	rec = doAPIRequest(t, h, http.MethodPost, "/v1/browsers", "t", `{"viewport":"huge"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = doAPIRequest(t, h, http.MethodPost, "/v1/browsers", "t", `{"bogus":1}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAPIGateway_CreateInvocation(t *testing.T) {
+	var captured kernel.InvocationNewParams
+	inv := &fakeInvocations{newFunc: func(ctx context.Context, body kernel.InvocationNewParams) (*kernel.InvocationNewResponse, error) {
+		captured = body
+		return &kernel.InvocationNewResponse{ID: "inv-1"}, nil
+	}}
+	h := (&apiGateway{token: "t", invocations: inv}).routes()
+
+	rec := doAPIRequest(t, h, http.MethodPost, "/v1/invocations", "t", `{"app":"my-app","action":"run","payload":{"k":1}}`)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "my-app", captured.AppName)
+	assert.Equal(t, "latest", captured.Version)
+	assert.JSONEq(t, `{"k":1}`, captured.Payload.Value)
+	assert.True(t, captured.Async.Value)
+
+	rec = doAPIRequest(t, h, http.MethodPost, "/v1/invocations", "t", `{"app":"my-app"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "app and action are required")
+}
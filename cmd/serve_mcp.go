@@ -0,0 +1,605 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/spf13/cobra"
+)
+
+const (
+	mcpProtocolVersion  = "2024-11-05"
+	mcpMaxMessageBytes  = 4 << 20
+	mcpInvokePollPeriod = time.Second
+)
+
+// JSON-RPC 2.0 error codes used by the MCP server.
+const (
+	jsonrpcParseError     = -32700
+	jsonrpcInvalidRequest = -32600
+	jsonrpcMethodNotFound = -32601
+	jsonrpcInvalidParams  = -32602
+)
+
+// mcpDefaultTools are enabled when --allow-tools isn't given. Tools that run
+// commands inside or tear down browsers must be opted into explicitly.
+var mcpDefaultTools = []string{"list_browsers", "create_browser", "run_auth", "invoke_app"}
+
+type jsonrpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type jsonrpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *jsonrpcError   `json:"error,omitempty"`
+}
+
+type jsonrpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type mcpToolDef struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+type mcpContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type mcpToolResult struct {
+	Content []mcpContent `json:"content"`
+	IsError bool         `json:"isError,omitempty"`
+}
+
+type mcpTool struct {
+	def  mcpToolDef
+	call func(ctx context.Context, args json.RawMessage) (any, error)
+}
+
+// mcpServer implements the Model Context Protocol over newline-delimited
+// JSON-RPC on stdio, exposing a fixed set of Kernel operations as tools.
+type mcpServer struct {
+	browsers    BrowsersService
+	process     BrowserProcessService
+	auth        AuthConnectionService
+	invocations InvocationsService
+	allowed     map[string]bool
+}
+
+func newMCPServer(s mcpServer, allow []string) (*mcpServer, error) {
+	known := s.tools()
+	s.allowed = map[string]bool{}
+	for _, name := range allow {
+		if _, ok := known[name]; !ok {
+			names := make([]string, 0, len(known))
+			for n := range known {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("unknown tool %q (available: %s)", name, strings.Join(names, ", "))
+		}
+		s.allowed[name] = true
+	}
+	return &s, nil
+}
+
+func mcpSchema(required []string, props map[string]any) map[string]any {
+	schema := map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (s *mcpServer) tools() map[string]mcpTool {
+	str := func(desc string) map[string]any { return map[string]any{"type": "string", "description": desc} }
+	boolean := func(desc string) map[string]any { return map[string]any{"type": "boolean", "description": desc} }
+	integer := func(desc string) map[string]any { return map[string]any{"type": "integer", "description": desc} }
+
+	return map[string]mcpTool{
+		"list_browsers": {
+			def: mcpToolDef{
+				Name:        "list_browsers",
+				Description: "List running Kernel browser sessions.",
+				InputSchema: mcpSchema(nil, map[string]any{"query": str("Filter by session ID, name, or profile")}),
+			},
+			call: s.listBrowsers,
+		},
+		"create_browser": {
+			def: mcpToolDef{
+				Name:        "create_browser",
+				Description: "Create a Kernel browser session and return its CDP and live view URLs.",
+				InputSchema: mcpSchema(nil, map[string]any{
+					"name":            str("Optional browser name"),
+					"headless":        boolean("Launch without a GUI or live view"),
+					"stealth":         boolean("Launch in stealth mode"),
+					"timeout_seconds": integer("Idle timeout in seconds"),
+					"profile_name":    str("Profile to load into the browser"),
+					"proxy_id":        str("Proxy to route traffic through"),
+					"start_url":       str("URL to open once the browser is ready"),
+					"viewport":        str("Viewport as WIDTHxHEIGHT[@RATE]"),
+				}),
+			},
+			call: s.createBrowser,
+		},
+		"delete_browser": {
+			def: mcpToolDef{
+				Name:        "delete_browser",
+				Description: "Delete a Kernel browser session.",
+				InputSchema: mcpSchema([]string{"id"}, map[string]any{"id": str("Browser session ID")}),
+			},
+			call: s.deleteBrowser,
+		},
+		"run_auth": {
+			def: mcpToolDef{
+				Name:        "run_auth",
+				Description: "Start a login flow for a managed auth connection and return the hosted URL to complete it.",
+				InputSchema: mcpSchema([]string{"id"}, map[string]any{
+					"id":       str("Auth connection ID"),
+					"proxy_id": str("Proxy to use for the login browser"),
+				}),
+			},
+			call: s.runAuth,
+		},
+		"invoke_app": {
+			def: mcpToolDef{
+				Name:        "invoke_app",
+				Description: "Invoke a deployed Kernel app action and wait for it to finish.",
+				InputSchema: mcpSchema([]string{"app", "action"}, map[string]any{
+					"app":     str("App name"),
+					"action":  str("Action name"),
+					"version": str("App version (default: latest)"),
+					"payload": map[string]any{"type": "object", "description": "JSON payload passed to the action"},
+				}),
+			},
+			call: s.invokeApp,
+		},
+		"exec_in_browser": {
+			def: mcpToolDef{
+				Name:        "exec_in_browser",
+				Description: "Run a command inside a browser VM and return its exit code, stdout, and stderr.",
+				InputSchema: mcpSchema([]string{"id", "command"}, map[string]any{
+					"id":          str("Browser session ID"),
+					"command":     str("Executable to run"),
+					"args":        map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Command arguments"},
+					"cwd":         str("Working directory"),
+					"timeout_sec": integer("Maximum run time in seconds"),
+				}),
+			},
+			call: s.execInBrowser,
+		},
+	}
+}
+
+// Serve reads requests from r and writes responses to w until r is exhausted
+// or ctx is cancelled. Tool calls run concurrently, so a long invoke_app
+// doesn't hold up other requests; clients match responses by id. Calls still
+// running when r ends are waited for.
+func (s *mcpServer) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), mcpMaxMessageBytes)
+	enc := json.NewEncoder(w)
+
+	// scanErr is sent exactly once, before lines is closed, so the loop
+	// below can always receive it once lines is closed.
+	lines := make(chan []byte)
+	scanErr := make(chan error, 1)
+	go func() {
+		var err error
+		defer func() {
+			scanErr <- err
+			close(lines)
+		}()
+		for scanner.Scan() {
+			select {
+			case lines <- slices.Clone(scanner.Bytes()):
+			case <-ctx.Done():
+				return
+			}
+		}
+		err = scanner.Err()
+	}()
+
+	var (
+		mu       sync.Mutex
+		writeErr error
+		calls    sync.WaitGroup
+		inflight = map[string]context.CancelFunc{}
+	)
+	defer calls.Wait()
+	write := func(resp *jsonrpcResponse) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if writeErr == nil {
+			writeErr = enc.Encode(resp)
+		}
+		return writeErr
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case line, ok := <-lines:
+			if !ok {
+				return <-scanErr
+			}
+			if len(strings.TrimSpace(string(line))) == 0 {
+				continue
+			}
+			var req jsonrpcRequest
+			if err := json.Unmarshal(line, &req); err != nil {
+				if err := write(&jsonrpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &jsonrpcError{Code: jsonrpcParseError, Message: err.Error()}}); err != nil {
+					return err
+				}
+				continue
+			}
+			switch {
+			case req.Method == "notifications/cancelled":
+				var params struct {
+					RequestID json.RawMessage `json:"requestId"`
+				}
+				if json.Unmarshal(req.Params, &params) == nil {
+					mu.Lock()
+					if cancel, ok := inflight[string(params.RequestID)]; ok {
+						delete(inflight, string(params.RequestID))
+						cancel()
+					}
+					mu.Unlock()
+				}
+			case req.Method == "tools/call" && len(req.ID) > 0:
+				id := string(req.ID)
+				mu.Lock()
+				_, dup := inflight[id]
+				mu.Unlock()
+				if dup {
+					// Replacing the running call would lose its cancel func
+					if err := write(&jsonrpcResponse{JSONRPC: "2.0", ID: req.ID, Error: &jsonrpcError{Code: jsonrpcInvalidRequest, Message: "request id " + id + " is already in use by a running call"}}); err != nil {
+						return err
+					}
+					continue
+				}
+				callCtx, cancel := context.WithCancel(ctx)
+				mu.Lock()
+				inflight[id] = cancel
+				mu.Unlock()
+				calls.Add(1)
+				go func() {
+					defer calls.Done()
+					defer cancel()
+					resp := s.handle(callCtx, req)
+					mu.Lock()
+					_, live := inflight[id]
+					delete(inflight, id)
+					mu.Unlock()
+					// Requests the client cancelled get no response
+					if live {
+						_ = write(resp)
+					}
+				}()
+			default:
+				if resp := s.handle(ctx, req); resp != nil {
+					if err := write(resp); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+}
+
+// handle processes one JSON-RPC message. Notifications (no id) get no response.
+func (s *mcpServer) handle(ctx context.Context, req jsonrpcRequest) *jsonrpcResponse {
+	if len(req.ID) == 0 {
+		return nil
+	}
+	resp := &jsonrpcResponse{JSONRPC: "2.0", ID: req.ID}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		resp.Error = &jsonrpcError{Code: jsonrpcInvalidRequest, Message: "invalid JSON-RPC 2.0 request"}
+		return resp
+	}
+
+	switch req.Method {
+	case "initialize":
+		resp.Result = map[string]any{
+			"protocolVersion": mcpProtocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "kernel", "version": metadata.Version},
+		}
+	case "ping":
+		resp.Result = map[string]any{}
+	case "tools/list":
+		resp.Result = map[string]any{"tools": s.allowedToolDefs()}
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			resp.Error = &jsonrpcError{Code: jsonrpcInvalidParams, Message: err.Error()}
+			return resp
+		}
+		tool, ok := s.tools()[params.Name]
+		if !ok || !s.allowed[params.Name] {
+			resp.Error = &jsonrpcError{Code: jsonrpcInvalidParams, Message: fmt.Sprintf("tool %q is not available", params.Name)}
+			return resp
+		}
+		resp.Result = s.callTool(ctx, tool, params.Arguments)
+	default:
+		resp.Error = &jsonrpcError{Code: jsonrpcMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)}
+	}
+	return resp
+}
+
+func (s *mcpServer) allowedToolDefs() []mcpToolDef {
+	defs := []mcpToolDef{}
+	for name, tool := range s.tools() {
+		if s.allowed[name] {
+			defs = append(defs, tool.def)
+		}
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs
+}
+
+// callTool runs a tool and wraps its outcome as MCP content. Tool failures are
+// reported in-band with isError so the model can see and react to them.
+func (s *mcpServer) callTool(ctx context.Context, tool mcpTool, args json.RawMessage) mcpToolResult {
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage("{}")
+	}
+	out, err := tool.call(ctx, args)
+	if err != nil {
+		var sdkErr *kernel.Error
+		if errors.As(err, &sdkErr) {
+			err = util.CleanedUpSdkError{Err: err}
+		}
+		return mcpToolResult{Content: []mcpContent{{Type: "text", Text: err.Error()}}, IsError: true}
+	}
+	var text string
+	if p, ok := out.(util.RawJSONProvider); ok && p.RawJSON() != "" {
+		text = p.RawJSON()
+	} else {
+		b, err := json.Marshal(out)
+		if err != nil {
+			return mcpToolResult{Content: []mcpContent{{Type: "text", Text: err.Error()}}, IsError: true}
+		}
+		text = string(b)
+	}
+	return mcpToolResult{Content: []mcpContent{{Type: "text", Text: text}}}
+}
+
+func decodeToolArgs(args json.RawMessage, v any) error {
+	dec := json.NewDecoder(strings.NewReader(string(args)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+func (s *mcpServer) listBrowsers(ctx context.Context, args json.RawMessage) (any, error) {
+	var in struct {
+		Query string `json:"query"`
+	}
+	if err := decodeToolArgs(args, &in); err != nil {
+		return nil, err
+	}
+	params := kernel.BrowserListParams{Status: kernel.BrowserListParamsStatusActive}
+	if in.Query != "" {
+		params.Query = kernel.Opt(in.Query)
+	}
+	page, err := s.browsers.List(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if page == nil {
+		return []kernel.BrowserListResponse{}, nil
+	}
+	return page.Items, nil
+}
+
+func (s *mcpServer) createBrowser(ctx context.Context, args json.RawMessage) (any, error) {
+	var in struct {
+		Name           string `json:"name"`
+		Headless       *bool  `json:"headless"`
+		Stealth        *bool  `json:"stealth"`
+		TimeoutSeconds int    `json:"timeout_seconds"`
+		ProfileName    string `json:"profile_name"`
+		ProxyID        string `json:"proxy_id"`
+		StartURL       string `json:"start_url"`
+		Viewport       string `json:"viewport"`
+	}
+	if err := decodeToolArgs(args, &in); err != nil {
+		return nil, err
+	}
+	params, err := buildBrowserNewParams(BrowsersCreateInput{
+		Name:           in.Name,
+		Headless:       optionalBoolFlag(in.Headless),
+		Stealth:        optionalBoolFlag(in.Stealth),
+		TimeoutSeconds: in.TimeoutSeconds,
+		ProfileName:    in.ProfileName,
+		ProxyID:        in.ProxyID,
+		StartURL:       in.StartURL,
+		Viewport:       in.Viewport,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.browsers.New(ctx, params)
+}
+
+func (s *mcpServer) deleteBrowser(ctx context.Context, args json.RawMessage) (any, error) {
+	var in struct {
+		ID string `json:"id"`
+	}
+	if err := decodeToolArgs(args, &in); err != nil {
+		return nil, err
+	}
+	if in.ID == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	if err := s.browsers.DeleteByID(ctx, in.ID); err != nil && !util.IsNotFound(err) {
+		return nil, err
+	}
+	return map[string]any{"deleted": in.ID}, nil
+}
+
+func (s *mcpServer) runAuth(ctx context.Context, args json.RawMessage) (any, error) {
+	var in struct {
+		ID      string `json:"id"`
+		ProxyID string `json:"proxy_id"`
+	}
+	if err := decodeToolArgs(args, &in); err != nil {
+		return nil, err
+	}
+	if in.ID == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	params := kernel.AuthConnectionLoginParams{}
+	if in.ProxyID != "" {
+		params.Proxy = kernel.AuthConnectionLoginParamsProxy{ID: kernel.Opt(in.ProxyID)}
+	}
+	return s.auth.Login(ctx, in.ID, params)
+}
+
+func (s *mcpServer) invokeApp(ctx context.Context, args json.RawMessage) (any, error) {
+	var in struct {
+		App     string          `json:"app"`
+		Action  string          `json:"action"`
+		Version string          `json:"version"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := decodeToolArgs(args, &in); err != nil {
+		return nil, err
+	}
+	if in.App == "" || in.Action == "" {
+		return nil, fmt.Errorf("app and action are required")
+	}
+	if in.Version == "" {
+		in.Version = "latest"
+	}
+	params := kernel.InvocationNewParams{AppName: in.App, ActionName: in.Action, Version: in.Version, Async: kernel.Opt(true)}
+	if len(in.Payload) > 0 && string(in.Payload) != "null" {
+		params.Payload = kernel.Opt(string(in.Payload))
+	}
+	resp, err := s.invocations.New(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		inv, err := s.invocations.Get(ctx, resp.ID)
+		if err != nil {
+			return nil, err
+		}
+		if inv.Status != kernel.InvocationGetResponseStatusQueued && inv.Status != kernel.InvocationGetResponseStatusRunning {
+			return inv, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(mcpInvokePollPeriod):
+		}
+	}
+}
+
+func (s *mcpServer) execInBrowser(ctx context.Context, args json.RawMessage) (any, error) {
+	var in struct {
+		ID         string   `json:"id"`
+		Command    string   `json:"command"`
+		Args       []string `json:"args"`
+		Cwd        string   `json:"cwd"`
+		TimeoutSec int64    `json:"timeout_sec"`
+	}
+	if err := decodeToolArgs(args, &in); err != nil {
+		return nil, err
+	}
+	if in.ID == "" || in.Command == "" {
+		return nil, fmt.Errorf("id and command are required")
+	}
+	params := kernel.BrowserProcessExecParams{Command: in.Command, Args: in.Args}
+	if in.Cwd != "" {
+		params.Cwd = kernel.Opt(in.Cwd)
+	}
+	if in.TimeoutSec > 0 {
+		params.TimeoutSec = kernel.Opt(in.TimeoutSec)
+	}
+	res, err := s.process.Exec(ctx, in.ID, params)
+	if err != nil {
+		return nil, err
+	}
+	stdout, _ := base64.StdEncoding.DecodeString(res.StdoutB64)
+	stderr, _ := base64.StdEncoding.DecodeString(res.StderrB64)
+	return map[string]any{
+		"exit_code":   res.ExitCode,
+		"duration_ms": res.DurationMs,
+		"stdout":      string(stdout),
+		"stderr":      string(stderr),
+	}, nil
+}
+
+var serveMCPCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Serve Kernel tools to AI agents over MCP stdio",
+	Long: `Run a Model Context Protocol server on stdin/stdout so local LLM agents can drive Kernel
+with the CLI's current credentials and project.
+
+Available tools: list_browsers, create_browser, delete_browser, run_auth, invoke_app,
+exec_in_browser. Only list_browsers, create_browser, run_auth and invoke_app are enabled by
+default; use --allow-tools to choose exactly which tools the agent may call.
+
+Example MCP client config:
+  {"command": "kernel", "args": ["serve", "mcp", "--allow-tools", "list_browsers,create_browser"]}`,
+	Args: cobra.NoArgs,
+	RunE: runServeMCP,
+}
+
+func init() {
+	serveMCPCmd.Flags().StringSlice("allow-tools", mcpDefaultTools, "Tools the agent may call")
+}
+
+func runServeMCP(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	allow, _ := cmd.Flags().GetStringSlice("allow-tools")
+
+	browsers := client.Browsers
+	auth := client.Auth.Connections
+	invocations := client.Invocations
+	srv, err := newMCPServer(mcpServer{
+		browsers:    &browsers,
+		process:     &browsers.Process,
+		auth:        &auth,
+		invocations: &invocations,
+	}, allow)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return srv.Serve(ctx, os.Stdin, os.Stdout)
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runMCPSession(t *testing.T, srv *mcpServer, requests ...string) []jsonrpcResponse {
+	t.Helper()
+	var out bytes.Buffer
+	require.NoError(t, srv.Serve(context.Background(), strings.NewReader(strings.Join(requests, "\n")), &out))
+	var responses []jsonrpcResponse
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var resp jsonrpcResponse
+		require.NoError(t, dec.Decode(&resp))
+		responses = append(responses, resp)
+	}
+	// Tool calls run concurrently, so order responses by id
+	sort.SliceStable(responses, func(i, j int) bool { return string(responses[i].ID) < string(responses[j].ID) })
+	return responses
+}
+
+func TestMCPServer_ListsOnlyAllowedTools(t *testing.T) {
+	srv, err := newMCPServer(mcpServer{}, []string{"invoke_app", "list_browsers"})
+	require.NoError(t, err)
+
+	responses := runMCPSession(t, srv,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"exec_in_browser","arguments":{"id":"b","command":"ls"}}}`,
+	)
+	require.Len(t, responses, 3)
+	assert.Nil(t, responses[0].Error)
+
+	b, _ := json.Marshal(responses[1].Result)
+	var list struct {
+		Tools []mcpToolDef `json:"tools"`
+	}
+	require.NoError(t, json.Unmarshal(b, &list))
+	require.Len(t, list.Tools, 2)
+	assert.Equal(t, "invoke_app", list.Tools[0].Name)
+	assert.Equal(t, "list_browsers", list.Tools[1].Name)
+
+	require.NotNil(t, responses[2].Error)
+	assert.Contains(t, responses[2].Error.Message, "not available")
+
+	_, err = newMCPServer(mcpServer{}, []string{"rm_rf"})
+	assert.ErrorContains(t, err, `unknown tool "rm_rf"`)
+}
+
+func TestMCPServer_InvokeAppWaitsForResult(t *testing.T) {
+	var captured kernel.InvocationNewParams
+	polls := 0
+	inv := &fakeLoadtestInvocations{
+		newFunc: func(ctx context.Context, body kernel.InvocationNewParams) (*kernel.InvocationNewResponse, error) {
+			captured = body
+			return &kernel.InvocationNewResponse{ID: "inv-1", Status: kernel.InvocationNewResponseStatusQueued}, nil
+		},
+		getFunc: func(ctx context.Context, id string) (*kernel.InvocationGetResponse, error) {
+			polls++
+			return &kernel.InvocationGetResponse{ID: id, Status: kernel.InvocationGetResponseStatusSucceeded, Output: `{"ok":true}`}, nil
+		},
+	}
+	srv, err := newMCPServer(mcpServer{invocations: inv}, mcpDefaultTools)
+	require.NoError(t, err)
+
+	responses := runMCPSession(t, srv,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"invoke_app","arguments":{"app":"scraper","action":"run","payload":{"url":"https://example.com"}}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"invoke_app","arguments":{"app":"scraper"}}}`,
+	)
+	require.Len(t, responses, 2)
+
+	assert.Equal(t, "scraper", captured.AppName)
+	assert.JSONEq(t, `{"url":"https://example.com"}`, captured.Payload.Value)
+	assert.Equal(t, 1, polls)
+
+	var ok, bad mcpToolResult
+	b, _ := json.Marshal(responses[0].Result)
+	require.NoError(t, json.Unmarshal(b, &ok))
+	assert.False(t, ok.IsError)
+	assert.Contains(t, ok.Content[0].Text, "succeeded")
+
+	b, _ = json.Marshal(responses[1].Result)
+	require.NoError(t, json.Unmarshal(b, &bad))
+	assert.True(t, bad.IsError)
+	assert.Contains(t, bad.Content[0].Text, "app and action are required")
+}
+
+func TestMCPServer_ToolCallsDontBlockOtherRequests(t *testing.T) {
+	cancelled := make(chan struct{})
+	inv := &fakeLoadtestInvocations{
+		newFunc: func(ctx context.Context, body kernel.InvocationNewParams) (*kernel.InvocationNewResponse, error) {
+			return &kernel.InvocationNewResponse{ID: "inv-1"}, nil
+		},
+		getFunc: func(ctx context.Context, id string) (*kernel.InvocationGetResponse, error) {
+			<-ctx.Done()
+			close(cancelled)
+			return nil, ctx.Err()
+		},
+	}
+	srv, err := newMCPServer(mcpServer{invocations: inv}, mcpDefaultTools)
+	require.NoError(t, err)
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(context.Background(), inR, outW)
+		outW.Close()
+	}()
+	send := func(line string) {
+		_, err := io.WriteString(inW, line+"\n")
+		require.NoError(t, err)
+	}
+	dec := json.NewDecoder(outR)
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"invoke_app","arguments":{"app":"scraper","action":"run"}}}`)
+	send(`{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	var resp jsonrpcResponse
+	require.NoError(t, dec.Decode(&resp))
+	assert.Equal(t, "2", string(resp.ID), "ping is answered while invoke_app is still waiting")
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"list_browsers","arguments":{}}}`)
+	resp = jsonrpcResponse{}
+	require.NoError(t, dec.Decode(&resp))
+	assert.Equal(t, "1", string(resp.ID))
+	require.NotNil(t, resp.Error, "an id already in flight is rejected")
+	assert.Contains(t, resp.Error.Message, "already in use")
+
+	send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1}}`)
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("invoke_app was not cancelled")
+	}
+	require.NoError(t, inW.Close())
+	require.NoError(t, <-done)
+	assert.False(t, dec.More(), "a cancelled call gets no response")
+}
+
+// gatedWriter blocks the first Write until release is closed.
+type gatedWriter struct {
+	once    sync.Once
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.entered) })
+	<-w.release
+	return len(p), nil
+}
+
+func TestMCPServer_ReturnsWhenCancelled(t *testing.T) {
+	srv, err := newMCPServer(mcpServer{}, mcpDefaultTools)
+	require.NoError(t, err)
+	requests := strings.Repeat(`{"jsonrpc":"2.0","id":1,"method":"ping"}`+"\n", 3)
+
+	for i := 0; i < 20; i++ {
+		// Cancel while a response is being written, so the reader stops
+		// mid-input and the loop then sees both ctx.Done and closed lines.
+		ctx, cancel := context.WithCancel(context.Background())
+		w := &gatedWriter{entered: make(chan struct{}), release: make(chan struct{})}
+		done := make(chan error, 1)
+		go func() { done <- srv.Serve(ctx, strings.NewReader(requests), w) }()
+		<-w.entered
+		cancel()
+		time.Sleep(5 * time.Millisecond)
+		close(w.release)
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Serve didn't return after its context was cancelled")
+		}
+	}
+}
+
+func TestMCPServer_CreateBrowserRejectsUnknownArguments(t *testing.T) {
+	called := false
+	fake := &FakeBrowsersService{
+		NewFunc: func(ctx context.Context, body kernel.BrowserNewParams, opts ...option.RequestOption) (*kernel.BrowserNewResponse, error) {
+			called = true
+			return &kernel.BrowserNewResponse{SessionID: "sess-1"}, nil
+		},
+	}
+	srv, err := newMCPServer(mcpServer{browsers: fake}, mcpDefaultTools)
+	require.NoError(t, err)
+
+	responses := runMCPSession(t, srv,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"create_browser","arguments":{"kiosk":true}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"create_browser","arguments":{"headless":true}}}`,
+	)
+	require.Len(t, responses, 2)
+
+	var rejected, created mcpToolResult
+	b, _ := json.Marshal(responses[0].Result)
+	require.NoError(t, json.Unmarshal(b, &rejected))
+	assert.True(t, rejected.IsError)
+
+	b, _ = json.Marshal(responses[1].Result)
+	require.NoError(t, json.Unmarshal(b, &created))
+	assert.False(t, created.IsError)
+	assert.True(t, called)
+	assert.Contains(t, created.Content[0].Text, "sess-1")
+}
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// slackMaxClockSkew rejects replayed requests, per Slack's verification guide.
+	slackMaxClockSkew = 5 * time.Minute
+	slackMaxBodyBytes = 64 << 10
+	slackListLimit    = 20
+)
+
+const slackHelpText = "Usage: `/kernel <command>`\n" +
+	"• `browsers list [query]` - list running browser sessions\n" +
+	"• `browsers get <id-or-name>` - show one browser session\n" +
+	"• `auth status [domain]` - show managed auth connection health\n" +
+	"• `help` - show this message"
+
+// slackBridge answers Slack slash commands with a fixed set of read-only
+// Kernel queries. Commands are dispatched explicitly rather than by running
+// arbitrary CLI input so that chat users can't reach mutating operations.
+type slackBridge struct {
+	signingSecret string
+	browsers      BrowsersService
+	auth          AuthConnectionService
+	now           func() time.Time
+}
+
+type slackResponse struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+func (s *slackBridge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet && r.URL.Path == "/healthz" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, slackMaxBodyBytes))
+	if err != nil {
+		http.Error(w, "failed to read request", http.StatusBadRequest)
+		return
+	}
+	if err := s.verify(r.Header, body); err != nil {
+		pterm.Warning.Printf("Rejected Slack request: %v\n", err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid form body", http.StatusBadRequest)
+		return
+	}
+
+	text := strings.TrimSpace(form.Get("text"))
+	pterm.Info.Printf("%s: %s %s\n", util.OrDash(form.Get("user_name")), form.Get("command"), text)
+
+	reply, err := s.dispatch(r.Context(), strings.Fields(text))
+	if err != nil {
+		reply = ":warning: " + err.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(slackResponse{ResponseType: "ephemeral", Text: reply})
+}
+
+// verify checks Slack's v0 request signature: an HMAC-SHA256 of
+// "v0:<timestamp>:<body>" keyed with the app's signing secret.
+func (s *slackBridge) verify(h http.Header, body []byte) error {
+	tsHeader := h.Get("X-Slack-Request-Timestamp")
+	ts, err := strconv.ParseInt(tsHeader, 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing or invalid timestamp")
+	}
+	if skew := s.now().Sub(time.Unix(ts, 0)); skew > slackMaxClockSkew || skew < -slackMaxClockSkew {
+		return fmt.Errorf("timestamp outside allowed window")
+	}
+	mac := hmac.New(sha256.New, []byte(s.signingSecret))
+	fmt.Fprintf(mac, "v0:%s:%s", tsHeader, body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(h.Get("X-Slack-Signature"))) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+func (s *slackBridge) dispatch(ctx context.Context, args []string) (string, error) {
+	if len(args) == 0 || args[0] == "help" {
+		return slackHelpText, nil
+	}
+	switch strings.Join(args[:min(2, len(args))], " ") {
+	case "browsers list":
+		return s.browsersList(ctx, strings.Join(args[2:], " "))
+	case "browsers get":
+		if len(args) != 3 {
+			return "", fmt.Errorf("usage: browsers get <id-or-name>")
+		}
+		return s.browsersGet(ctx, args[2])
+	case "auth status":
+		domain := ""
+		if len(args) > 2 {
+			domain = args[2]
+		}
+		return s.authStatus(ctx, domain)
+	}
+	return "", fmt.Errorf("unknown command `%s`\n%s", strings.Join(args, " "), slackHelpText)
+}
+
+func (s *slackBridge) browsersList(ctx context.Context, query string) (string, error) {
+	params := kernel.BrowserListParams{Status: kernel.BrowserListParamsStatusActive, Limit: kernel.Opt(int64(slackListLimit))}
+	if query != "" {
+		params.Query = kernel.Opt(query)
+	}
+	page, err := s.browsers.List(ctx, params)
+	if err != nil {
+		return "", util.CleanedUpSdkError{Err: err}
+	}
+	if page == nil || len(page.Items) == 0 {
+		return "No running browsers found", nil
+	}
+	rows := [][]string{{"ID", "Name", "Created", "Profile"}}
+	for _, b := range page.Items {
+		profile := b.Profile.Name
+		if profile == "" {
+			profile = b.Profile.ID
+		}
+		rows = append(rows, []string{b.SessionID, util.OrDash(b.Name), b.CreatedAt.UTC().Format(time.RFC3339), util.OrDash(profile)})
+	}
+	return fmt.Sprintf("%d running browser(s)\n%s", len(page.Items), slackCodeTable(rows)), nil
+}
+
+func (s *slackBridge) browsersGet(ctx context.Context, idOrName string) (string, error) {
+	b, err := s.browsers.Get(ctx, idOrName, kernel.BrowserGetParams{})
+	if err != nil {
+		return "", util.CleanedUpSdkError{Err: err}
+	}
+	rows := [][]string{
+		{"Session ID", b.SessionID},
+		{"Name", util.OrDash(b.Name)},
+		{"Created", b.CreatedAt.UTC().Format(time.RFC3339)},
+		{"Headless", strconv.FormatBool(b.Headless)},
+		{"Stealth", strconv.FormatBool(b.Stealth)},
+		{"Timeout", fmt.Sprintf("%ds", b.TimeoutSeconds)},
+	}
+	if b.BrowserLiveViewURL != "" {
+		rows = append(rows, []string{"Live View", b.BrowserLiveViewURL})
+	}
+	return slackCodeTable(rows), nil
+}
+
+func (s *slackBridge) authStatus(ctx context.Context, domain string) (string, error) {
+	params := kernel.AuthConnectionListParams{Limit: kernel.Opt(int64(slackListLimit))}
+	if domain != "" {
+		params.Domain = kernel.Opt(domain)
+	}
+	page, err := s.auth.List(ctx, params)
+	if err != nil {
+		return "", util.CleanedUpSdkError{Err: err}
+	}
+	if page == nil || len(page.Items) == 0 {
+		return "No managed auth connections found", nil
+	}
+	needsAuth := 0
+	rows := [][]string{{"ID", "Domain", "Profile", "Status", "Flow"}}
+	for _, a := range page.Items {
+		if a.Status == kernel.ManagedAuthStatusNeedsAuth {
+			needsAuth++
+		}
+		rows = append(rows, []string{a.ID, a.Domain, a.ProfileName, string(a.Status), util.OrDash(string(a.FlowStatus))})
+	}
+	summary := fmt.Sprintf(":white_check_mark: %d connection(s) authenticated", len(page.Items))
+	if needsAuth > 0 {
+		summary = fmt.Sprintf(":warning: %d of %d connection(s) need auth", needsAuth, len(page.Items))
+	}
+	return summary + "\n" + slackCodeTable(rows), nil
+}
+
+// slackCodeTable renders rows as a space-aligned table in a Slack code block.
+func slackCodeTable(rows [][]string) string {
+	widths := map[int]int{}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len([]rune(cell)))
+		}
+	}
+	var b strings.Builder
+	b.WriteString("```\n")
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(row)-1 {
+				b.WriteString(cell)
+				break
+			}
+			b.WriteString(cell + strings.Repeat(" ", widths[i]-len([]rune(cell))+2))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("```")
+	return b.String()
+}
+
+var serveSlackCmd = &cobra.Command{
+	Use:   "slack",
+	Short: "Answer Slack slash commands with Kernel state",
+	Long: `Run an HTTP server that answers Slack slash commands (e.g. "/kernel browsers list")
+using the CLI's current credentials and project. Point the slash command's Request URL at
+this server. Only read-only queries are supported; run "/kernel help" in Slack for the list.
+
+Requests are verified with the Slack app's signing secret (--signing-secret or
+SLACK_SIGNING_SECRET).`,
+	Args: cobra.NoArgs,
+	RunE: runServeSlack,
+}
+
+func init() {
+	serveSlackCmd.Flags().Int("port", 8080, "Port to listen on")
+	serveSlackCmd.Flags().String("host", "", "Interface to bind (default: all interfaces)")
+	serveSlackCmd.Flags().String("signing-secret", "", "Slack app signing secret (defaults to SLACK_SIGNING_SECRET)")
+}
+
+func runServeSlack(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	port, _ := cmd.Flags().GetInt("port")
+	host, _ := cmd.Flags().GetString("host")
+	secret, _ := cmd.Flags().GetString("signing-secret")
+	if secret == "" {
+		secret = os.Getenv("SLACK_SIGNING_SECRET")
+	}
+	if secret == "" {
+		return fmt.Errorf("a Slack signing secret is required (--signing-secret or SLACK_SIGNING_SECRET)")
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	browsers := client.Browsers
+	auth := client.Auth.Connections
+	bridge := &slackBridge{
+		signingSecret: secret,
+		browsers:      &browsers,
+		auth:          &auth,
+		now:           time.Now,
+	}
+	return runHTTPServer(ctx, fmt.Sprintf("%s:%d", host, port), bridge)
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/kernel/kernel-go-sdk/packages/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedSlackRequest(t *testing.T, secret string, ts time.Time, text string) *http.Request {
+	t.Helper()
+	body := url.Values{"command": {"/kernel"}, "text": {text}, "user_name": {"oncall"}}.Encode()
+	tsStr := fmt.Sprint(ts.Unix())
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", tsStr, body)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", tsStr)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestSlackBridge_RejectsBadSignatureAndStaleRequests(t *testing.T) {
+	setupStdoutCapture(t)
+	now := time.Unix(1_700_000_000, 0)
+	bridge := &slackBridge{signingSecret: "secret", browsers: &FakeBrowsersService{}, now: func() time.Time { return now }}
+
+	rec := httptest.NewRecorder()
+	bridge.ServeHTTP(rec, signedSlackRequest(t, "wrong", now, "help"))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = httptest.NewRecorder()
+	bridge.ServeHTTP(rec, signedSlackRequest(t, "secret", now.Add(-10*time.Minute), "help"))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = httptest.NewRecorder()
+	bridge.ServeHTTP(rec, signedSlackRequest(t, "secret", now, "help"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestSlackBridge_DispatchesCommands(t *testing.T) {
+	setupStdoutCapture(t)
+	now := time.Unix(1_700_000_000, 0)
+
+	var gotQuery string
+	bridge := &slackBridge{
+		signingSecret: "secret",
+		now:           func() time.Time { return now },
+		browsers: &FakeBrowsersService{
+			ListFunc: func(ctx context.Context, query kernel.BrowserListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.BrowserListResponse], error) {
+				gotQuery = query.Query.Value
+				return &pagination.OffsetPagination[kernel.BrowserListResponse]{Items: []kernel.BrowserListResponse{
+					{SessionID: "sess-1", Name: "checkout"},
+				}}, nil
+			},
+		},
+		auth: &FakeAuthConnectionService{
+			ListFunc: func(ctx context.Context, query kernel.AuthConnectionListParams, opts ...option.RequestOption) (*pagination.OffsetPagination[kernel.ManagedAuth], error) {
+				return &pagination.OffsetPagination[kernel.ManagedAuth]{Items: []kernel.ManagedAuth{
+					{ID: "conn-1", Domain: "example.com", Status: kernel.ManagedAuthStatusAuthenticated},
+					{ID: "conn-2", Domain: "shop.com", Status: kernel.ManagedAuthStatusNeedsAuth},
+				}}, nil
+			},
+		},
+	}
+
+	reply := func(text string) string {
+		rec := httptest.NewRecorder()
+		bridge.ServeHTTP(rec, signedSlackRequest(t, "secret", now, text))
+		require.Equal(t, http.StatusOK, rec.Code)
+		var resp slackResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "ephemeral", resp.ResponseType)
+		return resp.Text
+	}
+
+	out := reply("browsers list checkout")
+	assert.Equal(t, "checkout", gotQuery)
+	assert.Contains(t, out, "sess-1")
+	assert.Contains(t, out, "```")
+
+	out = reply("auth status")
+	assert.Contains(t, out, "1 of 2 connection(s) need auth")
+	assert.Contains(t, out, "shop.com")
+
+	out = reply("browsers delete sess-1")
+	assert.Contains(t, out, "unknown command")
+}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"context"
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -12,6 +11,7 @@ import (
 	"syscall"
 
 	"github.com/kernel/cli/pkg/ssh"
+	"github.com/kernel/cli/pkg/util"
 	"github.com/kernel/kernel-go-sdk"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
@@ -32,7 +32,8 @@ var sshCmd = &cobra.Command{
 	Long: `Establish an SSH connection to a running browser VM.
 
 By default, generates an ephemeral SSH keypair and opens an interactive shell.
-Use -i to specify an existing SSH private key instead.
+Use -i to specify an existing SSH private key instead, or --key to use one
+saved with 'kernel ssh-keys add'.
 
 Port forwarding uses standard SSH syntax:
   -L localport:host:remoteport   Forward local port to remote
@@ -49,13 +50,18 @@ Examples:
   kernel browsers ssh abc123def456 -L 5432:localhost:5432
 
   # Use existing SSH key
-  kernel browsers ssh abc123def456 -i ~/.ssh/id_ed25519`,
+  kernel browsers ssh abc123def456 -i ~/.ssh/id_ed25519
+
+  # Use a saved key
+  kernel browsers ssh abc123def456 --key team-debug`,
 	Args: cobra.ExactArgs(1),
 	RunE: runSSH,
 }
 
 func init() {
 	sshCmd.Flags().StringP("identity", "i", "", "Path to SSH private key (generates ephemeral if not provided)")
+	sshCmd.Flags().String("key", "", "Name of a saved SSH key (see 'kernel ssh-keys')")
+	sshCmd.MarkFlagsMutuallyExclusive("identity", "key")
 	sshCmd.Flags().StringP("local-forward", "L", "", "Local port forwarding (localport:host:remoteport)")
 	sshCmd.Flags().StringP("remote-forward", "R", "", "Remote port forwarding (remoteport:host:localport)")
 	sshCmd.Flags().Bool("setup-only", false, "Setup SSH on VM without connecting")
@@ -68,6 +74,13 @@ func runSSH(cmd *cobra.Command, args []string) error {
 	browserID := args[0]
 
 	identityFile, _ := cmd.Flags().GetString("identity")
+	if keyName, _ := cmd.Flags().GetString("key"); keyName != "" {
+		path, err := resolveSSHKeyFlag(keyName)
+		if err != nil {
+			return err
+		}
+		identityFile = path
+	}
 	localForward, _ := cmd.Flags().GetString("local-forward")
 	remoteForward, _ := cmd.Flags().GetString("remote-forward")
 	setupOnly, _ := cmd.Flags().GetBool("setup-only")
@@ -104,7 +117,9 @@ func connectSSH(ctx context.Context, client kernel.Client, cfg ssh.Config) error
 	if !jsonOutput {
 		pterm.Info.Printf("Getting browser %s info...\n", cfg.BrowserID)
 	}
-	browser, err := client.Browsers.Get(ctx, cfg.BrowserID, kernel.BrowserGetParams{})
+	browser, err := withIDPrefix(ctx, "browser", cfg.BrowserID, browserIDLister(&client.Browsers), func(id string) (*kernel.BrowserGetResponse, error) {
+		return client.Browsers.Get(ctx, id, kernel.BrowserGetParams{})
+	})
 	if err != nil {
 		return fmt.Errorf("failed to get browser: %w", err)
 	}
@@ -194,9 +209,7 @@ func connectSSH(ctx context.Context, client kernel.Client, cfg ssh.Config) error
 				ProxyCommand: proxyCmd,
 				SSHCommand:   sshCommand,
 			}
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(result)
+			return util.PrintJSON(result)
 		}
 		pterm.Info.Println("\n--setup-only specified, not connecting.")
 		pterm.Info.Printf("To connect manually:\n")
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kernel/cli/pkg/ssh"
+	"github.com/kernel/cli/pkg/util"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// SSHKeysCmd manages the local store of named SSH keys used by
+// 'kernel browsers ssh --key'.
+type SSHKeysCmd struct {
+	store *ssh.KeyStore
+}
+
+type SSHKeysAddInput struct {
+	Name string
+	// IdentityFile is an existing private key; empty generates a new one.
+	IdentityFile string
+	Output       string
+}
+
+type SSHKeysListInput struct {
+	Output string
+}
+
+type SSHKeysRemoveInput struct {
+	Name string
+}
+
+func (s SSHKeysCmd) Add(in SSHKeysAddInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	var (
+		key *ssh.StoredKey
+		err error
+	)
+	if in.IdentityFile != "" {
+		key, err = s.store.Add(in.Name, in.IdentityFile)
+	} else {
+		key, err = s.store.Generate(in.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if in.Output == "json" {
+		return util.PrintJSON(key)
+	}
+	if key.Generated {
+		pterm.Success.Printf("Generated SSH key '%s'\n", key.Name)
+	} else {
+		pterm.Success.Printf("Added SSH key '%s'\n", key.Name)
+	}
+	PrintTableNoPad(pterm.TableData{
+		{"Property", "Value"},
+		{"Name", key.Name},
+		{"Fingerprint", key.Fingerprint},
+		{"Identity File", key.IdentityFile},
+	}, true)
+	pterm.Info.Printf("Use it with: kernel browsers ssh <id> --key %s\n", key.Name)
+	return nil
+}
+
+func (s SSHKeysCmd) List(in SSHKeysListInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	keys, err := s.store.List()
+	if err != nil {
+		return err
+	}
+	if in.Output == "json" {
+		return util.PrintJSON(keys)
+	}
+	if len(keys) == 0 {
+		pterm.Info.Println("No SSH keys found")
+		return nil
+	}
+	rows := pterm.TableData{{"Name", "Fingerprint", "Identity File", "Added At"}}
+	for _, k := range keys {
+		rows = append(rows, []string{k.Name, k.Fingerprint, k.IdentityFile, util.FormatLocal(k.AddedAt)})
+	}
+	PrintTableNoPad(rows, true)
+	return nil
+}
+
+func (s SSHKeysCmd) Remove(in SSHKeysRemoveInput) error {
+	if err := s.store.Remove(in.Name); err != nil {
+		return err
+	}
+	pterm.Success.Printf("Removed SSH key '%s'\n", in.Name)
+	return nil
+}
+
+var sshKeysCmd = &cobra.Command{
+	Use:     "ssh-keys",
+	Aliases: []string{"ssh-key"},
+	Short:   "Manage reusable SSH keys for browser VMs",
+	Long: `Manage named SSH keys for 'kernel browsers ssh --key <name>', so connections
+can reuse a keypair instead of generating a throwaway one each time.
+
+Keys are kept on this machine under the CLI config directory. Generated keys
+are stored there; added keys are referenced where they are.`,
+}
+
+var sshKeysAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Generate a new SSH key, or register an existing one with -i",
+	Example: `  kernel ssh-keys add team-debug
+  kernel ssh-keys add laptop -i ~/.ssh/id_ed25519`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSSHKeysAdd,
+}
+
+var sshKeysListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List SSH keys",
+	Args:  cobra.NoArgs,
+	RunE:  runSSHKeysList,
+}
+
+var sshKeysRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove an SSH key",
+	Long:    "Remove an SSH key from the store. Generated keys are deleted; added keys are left where they are.",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runSSHKeysRemove,
+}
+
+func init() {
+	sshKeysCmd.AddCommand(sshKeysAddCmd)
+	sshKeysCmd.AddCommand(sshKeysListCmd)
+	sshKeysCmd.AddCommand(sshKeysRemoveCmd)
+
+	sshKeysAddCmd.Flags().StringP("identity", "i", "", "Existing private key to register (its .pub must sit alongside it)")
+	addJSONOutputFlag(sshKeysAddCmd)
+	addJSONOutputFlag(sshKeysListCmd)
+}
+
+func newSSHKeysCmd() (SSHKeysCmd, error) {
+	store, err := ssh.DefaultKeyStore()
+	if err != nil {
+		return SSHKeysCmd{}, err
+	}
+	return SSHKeysCmd{store: store}, nil
+}
+
+func runSSHKeysAdd(cmd *cobra.Command, args []string) error {
+	identity, _ := cmd.Flags().GetString("identity")
+	output, _ := cmd.Flags().GetString("output")
+	s, err := newSSHKeysCmd()
+	if err != nil {
+		return err
+	}
+	return s.Add(SSHKeysAddInput{Name: args[0], IdentityFile: identity, Output: output})
+}
+
+func runSSHKeysList(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	s, err := newSSHKeysCmd()
+	if err != nil {
+		return err
+	}
+	return s.List(SSHKeysListInput{Output: output})
+}
+
+func runSSHKeysRemove(cmd *cobra.Command, args []string) error {
+	s, err := newSSHKeysCmd()
+	if err != nil {
+		return err
+	}
+	return s.Remove(SSHKeysRemoveInput{Name: args[0]})
+}
+
+// resolveSSHKeyFlag turns --key <name> into the stored key's identity file.
+func resolveSSHKeyFlag(name string) (string, error) {
+	store, err := ssh.DefaultKeyStore()
+	if err != nil {
+		return "", err
+	}
+	key, err := store.Get(name)
+	if err != nil {
+		return "", fmt.Errorf("%w (see 'kernel ssh-keys list')", err)
+	}
+	return key.IdentityFile, nil
+}
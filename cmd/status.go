@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/kernel/cli/pkg/util"
@@ -71,9 +70,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	if output == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(status)
+		return util.PrintJSON(status)
 	}
 
 	printStatus(status)
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/kernel/cli/pkg/config"
+	"github.com/kernel/cli/pkg/util"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// slowCallThreshold is how long an API call may take before --timings
+// calls it out on its own line.
+const slowCallThreshold = 3 * time.Second
+
+// apiTimings records the current command's API calls when timings are on.
+var apiTimings *util.CallTimings
+
+// timingsEnabled reports whether the timings footer is on: via --timings,
+// KERNEL_TIMINGS, or "timings: true" in config.yaml, in that order.
+func timingsEnabled(cmd *cobra.Command, cfg *config.Config) bool {
+	if cmd.Flags().Changed("timings") {
+		on, _ := cmd.Flags().GetBool("timings")
+		return on
+	}
+	if v := os.Getenv("KERNEL_TIMINGS"); v != "" {
+		on, err := strconv.ParseBool(v)
+		return err == nil && on
+	}
+	return cfg != nil && cfg.Timings
+}
+
+// printAPITimings writes the timings footer to stderr after human output.
+// JSON output is left untouched so it stays machine-readable.
+func printAPITimings(cmd *cobra.Command, timings *util.CallTimings) {
+	if timings == nil {
+		return
+	}
+	if output, _ := cmd.Flags().GetString("output"); output == "json" {
+		return
+	}
+	summary := timings.Summary()
+	if summary == "" {
+		return
+	}
+	for _, c := range timings.Slow(slowCallThreshold) {
+		pterm.Warning.WithWriter(os.Stderr).Printf("Slow API call: %s %s took %s\n", c.Method, c.Path, c.Duration.Round(100*time.Millisecond))
+	}
+	pterm.Info.WithWriter(os.Stderr).Println(summary)
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/kernel/cli/pkg/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimingsEnabled(t *testing.T) {
+	newCmd := func() *cobra.Command {
+		c := &cobra.Command{Use: "x"}
+		c.Flags().Bool("timings", false, "")
+		return c
+	}
+	on := &config.Config{Timings: true}
+
+	t.Setenv("KERNEL_TIMINGS", "")
+	assert.False(t, timingsEnabled(newCmd(), nil))
+	assert.True(t, timingsEnabled(newCmd(), on), "config.yaml turns timings on")
+
+	t.Setenv("KERNEL_TIMINGS", "0")
+	assert.False(t, timingsEnabled(newCmd(), on), "the env var overrides config.yaml")
+	t.Setenv("KERNEL_TIMINGS", "1")
+	assert.True(t, timingsEnabled(newCmd(), nil))
+
+	c := newCmd()
+	_ = c.Flags().Set("timings", "false")
+	assert.False(t, timingsEnabled(c, on), "the flag overrides everything")
+}
@@ -7,11 +7,11 @@ import (
 	"io"
 	"os"
 	"os/exec"
-	"runtime"
 	"strings"
 	"time"
 
 	"github.com/kernel/cli/pkg/update"
+	"github.com/kernel/cli/pkg/util"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 )
@@ -21,6 +21,12 @@ type UpgradeInput struct {
 	DryRun bool
 }
 
+// UpgradeVerifyInput holds the input parameters for the upgrade verify command.
+type UpgradeVerifyInput struct {
+	BinaryPath string
+	Output     string
+}
+
 // UpgradeCmd handles the upgrade command logic, separated from cobra.
 type UpgradeCmd struct {
 	currentVersion string
@@ -161,12 +167,9 @@ func printManualUpgradeInstructions(version, binaryPath string) {
 	// Normalize version (remove 'v' prefix if present)
 	version = strings.TrimPrefix(version, "v")
 
-	goos := runtime.GOOS
-	goarch := runtime.GOARCH
-
 	downloadURL := fmt.Sprintf(
-		"https://github.com/kernel/cli/releases/download/v%s/kernel_%s_%s_%s.tar.gz",
-		version, version, goos, goarch,
+		"https://github.com/kernel/cli/releases/download/v%s/%s",
+		version, update.ArchiveName(version, update.DetectPlatform()),
 	)
 
 	if binaryPath == "" {
@@ -182,6 +185,59 @@ func printManualUpgradeInstructions(version, binaryPath string) {
 	pterm.Println()
 }
 
+// Verify checks the installed binary against the release it claims to be.
+func (u UpgradeCmd) Verify(ctx context.Context, in UpgradeVerifyInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	binaryPath := in.BinaryPath
+	if binaryPath == "" {
+		_, binaryPath = update.DetectInstallMethod()
+	}
+	if binaryPath == "" {
+		return fmt.Errorf("could not locate the kernel binary")
+	}
+	if in.Output != "json" {
+		pterm.Info.Printf("Verifying %s against release v%s...\n", binaryPath, strings.TrimPrefix(u.currentVersion, "v"))
+	}
+
+	report, err := update.VerifyInstalled(ctx, u.currentVersion, binaryPath)
+	if err != nil {
+		return err
+	}
+	if in.Output == "json" {
+		if err := util.PrintJSON(report); err != nil {
+			return err
+		}
+	} else {
+		rows := pterm.TableData{{"Check", "Status", "Detail"}}
+		for _, c := range report.Checks {
+			status := string(c.Status)
+			switch c.Status {
+			case update.CheckPassed:
+				status = pterm.Green(status)
+			case update.CheckFailed:
+				status = pterm.Red(status)
+			}
+			rows = append(rows, []string{c.Name, status, c.Detail})
+		}
+		PrintTableNoPad(rows, true)
+	}
+	if report.Status == update.StatusFailedCheck {
+		return fmt.Errorf("installed binary failed verification against v%s (%s)", report.Version, report.Archive)
+	}
+	if !report.Verified {
+		if in.Output != "json" {
+			pterm.Warning.Printf("%s matches %s from release v%s, but is unverified (no signature): the release's checksum file couldn't be checked against a signing key\n", binaryPath, report.Archive, report.Version)
+		}
+		return nil
+	}
+	if in.Output != "json" {
+		pterm.Success.Printf("%s matches %s from release v%s\n", binaryPath, report.Archive, report.Version)
+	}
+	return nil
+}
+
 var upgradeCmd = &cobra.Command{
 	Use:     "upgrade",
 	Aliases: []string{"update"},
@@ -198,8 +254,32 @@ If your installation method cannot be detected, manual upgrade instructions will
 	RunE: runUpgrade,
 }
 
+var upgradeVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the installed binary against its published release",
+	Long: `Check that the installed kernel binary is the one published for its version.
+
+The release's checksum file is checked against its minisign and cosign
+signatures when the CLI was built with the matching public keys (or
+KERNEL_MINISIGN_PUBKEY / KERNEL_COSIGN_PUBKEY are set). The archive for the
+platform the binary was built for (amd64 for an amd64 build under Rosetta,
+with a musl build preferred on musl systems when the release has one) is checked against the checksum file, and the binary
+inside it is compared byte for byte with the installed one.
+
+Without a signature the result is reported as "unverified (no signature)":
+the checksums only show the archive matches a file published next to it.
+Exits non-zero if any check fails.`,
+	Example: `  kernel update verify
+  kernel update verify -o json`,
+	Args: cobra.NoArgs,
+	RunE: runUpgradeVerify,
+}
+
 func init() {
 	upgradeCmd.Flags().Bool("dry-run", false, "Show what would be executed without running")
+	upgradeCmd.AddCommand(upgradeVerifyCmd)
+	upgradeVerifyCmd.Flags().String("binary", "", "Binary to verify (default: the running kernel)")
+	addJSONOutputFlag(upgradeVerifyCmd)
 }
 
 func runUpgrade(cmd *cobra.Command, args []string) error {
@@ -212,3 +292,12 @@ func runUpgrade(cmd *cobra.Command, args []string) error {
 		DryRun: dryRun,
 	})
 }
+
+func runUpgradeVerify(cmd *cobra.Command, args []string) error {
+	binary, _ := cmd.Flags().GetString("binary")
+	output, _ := cmd.Flags().GetString("output")
+	ctx, cancel := context.WithTimeout(cmd.Context(), 5*time.Minute)
+	defer cancel()
+	u := UpgradeCmd{currentVersion: metadata.Version}
+	return u.Verify(ctx, UpgradeVerifyInput{BinaryPath: binary, Output: output})
+}
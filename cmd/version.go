@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kernel/cli/pkg/update"
+	"github.com/kernel/cli/pkg/util"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// apiCompatibility is what the API advertises about the CLI versions it
+// supports and the features it has turned on.
+type apiCompatibility struct {
+	MinCLIVersion string          `json:"min_cli_version"`
+	MaxCLIVersion string          `json:"max_cli_version,omitempty"`
+	Features      map[string]bool `json:"features"`
+}
+
+type versionAPIReport struct {
+	BaseURL       string          `json:"base_url"`
+	MinCLIVersion string          `json:"min_cli_version,omitempty"`
+	MaxCLIVersion string          `json:"max_cli_version,omitempty"`
+	Features      map[string]bool `json:"features"`
+	Compatible    bool            `json:"compatible"`
+	Warnings      []string        `json:"warnings"`
+}
+
+type versionReport struct {
+	Version   string            `json:"version"`
+	Commit    string            `json:"commit"`
+	Date      string            `json:"date"`
+	GoVersion string            `json:"go_version"`
+	API       *versionAPIReport `json:"api,omitempty"`
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the CLI version and check it against the API",
+	Long: `Print the CLI version. With --check-compat, also ask the API which CLI
+versions it supports and which features it has enabled, warn when this CLI
+is outside that range, and exit non-zero if it is. CI can run
+"kernel version --check-compat -o json" before a suite of kernel commands
+and assert on .api.compatible or .api.features.`,
+	Args: cobra.NoArgs,
+	RunE: runVersion,
+}
+
+func init() {
+	addJSONOutputFlag(versionCmd)
+	versionCmd.Flags().Bool("check-compat", false, "Check this version against the versions and features the API supports")
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	if err := validateJSONOutput(output); err != nil {
+		return err
+	}
+	checkCompat, _ := cmd.Flags().GetBool("check-compat")
+
+	report := versionReport{
+		Version:   metadata.Version,
+		Commit:    metadata.Commit,
+		Date:      metadata.Date,
+		GoVersion: metadata.GoVersion,
+	}
+	if checkCompat {
+		api, err := fetchAPICompatibility(cmd.Context(), util.GetBaseURL(), metadata.Version)
+		if err != nil {
+			return err
+		}
+		report.API = api
+	}
+
+	if output == "json" {
+		if err := util.PrintJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printVersionReport(report)
+	}
+	if report.API != nil && !report.API.Compatible {
+		return fmt.Errorf("kernel %s is not supported by %s", report.Version, report.API.BaseURL)
+	}
+	return nil
+}
+
+// fetchAPICompatibility asks the API which CLI versions it supports. An API
+// that doesn't advertise compatibility is reported with a warning rather
+// than as incompatible.
+func fetchAPICompatibility(ctx context.Context, baseURL, version string) (*versionAPIReport, error) {
+	report := &versionAPIReport{BaseURL: baseURL, Features: map[string]bool{}, Compatible: true, Warnings: []string{}}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/cli/compatibility", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Kernel-Cli-Version", version)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not reach Kernel API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		report.Warnings = append(report.Warnings, "the API does not advertise supported CLI versions")
+		return report, nil
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return nil, fmt.Errorf("compatibility check failed: %s", resp.Status)
+	}
+
+	var compat apiCompatibility
+	if err := json.NewDecoder(resp.Body).Decode(&compat); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	report.MinCLIVersion = compat.MinCLIVersion
+	report.MaxCLIVersion = compat.MaxCLIVersion
+	if compat.Features != nil {
+		report.Features = compat.Features
+	}
+	report.Compatible, report.Warnings = checkVersionCompat(version, compat)
+	return report, nil
+}
+
+// checkVersionCompat reports whether version falls within the range the API
+// supports. Development builds can't be compared and only get a warning.
+func checkVersionCompat(version string, compat apiCompatibility) (bool, []string) {
+	warnings := []string{}
+	if compat.MinCLIVersion != "" {
+		older, err := update.IsNewerVersion(version, compat.MinCLIVersion)
+		if err != nil {
+			return true, append(warnings, fmt.Sprintf("cannot compare development build %q with the supported versions", version))
+		}
+		if older {
+			return false, append(warnings, fmt.Sprintf("kernel %s is older than the minimum supported version %s; run `kernel upgrade`", version, compat.MinCLIVersion))
+		}
+	}
+	if compat.MaxCLIVersion != "" {
+		newer, err := update.IsNewerVersion(compat.MaxCLIVersion, version)
+		if err != nil {
+			return true, append(warnings, fmt.Sprintf("cannot compare development build %q with the supported versions", version))
+		}
+		if newer {
+			return false, append(warnings, fmt.Sprintf("kernel %s is newer than the latest version this API supports (%s)", version, compat.MaxCLIVersion))
+		}
+	}
+	return true, warnings
+}
+
+func printVersionReport(r versionReport) {
+	rows := pterm.TableData{
+		{"Property", "Value"},
+		{"Version", r.Version},
+		{"Commit", r.Commit},
+		{"Built", r.Date},
+		{"Go", r.GoVersion},
+	}
+	if r.API != nil {
+		rows = append(rows,
+			[]string{"API", r.API.BaseURL},
+			[]string{"Supported Versions", util.FirstOrDash(r.API.MinCLIVersion) + " to " + util.FirstOrDash(r.API.MaxCLIVersion)},
+		)
+		if len(r.API.Features) > 0 {
+			names := make([]string, 0, len(r.API.Features))
+			for name, on := range r.API.Features {
+				if on {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+			rows = append(rows, []string{"Features", util.FirstOrDash(strings.Join(names, ", "))})
+		}
+	}
+	PrintTableNoPad(rows, true)
+
+	if r.API == nil {
+		return
+	}
+	for _, w := range r.API.Warnings {
+		pterm.Warning.Println(w)
+	}
+	if r.API.Compatible && len(r.API.Warnings) == 0 {
+		pterm.Success.Println("This CLI version is supported by the API")
+	}
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckVersionCompat(t *testing.T) {
+	compat := apiCompatibility{MinCLIVersion: "0.10.0", MaxCLIVersion: "1.2.0"}
+
+	ok, warnings := checkVersionCompat("0.12.3", compat)
+	assert.True(t, ok)
+	assert.Empty(t, warnings)
+
+	ok, warnings = checkVersionCompat("v0.9.1", compat)
+	assert.False(t, ok)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "older than the minimum supported version 0.10.0")
+
+	ok, warnings = checkVersionCompat("1.3.0", compat)
+	assert.False(t, ok)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "newer than the latest version this API supports (1.2.0)")
+
+	ok, warnings = checkVersionCompat("dev", compat)
+	assert.True(t, ok)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "development build")
+}
+
+func TestFetchAPICompatibility(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/cli/compatibility", r.URL.Path)
+		assert.Equal(t, "0.9.0", r.Header.Get("X-Kernel-Cli-Version"))
+		_, _ = w.Write([]byte(`{"min_cli_version":"0.10.0","features":{"managed_auth":true,"pools":false}}`))
+	}))
+	defer srv.Close()
+
+	report, err := fetchAPICompatibility(context.Background(), srv.URL, "0.9.0")
+	require.NoError(t, err)
+	assert.False(t, report.Compatible)
+	assert.Equal(t, "0.10.0", report.MinCLIVersion)
+	assert.Equal(t, map[string]bool{"managed_auth": true, "pools": false}, report.Features)
+	assert.Len(t, report.Warnings, 1)
+}
+
+func TestFetchAPICompatibility_NotAdvertised(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	report, err := fetchAPICompatibility(context.Background(), srv.URL, "0.9.0")
+	require.NoError(t, err)
+	assert.True(t, report.Compatible)
+	assert.Equal(t, []string{"the API does not advertise supported CLI versions"}, report.Warnings)
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// InvocationGetter is the subset of the invocations client that wait uses.
+type InvocationGetter interface {
+	Get(ctx context.Context, id string, opts ...option.RequestOption) (res *kernel.InvocationGetResponse, err error)
+}
+
+// WaitCmd blocks until resources reach a terminal state.
+type WaitCmd struct {
+	invocations InvocationGetter
+}
+
+type WaitInvocationInput struct {
+	ID       string
+	Timeout  time.Duration
+	Interval time.Duration
+	Output   string
+}
+
+// Invocation polls an invocation until it succeeds or fails. Unlike
+// 'kernel invoke' it holds no stream open, so it suits schedulers that start
+// jobs with 'kernel invoke --detach' and check on them later. A failed
+// invocation is an error, so the exit code reflects the outcome.
+func (w WaitCmd) Invocation(ctx context.Context, in WaitInvocationInput) error {
+	if err := validateJSONOutput(in.Output); err != nil {
+		return err
+	}
+	if in.Interval <= 0 {
+		in.Interval = 2 * time.Second
+	}
+	if in.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, in.Timeout)
+		defer cancel()
+	}
+	jsonOutput := in.Output == "json"
+
+	var lastStatus kernel.InvocationGetResponseStatus
+	for {
+		inv, err := w.invocations.Get(ctx, in.ID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return waitInterrupted(ctx, in)
+			}
+			return util.CleanedUpSdkError{Err: err}
+		}
+		if inv.Status != lastStatus && !jsonOutput {
+			pterm.Info.Printf("Invocation %s is %s\n", inv.ID, inv.Status)
+		}
+		lastStatus = inv.Status
+
+		switch inv.Status {
+		case kernel.InvocationGetResponseStatusSucceeded, kernel.InvocationGetResponseStatusFailed:
+			succeeded := inv.Status == kernel.InvocationGetResponseStatusSucceeded
+			if jsonOutput {
+				if err := util.PrintJSONLine(inv); err != nil {
+					return err
+				}
+			} else {
+				printResult(succeeded, inv.Output)
+			}
+			if !succeeded {
+				return fmt.Errorf("invocation %s failed%s", inv.ID, reasonSuffix(inv.StatusReason))
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return waitInterrupted(ctx, in)
+		case <-time.After(in.Interval):
+		}
+	}
+}
+
+func waitInterrupted(ctx context.Context, in WaitInvocationInput) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s waiting for invocation %s", in.Timeout, in.ID)
+	}
+	return fmt.Errorf("stopped waiting for invocation %s", in.ID)
+}
+
+func reasonSuffix(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return ": " + reason
+}
+
+var waitCmd = &cobra.Command{
+	Use:   "wait",
+	Short: "Wait for a resource to finish",
+}
+
+var waitInvocationCmd = &cobra.Command{
+	Use:   "invocation <invocation_id>",
+	Short: "Wait for an invocation to finish",
+	Long: `Wait for an invocation to succeed or fail by polling it, then print its output.
+Exits non-zero if the invocation failed or --timeout ran out.
+
+Pairs with 'kernel invoke --detach' for fire-and-forget jobs.`,
+	Example: `  id=$(kernel invoke my-app run --detach)
+  kernel wait invocation "$id" --timeout 30m`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWaitInvocation,
+}
+
+func init() {
+	waitInvocationCmd.Flags().Duration("timeout", 0, "Give up after this long (default: wait indefinitely)")
+	waitInvocationCmd.Flags().Duration("interval", 2*time.Second, "How often to check the invocation")
+	addJSONOutputFlag(waitInvocationCmd)
+	waitCmd.AddCommand(waitInvocationCmd)
+}
+
+func runWaitInvocation(cmd *cobra.Command, args []string) error {
+	client := getKernelClient(cmd)
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	output, _ := cmd.Flags().GetString("output")
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Resolve a short ID prefix once up front rather than on every poll.
+	inv, err := withIDPrefix(ctx, "invocation", args[0], invocationIDLister(&client.Invocations), func(id string) (*kernel.InvocationGetResponse, error) {
+		return client.Invocations.Get(ctx, id)
+	})
+	if err != nil {
+		return util.CleanedUpSdkError{Err: err}
+	}
+
+	w := WaitCmd{invocations: &client.Invocations}
+	return w.Invocation(ctx, WaitInvocationInput{
+		ID:       inv.ID,
+		Timeout:  timeout,
+		Interval: interval,
+		Output:   output,
+	})
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeInvocationGetter func(ctx context.Context, id string) (*kernel.InvocationGetResponse, error)
+
+func (f fakeInvocationGetter) Get(ctx context.Context, id string, opts ...option.RequestOption) (*kernel.InvocationGetResponse, error) {
+	return f(ctx, id)
+}
+
+func TestWaitInvocation_PollsUntilFinished(t *testing.T) {
+	setupStdoutCapture(t)
+
+	statuses := []kernel.InvocationGetResponseStatus{"queued", "running", "running", "succeeded"}
+	polls := 0
+	w := WaitCmd{invocations: fakeInvocationGetter(func(ctx context.Context, id string) (*kernel.InvocationGetResponse, error) {
+		s := statuses[polls]
+		polls++
+		inv := &kernel.InvocationGetResponse{ID: id, Status: s}
+		if s == "succeeded" {
+			inv.Output = `{"ok":true}`
+		}
+		return inv, nil
+	})}
+
+	require.NoError(t, w.Invocation(context.Background(), WaitInvocationInput{ID: "inv_1", Interval: time.Millisecond}))
+	assert.Equal(t, 4, polls)
+	out := outBuf.String()
+	assert.Equal(t, 1, strings.Count(out, "is running"), "unchanged statuses are reported once")
+	assert.Contains(t, out, `"ok": true`)
+}
+
+func TestWaitInvocation_FailedAndTimeoutAreErrors(t *testing.T) {
+	setupStdoutCapture(t)
+
+	failed := WaitCmd{invocations: fakeInvocationGetter(func(ctx context.Context, id string) (*kernel.InvocationGetResponse, error) {
+		return &kernel.InvocationGetResponse{ID: id, Status: "failed", StatusReason: "boom"}, nil
+	})}
+	err := failed.Invocation(context.Background(), WaitInvocationInput{ID: "inv_2", Output: "json"})
+	assert.EqualError(t, err, "invocation inv_2 failed: boom")
+
+	running := WaitCmd{invocations: fakeInvocationGetter(func(ctx context.Context, id string) (*kernel.InvocationGetResponse, error) {
+		return &kernel.InvocationGetResponse{ID: id, Status: "running"}, nil
+	})}
+	err = running.Invocation(context.Background(), WaitInvocationInput{ID: "inv_3", Timeout: 20 * time.Millisecond, Interval: time.Millisecond})
+	assert.ErrorContains(t, err, "timed out after 20ms waiting for invocation inv_3")
+}
@@ -0,0 +1,280 @@
+// Package appenv stores environment variables for apps in a JSON file under
+// the CLI config directory, so "kernel deploy" can apply them without them
+// living in code or being retyped on every deploy. Variables are kept per
+// scope (the API endpoint and project deployed to), so apps with the same name
+// in different places don't share them. Secret values are kept in the OS
+// keychain, or in the file only when the caller allows it, and are never read
+// back out except to deploy.
+package appenv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/zalando/go-keyring"
+)
+
+// KeyringService matches the service the CLI stores its login tokens under.
+const KeyringService = "kernel-cli"
+
+var keyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Var is one stored environment variable. Value is empty for secrets kept in
+// the keychain, and List clears it for every secret.
+type Var struct {
+	Key       string    `json:"key"`
+	Value     string    `json:"value,omitempty"`
+	Secret    bool      `json:"secret,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store reads and writes the app environment file at Path, seeing only the
+// variables stored under Scope.
+type Store struct {
+	Path string
+	// Scope identifies where the apps are deployed, e.g. the API base URL
+	// and project.
+	Scope string
+	Now   func() time.Time
+	// AllowPlaintextSecrets keeps secrets in the file when the keychain
+	// can't store them, instead of failing.
+	AllowPlaintextSecrets bool
+}
+
+// DefaultStore returns a store for scope at ~/.config/kernel/app-env.json.
+func DefaultStore(scope string) (*Store, error) {
+	configDir, err := util.ConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return &Store{Path: filepath.Join(configDir, "app-env.json"), Scope: scope}, nil
+}
+
+func (s *Store) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// ValidateKey reports whether key can be used as an environment variable name.
+func ValidateKey(key string) error {
+	if !keyPattern.MatchString(key) {
+		return fmt.Errorf("invalid environment variable name %q: use letters, digits and underscores, not starting with a digit", key)
+	}
+	return nil
+}
+
+// List returns the app's variables ordered by key, with secret values
+// cleared.
+func (s *Store) List(app string) ([]Var, error) {
+	scopes, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	vars := scopes[s.Scope][app]
+	for i := range vars {
+		if vars[i].Secret {
+			vars[i].Value = ""
+		}
+	}
+	return vars, nil
+}
+
+// Set stores vars for the app, replacing any with the same keys. With secret
+// the values go to the keychain; a key set again without secret stops being
+// one. It returns the secrets that were written to the file because the
+// keychain couldn't store them, which only happens with
+// AllowPlaintextSecrets.
+func (s *Store) Set(app string, vars map[string]string, secret bool) (plaintext []string, err error) {
+	for key := range vars {
+		if err := ValidateKey(key); err != nil {
+			return nil, err
+		}
+	}
+	scopes, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	apps := scopes.apps(s.Scope)
+	existing := apps[app]
+	now := s.now()
+	for key, value := range vars {
+		v := Var{Key: key, Value: value, Secret: secret, UpdatedAt: now}
+		if secret {
+			if err := keyring.Set(KeyringService, s.keyringUser(app, key), value); err == nil {
+				v.Value = ""
+			} else if s.AllowPlaintextSecrets {
+				// The file is only readable by the current user
+				plaintext = append(plaintext, key)
+			} else {
+				return nil, fmt.Errorf("store secret %s for app %s in keychain: %w", key, app, err)
+			}
+		} else {
+			_ = s.deleteSecret(app, key)
+		}
+		if i := indexOf(existing, key); i >= 0 {
+			existing[i] = v
+		} else {
+			existing = append(existing, v)
+		}
+	}
+	apps[app] = existing
+	sort.Strings(plaintext)
+	return plaintext, s.write(scopes)
+}
+
+// Unset removes the given keys from the app and returns those that were set.
+func (s *Store) Unset(app string, keys []string) ([]string, error) {
+	scopes, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	apps := scopes.apps(s.Scope)
+	var removed []string
+	vars := apps[app]
+	for _, key := range keys {
+		i := indexOf(vars, key)
+		if i < 0 {
+			continue
+		}
+		if vars[i].Secret {
+			if err := s.deleteSecret(app, key); err != nil {
+				return nil, err
+			}
+		}
+		vars = append(vars[:i], vars[i+1:]...)
+		removed = append(removed, key)
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	if len(vars) == 0 {
+		delete(apps, app)
+	} else {
+		apps[app] = vars
+	}
+	if len(apps) == 0 {
+		delete(scopes, s.Scope)
+	}
+	return removed, s.write(scopes)
+}
+
+// Values returns the app's variables with secrets resolved, for deploying.
+func (s *Store) Values(app string) (map[string]string, error) {
+	scopes, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	vars := scopes[s.Scope][app]
+	values := make(map[string]string, len(vars))
+	for _, v := range vars {
+		if v.Secret && v.Value == "" {
+			secret, err := keyring.Get(KeyringService, s.keyringUser(app, v.Key))
+			if errors.Is(err, keyring.ErrNotFound) {
+				return nil, fmt.Errorf("secret %s for app %s missing from keychain; re-run app env set --secret", v.Key, app)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("read secret %s for app %s from keychain: %w", v.Key, app, err)
+			}
+			v.Value = secret
+		}
+		values[v.Key] = v.Value
+	}
+	return values, nil
+}
+
+// keyringUser includes the scope so a secret stored for one endpoint or
+// project is never read when deploying to another.
+func (s *Store) keyringUser(app, key string) string {
+	return "app-env/" + s.Scope + "/" + app + "/" + key
+}
+
+func (s *Store) deleteSecret(app, key string) error {
+	err := keyring.Delete(KeyringService, s.keyringUser(app, key))
+	if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("delete secret %s for app %s from keychain: %w", key, app, err)
+	}
+	return nil
+}
+
+func indexOf(vars []Var, key string) int {
+	for i, v := range vars {
+		if v.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// scopedApps is the file's contents: scope -> app -> variables.
+type scopedApps map[string]map[string][]Var
+
+// apps returns the apps stored under scope, adding the scope if needed.
+func (m scopedApps) apps(scope string) map[string][]Var {
+	if m[scope] == nil {
+		m[scope] = map[string][]Var{}
+	}
+	return m[scope]
+}
+
+func (s *Store) read() (scopedApps, error) {
+	scopes := scopedApps{}
+	b, err := os.ReadFile(s.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return scopes, nil
+		}
+		return nil, fmt.Errorf("read app env: %w", err)
+	}
+	if err := json.Unmarshal(b, &scopes); err != nil {
+		return nil, fmt.Errorf("parse app env %s: %w", s.Path, err)
+	}
+	for _, apps := range scopes {
+		for _, vars := range apps {
+			sort.Slice(vars, func(i, j int) bool { return vars[i].Key < vars[j].Key })
+		}
+	}
+	return scopes, nil
+}
+
+// write replaces the file atomically so a concurrent deploy never sees a
+// partial file. It may hold secrets when AllowPlaintextSecrets is set, so
+// only the current user can read it.
+func (s *Store) write(scopes scopedApps) error {
+	for _, apps := range scopes {
+		for _, vars := range apps {
+			sort.Slice(vars, func(i, j int) bool { return vars[i].Key < vars[j].Key })
+		}
+	}
+	b, err := json.MarshalIndent(scopes, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o700); err != nil {
+		return fmt.Errorf("create app env directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), ".app-env-*.json")
+	if err != nil {
+		return fmt.Errorf("write app env: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write app env: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write app env: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.Path); err != nil {
+		return fmt.Errorf("write app env: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package appenv
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+)
+
+func newTestStore(t *testing.T) *Store {
+	keyring.MockInit()
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	return &Store{Path: filepath.Join(t.TempDir(), "app-env.json"), Scope: "https://api.example.com", Now: func() time.Time { return now }}
+}
+
+func mustSet(t *testing.T, s *Store, app string, vars map[string]string, secret bool) {
+	t.Helper()
+	plaintext, err := s.Set(app, vars, secret)
+	require.NoError(t, err)
+	require.Empty(t, plaintext)
+}
+
+func TestStore_SetListValues(t *testing.T) {
+	s := newTestStore(t)
+	mustSet(t, s, "app", map[string]string{"REGION": "us", "DEBUG": "1"}, false)
+	mustSet(t, s, "app", map[string]string{"API_TOKEN": "s3cret"}, true)
+	mustSet(t, s, "other", map[string]string{"DEBUG": "0"}, false)
+
+	vars, err := s.List("app")
+	require.NoError(t, err)
+	require.Len(t, vars, 3)
+	assert.Equal(t, Var{Key: "API_TOKEN", Secret: true, UpdatedAt: s.Now()}, vars[0])
+	assert.Equal(t, "DEBUG", vars[1].Key)
+	assert.Equal(t, "1", vars[1].Value)
+
+	values, err := s.Values("app")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"API_TOKEN": "s3cret", "DEBUG": "1", "REGION": "us"}, values)
+
+	// The secret lives in the keychain, not the file.
+	b, err := os.ReadFile(s.Path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "s3cret")
+	info, err := os.Stat(s.Path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestStore_ValuesFailsOnMissingSecret(t *testing.T) {
+	s := newTestStore(t)
+	mustSet(t, s, "app", map[string]string{"TOKEN": "s3cret"}, true)
+	require.NoError(t, keyring.Delete(KeyringService, s.keyringUser("app", "TOKEN")))
+
+	_, err := s.Values("app")
+	assert.EqualError(t, err, "secret TOKEN for app app missing from keychain; re-run app env set --secret")
+}
+
+func TestStore_SetPlainReplacesSecret(t *testing.T) {
+	s := newTestStore(t)
+	mustSet(t, s, "app", map[string]string{"TOKEN": "s3cret"}, true)
+	mustSet(t, s, "app", map[string]string{"TOKEN": "plain"}, false)
+
+	vars, err := s.List("app")
+	require.NoError(t, err)
+	require.Len(t, vars, 1)
+	assert.False(t, vars[0].Secret)
+	assert.Equal(t, "plain", vars[0].Value)
+	_, err = keyring.Get(KeyringService, s.keyringUser("app", "TOKEN"))
+	assert.ErrorIs(t, err, keyring.ErrNotFound)
+}
+
+func TestStore_SetSecretWithoutKeychain(t *testing.T) {
+	s := newTestStore(t)
+	keyring.MockInitWithError(errors.New("no keychain"))
+
+	_, err := s.Set("app", map[string]string{"TOKEN": "s3cret"}, true)
+	assert.EqualError(t, err, "store secret TOKEN for app app in keychain: no keychain")
+	_, statErr := os.Stat(s.Path)
+	assert.True(t, os.IsNotExist(statErr), "nothing is written")
+
+	s.AllowPlaintextSecrets = true
+	plaintext, err := s.Set("app", map[string]string{"TOKEN": "s3cret"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TOKEN"}, plaintext)
+	values, err := s.Values("app")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"TOKEN": "s3cret"}, values)
+}
+
+func TestStore_ScopesAreSeparate(t *testing.T) {
+	prod := newTestStore(t)
+	mustSet(t, prod, "app", map[string]string{"REGION": "us"}, false)
+	mustSet(t, prod, "app", map[string]string{"TOKEN": "prod-secret"}, true)
+	staging := &Store{Path: prod.Path, Scope: "https://api.example.com project=staging", Now: prod.Now}
+
+	values, err := staging.Values("app")
+	require.NoError(t, err)
+	assert.Empty(t, values, "another scope's variables aren't applied")
+
+	mustSet(t, staging, "app", map[string]string{"TOKEN": "staging-secret"}, true)
+	values, err = prod.Values("app")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"REGION": "us", "TOKEN": "prod-secret"}, values)
+	values, err = staging.Values("app")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"TOKEN": "staging-secret"}, values)
+
+	removed, err := staging.Unset("app", []string{"TOKEN"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TOKEN"}, removed)
+	_, err = keyring.Get(KeyringService, prod.keyringUser("app", "TOKEN"))
+	assert.NoError(t, err, "unsetting in one scope leaves the other's secret")
+}
+
+func TestStore_Unset(t *testing.T) {
+	s := newTestStore(t)
+	mustSet(t, s, "app", map[string]string{"A": "1", "B": "2"}, true)
+
+	removed, err := s.Unset("app", []string{"A", "MISSING"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A"}, removed)
+	_, err = keyring.Get(KeyringService, s.keyringUser("app", "A"))
+	assert.ErrorIs(t, err, keyring.ErrNotFound)
+
+	values, err := s.Values("app")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"B": "2"}, values)
+
+	removed, err = s.Unset("app", []string{"MISSING"})
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+}
+
+func TestStore_SetRejectsInvalidKey(t *testing.T) {
+	s := newTestStore(t)
+	_, err := s.Set("app", map[string]string{"1BAD": "x"}, false)
+	assert.EqualError(t, err, `invalid environment variable name "1BAD": use letters, digits and underscores, not starting with a digit`)
+	_, statErr := os.Stat(s.Path)
+	assert.True(t, os.IsNotExist(statErr))
+}
@@ -0,0 +1,99 @@
+// Package config reads and writes the CLI's named contexts: sets of API key,
+// base URL and default project stored in ~/.config/kernel/config.yaml, so one
+// machine can switch between Kernel accounts or environments.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kernel/cli/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+// Context is one named set of connection settings. Empty fields fall back to
+// the environment and the CLI's defaults.
+type Context struct {
+	APIKey  string `yaml:"api_key,omitempty"`
+	BaseURL string `yaml:"base_url,omitempty"`
+	// BaseURLs lists API endpoints in priority order; reads fail over from
+	// the first to the rest. It takes the place of BaseURL when set.
+	BaseURLs []string `yaml:"base_urls,omitempty"`
+	Project  string   `yaml:"project,omitempty"`
+}
+
+// URLs returns the context's API endpoints in priority order, from BaseURLs
+// or else a (possibly comma-separated) BaseURL.
+func (c Context) URLs() []string {
+	if len(c.BaseURLs) > 0 {
+		return c.BaseURLs
+	}
+	return SplitURLs(c.BaseURL)
+}
+
+// SplitURLs splits a comma-separated list of URLs, dropping empty entries.
+func SplitURLs(s string) []string {
+	var urls []string
+	for _, u := range strings.Split(s, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// Config is the contents of config.yaml.
+type Config struct {
+	CurrentContext string             `yaml:"current_context,omitempty"`
+	Contexts       map[string]Context `yaml:"contexts,omitempty"`
+	// Timings turns on the API timings footer for every command.
+	Timings bool `yaml:"timings,omitempty"`
+}
+
+// Path returns the location of config.yaml.
+func Path() (string, error) {
+	configDir, err := util.ConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, "config.yaml"), nil
+}
+
+// Load reads config.yaml. A missing file is an empty config.
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Save writes config.yaml with owner-only permissions, since contexts may
+// hold API keys.
+func Save(cfg *Config) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
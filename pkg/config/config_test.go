@@ -0,0 +1,53 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSave(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Contexts)
+
+	cfg = &Config{
+		CurrentContext: "staging",
+		Contexts: map[string]Context{
+			"staging": {APIKey: "sk_staging", BaseURL: "https://api.staging.example.com"},
+			"prod":    {Project: "proj_123"},
+			"multi":   {BaseURLs: []string{"https://us.example.com", "https://eu.example.com"}},
+		},
+	}
+	require.NoError(t, Save(cfg))
+
+	path, err := Path()
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	loaded, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, cfg, loaded)
+}
+
+func TestLoad_InvalidYAML(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path, err := Path()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte("contexts: [\n"), 0o600))
+
+	_, err = Load()
+	assert.ErrorContains(t, err, "parse")
+}
+
+func TestContextURLs(t *testing.T) {
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, Context{BaseURL: "https://a.example.com, https://b.example.com,"}.URLs())
+	assert.Equal(t, []string{"https://b.example.com"}, Context{BaseURL: "https://a.example.com", BaseURLs: []string{"https://b.example.com"}}.URLs())
+	assert.Empty(t, Context{}.URLs())
+}
@@ -0,0 +1,124 @@
+package extensions
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+)
+
+// CRX3 header fields (components/crx_file/crx3.proto in Chromium).
+const (
+	crx3FieldSHA256WithRSA    = 2
+	crx3FieldSHA256WithECDSA  = 3
+	crx3FieldSignedHeaderData = 10000
+	crx3ProofPublicKey        = 1
+	crx3ProofSignature        = 2
+	crx3SignedDataCRXID       = 1
+)
+
+// parseCRXKey reads a CRX signing key: RSA, or ECDSA on P-256, as PKCS#8,
+// PKCS#1 or SEC 1 PEM.
+func parseCRXKey(pemData []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block")
+	}
+	var key any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("ECDSA CRX keys must use P-256")
+		}
+		return k, nil
+	}
+	return nil, fmt.Errorf("CRX keys must be RSA or ECDSA P-256, got %T", key)
+}
+
+// crxExtensionID derives the Chrome extension ID from a DER public key: the
+// first 16 bytes of its SHA-256, each nibble written as a letter a-p.
+func crxExtensionID(publicKeyDER []byte) string {
+	sum := sha256.Sum256(publicKeyDER)
+	id := make([]byte, 32)
+	for i, b := range sum[:16] {
+		id[2*i] = 'a' + b>>4
+		id[2*i+1] = 'a' + b&0xf
+	}
+	return string(id)
+}
+
+// crxKeyExtensionID is the extension ID of packages signed with key.
+func crxKeyExtensionID(key crypto.Signer) (string, error) {
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return "", fmt.Errorf("failed to encode public key: %w", err)
+	}
+	return crxExtensionID(publicKeyDER), nil
+}
+
+// packCRX3 signs the zipped extension with key and returns the CRX3 file
+// and the extension ID it installs as.
+func packCRX3(zipData []byte, key crypto.Signer) ([]byte, string, error) {
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode public key: %w", err)
+	}
+	crxID := sha256.Sum256(publicKeyDER)
+	signedData := protoBytes(nil, crx3SignedDataCRXID, crxID[:16])
+
+	// The signature covers a fixed prefix, the signed header data and the zip
+	msg := []byte("CRX3 SignedData\x00")
+	msg = binary.LittleEndian.AppendUint32(msg, uint32(len(signedData)))
+	msg = append(msg, signedData...)
+	msg = append(msg, zipData...)
+	digest := sha256.Sum256(msg)
+	signature, err := key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to sign CRX: %w", err)
+	}
+
+	proofField := crx3FieldSHA256WithRSA
+	if _, ok := key.(*ecdsa.PrivateKey); ok {
+		proofField = crx3FieldSHA256WithECDSA
+	}
+	proof := protoBytes(nil, crx3ProofPublicKey, publicKeyDER)
+	proof = protoBytes(proof, crx3ProofSignature, signature)
+	header := protoBytes(nil, proofField, proof)
+	header = protoBytes(header, crx3FieldSignedHeaderData, signedData)
+
+	var out bytes.Buffer
+	out.WriteString("Cr24")
+	_ = binary.Write(&out, binary.LittleEndian, uint32(3))
+	_ = binary.Write(&out, binary.LittleEndian, uint32(len(header)))
+	out.Write(header)
+	out.Write(zipData)
+	return out.Bytes(), crxExtensionID(publicKeyDER), nil
+}
+
+// protoBytes appends a length-delimited protobuf field to b.
+func protoBytes(b []byte, field int, value []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(value)))
+	return append(b, value...)
+}
@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -36,6 +35,11 @@ type ExtensionsBuildWebBotAuthInput struct {
 	ExtensionName     string // Name for the extension paths (defaults to "web-bot-auth")
 	AutoUpload        bool   // Whether the extension will be automatically uploaded after building
 	SignatureAgentURL string // URL of the signature agent
+	Prebuilt          bool   // Start from the prebuilt release artifact instead of building with npm
+	PrebuiltURL       string // Overrides webBotAuthPrebuiltURL (implies Prebuilt)
+	NoCache           bool   // Download archives again instead of using ~/.cache/kernel/sources
+	SourceDir         string // Local web-bot-auth checkout to build from instead of downloading
+	ArchiveSHA256     string // Expected sha256 of the downloaded archive, overriding the pinned one
 }
 
 // BuildWebBotAuthOutput contains the result of building the extension
@@ -47,9 +51,19 @@ type BuildWebBotAuthOutput struct {
 func BuildWebBotAuth(ctx context.Context, in ExtensionsBuildWebBotAuthInput) (*BuildWebBotAuthOutput, error) {
 	pterm.Info.Println("Preparing web-bot-auth extension...")
 
+	prebuilt := in.Prebuilt || in.PrebuiltURL != ""
+	if prebuilt && in.SignatureAgentURL != "" {
+		return nil, fmt.Errorf("--signature-agent is baked in at build time and can't be used with --prebuilt")
+	}
+	if prebuilt && in.SourceDir != "" {
+		return nil, fmt.Errorf("--source-dir builds from source and can't be used with --prebuilt")
+	}
+
 	// Validate preconditions
-	if err := validateToolDependencies(); err != nil {
-		return nil, err
+	if !prebuilt {
+		if err := validateToolDependencies(); err != nil {
+			return nil, err
+		}
 	}
 
 	outputDir, err := filepath.Abs(in.Output)
@@ -75,13 +89,6 @@ func BuildWebBotAuth(ctx context.Context, in ExtensionsBuildWebBotAuthInput) (*B
 		return nil, fmt.Errorf("failed to check output directory: %w", err)
 	}
 
-	// Download and extract
-	browserExtDir, cleanup, err := downloadAndExtractWebBotAuth(ctx)
-	defer cleanup()
-	if err != nil {
-		return nil, err
-	}
-
 	// Load key (custom or default)
 	var keyData string
 	var usingDefaultKey bool
@@ -99,15 +106,43 @@ func BuildWebBotAuth(ctx context.Context, in ExtensionsBuildWebBotAuthInput) (*B
 		usingDefaultKey = true
 	}
 
-	// Build extension
-	extensionID, err := buildWebBotAuthExtension(ctx, browserExtDir, in.HostURL, keyData, in.ExtensionName, in.SignatureAgentURL)
-	if err != nil {
-		return nil, err
-	}
+	var extensionID string
+	if prebuilt {
+		url, sum := in.PrebuiltURL, in.ArchiveSHA256
+		if url == "" {
+			url = webBotAuthPrebuiltURL
+			if sum == "" {
+				sum = webBotAuthPrebuiltSHA256
+			}
+		}
+		extensionID, err = prepareWebBotAuthPrebuilt(ctx, url, sum, !in.NoCache, outputDir, in.HostURL, keyData, in.ExtensionName, usingDefaultKey)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// Download and extract, or copy a local checkout
+		var browserExtDir string
+		var cleanup func()
+		if in.SourceDir != "" {
+			browserExtDir, cleanup, err = copyWebBotAuthSource(in.SourceDir)
+		} else {
+			browserExtDir, cleanup, err = downloadAndExtractWebBotAuth(ctx, in.ArchiveSHA256, !in.NoCache)
+		}
+		defer cleanup()
+		if err != nil {
+			return nil, err
+		}
 
-	// Copy artifacts
-	if err := copyExtensionArtifacts(browserExtDir, outputDir); err != nil {
-		return nil, err
+		// Build extension
+		extensionID, err = buildWebBotAuthExtension(ctx, browserExtDir, in.HostURL, keyData, in.ExtensionName, in.SignatureAgentURL)
+		if err != nil {
+			return nil, err
+		}
+
+		// Copy artifacts
+		if err := copyExtensionArtifacts(browserExtDir, outputDir); err != nil {
+			return nil, err
+		}
 	}
 
 	// Display success message
@@ -144,41 +179,48 @@ func validateToolDependencies() error {
 	return nil
 }
 
-// downloadAndExtractWebBotAuth downloads and extracts the web-bot-auth repo, returns the browser-extension directory path
-func downloadAndExtractWebBotAuth(ctx context.Context) (browserExtDir string, cleanup func(), err error) {
-	cleanup = func() {}
-
-	// Download from GitHub
+// downloadAndExtractWebBotAuth downloads and extracts the web-bot-auth repo, returns the browser-extension directory path.
+// wantSHA256 overrides webBotAuthArchiveSHA256 when set.
+func downloadAndExtractWebBotAuth(ctx context.Context, wantSHA256 string, useCache bool) (browserExtDir string, cleanup func(), err error) {
+	if wantSHA256 == "" {
+		wantSHA256 = webBotAuthArchiveSHA256
+	}
 	pterm.Info.Printf("Downloading web-bot-auth from GitHub...\n")
-	client := &http.Client{Timeout: downloadTimeout}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, webBotAuthDownloadURL, nil)
+	tmpExtractDir, cleanup, err := downloadAndExtractZip(ctx, webBotAuthDownloadURL, wantSHA256, useCache)
 	if err != nil {
-		return "", cleanup, fmt.Errorf("failed to create download request: %w", err)
+		return "", cleanup, err
 	}
 
-	resp, err := client.Do(req)
+	entries, err := os.ReadDir(tmpExtractDir)
 	if err != nil {
-		return "", cleanup, fmt.Errorf("failed to download web-bot-auth: %w", err)
+		return "", cleanup, fmt.Errorf("failed to read extracted directory: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", cleanup, fmt.Errorf("failed to download web-bot-auth: HTTP %d", resp.StatusCode)
+	if len(entries) == 0 {
+		return "", cleanup, fmt.Errorf("extracted archive is empty")
 	}
 
-	// Save to temporary file
-	tmpZip, err := os.CreateTemp("", "web-bot-auth-*.zip")
-	if err != nil {
-		return "", cleanup, fmt.Errorf("failed to create temp file: %w", err)
+	extractedDir := filepath.Join(tmpExtractDir, entries[0].Name())
+	browserExtDir = filepath.Join(extractedDir, "examples", "browser-extension")
+
+	// Verify the browser-extension directory exists
+	if _, err := os.Stat(browserExtDir); err != nil {
+		if os.IsNotExist(err) {
+			return "", cleanup, fmt.Errorf("browser-extension directory not found in archive")
+		}
+		return "", cleanup, fmt.Errorf("failed to access browser-extension directory: %w", err)
 	}
-	tmpZipPath := tmpZip.Name()
-	cleanup = func() { os.Remove(tmpZipPath) }
 
-	if _, err := io.Copy(tmpZip, resp.Body); err != nil {
-		tmpZip.Close()
-		return "", cleanup, fmt.Errorf("failed to save download: %w", err)
+	return browserExtDir, cleanup, nil
+}
+
+// downloadAndExtractZip fetches a web-bot-auth zip archive (see fetchArchive)
+// and extracts it to a temporary directory, which cleanup removes
+func downloadAndExtractZip(ctx context.Context, url, wantSHA256 string, useCache bool) (dir string, cleanup func(), err error) {
+	zipPath, removeZip, err := fetchArchive(ctx, url, wantSHA256, useCache)
+	cleanup = removeZip
+	if err != nil {
+		return "", cleanup, sourceDirHint(err)
 	}
-	tmpZip.Close()
 
 	// Extract to temporary directory
 	tmpExtractDir, err := os.MkdirTemp("", "web-bot-auth-extract-*")
@@ -186,35 +228,16 @@ func downloadAndExtractWebBotAuth(ctx context.Context) (browserExtDir string, cl
 		return "", cleanup, fmt.Errorf("failed to create temp directory: %w", err)
 	}
 	cleanup = func() {
-		os.Remove(tmpZipPath)
+		removeZip()
 		os.RemoveAll(tmpExtractDir)
 	}
 
 	pterm.Info.Println("Extracting archive...")
-	if err := util.Unzip(tmpZipPath, tmpExtractDir); err != nil {
+	if err := util.Unzip(zipPath, tmpExtractDir); err != nil {
 		return "", cleanup, fmt.Errorf("failed to extract archive: %w", err)
 	}
 
-	entries, err := os.ReadDir(tmpExtractDir)
-	if err != nil {
-		return "", cleanup, fmt.Errorf("failed to read extracted directory: %w", err)
-	}
-	if len(entries) == 0 {
-		return "", cleanup, fmt.Errorf("extracted archive is empty")
-	}
-
-	extractedDir := filepath.Join(tmpExtractDir, entries[0].Name())
-	browserExtDir = filepath.Join(extractedDir, "examples", "browser-extension")
-
-	// Verify the browser-extension directory exists
-	if _, err := os.Stat(browserExtDir); err != nil {
-		if os.IsNotExist(err) {
-			return "", cleanup, fmt.Errorf("browser-extension directory not found in archive")
-		}
-		return "", cleanup, fmt.Errorf("failed to access browser-extension directory: %w", err)
-	}
-
-	return browserExtDir, cleanup, nil
+	return tmpExtractDir, cleanup, nil
 }
 
 // buildWebBotAuthExtension modifies templates, builds the extension, and returns the extension ID
@@ -225,29 +248,9 @@ func buildWebBotAuthExtension(ctx context.Context, browserExtDir, hostURL, keyDa
 
 	// Validate key and write to browserExtDir before building
 	pterm.Info.Println("Validating key...")
-	var pemData []byte
-	var err error
-	// JWK data is used for keyid signing in background.ts
-	var jwkData string
-
-	if util.IsPEMKey(keyData) {
-		// Key is already in PEM format, validate it
-		if err := util.ValidatePEMKey(keyData); err != nil {
-			return "", fmt.Errorf("failed to validate PEM key: %w", err)
-		}
-
-		jwkData, err = util.ConvertPEMToJWK(keyData)
-		if err != nil {
-			return "", fmt.Errorf("failed to convert PEM to JWK: %w", err)
-		}
-		pemData = []byte(keyData)
-	} else {
-		// Key is in JWK format, convert to PEM
-		pemData, err = util.ConvertJWKToPEM(keyData)
-		if err != nil {
-			return "", fmt.Errorf("failed to convert JWK to PEM: %w", err)
-		}
-		jwkData = keyData
+	pemData, jwkData, err := webBotAuthKeyMaterial(keyData)
+	if err != nil {
+		return "", err
 	}
 
 	privateKeyPath := filepath.Join(browserExtDir, "private_key.pem")
@@ -373,6 +376,30 @@ func buildWebBotAuthExtension(ctx context.Context, browserExtDir, hostURL, keyDa
 	return extensionID, nil
 }
 
+// webBotAuthKeyMaterial validates a JWK or PEM signing key and returns it in
+// both forms: PEM for private_key.pem and JWK for keyid signing in background.ts
+func webBotAuthKeyMaterial(keyData string) (pemData []byte, jwkData string, err error) {
+	if util.IsPEMKey(keyData) {
+		// Key is already in PEM format, validate it
+		if err := util.ValidatePEMKey(keyData); err != nil {
+			return nil, "", fmt.Errorf("failed to validate PEM key: %w", err)
+		}
+
+		jwkData, err = util.ConvertPEMToJWK(keyData)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to convert PEM to JWK: %w", err)
+		}
+		return []byte(keyData), jwkData, nil
+	}
+
+	// Key is in JWK format, convert to PEM
+	pemData, err = util.ConvertJWKToPEM(keyData)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to convert JWK to PEM: %w", err)
+	}
+	return pemData, keyData, nil
+}
+
 // injectJWKIntoBackgroundTs replaces the hardcoded test key import with the custom JWK
 func injectJWKIntoBackgroundTs(backgroundTsPath, jwkData string) error {
 	content, err := os.ReadFile(backgroundTsPath)
@@ -446,11 +473,8 @@ func copyExtensionArtifacts(browserExtDir, outputDir string) error {
 			return fmt.Errorf("failed to copy private_key.pem: %w", err)
 		}
 
-		// Create .gitignore to prevent private key from being uploaded
-		gitignorePath := filepath.Join(outputDir, ".gitignore")
-		gitignoreContent := "# Exclude private key from uploads\nprivate_key.pem\n"
-		if err := os.WriteFile(gitignorePath, []byte(gitignoreContent), defaultFileMode); err != nil {
-			return fmt.Errorf("failed to create .gitignore: %w", err)
+		if err := excludePrivateKeyFromUploads(outputDir); err != nil {
+			return err
 		}
 		pterm.Info.Println("Private key preserved (private_key.pem)")
 	} else if !os.IsNotExist(err) {
@@ -471,6 +495,17 @@ func copyExtensionArtifacts(browserExtDir, outputDir string) error {
 	return nil
 }
 
+// excludePrivateKeyFromUploads writes a .gitignore so that uploading
+// outputDir leaves private_key.pem out
+func excludePrivateKeyFromUploads(outputDir string) error {
+	gitignorePath := filepath.Join(outputDir, ".gitignore")
+	gitignoreContent := "# Exclude private key from uploads\nprivate_key.pem\n"
+	if err := os.WriteFile(gitignorePath, []byte(gitignoreContent), defaultFileMode); err != nil {
+		return fmt.Errorf("failed to create .gitignore: %w", err)
+	}
+	return nil
+}
+
 // displayWebBotAuthSuccess displays success message and next steps
 func displayWebBotAuthSuccess(outputDir, extensionName, extensionID, hostURL string, usingDefaultKey, autoUpload bool) {
 	pterm.Success.Println("Web-bot-auth extension prepared successfully!")
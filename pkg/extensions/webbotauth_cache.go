@@ -0,0 +1,213 @@
+package extensions
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/pterm/pterm"
+)
+
+// Expected sha256 of the archives at webBotAuthDownloadURL and
+// webBotAuthPrebuiltURL. Update them whenever webBotAuthCommit changes, with
+// the output of: curl -sL <url> | sha256sum
+//
+// While a checksum is empty, the first download of the archive is pinned in
+// the cache index and later downloads must match it.
+const (
+	webBotAuthArchiveSHA256  = ""
+	webBotAuthPrebuiltSHA256 = ""
+)
+
+const sourceCacheIndex = "index.json"
+
+// sourceCacheDir is where downloaded archives are kept, named by their
+// sha256. Tests point it at a temp dir.
+var sourceCacheDir = func() (string, error) {
+	dir, err := util.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sources"), nil
+}
+
+// fetchArchive returns a local copy of the zip at url, checked against
+// wantSHA256 (or the checksum pinned for url by an earlier download). With
+// useCache, a cached copy is used when present and downloads are added to
+// the cache; otherwise the copy is a temp file that cleanup removes.
+func fetchArchive(ctx context.Context, url, wantSHA256 string, useCache bool) (path string, cleanup func(), err error) {
+	cleanup = func() {}
+
+	wantSHA256 = strings.ToLower(strings.TrimSpace(wantSHA256))
+	var cacheDir string
+	var index map[string]string
+	if useCache {
+		if cacheDir, err = sourceCacheDir(); err != nil {
+			return "", cleanup, fmt.Errorf("failed to locate cache directory: %w", err)
+		}
+		index = loadSourceIndex(cacheDir)
+		if wantSHA256 == "" {
+			wantSHA256 = index[url]
+		}
+		if wantSHA256 != "" {
+			cached := filepath.Join(cacheDir, "sha256-"+wantSHA256+".zip")
+			if got, err := fileSHA256(cached); err == nil {
+				if got == wantSHA256 {
+					pterm.Info.Printf("Using cached archive %s\n", cached)
+					return cached, cleanup, nil
+				}
+				pterm.Warning.Printf("Cached archive %s is corrupt; downloading again\n", cached)
+				_ = os.Remove(cached)
+			}
+		}
+	}
+
+	client := &http.Client{Timeout: downloadTimeout}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", cleanup, fmt.Errorf("failed to create download request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", cleanup, fmt.Errorf("failed to download web-bot-auth: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", cleanup, fmt.Errorf("failed to download web-bot-auth: HTTP %d", resp.StatusCode)
+	}
+
+	// Save to temporary file
+	tmpZip, err := os.CreateTemp(cacheDir, "web-bot-auth-*.zip")
+	if err != nil {
+		return "", cleanup, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpZipPath := tmpZip.Name()
+	cleanup = func() { os.Remove(tmpZipPath) }
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmpZip, h), resp.Body); err != nil {
+		tmpZip.Close()
+		return "", cleanup, fmt.Errorf("failed to save download: %w", err)
+	}
+	tmpZip.Close()
+
+	got := hex.EncodeToString(h.Sum(nil))
+	if wantSHA256 != "" && got != wantSHA256 {
+		return "", cleanup, fmt.Errorf("archive checksum mismatch for %s: got sha256 %s, expected %s", url, got, wantSHA256)
+	}
+	if wantSHA256 == "" {
+		pterm.Warning.Printf("No checksum is pinned for %s; using it unverified (sha256 %s)\n", url, got)
+	}
+	if !useCache {
+		return tmpZipPath, cleanup, nil
+	}
+
+	cached := filepath.Join(cacheDir, "sha256-"+got+".zip")
+	if err := os.Rename(tmpZipPath, cached); err != nil {
+		return "", cleanup, fmt.Errorf("failed to cache archive: %w", err)
+	}
+	cleanup = func() {}
+	if index[url] != got {
+		index[url] = got
+		if err := saveSourceIndex(cacheDir, index); err != nil {
+			pterm.Warning.Printf("Failed to record archive checksum: %v\n", err)
+		}
+	}
+	return cached, cleanup, nil
+}
+
+// loadSourceIndex reads the url -> sha256 pins, treating a missing or
+// unreadable index as empty.
+func loadSourceIndex(cacheDir string) map[string]string {
+	index := map[string]string{}
+	if b, err := os.ReadFile(filepath.Join(cacheDir, sourceCacheIndex)); err == nil {
+		_ = json.Unmarshal(b, &index)
+	}
+	return index
+}
+
+func saveSourceIndex(cacheDir string, index map[string]string) error {
+	b, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(cacheDir, sourceCacheIndex), b, defaultFileMode)
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// copyWebBotAuthSource copies a local web-bot-auth checkout to a temp dir the
+// build can modify, leaving out dependencies and VCS data, and returns the
+// browser-extension directory inside it.
+func copyWebBotAuthSource(sourceDir string) (browserExtDir string, cleanup func(), err error) {
+	cleanup = func() {}
+	src, err := filepath.Abs(sourceDir)
+	if err != nil {
+		return "", cleanup, fmt.Errorf("failed to resolve source directory: %w", err)
+	}
+	if _, err := os.Stat(filepath.Join(src, "examples", "browser-extension")); err != nil {
+		return "", cleanup, fmt.Errorf("%s is not a web-bot-auth checkout: examples/browser-extension not found", src)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "web-bot-auth-src-*")
+	if err != nil {
+		return "", cleanup, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	cleanup = func() { os.RemoveAll(tmpDir) }
+
+	pterm.Info.Printf("Copying web-bot-auth source from %s...\n", src)
+	err = filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && (d.Name() == "node_modules" || d.Name() == ".git") {
+			return filepath.SkipDir
+		}
+		dst := filepath.Join(tmpDir, rel)
+		if d.IsDir() {
+			return os.MkdirAll(dst, defaultDirMode)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		return util.CopyFile(path, dst)
+	})
+	if err != nil {
+		return "", cleanup, fmt.Errorf("failed to copy source directory: %w", err)
+	}
+	return filepath.Join(tmpDir, "examples", "browser-extension"), cleanup, nil
+}
+
+// sourceDirHint is appended to download errors so air-gapped users find the
+// offline options.
+func sourceDirHint(err error) error {
+	if err == nil || !strings.Contains(err.Error(), "failed to download") {
+		return err
+	}
+	return fmt.Errorf("%w (use --source-dir with a local web-bot-auth checkout to build offline)", err)
+}
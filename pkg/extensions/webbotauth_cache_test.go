@@ -0,0 +1,105 @@
+package extensions
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchArchive_CachesAndVerifies(t *testing.T) {
+	cacheDir := useTempSourceCache(t)
+	body := []byte("archive v1")
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+	sum := sha256.Sum256(body)
+	want := hex.EncodeToString(sum[:])
+
+	path, cleanup, err := fetchArchive(context.Background(), srv.URL, want, true)
+	require.NoError(t, err)
+	cleanup()
+	assert.Equal(t, filepath.Join(cacheDir, "sha256-"+want+".zip"), path)
+	assert.FileExists(t, path, "cached archives survive cleanup")
+
+	// The second fetch is served from the cache
+	_, _, err = fetchArchive(context.Background(), srv.URL, want, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests)
+
+	// A changed upstream archive no longer matches, and nothing is cached
+	require.NoError(t, os.Remove(path))
+	body = []byte("archive v2")
+	_, _, err = fetchArchive(context.Background(), srv.URL, want, true)
+	assert.ErrorContains(t, err, "checksum mismatch")
+	assert.NoFileExists(t, path)
+
+	// Without the cache, nothing is kept
+	body = []byte("archive v1")
+	path, cleanup, err = fetchArchive(context.Background(), srv.URL, want, false)
+	require.NoError(t, err)
+	cleanup()
+	assert.NoFileExists(t, path)
+}
+
+func TestFetchArchive_PinsFirstDownloadWithoutChecksum(t *testing.T) {
+	cacheDir := useTempSourceCache(t)
+	body := []byte("archive v1")
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+	sum := sha256.Sum256(body)
+	want := hex.EncodeToString(sum[:])
+
+	path, cleanup, err := fetchArchive(context.Background(), srv.URL, "", true)
+	require.NoError(t, err)
+	cleanup()
+	assert.Equal(t, filepath.Join(cacheDir, "sha256-"+want+".zip"), path)
+
+	_, _, err = fetchArchive(context.Background(), srv.URL, "", true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests, "the pinned archive is served from the cache")
+
+	// A changed upstream archive no longer matches the pinned checksum
+	require.NoError(t, os.Remove(path))
+	body = []byte("archive v2")
+	_, _, err = fetchArchive(context.Background(), srv.URL, "", true)
+	assert.ErrorContains(t, err, "checksum mismatch")
+
+	// Without the cache, nothing is pinned or kept
+	path, cleanup, err = fetchArchive(context.Background(), srv.URL, "", false)
+	require.NoError(t, err)
+	cleanup()
+	assert.NoFileExists(t, path)
+}
+
+func TestCopyWebBotAuthSource(t *testing.T) {
+	src := t.TempDir()
+	extDir := filepath.Join(src, "examples", "browser-extension")
+	require.NoError(t, os.MkdirAll(filepath.Join(extDir, "src"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(extDir, "src", "background.ts"), []byte("ts"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "node_modules", "dep"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "node_modules", "dep", "index.js"), []byte("js"), 0o644))
+
+	browserExtDir, cleanup, err := copyWebBotAuthSource(src)
+	defer cleanup()
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(browserExtDir, "src", "background.ts"))
+	assert.NoDirExists(t, filepath.Join(browserExtDir, "..", "..", "node_modules"))
+
+	_, _, err = copyWebBotAuthSource(t.TempDir())
+	assert.ErrorContains(t, err, "not a web-bot-auth checkout")
+}
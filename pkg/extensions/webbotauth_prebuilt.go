@@ -0,0 +1,194 @@
+package extensions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/pterm/pterm"
+)
+
+// webBotAuthPrebuiltURL is the web-bot-auth release artifact built from
+// webBotAuthCommit with the default test key, no signature agent, and
+// defaultLocalhostURL as the host. Its layout is:
+//
+//	chromium/                                  unpacked extension (dist/mv3/chromium)
+//	update.xml
+//	http-message-signatures-extension.crx
+//	policy/                                    policy.json, plist and their .templ sources
+const webBotAuthPrebuiltURL = "https://github.com/kernel/web-bot-auth/releases/download/build-" + webBotAuthCommit + "/web-bot-auth-prebuilt.zip"
+
+const webBotAuthCRX = "http-message-signatures-extension.crx"
+
+var updateXMLAppID = regexp.MustCompile(`appid=['"]([a-p]{32})['"]`)
+
+// prepareWebBotAuthPrebuilt fills outputDir from the prebuilt artifact at url
+// (which must have the sha256 wantSHA256), doing locally only what depends on
+// the inputs: injecting the signing key and pointing the update and policy
+// URLs at hostURL. No Node toolchain is needed. Returns the Chrome extension
+// ID from update.xml.
+func prepareWebBotAuthPrebuilt(ctx context.Context, url, wantSHA256 string, useCache bool, outputDir, hostURL, keyData, extensionName string, usingDefaultKey bool) (string, error) {
+	// Normalize hostURL by removing trailing slashes to prevent double slashes in URLs
+	hostURL = strings.TrimRight(hostURL, "/")
+
+	pterm.Info.Println("Validating key...")
+	pemData, jwkData, err := webBotAuthKeyMaterial(keyData)
+	if err != nil {
+		return "", err
+	}
+
+	pterm.Info.Println("Downloading prebuilt web-bot-auth extension...")
+	artifactDir, cleanup, err := downloadAndExtractZip(ctx, url, wantSHA256, useCache)
+	defer cleanup()
+	if err != nil {
+		return "", err
+	}
+	// Tolerate archives that wrap everything in one top-level directory
+	if _, err := os.Stat(filepath.Join(artifactDir, "chromium")); os.IsNotExist(err) {
+		if entries, _ := os.ReadDir(artifactDir); len(entries) == 1 && entries[0].IsDir() {
+			artifactDir = filepath.Join(artifactDir, entries[0].Name())
+		}
+	}
+	chromiumDir := filepath.Join(artifactDir, "chromium")
+	if _, err := os.Stat(chromiumDir); err != nil {
+		return "", fmt.Errorf("chromium directory not found in prebuilt archive")
+	}
+
+	if !usingDefaultKey {
+		pterm.Info.Println("Injecting custom JWK into the extension bundle...")
+		if err := injectJWKIntoBundle(chromiumDir, jwkData); err != nil {
+			return "", fmt.Errorf("failed to inject JWK: %w", err)
+		}
+		pterm.Success.Println("Custom JWK injected successfully")
+	}
+
+	pterm.Info.Println("Copying extension files to output directory...")
+	if err := util.CopyDir(chromiumDir, outputDir); err != nil {
+		return "", fmt.Errorf("failed to copy extension files: %w", err)
+	}
+
+	// Same URLs as the npm build ends up with: the update manifest and .crx
+	// are served from <host>/extensions/<name>/
+	pterm.Info.Printf("Updating URLs to use host %s and extension name %s\n", hostURL, extensionName)
+	updateXMLPath := filepath.Join(outputDir, "update.xml")
+	if err := util.CopyFile(filepath.Join(artifactDir, "update.xml"), updateXMLPath); err != nil {
+		return "", fmt.Errorf("failed to copy update.xml: %w", err)
+	}
+	if err := util.ModifyFile(updateXMLPath,
+		fmt.Sprintf("%s/%s", defaultLocalhostURL, webBotAuthCRX),
+		fmt.Sprintf("%s/extensions/%s/%s", hostURL, extensionName, webBotAuthCRX)); err != nil {
+		return "", fmt.Errorf("failed to update update.xml codebase: %w", err)
+	}
+	updateXML, err := os.ReadFile(updateXMLPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read update.xml: %w", err)
+	}
+	m := updateXMLAppID.FindSubmatch(updateXML)
+	if m == nil {
+		return "", fmt.Errorf("failed to find extension ID in update.xml")
+	}
+	extensionID := string(m[1])
+
+	// The .crx is signed over the bundle, so it's only valid while the bundle
+	// is unchanged
+	if usingDefaultKey {
+		if err := util.CopyFile(filepath.Join(artifactDir, webBotAuthCRX), filepath.Join(outputDir, webBotAuthCRX)); err != nil {
+			return "", fmt.Errorf("failed to copy .crx file: %w", err)
+		}
+	} else {
+		pterm.Warning.Println("Skipping the .crx: it can't be re-signed without npm. Upload the unpacked extension instead, or build without --prebuilt for policy installs")
+	}
+
+	policyDst := filepath.Join(outputDir, "policy")
+	if err := util.CopyDir(filepath.Join(artifactDir, "policy"), policyDst); err != nil {
+		return "", fmt.Errorf("failed to copy policy directory: %w", err)
+	}
+	extensionUpdateURL := fmt.Sprintf("%s/extensions/%s/update.xml", hostURL, extensionName)
+	for _, name := range []string{"policy.json", "com.google.Chrome.managed.plist"} {
+		if err := util.ModifyFile(filepath.Join(policyDst, name), defaultLocalhostURL+"/update.xml", extensionUpdateURL); err != nil {
+			return "", fmt.Errorf("failed to update %s: %w", name, err)
+		}
+	}
+	for _, name := range []string{"policy.json.templ", "com.google.Chrome.managed.plist.templ"} {
+		if err := util.ModifyFile(filepath.Join(policyDst, name), defaultLocalhostURL, hostURL); err != nil {
+			return "", fmt.Errorf("failed to modify %s: %w", name, err)
+		}
+	}
+	pterm.Info.Println("Policy files copied (required for Chrome configuration)")
+
+	if err := os.WriteFile(filepath.Join(outputDir, "private_key.pem"), pemData, 0600); err != nil {
+		return "", fmt.Errorf("failed to write private key: %w", err)
+	}
+	if err := excludePrivateKeyFromUploads(outputDir); err != nil {
+		return "", err
+	}
+	pterm.Info.Println("Private key preserved (private_key.pem)")
+
+	return extensionID, nil
+}
+
+// injectJWKIntoBundle swaps the default test key for jwkData in the bundled
+// scripts under dir.
+func injectJWKIntoBundle(dir, jwkData string) error {
+	if err := replaceJWKInBundle(dir, defaultWebBotAuthKey, jwkData); err != nil {
+		if errors.Is(err, errKeyNotInBundle) {
+			return fmt.Errorf("default test key not found in the prebuilt bundle")
+		}
+		return err
+	}
+	return nil
+}
+
+var errKeyNotInBundle = errors.New("key not found in the extension bundle")
+
+// replaceJWKInBundle swaps oldJWK for newJWK in the bundled scripts under
+// dir. The bundler may reformat the inlined JWK object, so its "d" and "x"
+// values are replaced rather than the object as a whole.
+func replaceJWKInBundle(dir, oldJWK, newJWK string) error {
+	var oldKey, key struct {
+		Kty string `json:"kty"`
+		Crv string `json:"crv"`
+		D   string `json:"d"`
+		X   string `json:"x"`
+	}
+	if err := json.Unmarshal([]byte(oldJWK), &oldKey); err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(newJWK), &key); err != nil {
+		return fmt.Errorf("invalid JWK: %w", err)
+	}
+	if key.Kty != "OKP" || key.Crv != "Ed25519" || key.D == "" || key.X == "" {
+		return fmt.Errorf("key must be an Ed25519 private key")
+	}
+	replacer := strings.NewReplacer(oldKey.D, key.D, oldKey.X, key.X)
+
+	injected := false
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".js" {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(string(content), oldKey.D) {
+			return nil
+		}
+		injected = true
+		return os.WriteFile(path, []byte(replacer.Replace(string(content))), defaultFileMode)
+	})
+	if err != nil {
+		return err
+	}
+	if !injected {
+		return errKeyNotInBundle
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package extensions
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testExtensionID = "abcdefghijklmnopabcdefghijklmnop"
+
+// prebuiltArchive builds a zip laid out like the web-bot-auth release artifact.
+func prebuiltArchive(t *testing.T) []byte {
+	t.Helper()
+	files := map[string]string{
+		"web-bot-auth/chromium/manifest.json":                       `{"manifest_version":3,"version":"1.0.0"}`,
+		"web-bot-auth/chromium/background.js":                       `const jwk={kty:"OKP",crv:"Ed25519",d:"n4Ni-HpISpVObnQMW0wOhCKROaIKqKtW_2ZYb2p9KcU",x:"JrQLj5P_89iXES9-vFgrIy29clF9CC_oPPsw3c5D0bs"};`,
+		"web-bot-auth/update.xml":                                   `<app appid='` + testExtensionID + `'><updatecheck codebase='http://localhost:8000/http-message-signatures-extension.crx'/></app>`,
+		"web-bot-auth/" + webBotAuthCRX:                             "crx",
+		"web-bot-auth/policy/policy.json":                           `{"ExtensionSettings":{"update_url":"http://localhost:8000/update.xml"}}`,
+		"web-bot-auth/policy/policy.json.templ":                     `{"update_url":"http://localhost:8000/update.xml"}`,
+		"web-bot-auth/policy/com.google.Chrome.managed.plist":       `<string>http://localhost:8000/update.xml</string>`,
+		"web-bot-auth/policy/com.google.Chrome.managed.plist.templ": `<string>http://localhost:8000/update.xml</string>`,
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+// useTempSourceCache points the archive cache at a fresh directory for the test.
+func useTempSourceCache(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	orig := sourceCacheDir
+	sourceCacheDir = func() (string, error) { return dir, nil }
+	t.Cleanup(func() { sourceCacheDir = orig })
+	return dir
+}
+
+func TestBuildWebBotAuth_Prebuilt(t *testing.T) {
+	useTempSourceCache(t)
+	archive := prebuiltArchive(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(archive)
+	}))
+	defer srv.Close()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	b64 := base64.RawURLEncoding.EncodeToString
+	d, x := b64(priv.Seed()), b64(pub)
+	keyPath := filepath.Join(t.TempDir(), "key.jwk")
+	require.NoError(t, os.WriteFile(keyPath, fmt.Appendf(nil, `{"kty":"OKP","crv":"Ed25519","d":%q,"x":%q}`, d, x), 0600))
+
+	outDir := filepath.Join(t.TempDir(), "out")
+	res, err := BuildWebBotAuth(context.Background(), ExtensionsBuildWebBotAuthInput{
+		Output:        outDir,
+		HostURL:       "https://ext.example.com/",
+		KeyPath:       keyPath,
+		ExtensionName: "my-auth",
+		PrebuiltURL:   srv.URL,
+		ArchiveSHA256: fmt.Sprintf("%x", sha256.Sum256(archive)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, testExtensionID, res.ExtensionID)
+
+	read := func(name string) string {
+		b, err := os.ReadFile(filepath.Join(outDir, name))
+		require.NoError(t, err)
+		return string(b)
+	}
+	bg := read("background.js")
+	assert.Contains(t, bg, `d:"`+d+`"`)
+	assert.Contains(t, bg, `x:"`+x+`"`)
+	assert.NotContains(t, bg, "n4Ni-HpISpVObnQMW0wOhCKROaIKqKtW_2ZYb2p9KcU")
+	assert.Contains(t, read("update.xml"), "https://ext.example.com/extensions/my-auth/"+webBotAuthCRX)
+	assert.Contains(t, read("policy/policy.json"), "https://ext.example.com/extensions/my-auth/update.xml")
+	assert.Contains(t, read("policy/com.google.Chrome.managed.plist"), "https://ext.example.com/extensions/my-auth/update.xml")
+	assert.Contains(t, read("policy/policy.json.templ"), "https://ext.example.com/update.xml")
+	assert.Contains(t, read("private_key.pem"), "PRIVATE KEY")
+	assert.Contains(t, read(".gitignore"), "private_key.pem")
+	// A re-keyed bundle no longer matches the prebuilt .crx signature
+	assert.NoFileExists(t, filepath.Join(outDir, webBotAuthCRX))
+}
+
+func TestBuildWebBotAuth_PrebuiltRejectsSignatureAgent(t *testing.T) {
+	_, err := BuildWebBotAuth(context.Background(), ExtensionsBuildWebBotAuthInput{
+		Output:            t.TempDir(),
+		Prebuilt:          true,
+		SignatureAgentURL: "https://agent.example.com",
+	})
+	assert.ErrorContains(t, err, "--signature-agent")
+}
@@ -0,0 +1,253 @@
+package extensions
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kernel/cli/pkg/util"
+)
+
+var (
+	manifestVersionField = regexp.MustCompile(`("version"\s*:\s*")([^"]*)(")`)
+	updateCheckVersion   = regexp.MustCompile(`(<updatecheck\b[^>]*\bversion=['"])([^'"]*)(['"])`)
+)
+
+type RotateWebBotAuthKeyInput struct {
+	Dir        string // Extension directory written by BuildWebBotAuth
+	KeyPath    string // New Ed25519 signing key (JWK or PEM)
+	CRXKeyPath string // RSA or ECDSA P-256 key to re-sign the .crx with (optional)
+}
+
+type RotateWebBotAuthKeyOutput struct {
+	Dir             string `json:"dir"`
+	ExtensionID     string `json:"extension_id,omitempty"`
+	PreviousVersion string `json:"previous_version"`
+	Version         string `json:"version"`
+	KeyID           string `json:"key_id"`
+	PreviousKeyID   string `json:"previous_key_id"`
+	// CRX is the re-signed package; CRXRemoved is set instead when there was
+	// no CRX key and the stale package was deleted.
+	CRX        string `json:"crx,omitempty"`
+	CRXRemoved bool   `json:"crx_removed,omitempty"`
+	// JWKS holds the keys origins should trust: the new key, then the old
+	// one (unless it was the public test key) for browsers not yet updated.
+	JWKS util.JWKS `json:"jwks"`
+}
+
+// RotateWebBotAuthKey swaps the signing key of an already-built extension
+// in place: the JWK in the bundled scripts, private_key.pem, and a bumped
+// version in manifest.json and update.xml so installed copies update. With
+// a CRX key the .crx is re-signed; its extension ID must match update.xml.
+func RotateWebBotAuthKey(in RotateWebBotAuthKeyInput) (*RotateWebBotAuthKeyOutput, error) {
+	dir, err := filepath.Abs(in.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve directory: %w", err)
+	}
+	manifestPath := filepath.Join(dir, "manifest.json")
+	manifest, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a built extension: manifest.json not found", dir)
+	}
+	oldPEM, err := os.ReadFile(filepath.Join(dir, "private_key.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("no private_key.pem in %s; can't tell which key the extension was built with", dir)
+	}
+	oldJWK, err := util.ConvertPEMToJWK(string(oldPEM))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private_key.pem: %w", err)
+	}
+	keyBytes, err := os.ReadFile(in.KeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	pemData, jwkData, err := webBotAuthKeyMaterial(string(keyBytes))
+	if err != nil {
+		return nil, err
+	}
+	oldPublic, err := util.ToPublicJWK(oldJWK)
+	if err != nil {
+		return nil, err
+	}
+	newPublic, err := util.ToPublicJWK(jwkData)
+	if err != nil {
+		return nil, err
+	}
+	if oldPublic.X == newPublic.X {
+		return nil, fmt.Errorf("the extension is already signing with this key")
+	}
+
+	out := &RotateWebBotAuthKeyOutput{Dir: dir, KeyID: newPublic.Kid, PreviousKeyID: oldPublic.Kid}
+	m := manifestVersionField.FindSubmatch(manifest)
+	if m == nil {
+		return nil, fmt.Errorf("manifest.json has no version")
+	}
+	out.PreviousVersion = string(m[2])
+	if out.Version, err = bumpExtensionVersion(out.PreviousVersion); err != nil {
+		return nil, err
+	}
+
+	updateXMLPath := filepath.Join(dir, "update.xml")
+	updateXML, err := os.ReadFile(updateXMLPath)
+	hasUpdateXML := err == nil
+	if hasUpdateXML {
+		if m := updateXMLAppID.FindSubmatch(updateXML); m != nil {
+			out.ExtensionID = string(m[1])
+		}
+	}
+
+	// Check the CRX key before changing anything: policies install by ID
+	var crxKey crypto.Signer
+	var crxKeyPath string
+	if in.CRXKeyPath != "" {
+		if crxKeyPath, err = filepath.Abs(in.CRXKeyPath); err != nil {
+			return nil, fmt.Errorf("failed to resolve CRX key path: %w", err)
+		}
+		crxKeyData, err := os.ReadFile(crxKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CRX key: %w", err)
+		}
+		if crxKey, err = parseCRXKey(crxKeyData); err != nil {
+			return nil, fmt.Errorf("invalid CRX key: %w", err)
+		}
+		id, err := crxKeyExtensionID(crxKey)
+		if err != nil {
+			return nil, err
+		}
+		if out.ExtensionID != "" && id != out.ExtensionID {
+			return nil, fmt.Errorf("the CRX key is for extension %s, but update.xml is for %s", id, out.ExtensionID)
+		}
+		out.ExtensionID = id
+	}
+
+	if err := replaceJWKInBundle(dir, oldJWK, jwkData); err != nil {
+		if errors.Is(err, errKeyNotInBundle) {
+			return nil, fmt.Errorf("the key in private_key.pem isn't in the extension's scripts; was the directory built with it?")
+		}
+		return nil, fmt.Errorf("failed to inject JWK: %w", err)
+	}
+	manifest = manifestVersionField.ReplaceAll(manifest, []byte("${1}"+out.Version+"${3}"))
+	if err := os.WriteFile(manifestPath, manifest, defaultFileMode); err != nil {
+		return nil, fmt.Errorf("failed to write manifest.json: %w", err)
+	}
+
+	crxPath := filepath.Join(dir, webBotAuthCRX)
+	if crxKey != nil {
+		zipData, err := zipUnpackedExtension(dir, crxKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to zip extension: %w", err)
+		}
+		crx, _, err := packCRX3(zipData, crxKey)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(crxPath, crx, defaultFileMode); err != nil {
+			return nil, fmt.Errorf("failed to write .crx: %w", err)
+		}
+		out.CRX = crxPath
+	} else if err := os.Remove(crxPath); err == nil {
+		// The old package still carries the old key
+		out.CRXRemoved = true
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove stale .crx: %w", err)
+	}
+
+	if hasUpdateXML {
+		updated := updateCheckVersion.ReplaceAll(updateXML, []byte("${1}"+out.Version+"${3}"))
+		if out.ExtensionID != "" {
+			updated = updateXMLAppID.ReplaceAll(updated, []byte("appid='"+out.ExtensionID+"'"))
+		}
+		if err := os.WriteFile(updateXMLPath, updated, defaultFileMode); err != nil {
+			return nil, fmt.Errorf("failed to write update.xml: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "private_key.pem"), pemData, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write private key: %w", err)
+	}
+	if err := excludePrivateKeyFromUploads(dir); err != nil {
+		return nil, err
+	}
+
+	out.JWKS.Keys = []util.PublicJWK{newPublic}
+	if defaultPublic, err := util.ToPublicJWK(defaultWebBotAuthKey); err == nil && oldPublic.X != defaultPublic.X {
+		out.JWKS.Keys = append(out.JWKS.Keys, oldPublic)
+	}
+	return out, nil
+}
+
+// bumpExtensionVersion increments the last part of a Chrome extension
+// version (1-4 dot-separated integers up to 65535).
+func bumpExtensionVersion(v string) (string, error) {
+	parts := strings.Split(v, ".")
+	if len(parts) > 4 {
+		return "", fmt.Errorf("invalid extension version %q", v)
+	}
+	for _, p := range parts {
+		if n, err := strconv.Atoi(p); err != nil || n < 0 || n > 65535 {
+			return "", fmt.Errorf("invalid extension version %q", v)
+		}
+	}
+	last, _ := strconv.Atoi(parts[len(parts)-1])
+	if last == 65535 {
+		if len(parts) == 4 {
+			return "", fmt.Errorf("can't bump extension version %q", v)
+		}
+		parts = append(parts, "1")
+	} else {
+		parts[len(parts)-1] = strconv.Itoa(last + 1)
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// zipUnpackedExtension zips the unpacked extension in dir, leaving out the
+// build artifacts that sit next to it and the key at skipPath.
+func zipUnpackedExtension(dir, skipPath string) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		name := d.Name()
+		if strings.HasPrefix(name, ".") || path == skipPath ||
+			(filepath.Dir(rel) == "." && (name == "policy" || name == "update.xml" || name == "private_key.pem" || filepath.Ext(name) == ".crx")) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		w, err := zw.Create(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(content)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
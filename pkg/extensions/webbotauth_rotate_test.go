@@ -0,0 +1,192 @@
+package extensions
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kernel/cli/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeBuiltExtension lays out dir like build-web-bot-auth output, built with
+// the default test key.
+func writeBuiltExtension(t *testing.T, dir, appID string) {
+	t.Helper()
+	var key struct{ D, X string }
+	require.NoError(t, json.Unmarshal([]byte(defaultWebBotAuthKey), &key))
+	pemData, err := util.ConvertJWKToPEM(defaultWebBotAuthKey)
+	require.NoError(t, err)
+	files := map[string]string{
+		"manifest.json":      `{"manifest_version": 3, "name": "sig", "version": "1.0.0"}`,
+		"background.js":      `const jwk={kty:"OKP",crv:"Ed25519",d:"` + key.D + `",x:"` + key.X + `"};`,
+		"private_key.pem":    string(pemData),
+		"update.xml":         `<?xml version='1.0' encoding='UTF-8'?><gupdate><app appid='` + appID + `'><updatecheck codebase='https://ext.example.com/extensions/sig/` + webBotAuthCRX + `' version='1.0.0' /></app></gupdate>`,
+		webBotAuthCRX:        "stale",
+		"policy/policy.json": `{}`,
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+}
+
+func writeNewSigningKey(t *testing.T) (path string, x string) {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	path = filepath.Join(t.TempDir(), "new.pem")
+	require.NoError(t, os.WriteFile(path, pemData, 0o600))
+	jwk, err := util.ConvertPEMToJWK(string(pemData))
+	require.NoError(t, err)
+	var k struct{ X string }
+	require.NoError(t, json.Unmarshal([]byte(jwk), &k))
+	return path, k.X
+}
+
+func TestRotateWebBotAuthKey(t *testing.T) {
+	dir := t.TempDir()
+	writeBuiltExtension(t, dir, "abcdefghijklmnopabcdefghijklmnop")
+	keyPath, newX := writeNewSigningKey(t)
+
+	out, err := RotateWebBotAuthKey(RotateWebBotAuthKeyInput{Dir: dir, KeyPath: keyPath})
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", out.PreviousVersion)
+	assert.Equal(t, "1.0.1", out.Version)
+	assert.Equal(t, "abcdefghijklmnopabcdefghijklmnop", out.ExtensionID)
+	assert.True(t, out.CRXRemoved)
+	assert.NoFileExists(t, filepath.Join(dir, webBotAuthCRX))
+
+	background, err := os.ReadFile(filepath.Join(dir, "background.js"))
+	require.NoError(t, err)
+	assert.Contains(t, string(background), newX)
+	assert.NotContains(t, string(background), "JrQLj5P_89iXES9-vFgrIy29clF9CC_oPPsw3c5D0bs")
+	manifest, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(manifest), `"version": "1.0.1"`)
+	updateXML, err := os.ReadFile(filepath.Join(dir, "update.xml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(updateXML), `version='1.0.1'`)
+	assert.Contains(t, string(updateXML), `<?xml version='1.0'`)
+
+	// The old key was the public test key, so origins shouldn't keep trusting it
+	require.Len(t, out.JWKS.Keys, 1)
+	assert.Equal(t, newX, out.JWKS.Keys[0].X)
+	assert.Equal(t, out.KeyID, out.JWKS.Keys[0].Kid)
+
+	// Rotating again keeps the previous (custom) key in the JWKS
+	nextPath, _ := writeNewSigningKey(t)
+	out, err = RotateWebBotAuthKey(RotateWebBotAuthKeyInput{Dir: dir, KeyPath: nextPath})
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.2", out.Version)
+	require.Len(t, out.JWKS.Keys, 2)
+	assert.Equal(t, newX, out.JWKS.Keys[1].X)
+
+	_, err = RotateWebBotAuthKey(RotateWebBotAuthKeyInput{Dir: dir, KeyPath: nextPath})
+	assert.ErrorContains(t, err, "already signing with this key")
+}
+
+func TestRotateWebBotAuthKey_ResignsCRX(t *testing.T) {
+	crxKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(crxKey)
+	require.NoError(t, err)
+	crxKeyPath := filepath.Join(t.TempDir(), "crx.pem")
+	require.NoError(t, os.WriteFile(crxKeyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	id, err := crxKeyExtensionID(crxKey)
+	require.NoError(t, err)
+	keyPath, _ := writeNewSigningKey(t)
+
+	mismatched := t.TempDir()
+	writeBuiltExtension(t, mismatched, "abcdefghijklmnopabcdefghijklmnop")
+	_, err = RotateWebBotAuthKey(RotateWebBotAuthKeyInput{Dir: mismatched, KeyPath: keyPath, CRXKeyPath: crxKeyPath})
+	assert.ErrorContains(t, err, "but update.xml is for")
+	manifest, _ := os.ReadFile(filepath.Join(mismatched, "manifest.json"))
+	assert.Contains(t, string(manifest), `"version": "1.0.0"`, "nothing changes when the CRX key is wrong")
+
+	dir := t.TempDir()
+	writeBuiltExtension(t, dir, id)
+	out, err := RotateWebBotAuthKey(RotateWebBotAuthKeyInput{Dir: dir, KeyPath: keyPath, CRXKeyPath: crxKeyPath})
+	require.NoError(t, err)
+	assert.Equal(t, id, out.ExtensionID)
+	crx, err := os.ReadFile(out.CRX)
+	require.NoError(t, err)
+
+	zipData := verifyCRX3(t, crx, &crxKey.PublicKey)
+	zr, err := zip.NewReader(bytes.NewReader(zipData), int64(len(zipData)))
+	require.NoError(t, err)
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{"background.js", "manifest.json"}, names, "build artifacts and keys stay out of the package")
+}
+
+// verifyCRX3 checks a CRX3's ECDSA proof and returns its zip payload.
+func verifyCRX3(t *testing.T, crx []byte, pub *ecdsa.PublicKey) []byte {
+	t.Helper()
+	require.Equal(t, "Cr24", string(crx[:4]))
+	require.EqualValues(t, 3, binary.LittleEndian.Uint32(crx[4:8]))
+	headerLen := binary.LittleEndian.Uint32(crx[8:12])
+	header, zipData := crx[12:12+headerLen], crx[12+headerLen:]
+
+	fields := protoFields(t, header)
+	proof := protoFields(t, fields[crx3FieldSHA256WithECDSA])
+	signedData := fields[crx3FieldSignedHeaderData]
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	assert.Equal(t, pubDER, proof[crx3ProofPublicKey])
+	crxID := sha256.Sum256(pubDER)
+	assert.Equal(t, crxID[:16], protoFields(t, signedData)[crx3SignedDataCRXID])
+
+	msg := []byte("CRX3 SignedData\x00")
+	msg = binary.LittleEndian.AppendUint32(msg, uint32(len(signedData)))
+	msg = append(append(msg, signedData...), zipData...)
+	digest := sha256.Sum256(msg)
+	assert.True(t, ecdsa.VerifyASN1(pub, digest[:], proof[crx3ProofSignature]), "CRX signature")
+	return zipData
+}
+
+func protoFields(t *testing.T, b []byte) map[int][]byte {
+	t.Helper()
+	fields := map[int][]byte{}
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		require.Greater(t, n, 0)
+		require.EqualValues(t, 2, tag&7, "only length-delimited fields are expected")
+		b = b[n:]
+		size, n := binary.Uvarint(b)
+		require.Greater(t, n, 0)
+		b = b[n:]
+		fields[int(tag>>3)] = b[:size]
+		b = b[size:]
+	}
+	return fields
+}
+
+func TestBumpExtensionVersion(t *testing.T) {
+	for in, want := range map[string]string{"1": "2", "1.0.0": "1.0.1", "2.3.65535": "2.3.65535.1"} {
+		got, err := bumpExtensionVersion(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got)
+	}
+	for _, in := range []string{"", "1.0.beta", "1.2.3.65535", "1.2.3.4.5"} {
+		_, err := bumpExtensionVersion(in)
+		assert.Error(t, err, in)
+	}
+}
@@ -2,6 +2,9 @@ package extensions
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
 	"net/http"
 	"testing"
 	"time"
@@ -40,6 +43,14 @@ func TestWebBotAuthDownloadable(t *testing.T) {
 		assert.GreaterOrEqual(t, contentLength, int64(1024), "Content-Length should be at least 1KB")
 	}
 
+	// Once pinned, the checksum must match the archive or every build fails
+	if webBotAuthArchiveSHA256 != "" {
+		h := sha256.New()
+		_, err = io.Copy(h, resp.Body)
+		require.NoError(t, err, "Failed to read web-bot-auth archive")
+		assert.Equal(t, webBotAuthArchiveSHA256, hex.EncodeToString(h.Sum(nil)), "webBotAuthArchiveSHA256 does not match the archive at webBotAuthDownloadURL")
+	}
+
 	t.Logf("Successfully verified web-bot-auth is downloadable")
 	t.Logf("Content-Type: %s", contentType)
 	t.Logf("Content-Length: %d bytes", contentLength)
@@ -54,7 +65,7 @@ func TestDownloadAndExtractWebBotAuth(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
 
-	browserExtDir, cleanup, err := downloadAndExtractWebBotAuth(ctx)
+	browserExtDir, cleanup, err := downloadAndExtractWebBotAuth(ctx, "", false)
 	defer cleanup()
 
 	require.NoError(t, err, "Failed to download and extract web-bot-auth")
@@ -0,0 +1,383 @@
+// Package lock implements advisory, TTL-based locks on Kernel resources
+// (e.g. profiles) stored as files under the CLI config directory. Locks only
+// coordinate processes that share this machine's config directory.
+package lock
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+)
+
+// OwnerEnvVar overrides the default lock owner. Processes that set the same
+// owner share its locks instead of contending for them.
+const OwnerEnvVar = "KERNEL_LOCK_OWNER"
+
+// Lock is a single held lock.
+type Lock struct {
+	Resource   string    `json:"resource"`
+	Owner      string    `json:"owner"`
+	PID        int       `json:"pid"`
+	Note       string    `json:"note,omitempty"`
+	SessionID  string    `json:"session_id,omitempty"`
+	RunID      string    `json:"run_id,omitempty"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the lock's TTL has elapsed at now.
+func (l Lock) Expired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
+
+// HeldError is returned when a lock is held by a different owner.
+type HeldError struct {
+	Lock Lock
+}
+
+func (e *HeldError) Error() string {
+	msg := fmt.Sprintf("%s is locked by %s (pid %d) until %s", e.Lock.Resource, e.Lock.Owner, e.Lock.PID, util.FormatLocal(e.Lock.ExpiresAt))
+	if e.Lock.Note != "" {
+		msg += fmt.Sprintf(" [%s]", e.Lock.Note)
+	}
+	return msg
+}
+
+// Options describe a lock acquisition.
+type Options struct {
+	TTL       time.Duration
+	Owner     string
+	Note      string
+	SessionID string
+	RunID     string
+}
+
+// Store reads and writes lock files in Dir.
+type Store struct {
+	Dir string
+	Now func() time.Time
+}
+
+// DefaultStore returns a store rooted at ~/.config/kernel/locks.
+func DefaultStore() (*Store, error) {
+	configDir, err := util.ConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return &Store{Dir: filepath.Join(configDir, "locks")}, nil
+}
+
+// DefaultOwner returns $KERNEL_LOCK_OWNER, or user@hostname.
+func DefaultOwner() string {
+	if o := strings.TrimSpace(os.Getenv(OwnerEnvVar)); o != "" {
+		return o
+	}
+	name := "unknown"
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		name = u.Username
+	}
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "localhost"
+	}
+	return name + "@" + host
+}
+
+// ProcessOwner returns $KERNEL_LOCK_OWNER, or user@hostname#pid, so that two
+// automations run by the same user on the same machine still contend for a
+// lock unless they opt in to sharing it.
+func ProcessOwner() string {
+	if o := strings.TrimSpace(os.Getenv(OwnerEnvVar)); o != "" {
+		return o
+	}
+	return DefaultOwner() + "#" + strconv.Itoa(os.Getpid())
+}
+
+// IsProcessOwner reports whether processOwner is the ProcessOwner of one of
+// owner's processes, i.e. owner#pid.
+func IsProcessOwner(processOwner, owner string) bool {
+	pid, ok := strings.CutPrefix(processOwner, owner+"#")
+	if !ok {
+		return false
+	}
+	_, err := strconv.Atoi(pid)
+	return err == nil
+}
+
+// ProfileResource returns the lock resource key for a profile ID or name.
+func ProfileResource(profile string) string {
+	return "profile/" + profile
+}
+
+// AuthConnectionResource returns the lock resource key for runs that drive an
+// auth connection's login flow.
+func AuthConnectionResource(id string) string {
+	return "auth-connection/" + id
+}
+
+// AppDeployResource returns the lock resource key for deploys of a version of
+// an app, wherever they are deployed from.
+func AppDeployResource(app, version string) string {
+	return "deploy/" + app + "@" + version
+}
+
+// DeployResource returns the lock resource key for deploys of the app whose
+// source is identified by source (a directory or repository location). It is
+// the fallback for when the app's name isn't known.
+func DeployResource(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return "deploy/" + hex.EncodeToString(sum[:8])
+}
+
+func (s *Store) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// path names lock files by the sha256 of their resource, so distinct
+// resources never share a file; the file itself records the resource.
+func (s *Store) path(resource string) string {
+	sum := sha256.Sum256([]byte(resource))
+	return filepath.Join(s.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get returns the current unexpired lock for resource, or nil if unlocked.
+func (s *Store) Get(resource string) (*Lock, error) {
+	l, err := s.read(resource)
+	if err != nil || l == nil {
+		return nil, err
+	}
+	if l.Expired(s.now()) {
+		return nil, nil
+	}
+	return l, nil
+}
+
+// Acquire takes the lock for resource. Re-acquiring a lock already held by the
+// same owner refreshes its TTL. A *HeldError is returned when another owner
+// holds an unexpired lock.
+func (s *Store) Acquire(resource string, opts Options) (*Lock, error) {
+	if opts.TTL <= 0 {
+		return nil, fmt.Errorf("lock TTL must be positive")
+	}
+	if opts.Owner == "" {
+		opts.Owner = DefaultOwner()
+	}
+
+	var l Lock
+	err := s.withFileLock(resource, func() error {
+		now := s.now()
+		l = Lock{
+			Resource:   resource,
+			Owner:      opts.Owner,
+			PID:        os.Getpid(),
+			Note:       opts.Note,
+			SessionID:  opts.SessionID,
+			RunID:      opts.RunID,
+			AcquiredAt: now,
+			ExpiresAt:  now.Add(opts.TTL),
+		}
+		existing, err := s.read(resource)
+		if err != nil {
+			return err
+		}
+		if existing != nil && !existing.Expired(now) && existing.Owner != opts.Owner {
+			return &HeldError{Lock: *existing}
+		}
+		if existing != nil && existing.Owner == opts.Owner {
+			l.AcquiredAt = existing.AcquiredAt
+		}
+		return s.write(l)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// LostError is returned by Refresh when the lock is no longer held by the
+// owner refreshing it.
+type LostError struct {
+	Resource string
+	// Holder is whoever holds the lock now, or nil if nobody does.
+	Holder *Lock
+}
+
+func (e *LostError) Error() string {
+	if e.Holder == nil {
+		return fmt.Sprintf("lost the lock on %s: it was released", e.Resource)
+	}
+	return fmt.Sprintf("lost the lock on %s: it is now held by %s (pid %d)", e.Resource, e.Holder.Owner, e.Holder.PID)
+}
+
+// Refresh extends a lock held by owner to ttl from now. Unlike Acquire it
+// never takes a lock that was released or taken over meanwhile; it returns a
+// *LostError instead.
+func (s *Store) Refresh(resource, owner string, ttl time.Duration) (*Lock, error) {
+	var l *Lock
+	err := s.withFileLock(resource, func() error {
+		existing, err := s.read(resource)
+		if err != nil {
+			return err
+		}
+		if existing == nil || existing.Owner != owner {
+			return &LostError{Resource: resource, Holder: existing}
+		}
+		existing.ExpiresAt = s.now().Add(ttl)
+		l = existing
+		return s.write(*existing)
+	})
+	return l, err
+}
+
+// Release removes the lock for resource. Unless force is set, only the owner
+// may release an unexpired lock. Releasing an unlocked resource is a no-op.
+func (s *Store) Release(resource, owner string, force bool) error {
+	if owner == "" {
+		owner = DefaultOwner()
+	}
+	return s.withFileLock(resource, func() error {
+		existing, err := s.read(resource)
+		if err != nil || existing == nil {
+			return err
+		}
+		if !force && !existing.Expired(s.now()) && existing.Owner != owner {
+			return &HeldError{Lock: *existing}
+		}
+		if err := os.Remove(s.path(resource)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove lock: %w", err)
+		}
+		return nil
+	})
+}
+
+// ReleaseSession removes any locks acquired on behalf of a browser session.
+func (s *Store) ReleaseSession(sessionID string) ([]Lock, error) {
+	locks, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	var released []Lock
+	for _, l := range locks {
+		if l.SessionID == "" || l.SessionID != sessionID {
+			continue
+		}
+		err := s.withFileLock(l.Resource, func() error {
+			// The lock may have been replaced since it was listed.
+			current, err := s.read(l.Resource)
+			if err != nil || current == nil || current.SessionID != sessionID {
+				return err
+			}
+			if err := os.Remove(s.path(l.Resource)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("remove lock: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return released, err
+		}
+		released = append(released, l)
+	}
+	return released, nil
+}
+
+// List returns all unexpired locks ordered by resource.
+func (s *Store) List() ([]Lock, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	now := s.now()
+	var locks []Lock
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(s.Dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		var l Lock
+		if err := json.Unmarshal(b, &l); err != nil || l.Expired(now) {
+			continue
+		}
+		locks = append(locks, l)
+	}
+	sort.Slice(locks, func(i, j int) bool { return locks[i].Resource < locks[j].Resource })
+	return locks, nil
+}
+
+func (s *Store) read(resource string) (*Lock, error) {
+	b, err := os.ReadFile(s.path(resource))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read lock: %w", err)
+	}
+	var l Lock
+	if err := json.Unmarshal(b, &l); err != nil {
+		return nil, fmt.Errorf("parse lock %s: %w", s.path(resource), err)
+	}
+	return &l, nil
+}
+
+// withFileLock runs fn holding an exclusive OS file lock on the resource's
+// sidecar file, so that reading, checking and replacing its lock file is
+// atomic across processes. The sidecar is never removed: removing it would
+// let two processes lock different files.
+func (s *Store) withFileLock(resource string, fn func() error) error {
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return fmt.Errorf("create lock directory: %w", err)
+	}
+	f, err := os.OpenFile(s.path(resource)+".flock", os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("open lock: %w", err)
+	}
+	defer f.Close()
+	if err := lockFile(f); err != nil {
+		return fmt.Errorf("lock %s: %w", resource, err)
+	}
+	defer func() { _ = unlockFile(f) }()
+	return fn()
+}
+
+// write replaces the lock file through a rename, so readers never see a
+// partial or missing file while a lock is being replaced or refreshed.
+func (s *Store) write(l Lock) error {
+	b, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.Dir, ".lock-*.tmp")
+	if err != nil {
+		return fmt.Errorf("write lock: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write lock: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write lock: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(l.Resource)); err != nil {
+		return fmt.Errorf("write lock: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T, now *time.Time) *Store {
+	return &Store{Dir: t.TempDir(), Now: func() time.Time { return *now }}
+}
+
+func TestAcquire_ConflictsWithOtherOwner(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := newTestStore(t, &now)
+
+	_, err := s.Acquire("profile/p1", Options{TTL: time.Minute, Owner: "alice", Note: "browsers create"})
+	require.NoError(t, err)
+
+	_, err = s.Acquire("profile/p1", Options{TTL: time.Minute, Owner: "bob"})
+	var held *HeldError
+	require.True(t, errors.As(err, &held))
+	assert.Equal(t, "alice", held.Lock.Owner)
+	assert.Contains(t, err.Error(), "locked by alice")
+	assert.Contains(t, err.Error(), "browsers create")
+}
+
+func TestAcquire_SameOwnerRefreshes(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := newTestStore(t, &now)
+
+	first, err := s.Acquire("profile/p1", Options{TTL: time.Minute, Owner: "alice"})
+	require.NoError(t, err)
+	now = now.Add(30 * time.Second)
+	second, err := s.Acquire("profile/p1", Options{TTL: time.Minute, Owner: "alice", SessionID: "sess"})
+	require.NoError(t, err)
+	assert.True(t, first.AcquiredAt.Equal(second.AcquiredAt))
+	assert.Equal(t, now.Add(time.Minute), second.ExpiresAt)
+	assert.Equal(t, "sess", second.SessionID)
+}
+
+func TestAcquire_ExpiredLockCanBeTaken(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := newTestStore(t, &now)
+
+	_, err := s.Acquire("profile/p1", Options{TTL: time.Minute, Owner: "alice"})
+	require.NoError(t, err)
+	now = now.Add(2 * time.Minute)
+
+	got, err := s.Get("profile/p1")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	l, err := s.Acquire("profile/p1", Options{TTL: time.Minute, Owner: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, "bob", l.Owner)
+}
+
+func TestAcquire_ExpiredLockHasOneWinner(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := newTestStore(t, &now)
+	_, err := s.Acquire("profile/p1", Options{TTL: time.Minute, Owner: "alice"})
+	require.NoError(t, err)
+	now = now.Add(2 * time.Minute)
+
+	// Separate stores open the lock files independently, like separate
+	// processes do.
+	var (
+		wg      sync.WaitGroup
+		winners atomic.Int32
+	)
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			other := &Store{Dir: s.Dir, Now: s.Now}
+			_, err := other.Acquire("profile/p1", Options{TTL: time.Minute, Owner: fmt.Sprintf("owner-%d", i)})
+			var held *HeldError
+			if err == nil {
+				winners.Add(1)
+			} else if !errors.As(err, &held) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), winners.Load())
+}
+
+func TestRefresh(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := newTestStore(t, &now)
+	_, err := s.Acquire("deploy/app", Options{TTL: time.Minute, Owner: "run-1"})
+	require.NoError(t, err)
+
+	now = now.Add(30 * time.Second)
+	l, err := s.Refresh("deploy/app", "run-1", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Minute), l.ExpiresAt)
+	assert.True(t, l.AcquiredAt.Equal(time.Unix(1000, 0)))
+
+	// Once another run has taken it over, refreshing doesn't take it back.
+	now = now.Add(2 * time.Minute)
+	_, err = s.Acquire("deploy/app", Options{TTL: time.Minute, Owner: "run-2"})
+	require.NoError(t, err)
+	_, err = s.Refresh("deploy/app", "run-1", time.Minute)
+	var lost *LostError
+	require.ErrorAs(t, err, &lost)
+	assert.Equal(t, "run-2", lost.Holder.Owner)
+
+	require.NoError(t, s.Release("deploy/app", "run-2", false))
+	_, err = s.Refresh("deploy/app", "run-2", time.Minute)
+	assert.EqualError(t, err, "lost the lock on deploy/app: it was released")
+}
+
+func TestRelease(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := newTestStore(t, &now)
+
+	_, err := s.Acquire("profile/p1", Options{TTL: time.Minute, Owner: "alice"})
+	require.NoError(t, err)
+
+	var held *HeldError
+	require.True(t, errors.As(s.Release("profile/p1", "bob", false), &held))
+	require.NoError(t, s.Release("profile/p1", "bob", true))
+
+	got, err := s.Get("profile/p1")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	require.NoError(t, s.Release("profile/p1", "alice", false))
+}
+
+func TestReleaseSession(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := newTestStore(t, &now)
+
+	_, err := s.Acquire("profile/p1", Options{TTL: time.Minute, Owner: "alice", SessionID: "sess-1"})
+	require.NoError(t, err)
+	_, err = s.Acquire("profile/p2", Options{TTL: time.Minute, Owner: "alice", SessionID: "sess-2"})
+	require.NoError(t, err)
+
+	released, err := s.ReleaseSession("sess-1")
+	require.NoError(t, err)
+	require.Len(t, released, 1)
+	assert.Equal(t, "profile/p1", released[0].Resource)
+
+	locks, err := s.List()
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	assert.Equal(t, "profile/p2", locks[0].Resource)
+}
+
+func TestDefaultOwner_Env(t *testing.T) {
+	t.Setenv(OwnerEnvVar, "ci-job-42")
+	assert.Equal(t, "ci-job-42", DefaultOwner())
+}
+
+func TestProcessOwner(t *testing.T) {
+	t.Setenv(OwnerEnvVar, "")
+	assert.Equal(t, DefaultOwner()+"#"+strconv.Itoa(os.Getpid()), ProcessOwner())
+	assert.True(t, IsProcessOwner(ProcessOwner(), DefaultOwner()))
+	assert.False(t, IsProcessOwner(DefaultOwner(), DefaultOwner()))
+	assert.False(t, IsProcessOwner("alice@host#x", "alice@host"))
+
+	t.Setenv(OwnerEnvVar, "ci-job-42")
+	assert.Equal(t, "ci-job-42", ProcessOwner())
+}
+
+func TestAcquire_DistinctResourcesDontCollide(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+	_, err := s.Acquire("profile/a/b", Options{TTL: time.Minute, Owner: "alice"})
+	require.NoError(t, err)
+	_, err = s.Acquire("profile/a__b", Options{TTL: time.Minute, Owner: "bob"})
+	require.NoError(t, err)
+
+	locks, err := s.List()
+	require.NoError(t, err)
+	require.Len(t, locks, 2)
+	assert.Equal(t, "profile/a/b", locks[0].Resource)
+	assert.Equal(t, "profile/a__b", locks[1].Resource)
+}
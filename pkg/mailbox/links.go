@@ -0,0 +1,86 @@
+package mailbox
+
+import (
+	"bytes"
+	"encoding/base64"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// ExtractLink returns the first match of pattern in the decoded text and HTML
+// parts of a raw RFC 5322 message, or "" if there is none.
+func ExtractLink(raw []byte, pattern *regexp.Regexp) string {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return ""
+	}
+	for _, text := range textParts(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body) {
+		if m := pattern.FindString(html.UnescapeString(text)); m != "" {
+			// Loose patterns like \S+ run on into the surrounding markup or
+			// sentence; URLs never contain these characters unescaped.
+			if i := strings.IndexAny(m, `"'<> `); i >= 0 {
+				m = m[:i]
+			}
+			return strings.TrimRight(m, ").,;")
+		}
+	}
+	return ""
+}
+
+// textParts decodes the text/* leaves of a possibly multipart body.
+func textParts(contentType, encoding string, body io.Reader) []string {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		var out []string
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err != nil {
+				return out
+			}
+			out = append(out, textParts(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)...)
+		}
+	}
+	if !strings.HasPrefix(mediaType, "text/") {
+		return nil
+	}
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, newlineStripper{body})
+	}
+	data, err := io.ReadAll(body)
+	if err != nil && len(data) == 0 {
+		return nil
+	}
+	return []string{string(data)}
+}
+
+// newlineStripper drops the line breaks that wrap base64 bodies.
+type newlineStripper struct{ r io.Reader }
+
+func (n newlineStripper) Read(p []byte) (int, error) {
+	for {
+		c, err := n.r.Read(p)
+		j := 0
+		for _, b := range p[:c] {
+			if b != '\r' && b != '\n' {
+				p[j] = b
+				j++
+			}
+		}
+		if j > 0 || err != nil {
+			return j, err
+		}
+	}
+}
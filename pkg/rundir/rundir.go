@@ -0,0 +1,302 @@
+// Package rundir records runs of composite commands on disk. Each run gets
+// its own directory, <root>/runs/<run-id>/, holding a manifest.json that
+// describes the run and lists every file written next to it: the events the
+// command saw (events.jsonl), its log lines (logs.txt), result files and
+// screenshots. The conventional root is .kernel in the working directory.
+//
+// A nil *Run records nothing, so commands can call its methods whether or
+// not a run directory was requested.
+package rundir
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRoot is the run directory root used when none is given.
+const DefaultRoot = ".kernel"
+
+const (
+	ManifestFile = "manifest.json"
+	EventsFile   = "events.jsonl"
+	LogsFile     = "logs.txt"
+)
+
+// Run statuses recorded in the manifest.
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Manifest describes a run and the files it left behind.
+type Manifest struct {
+	RunID      string            `json:"run_id"`
+	Command    string            `json:"command"`
+	Args       []string          `json:"args"`
+	CLIVersion string            `json:"cli_version,omitempty"`
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt time.Time         `json:"finished_at,omitzero"`
+	Status     string            `json:"status"`
+	Error      string            `json:"error,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	Files      []File            `json:"files"`
+}
+
+// File is one entry in the manifest, with a path relative to the run
+// directory.
+type File struct {
+	Path        string `json:"path"`
+	Description string `json:"description"`
+}
+
+// Run is a run directory being written.
+type Run struct {
+	Dir string
+
+	mu       sync.Mutex
+	manifest Manifest
+	events   *os.File
+	logs     *os.File
+	now      func() time.Time
+}
+
+var slugUnsafe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Start creates a run directory under root for command and writes its
+// manifest with status "running", so a run that never finishes still leaves
+// a record.
+func Start(root, command string, args []string, cliVersion string) (*Run, error) {
+	return start(root, command, args, cliVersion, time.Now)
+}
+
+func start(root, command string, args []string, cliVersion string, now func() time.Time) (*Run, error) {
+	if root == "" {
+		root = DefaultRoot
+	}
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	started := now()
+	slug := strings.Trim(slugUnsafe.ReplaceAllString(strings.ToLower(command), "-"), "-")
+	id := fmt.Sprintf("%s-%s-%s", started.UTC().Format("20060102-150405"), slug, hex.EncodeToString(suffix))
+	dir := filepath.Join(root, "runs", id)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create run directory: %w", err)
+	}
+	if args == nil {
+		args = []string{}
+	}
+	r := &Run{
+		Dir: dir,
+		manifest: Manifest{
+			RunID:      id,
+			Command:    command,
+			Args:       args,
+			CLIVersion: cliVersion,
+			StartedAt:  started,
+			Status:     StatusRunning,
+			Files:      []File{},
+		},
+		now: now,
+	}
+	if err := r.writeManifest(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Set records a metadata value in the manifest, such as the ID of the
+// resource the run created. Later values replace earlier ones.
+func (r *Run) Set(key, value string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.manifest.Metadata == nil {
+		r.manifest.Metadata = map[string]string{}
+	}
+	r.manifest.Metadata[key] = value
+}
+
+// Event appends v to events.jsonl as one JSON line.
+func (r *Run) Event(v any) {
+	if r == nil {
+		return
+	}
+	b, err := marshal(v)
+	if err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); err != nil {
+		return
+	}
+	b = buf.Bytes()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.events == nil {
+		if r.events = r.open(EventsFile, "events, one JSON object per line"); r.events == nil {
+			return
+		}
+	}
+	_, _ = r.events.Write(append(b, '\n'))
+}
+
+// Logf appends a timestamped line to logs.txt.
+func (r *Run) Logf(format string, args ...any) {
+	if r == nil {
+		return
+	}
+	line := strings.TrimSuffix(fmt.Sprintf(format, args...), "\n")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.logs == nil {
+		if r.logs = r.open(LogsFile, "log lines"); r.logs == nil {
+			return
+		}
+	}
+	fmt.Fprintf(r.logs, "%s %s\n", r.now().UTC().Format(time.RFC3339Nano), line)
+}
+
+// WriteFile writes data to name in the run directory and lists it in the
+// manifest. Writing the same name again replaces the file.
+func (r *Run) WriteFile(name string, data []byte, description string) error {
+	if r == nil {
+		return nil
+	}
+	path := filepath.Join(r.Dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.addFile(name, description)
+	return nil
+}
+
+// WriteJSON writes v to name as indented JSON; see WriteFile.
+func (r *Run) WriteJSON(name string, v any, description string) error {
+	if r == nil {
+		return nil
+	}
+	b, err := marshal(v)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, b, "", "  "); err != nil {
+		return err
+	}
+	return r.WriteFile(name, append(buf.Bytes(), '\n'), description)
+}
+
+// Subdir creates a directory in the run directory for files the command
+// writes itself, such as screenshots, lists it in the manifest and returns
+// its path.
+func (r *Run) Subdir(name, description string) (string, error) {
+	if r == nil {
+		return "", nil
+	}
+	path := filepath.Join(r.Dir, name)
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return "", err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.addFile(name+"/", description)
+	return path, nil
+}
+
+// Finish records how the run ended, closes its files and rewrites the
+// manifest.
+func (r *Run) Finish(runErr error) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.manifest.FinishedAt = r.now()
+	r.manifest.Status = StatusSucceeded
+	if runErr != nil {
+		r.manifest.Status = StatusFailed
+		r.manifest.Error = runErr.Error()
+	}
+	for _, f := range []*os.File{r.events, r.logs} {
+		if f != nil {
+			_ = f.Close()
+		}
+	}
+	r.events, r.logs = nil, nil
+	return r.writeManifest()
+}
+
+// open opens name for appending and lists it in the manifest. Failures
+// return nil: a run record is best effort and never fails the command.
+func (r *Run) open(name, description string) *os.File {
+	f, err := os.OpenFile(filepath.Join(r.Dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil
+	}
+	r.addFile(name, description)
+	return f
+}
+
+func (r *Run) addFile(path, description string) {
+	for _, f := range r.manifest.Files {
+		if f.Path == path {
+			return
+		}
+	}
+	r.manifest.Files = append(r.manifest.Files, File{Path: path, Description: description})
+}
+
+func (r *Run) writeManifest() error {
+	b, err := json.MarshalIndent(r.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(r.Dir, ManifestFile+".tmp")
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write run manifest: %w", err)
+	}
+	return os.Rename(tmp, filepath.Join(r.Dir, ManifestFile))
+}
+
+// marshal encodes v, preferring the JSON an API response was decoded from
+// so fields the SDK doesn't know about are kept.
+func marshal(v any) ([]byte, error) {
+	if raw, ok := v.(interface{ RawJSON() string }); ok && raw.RawJSON() != "" {
+		return []byte(raw.RawJSON()), nil
+	}
+	return json.Marshal(v)
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying r, for code deep in a command that
+// records into the run.
+func NewContext(ctx context.Context, r *Run) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// FromContext returns the run carried by ctx, or nil.
+func FromContext(ctx context.Context) *Run {
+	r, _ := ctx.Value(contextKey{}).(*Run)
+	return r
+}
@@ -0,0 +1,85 @@
+package rundir
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readManifest(t *testing.T, r *Run) Manifest {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(r.Dir, ManifestFile))
+	require.NoError(t, err)
+	var m Manifest
+	require.NoError(t, json.Unmarshal(b, &m))
+	return m
+}
+
+func TestRunRecordsFilesAndOutcome(t *testing.T) {
+	root := t.TempDir()
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	r, err := start(root, "kernel invoke", []string{"app", "act"}, "1.2.3", func() time.Time { return now })
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "runs"), filepath.Dir(r.Dir))
+	assert.True(t, strings.HasPrefix(filepath.Base(r.Dir), "20260304-050607-kernel-invoke-"), r.Dir)
+
+	m := readManifest(t, r)
+	assert.Equal(t, StatusRunning, m.Status)
+	assert.Equal(t, []string{"app", "act"}, m.Args)
+	assert.True(t, m.FinishedAt.IsZero())
+
+	r.Event(map[string]string{"event": "log"})
+	r.Event(map[string]string{"event": "invocation_state"})
+	r.Logf("hello %s\n", "world")
+	r.Set("invocation_id", "inv_1")
+	require.NoError(t, r.WriteJSON("result.json", map[string]int{"n": 1}, "the result"))
+	require.NoError(t, r.WriteJSON("result.json", map[string]int{"n": 2}, "the result"))
+	shots, err := r.Subdir("screenshots", "screenshots")
+	require.NoError(t, err)
+	assert.DirExists(t, shots)
+
+	now = now.Add(time.Minute)
+	require.NoError(t, r.Finish(errors.New("invocation failed")))
+
+	m = readManifest(t, r)
+	assert.Equal(t, StatusFailed, m.Status)
+	assert.Equal(t, "invocation failed", m.Error)
+	assert.Equal(t, now, m.FinishedAt)
+	assert.Equal(t, map[string]string{"invocation_id": "inv_1"}, m.Metadata)
+	var paths []string
+	for _, f := range m.Files {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{EventsFile, LogsFile, "result.json", "screenshots/"}, paths)
+
+	events, err := os.ReadFile(filepath.Join(r.Dir, EventsFile))
+	require.NoError(t, err)
+	assert.Equal(t, "{\"event\":\"log\"}\n{\"event\":\"invocation_state\"}\n", string(events))
+	logs, err := os.ReadFile(filepath.Join(r.Dir, LogsFile))
+	require.NoError(t, err)
+	assert.Equal(t, "2026-03-04T05:06:07Z hello world\n", string(logs))
+	result, err := os.ReadFile(filepath.Join(r.Dir, "result.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"n":2}`, string(result))
+}
+
+func TestNilRunRecordsNothing(t *testing.T) {
+	r := FromContext(context.Background())
+	require.Nil(t, r)
+	r.Event("x")
+	r.Logf("x")
+	r.Set("k", "v")
+	assert.NoError(t, r.WriteJSON("x.json", 1, ""))
+	assert.NoError(t, r.Finish(nil))
+
+	real := &Run{Dir: t.TempDir()}
+	assert.Same(t, real, FromContext(NewContext(context.Background(), real)))
+}
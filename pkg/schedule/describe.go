@@ -0,0 +1,161 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronUnits names each field's unit in descriptions, in field order.
+var cronUnits = [5]string{"minute", "hour", "day-of-month", "month", "day-of-week"}
+
+// Describe returns the expression in English, such as "At minute 0 past
+// every 6th hour" for "0 */6 * * *". Times are wall-clock times in whatever
+// timezone the schedule is evaluated in.
+func (c Cron) Describe() string {
+	minute, hour, dom, month, dow := c.fields[0], c.fields[1], c.fields[2], c.fields[3], c.fields[4]
+
+	var b strings.Builder
+	if times, ok := clockTimes(minute, hour); ok {
+		b.WriteString("At " + joinList(times))
+	} else {
+		if minute == "*" {
+			b.WriteString("Every minute")
+		} else {
+			b.WriteString("At " + labeledField(minute, 0))
+		}
+		if hour != "*" {
+			b.WriteString(" past " + labeledField(hour, 1))
+		}
+	}
+
+	var days []string
+	if dom != "*" {
+		days = append(days, "on "+labeledField(dom, 2))
+	}
+	if dow != "*" {
+		days = append(days, "on "+describeField(dow, 4))
+	}
+	// See dayMatches: either day field is enough only when both are restricted.
+	sep := " and "
+	if c.domRestricted && c.dowRestricted {
+		sep = " or "
+	}
+	if len(days) > 0 {
+		b.WriteString(" " + strings.Join(days, sep))
+	}
+	if month != "*" {
+		b.WriteString(" in " + describeField(month, 3))
+	}
+	return b.String()
+}
+
+// clockTimes lists the times of day when minute and hour are both plain
+// values, as "09:00" rather than "at minute 0 past hour 9".
+func clockTimes(minute, hour string) ([]string, bool) {
+	ms, ok := plainValues(minute)
+	if !ok {
+		return nil, false
+	}
+	hs, ok := plainValues(hour)
+	if !ok || len(ms)*len(hs) > 6 {
+		return nil, false
+	}
+	var times []string
+	for _, h := range hs {
+		for _, m := range ms {
+			times = append(times, fmt.Sprintf("%02d:%02d", h, m))
+		}
+	}
+	return times, true
+}
+
+func plainValues(field string) ([]int, bool) {
+	var vs []int
+	for _, item := range strings.Split(field, ",") {
+		v, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, false
+		}
+		vs = append(vs, v)
+	}
+	return vs, true
+}
+
+// labeledField describes a minute, hour or day-of-month field, naming the
+// unit before plain values ("minute 0") but not before steps, which already
+// name it ("every 5th minute").
+func labeledField(field string, i int) string {
+	desc := describeField(field, i)
+	if strings.HasPrefix(desc, "every ") {
+		return desc
+	}
+	return cronUnits[i] + " " + desc
+}
+
+// describeField describes one field of a parsed expression, so its values
+// are already known to be valid.
+func describeField(field string, i int) string {
+	unit := cronUnits[i]
+	var items []string
+	for _, item := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		step, _ := strconv.Atoi(stepStr)
+		every := fmt.Sprintf("every %s %s", ordinal(step), unit)
+		switch {
+		case rng == "*" && hasStep:
+			items = append(items, every)
+		case rng == "*":
+			items = append(items, "every "+unit)
+		case strings.Contains(rng, "-"):
+			lo, hi, _ := strings.Cut(rng, "-")
+			desc := cronValueName(lo, i) + " through " + cronValueName(hi, i)
+			if hasStep {
+				desc = every + " from " + desc
+			}
+			items = append(items, desc)
+		case hasStep:
+			items = append(items, every+" from "+cronValueName(rng, i))
+		default:
+			items = append(items, cronValueName(rng, i))
+		}
+	}
+	return joinList(items)
+}
+
+// cronValueName names months and weekdays; other values read as numbers.
+func cronValueName(v string, i int) string {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return v
+	}
+	switch i {
+	case 3:
+		return time.Month(n).String()
+	case 4:
+		return time.Weekday(n % 7).String()
+	}
+	return v
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+func joinList(items []string) string {
+	if len(items) <= 1 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
@@ -0,0 +1,153 @@
+package schedule
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronNext(t *testing.T) {
+	// Friday 2026-01-02 10:07:30 UTC
+	from := time.Date(2026, 1, 2, 10, 7, 30, 0, time.UTC)
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/5 * * * *", time.Date(2026, 1, 2, 10, 10, 0, 0, time.UTC)},
+		{"* * * * *", time.Date(2026, 1, 2, 10, 8, 0, 0, time.UTC)},
+		{"0 9 * * *", time.Date(2026, 1, 3, 9, 0, 0, 0, time.UTC)},
+		{"30 8-17 * * 1-5", time.Date(2026, 1, 2, 10, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"15,45 */6 * * *", time.Date(2026, 1, 2, 12, 15, 0, 0, time.UTC)},
+		{"5/20 10 * * *", time.Date(2026, 1, 2, 10, 25, 0, 0, time.UTC)},
+		// Either day field matches when both are restricted
+		{"0 0 15 * 6", time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 1, 2, 11, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+			c, err := ParseCron(tc.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, c.Next(from))
+		})
+	}
+}
+
+func TestCronNext_Never(t *testing.T) {
+	c, err := ParseCron("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, c.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero())
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestCronDescribe(t *testing.T) {
+	cases := map[string]string{
+		"0 */6 * * *":     "At minute 0 past every 6th hour",
+		"*/5 * * * *":     "At every 5th minute",
+		"* * * * *":       "Every minute",
+		"0 9 * * *":       "At 09:00",
+		"30 8,20 * * *":   "At 08:30 and 20:30",
+		"30 8-17 * * 1-5": "At minute 30 past hour 8 through 17 on Monday through Friday",
+		"15,45 */6 * * *": "At minute 15 and 45 past every 6th hour",
+		"5/20 10 * * *":   "At every 20th minute from 5 past hour 10",
+		"0 0 15 * 6":      "At 00:00 on day-of-month 15 or on Saturday",
+		"0 0 */2 * 1":     "At 00:00 on every 2nd day-of-month and on Monday",
+		"0 12 1 1,7 *":    "At 12:00 on day-of-month 1 in January and July",
+		"0 0 * * 7":       "At 00:00 on Sunday",
+		"@hourly":         "At minute 0",
+	}
+	for expr, want := range cases {
+		c, err := ParseCron(expr)
+		require.NoError(t, err)
+		assert.Equal(t, want, c.Describe(), expr)
+	}
+}
+
+func newTestStore(t *testing.T, now *time.Time) *Store {
+	return &Store{Path: filepath.Join(t.TempDir(), "schedules.json"), Now: func() time.Time { return *now }}
+}
+
+func TestStore_AddListDelete(t *testing.T) {
+	now := time.Unix(1000, 0).UTC()
+	s := newTestStore(t, &now)
+
+	list, err := s.List()
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	a, err := s.Add(Schedule{Cron: "*/5 * * * *", App: "app", Action: "sync", Version: "latest"})
+	require.NoError(t, err)
+	assert.Regexp(t, `^sch_[0-9a-f]{12}$`, a.ID)
+	assert.Equal(t, now, a.CreatedAt)
+
+	now = now.Add(time.Second)
+	b, err := s.Add(Schedule{Cron: "@daily", App: "app", Action: "report", Version: "v2", Payload: `{"x":1}`})
+	require.NoError(t, err)
+
+	list, err = s.List()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, a.ID, list[0].ID)
+	assert.Equal(t, b.ID, list[1].ID)
+
+	got, err := s.Get(b.ID[:8])
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, `{"x":1}`, got.Payload)
+
+	removed, err := s.Delete(a.ID)
+	require.NoError(t, err)
+	require.NotNil(t, removed)
+	assert.Equal(t, "sync", removed.Action)
+
+	removed, err = s.Delete("sch_missing")
+	require.NoError(t, err)
+	assert.Nil(t, removed)
+
+	list, err = s.List()
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, b.ID, list[0].ID)
+}
+
+func TestStore_AddRejectsInvalidCron(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := newTestStore(t, &now)
+	_, err := s.Add(Schedule{Cron: "every minute", App: "app", Action: "sync"})
+	assert.ErrorContains(t, err, "invalid cron expression")
+}
+
+func TestStore_RecordRun(t *testing.T) {
+	now := time.Unix(1000, 0).UTC()
+	s := newTestStore(t, &now)
+	sched, err := s.Add(Schedule{Cron: "* * * * *", App: "app", Action: "sync"})
+	require.NoError(t, err)
+
+	at := now.Add(time.Minute)
+	require.NoError(t, s.RecordRun(sched.ID, at, "inv_1", nil))
+	got, err := s.Get(sched.ID)
+	require.NoError(t, err)
+	assert.Equal(t, at, got.LastRunAt)
+	assert.Equal(t, "inv_1", got.LastInvocationID)
+	assert.Empty(t, got.LastError)
+
+	require.NoError(t, s.RecordRun(sched.ID, at, "", assert.AnError))
+	got, err = s.Get(sched.ID)
+	require.NoError(t, err)
+	assert.Equal(t, assert.AnError.Error(), got.LastError)
+
+	// Deleted meanwhile: nothing to record
+	require.NoError(t, s.RecordRun("sch_gone", at, "inv_2", nil))
+}
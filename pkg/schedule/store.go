@@ -0,0 +1,205 @@
+// Package schedule stores recurring invocations in a JSON file under the CLI
+// config directory and works out when each is next due. Schedules only run
+// while "kernel schedules run" is running on this machine.
+package schedule
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+)
+
+// Schedule is one recurring invocation of an app action.
+type Schedule struct {
+	ID                  string    `json:"id"`
+	Cron                string    `json:"cron"`
+	App                 string    `json:"app"`
+	Action              string    `json:"action"`
+	Version             string    `json:"version"`
+	Payload             string    `json:"payload,omitempty"`
+	AsyncTimeoutSeconds int64     `json:"async_timeout_seconds,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+	LastRunAt           time.Time `json:"last_run_at,omitzero"`
+	LastInvocationID    string    `json:"last_invocation_id,omitempty"`
+	LastError           string    `json:"last_error,omitempty"`
+}
+
+// Next returns when the schedule is next due after t, or the zero time if
+// its cron expression never matches.
+func (s Schedule) Next(t time.Time) time.Time {
+	c, err := ParseCron(s.Cron)
+	if err != nil {
+		return time.Time{}
+	}
+	return c.Next(t)
+}
+
+// Store reads and writes the schedules file at Path.
+type Store struct {
+	Path string
+	Now  func() time.Time
+}
+
+// DefaultStore returns a store at ~/.config/kernel/schedules.json.
+func DefaultStore() (*Store, error) {
+	configDir, err := util.ConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return &Store{Path: filepath.Join(configDir, "schedules.json")}, nil
+}
+
+func (s *Store) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// List returns all schedules ordered by creation time.
+func (s *Store) List() ([]Schedule, error) {
+	b, err := os.ReadFile(s.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read schedules: %w", err)
+	}
+	var schedules []Schedule
+	if err := json.Unmarshal(b, &schedules); err != nil {
+		return nil, fmt.Errorf("parse schedules %s: %w", s.Path, err)
+	}
+	sort.SliceStable(schedules, func(i, j int) bool { return schedules[i].CreatedAt.Before(schedules[j].CreatedAt) })
+	return schedules, nil
+}
+
+// Get returns the schedule with id, or nil if there is none. A unique ID
+// prefix is accepted too.
+func (s *Store) Get(id string) (*Schedule, error) {
+	schedules, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	i, err := find(schedules, id)
+	if err != nil || i < 0 {
+		return nil, err
+	}
+	return &schedules[i], nil
+}
+
+// Add validates sched's cron expression, assigns it an ID and saves it.
+func (s *Store) Add(sched Schedule) (Schedule, error) {
+	if _, err := ParseCron(sched.Cron); err != nil {
+		return Schedule{}, err
+	}
+	if sched.App == "" || sched.Action == "" {
+		return Schedule{}, fmt.Errorf("a schedule needs an app and an action")
+	}
+	schedules, err := s.List()
+	if err != nil {
+		return Schedule{}, err
+	}
+	sched.ID = newID()
+	sched.CreatedAt = s.now()
+	schedules = append(schedules, sched)
+	return sched, s.write(schedules)
+}
+
+// Delete removes the schedule with id (or a unique ID prefix) and returns
+// it, or nil if there is none.
+func (s *Store) Delete(id string) (*Schedule, error) {
+	schedules, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	i, err := find(schedules, id)
+	if err != nil || i < 0 {
+		return nil, err
+	}
+	removed := schedules[i]
+	schedules = append(schedules[:i], schedules[i+1:]...)
+	return &removed, s.write(schedules)
+}
+
+// RecordRun stores the outcome of a run of the schedule with id. Runs of a
+// schedule deleted meanwhile are dropped.
+func (s *Store) RecordRun(id string, at time.Time, invocationID string, runErr error) error {
+	schedules, err := s.List()
+	if err != nil {
+		return err
+	}
+	for i := range schedules {
+		if schedules[i].ID != id {
+			continue
+		}
+		schedules[i].LastRunAt = at
+		schedules[i].LastInvocationID = invocationID
+		schedules[i].LastError = ""
+		if runErr != nil {
+			schedules[i].LastError = runErr.Error()
+		}
+		return s.write(schedules)
+	}
+	return nil
+}
+
+func find(schedules []Schedule, id string) (int, error) {
+	match := -1
+	for i, sched := range schedules {
+		if sched.ID == id {
+			return i, nil
+		}
+		if id != "" && strings.HasPrefix(sched.ID, id) {
+			if match >= 0 {
+				return -1, fmt.Errorf("schedule ID prefix %q is ambiguous", id)
+			}
+			match = i
+		}
+	}
+	return match, nil
+}
+
+// write replaces the file atomically so a concurrent reader never sees a
+// partial list.
+func (s *Store) write(schedules []Schedule) error {
+	if schedules == nil {
+		schedules = []Schedule{}
+	}
+	b, err := json.MarshalIndent(schedules, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o700); err != nil {
+		return fmt.Errorf("create schedules directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), ".schedules-*.json")
+	if err != nil {
+		return fmt.Errorf("write schedules: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write schedules: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write schedules: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.Path); err != nil {
+		return fmt.Errorf("write schedules: %w", err)
+	}
+	return nil
+}
+
+func newID() string {
+	var b [6]byte
+	_, _ = rand.Read(b[:])
+	return "sch_" + hex.EncodeToString(b[:])
+}
@@ -0,0 +1,209 @@
+package ssh
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kernel/cli/pkg/util"
+	"golang.org/x/crypto/ssh"
+)
+
+// StoredKey is a named keypair that can be reused across SSH connections.
+type StoredKey struct {
+	Name         string `json:"name"`
+	IdentityFile string `json:"identity_file"`
+	PublicKey    string `json:"public_key"`
+	Fingerprint  string `json:"fingerprint"`
+	// Generated keys live in the store directory and are deleted with the
+	// entry; added keys are only referenced.
+	Generated bool      `json:"generated"`
+	AddedAt   time.Time `json:"added_at"`
+}
+
+// ErrKeyNotFound is returned for names the store doesn't have.
+var ErrKeyNotFound = errors.New("ssh key not found")
+
+var keyNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// KeyStore keeps named SSH keys under Dir, indexed by keys.json.
+type KeyStore struct {
+	Dir string
+}
+
+// DefaultKeyStore returns a store rooted at ~/.config/kernel/ssh-keys.
+func DefaultKeyStore() (*KeyStore, error) {
+	configDir, err := util.ConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return &KeyStore{Dir: filepath.Join(configDir, "ssh-keys")}, nil
+}
+
+func (s *KeyStore) indexPath() string {
+	return filepath.Join(s.Dir, "keys.json")
+}
+
+func (s *KeyStore) load() (map[string]StoredKey, error) {
+	keys := map[string]StoredKey{}
+	data, err := os.ReadFile(s.indexPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return keys, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("invalid ssh key index %s: %w", s.indexPath(), err)
+	}
+	return keys, nil
+}
+
+func (s *KeyStore) save(keys map[string]StoredKey) error {
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.indexPath(), data, 0o600)
+}
+
+// Add registers an existing private key under name. The public key is read
+// from the identity file's .pub sibling, as 'kernel browsers ssh -i' does.
+func (s *KeyStore) Add(name, identityFile string) (*StoredKey, error) {
+	abs, err := filepath.Abs(identityFile)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(abs); err != nil {
+		return nil, fmt.Errorf("private key %s: %w", identityFile, err)
+	}
+	pub, err := os.ReadFile(abs + ".pub")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key %s.pub: %w", identityFile, err)
+	}
+	return s.put(name, abs, strings.TrimSpace(string(pub)), false)
+}
+
+// Generate creates a new ed25519 keypair in the store under name.
+func (s *KeyStore) Generate(name string) (*StoredKey, error) {
+	if !keyNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid key name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	// Check before writing so an existing key's files aren't overwritten.
+	if _, err := s.Get(name); err == nil {
+		return nil, fmt.Errorf("an ssh key named %q already exists", name)
+	} else if !errors.Is(err, ErrKeyNotFound) {
+		return nil, err
+	}
+	pair, err := GenerateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(s.Dir, name)
+	if err := os.WriteFile(path, []byte(pair.PrivateKeyPEM), 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write private key: %w", err)
+	}
+	if err := os.WriteFile(path+".pub", []byte(pair.PublicKeyOpenSSH+"\n"), 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write public key: %w", err)
+	}
+	key, err := s.put(name, path, pair.PublicKeyOpenSSH, true)
+	if err != nil {
+		os.Remove(path)
+		os.Remove(path + ".pub")
+	}
+	return key, err
+}
+
+func (s *KeyStore) put(name, identityFile, publicKey string, generated bool) (*StoredKey, error) {
+	if !keyNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid key name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	parsed, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	keys, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := keys[name]; exists {
+		return nil, fmt.Errorf("an ssh key named %q already exists", name)
+	}
+	key := StoredKey{
+		Name:         name,
+		IdentityFile: identityFile,
+		PublicKey:    publicKey,
+		Fingerprint:  ssh.FingerprintSHA256(parsed),
+		Generated:    generated,
+		AddedAt:      time.Now(),
+	}
+	keys[name] = key
+	if err := s.save(keys); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// Get returns the key stored under name.
+func (s *KeyStore) Get(name string) (*StoredKey, error) {
+	keys, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	key, ok := keys[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, name)
+	}
+	return &key, nil
+}
+
+// List returns the stored keys sorted by name.
+func (s *KeyStore) List() ([]StoredKey, error) {
+	keys, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]StoredKey, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, k)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// Remove forgets the key stored under name, deleting its files if the store
+// generated them.
+func (s *KeyStore) Remove(name string) error {
+	keys, err := s.load()
+	if err != nil {
+		return err
+	}
+	key, ok := keys[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, name)
+	}
+	delete(keys, name)
+	if err := s.save(keys); err != nil {
+		return err
+	}
+	if key.Generated {
+		for _, p := range []string{key.IdentityFile, key.IdentityFile + ".pub"} {
+			if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	return nil
+}
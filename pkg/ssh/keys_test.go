@@ -0,0 +1,60 @@
+package ssh
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyStore_GenerateListRemove(t *testing.T) {
+	s := &KeyStore{Dir: t.TempDir()}
+
+	key, err := s.Generate("team-debug")
+	require.NoError(t, err)
+	assert.True(t, key.Generated)
+	assert.Contains(t, key.Fingerprint, "SHA256:")
+	info, err := os.Stat(key.IdentityFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	_, err = s.Generate("team-debug")
+	assert.ErrorContains(t, err, "already exists")
+	_, err = s.Generate("../escape")
+	assert.ErrorContains(t, err, "invalid key name")
+
+	keys, err := s.List()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "team-debug", keys[0].Name)
+
+	require.NoError(t, s.Remove("team-debug"))
+	_, err = os.Stat(key.IdentityFile)
+	assert.True(t, os.IsNotExist(err))
+	_, err = s.Get("team-debug")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestKeyStore_AddKeepsExistingFiles(t *testing.T) {
+	dir := t.TempDir()
+	pair, err := GenerateKeyPair()
+	require.NoError(t, err)
+	path := filepath.Join(dir, "id_ed25519")
+	require.NoError(t, os.WriteFile(path, []byte(pair.PrivateKeyPEM), 0o600))
+	require.NoError(t, os.WriteFile(path+".pub", []byte(pair.PublicKeyOpenSSH+"\n"), 0o644))
+
+	s := &KeyStore{Dir: filepath.Join(dir, "store")}
+	key, err := s.Add("laptop", path)
+	require.NoError(t, err)
+	assert.False(t, key.Generated)
+	assert.Equal(t, path, key.IdentityFile)
+
+	require.NoError(t, s.Remove("laptop"))
+	_, err = os.Stat(path)
+	assert.NoError(t, err, "added keys are only referenced, not deleted")
+
+	_, err = s.Add("missing", filepath.Join(dir, "nope"))
+	assert.ErrorContains(t, err, "private key")
+}
@@ -113,13 +113,34 @@ func IsVeryOldVersion(current, latest string) (bool, error) {
 // It expects that the GitHub API returns releases in descending chronological order
 // (newest first), which is standard behavior.
 func FetchLatest(ctx context.Context) (tag string, url string, err error) {
-	apiURL := os.Getenv("KERNEL_RELEASES_URL")
-	if apiURL == "" {
-		apiURL = defaultReleasesAPI
+	var releases []Release
+	if err := getReleasesJSON(ctx, releasesAPIURL(), &releases); err != nil {
+		return "", "", err
 	}
+	for _, r := range releases {
+		if r.Draft || r.Prerelease {
+			continue
+		}
+		if r.TagName == "" {
+			continue
+		}
+		return r.TagName, r.HTMLURL, nil
+	}
+	return "", "", errors.New("no stable releases found")
+}
+
+func releasesAPIURL() string {
+	if apiURL := os.Getenv("KERNEL_RELEASES_URL"); apiURL != "" {
+		return apiURL
+	}
+	return defaultReleasesAPI
+}
+
+// getReleasesJSON GETs a GitHub Releases API URL and decodes the response into v.
+func getReleasesJSON(ctx context.Context, apiURL string, v any) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
 	if err != nil {
-		return "", "", err
+		return err
 	}
 	req.Header.Set("Accept", "application/vnd.github+json")
 	req.Header.Set("User-Agent", userAgent)
@@ -129,32 +150,13 @@ func FetchLatest(ctx context.Context) (tag string, url string, err error) {
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", "", err
+		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return "", "", fmt.Errorf("unexpected status: %s", resp.Status)
-	}
-
-	var releases []struct {
-		TagName    string `json:"tag_name"`
-		HTMLURL    string `json:"html_url"`
-		Draft      bool   `json:"draft"`
-		Prerelease bool   `json:"prerelease"`
+		return fmt.Errorf("unexpected status: %s", resp.Status)
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
-		return "", "", err
-	}
-	for _, r := range releases {
-		if r.Draft || r.Prerelease {
-			continue
-		}
-		if r.TagName == "" {
-			continue
-		}
-		return r.TagName, r.HTMLURL, nil
-	}
-	return "", "", errors.New("no stable releases found")
+	return json.NewDecoder(resp.Body).Decode(v)
 }
 
 // isOnOldBrewTap checks if the user has kernel installed from onkernel/tap
@@ -0,0 +1,146 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	projectName = "kernel"
+	// maxAssetBytes bounds release asset downloads.
+	maxAssetBytes = 256 << 20
+)
+
+// Release is a GitHub release and its downloadable assets.
+type Release struct {
+	TagName    string         `json:"tag_name"`
+	HTMLURL    string         `json:"html_url"`
+	Draft      bool           `json:"draft"`
+	Prerelease bool           `json:"prerelease"`
+	Assets     []ReleaseAsset `json:"assets"`
+}
+
+type ReleaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// FetchRelease returns the release tagged tag (e.g. "v0.12.0").
+func FetchRelease(ctx context.Context, tag string) (*Release, error) {
+	var r Release
+	if err := getReleasesJSON(ctx, strings.TrimRight(releasesAPIURL(), "/")+"/tags/"+tag, &r); err != nil {
+		return nil, fmt.Errorf("fetch release %s: %w", tag, err)
+	}
+	return &r, nil
+}
+
+// Asset returns the release asset called name.
+func (r *Release) Asset(name string) (ReleaseAsset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return ReleaseAsset{}, false
+}
+
+// downloadAsset reads a release asset into memory.
+func downloadAsset(ctx context.Context, a ReleaseAsset) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", a.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download %s: unexpected status: %s", a.Name, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAssetBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", a.Name, err)
+	}
+	if len(data) > maxAssetBytes {
+		return nil, fmt.Errorf("download %s: larger than %d bytes", a.Name, maxAssetBytes)
+	}
+	return data, nil
+}
+
+// Platform identifies which release archive fits this machine.
+type Platform struct {
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+	// Musl is set on Linux systems whose C library is musl (e.g. Alpine).
+	Musl bool `json:"musl,omitempty"`
+}
+
+func (p Platform) String() string {
+	s := p.OS + "/" + p.Arch
+	if p.Musl {
+		s += " (musl)"
+	}
+	return s
+}
+
+// DetectPlatform reports the machine's platform. That's not always the one
+// this binary was built for: an amd64 build running under Rosetta on Apple
+// silicon reports arm64, so upgrades pick the native build.
+func DetectPlatform() Platform {
+	return detectPlatform(runtime.GOOS, runtime.GOARCH, runningUnderRosetta, hasMuslLibc)
+}
+
+func detectPlatform(goos, goarch string, rosetta, musl func() bool) Platform {
+	p := Platform{OS: goos, Arch: goarch}
+	if goos == "darwin" && goarch == "amd64" && rosetta() {
+		p.Arch = "arm64"
+	}
+	if goos == "linux" {
+		p.Musl = musl()
+	}
+	return p
+}
+
+func runningUnderRosetta() bool {
+	out, err := exec.Command("sysctl", "-n", "sysctl.proc_translated").Output()
+	return err == nil && strings.TrimSpace(string(out)) == "1"
+}
+
+func hasMuslLibc() bool {
+	if matches, _ := filepath.Glob("/lib/ld-musl-*.so.1"); len(matches) > 0 {
+		return true
+	}
+	_, err := os.Stat("/etc/alpine-release")
+	return err == nil
+}
+
+// ArchiveName is the release archive for version on p, following the
+// archives name_template in .goreleaser.yaml. Builds are static, so the same
+// archive serves glibc and musl systems.
+func ArchiveName(version string, p Platform) string {
+	return fmt.Sprintf("%s_%s_%s_%s.tar.gz", projectName, normalizeSemver(version), p.OS, p.Arch)
+}
+
+// archiveCandidates lists the archives that fit p, preferred first: a
+// musl-specific build if the release has one, then the standard archive.
+func archiveCandidates(version string, p Platform) []string {
+	name := ArchiveName(version, p)
+	if !p.Musl {
+		return []string{name}
+	}
+	return []string{strings.TrimSuffix(name, ".tar.gz") + "_musl.tar.gz", name}
+}
+
+// checksumsName is goreleaser's default checksum file for version.
+func checksumsName(version string) string {
+	return fmt.Sprintf("%s_%s_checksums.txt", projectName, normalizeSemver(version))
+}
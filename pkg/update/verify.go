@@ -0,0 +1,343 @@
+package update
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// Public keys for release signatures, set at build time, e.g.
+//
+//	-ldflags "-X github.com/kernel/cli/pkg/update.MinisignPublicKey=RWQ..."
+//
+// KERNEL_MINISIGN_PUBKEY and KERNEL_COSIGN_PUBKEY override them. A signature
+// is only checked when both its key and its asset are available.
+var (
+	// MinisignPublicKey is a minisign public key, bare or as the .pub file.
+	MinisignPublicKey string
+	// CosignPublicKey is a PEM ECDSA key used with "cosign sign-blob --key".
+	CosignPublicKey string
+)
+
+// CheckStatus is the outcome of one verification step.
+type CheckStatus string
+
+const (
+	CheckPassed  CheckStatus = "passed"
+	CheckFailed  CheckStatus = "failed"
+	CheckSkipped CheckStatus = "skipped"
+)
+
+// Overall outcomes of a verification, in VerifyReport.Status.
+const (
+	StatusVerified    = "verified"
+	StatusUnsigned    = "unverified (no signature)"
+	StatusFailedCheck = "failed"
+)
+
+type Check struct {
+	Name   string      `json:"name"`
+	Status CheckStatus `json:"status"`
+	Detail string      `json:"detail,omitempty"`
+}
+
+// VerifyReport describes how an installed binary compares to its release.
+type VerifyReport struct {
+	Version  string   `json:"version"`
+	Platform Platform `json:"platform"`
+	Binary   string   `json:"binary"`
+	Archive  string   `json:"archive,omitempty"`
+	Checks   []Check  `json:"checks"`
+	// Status is StatusVerified, StatusUnsigned when every check that ran
+	// passed but no signature could be checked, or StatusFailedCheck.
+	Status string `json:"status"`
+	// Verified is set when no check failed and a signature check passed.
+	// Without a signature, a matching checksum only shows the archive
+	// matches a checksum file from the same place.
+	Verified bool `json:"verified"`
+}
+
+func (r *VerifyReport) add(name string, status CheckStatus, detail string, args ...any) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: status, Detail: fmt.Sprintf(detail, args...)})
+}
+
+// VerifyInstalled checks binaryPath against the published release for
+// version: the checksum file's signatures, the archive's checksum, and that
+// the binary inside the archive is byte-for-byte the installed one. Failed
+// checks are reported rather than returned; an error means verification
+// couldn't run.
+func VerifyInstalled(ctx context.Context, version, binaryPath string) (*VerifyReport, error) {
+	if !isSemverLike(version) {
+		return nil, fmt.Errorf("%q isn't a release version; only released builds can be verified", version)
+	}
+	report := &VerifyReport{Version: normalizeSemver(version), Platform: installedPlatform(), Binary: binaryPath}
+	installed, err := os.ReadFile(binaryPath)
+	if err != nil {
+		return nil, fmt.Errorf("read installed binary: %w", err)
+	}
+
+	release, err := FetchRelease(ctx, "v"+report.Version)
+	if err != nil {
+		return nil, err
+	}
+	checksumsAsset, ok := release.Asset(checksumsName(version))
+	if !ok {
+		return nil, fmt.Errorf("release v%s has no %s", report.Version, checksumsName(version))
+	}
+	checksums, err := downloadAsset(ctx, checksumsAsset)
+	if err != nil {
+		return nil, err
+	}
+
+	verifyChecksumSignature(ctx, report, release, checksumsAsset.Name, checksums, "minisign", ".minisig",
+		firstNonEmpty(os.Getenv("KERNEL_MINISIGN_PUBKEY"), MinisignPublicKey), verifyMinisign)
+	verifyChecksumSignature(ctx, report, release, checksumsAsset.Name, checksums, "cosign", ".sig",
+		firstNonEmpty(os.Getenv("KERNEL_COSIGN_PUBKEY"), CosignPublicKey), verifyCosignBlob)
+
+	var archive ReleaseAsset
+	for _, name := range archiveCandidates(version, report.Platform) {
+		if archive, ok = release.Asset(name); ok {
+			break
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("release v%s has no archive for %s", report.Version, report.Platform)
+	}
+	report.Archive = archive.Name
+	archiveData, err := downloadAsset(ctx, archive)
+	if err != nil {
+		return nil, err
+	}
+
+	want, ok := parseChecksums(checksums)[archive.Name]
+	switch got := sha256Hex(archiveData); {
+	case !ok:
+		report.add("archive checksum", CheckFailed, "%s isn't listed in %s", archive.Name, checksumsAsset.Name)
+	case got != want:
+		report.add("archive checksum", CheckFailed, "sha256 %s, expected %s", got, want)
+	default:
+		report.add("archive checksum", CheckPassed, "sha256 %s", got)
+	}
+
+	binaryName := projectName
+	if report.Platform.OS == "windows" {
+		binaryName += ".exe"
+	}
+	released, err := extractFromTarGz(archiveData, binaryName)
+	switch {
+	case err != nil:
+		report.add("installed binary", CheckFailed, "%v", err)
+	case !bytes.Equal(installed, released):
+		report.add("installed binary", CheckFailed, "sha256 %s doesn't match %s in %s (sha256 %s)", sha256Hex(installed), binaryName, archive.Name, sha256Hex(released))
+	default:
+		report.add("installed binary", CheckPassed, "sha256 %s", sha256Hex(installed))
+	}
+
+	failed, signed := false, false
+	for _, c := range report.Checks {
+		switch {
+		case c.Status == CheckFailed:
+			failed = true
+		case c.Status == CheckPassed && strings.HasSuffix(c.Name, " signature"):
+			signed = true
+		}
+	}
+	switch {
+	case failed:
+		report.Status = StatusFailedCheck
+	case !signed:
+		report.Status = StatusUnsigned
+	default:
+		report.Status = StatusVerified
+		report.Verified = true
+	}
+	return report, nil
+}
+
+// installedPlatform is the platform this binary was built for, whose archive
+// it is compared with. Unlike DetectPlatform it ignores Rosetta, so an amd64
+// build on Apple silicon is checked against the amd64 archive.
+func installedPlatform() Platform {
+	return detectPlatform(runtime.GOOS, runtime.GOARCH, func() bool { return false }, hasMuslLibc)
+}
+
+// verifyChecksumSignature checks the checksum file against its <suffix>
+// signature asset with verify, skipping when there's no key or signature.
+func verifyChecksumSignature(ctx context.Context, report *VerifyReport, release *Release, checksumsName string, checksums []byte, name, suffix, key string, verify func(key string, data, sig []byte) error) {
+	check := name + " signature"
+	if key == "" {
+		report.add(check, CheckSkipped, "no %s public key configured", name)
+		return
+	}
+	sigAsset, ok := release.Asset(checksumsName + suffix)
+	if !ok {
+		report.add(check, CheckSkipped, "release has no %s%s", checksumsName, suffix)
+		return
+	}
+	sig, err := downloadAsset(ctx, sigAsset)
+	if err != nil {
+		report.add(check, CheckFailed, "%v", err)
+		return
+	}
+	if err := verify(key, checksums, sig); err != nil {
+		report.add(check, CheckFailed, "%v", err)
+		return
+	}
+	report.add(check, CheckPassed, "%s signed by the configured key", checksumsName)
+}
+
+// verifyMinisign checks a minisign signature, including its trusted comment.
+// Both legacy ("Ed") and prehashed ("ED") signatures are accepted.
+func verifyMinisign(publicKey string, data, sigFile []byte) error {
+	pk, err := decodeMinisignBlob(publicKey, 2+8+ed25519.PublicKeySize)
+	if err != nil {
+		return fmt.Errorf("invalid minisign public key: %w", err)
+	}
+	if string(pk[:2]) != "Ed" {
+		return errors.New("invalid minisign public key: unsupported algorithm")
+	}
+	lines := strings.Split(strings.ReplaceAll(string(sigFile), "\r\n", "\n"), "\n")
+	if len(lines) < 4 {
+		return errors.New("malformed minisign signature")
+	}
+	sig, err := decodeMinisignBlob(lines[1], 2+8+ed25519.SignatureSize)
+	if err != nil {
+		return fmt.Errorf("malformed minisign signature: %w", err)
+	}
+	if !bytes.Equal(sig[2:10], pk[2:10]) {
+		return errors.New("signed with a different minisign key")
+	}
+	message := data
+	switch string(sig[:2]) {
+	case "Ed":
+	case "ED":
+		sum := blake2b.Sum512(data)
+		message = sum[:]
+	default:
+		return errors.New("malformed minisign signature: unsupported algorithm")
+	}
+	key := ed25519.PublicKey(pk[10:])
+	if !ed25519.Verify(key, message, sig[10:]) {
+		return errors.New("minisign signature doesn't match")
+	}
+	trusted, ok := strings.CutPrefix(lines[2], "trusted comment: ")
+	if !ok {
+		return errors.New("malformed minisign signature: missing trusted comment")
+	}
+	global, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(global) != ed25519.SignatureSize {
+		return errors.New("malformed minisign signature: bad trusted comment signature")
+	}
+	if !ed25519.Verify(key, append(bytes.Clone(sig[10:]), trusted...), global) {
+		return errors.New("minisign trusted comment signature doesn't match")
+	}
+	return nil
+}
+
+// decodeMinisignBlob decodes a base64 key or signature line of size bytes.
+// Keys may be given as a whole .pub file; its last line is the key.
+func decodeMinisignBlob(s string, size int) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndex(s, "\n"); i >= 0 {
+		s = strings.TrimSpace(s[i+1:])
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != size {
+		return nil, fmt.Errorf("expected %d bytes, got %d", size, len(b))
+	}
+	return b, nil
+}
+
+// verifyCosignBlob checks a "cosign sign-blob --key" signature: a base64
+// ASN.1 ECDSA signature over the SHA-256 of data.
+func verifyCosignBlob(publicKey string, data, sigFile []byte) error {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil {
+		return errors.New("invalid cosign public key: not PEM")
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("invalid cosign public key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return errors.New("invalid cosign public key: only ECDSA keys are supported")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigFile)))
+	if err != nil {
+		return fmt.Errorf("malformed cosign signature: %w", err)
+	}
+	digest := sha256.Sum256(data)
+	if !ecdsa.VerifyASN1(key, digest[:], sig) {
+		return errors.New("cosign signature doesn't match")
+	}
+	return nil
+}
+
+// parseChecksums reads a "<sha256>  <file>" checksum file.
+func parseChecksums(data []byte) map[string]string {
+	sums := map[string]string{}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 2 {
+			sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+		}
+	}
+	return sums
+}
+
+func extractFromTarGz(archive []byte, name string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("read archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%s not found in archive", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read archive: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeReg && path.Base(hdr.Name) == name {
+			return io.ReadAll(io.LimitReader(tr, maxAssetBytes))
+		}
+	}
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,181 @@
+package update
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+)
+
+// minisignSign produces a .pub file and a prehashed .minisig for data, as
+// "minisign -S" does.
+func minisignSign(t *testing.T, data []byte) (pub string, sig []byte) {
+	t.Helper()
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keyID := []byte("kernelid")
+	pub = "untrusted comment: minisign public key\n" + base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), pk...))
+
+	sum := blake2b.Sum512(data)
+	signature := ed25519.Sign(sk, sum[:])
+	trusted := "timestamp:1700000000\tfile:checksums.txt\thashed"
+	global := ed25519.Sign(sk, append(bytes.Clone(signature), trusted...))
+	sig = fmt.Appendf(nil, "untrusted comment: signature\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(append(append([]byte("ED"), keyID...), signature...)),
+		trusted, base64.StdEncoding.EncodeToString(global))
+	return pub, sig
+}
+
+func cosignSign(t *testing.T, data []byte) (pub string, sig []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	digest := sha256.Sum256(data)
+	raw, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), []byte(base64.StdEncoding.EncodeToString(raw))
+}
+
+func TestVerifyMinisign(t *testing.T) {
+	data := []byte("abc  kernel_1.0.0_linux_amd64.tar.gz\n")
+	pub, sig := minisignSign(t, data)
+	require.NoError(t, verifyMinisign(pub, data, sig))
+	assert.ErrorContains(t, verifyMinisign(pub, []byte("tampered"), sig), "doesn't match")
+
+	otherPub, _ := minisignSign(t, data)
+	assert.Error(t, verifyMinisign(otherPub, data, sig))
+}
+
+func TestVerifyCosignBlob(t *testing.T) {
+	data := []byte("checksums")
+	pub, sig := cosignSign(t, data)
+	require.NoError(t, verifyCosignBlob(pub, data, sig))
+	assert.ErrorContains(t, verifyCosignBlob(pub, []byte("tampered"), sig), "doesn't match")
+}
+
+func TestDetectPlatform(t *testing.T) {
+	yes := func() bool { return true }
+	no := func() bool { return false }
+	assert.Equal(t, Platform{OS: "darwin", Arch: "arm64"}, detectPlatform("darwin", "amd64", yes, no), "Rosetta reports the native arch")
+	assert.Equal(t, Platform{OS: "darwin", Arch: "amd64"}, detectPlatform("darwin", "amd64", no, no))
+	assert.Equal(t, Platform{OS: "linux", Arch: "arm64", Musl: true}, detectPlatform("linux", "arm64", yes, yes))
+
+	assert.Equal(t, []string{"kernel_1.2.3_linux_arm64_musl.tar.gz", "kernel_1.2.3_linux_arm64.tar.gz"},
+		archiveCandidates("v1.2.3", Platform{OS: "linux", Arch: "arm64", Musl: true}))
+	assert.Equal(t, []string{"kernel_1.2.3_darwin_amd64.tar.gz"}, archiveCandidates("1.2.3", Platform{OS: "darwin", Arch: "amd64"}))
+}
+
+func tarGz(t *testing.T, name string, content []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+	_, err := tw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestVerifyInstalled(t *testing.T) {
+	binary := []byte("kernel binary")
+	p := installedPlatform()
+	assert.Equal(t, runtime.GOARCH, p.Arch)
+	binaryName := "kernel"
+	if p.OS == "windows" {
+		binaryName += ".exe"
+	}
+	archiveName := ArchiveName("1.0.0", p)
+	archive := tarGz(t, binaryName, binary)
+	checksums := fmt.Appendf(nil, "%s  %s\n", sha256Hex(archive), archiveName)
+	minisignPub, minisig := minisignSign(t, checksums)
+
+	assets := map[string][]byte{
+		archiveName:                          archive,
+		"kernel_1.0.0_checksums.txt":         checksums,
+		"kernel_1.0.0_checksums.txt.minisig": minisig,
+	}
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	mux.HandleFunc("/releases/tags/v1.0.0", func(w http.ResponseWriter, r *http.Request) {
+		rel := Release{TagName: "v1.0.0"}
+		for name := range assets {
+			rel.Assets = append(rel.Assets, ReleaseAsset{Name: name, URL: srv.URL + "/download/" + name})
+		}
+		_ = json.NewEncoder(w).Encode(rel)
+	})
+	mux.HandleFunc("/download/{name}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(assets[r.PathValue("name")])
+	})
+	t.Setenv("KERNEL_RELEASES_URL", srv.URL+"/releases")
+	t.Setenv("KERNEL_MINISIGN_PUBKEY", minisignPub)
+	t.Setenv("KERNEL_COSIGN_PUBKEY", "")
+
+	installed := filepath.Join(t.TempDir(), binaryName)
+	require.NoError(t, os.WriteFile(installed, binary, 0o755))
+
+	report, err := VerifyInstalled(context.Background(), "v1.0.0", installed)
+	require.NoError(t, err)
+	assert.True(t, report.Verified)
+	assert.Equal(t, StatusVerified, report.Status)
+	assert.Equal(t, archiveName, report.Archive)
+	statuses := map[string]CheckStatus{}
+	for _, c := range report.Checks {
+		statuses[c.Name] = c.Status
+	}
+	assert.Equal(t, map[string]CheckStatus{
+		"minisign signature": CheckPassed,
+		"cosign signature":   CheckSkipped,
+		"archive checksum":   CheckPassed,
+		"installed binary":   CheckPassed,
+	}, statuses)
+
+	// A modified binary fails
+	require.NoError(t, os.WriteFile(installed, []byte("patched"), 0o755))
+	report, err = VerifyInstalled(context.Background(), "v1.0.0", installed)
+	require.NoError(t, err)
+	assert.False(t, report.Verified)
+
+	// So does a checksum file signed by someone else
+	require.NoError(t, os.WriteFile(installed, binary, 0o755))
+	otherPub, _ := minisignSign(t, checksums)
+	t.Setenv("KERNEL_MINISIGN_PUBKEY", otherPub)
+	report, err = VerifyInstalled(context.Background(), "v1.0.0", installed)
+	require.NoError(t, err)
+	assert.False(t, report.Verified)
+	assert.Equal(t, StatusFailedCheck, report.Status)
+
+	// Matching checksums alone don't make a release verified
+	t.Setenv("KERNEL_MINISIGN_PUBKEY", "")
+	report, err = VerifyInstalled(context.Background(), "v1.0.0", installed)
+	require.NoError(t, err)
+	assert.False(t, report.Verified)
+	assert.Equal(t, StatusUnsigned, report.Status)
+
+	_, err = VerifyInstalled(context.Background(), "dev", installed)
+	assert.ErrorContains(t, err, "release version")
+}
@@ -7,7 +7,6 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"strings"
 	"sync/atomic"
 
 	"github.com/kernel/cli/pkg/update"
@@ -85,13 +84,13 @@ func showUpgradeMessage() {
 	}
 }
 
-// GetBaseURL returns the Kernel API base URL, falling back to production.
-// KERNEL_BASE_URL is never set in .env; it exists solely for internal dev/staging overrides.
+const defaultBaseURL = "https://api.onkernel.com"
+
+// GetBaseURL returns the primary Kernel API base URL, falling back to production.
+// KERNEL_BASE_URL is never set in .env; it exists solely for internal dev/staging
+// overrides and regional failover lists (see GetBaseURLs).
 func GetBaseURL() string {
-	if u := os.Getenv("KERNEL_BASE_URL"); strings.TrimSpace(u) != "" {
-		return u
-	}
-	return "https://api.onkernel.com"
+	return GetBaseURLs()[0]
 }
 
 // IsNotFound returns true if the error is a Kernel API error with HTTP 404.
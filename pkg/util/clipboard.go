@@ -0,0 +1,90 @@
+package util
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrNoClipboard is returned by CopyToClipboard when no clipboard tool is
+// available and there is no terminal to ask instead.
+var ErrNoClipboard = errors.New("no clipboard tool found (install wl-clipboard, xclip or xsel)")
+
+// CopyToClipboard places text on the system clipboard using the platform's
+// clipboard tool. Over SSH, or when there is no tool, it asks the terminal
+// to copy it with an OSC 52 escape sequence instead, which reaches the
+// clipboard of the machine the user is sitting at in terminals that support
+// it.
+func CopyToClipboard(text string) error {
+	if os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != "" {
+		if err := copyWithOSC52(text); err == nil {
+			return nil
+		}
+	}
+	err := copyWithTool(text)
+	if errors.Is(err, ErrNoClipboard) {
+		if oscErr := copyWithOSC52(text); oscErr == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// copyWithOSC52 writes the OSC 52 sequence for text to the controlling
+// terminal.
+func copyWithOSC52(text string) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return ErrNoClipboard
+	}
+	defer tty.Close()
+	return writeOSC52(tty, text, os.Getenv("TMUX") != "")
+}
+
+// writeOSC52 writes an OSC 52 "set clipboard" sequence for text to w. In
+// tmux the sequence is wrapped in a passthrough so it reaches the outer
+// terminal.
+func writeOSC52(w io.Writer, text string, tmux bool) error {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if tmux {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	_, err := io.WriteString(w, seq)
+	return err
+}
+
+func copyWithTool(text string) error {
+	var candidates [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = [][]string{{"pbcopy"}}
+	case "windows":
+		candidates = [][]string{{"clip"}}
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, []string{"wl-copy"})
+		}
+		candidates = append(candidates,
+			[]string{"xclip", "-selection", "clipboard"},
+			[]string{"xsel", "--clipboard", "--input"},
+		)
+	}
+
+	for _, args := range candidates {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+	return ErrNoClipboard
+}
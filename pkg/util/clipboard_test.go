@@ -0,0 +1,19 @@
+package util
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteOSC52(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeOSC52(&buf, "https://example.com", false))
+	assert.Equal(t, "\x1b]52;c;aHR0cHM6Ly9leGFtcGxlLmNvbQ==\a", buf.String())
+
+	buf.Reset()
+	require.NoError(t, writeOSC52(&buf, "id", true))
+	assert.Equal(t, "\x1bPtmux;\x1b\x1b]52;c;aWQ=\a\x1b\\", buf.String())
+}
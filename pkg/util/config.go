@@ -0,0 +1,40 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ConfigDir returns the CLI configuration directory (~/.config/kernel),
+// creating it with owner-only permissions if needed.
+func ConfigDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	configDir := filepath.Join(homeDir, ".config", "kernel")
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return "", err
+	}
+	return configDir, nil
+}
+
+// CacheDir returns the CLI cache directory ($XDG_CACHE_HOME/kernel, or
+// ~/.cache/kernel), creating it if needed.
+func CacheDir() (string, error) {
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(homeDir, ".cache")
+	}
+
+	cacheDir := filepath.Join(base, "kernel")
+	if err := os.MkdirAll(cacheDir, 0700); err != nil {
+		return "", err
+	}
+	return cacheDir, nil
+}
@@ -2,6 +2,8 @@ package util
 
 import (
 	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
@@ -133,3 +135,86 @@ func ConvertPEMToJWK(pemData string) (string, error) {
 
 	return string(jwkJSON), nil
 }
+
+// PublicJWK is the public half of an Ed25519 JWK. Kid is its RFC 7638
+// thumbprint, the keyid web-bot-auth puts in signatures.
+type PublicJWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Kid string `json:"kid"`
+}
+
+// JWKS is a JSON Web Key Set of public keys, as served to origins.
+type JWKS struct {
+	Keys []PublicJWK `json:"keys"`
+}
+
+// GenerateEd25519PEM generates a new Ed25519 private key in PKCS#8 PEM format
+func GenerateEd25519PEM() ([]byte, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	pkcs8Bytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal PKCS#8: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8Bytes}), nil
+}
+
+// ToPublicJWK returns the public half of an Ed25519 JWK (private or public)
+// with its thumbprint as the kid
+func ToPublicJWK(jwkJSON string) (PublicJWK, error) {
+	var key jwkKey
+	if err := json.Unmarshal([]byte(jwkJSON), &key); err != nil {
+		return PublicJWK{}, fmt.Errorf("failed to parse JWK: %w", err)
+	}
+	if key.Kty != "OKP" || key.Crv != "Ed25519" {
+		return PublicJWK{}, fmt.Errorf("invalid key type: expected OKP/Ed25519, got %s/%s", key.Kty, key.Crv)
+	}
+	publicKeyBytes, err := base64.RawURLEncoding.DecodeString(key.X)
+	if err != nil {
+		return PublicJWK{}, fmt.Errorf("failed to decode public key: %w", err)
+	}
+	if len(publicKeyBytes) != ed25519.PublicKeySize {
+		return PublicJWK{}, fmt.Errorf("invalid public key size: expected %d bytes, got %d", ed25519.PublicKeySize, len(publicKeyBytes))
+	}
+
+	// RFC 7638: required members in lexicographic order, no whitespace
+	sum := sha256.Sum256([]byte(fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q}`, key.Crv, key.Kty, key.X)))
+	return PublicJWK{
+		Kty: key.Kty,
+		Crv: key.Crv,
+		X:   key.X,
+		Kid: base64.RawURLEncoding.EncodeToString(sum[:]),
+	}, nil
+}
+
+// VerifyKeyPair checks that an Ed25519 PEM private key and a JWK are the same
+// key. The JWK may be private or public only; a private JWK must also carry
+// the PEM's seed. Returns the public JWK on success
+func VerifyKeyPair(pemData, jwkJSON string) (PublicJWK, error) {
+	pemJWK, err := ConvertPEMToJWK(pemData)
+	if err != nil {
+		return PublicJWK{}, err
+	}
+	var fromPEM, fromJWK jwkKey
+	if err := json.Unmarshal([]byte(pemJWK), &fromPEM); err != nil {
+		return PublicJWK{}, err
+	}
+	public, err := ToPublicJWK(jwkJSON)
+	if err != nil {
+		return PublicJWK{}, err
+	}
+	if err := json.Unmarshal([]byte(jwkJSON), &fromJWK); err != nil {
+		return PublicJWK{}, err
+	}
+	if fromJWK.X != fromPEM.X {
+		return PublicJWK{}, fmt.Errorf("public keys differ: PEM has x=%s, JWK has x=%s", fromPEM.X, fromJWK.X)
+	}
+	if fromJWK.D != "" && fromJWK.D != fromPEM.D {
+		return PublicJWK{}, fmt.Errorf("private keys differ although the public keys match")
+	}
+	return public, nil
+}
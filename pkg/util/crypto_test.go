@@ -332,3 +332,65 @@ MC4CAQAwBQYDK2VwBCIEIJ+DYvh6SEqVTm50DFtMDoQikTmiCqirVv9mWG9qfSnF
 	assert.True(t, ed25519.Verify(pubKey1, message, sig2), "Key1 should verify signature from Key2")
 	assert.True(t, ed25519.Verify(pubKey2, message, sig1), "Key2 should verify signature from Key1")
 }
+
+func TestGenerateEd25519PEM(t *testing.T) {
+	pemData, err := GenerateEd25519PEM()
+	require.NoError(t, err)
+	require.NoError(t, ValidatePEMKey(string(pemData)))
+
+	other, err := GenerateEd25519PEM()
+	require.NoError(t, err)
+	assert.NotEqual(t, pemData, other, "each call should generate a new key")
+}
+
+func TestToPublicJWK(t *testing.T) {
+	// RFC 8037 appendix A.3
+	public, err := ToPublicJWK(`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", public.Kid)
+	assert.Equal(t, "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo", public.X)
+
+	// The private part never leaks into the public JWK
+	public, err = ToPublicJWK(`{"kty":"OKP","crv":"Ed25519","d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`)
+	require.NoError(t, err)
+	b, err := json.Marshal(public)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), `"d"`)
+
+	_, err = ToPublicJWK(`{"kty":"RSA","n":"abc","e":"AQAB"}`)
+	assert.Error(t, err)
+	_, err = ToPublicJWK(`{"kty":"OKP","crv":"Ed25519","x":"c2hvcnQ"}`)
+	assert.Error(t, err)
+}
+
+func TestVerifyKeyPair(t *testing.T) {
+	pemData, err := GenerateEd25519PEM()
+	require.NoError(t, err)
+	jwkJSON, err := ConvertPEMToJWK(string(pemData))
+	require.NoError(t, err)
+
+	public, err := VerifyKeyPair(string(pemData), jwkJSON)
+	require.NoError(t, err)
+	publicJSON, err := json.Marshal(public)
+	require.NoError(t, err)
+	_, err = VerifyKeyPair(string(pemData), string(publicJSON))
+	assert.NoError(t, err, "a public JWK should match its private PEM")
+
+	other, err := GenerateEd25519PEM()
+	require.NoError(t, err)
+	_, err = VerifyKeyPair(string(other), jwkJSON)
+	assert.ErrorContains(t, err, "public keys differ")
+
+	// Right public key, wrong private key
+	var key jwkKey
+	require.NoError(t, json.Unmarshal([]byte(jwkJSON), &key))
+	otherJWK, err := ConvertPEMToJWK(string(other))
+	require.NoError(t, err)
+	var otherKey jwkKey
+	require.NoError(t, json.Unmarshal([]byte(otherJWK), &otherKey))
+	key.D = otherKey.D
+	mixed, err := json.Marshal(key)
+	require.NoError(t, err)
+	_, err = VerifyKeyPair(string(pemData), string(mixed))
+	assert.ErrorContains(t, err, "private keys differ")
+}
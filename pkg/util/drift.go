@@ -0,0 +1,164 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/kernel/kernel-go-sdk/packages/respjson"
+	"github.com/pterm/pterm"
+)
+
+var strictDecode atomic.Bool
+
+// SetStrictDecode toggles schema drift warnings for SDK responses, whether
+// they are printed as JSON or rendered as tables.
+func SetStrictDecode(enabled bool) {
+	strictDecode.Store(enabled)
+}
+
+// StrictDecodeEnabled reports whether --strict-decode is active.
+func StrictDecodeEnabled() bool {
+	return strictDecode.Load()
+}
+
+// DecodeDrift describes a single mismatch between an API response and the
+// SDK type it was decoded into.
+type DecodeDrift struct {
+	// Path is a JSONPath-like location of the field, e.g. "$.items[0].name".
+	Path string
+	// Kind is "unknown" for fields the SDK does not model and "missing" for
+	// required fields absent from the response.
+	Kind string
+	// Raw is the raw JSON of an unknown field. Empty for missing fields.
+	Raw string
+}
+
+var respjsonFieldType = reflect.TypeOf(respjson.Field{})
+
+// FindDecodeDrift walks an SDK response value and reports fields the API
+// returned that the SDK type does not declare, and required fields that were
+// absent. Results are ordered by path.
+func FindDecodeDrift(v any) []DecodeDrift {
+	var drift []DecodeDrift
+	walkDecodeDrift(reflect.ValueOf(v), "$", &drift)
+	sort.SliceStable(drift, func(i, j int) bool { return drift[i].Path < drift[j].Path })
+	return drift
+}
+
+func walkDecodeDrift(v reflect.Value, path string, drift *[]DecodeDrift) {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walkDecodeDrift(v.Index(i), fmt.Sprintf("%s[%d]", path, i), drift)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			walkDecodeDrift(v.MapIndex(key), fmt.Sprintf("%s.%v", path, key.Interface()), drift)
+		}
+	case reflect.Struct:
+		walkDecodeDriftStruct(v, path, drift)
+	}
+}
+
+func walkDecodeDriftStruct(v reflect.Value, path string, drift *[]DecodeDrift) {
+	t := v.Type()
+	meta := v.FieldByName("JSON")
+	hasMeta := meta.IsValid() && meta.Kind() == reflect.Struct
+
+	// Only inspect structs that were actually populated from a response body;
+	// zero-valued nested objects would otherwise report every required field.
+	decoded := false
+	if raw, ok := v.Interface().(RawJSONProvider); ok {
+		decoded = raw.RawJSON() != ""
+	}
+
+	if hasMeta && decoded {
+		if extra := meta.FieldByName("ExtraFields"); extra.IsValid() && extra.Kind() == reflect.Map {
+			for _, key := range extra.MapKeys() {
+				field, _ := extra.MapIndex(key).Interface().(respjson.Field)
+				*drift = append(*drift, DecodeDrift{
+					Path: path + "." + key.String(),
+					Kind: "unknown",
+					Raw:  field.Raw(),
+				})
+			}
+		}
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() || sf.Name == "JSON" {
+			continue
+		}
+		name, inline := jsonFieldName(sf)
+		if name == "-" {
+			continue
+		}
+		fieldPath := path
+		if !inline {
+			fieldPath = path + "." + name
+		}
+
+		if hasMeta && decoded && !inline && strings.Contains(sf.Tag.Get("api"), "required") {
+			if m := meta.FieldByName(sf.Name); m.IsValid() && m.Type() == respjsonFieldType {
+				if m.Interface().(respjson.Field).Raw() == respjson.Omitted {
+					*drift = append(*drift, DecodeDrift{Path: fieldPath, Kind: "missing"})
+				}
+			}
+		}
+
+		walkDecodeDrift(v.Field(i), fieldPath, drift)
+	}
+}
+
+func jsonFieldName(sf reflect.StructField) (string, bool) {
+	tag := sf.Tag.Get("json")
+	if tag == "" {
+		return sf.Name, false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" && strings.Contains(opts, "inline") {
+		return "", true
+	}
+	if name == "" {
+		return sf.Name, false
+	}
+	return name, false
+}
+
+// WarnDecodeDrift prints schema drift warnings for v to stderr when
+// --strict-decode is enabled. Warnings go to stderr so JSON on stdout stays
+// parseable.
+func WarnDecodeDrift(v any) {
+	if !StrictDecodeEnabled() {
+		return
+	}
+	drift := FindDecodeDrift(v)
+	if len(drift) == 0 {
+		return
+	}
+	warn := pterm.Warning.WithWriter(os.Stderr)
+	for _, d := range drift {
+		switch d.Kind {
+		case "unknown":
+			warn.Printf("strict-decode: unknown field %s = %s\n", d.Path, d.Raw)
+		case "missing":
+			warn.Printf("strict-decode: missing required field %s\n", d.Path)
+		}
+	}
+	warn.Println("strict-decode: the API response does not match this CLI's SDK types; consider running 'kernel upgrade'")
+}
@@ -0,0 +1,39 @@
+package util
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kernel/kernel-go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDecodeDrift_NoDrift(t *testing.T) {
+	var p kernel.Profile
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"p1","created_at":"2024-01-01T00:00:00Z","name":"alpha"}`), &p))
+	assert.Empty(t, FindDecodeDrift(p))
+}
+
+func TestFindDecodeDrift_UnknownAndMissing(t *testing.T) {
+	var p kernel.Profile
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"p1","region":{"code":"us-east"}}`), &p))
+	drift := FindDecodeDrift(&p)
+	require.Len(t, drift, 2)
+	assert.Equal(t, DecodeDrift{Path: "$.created_at", Kind: "missing"}, drift[0])
+	assert.Equal(t, DecodeDrift{Path: "$.region", Kind: "unknown", Raw: `{"code":"us-east"}`}, drift[1])
+}
+
+func TestFindDecodeDrift_Slice(t *testing.T) {
+	var items []kernel.Profile
+	require.NoError(t, json.Unmarshal([]byte(`[{"id":"a","created_at":"2024-01-01T00:00:00Z"},{"id":"b","created_at":"2024-01-01T00:00:00Z","beta":true}]`), &items))
+	drift := FindDecodeDrift(items)
+	require.Len(t, drift, 1)
+	assert.Equal(t, "$[1].beta", drift[0].Path)
+	assert.Equal(t, "true", drift[0].Raw)
+}
+
+func TestFindDecodeDrift_IgnoresUndecodedValues(t *testing.T) {
+	assert.Empty(t, FindDecodeDrift(kernel.Profile{ID: "constructed"}))
+	assert.Empty(t, FindDecodeDrift(nil))
+}
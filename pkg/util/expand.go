@@ -0,0 +1,64 @@
+package util
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ExpandTemplate expands {{env "VAR"}}, {{now}}, {{uuid}} and {{file "path"}}
+// in s so CI scripts don't have to build payloads with shell interpolation.
+// Their output is inserted as is; {{json ...}} encodes a value as a JSON
+// string, quotes included, for text that may hold quotes or newlines.
+// Relative file paths are resolved against dir, or the working directory if
+// dir is empty. Text without "{{" is returned unchanged.
+func ExpandTemplate(s, dir string) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	tmpl, err := template.New("").Option("missingkey=error").Funcs(template.FuncMap{
+		"env": func(name string) (string, error) {
+			v, ok := os.LookupEnv(name)
+			if !ok {
+				return "", fmt.Errorf("environment variable %s is not set", name)
+			}
+			return v, nil
+		},
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"now": func() string { return time.Now().UTC().Format(time.RFC3339) },
+		"uuid": func() string {
+			var b [16]byte
+			_, _ = rand.Read(b[:])
+			b[6] = b[6]&0x0f | 0x40
+			b[8] = b[8]&0x3f | 0x80
+			return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+		},
+		"file": func(path string) (string, error) {
+			if dir != "" && !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", err
+			}
+			// Files written by editors and `echo` end in a newline nobody wants inlined.
+			return strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r"), nil
+		},
+	}).Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, nil); err != nil {
+		return "", fmt.Errorf("expand template: %w", err)
+	}
+	return b.String(), nil
+}
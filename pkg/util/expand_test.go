@@ -0,0 +1,55 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "token.txt"), []byte("s3cret\n"), 0o600))
+	t.Setenv("KERNEL_TEST_BRANCH", "main")
+
+	out, err := ExpandTemplate(`{"branch":"{{env "KERNEL_TEST_BRANCH"}}","token":"{{file "token.txt"}}"}`, dir)
+	require.NoError(t, err)
+	assert.Equal(t, `{"branch":"main","token":"s3cret"}`, out)
+
+	out, err = ExpandTemplate(`{{uuid}}`, "")
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), out)
+
+	out, err = ExpandTemplate(`{{now}}`, "")
+	require.NoError(t, err)
+	_, err = time.Parse(time.RFC3339, out)
+	assert.NoError(t, err)
+
+	out, err = ExpandTemplate(`{"plain": true}`, "")
+	require.NoError(t, err)
+	assert.Equal(t, `{"plain": true}`, out)
+}
+
+func TestExpandTemplate_JSON(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "note.txt"), []byte("say \"hi\"\nthen leave\n"), 0o600))
+
+	out, err := ExpandTemplate(`{"note":{{file "note.txt" | json}}}`, dir)
+	require.NoError(t, err)
+	assert.Equal(t, `{"note":"say \"hi\"\nthen leave"}`, out)
+}
+
+func TestExpandTemplate_Errors(t *testing.T) {
+	_, err := ExpandTemplate(`{{env "KERNEL_TEST_SURELY_UNSET"}}`, "")
+	assert.ErrorContains(t, err, "KERNEL_TEST_SURELY_UNSET is not set")
+
+	_, err = ExpandTemplate(`{{file "missing.txt"}}`, t.TempDir())
+	assert.ErrorContains(t, err, "missing.txt")
+
+	_, err = ExpandTemplate(`{{nope}}`, "")
+	assert.ErrorContains(t, err, "invalid template")
+}
@@ -0,0 +1,106 @@
+package util
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/pterm/pterm"
+)
+
+// GetBaseURLs returns every configured API base URL in priority order.
+// KERNEL_BASE_URL may hold a comma-separated list; the first entry is the
+// primary endpoint and the rest are failover targets for idempotent reads.
+func GetBaseURLs() []string {
+	var urls []string
+	for _, u := range strings.Split(os.Getenv("KERNEL_BASE_URL"), ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, strings.TrimRight(u, "/"))
+		}
+	}
+	if len(urls) == 0 {
+		return []string{defaultBaseURL}
+	}
+	return urls
+}
+
+// isIdempotentMethod reports whether a request can safely be replayed against
+// another endpoint.
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// isFailoverStatus reports whether a response indicates the endpoint itself,
+// rather than the request, is unhealthy.
+func isFailoverStatus(code int) bool {
+	switch code {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// FailoverMiddleware retries idempotent requests against the remaining base
+// URLs when the primary endpoint is unreachable or returns a gateway error.
+// Non-idempotent requests are never replayed.
+func FailoverMiddleware(baseURLs []string) option.Middleware {
+	bases := make([]*url.URL, 0, len(baseURLs))
+	for _, raw := range baseURLs {
+		if u, err := url.Parse(raw); err == nil && u.Host != "" {
+			bases = append(bases, u)
+		}
+	}
+
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		resp, err := next(req)
+		if len(bases) < 2 || !isIdempotentMethod(req.Method) || req.Context().Err() != nil {
+			return resp, err
+		}
+		if err == nil && !isFailoverStatus(resp.StatusCode) {
+			return resp, err
+		}
+
+		primary := bases[0]
+		for _, alt := range bases[1:] {
+			altURL, ok := rebaseURL(req.URL, primary, alt)
+			if !ok {
+				break
+			}
+			if err != nil {
+				pterm.Debug.Printf("%s unreachable (%v); retrying on %s\n", primary.Host, err, alt.Host)
+			} else {
+				pterm.Debug.Printf("%s returned %d; retrying on %s\n", primary.Host, resp.StatusCode, alt.Host)
+				resp.Body.Close()
+			}
+			altReq := req.Clone(req.Context())
+			altReq.URL = altURL
+			altReq.Host = altURL.Host
+			resp, err = next(altReq)
+			if req.Context().Err() != nil || (err == nil && !isFailoverStatus(resp.StatusCode)) {
+				return resp, err
+			}
+		}
+		return resp, err
+	}
+}
+
+// rebaseURL moves u from the from base URL onto the to base URL, preserving
+// the API path below the base and the query string.
+func rebaseURL(u, from, to *url.URL) (*url.URL, bool) {
+	if u.Host != from.Host {
+		return nil, false
+	}
+	rel := strings.TrimPrefix(u.Path, strings.TrimRight(from.Path, "/"))
+	out := *u
+	out.Scheme = to.Scheme
+	out.Host = to.Host
+	out.Path = strings.TrimRight(to.Path, "/") + rel
+	out.RawPath = ""
+	return &out, true
+}
@@ -0,0 +1,91 @@
+package util
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kernel/kernel-go-sdk/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBaseURLs(t *testing.T) {
+	t.Setenv("KERNEL_BASE_URL", "")
+	assert.Equal(t, []string{"https://api.onkernel.com"}, GetBaseURLs())
+
+	t.Setenv("KERNEL_BASE_URL", " https://us.example.com/ , https://eu.example.com,,")
+	assert.Equal(t, []string{"https://us.example.com", "https://eu.example.com"}, GetBaseURLs())
+	assert.Equal(t, "https://us.example.com", GetBaseURL())
+}
+
+// doThroughMiddleware runs req through mw with a plain HTTP transport as next.
+func doThroughMiddleware(t *testing.T, mw option.Middleware, method, url string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	require.NoError(t, err)
+	resp, err := mw(req, http.DefaultTransport.RoundTrip)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestFailoverMiddleware_RetriesIdempotentReads(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	var gotPath string
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.RequestURI()
+		io.WriteString(w, "ok")
+	}))
+	defer fallback.Close()
+
+	mw := FailoverMiddleware([]string{primary.URL, fallback.URL + "/v2"})
+	resp := doThroughMiddleware(t, mw, http.MethodGet, primary.URL+"/browsers?limit=1")
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, "/v2/browsers?limit=1", gotPath)
+}
+
+func TestFailoverMiddleware_RetriesUnreachablePrimary(t *testing.T) {
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer fallback.Close()
+	dead := httptest.NewServer(http.NotFoundHandler())
+	deadURL := dead.URL
+	dead.Close()
+
+	mw := FailoverMiddleware([]string{deadURL, fallback.URL})
+	resp := doThroughMiddleware(t, mw, http.MethodGet, deadURL+"/profiles")
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestFailoverMiddleware_DoesNotReplayWrites(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer primary.Close()
+	fallbackHits := 0
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fallbackHits++
+	}))
+	defer fallback.Close()
+
+	mw := FailoverMiddleware([]string{primary.URL, fallback.URL})
+	req, err := http.NewRequest(http.MethodPost, primary.URL+"/browsers", strings.NewReader("{}"))
+	require.NoError(t, err)
+	resp, err := mw(req, http.DefaultTransport.RoundTrip)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Zero(t, fallbackHits)
+}
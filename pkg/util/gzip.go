@@ -0,0 +1,51 @@
+package util
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/kernel/kernel-go-sdk/option"
+)
+
+// Gzip compresses b at the default level.
+func Gzip(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GzipRequestMiddleware gzips request bodies and marks them with
+// Content-Encoding: gzip. Use it only on endpoints that accept compressed
+// bodies.
+func GzipRequestMiddleware() option.Middleware {
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		if req.Body == nil || req.Body == http.NoBody {
+			return next(req)
+		}
+		raw, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read request body: %w", err)
+		}
+		compressed, err := Gzip(raw)
+		if err != nil {
+			return nil, fmt.Errorf("compress request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(compressed))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(compressed)), nil
+		}
+		req.ContentLength = int64(len(compressed))
+		req.Header.Set("Content-Encoding", "gzip")
+		return next(req)
+	}
+}
@@ -0,0 +1,33 @@
+package util
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGzipRequestMiddleware(t *testing.T) {
+	body := strings.Repeat(`{"k":"v"}`, 1000)
+	req, err := http.NewRequest(http.MethodPost, "https://api.example.com/invocations", strings.NewReader(body))
+	require.NoError(t, err)
+
+	var got *http.Request
+	_, err = GzipRequestMiddleware()(req, func(r *http.Request) (*http.Response, error) {
+		got = r
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "gzip", got.Header.Get("Content-Encoding"))
+	assert.Less(t, got.ContentLength, int64(len(body)))
+	zr, err := gzip.NewReader(got.Body)
+	require.NoError(t, err)
+	decoded, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(decoded))
+}
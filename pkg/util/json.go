@@ -3,7 +3,6 @@ package util
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 )
 
 // RawJSONProvider is an interface for SDK types that provide raw JSON responses.
@@ -15,24 +14,24 @@ type RawJSONProvider interface {
 // It uses the RawJSON() method to get the original API response, avoiding
 // zero-value fields that would appear when re-marshaling the Go struct.
 func PrintPrettyJSON(v RawJSONProvider) error {
+	WarnDecodeDrift(v)
 	raw := v.RawJSON()
 	if raw == "" {
-		fmt.Println("{}")
-		return nil
+		return printJSONOutput([]byte("{}"))
 	}
 
 	var buf bytes.Buffer
 	if err := json.Indent(&buf, []byte(raw), "", "  "); err != nil {
 		return err
 	}
-	fmt.Println(buf.String())
-	return nil
+	return printJSONOutput(buf.Bytes())
 }
 
 // PrintCompactJSONLine prints v as a single compact JSON line followed by a
 // newline. Use inside SSE loops where downstream tooling (jq -c, log shippers)
 // expects newline-delimited JSON.
 func PrintCompactJSONLine(v RawJSONProvider) error {
+	WarnDecodeDrift(v)
 	raw := v.RawJSON()
 	if raw == "" {
 		return nil
@@ -41,17 +40,16 @@ func PrintCompactJSONLine(v RawJSONProvider) error {
 	if err := json.Compact(&buf, []byte(raw)); err != nil {
 		return err
 	}
-	fmt.Println(buf.String())
-	return nil
+	return printJSONOutput(buf.Bytes())
 }
 
 // PrintPrettyJSONSlice prints a slice of SDK response types as a JSON array.
 // Each element must implement RawJSONProvider.
 func PrintPrettyJSONSlice[T RawJSONProvider](items []T) error {
 	if len(items) == 0 {
-		fmt.Println("[]")
-		return nil
+		return printJSONOutput([]byte("[]"))
 	}
+	WarnDecodeDrift(items)
 
 	// Build a JSON array from raw JSON elements
 	var buf bytes.Buffer
@@ -78,6 +76,5 @@ func PrintPrettyJSONSlice[T RawJSONProvider](items []T) error {
 		buf.WriteString("\n")
 	}
 	buf.WriteString("]")
-	fmt.Println(buf.String())
-	return nil
+	return printJSONOutput(buf.Bytes())
 }
@@ -0,0 +1,498 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a parsed kubectl-style JSONPath template such as
+// `{range [*]}{.session_id}{"\t"}{.status}{"\n"}{end}`. Text outside braces is
+// printed as-is. Inside braces it supports $ and @, .field, ['field'], [n],
+// [start:end], [*], filters like [?(@.status=="running")], quoted literals,
+// and range/end blocks. Missing keys produce no output, as with kubectl.
+type jsonPath struct {
+	nodes []jpNode
+}
+
+type jpNode interface{}
+
+type jpText string
+
+type jpRange struct {
+	expr jpExpr
+	body []jpNode
+}
+
+type jpExpr struct {
+	// fromRoot evaluates against the document root ($) instead of the
+	// current range element.
+	fromRoot bool
+	steps    []jpStep
+}
+
+type jpStepKind int
+
+const (
+	jpField jpStepKind = iota
+	jpIndex
+	jpSlice
+	jpWildcard
+	jpFilter
+)
+
+type jpStep struct {
+	kind       jpStepKind
+	name       string
+	index      int
+	start, end *int
+	filter     *jpFilterExpr
+}
+
+type jpFilterExpr struct {
+	path jpExpr
+	op   string
+	// value is the literal compared against; nil for existence checks.
+	value any
+}
+
+func parseJSONPath(tmpl string) (*jsonPath, error) {
+	// stack holds the node lists of open range blocks; the last is appended to.
+	stack := [][]jpNode{nil}
+	var ranges []jpExpr
+	for len(tmpl) > 0 {
+		open := strings.IndexByte(tmpl, '{')
+		if open < 0 {
+			stack[len(stack)-1] = append(stack[len(stack)-1], jpText(tmpl))
+			break
+		}
+		if open > 0 {
+			stack[len(stack)-1] = append(stack[len(stack)-1], jpText(tmpl[:open]))
+		}
+		end, err := jpClosingBrace(tmpl, open)
+		if err != nil {
+			return nil, err
+		}
+		action := strings.TrimSpace(tmpl[open+1 : end])
+		tmpl = tmpl[end+1:]
+
+		switch {
+		case action == "end":
+			if len(ranges) == 0 {
+				return nil, fmt.Errorf("{end} without {range}")
+			}
+			body := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			r := jpRange{expr: ranges[len(ranges)-1], body: body}
+			ranges = ranges[:len(ranges)-1]
+			stack[len(stack)-1] = append(stack[len(stack)-1], r)
+		case strings.HasPrefix(action, "range ") || strings.HasPrefix(action, "range\t"):
+			expr, err := parseJPExpr(strings.TrimSpace(action[len("range"):]))
+			if err != nil {
+				return nil, err
+			}
+			ranges = append(ranges, expr)
+			stack = append(stack, nil)
+		case strings.HasPrefix(action, `"`) || strings.HasPrefix(action, "'"):
+			s, err := jpUnquote(action)
+			if err != nil {
+				return nil, fmt.Errorf("invalid literal %s: %w", action, err)
+			}
+			stack[len(stack)-1] = append(stack[len(stack)-1], jpText(s))
+		default:
+			expr, err := parseJPExpr(action)
+			if err != nil {
+				return nil, err
+			}
+			stack[len(stack)-1] = append(stack[len(stack)-1], expr)
+		}
+	}
+	if len(ranges) > 0 {
+		return nil, fmt.Errorf("{range} without {end}")
+	}
+	return &jsonPath{nodes: stack[0]}, nil
+}
+
+// jpClosingBrace finds the brace closing the action opened at s[open],
+// skipping braces inside quoted literals.
+func jpClosingBrace(s string, open int) (int, error) {
+	var quote byte
+	for i := open + 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '}':
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unclosed action starting at %q", s[open:])
+}
+
+func jpUnquote(s string) (string, error) {
+	if strings.HasPrefix(s, "'") {
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", fmt.Errorf("unterminated string")
+		}
+		return s[1 : len(s)-1], nil
+	}
+	return strconv.Unquote(s)
+}
+
+func parseJPExpr(s string) (jpExpr, error) {
+	var expr jpExpr
+	orig := s
+	switch {
+	case strings.HasPrefix(s, "$"):
+		expr.fromRoot = true
+		s = s[1:]
+	case strings.HasPrefix(s, "@"):
+		s = s[1:]
+	}
+	for len(s) > 0 {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+			if strings.HasPrefix(s, ".") {
+				return expr, fmt.Errorf("recursive descent (..) is not supported in %q", orig)
+			}
+			n := strings.IndexAny(s, ".[")
+			if n < 0 {
+				n = len(s)
+			}
+			name := s[:n]
+			s = s[n:]
+			switch name {
+			case "":
+				// A bare "." refers to the current value.
+			case "*":
+				expr.steps = append(expr.steps, jpStep{kind: jpWildcard})
+			default:
+				expr.steps = append(expr.steps, jpStep{kind: jpField, name: name})
+			}
+		case '[':
+			end, err := jpClosingBracket(s)
+			if err != nil {
+				return expr, fmt.Errorf("%w in %q", err, orig)
+			}
+			step, err := parseJPBracket(strings.TrimSpace(s[1:end]))
+			if err != nil {
+				return expr, fmt.Errorf("%w in %q", err, orig)
+			}
+			expr.steps = append(expr.steps, step)
+			s = s[end+1:]
+		default:
+			return expr, fmt.Errorf("unexpected %q in %q", s, orig)
+		}
+	}
+	return expr, nil
+}
+
+func jpClosingBracket(s string) (int, error) {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unclosed [")
+}
+
+func parseJPBracket(s string) (jpStep, error) {
+	switch {
+	case s == "*":
+		return jpStep{kind: jpWildcard}, nil
+	case strings.HasPrefix(s, "'") || strings.HasPrefix(s, `"`):
+		name, err := jpUnquote(s)
+		if err != nil {
+			return jpStep{}, err
+		}
+		return jpStep{kind: jpField, name: name}, nil
+	case strings.HasPrefix(s, "?(") && strings.HasSuffix(s, ")"):
+		f, err := parseJPFilter(strings.TrimSpace(s[2 : len(s)-1]))
+		if err != nil {
+			return jpStep{}, err
+		}
+		return jpStep{kind: jpFilter, filter: f}, nil
+	case strings.Contains(s, ":"):
+		lo, hi, _ := strings.Cut(s, ":")
+		step := jpStep{kind: jpSlice}
+		for _, b := range []struct {
+			text string
+			dst  **int
+		}{{lo, &step.start}, {hi, &step.end}} {
+			if t := strings.TrimSpace(b.text); t != "" {
+				n, err := strconv.Atoi(t)
+				if err != nil {
+					return jpStep{}, fmt.Errorf("invalid slice bound %q", t)
+				}
+				*b.dst = &n
+			}
+		}
+		return step, nil
+	default:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return jpStep{}, fmt.Errorf("invalid subscript [%s]", s)
+		}
+		return jpStep{kind: jpIndex, index: n}, nil
+	}
+}
+
+func parseJPFilter(s string) (*jpFilterExpr, error) {
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		left, right, ok := strings.Cut(s, op)
+		if !ok {
+			continue
+		}
+		path, err := parseJPExpr(strings.TrimSpace(left))
+		if err != nil {
+			return nil, err
+		}
+		var value any
+		right = strings.TrimSpace(right)
+		if strings.HasPrefix(right, "'") || strings.HasPrefix(right, `"`) {
+			if value, err = jpUnquote(right); err != nil {
+				return nil, err
+			}
+		} else if err := json.Unmarshal([]byte(right), &value); err != nil {
+			return nil, fmt.Errorf("invalid filter value %q", right)
+		}
+		return &jpFilterExpr{path: path, op: op, value: value}, nil
+	}
+	path, err := parseJPExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	return &jpFilterExpr{path: path}, nil
+}
+
+// Execute renders the template against data, which must have been decoded
+// with json.Decoder.UseNumber.
+func (p *jsonPath) Execute(data any) (string, error) {
+	var b strings.Builder
+	if err := jpExecute(&b, p.nodes, data, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func jpExecute(b *strings.Builder, nodes []jpNode, root, cur any) error {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case jpText:
+			b.WriteString(string(n))
+		case jpExpr:
+			vals := n.eval(root, cur)
+			for i, v := range vals {
+				if i > 0 {
+					b.WriteByte(' ')
+				}
+				s, err := jpFormat(v)
+				if err != nil {
+					return err
+				}
+				b.WriteString(s)
+			}
+		case jpRange:
+			vals := n.expr.eval(root, cur)
+			if len(vals) == 1 {
+				if list, ok := vals[0].([]any); ok {
+					vals = list
+				}
+			}
+			for _, v := range vals {
+				if err := jpExecute(b, n.body, root, v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (e jpExpr) eval(root, cur any) []any {
+	vals := []any{cur}
+	if e.fromRoot {
+		vals = []any{root}
+	}
+	for _, step := range e.steps {
+		var next []any
+		for _, v := range vals {
+			next = append(next, step.apply(root, v)...)
+		}
+		vals = next
+	}
+	return vals
+}
+
+func (s jpStep) apply(root, v any) []any {
+	switch s.kind {
+	case jpField:
+		if m, ok := v.(map[string]any); ok {
+			if field, ok := m[s.name]; ok {
+				return []any{field}
+			}
+		}
+	case jpIndex:
+		if list, ok := v.([]any); ok {
+			i := s.index
+			if i < 0 {
+				i += len(list)
+			}
+			if i >= 0 && i < len(list) {
+				return []any{list[i]}
+			}
+		}
+	case jpSlice:
+		if list, ok := v.([]any); ok {
+			lo, hi := 0, len(list)
+			if s.start != nil {
+				lo = jpClamp(*s.start, len(list))
+			}
+			if s.end != nil {
+				hi = jpClamp(*s.end, len(list))
+			}
+			if lo < hi {
+				return list[lo:hi]
+			}
+		}
+	case jpWildcard:
+		return jpChildren(v)
+	case jpFilter:
+		var out []any
+		for _, c := range jpChildren(v) {
+			if s.filter.match(root, c) {
+				out = append(out, c)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func jpClamp(i, n int) int {
+	if i < 0 {
+		i += n
+	}
+	return max(0, min(i, n))
+}
+
+// jpChildren returns a list's elements or an object's values in key order.
+func jpChildren(v any) []any {
+	switch v := v.(type) {
+	case []any:
+		return v
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make([]any, 0, len(keys))
+		for _, k := range keys {
+			out = append(out, v[k])
+		}
+		return out
+	}
+	return nil
+}
+
+func (f *jpFilterExpr) match(root, v any) bool {
+	got := f.path.eval(root, v)
+	if f.op == "" {
+		return len(got) > 0 && got[0] != nil && got[0] != false
+	}
+	if len(got) == 0 {
+		return f.op == "!="
+	}
+	left := got[0]
+	if ln, ok := jpNumber(left); ok {
+		if rn, ok := jpNumber(f.value); ok {
+			switch f.op {
+			case "==":
+				return ln == rn
+			case "!=":
+				return ln != rn
+			case "<":
+				return ln < rn
+			case ">":
+				return ln > rn
+			case "<=":
+				return ln <= rn
+			case ">=":
+				return ln >= rn
+			}
+		}
+	}
+	ls, _ := jpFormat(left)
+	rs, _ := jpFormat(f.value)
+	switch f.op {
+	case "==":
+		return ls == rs
+	case "!=":
+		return ls != rs
+	case "<":
+		return ls < rs
+	case ">":
+		return ls > rs
+	case "<=":
+		return ls <= rs
+	case ">=":
+		return ls >= rs
+	}
+	return false
+}
+
+func jpNumber(v any) (float64, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// jpFormat prints scalars bare and objects or lists as compact JSON.
+func jpFormat(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
@@ -0,0 +1,93 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const jsonPathFixture = `[
+  {"session_id": "a1", "headless": true, "profile": {"name": "work"}, "tags": ["x", "y"]},
+  {"session_id": "b2", "headless": false, "tags": []},
+  {"session_id": "c3", "headless": true, "timeout_seconds": 600}
+]`
+
+func TestJSONPath(t *testing.T) {
+	dec := json.NewDecoder(bytes.NewReader([]byte(jsonPathFixture)))
+	dec.UseNumber()
+	var data any
+	require.NoError(t, dec.Decode(&data))
+
+	tests := []struct {
+		tmpl string
+		want string
+	}{
+		{`{[0].session_id}`, "a1"},
+		{`{$[1].session_id}`, "b2"},
+		{`{[*].session_id}`, "a1 b2 c3"},
+		{`{[0:2].session_id}`, "a1 b2"},
+		{`{[-1].timeout_seconds}`, "600"},
+		{`{[0].profile}`, `{"name":"work"}`},
+		{`{[0].profile.name}`, "work"},
+		{`{[0]['session_id']}`, "a1"},
+		{`{[0].missing}`, ""},
+		{`{[?(@.headless==true)].session_id}`, "a1 c3"},
+		{`{[?(@.timeout_seconds>300)].session_id}`, "c3"},
+		{`{[?(@.profile)].session_id}`, "a1"},
+		{`{range [*]}{.session_id}{"\n"}{end}`, "a1\nb2\nc3\n"},
+		{`id={[0].session_id} tags={[0].tags[*]}`, "id=a1 tags=x y"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			p, err := parseJSONPath(tt.tmpl)
+			require.NoError(t, err)
+			got, err := p.Execute(data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestJSONPath_ParseErrors(t *testing.T) {
+	for _, tmpl := range []string{`{.a`, `{range [*]}{.a}`, `{[?(@.a==]}`} {
+		_, err := parseJSONPath(tmpl)
+		assert.Error(t, err, tmpl)
+	}
+}
+
+func TestApplyOutputTemplate(t *testing.T) {
+	t.Cleanup(func() { outputTemplate = nil })
+
+	newCmd := func(output string) *cobra.Command {
+		cmd := &cobra.Command{}
+		AddJSONOutputFlag(cmd)
+		require.NoError(t, cmd.Flags().Set("output", output))
+		return cmd
+	}
+
+	cmd := newCmd("jsonpath={.id}")
+	require.NoError(t, ApplyOutputTemplate(cmd))
+	output, _ := cmd.Flags().GetString("output")
+	assert.Equal(t, "json", output)
+	got, err := outputTemplate(map[string]any{"id": "abc"})
+	require.NoError(t, err)
+	assert.Equal(t, "abc", got)
+
+	cmd = newCmd("go-template={{.id}} {{.missing}}")
+	require.NoError(t, ApplyOutputTemplate(cmd))
+	got, err = outputTemplate(map[string]any{"id": "abc"})
+	require.NoError(t, err)
+	assert.Equal(t, "abc <no value>", got)
+
+	outputTemplate = nil
+	cmd = newCmd("json")
+	require.NoError(t, ApplyOutputTemplate(cmd))
+	assert.Nil(t, outputTemplate)
+
+	assert.ErrorContains(t, ApplyOutputTemplate(newCmd("jsonpath={.id")), "invalid --output jsonpath")
+	assert.ErrorContains(t, ApplyOutputTemplate(newCmd("go-template={{.id")), "invalid --output go-template")
+}
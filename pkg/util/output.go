@@ -1,20 +1,111 @@
 package util
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
+	"text/template"
 
 	"github.com/spf13/cobra"
 )
 
-const JSONOutputFlagDescription = "Output format: json for raw API response"
+const JSONOutputFlagDescription = "Output format: json for raw API response, or jsonpath=<template> / go-template=<template> to extract fields"
 
 func ValidateJSONOutput(output string) error {
 	if output == "" || output == "json" {
 		return nil
 	}
-	return fmt.Errorf("unsupported --output value %q; use \"json\" or omit --output for human-readable output", output)
+	return fmt.Errorf("unsupported --output value %q; use \"json\", \"jsonpath=<template>\", \"go-template=<template>\", or omit --output for human-readable output", output)
 }
 
 func AddJSONOutputFlag(cmd *cobra.Command) {
 	cmd.Flags().StringP("output", "o", "", JSONOutputFlagDescription)
 }
+
+// outputTemplate renders JSON output through a jsonpath or go-template
+// instead of printing it. It is nil unless --output selected a template.
+var outputTemplate func(data any) (string, error)
+
+// ApplyOutputTemplate handles --output jsonpath=... and --output
+// go-template=... for every command at once: it parses the template, then
+// switches the flag to "json" so the command takes its JSON path, whose
+// printing helpers render the template instead.
+func ApplyOutputTemplate(cmd *cobra.Command) error {
+	flag := cmd.Flags().Lookup("output")
+	if flag == nil {
+		return nil
+	}
+	kind, text, ok := strings.Cut(flag.Value.String(), "=")
+	if !ok {
+		return nil
+	}
+	switch kind {
+	case "jsonpath":
+		p, err := parseJSONPath(text)
+		if err != nil {
+			return fmt.Errorf("invalid --output jsonpath: %w", err)
+		}
+		outputTemplate = p.Execute
+	case "go-template":
+		t, err := template.New("output").Option("missingkey=zero").Parse(text)
+		if err != nil {
+			return fmt.Errorf("invalid --output go-template: %w", err)
+		}
+		outputTemplate = func(data any) (string, error) {
+			var b strings.Builder
+			err := t.Execute(&b, data)
+			return b.String(), err
+		}
+	default:
+		return nil
+	}
+	return flag.Value.Set("json")
+}
+
+// printJSONOutput prints already formatted JSON, or renders it through the
+// active output template.
+func printJSONOutput(formatted []byte) error {
+	if outputTemplate == nil {
+		fmt.Println(string(bytes.TrimRight(formatted, "\n")))
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(formatted))
+	dec.UseNumber()
+	var data any
+	if err := dec.Decode(&data); err != nil {
+		return err
+	}
+	out, err := outputTemplate(data)
+	if err != nil {
+		return fmt.Errorf("render --output template: %w", err)
+	}
+	if out != "" && !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	_, err = os.Stdout.WriteString(out)
+	return err
+}
+
+// PrintJSON prints v as indented JSON (or through the --output template), for
+// values that aren't SDK responses.
+func PrintJSON(v any) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return printJSONOutput(buf.Bytes())
+}
+
+// PrintJSONLine prints v as one compact JSON line (or through the --output
+// template), for newline-delimited streams.
+func PrintJSONLine(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return printJSONOutput(b)
+}
@@ -0,0 +1,261 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SecretResolver fetches a secret from one backend. ref is everything after
+// the "scheme:" prefix of a secret reference.
+type SecretResolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// SecretResolverFunc adapts a function to SecretResolver.
+type SecretResolverFunc func(ctx context.Context, ref string) (string, error)
+
+func (f SecretResolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+var (
+	secretResolversMu sync.RWMutex
+	secretResolvers   = map[string]SecretResolver{
+		"env":      SecretResolverFunc(resolveEnvSecret),
+		"file":     SecretResolverFunc(resolveFileSecret),
+		"op":       SecretResolverFunc(resolveOnePasswordSecret),
+		"vault":    SecretResolverFunc(resolveVaultSecret),
+		"aws-sm":   SecretResolverFunc(resolveAWSSecret),
+		"keychain": SecretResolverFunc(resolveKeychainSecret),
+	}
+)
+
+// RegisterSecretResolver adds or replaces the backend for scheme.
+func RegisterSecretResolver(scheme string, r SecretResolver) {
+	secretResolversMu.Lock()
+	defer secretResolversMu.Unlock()
+	secretResolvers[scheme] = r
+}
+
+// SecretSchemes returns the registered schemes, sorted.
+func SecretSchemes() []string {
+	secretResolversMu.RLock()
+	defer secretResolversMu.RUnlock()
+	schemes := make([]string, 0, len(secretResolvers))
+	for s := range secretResolvers {
+		schemes = append(schemes, s)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// ResolveSecret resolves a secret flag value. Values of the form
+// "scheme:ref" with a registered scheme (env:, file:, op:, vault:, aws-sm:,
+// keychain:) are fetched from that backend; anything else is returned as is.
+// Prefix a value with "literal:" to pass it through even if it looks like a
+// reference.
+func ResolveSecret(ctx context.Context, value string) (string, error) {
+	scheme, ref, ok := strings.Cut(value, ":")
+	if !ok {
+		return value, nil
+	}
+	if scheme == "literal" {
+		return ref, nil
+	}
+	secretResolversMu.RLock()
+	r, known := secretResolvers[scheme]
+	secretResolversMu.RUnlock()
+	if !known {
+		return value, nil
+	}
+	if ref == "" {
+		return "", fmt.Errorf("%s: secret reference is empty", scheme)
+	}
+	secret, err := r.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("%s:%s: %w", scheme, ref, err)
+	}
+	return secret, nil
+}
+
+// splitSecretKey splits "path#key" into its path and optional key.
+func splitSecretKey(ref string) (string, string) {
+	path, key, _ := strings.Cut(ref, "#")
+	return path, key
+}
+
+// runSecretCommand runs a backend's CLI and returns its trimmed stdout.
+// Tests replace it.
+var runSecretCommand = func(ctx context.Context, name string, args ...string) (string, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", fmt.Errorf("%s is not installed or not on PATH", name)
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %s", name, msg)
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+func resolveEnvSecret(_ context.Context, name string) (string, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return v, nil
+}
+
+func resolveFileSecret(_ context.Context, path string) (string, error) {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// resolveOnePasswordSecret reads a 1Password secret reference with the op
+// CLI: op:vault/item/field or op:op://vault/item/field.
+func resolveOnePasswordSecret(ctx context.Context, ref string) (string, error) {
+	if !strings.HasPrefix(ref, "op://") {
+		ref = "op://" + strings.TrimPrefix(ref, "//")
+	}
+	return runSecretCommand(ctx, "op", "read", "--no-newline", ref)
+}
+
+// vaultHTTPClient is used for Vault reads; tests replace it.
+var vaultHTTPClient = http.DefaultClient
+
+// resolveVaultSecret reads a field from HashiCorp Vault over its HTTP API:
+// vault:<path>#<field>, e.g. vault:kv/data/github#password. VAULT_ADDR and
+// VAULT_TOKEN (or ~/.vault-token) configure access, as for the vault CLI.
+func resolveVaultSecret(ctx context.Context, ref string) (string, error) {
+	path, field := splitSecretKey(ref)
+	if field == "" {
+		return "", fmt.Errorf("expected <path>#<field>")
+	}
+	addr := strings.TrimRight(os.Getenv("VAULT_ADDR"), "/")
+	if addr == "" {
+		return "", fmt.Errorf("VAULT_ADDR is not set")
+	}
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			if data, err := os.ReadFile(filepath.Join(home, ".vault-token")); err == nil {
+				token = strings.TrimSpace(string(data))
+			}
+		}
+	}
+	if token == "" {
+		return "", fmt.Errorf("VAULT_TOKEN is not set and ~/.vault-token is missing")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+	resp, err := vaultHTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %s", resp.Status)
+	}
+
+	var payload struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", fmt.Errorf("invalid vault response: %w", err)
+	}
+	data := payload.Data
+	// KV v2 nests the secret under data.data.
+	if inner, ok := data["data"].(map[string]any); ok {
+		if _, direct := data[field]; !direct {
+			data = inner
+		}
+	}
+	return secretField(data, field)
+}
+
+// resolveAWSSecret reads an AWS Secrets Manager secret with the aws CLI:
+// aws-sm:<secret-id> for the whole string, or aws-sm:<secret-id>#<key> for
+// one key of a JSON secret.
+func resolveAWSSecret(ctx context.Context, ref string) (string, error) {
+	id, key := splitSecretKey(ref)
+	secret, err := runSecretCommand(ctx, "aws", "secretsmanager", "get-secret-value",
+		"--secret-id", id, "--query", "SecretString", "--output", "text")
+	if err != nil || key == "" {
+		return secret, err
+	}
+	var data map[string]any
+	if err := json.Unmarshal([]byte(secret), &data); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object, so #%s cannot be selected", key)
+	}
+	return secretField(data, key)
+}
+
+// resolveKeychainSecret reads a password from the OS keychain:
+// keychain:<service>#<account>. It uses security on macOS and secret-tool
+// (libsecret) elsewhere.
+func resolveKeychainSecret(ctx context.Context, ref string) (string, error) {
+	service, account := splitSecretKey(ref)
+	if runtime.GOOS == "darwin" {
+		args := []string{"find-generic-password", "-s", service, "-w"}
+		if account != "" {
+			args = append(args, "-a", account)
+		}
+		return runSecretCommand(ctx, "security", args...)
+	}
+	args := []string{"lookup", "service", service}
+	if account != "" {
+		args = append(args, "account", account)
+	}
+	return runSecretCommand(ctx, "secret-tool", args...)
+}
+
+// secretField returns data[key] as a string.
+func secretField(data map[string]any, key string) (string, error) {
+	v, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("key %q not found", key)
+	}
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", fmt.Errorf("key %q is null", key)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b), nil
+	}
+}
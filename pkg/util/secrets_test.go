@@ -0,0 +1,138 @@
+package util
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSecretCommands replaces runSecretCommand and records each invocation.
+func fakeSecretCommands(t *testing.T, out string) *[]string {
+	t.Helper()
+	var calls []string
+	orig := runSecretCommand
+	runSecretCommand = func(ctx context.Context, name string, args ...string) (string, error) {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		return out, nil
+	}
+	t.Cleanup(func() { runSecretCommand = orig })
+	return &calls
+}
+
+func TestResolveSecret(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pw")
+	require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0o600))
+	t.Setenv("KERNEL_TEST_SECRET", "from-env")
+
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"env:KERNEL_TEST_SECRET", "from-env"},
+		{"file:" + path, "from-file"},
+		{"literal:env:KERNEL_TEST_SECRET", "env:KERNEL_TEST_SECRET"},
+		// Unregistered schemes are ordinary values.
+		{"https://example.com", "https://example.com"},
+		{"pass:word", "pass:word"},
+	}
+	for _, tt := range tests {
+		got, err := ResolveSecret(ctx, tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	_, err := ResolveSecret(ctx, "env:KERNEL_TEST_UNSET_SECRET")
+	assert.ErrorContains(t, err, "env:KERNEL_TEST_UNSET_SECRET: environment variable KERNEL_TEST_UNSET_SECRET is not set")
+	_, err = ResolveSecret(ctx, "file:")
+	assert.ErrorContains(t, err, "secret reference is empty")
+}
+
+func TestResolveSecret_CommandBackends(t *testing.T) {
+	ctx := context.Background()
+
+	calls := fakeSecretCommands(t, "s3cret")
+	got, err := ResolveSecret(ctx, "op:Private/GitHub/password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", got)
+	_, err = ResolveSecret(ctx, "op:op://Private/GitHub/password")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"op read --no-newline op://Private/GitHub/password",
+		"op read --no-newline op://Private/GitHub/password",
+	}, *calls)
+
+	calls = fakeSecretCommands(t, `{"username":"me","password":"hunter2"}`)
+	got, err = ResolveSecret(ctx, "aws-sm:prod/github#password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", got)
+	assert.Equal(t, []string{"aws secretsmanager get-secret-value --secret-id prod/github --query SecretString --output text"}, *calls)
+	_, err = ResolveSecret(ctx, "aws-sm:prod/github#token")
+	assert.ErrorContains(t, err, `key "token" not found`)
+
+	calls = fakeSecretCommands(t, "kc-secret")
+	got, err = ResolveSecret(ctx, "keychain:github.com#me")
+	require.NoError(t, err)
+	assert.Equal(t, "kc-secret", got)
+	require.Len(t, *calls, 1)
+	assert.Contains(t, (*calls)[0], "github.com")
+	assert.Contains(t, (*calls)[0], "me")
+}
+
+func TestResolveSecret_Vault(t *testing.T) {
+	var gotToken, gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotToken, gotPath = r.Header.Get("X-Vault-Token"), r.URL.Path
+		switch r.URL.Path {
+		case "/v1/kv/data/github":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"kv2-secret"},"metadata":{"version":3}}}`))
+		case "/v1/secret/github":
+			_, _ = w.Write([]byte(`{"data":{"password":"kv1-secret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("VAULT_ADDR", srv.URL+"/")
+	t.Setenv("VAULT_TOKEN", "tok")
+	ctx := context.Background()
+
+	got, err := ResolveSecret(ctx, "vault:kv/data/github#password")
+	require.NoError(t, err)
+	assert.Equal(t, "kv2-secret", got)
+	assert.Equal(t, "tok", gotToken)
+
+	got, err = ResolveSecret(ctx, "vault:secret/github#password")
+	require.NoError(t, err)
+	assert.Equal(t, "kv1-secret", got)
+	assert.Equal(t, "/v1/secret/github", gotPath)
+
+	_, err = ResolveSecret(ctx, "vault:kv/data/missing#password")
+	assert.ErrorContains(t, err, "404")
+	_, err = ResolveSecret(ctx, "vault:kv/data/github")
+	assert.ErrorContains(t, err, "expected <path>#<field>")
+}
+
+func TestRegisterSecretResolver(t *testing.T) {
+	RegisterSecretResolver("test-backend", SecretResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		return strings.ToUpper(ref), nil
+	}))
+	t.Cleanup(func() {
+		secretResolversMu.Lock()
+		delete(secretResolvers, "test-backend")
+		secretResolversMu.Unlock()
+	})
+
+	got, err := ResolveSecret(context.Background(), "test-backend:abc")
+	require.NoError(t, err)
+	assert.Equal(t, "ABC", got)
+	assert.Contains(t, SecretSchemes(), "test-backend")
+}
@@ -1,6 +1,10 @@
 package util
 
-import "time"
+import (
+	"strconv"
+	"strings"
+	"time"
+)
 
 // DefaultTimeLayout is the standard layout used for displaying timestamps.
 // Includes the local timezone abbreviation to make it clear times are local.
@@ -14,3 +18,24 @@ func FormatLocal(t time.Time) string {
 	}
 	return t.In(time.Local).Format(DefaultTimeLayout)
 }
+
+// ParseTimeOrAgo parses a point in time given as how long ago it was (7d,
+// 12h, 90m), a date (2006-01-02, local time) or an RFC-3339 timestamp. It
+// reports false for anything else.
+func ParseTimeOrAgo(s string, now time.Time) (time.Time, bool) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return now.AddDate(0, 0, -n), true
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return now.Add(-d), true
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,90 @@
+package util
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kernel/kernel-go-sdk/option"
+)
+
+// CallTiming is one API request attempt and how long it took. Retries and
+// failover attempts are recorded separately.
+type CallTiming struct {
+	Method   string
+	Path     string
+	Duration time.Duration
+}
+
+// CallTimings records the API calls a command makes. It is safe for
+// concurrent use.
+type CallTimings struct {
+	mu    sync.Mutex
+	calls []CallTiming
+}
+
+// Middleware returns SDK middleware that records every request into t.
+func (t *CallTimings) Middleware() option.Middleware {
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		start := time.Now()
+		resp, err := next(req)
+		t.Record(CallTiming{Method: req.Method, Path: req.URL.Path, Duration: time.Since(start)})
+		return resp, err
+	}
+}
+
+func (t *CallTimings) Record(c CallTiming) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.calls = append(t.calls, c)
+}
+
+// Calls returns a copy of the recorded calls in the order they finished.
+func (t *CallTimings) Calls() []CallTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]CallTiming(nil), t.calls...)
+}
+
+// Summary renders the calls as a one-line footer, e.g.
+// "API: 4 calls, 2.3s total; slowest: POST /invocations 1.9s". It returns
+// "" when no calls were made.
+func (t *CallTimings) Summary() string {
+	calls := t.Calls()
+	if len(calls) == 0 {
+		return ""
+	}
+	var total time.Duration
+	slowest := calls[0]
+	for _, c := range calls {
+		total += c.Duration
+		if c.Duration > slowest.Duration {
+			slowest = c
+		}
+	}
+	noun := "calls"
+	if len(calls) == 1 {
+		noun = "call"
+	}
+	return fmt.Sprintf("API: %d %s, %s total; slowest: %s %s %s",
+		len(calls), noun, formatSeconds(total), slowest.Method, slowest.Path, formatSeconds(slowest.Duration))
+}
+
+// Slow returns the calls that took at least threshold.
+func (t *CallTimings) Slow(threshold time.Duration) []CallTiming {
+	var slow []CallTiming
+	for _, c := range t.Calls() {
+		if c.Duration >= threshold {
+			slow = append(slow, c)
+		}
+	}
+	return slow
+}
+
+func formatSeconds(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
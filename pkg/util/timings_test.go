@@ -0,0 +1,43 @@
+package util
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallTimings_Summary(t *testing.T) {
+	var timings CallTimings
+	assert.Equal(t, "", timings.Summary())
+
+	timings.Record(CallTiming{Method: "GET", Path: "/apps", Duration: 150 * time.Millisecond})
+	assert.Equal(t, "API: 1 call, 150ms total; slowest: GET /apps 150ms", timings.Summary())
+
+	timings.Record(CallTiming{Method: "POST", Path: "/invocations", Duration: 1900 * time.Millisecond})
+	timings.Record(CallTiming{Method: "GET", Path: "/invocations/abc", Duration: 100 * time.Millisecond})
+	timings.Record(CallTiming{Method: "GET", Path: "/invocations/abc", Duration: 150 * time.Millisecond})
+	assert.Equal(t, "API: 4 calls, 2.3s total; slowest: POST /invocations 1.9s", timings.Summary())
+
+	slow := timings.Slow(time.Second)
+	require.Len(t, slow, 1)
+	assert.Equal(t, "/invocations", slow[0].Path)
+}
+
+func TestCallTimings_Middleware(t *testing.T) {
+	var timings CallTimings
+	req, err := http.NewRequest(http.MethodDelete, "https://api.example.com/browsers/b1", nil)
+	require.NoError(t, err)
+
+	_, err = timings.Middleware()(req, func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody}, nil
+	})
+	require.NoError(t, err)
+
+	calls := timings.Calls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "DELETE", calls[0].Method)
+	assert.Equal(t, "/browsers/b1", calls[0].Path)
+}